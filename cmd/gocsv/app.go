@@ -10,10 +10,12 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,6 +23,8 @@ import (
 
 	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
 
+	"github.com/bitjungle/gopca/internal/core"
+	streamstats "github.com/bitjungle/gopca/internal/stats"
 	"github.com/bitjungle/gopca/internal/version"
 	"github.com/bitjungle/gopca/pkg/integration"
 	"github.com/bitjungle/gopca/pkg/types"
@@ -33,12 +37,19 @@ type App struct {
 	ctx         context.Context
 	history     *CommandHistory
 	currentData *FileData
+
+	// Significance level for the normality test in the data quality report
+	normalityAlpha float64
+
+	// How CSV rows with more or fewer fields than the header are handled on import
+	raggedRows types.RaggedRowPolicy
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
 	return &App{
-		history: NewCommandHistory(100), // Keep last 100 commands
+		history:        NewCommandHistory(100), // Keep last 100 commands
+		normalityAlpha: core.DefaultNormalityAlpha,
 	}
 }
 
@@ -48,12 +59,6 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-// ValidationResult represents the result of GoPCA validation
-type ValidationResult struct {
-	IsValid  bool     `json:"isValid"`
-	Messages []string `json:"messages"`
-}
-
 // LoadCSV loads a CSV file and returns its data
 func (a *App) LoadCSV(filePath string) (*FileData, error) {
 	// If no filepath provided, show file dialog
@@ -104,7 +109,7 @@ func (a *App) LoadCSV(filePath string) (*FileData, error) {
 		if err != nil {
 			return nil, fmt.Errorf("error loading Excel file: %w", err)
 		}
-	case ".tsv", ".csv", "":
+	case ".tsv", ".tab", ".csv", "":
 		// Handle CSV/TSV files
 		content, err := os.ReadFile(filePath)
 		if err != nil {
@@ -117,7 +122,7 @@ func (a *App) LoadCSV(filePath string) (*FileData, error) {
 		}
 
 		// Parse using GoPCA's parser with format detection
-		fileData, err = a.parseCSVContent(string(content), ext)
+		fileData, err = a.parseCSVContent(string(content), filePath)
 		if err != nil {
 			return nil, err
 		}
@@ -125,6 +130,8 @@ func (a *App) LoadCSV(filePath string) (*FileData, error) {
 		return nil, fmt.Errorf("unsupported file format: %s", ext)
 	}
 
+	updateColumnSubtypes(fileData)
+
 	// Store the filename for display
 	if a.ctx != nil {
 		wailsruntime.EventsEmit(a.ctx, "file-loaded", filepath.Base(filePath))
@@ -175,21 +182,11 @@ func (a *App) loadExcel(filePath string) (*FileData, error) {
 
 	// Convert Excel data to CSV format for parsing
 	var csvContent strings.Builder
-	for _, row := range rows {
-		for i, cell := range row {
-			if i > 0 {
-				csvContent.WriteString(",")
-			}
-			// Quote cells that contain commas or quotes
-			if strings.Contains(cell, ",") || strings.Contains(cell, "\"") {
-				csvContent.WriteString("\"")
-				csvContent.WriteString(strings.ReplaceAll(cell, "\"", "\"\""))
-				csvContent.WriteString("\"")
-			} else {
-				csvContent.WriteString(cell)
-			}
-		}
-		csvContent.WriteString("\n")
+	csvOpts := pkgcsv.DefaultOptions()
+	csvOpts.HasHeaders = false
+	csvOpts.HasRowNames = false
+	if err := pkgcsv.Write(&csvContent, &pkgcsv.Data{StringData: rows}, csvOpts); err != nil {
+		return nil, fmt.Errorf("failed to convert sheet %s: %w", selectedSheet, err)
 	}
 
 	// Parse the CSV content using GoPCA's parser
@@ -197,8 +194,9 @@ func (a *App) loadExcel(filePath string) (*FileData, error) {
 	return a.parseCSVContent(csvContent.String(), ".csv")
 }
 
-// parseCSVContent parses CSV content using GoPCA's parser
-func (a *App) parseCSVContent(content string, ext string) (*FileData, error) {
+// parseCSVContent parses CSV content using GoPCA's parser. The filename is only
+// used to detect tab-separated files.
+func (a *App) parseCSVContent(content string, filename string) (*FileData, error) {
 	// Configure format based on file extension
 	defaultFormat := types.DefaultCSVFormat()
 	formats := []types.CSVFormat{
@@ -206,7 +204,7 @@ func (a *App) parseCSVContent(content string, ext string) (*FileData, error) {
 	}
 
 	// Add TSV format if TSV file
-	if ext == ".tsv" {
+	if types.IsTSVFile(filename) {
 		formats = []types.CSVFormat{
 			{
 				FieldDelimiter:   '\t',
@@ -229,99 +227,135 @@ func (a *App) parseCSVContent(content string, ext string) (*FileData, error) {
 		)
 	}
 
-	var csvData *types.CSVData
-	var categoricalData map[string][]string
-	var numericTargetData map[string][]float64
-	var lastErr error
-	var successfulFormat types.CSVFormat
-
-	// Try each format until one works
-	for _, format := range formats {
-		reader := strings.NewReader(content)
-		data, catData, targetData, err := types.ParseCSVMixedWithTargets(reader, format, nil)
-		if err == nil && data != nil && data.Columns > 0 {
-			csvData = data
-			categoricalData = catData
-			numericTargetData = targetData
-			successfulFormat = format
-			break
-		}
-		if err != nil {
-			lastErr = err
-		}
-	}
-
-	if csvData == nil {
-		if lastErr != nil {
-			wailsruntime.LogError(a.ctx, fmt.Sprintf("Failed to parse CSV: %v", lastErr))
-			return nil, fmt.Errorf("failed to parse CSV: %w", lastErr)
-		}
-		wailsruntime.LogError(a.ctx, "No data found in file")
-		return nil, fmt.Errorf("no data found in file")
+	for i := range formats {
+		formats[i].RaggedRows = a.raggedRows
 	}
-
-	// Convert numeric matrix to string matrix for display
-	stringData := make([][]string, len(csvData.Matrix))
-	for i, row := range csvData.Matrix {
-		stringData[i] = make([]string, len(row))
-		for j, val := range row {
-			if csvData.MissingMask != nil && csvData.MissingMask[i][j] {
-				stringData[i][j] = ""
-			} else {
-				stringData[i][j] = strconv.FormatFloat(val, 'g', -1, 64)
-			}
-		}
-	}
-
-	// Build column types map
-	columnTypes := make(map[string]string)
-
-	// Mark numeric columns
-	for _, header := range csvData.Headers {
-		columnTypes[header] = "numeric"
+	parsed, confidence, err := parseBestCSVFormat(content, formats)
+	if err != nil {
+		wailsruntime.LogError(a.ctx, err.Error())
+		return nil, err
 	}
+	csvData := parsed.data
+	categoricalData := parsed.categorical
+	numericTargetData := parsed.targets
+	successfulFormat := parsed.format
 
-	// Mark categorical columns
-	for colName := range categoricalData {
-		columnTypes[colName] = "categorical"
+	wailsruntime.LogInfo(a.ctx, fmt.Sprintf("Parsed data: %d rows, %d columns, %d headers (delimiter %q, decimal %q, confidence %.2f)",
+		csvData.Rows, csvData.Columns, len(csvData.Headers), successfulFormat.FieldDelimiter,
+		successfulFormat.DecimalSeparator, confidence))
+	for _, warning := range csvData.Warnings {
+		wailsruntime.LogWarning(a.ctx, warning)
 	}
 
-	// Mark target columns
-	for colName := range numericTargetData {
-		columnTypes[colName] = "target"
+	// Combine numeric, categorical and target columns into one grid for display,
+	// keeping the column order of the file
+	var columnOrder []string
+	if len(categoricalData) > 0 || len(numericTargetData) > 0 {
+		columnOrder = a.getAllOriginalHeaders(content, successfulFormat)
 	}
+	grid := types.CSVDataToFileData(csvData, categoricalData, numericTargetData, columnOrder)
 
-	// Create FileData with all information
 	fileData := &FileData{
-		Headers:              csvData.Headers,
-		RowNames:             csvData.RowNames,
-		Data:                 stringData,
+		FileData:             *grid,
 		Rows:                 csvData.Rows,
-		Columns:              csvData.Columns,
+		Columns:              len(grid.Headers),
 		CategoricalColumns:   categoricalData,
 		NumericTargetColumns: ConvertFloat64MapToJSON(numericTargetData),
-		ColumnTypes:          columnTypes,
+		Warnings:             csvData.Warnings,
+		FormatConfidence:     confidence,
 	}
 
-	wailsruntime.LogInfo(a.ctx, fmt.Sprintf("Parsed data: %d rows, %d columns, %d headers", csvData.Rows, csvData.Columns, len(csvData.Headers)))
+	return fileData, nil
+}
 
-	// If we have categorical or target columns, we need to combine them with numeric data
-	// for the full data display
-	if len(categoricalData) > 0 || len(numericTargetData) > 0 {
-		// Get all original headers to preserve column order
-		allOriginalHeaders := a.getAllOriginalHeaders(content, successfulFormat)
-		fileData = a.combineAllColumns(csvData, categoricalData, numericTargetData, allOriginalHeaders)
+// parsedCSV is the result of parsing CSV content with one candidate format
+type parsedCSV struct {
+	format      types.CSVFormat
+	data        *types.CSVData
+	categorical map[string][]string
+	targets     map[string][]float64
+	score       float64
+}
+
+// parseBestCSVFormat parses content with every candidate format and returns the
+// result of the best one rather than the first that parses: a semicolon-separated
+// file with decimal commas also parses as comma-separated, into split and mostly
+// categorical columns. Each format scores its number of numeric and target columns
+// times the fraction of rows with the most common field count; ties go to the
+// earlier format. The confidence is the best score's share of the scores of all
+// formats that parsed, so 1 when no other format parsed and 0.5 for a tie.
+func parseBestCSVFormat(content string, formats []types.CSVFormat) (*parsedCSV, float64, error) {
+	var best *parsedCSV
+	var lastErr error
+	total := 0.0
+	for _, format := range formats {
+		data, categorical, targets, err := types.ParseCSVMixedWithTargets(strings.NewReader(content), format, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if data == nil || data.Columns == 0 {
+			continue
+		}
+		candidate := &parsedCSV{
+			format:      format,
+			data:        data,
+			categorical: categorical,
+			targets:     targets,
+			score:       float64(data.Columns+len(targets)) * fieldCountConsistency(content, format),
+		}
+		total += candidate.score
+		if best == nil || candidate.score > best.score {
+			best = candidate
+		}
 	}
 
-	return fileData, nil
+	if best == nil {
+		if lastErr != nil {
+			return nil, 0, fmt.Errorf("failed to parse CSV: %w", lastErr)
+		}
+		return nil, 0, fmt.Errorf("no data found in file")
+	}
+	confidence := 1.0
+	if total > 0 {
+		confidence = best.score / total
+	}
+	return best, confidence, nil
+}
+
+// fieldCountConsistency returns the fraction of the records of content, split with
+// the format's delimiter, that have the most common number of fields
+func fieldCountConsistency(content string, format types.CSVFormat) float64 {
+	reader := types.NewCSVReader(strings.NewReader(content), format.FieldDelimiter)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	counts := make(map[int]int)
+	records := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0
+		}
+		counts[len(record)]++
+		records++
+	}
+	if records == 0 {
+		return 0
+	}
+	mostCommon := 0
+	for _, n := range counts {
+		mostCommon = max(mostCommon, n)
+	}
+	return float64(mostCommon) / float64(records)
 }
 
 // getAllOriginalHeaders extracts all headers from CSV content in their original order
 func (a *App) getAllOriginalHeaders(content string, format types.CSVFormat) []string {
-	csvReader := csv.NewReader(strings.NewReader(content))
-	csvReader.Comma = format.FieldDelimiter
+	csvReader := types.NewCSVReader(strings.NewReader(content), format.FieldDelimiter)
 	csvReader.LazyQuotes = true
-	csvReader.TrimLeadingSpace = true
 
 	// Read first line to get headers
 	records, err := csvReader.Read()
@@ -337,188 +371,121 @@ func (a *App) getAllOriginalHeaders(content string, format types.CSVFormat) []st
 	return records
 }
 
-// combineAllColumns combines numeric, categorical, and target columns for display
-func (a *App) combineAllColumns(csvData *types.CSVData, categoricalData map[string][]string, numericTargetData map[string][]float64, originalHeaders []string) *FileData {
-	// If no original headers provided, fall back to the old behavior
-	if len(originalHeaders) == 0 {
-		originalHeaders = make([]string, 0)
-		// Add numeric headers first
-		originalHeaders = append(originalHeaders, csvData.Headers...)
-		// Add categorical headers
-		for colName := range categoricalData {
-			originalHeaders = append(originalHeaders, colName)
-		}
-		// Add target headers
-		for colName := range numericTargetData {
-			originalHeaders = append(originalHeaders, colName)
-		}
-	}
-
-	// Create a map to quickly find numeric column indices
-	numericColIndex := make(map[string]int)
-	for idx, header := range csvData.Headers {
-		numericColIndex[header] = idx
-	}
-
-	// Initialize the result data structures
-	allHeaders := make([]string, 0, len(originalHeaders))
-	allData := make([][]string, csvData.Rows)
-	columnTypes := make(map[string]string)
-
-	// Initialize rows
-	for i := range allData {
-		allData[i] = make([]string, 0, len(originalHeaders))
+// ValidateForGoPCA validates that the CSV data is compatible with GoPCA
+func (a *App) ValidateForGoPCA(data *FileData) *types.ValidationReport {
+	return fileDataToCSVData(data).Validate(pkgcsv.DefaultValidationOptions())
+}
+
+// fileDataToCSVData converts the grid data to parsed CSV data using the detected
+// column types. Numeric cells that are missing or fail to parse become NaN, and
+// columns without a detected type are left out.
+func fileDataToCSVData(data *FileData) *pkgcsv.Data {
+	grid := data.FileData
+	grid.Data = grid.Data[:min(data.Rows, len(grid.Data))]
+	parsed, categorical, targets := types.FileDataToCSVData(&grid, pkgcsv.DefaultOptions().NullValues)
+
+	return &pkgcsv.Data{
+		Matrix:               parsed.Matrix,
+		Headers:              parsed.Headers,
+		RowNames:             parsed.RowNames,
+		MissingMask:          parsed.MissingMask,
+		Rows:                 parsed.Rows,
+		Columns:              parsed.Columns,
+		CategoricalColumns:   categorical,
+		NumericTargetColumns: targets,
+	}
+}
+
+// CellIssue describes a cell whose value does not match the type of its column
+type CellIssue struct {
+	Row          int    `json:"row"`
+	Col          int    `json:"col"`
+	Column       string `json:"column"`
+	Value        string `json:"value"`
+	ExpectedType string `json:"expectedType"`
+	SuggestedFix string `json:"suggestedFix,omitempty"`
+}
+
+// numericPrefixPattern matches a number at the start of a value, such as "5" in "5kg"
+var numericPrefixPattern = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?`)
+
+// ValidateCellTypes reports every cell whose value does not match its column's type,
+// so stray values can be fixed before they are silently treated as missing in PCA.
+// Numeric and target columns report non-numeric cells, with the leading number as a
+// suggested fix when the value has a trailing unit or typo. Categorical columns report
+// cells that are numbers when most of the column is not. Missing values are ignored.
+func (a *App) ValidateCellTypes(data *FileData) []CellIssue {
+	issues := []CellIssue{}
+	if data == nil {
+		return issues
 	}
 
-	// Process columns in their original order
-	for _, header := range originalHeaders {
-		allHeaders = append(allHeaders, header)
+	for colIdx, header := range data.Headers {
+		// Detect the type from the non-missing values. A single stray cell makes the
+		// loader treat a numeric column as categorical, so a column that is mostly
+		// numbers is checked as numeric.
+		column := make([][]string, 0, len(data.Data))
+		for _, row := range data.Data {
+			if colIdx < len(row) && !isMissingValue(row[colIdx]) {
+				column = append(column, []string{row[colIdx]})
+			}
+		}
+		colType := data.ColumnTypes[header]
+		if detected := a.detectColumnType(column, 0); colType == "" || (detected == "numeric" && colType != "target") {
+			colType = detected
+		}
 
-		// Check if it's a numeric column
-		if colIdx, isNumeric := numericColIndex[header]; isNumeric {
-			columnTypes[header] = "numeric"
-			for rowIdx := 0; rowIdx < csvData.Rows; rowIdx++ {
-				if csvData.MissingMask != nil && csvData.MissingMask[rowIdx][colIdx] {
-					allData[rowIdx] = append(allData[rowIdx], "")
-				} else {
-					allData[rowIdx] = append(allData[rowIdx], strconv.FormatFloat(csvData.Matrix[rowIdx][colIdx], 'g', -1, 64))
+		switch colType {
+		case "numeric", "target":
+			for rowIdx, row := range data.Data {
+				if colIdx >= len(row) || isMissingValue(row[colIdx]) {
+					continue
 				}
-			}
-		} else if values, isCategorical := categoricalData[header]; isCategorical {
-			// It's a categorical column
-			columnTypes[header] = "categorical"
-			for rowIdx, value := range values {
-				if rowIdx < len(allData) {
-					allData[rowIdx] = append(allData[rowIdx], value)
+				value := strings.TrimSpace(row[colIdx])
+				if _, err := strconv.ParseFloat(value, 64); err == nil {
+					continue
 				}
-			}
-		} else if values, isTarget := numericTargetData[header]; isTarget {
-			// It's a target column
-			columnTypes[header] = "target"
-			for rowIdx, value := range values {
-				if rowIdx < len(allData) {
-					allData[rowIdx] = append(allData[rowIdx], strconv.FormatFloat(value, 'g', -1, 64))
+				issue := CellIssue{
+					Row:          rowIdx,
+					Col:          colIdx,
+					Column:       header,
+					Value:        row[colIdx],
+					ExpectedType: "numeric",
+				}
+				if prefix := numericPrefixPattern.FindString(value); prefix != "" {
+					issue.SuggestedFix = prefix
 				}
+				issues = append(issues, issue)
 			}
-		}
-		// If header not found in any category, it will be skipped
-	}
-
-	return &FileData{
-		Headers:              allHeaders,
-		RowNames:             csvData.RowNames,
-		Data:                 allData,
-		Rows:                 csvData.Rows,
-		Columns:              len(allHeaders),
-		CategoricalColumns:   categoricalData,
-		NumericTargetColumns: ConvertFloat64MapToJSON(numericTargetData),
-		ColumnTypes:          columnTypes,
-	}
-}
-
-// ValidateForGoPCA validates that the CSV data is compatible with GoPCA
-func (a *App) ValidateForGoPCA(data *FileData) *ValidationResult {
-	var warnings []string
-	var numericColumns int
-	var categoricalColumns int
-	var targetColumns int
-	var totalMissing int
-
-	// Check minimum data requirements
-	if data.Rows < 2 {
-		warnings = append(warnings, "ERROR: Data must have at least 2 rows (found "+fmt.Sprintf("%d", data.Rows)+")")
-	}
-
-	// Count column types using our pre-detected types
-	for _, colType := range data.ColumnTypes {
-		switch colType {
-		case "numeric":
-			numericColumns++
 		case "categorical":
-			categoricalColumns++
-		case "target":
-			targetColumns++
-		}
-	}
-
-	// Check for missing values in the data
-	for colIdx, header := range data.Headers {
-		missingInCol := 0
-
-		for i := 0; i < data.Rows; i++ {
-			if i >= len(data.Data) {
-				break
+			var numericRows []int
+			nonMissing := 0
+			for rowIdx, row := range data.Data {
+				if colIdx >= len(row) || isMissingValue(row[colIdx]) {
+					continue
+				}
+				nonMissing++
+				if _, err := strconv.ParseFloat(strings.TrimSpace(row[colIdx]), 64); err == nil {
+					numericRows = append(numericRows, rowIdx)
+				}
 			}
-			value := data.Data[i][colIdx]
-
-			// Check for missing values
-			trimmed := strings.TrimSpace(value)
-			if trimmed == "" || trimmed == "NA" || trimmed == "N/A" ||
-				trimmed == "nan" || trimmed == "NaN" || trimmed == "null" || trimmed == "NULL" {
-				missingInCol++
-				totalMissing++
+			// Numeric codes are fine when they make up most of the column
+			if 2*len(numericRows) >= nonMissing {
+				continue
 			}
-		}
-
-		// Report high missing value percentage
-		if data.Rows > 0 {
-			missingPercent := float64(missingInCol) / float64(data.Rows) * 100
-			if missingPercent > 50 {
-				warnings = append(warnings, fmt.Sprintf("WARNING: Column '%s' has %.1f%% missing values", header, missingPercent))
+			for _, rowIdx := range numericRows {
+				issues = append(issues, CellIssue{
+					Row:          rowIdx,
+					Col:          colIdx,
+					Column:       header,
+					Value:        data.Data[rowIdx][colIdx],
+					ExpectedType: "categorical",
+				})
 			}
 		}
 	}
 
-	// Report column type summary
-	if categoricalColumns > 0 {
-		warnings = append(warnings, fmt.Sprintf("INFO: %d categorical column(s) detected - these will be excluded from PCA but available for visualization", categoricalColumns))
-	}
-
-	if targetColumns > 0 {
-		warnings = append(warnings, fmt.Sprintf("INFO: %d target column(s) detected - these will be excluded from PCA but available for visualization", targetColumns))
-	}
-
-	// Check if we have enough numeric columns for PCA
-	if numericColumns < 2 {
-		warnings = append(warnings, fmt.Sprintf("ERROR: Need at least 2 numeric columns for PCA (found %d)", numericColumns))
-	} else if numericColumns < 3 {
-		warnings = append(warnings, fmt.Sprintf("WARNING: Only %d numeric columns found - PCA results may be limited", numericColumns))
-	} else {
-		warnings = append(warnings, fmt.Sprintf("INFO: %d numeric columns will be used for PCA analysis", numericColumns))
-	}
-
-	// Report overall missing data
-	totalCells := data.Rows * data.Columns
-	if totalCells > 0 {
-		missingPercent := float64(totalMissing) / float64(totalCells) * 100
-		if missingPercent > 0 {
-			warnings = append(warnings, fmt.Sprintf("INFO: Dataset contains %.1f%% missing values (%d cells)", missingPercent, totalMissing))
-		}
-	}
-
-	// Check for reasonable data size
-	if data.Rows > 10000 {
-		warnings = append(warnings, fmt.Sprintf("INFO: Large dataset detected (%d rows) - processing may take time", data.Rows))
-	}
-
-	// Check if row names were detected
-	if len(data.RowNames) > 0 {
-		warnings = append(warnings, "INFO: Row names detected in first column")
-	}
-
-	// Determine if data is valid (no ERRORs)
-	isValid := true
-	for _, warning := range warnings {
-		if strings.HasPrefix(warning, "ERROR:") {
-			isValid = false
-			break
-		}
-	}
-
-	return &ValidationResult{
-		IsValid:  isValid,
-		Messages: warnings,
-	}
+	return issues
 }
 
 // SaveCSV saves the data to a CSV file
@@ -532,6 +499,10 @@ func (a *App) SaveCSV(data *FileData) error {
 				DisplayName: "CSV Files (*.csv)",
 				Pattern:     "*.csv",
 			},
+			{
+				DisplayName: "TSV Files (*.tsv)",
+				Pattern:     "*.tsv",
+			},
 		},
 	})
 	if err != nil {
@@ -545,7 +516,7 @@ func (a *App) SaveCSV(data *FileData) error {
 	csvData := &pkgcsv.Data{
 		Headers:    data.Headers,
 		RowNames:   data.RowNames,
-		StringData: data.Data,
+		StringData: exportRows(data),
 		Rows:       data.Rows,
 		Columns:    data.Columns,
 	}
@@ -554,9 +525,12 @@ func (a *App) SaveCSV(data *FileData) error {
 	opts := pkgcsv.DefaultOptions()
 	opts.HasHeaders = true
 	opts.HasRowNames = len(data.RowNames) > 0
-	
+	if types.IsTSVFile(selection) {
+		opts.Delimiter = '\t'
+	}
+
 	// Write using the unified CSV writer
-	if err := pkgcsv.SaveFile(selection, csvData, opts); err != nil {
+	if err := pkgcsv.WriteFile(selection, csvData, opts); err != nil {
 		return fmt.Errorf("error writing CSV file: %w", err)
 	}
 
@@ -626,7 +600,7 @@ func (a *App) SaveExcel(data *FileData) error {
 	}
 
 	// Write data rows
-	for rowIdx, row := range data.Data {
+	for rowIdx, row := range exportRows(data) {
 		excelRow := rowIdx + 2 // Excel rows are 1-indexed, plus header row
 
 		// Write row name if present
@@ -738,31 +712,29 @@ func (a *App) AnalyzeMissingValues(data *FileData) *MissingValueStats {
 			RowStats:    make(map[int]*RowMissing),
 		}
 	}
+	return missingValueStats(data, ScanData(data))
+}
 
+// missingValueStats builds the missing value statistics of data from its scan
+func missingValueStats(data *FileData, scan *DataScan) *MissingValueStats {
 	stats := &MissingValueStats{
-		TotalCells:  data.Rows * data.Columns,
-		ColumnStats: make(map[string]*ColumnMissing),
-		RowStats:    make(map[int]*RowMissing),
+		TotalCells:     scan.TotalCells,
+		MissingCells:   scan.MissingCells,
+		MissingPercent: scan.MissingPercent(),
+		ColumnStats:    make(map[string]*ColumnMissing),
+		RowStats:       make(map[int]*RowMissing),
 	}
 
-	// Analyze by column
+	// Summarize by column
 	for colIdx, header := range data.Headers {
 		colStats := &ColumnMissing{
 			Name:        header,
 			TotalValues: data.Rows,
 		}
-
-		missingIndices := []int{}
-		for rowIdx := 0; rowIdx < data.Rows; rowIdx++ {
-			if rowIdx >= len(data.Data) || colIdx >= len(data.Data[rowIdx]) {
-				continue
-			}
-			value := strings.TrimSpace(data.Data[rowIdx][colIdx])
-			if isMissingValue(value) {
-				colStats.MissingValues++
-				stats.MissingCells++
-				missingIndices = append(missingIndices, rowIdx)
-			}
+		var missingIndices []int
+		if colIdx < len(scan.ColumnMissing) {
+			colStats.MissingValues = scan.ColumnMissing[colIdx]
+			missingIndices = scan.ColumnMissingRows[colIdx]
 		}
 
 		if colStats.TotalValues > 0 {
@@ -774,35 +746,20 @@ func (a *App) AnalyzeMissingValues(data *FileData) *MissingValueStats {
 		stats.ColumnStats[header] = colStats
 	}
 
-	// Analyze by row
-	for rowIdx := 0; rowIdx < data.Rows; rowIdx++ {
-		rowStats := &RowMissing{
-			Index:       rowIdx,
-			TotalValues: data.Columns,
+	// Summarize by row, only including rows with missing values
+	for rowIdx, missing := range scan.RowMissing {
+		if missing == 0 {
+			continue
 		}
-
-		if rowIdx < len(data.Data) {
-			for colIdx := 0; colIdx < data.Columns && colIdx < len(data.Data[rowIdx]); colIdx++ {
-				value := strings.TrimSpace(data.Data[rowIdx][colIdx])
-				if isMissingValue(value) {
-					rowStats.MissingValues++
-				}
-			}
+		rowStats := &RowMissing{
+			Index:         rowIdx,
+			TotalValues:   data.Columns,
+			MissingValues: missing,
 		}
-
 		if rowStats.TotalValues > 0 {
 			rowStats.MissingPercent = float64(rowStats.MissingValues) / float64(rowStats.TotalValues) * 100
 		}
-
-		// Only include rows with missing values
-		if rowStats.MissingValues > 0 {
-			stats.RowStats[rowIdx] = rowStats
-		}
-	}
-
-	// Calculate overall percentage
-	if stats.TotalCells > 0 {
-		stats.MissingPercent = float64(stats.MissingCells) / float64(stats.TotalCells) * 100
+		stats.RowStats[rowIdx] = rowStats
 	}
 
 	return stats
@@ -867,9 +824,10 @@ func detectMissingPattern(missingIndices []int, totalRows int) string {
 
 // FillMissingValuesRequest represents a request to fill missing values
 type FillMissingValuesRequest struct {
-	Strategy string `json:"strategy"` // "mean", "median", "mode", "forward", "backward", "custom"
-	Column   string `json:"column"`   // Column name, or empty for all columns
-	Value    string `json:"value"`    // Custom value for "custom" strategy
+	Strategy string `json:"strategy"`          // "mean", "median", "mode", "forward", "backward", "custom"
+	Column   string `json:"column"`            // Column name, or empty for all columns
+	Value    string `json:"value"`             // Custom value for "custom" strategy
+	GroupBy  string `json:"groupBy,omitempty"` // Column whose groups "mean", "median" and "mode" are computed within
 }
 
 // FillMissingValues fills missing values in the data according to the specified strategy
@@ -880,14 +838,16 @@ func (a *App) FillMissingValues(data *FileData, request FillMissingValuesRequest
 
 	// Clone the data to avoid modifying the original
 	result := &FileData{
-		Headers:              data.Headers,
-		RowNames:             data.RowNames,
-		Data:                 make([][]string, len(data.Data)),
+		FileData: types.FileData{
+			Headers:     data.Headers,
+			RowNames:    data.RowNames,
+			Data:        make([][]string, len(data.Data)),
+			ColumnTypes: data.ColumnTypes,
+		},
 		Rows:                 data.Rows,
 		Columns:              data.Columns,
 		CategoricalColumns:   data.CategoricalColumns,
 		NumericTargetColumns: data.NumericTargetColumns,
-		ColumnTypes:          data.ColumnTypes,
 	}
 
 	// Deep copy the data
@@ -918,6 +878,14 @@ func (a *App) FillMissingValues(data *FileData, request FillMissingValuesRequest
 		}
 	}
 
+	if request.GroupBy != "" {
+		if err := fillByGroup(result, columnsToProcess, request); err != nil {
+			return nil, err
+		}
+		updateColumnSubtypes(result)
+		return result, nil
+	}
+
 	// Apply the fill strategy
 	for _, colIdx := range columnsToProcess {
 		switch request.Strategy {
@@ -937,10 +905,96 @@ func (a *App) FillMissingValues(data *FileData, request FillMissingValuesRequest
 			return nil, fmt.Errorf("unknown fill strategy: %s", request.Strategy)
 		}
 	}
+	updateColumnSubtypes(result)
 
 	return result, nil
 }
 
+// fillByGroup fills missing values with the mean, median or mode of their column over
+// the rows with the same value in the request's GroupBy column. Rows with a missing
+// group, and groups without an observed value in the column, get the statistic over
+// all rows.
+func fillByGroup(data *FileData, columns []int, request FillMissingValuesRequest) error {
+	fill := map[string]func(*FileData, int){
+		"mean":   fillWithMean,
+		"median": fillWithMedian,
+		"mode":   fillWithMode,
+	}[request.Strategy]
+	if fill == nil {
+		return fmt.Errorf("group-wise filling needs the mean, median or mode strategy, not %s", request.Strategy)
+	}
+	groupCol := -1
+	for i, header := range data.Headers {
+		if header == request.GroupBy {
+			groupCol = i
+			break
+		}
+	}
+	if groupCol < 0 {
+		return fmt.Errorf("group column '%s' not found", request.GroupBy)
+	}
+
+	// Groups are taken before any filling, so a filled group column does not move rows
+	var groupNames []string
+	groups := make(map[string][][]string)
+	for _, row := range data.Data {
+		if groupCol >= len(row) {
+			continue
+		}
+		name := strings.TrimSpace(row[groupCol])
+		if isMissingValue(name) {
+			continue
+		}
+		if _, ok := groups[name]; !ok {
+			groupNames = append(groupNames, name)
+		}
+		groups[name] = append(groups[name], row)
+	}
+
+	// Statistics over all rows, taken before the groups are filled
+	global := deepCopyFileData(data)
+	for _, colIdx := range columns {
+		fill(global, colIdx)
+	}
+
+	// The rows of a group share their slices with data, so filling a group fills data
+	for _, name := range groupNames {
+		group := &FileData{
+			FileData: types.FileData{
+				Headers:     data.Headers,
+				Data:        groups[name],
+				ColumnTypes: data.ColumnTypes,
+			},
+			Rows:    len(groups[name]),
+			Columns: data.Columns,
+		}
+		for _, colIdx := range columns {
+			if hasObservedValue(group.Data, colIdx) {
+				fill(group, colIdx)
+			}
+		}
+	}
+
+	for rowIdx, row := range data.Data {
+		for _, colIdx := range columns {
+			if colIdx < len(row) && isMissingValue(strings.TrimSpace(row[colIdx])) {
+				row[colIdx] = global.Data[rowIdx][colIdx]
+			}
+		}
+	}
+	return nil
+}
+
+// hasObservedValue reports whether any row has a non-missing value in the column
+func hasObservedValue(rows [][]string, colIdx int) bool {
+	for _, row := range rows {
+		if colIdx < len(row) && !isMissingValue(strings.TrimSpace(row[colIdx])) {
+			return true
+		}
+	}
+	return false
+}
+
 // fillWithMean fills missing values with the column mean (numeric columns only)
 func fillWithMean(data *FileData, colIdx int) {
 	if colIdx >= len(data.Headers) {
@@ -1101,6 +1155,15 @@ type DataQualityReport struct {
 	QualityScore    float64          `json:"qualityScore"`
 	Issues          []QualityIssue   `json:"issues"`
 	Recommendations []Recommendation `json:"recommendations"`
+	// Groups of mutually redundant numeric columns (|r| above highCorrelationThreshold)
+	CorrelationClusters []CorrelationCluster `json:"correlationClusters"`
+}
+
+// CorrelationCluster represents a group of highly correlated numeric columns
+type CorrelationCluster struct {
+	Columns        []string `json:"columns"`
+	Representative string   `json:"representative"` // Column with the highest variance
+	MinCorrelation float64  `json:"minCorrelation"` // Weakest |r| linking the cluster
 }
 
 // DataProfile contains overall dataset statistics
@@ -1142,7 +1205,8 @@ type ColumnStatistics struct {
 	IQR            *float64       `json:"iqr,omitempty"`
 	Skewness       *float64       `json:"skewness,omitempty"`
 	Kurtosis       *float64       `json:"kurtosis,omitempty"`
-	Categories     map[string]int `json:"categories,omitempty"` // For categorical columns
+	Categories     map[string]int `json:"categories,omitempty"`  // For categorical columns
+	Approximate    bool           `json:"approximate,omitempty"` // Median and quartiles are streaming estimates, and unique values a capped count
 }
 
 // DistributionInfo contains information about data distribution
@@ -1150,7 +1214,8 @@ type DistributionInfo struct {
 	Histogram       []HistogramBin `json:"histogram,omitempty"`
 	IsNormal        bool           `json:"isNormal"`
 	NormalityPValue float64        `json:"normalityPValue,omitempty"`
-	DistType        string         `json:"distType"` // "normal", "skewed", "bimodal", "uniform", etc.
+	NormalityTest   string         `json:"normalityTest,omitempty"` // Name of the test that gave NormalityPValue
+	DistType        string         `json:"distType"`                // "normal", "skewed", "bimodal", "uniform", etc.
 }
 
 // HistogramBin represents a bin in a histogram
@@ -1186,6 +1251,26 @@ type Recommendation struct {
 	Columns     []string `json:"columns,omitempty"`
 }
 
+// SetRaggedRows sets how CSV rows with more or fewer fields than the header are
+// handled when a file is loaded or imported: "error", "pad" or "truncate"
+func (a *App) SetRaggedRows(policy string) error {
+	raggedRows, err := types.ParseRaggedRowPolicy(policy)
+	if err != nil {
+		return err
+	}
+	a.raggedRows = raggedRows
+	return nil
+}
+
+// SetNormalityAlpha sets the significance level used by the normality test in the data quality report
+func (a *App) SetNormalityAlpha(alpha float64) error {
+	if alpha <= 0 || alpha >= 1 {
+		return fmt.Errorf("alpha must be between 0 and 1, got %g", alpha)
+	}
+	a.normalityAlpha = alpha
+	return nil
+}
+
 // AnalyzeDataQuality performs comprehensive data quality analysis
 func (a *App) AnalyzeDataQuality(data *FileData) (*DataQualityReport, error) {
 	if data == nil || len(data.Data) == 0 {
@@ -1198,9 +1283,10 @@ func (a *App) AnalyzeDataQuality(data *FileData) (*DataQualityReport, error) {
 			Rows:    data.Rows,
 			Columns: data.Columns,
 		},
-		ColumnAnalysis:  make([]ColumnAnalysis, 0, data.Columns),
-		Issues:          []QualityIssue{},
-		Recommendations: []Recommendation{},
+		ColumnAnalysis:      make([]ColumnAnalysis, 0, data.Columns),
+		Issues:              []QualityIssue{},
+		Recommendations:     []Recommendation{},
+		CorrelationClusters: []CorrelationCluster{},
 	}
 
 	// Count column types
@@ -1215,30 +1301,27 @@ func (a *App) AnalyzeDataQuality(data *FileData) (*DataQualityReport, error) {
 		}
 	}
 
-	// Calculate missing data percentage
-	missingStats := a.AnalyzeMissingValues(data)
-	report.DataProfile.MissingPercent = missingStats.MissingPercent
-
-	// Detect duplicate rows
-	report.DataProfile.DuplicateRows = countDuplicateRows(data)
-
-	// Estimate memory size
-	report.DataProfile.MemorySize = estimateMemorySize(data)
+	// Missing values, duplicate rows and memory size from one pass over the data
+	scan := ScanData(data)
+	report.DataProfile.MissingPercent = scan.MissingPercent()
+	report.DataProfile.DuplicateRows = scan.DuplicateRows
+	report.DataProfile.MemorySize = formatMemorySize(scan.MemoryBytes)
 
 	// Analyze each column
 	for colIdx, header := range data.Headers {
-		colAnalysis := analyzeColumn(data, colIdx, header)
+		colAnalysis := analyzeColumn(data, colIdx, header, a.normalityAlpha)
 		report.ColumnAnalysis = append(report.ColumnAnalysis, colAnalysis)
 	}
 
-	// Calculate correlations for numeric columns
+	// Calculate correlations for numeric columns and group redundant ones
 	correlations := calculateCorrelations(data)
+	report.CorrelationClusters = clusterCorrelatedColumns(correlations, report.ColumnAnalysis, highCorrelationThreshold)
 
 	// Generate issues based on analysis
-	report.Issues = generateQualityIssues(report, correlations)
+	report.Issues = generateQualityIssues(report)
 
-	// Generate recommendations
-	report.Recommendations = generateRecommendations(report)
+	// Generate recommendations, including the preprocessing PCA needs
+	report.Recommendations = generateRecommendations(report, core.RecommendPreprocessing(numericMatrix(data)))
 
 	// Calculate overall quality score
 	report.QualityScore = calculateQualityScore(report)
@@ -1247,7 +1330,7 @@ func (a *App) AnalyzeDataQuality(data *FileData) (*DataQualityReport, error) {
 }
 
 // analyzeColumn performs detailed analysis on a single column
-func analyzeColumn(data *FileData, colIdx int, header string) ColumnAnalysis {
+func analyzeColumn(data *FileData, colIdx int, header string, normalityAlpha float64) ColumnAnalysis {
 	analysis := ColumnAnalysis{
 		Name: header,
 		Type: "numeric", // Default
@@ -1263,7 +1346,7 @@ func analyzeColumn(data *FileData, colIdx int, header string) ColumnAnalysis {
 	// Calculate statistics based on column type
 	if analysis.Type == "numeric" {
 		analysis.Stats = calculateNumericStats(data, colIdx)
-		analysis.Distribution = analyzeDistribution(data, colIdx)
+		analysis.Distribution = analyzeDistribution(data, colIdx, analysis.Stats, normalityAlpha)
 		analysis.Outliers = detectOutliers(data, colIdx, analysis.Stats)
 	} else {
 		analysis.Stats = calculateCategoricalStats(data, colIdx)
@@ -1275,8 +1358,23 @@ func analyzeColumn(data *FileData, colIdx int, header string) ColumnAnalysis {
 	return analysis
 }
 
+// streamingStatsRows is the number of rows above which numeric column statistics are
+// computed in one pass, with approximate median and quartiles, instead of sorting the column
+const streamingStatsRows = 100000
+
+// Bounds of the memory used by the statistics of a column above streamingStatsRows: the
+// number of distinct values counted and the size of the sample tested for normality
+const (
+	streamingDistinctLimit = 10000
+	streamingSampleSize    = 5000
+)
+
 // calculateNumericStats calculates statistics for numeric columns
 func calculateNumericStats(data *FileData, colIdx int) ColumnStatistics {
+	if data.Rows > streamingStatsRows {
+		return calculateNumericStatsStreaming(data, colIdx)
+	}
+
 	stats := ColumnStatistics{
 		Count: data.Rows,
 	}
@@ -1334,6 +1432,66 @@ func calculateNumericStats(data *FileData, colIdx int) ColumnStatistics {
 	return stats
 }
 
+// calculateNumericStatsStreaming calculates the same statistics as calculateNumericStats
+// in one pass without sorting or copying the column. Mean, variance and higher moments
+// are exact; median and quartiles are P² estimates, and distinct values are counted up
+// to streamingDistinctLimit.
+func calculateNumericStatsStreaming(data *FileData, colIdx int) ColumnStatistics {
+	stats := ColumnStatistics{
+		Count:       data.Rows,
+		Approximate: true,
+	}
+
+	summary := streamstats.NewSummary()
+	unique := streamstats.NewDistinctCounter(streamingDistinctLimit)
+	for rowIdx := 0; rowIdx < data.Rows && rowIdx < len(data.Data); rowIdx++ {
+		if colIdx >= len(data.Data[rowIdx]) {
+			continue
+		}
+		value := data.Data[rowIdx][colIdx]
+		if isMissingValue(value) {
+			stats.Missing++
+			continue
+		}
+		if num, ok := parseNumericValue(value); ok {
+			summary.Add(num)
+			unique.Add(num)
+		}
+	}
+
+	if stats.Count > 0 {
+		stats.MissingPercent = float64(stats.Missing) / float64(stats.Count) * 100
+	}
+
+	if summary.Count() == 0 {
+		return stats
+	}
+
+	stats.Unique = unique.Count()
+	mean := summary.Mean()
+	stats.Mean = &mean
+	stdDev := summary.StdDev()
+	stats.StdDev = &stdDev
+	min := summary.Min()
+	stats.Min = &min
+	max := summary.Max()
+	stats.Max = &max
+
+	q1, median, q3 := summary.Quartiles()
+	stats.Median = &median
+	stats.Q1 = &q1
+	stats.Q3 = &q3
+	iqr := q3 - q1
+	stats.IQR = &iqr
+
+	skewness := summary.Skewness()
+	stats.Skewness = &skewness
+	kurtosis := summary.Kurtosis()
+	stats.Kurtosis = &kurtosis
+
+	return stats
+}
+
 // calculateCategoricalStats calculates statistics for categorical columns
 func calculateCategoricalStats(data *FileData, colIdx int) ColumnStatistics {
 	stats := ColumnStatistics{
@@ -1453,20 +1611,20 @@ func calculateKurtosis(values []float64, mean, stdDev float64) float64 {
 	return (n*(n+1)/((n-1)*(n-2)*(n-3)))*sum - 3*(n-1)*(n-1)/((n-2)*(n-3))
 }
 
-// analyzeDistribution analyzes the distribution of numeric data
-func analyzeDistribution(data *FileData, colIdx int) DistributionInfo {
+// analyzeDistribution analyzes the distribution of numeric data, using the statistics
+// of the column above streamingStatsRows rows
+func analyzeDistribution(data *FileData, colIdx int, stats ColumnStatistics, normalityAlpha float64) DistributionInfo {
+	if data.Rows > streamingStatsRows {
+		return analyzeDistributionStreaming(data, colIdx, stats, normalityAlpha)
+	}
+
 	dist := DistributionInfo{}
 
 	// Collect valid numeric values
 	values := []float64{}
 	for rowIdx := 0; rowIdx < data.Rows && rowIdx < len(data.Data); rowIdx++ {
-		if colIdx < len(data.Data[rowIdx]) {
-			value := strings.TrimSpace(data.Data[rowIdx][colIdx])
-			if !isMissingValue(value) {
-				if num, err := strconv.ParseFloat(value, 64); err == nil {
-					values = append(values, num)
-				}
-			}
+		if num, ok := distributionValue(data, rowIdx, colIdx); ok {
+			values = append(values, num)
 		}
 	}
 
@@ -1477,43 +1635,120 @@ func analyzeDistribution(data *FileData, colIdx int) DistributionInfo {
 	// Create histogram with 10 bins
 	sort.Float64s(values)
 	min, max := values[0], values[len(values)-1]
-	binWidth := (max - min) / 10
+	dist.Histogram = histogramBins(min, max)
+	for _, v := range values {
+		addToHistogram(dist.Histogram, v, min, max)
+	}
 
-	if binWidth > 0 {
-		dist.Histogram = make([]HistogramBin, 10)
-		for i := 0; i < 10; i++ {
-			binMin := min + float64(i)*binWidth
-			binMax := binMin + binWidth
-			if i == 9 {
-				binMax = max + 0.001 // Include max value in last bin
-			}
+	// Normality test (Shapiro-Wilk, or D'Agostino-Pearson for large samples)
+	mean := calculateMean(values)
+	stdDev := calculateStdDev(values, mean)
+	skewness := calculateSkewness(values, mean, stdDev)
 
-			dist.Histogram[i] = HistogramBin{
-				Min: binMin,
-				Max: binMax,
-			}
-		}
+	if normality, err := core.NormalityTest(values, normalityAlpha); err == nil {
+		dist.IsNormal = normality.IsNormal
+		dist.NormalityPValue = normality.PValue
+		dist.NormalityTest = normalityTestName(normality.Method)
+	}
 
-		// Count values in each bin
-		for _, v := range values {
-			binIndex := int((v - min) / binWidth)
-			if binIndex >= 10 {
-				binIndex = 9
-			}
-			dist.Histogram[binIndex].Count++
+	classifyDistribution(&dist, skewness)
+	return dist
+}
+
+// analyzeDistributionStreaming analyzes the distribution of a large numeric column in
+// one pass with bounded memory. The histogram spans the minimum and maximum of stats,
+// and normality is tested on a random sample of streamingSampleSize values.
+func analyzeDistributionStreaming(data *FileData, colIdx int, stats ColumnStatistics, normalityAlpha float64) DistributionInfo {
+	dist := DistributionInfo{}
+	if stats.Min == nil || stats.Max == nil || stats.Skewness == nil {
+		return dist
+	}
+
+	min, max := *stats.Min, *stats.Max
+	dist.Histogram = histogramBins(min, max)
+	sample := streamstats.NewReservoir(streamingSampleSize)
+	for rowIdx := 0; rowIdx < data.Rows && rowIdx < len(data.Data); rowIdx++ {
+		if num, ok := distributionValue(data, rowIdx, colIdx); ok {
+			addToHistogram(dist.Histogram, num, min, max)
+			sample.Add(num)
 		}
 	}
 
-	// Simple normality test based on skewness and kurtosis
-	mean := calculateMean(values)
-	stdDev := calculateStdDev(values, mean)
-	skewness := calculateSkewness(values, mean, stdDev)
-	kurtosis := calculateKurtosis(values, mean, stdDev)
+	if normality, err := core.NormalityTest(sample.Sample(), normalityAlpha); err == nil {
+		dist.IsNormal = normality.IsNormal
+		dist.NormalityPValue = normality.PValue
+		dist.NormalityTest = normalityTestName(normality.Method)
+	}
+
+	classifyDistribution(&dist, *stats.Skewness)
+	return dist
+}
+
+// normalityTestName returns the display name of a core normality test method
+func normalityTestName(method string) string {
+	switch method {
+	case core.NormalityShapiroWilk:
+		return "Shapiro-Wilk"
+	case core.NormalityDAgostinoK2:
+		return "D'Agostino-Pearson"
+	default:
+		return method
+	}
+}
+
+// distributionValue returns the numeric value of a cell, or false if it is missing or
+// not a number
+func distributionValue(data *FileData, rowIdx, colIdx int) (float64, bool) {
+	if colIdx >= len(data.Data[rowIdx]) {
+		return 0, false
+	}
+	value := strings.TrimSpace(data.Data[rowIdx][colIdx])
+	if isMissingValue(value) {
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(value, 64)
+	return num, err == nil
+}
+
+// histogramBins returns the 10 empty bins of a histogram from min to max, or nil when
+// min and max are equal
+func histogramBins(min, max float64) []HistogramBin {
+	binWidth := (max - min) / 10
+	if binWidth <= 0 {
+		return nil
+	}
+
+	bins := make([]HistogramBin, 10)
+	for i := 0; i < 10; i++ {
+		binMin := min + float64(i)*binWidth
+		binMax := binMin + binWidth
+		if i == 9 {
+			binMax = max + 0.001 // Include max value in last bin
+		}
 
-	// Very simple normality check
-	dist.IsNormal = math.Abs(skewness) < 0.5 && math.Abs(kurtosis) < 1.0
+		bins[i] = HistogramBin{
+			Min: binMin,
+			Max: binMax,
+		}
+	}
+	return bins
+}
 
-	// Determine distribution type
+// addToHistogram counts v in its bin of a histogram from histogramBins
+func addToHistogram(bins []HistogramBin, v, min, max float64) {
+	if len(bins) == 0 {
+		return
+	}
+	binIndex := int((v - min) / ((max - min) / 10))
+	if binIndex >= 10 {
+		binIndex = 9
+	}
+	bins[binIndex].Count++
+}
+
+// classifyDistribution sets the distribution type from the normality test, the
+// skewness and the peaks of the histogram
+func classifyDistribution(dist *DistributionInfo, skewness float64) {
 	if dist.IsNormal {
 		dist.DistType = "normal"
 	} else if math.Abs(skewness) > 1.0 {
@@ -1537,8 +1772,6 @@ func analyzeDistribution(data *FileData, colIdx int) DistributionInfo {
 			dist.DistType = "unknown"
 		}
 	}
-
-	return dist
 }
 
 // detectOutliers detects outliers using IQR and Z-score methods
@@ -1575,14 +1808,9 @@ func detectOutliers(data *FileData, colIdx int, stats ColumnStatistics) []Outlie
 					if *stats.StdDev > 0 {
 						zScore := math.Abs(num-*stats.Mean) / *stats.StdDev
 						if zScore > zThreshold {
-							// Only add if not already detected by IQR
-							alreadyDetected := false
-							for _, o := range outliers {
-								if o.RowIndex == rowIdx {
-									alreadyDetected = true
-									break
-								}
-							}
+							// Only add if not already detected by IQR, which appends
+							// the row just before
+							alreadyDetected := len(outliers) > 0 && outliers[len(outliers)-1].RowIndex == rowIdx
 							if !alreadyDetected {
 								outliers = append(outliers, OutlierInfo{
 									RowIndex: rowIdx,
@@ -1601,40 +1829,6 @@ func detectOutliers(data *FileData, colIdx int, stats ColumnStatistics) []Outlie
 	return outliers
 }
 
-// countDuplicateRows counts the number of duplicate rows in the dataset
-func countDuplicateRows(data *FileData) int {
-	rowMap := make(map[string]int)
-	duplicates := 0
-
-	for rowIdx := 0; rowIdx < data.Rows && rowIdx < len(data.Data); rowIdx++ {
-		// Create a key from the row data
-		rowKey := strings.Join(data.Data[rowIdx], "|")
-		rowMap[rowKey]++
-		if rowMap[rowKey] == 2 {
-			duplicates++ // Count the first duplicate
-		} else if rowMap[rowKey] > 2 {
-			duplicates++ // Count subsequent duplicates
-		}
-	}
-
-	return duplicates
-}
-
-// estimateMemorySize estimates the memory usage of the dataset
-func estimateMemorySize(data *FileData) string {
-	// Rough estimation: average 10 bytes per cell
-	bytes := data.Rows * data.Columns * 10
-
-	if bytes < 1024 {
-		return fmt.Sprintf("%d B", bytes)
-	} else if bytes < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
-	} else if bytes < 1024*1024*1024 {
-		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
-	}
-	return fmt.Sprintf("%.1f GB", float64(bytes)/(1024*1024*1024))
-}
-
 // calculateCorrelations calculates correlations between numeric columns
 func calculateCorrelations(data *FileData) map[string]map[string]float64 {
 	correlations := make(map[string]map[string]float64)
@@ -1720,8 +1914,103 @@ func calculatePearsonCorrelation(data *FileData, col1, col2 int) float64 {
 	return num / math.Sqrt(den1*den2)
 }
 
+// highCorrelationThreshold is the |r| above which columns are considered redundant
+const highCorrelationThreshold = 0.95
+
+// clusterCorrelatedColumns groups numeric columns by single-linkage clustering:
+// two columns end up in the same cluster if they are connected by a chain of
+// pairs with |r| > threshold. Only clusters with at least two columns are returned.
+func clusterCorrelatedColumns(correlations map[string]map[string]float64, columns []ColumnAnalysis, threshold float64) []CorrelationCluster {
+	// Sort names so clusters are reported in a stable order
+	names := make([]string, 0, len(correlations))
+	for name := range correlations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Union-find over column names
+	parent := make(map[string]string, len(names))
+	for _, name := range names {
+		parent[name] = name
+	}
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+
+	for i, col1 := range names {
+		for _, col2 := range names[i+1:] {
+			if math.Abs(correlations[col1][col2]) > threshold {
+				root1, root2 := find(col1), find(col2)
+				if root1 != root2 {
+					parent[root2] = root1
+				}
+			}
+		}
+	}
+
+	// Collect members per root, preserving sorted order
+	members := make(map[string][]string)
+	roots := []string{}
+	for _, name := range names {
+		root := find(name)
+		if _, exists := members[root]; !exists {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], name)
+	}
+
+	stdDevs := make(map[string]float64, len(columns))
+	for _, col := range columns {
+		if col.Stats.StdDev != nil {
+			stdDevs[col.Name] = *col.Stats.StdDev
+		}
+	}
+
+	clusters := []CorrelationCluster{}
+	for _, root := range roots {
+		cols := members[root]
+		if len(cols) < 2 {
+			continue
+		}
+
+		// The representative is the column carrying the most variance
+		representative := cols[0]
+		for _, col := range cols[1:] {
+			if stdDevs[col] > stdDevs[representative] {
+				representative = col
+			}
+		}
+
+		// Weakest link: for each column, its strongest correlation within the cluster
+		minCorr := 1.0
+		for _, col1 := range cols {
+			best := 0.0
+			for _, col2 := range cols {
+				if col1 != col2 && math.Abs(correlations[col1][col2]) > best {
+					best = math.Abs(correlations[col1][col2])
+				}
+			}
+			if best < minCorr {
+				minCorr = best
+			}
+		}
+
+		clusters = append(clusters, CorrelationCluster{
+			Columns:        cols,
+			Representative: representative,
+			MinCorrelation: minCorr,
+		})
+	}
+
+	return clusters
+}
+
 // generateQualityIssues generates quality issues based on the analysis
-func generateQualityIssues(report *DataQualityReport, correlations map[string]map[string]float64) []QualityIssue {
+func generateQualityIssues(report *DataQualityReport) []QualityIssue {
 	issues := []QualityIssue{}
 
 	// Check for high missing data
@@ -1780,19 +2069,16 @@ func generateQualityIssues(report *DataQualityReport, correlations map[string]ma
 		}
 	}
 
-	// Check for highly correlated variables
-	for col1, corrMap := range correlations {
-		for col2, corr := range corrMap {
-			if col1 < col2 && math.Abs(corr) > 0.95 {
-				issues = append(issues, QualityIssue{
-					Severity:    "warning",
-					Category:    "correlation",
-					Description: fmt.Sprintf("Columns '%s' and '%s' are highly correlated (r=%.3f)", col1, col2, corr),
-					Affected:    []string{col1, col2},
-					Impact:      "Highly correlated variables provide redundant information in PCA",
-				})
-			}
-		}
+	// Check for clusters of highly correlated variables
+	for _, cluster := range report.CorrelationClusters {
+		issues = append(issues, QualityIssue{
+			Severity: "warning",
+			Category: "correlation",
+			Description: fmt.Sprintf("%d columns are highly correlated with each other (|r|>%.2f): %s",
+				len(cluster.Columns), highCorrelationThreshold, strings.Join(cluster.Columns, ", ")),
+			Affected: cluster.Columns,
+			Impact:   "Highly correlated variables provide redundant information in PCA",
+		})
 	}
 
 	// Check for low variance columns
@@ -1827,8 +2113,9 @@ func generateQualityIssues(report *DataQualityReport, correlations map[string]ma
 	return issues
 }
 
-// generateRecommendations generates recommendations based on the quality analysis
-func generateRecommendations(report *DataQualityReport) []Recommendation {
+// generateRecommendations generates recommendations based on the quality analysis.
+// preprocessing holds the "method: rationale" suggestions from core.RecommendPreprocessing.
+func generateRecommendations(report *DataQualityReport, preprocessing []string) []Recommendation {
 	recs := []Recommendation{}
 
 	// Missing data recommendations
@@ -1868,23 +2155,14 @@ func generateRecommendations(report *DataQualityReport) []Recommendation {
 		})
 	}
 
-	// Scaling recommendation
-	varyingScales := false
-	for _, col := range report.ColumnAnalysis {
-		if col.Type == "numeric" && col.Stats.Min != nil && col.Stats.Max != nil {
-			range_ := *col.Stats.Max - *col.Stats.Min
-			if range_ > 1000 || range_ < 0.01 {
-				varyingScales = true
-				break
-			}
-		}
-	}
-	if varyingScales {
+	// Scaling recommendations, in the order the preprocessing steps are applied
+	for _, suggestion := range preprocessing {
+		method, rationale, _ := strings.Cut(suggestion, ": ")
 		recs = append(recs, Recommendation{
 			Priority:    "high",
 			Category:    "scaling",
-			Action:      "Scale numeric columns",
-			Description: "Columns have varying scales; consider standardization or normalization before PCA",
+			Action:      "Use " + method + " before PCA",
+			Description: rationale,
 		})
 	}
 
@@ -1905,7 +2183,25 @@ func generateRecommendations(report *DataQualityReport) []Recommendation {
 		})
 	}
 
-	// Correlation recommendations
+	// Redundant variable recommendations, one per correlation cluster
+	for _, cluster := range report.CorrelationClusters {
+		others := make([]string, 0, len(cluster.Columns)-1)
+		for _, col := range cluster.Columns {
+			if col != cluster.Representative {
+				others = append(others, col)
+			}
+		}
+		recs = append(recs, Recommendation{
+			Priority: "medium",
+			Category: "correlation",
+			Action:   "Reduce redundant variables",
+			Description: fmt.Sprintf("Keep '%s' (highest variance) and consider removing %s, which carry largely the same information",
+				cluster.Representative, strings.Join(others, ", ")),
+			Columns: cluster.Columns,
+		})
+	}
+
+	// Column count recommendations
 	if report.DataProfile.NumericColumns < 3 {
 		recs = append(recs, Recommendation{
 			Priority:    "high",
@@ -2081,38 +2377,17 @@ func (a *App) OpenInGoPCA(data *FileData) error {
 	tempFile := filepath.Join(tempDir, fmt.Sprintf("gocsv_export_%s.csv", timestamp))
 
 	// Write data to temp file
-	file, err := os.Create(tempFile)
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write headers with row name column if present
-	headers := data.Headers
-	if len(data.RowNames) > 0 {
-		headers = append([]string{"Row"}, headers...)
-	}
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write headers: %w", err)
-	}
-
-	// Write data rows
-	for i, row := range data.Data {
-		rowData := row
-		if len(data.RowNames) > 0 && i < len(data.RowNames) {
-			rowData = append([]string{data.RowNames[i]}, row...)
-		}
-		if err := writer.Write(rowData); err != nil {
-			return fmt.Errorf("failed to write row %d: %w", i+1, err)
-		}
+	csvData := &pkgcsv.Data{
+		Headers:    data.Headers,
+		RowNames:   data.RowNames,
+		StringData: exportRows(data),
+		Rows:       data.Rows,
+		Columns:    data.Columns,
 	}
-
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("failed to flush CSV writer: %w", err)
+	opts := pkgcsv.DefaultOptions()
+	opts.HasRowNames = len(data.RowNames) > 0
+	if err := pkgcsv.WriteFile(tempFile, csvData, opts); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
 	}
 
 	// Launch GoPCA with the file using the shared integration package
@@ -2133,6 +2408,77 @@ func (a *App) OpenInGoPCA(data *FileData) error {
 	return nil
 }
 
+// PCAPreview is a quick PCA of the numeric columns, for a preview in the editor
+type PCAPreview struct {
+	Components         int         `json:"components"`
+	ComponentLabels    []string    `json:"componentLabels"`
+	RowNames           []string    `json:"rowNames,omitempty"`
+	Scores             [][]float64 `json:"scores"`             // PC1 and PC2 of each row, or only PC1 for one component
+	ExplainedVariance  []float64   `json:"explainedVariance"`  // Percentage of the variance of each component
+	CumulativeVariance []float64   `json:"cumulativeVariance"` // Cumulative percentage
+	Columns            []string    `json:"columns"`            // Numeric columns analyzed
+	ExcludedColumns    []string    `json:"excludedColumns,omitempty"`
+	ImputedValues      int         `json:"imputedValues"` // Missing values replaced with column means
+}
+
+// RunQuickPCA runs PCA on the numeric columns of the data in-process, so that the
+// result can be previewed without GoPCA Desktop. Missing values are imputed with
+// column means, and the columns are centered and scaled to unit variance before an
+// SVD fit. Non-numeric, categorical and target columns are left out.
+func (a *App) RunQuickPCA(data *FileData, components int) (*PCAPreview, error) {
+	if data == nil || len(data.Data) == 0 {
+		return nil, fmt.Errorf("no data to analyze")
+	}
+	if components < 1 {
+		return nil, fmt.Errorf("number of components must be at least 1, got %d", components)
+	}
+
+	csvData := fileDataToCSVData(data)
+	if csvData.Columns == 0 {
+		return nil, fmt.Errorf("no numeric columns to analyze")
+	}
+	if csvData.Rows < 2 {
+		return nil, fmt.Errorf("need at least 2 rows, got %d", csvData.Rows)
+	}
+	if maxComponents := min(csvData.Rows-1, csvData.Columns); components > maxComponents {
+		return nil, fmt.Errorf("requested %d components, but %d rows × %d numeric columns support at most %d",
+			components, csvData.Rows, csvData.Columns, maxComponents)
+	}
+
+	missingInfo := csvData.GetMissingValueInfo(nil)
+	matrix, err := core.NewMissingValueHandler(types.MissingMean).HandleMissingValues(csvData.Matrix, missingInfo, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to impute missing values: %w", err)
+	}
+
+	config := types.PCAConfig{Components: components, Method: "svd", MeanCenter: true, StandardScale: true}
+	result, err := core.NewPCAEngine().Fit(matrix, config)
+	if err != nil {
+		return nil, fmt.Errorf("PCA failed: %w", err)
+	}
+
+	preview := &PCAPreview{
+		Components:         len(result.ComponentLabels),
+		ComponentLabels:    result.ComponentLabels,
+		RowNames:           csvData.RowNames,
+		Scores:             make([][]float64, len(result.Scores)),
+		ExplainedVariance:  result.ExplainedVarRatio,
+		CumulativeVariance: result.CumulativeVar,
+		Columns:            csvData.Headers,
+		ImputedValues:      missingInfo.TotalMissing,
+	}
+	shown := min(2, preview.Components)
+	for i, row := range result.Scores {
+		preview.Scores[i] = row[:shown]
+	}
+	for _, header := range data.Headers {
+		if data.ColumnTypes[header] != "numeric" {
+			preview.ExcludedColumns = append(preview.ExcludedColumns, header)
+		}
+	}
+	return preview, nil
+}
+
 // DownloadGoPCA opens the GoPCA download page in the default browser
 func (a *App) DownloadGoPCA() error {
 	url := "https://github.com/bitjungle/gopca/releases"
@@ -2226,8 +2572,8 @@ func (a *App) ExecuteHeaderEdit(data *FileData, col int, oldValue, newValue stri
 }
 
 // ExecuteFillMissingValues executes a fill missing values command
-func (a *App) ExecuteFillMissingValues(data *FileData, strategy, column, customValue string) (*FileData, error) {
-	cmd := NewFillMissingValuesCommand(a, data, strategy, column, customValue)
+func (a *App) ExecuteFillMissingValues(data *FileData, strategy, column, customValue, groupBy string) (*FileData, error) {
+	cmd := NewFillMissingValuesCommand(a, data, strategy, column, customValue, groupBy)
 	return a.executeCommand(cmd, data, "fill missing values")
 }
 
@@ -2254,6 +2600,7 @@ type ImportOptions struct {
 	SkipRows        int    `json:"skipRows"`                  // Number of rows to skip from top
 	MaxRows         int    `json:"maxRows"`                   // 0 for all rows
 	SelectedColumns []int  `json:"selectedColumns,omitempty"` // Indices of columns to import
+	IndexColumns    []int  `json:"indexColumns,omitempty"`    // Columns joined into composite row names, 0-based
 }
 
 // FilePreview represents a preview of file contents
@@ -2287,7 +2634,7 @@ func (a *App) GetFileInfo(filePath string) (*ImportFileInfo, error) {
 	case ".csv":
 		info.FileFormat = "csv"
 		info.Encoding = "UTF-8" // TODO: Detect encoding
-	case ".tsv":
+	case ".tsv", ".tab":
 		info.FileFormat = "tsv"
 		info.Encoding = "UTF-8"
 	case ".xlsx", ".xls":
@@ -2396,6 +2743,21 @@ func (a *App) detectFileFormat(filePath string) string {
 	return "csv"
 }
 
+// readImportRecords reads the remaining records of an import and repairs rows with
+// more or fewer fields than the first according to the ragged row policy, returning
+// one warning per repaired row
+func (a *App) readImportRecords(reader *csv.Reader) ([][]string, []string, error) {
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	records, warnings, err := types.RepairRaggedRows(records, a.raggedRows, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return records, warnings, nil
+}
+
 // previewCSV generates a preview of a CSV/TSV file
 func (a *App) previewCSV(filePath string, options ImportOptions, preview *FilePreview) (*FilePreview, error) {
 	file, err := os.Open(filePath)
@@ -2404,17 +2766,19 @@ func (a *App) previewCSV(filePath string, options ImportOptions, preview *FilePr
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-
 	// Set delimiter
+	delimiter := ','
 	if options.Format == "tsv" || options.Delimiter == "\t" {
-		reader.Comma = '\t'
+		delimiter = '\t'
 	} else if options.Delimiter != "" && len(options.Delimiter) == 1 {
-		reader.Comma = rune(options.Delimiter[0])
+		delimiter = rune(options.Delimiter[0])
 	}
 
+	reader := types.NewCSVReader(file, delimiter)
 	reader.LazyQuotes = true
-	reader.TrimLeadingSpace = true
+	if a.raggedRows != types.RaggedRowsError {
+		reader.FieldsPerRecord = -1
+	}
 
 	// Skip rows if specified
 	for i := 0; i < options.SkipRows; i++ {
@@ -2425,10 +2789,11 @@ func (a *App) previewCSV(filePath string, options ImportOptions, preview *FilePr
 	}
 
 	// Read all data for analysis
-	allData, err := reader.ReadAll()
+	allData, warnings, err := a.readImportRecords(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, err
 	}
+	preview.Issues = append(preview.Issues, warnings...)
 
 	if len(allData) == 0 {
 		return nil, fmt.Errorf("no data found in file")
@@ -2572,17 +2937,19 @@ func (a *App) importCSVWithOptions(filePath string, options ImportOptions) (*Fil
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-
 	// Set delimiter
+	delimiter := ','
 	if options.Format == "tsv" || options.Delimiter == "\t" {
-		reader.Comma = '\t'
+		delimiter = '\t'
 	} else if options.Delimiter != "" && len(options.Delimiter) == 1 {
-		reader.Comma = rune(options.Delimiter[0])
+		delimiter = rune(options.Delimiter[0])
 	}
 
+	reader := types.NewCSVReader(file, delimiter)
 	reader.LazyQuotes = true
-	reader.TrimLeadingSpace = true
+	if a.raggedRows != types.RaggedRowsError {
+		reader.FieldsPerRecord = -1
+	}
 
 	// Skip rows if specified
 	for i := 0; i < options.SkipRows; i++ {
@@ -2593,9 +2960,9 @@ func (a *App) importCSVWithOptions(filePath string, options ImportOptions) (*Fil
 	}
 
 	// Read all data
-	allData, err := reader.ReadAll()
+	allData, warnings, err := a.readImportRecords(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, err
 	}
 
 	if len(allData) == 0 {
@@ -2603,9 +2970,12 @@ func (a *App) importCSVWithOptions(filePath string, options ImportOptions) (*Fil
 	}
 
 	fileData := &FileData{
+		FileData: types.FileData{
+			ColumnTypes: make(map[string]string),
+		},
 		CategoricalColumns:   make(map[string][]string),
 		NumericTargetColumns: make(map[string][]types.JSONFloat64),
-		ColumnTypes:          make(map[string]string),
+		Warnings:             warnings,
 	}
 
 	// Extract headers
@@ -2639,6 +3009,14 @@ func (a *App) importCSVWithOptions(filePath string, options ImportOptions) (*Fil
 		}
 	}
 
+	// Join composite keys into row names
+	if len(options.IndexColumns) > 0 {
+		allData, err = applyIndexColumns(fileData, allData, &options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply column selection if specified
 	if len(options.SelectedColumns) > 0 {
 		// Filter headers
@@ -2697,6 +3075,8 @@ func (a *App) importCSVWithOptions(filePath string, options ImportOptions) (*Fil
 		}
 	}
 
+	updateColumnSubtypes(fileData)
+
 	// Emit file loaded event
 	wailsruntime.EventsEmit(a.ctx, "file-loaded", filepath.Base(filePath))
 
@@ -2706,6 +3086,62 @@ func (a *App) importCSVWithOptions(filePath string, options ImportOptions) (*Fil
 	return fileData, nil
 }
 
+// applyIndexColumns joins the index columns of each row into a row name, as
+// subjectA|visit1, and removes them from the headers and rows. Selected columns refer to
+// file columns, so they are remapped to the remaining columns; an index column cannot
+// also be selected as data.
+func applyIndexColumns(fileData *FileData, rows [][]string, options *ImportOptions) ([][]string, error) {
+	if options.RowNameColumn >= 0 {
+		return nil, fmt.Errorf("use either a row names column or index columns, not both")
+	}
+
+	isIndex := make(map[int]bool, len(options.IndexColumns))
+	for _, col := range options.IndexColumns {
+		if col < 0 || col >= len(fileData.Headers) {
+			return nil, fmt.Errorf("index column %d does not exist", col+1)
+		}
+		isIndex[col] = true
+	}
+	for _, col := range options.SelectedColumns {
+		if isIndex[col] {
+			return nil, fmt.Errorf("column %q is an index column and cannot also be selected as data", fileData.Headers[col])
+		}
+	}
+
+	// Excel rows omit trailing empty cells
+	for i, row := range rows {
+		for len(row) < len(fileData.Headers) {
+			row = append(row, "")
+		}
+		rows[i] = row
+	}
+
+	names, rest, err := pkgcsv.JoinIndexColumns(rows, options.IndexColumns, pkgcsv.DefaultIndexSeparator)
+	if err != nil {
+		return nil, err
+	}
+	_, headers, err := pkgcsv.JoinIndexColumns([][]string{fileData.Headers}, options.IndexColumns, pkgcsv.DefaultIndexSeparator)
+	if err != nil {
+		return nil, err
+	}
+	fileData.RowNames = names
+	fileData.Headers = headers[0]
+
+	selected := make([]int, len(options.SelectedColumns))
+	for i, col := range options.SelectedColumns {
+		shift := 0
+		for indexCol := range isIndex {
+			if indexCol < col {
+				shift++
+			}
+		}
+		selected[i] = col - shift
+	}
+	options.SelectedColumns = selected
+
+	return rest, nil
+}
+
 // importExcelWithOptions imports an Excel file with specific options
 func (a *App) importExcelWithOptions(filePath string, options ImportOptions) (*FileData, error) {
 	f, err := excelize.OpenFile(filePath)
@@ -2741,9 +3177,11 @@ func (a *App) importExcelWithOptions(filePath string, options ImportOptions) (*F
 	}
 
 	fileData := &FileData{
+		FileData: types.FileData{
+			ColumnTypes: make(map[string]string),
+		},
 		CategoricalColumns:   make(map[string][]string),
 		NumericTargetColumns: make(map[string][]types.JSONFloat64),
-		ColumnTypes:          make(map[string]string),
 	}
 
 	// Extract headers
@@ -2779,6 +3217,14 @@ func (a *App) importExcelWithOptions(filePath string, options ImportOptions) (*F
 		}
 	}
 
+	// Join composite keys into row names
+	if len(options.IndexColumns) > 0 {
+		rows, err = applyIndexColumns(fileData, rows, &options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply column selection if specified
 	if len(options.SelectedColumns) > 0 {
 		// Filter headers
@@ -2837,6 +3283,8 @@ func (a *App) importExcelWithOptions(filePath string, options ImportOptions) (*F
 		}
 	}
 
+	updateColumnSubtypes(fileData)
+
 	// Emit file loaded event
 	wailsruntime.EventsEmit(a.ctx, "file-loaded", filepath.Base(filePath))
 
@@ -3000,13 +3448,15 @@ func (a *App) applyTransformationInternal(data *FileData, options TransformOptio
 
 	// Create a copy of the data
 	newData := &FileData{
-		Headers:              make([]string, len(data.Headers)),
-		Data:                 make([][]string, len(data.Data)),
+		FileData: types.FileData{
+			Headers:     make([]string, len(data.Headers)),
+			Data:        make([][]string, len(data.Data)),
+			ColumnTypes: make(map[string]string),
+		},
 		Rows:                 data.Rows,
 		Columns:              data.Columns,
 		CategoricalColumns:   make(map[string][]string),
 		NumericTargetColumns: make(map[string][]types.JSONFloat64),
-		ColumnTypes:          make(map[string]string),
 	}
 
 	// Copy headers
@@ -3060,6 +3510,7 @@ func (a *App) applyTransformationInternal(data *FileData, options TransformOptio
 		return nil, fmt.Errorf("unsupported transformation type: %s", options.Type)
 	}
 
+	updateColumnSubtypes(newData)
 	result.Data = newData
 	return result, nil
 }
@@ -3565,6 +4016,18 @@ func (a *App) ExecuteToggleTargetColumn(data *FileData, colIndex int) (*FileData
 	return a.executeCommand(cmd, data, "toggle target column")
 }
 
+// ExecuteEncodeTargetColumn encodes a categorical column as a numeric #target column
+// with undo support. The encoding is "ordinal" (codes 0, 1, ... in sorted order of the
+// categories) or "onehot" (one 0/1 target column per category).
+func (a *App) ExecuteEncodeTargetColumn(data *FileData, colIndex int, encoding string) (*FileData, error) {
+	cmd, err := NewEncodeTargetColumnCommand(a, data, colIndex, TargetEncoding(encoding))
+	if err != nil {
+		return nil, fmt.Errorf("encode target column: %w", err)
+	}
+
+	return a.executeCommand(cmd, data, "encode target column")
+}
+
 // ExecuteDuplicateRows duplicates selected rows with undo support
 func (a *App) ExecuteDuplicateRows(data *FileData, rowIndices []int) (*FileData, error) {
 	if len(rowIndices) == 0 {
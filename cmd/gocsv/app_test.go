@@ -7,7 +7,16 @@
 package main
 
 import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/bitjungle/gopca/internal/datasets"
+	"github.com/bitjungle/gopca/pkg/types"
 )
 
 func TestAppMultiStepUndoRedo(t *testing.T) {
@@ -16,11 +25,13 @@ func TestAppMultiStepUndoRedo(t *testing.T) {
 
 	// Create test data
 	data := &FileData{
-		Headers: []string{"A", "B", "C"},
-		Data: [][]string{
-			{"1", "2", "3"},
-			{"4", "5", "6"},
-			{"7", "8", "9"},
+		FileData: types.FileData{
+			Headers: []string{"A", "B", "C"},
+			Data: [][]string{
+				{"1", "2", "3"},
+				{"4", "5", "6"},
+				{"7", "8", "9"},
+			},
 		},
 		Rows:    3,
 		Columns: 3,
@@ -141,19 +152,21 @@ func TestCommandHistoryWithMultipleOperations(t *testing.T) {
 
 	// Create test data
 	data := &FileData{
-		Headers: []string{"Name", "Age", "City"},
-		Data: [][]string{
-			{"Alice", "25", "NYC"},
-			{"Bob", "30", "LA"},
-			{"Charlie", "35", "Chicago"},
+		FileData: types.FileData{
+			Headers: []string{"Name", "Age", "City"},
+			Data: [][]string{
+				{"Alice", "25", "NYC"},
+				{"Bob", "30", "LA"},
+				{"Charlie", "35", "Chicago"},
+			},
+			ColumnTypes: map[string]string{
+				"Name": "text",
+				"Age":  "numeric",
+				"City": "text",
+			},
 		},
 		Rows:    3,
 		Columns: 3,
-		ColumnTypes: map[string]string{
-			"Name": "text",
-			"Age":  "numeric",
-			"City": "text",
-		},
 	}
 
 	// Set initial current data
@@ -228,3 +241,595 @@ func TestCommandHistoryWithMultipleOperations(t *testing.T) {
 		t.Errorf("Expected 2 rows after redo, got %d", len(dataRedo3.Data))
 	}
 }
+
+func TestAnalyzeDataQualityCorrelationClusters(t *testing.T) {
+	app := NewApp()
+
+	// Two independent latent signals, each copied (with tiny changes) into several columns,
+	// plus one unrelated column
+	headers := []string{"a1", "a2", "a3", "b1", "b2", "c"}
+	x := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	y := []float64{3, -1, 4, 1, -5, 9, 2, -6, 5, 3}
+	z := []float64{2, 7, 1, 8, 2, 8, 1, 8, 2, 8}
+	rows := make([][]string, len(x))
+	for i := range x {
+		rows[i] = []string{
+			fmt.Sprintf("%g", x[i]),
+			fmt.Sprintf("%g", 2*x[i]+0.01*float64(i%2)),
+			fmt.Sprintf("%g", 10*x[i]+1),
+			fmt.Sprintf("%g", y[i]),
+			fmt.Sprintf("%g", -3*y[i]+0.02*float64(i%3)),
+			fmt.Sprintf("%g", z[i]),
+		}
+	}
+
+	columnTypes := make(map[string]string)
+	for _, h := range headers {
+		columnTypes[h] = "numeric"
+	}
+
+	data := &FileData{
+		FileData: types.FileData{
+			Headers:     headers,
+			Data:        rows,
+			ColumnTypes: columnTypes,
+		},
+		Rows:    len(rows),
+		Columns: len(headers),
+	}
+
+	report, err := app.AnalyzeDataQuality(data)
+	if err != nil {
+		t.Fatalf("AnalyzeDataQuality failed: %v", err)
+	}
+
+	if len(report.CorrelationClusters) != 2 {
+		t.Fatalf("Expected 2 correlation clusters, got %d: %+v", len(report.CorrelationClusters), report.CorrelationClusters)
+	}
+
+	expected := []struct {
+		columns        []string
+		representative string
+	}{
+		{[]string{"a1", "a2", "a3"}, "a3"},
+		{[]string{"b1", "b2"}, "b2"},
+	}
+	for i, exp := range expected {
+		cluster := report.CorrelationClusters[i]
+		if strings.Join(cluster.Columns, ",") != strings.Join(exp.columns, ",") {
+			t.Errorf("Cluster %d: expected columns %v, got %v", i, exp.columns, cluster.Columns)
+		}
+		if cluster.Representative != exp.representative {
+			t.Errorf("Cluster %d: expected representative %s, got %s", i, exp.representative, cluster.Representative)
+		}
+	}
+
+	// Each cluster should be reported once, not once per pair
+	correlationIssues := 0
+	for _, issue := range report.Issues {
+		if issue.Category == "correlation" {
+			correlationIssues++
+		}
+	}
+	if correlationIssues != 2 {
+		t.Errorf("Expected 2 correlation issues (one per cluster), got %d", correlationIssues)
+	}
+
+	correlationRecs := 0
+	for _, rec := range report.Recommendations {
+		if rec.Category == "correlation" {
+			correlationRecs++
+		}
+	}
+	if correlationRecs != 2 {
+		t.Errorf("Expected 2 correlation recommendations (one per cluster), got %d", correlationRecs)
+	}
+}
+
+func TestValidateCellTypes(t *testing.T) {
+	app := NewApp()
+
+	// A numeric weight column with one stray unit, and a categorical species column
+	// with one stray number
+	weights := []string{"4.2", "5.1", "3.9", "5kg", "4.8", "5.5", "4.1", "4.4", "5.0", "4.7", "4.9", "NA"}
+	species := []string{"cat", "dog", "cat", "dog", "bird", "cat", "dog", "42", "bird", "cat", "dog", "cat"}
+	rows := make([][]string, len(weights))
+	for i := range weights {
+		rows[i] = []string{weights[i], species[i]}
+	}
+
+	data := &FileData{
+		FileData: types.FileData{
+			Headers: []string{"weight", "species"},
+			Data:    rows,
+			// As loaded, the stray unit makes the weight column look categorical
+			ColumnTypes: map[string]string{
+				"weight":  "categorical",
+				"species": "categorical",
+			},
+		},
+		Rows:    len(rows),
+		Columns: 2,
+	}
+
+	issues := app.ValidateCellTypes(data)
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %+v", len(issues), issues)
+	}
+
+	weight := issues[0]
+	if weight.Row != 3 || weight.Col != 0 || weight.Value != "5kg" || weight.ExpectedType != "numeric" {
+		t.Errorf("unexpected weight issue: %+v", weight)
+	}
+	if weight.SuggestedFix != "5" {
+		t.Errorf("expected suggested fix 5, got %q", weight.SuggestedFix)
+	}
+
+	stray := issues[1]
+	if stray.Row != 7 || stray.Col != 1 || stray.Value != "42" || stray.ExpectedType != "categorical" {
+		t.Errorf("unexpected species issue: %+v", stray)
+	}
+	if stray.SuggestedFix != "" {
+		t.Errorf("expected no suggested fix for a stray number, got %q", stray.SuggestedFix)
+	}
+
+	// Clean data has no issues
+	data.Data[3][0] = "5"
+	data.Data[7][1] = "cat"
+	if issues := app.ValidateCellTypes(data); len(issues) != 0 {
+		t.Errorf("expected no issues for clean data, got %+v", issues)
+	}
+}
+
+func TestValidateForGoPCA(t *testing.T) {
+	app := NewApp()
+
+	data := &FileData{
+		FileData: types.FileData{
+			Headers: []string{"x", "y", "group", "conc"},
+			Data: [][]string{
+				{"1.0", "2.0", "a", "0.5"},
+				{"2.0", "NA", "b", "0.7"},
+				{"3.0", "1.0", "a", "0.9"},
+			},
+			ColumnTypes: map[string]string{
+				"x":     "numeric",
+				"y":     "numeric",
+				"group": "categorical",
+				"conc":  "target",
+			},
+		},
+		Rows:    3,
+		Columns: 4,
+	}
+
+	report := app.ValidateForGoPCA(data)
+	if !report.Valid {
+		t.Fatalf("expected valid data, got issues %+v", report.Issues)
+	}
+	if report.NumericColumns != 2 || report.CategoricalColumns != 1 || report.TargetColumns != 1 {
+		t.Errorf("unexpected column counts: %+v", report)
+	}
+	if report.MissingValues != 1 {
+		t.Errorf("expected 1 missing value, got %d", report.MissingValues)
+	}
+
+	// Without a second numeric column the data cannot be used for PCA
+	data.ColumnTypes["y"] = "categorical"
+	report = app.ValidateForGoPCA(data)
+	if report.Valid || report.Count(types.SeverityError) != 1 {
+		t.Errorf("expected one error for too few numeric columns, got %+v", report.Issues)
+	}
+}
+
+func TestAnalyzeDataQualityScalingRecommendation(t *testing.T) {
+	app := NewApp()
+
+	// Comparable columns apart from one measured in much larger units
+	headers := []string{"a", "b", "c"}
+	rows := make([][]string, 20)
+	for i := range rows {
+		v := float64(i%7) - 3 + 0.1*float64(i)
+		rows[i] = []string{
+			fmt.Sprintf("%g", v),
+			fmt.Sprintf("%g", float64((i*3)%5)),
+			fmt.Sprintf("%g", 1000*float64((i*5)%9)),
+		}
+	}
+	columnTypes := map[string]string{"a": "numeric", "b": "numeric", "c": "numeric"}
+
+	report, err := app.AnalyzeDataQuality(&FileData{
+		FileData: types.FileData{
+			Headers:     headers,
+			Data:        rows,
+			ColumnTypes: columnTypes,
+		},
+		Rows:    len(rows),
+		Columns: len(headers),
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeDataQuality failed: %v", err)
+	}
+
+	var scaling []Recommendation
+	for _, rec := range report.Recommendations {
+		if rec.Category == "scaling" {
+			scaling = append(scaling, rec)
+		}
+	}
+	if len(scaling) != 1 || scaling[0].Action != "Use standard scaling before PCA" {
+		t.Errorf("Expected a standard scaling recommendation, got %+v", scaling)
+	}
+}
+
+func TestCalculateNumericStatsStreaming(t *testing.T) {
+	// Skewed values with some missing entries
+	rows := make([][]string, 5000)
+	for i := range rows {
+		if i%50 == 0 {
+			rows[i] = []string{"NA"}
+			continue
+		}
+		x := float64((i*7919)%1000) / 1000
+		rows[i] = []string{fmt.Sprintf("%g", x*x*100)}
+	}
+	data := &FileData{FileData: types.FileData{Headers: []string{"x"}, Data: rows}, Rows: len(rows), Columns: 1}
+
+	exact := calculateNumericStats(data, 0)
+	streaming := calculateNumericStatsStreaming(data, 0)
+
+	if !streaming.Approximate || exact.Approximate {
+		t.Errorf("Expected only the streaming statistics to be approximate")
+	}
+	if streaming.Missing != exact.Missing || streaming.Unique != exact.Unique {
+		t.Errorf("Counts differ: streaming missing=%d unique=%d, exact missing=%d unique=%d",
+			streaming.Missing, streaming.Unique, exact.Missing, exact.Unique)
+	}
+
+	checks := []struct {
+		name      string
+		got, want *float64
+		tolerance float64
+	}{
+		{"mean", streaming.Mean, exact.Mean, 1e-9},
+		{"stdDev", streaming.StdDev, exact.StdDev, 1e-9},
+		{"min", streaming.Min, exact.Min, 0},
+		{"max", streaming.Max, exact.Max, 0},
+		{"skewness", streaming.Skewness, exact.Skewness, 1e-6},
+		{"kurtosis", streaming.Kurtosis, exact.Kurtosis, 1e-6},
+		// Quartiles are estimates; allow 2% of the range
+		{"q1", streaming.Q1, exact.Q1, 2},
+		{"median", streaming.Median, exact.Median, 2},
+		{"q3", streaming.Q3, exact.Q3, 2},
+	}
+	for _, c := range checks {
+		if c.got == nil || c.want == nil {
+			t.Errorf("%s: missing value", c.name)
+			continue
+		}
+		if math.Abs(*c.got-*c.want) > c.tolerance {
+			t.Errorf("%s: streaming %g, exact %g", c.name, *c.got, *c.want)
+		}
+	}
+
+	// The histogram of the streaming pass matches the exact one, bin for bin
+	exactDist := analyzeDistribution(data, 0, exact, 0.05)
+	streamingDist := analyzeDistributionStreaming(data, 0, streaming, 0.05)
+	if len(streamingDist.Histogram) != len(exactDist.Histogram) {
+		t.Fatalf("expected %d histogram bins, got %d", len(exactDist.Histogram), len(streamingDist.Histogram))
+	}
+	for i, bin := range exactDist.Histogram {
+		if streamingDist.Histogram[i].Count != bin.Count {
+			t.Errorf("bin %d: streaming count %d, exact %d", i, streamingDist.Histogram[i].Count, bin.Count)
+		}
+	}
+	if streamingDist.DistType != exactDist.DistType {
+		t.Errorf("expected distribution type %q, got %q", exactDist.DistType, streamingDist.DistType)
+	}
+
+	// Distinct values are counted up to the limit
+	rows = make([][]string, 2*streamingDistinctLimit)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprint(i)}
+	}
+	data = &FileData{FileData: types.FileData{Headers: []string{"x"}, Data: rows}, Rows: len(rows), Columns: 1}
+	if unique := calculateNumericStatsStreaming(data, 0).Unique; unique != streamingDistinctLimit {
+		t.Errorf("expected the distinct count to stop at %d, got %d", streamingDistinctLimit, unique)
+	}
+}
+
+func TestAnalyzeDistributionNormalityTest(t *testing.T) {
+	// Shapiro-Wilk is used up to 5000 values and D'Agostino-Pearson above
+	for _, tc := range []struct {
+		rows int
+		want string
+	}{
+		{100, "Shapiro-Wilk"},
+		{6000, "D'Agostino-Pearson"},
+	} {
+		rows := make([][]string, tc.rows)
+		for i := range rows {
+			rows[i] = []string{fmt.Sprint((i * 7919) % 1000)}
+		}
+		data := &FileData{FileData: types.FileData{Headers: []string{"x"}, Data: rows}, Rows: len(rows), Columns: 1}
+
+		dist := analyzeDistribution(data, 0, calculateNumericStats(data, 0), 0.05)
+		if dist.NormalityTest != tc.want {
+			t.Errorf("%d rows: expected normality test %q, got %q", tc.rows, tc.want, dist.NormalityTest)
+		}
+	}
+}
+
+func TestApplyIndexColumns(t *testing.T) {
+	fileData := &FileData{FileData: types.FileData{Headers: []string{"subject", "x", "visit", "y"}}}
+	rows := [][]string{
+		{"subjectA", "1", "visit1", "2"},
+		{"subjectA", "3", "visit2"}, // Trailing empty cell omitted, as in Excel
+	}
+	options := ImportOptions{RowNameColumn: -1, IndexColumns: []int{0, 2}, SelectedColumns: []int{3}}
+
+	rest, err := applyIndexColumns(fileData, rows, &options)
+	if err != nil {
+		t.Fatalf("applyIndexColumns failed: %v", err)
+	}
+	if strings.Join(fileData.RowNames, ",") != "subjectA|visit1,subjectA|visit2" {
+		t.Errorf("Unexpected row names: %v", fileData.RowNames)
+	}
+	if strings.Join(fileData.Headers, ",") != "x,y" || len(rest[0]) != 2 || len(rest[1]) != 2 {
+		t.Errorf("Expected two data columns, got headers %v and rows %v", fileData.Headers, rest)
+	}
+	// File column 3 is column 1 once the index columns are removed
+	if len(options.SelectedColumns) != 1 || options.SelectedColumns[0] != 1 {
+		t.Errorf("Expected selected columns remapped to [1], got %v", options.SelectedColumns)
+	}
+
+	fileData = &FileData{FileData: types.FileData{Headers: []string{"subject", "x", "visit", "y"}}}
+	options = ImportOptions{RowNameColumn: -1, IndexColumns: []int{0, 2}, SelectedColumns: []int{0, 1}}
+	if _, err := applyIndexColumns(fileData, rows, &options); err == nil {
+		t.Error("Expected error when an index column is also selected as data")
+	}
+}
+
+func TestExportRowsIntegerColumns(t *testing.T) {
+	data := &FileData{
+		FileData: types.FileData{
+			Headers: []string{"id", "count", "weight", "label"},
+			Data: [][]string{
+				{"1", "5.0", "4.20", "a"},
+				{"2", "1e+06", "5.5", "b"},
+				{"3", "", "3.75", "c"},
+			},
+			ColumnTypes: map[string]string{
+				"id":     "numeric",
+				"count":  "target",
+				"weight": "numeric",
+				"label":  "categorical",
+			},
+		},
+		Rows:    3,
+		Columns: 4,
+	}
+
+	rows := exportRows(data)
+	if data.ColumnSubtypes["id"] != columnSubtypeInteger || data.ColumnSubtypes["count"] != columnSubtypeInteger {
+		t.Errorf("expected id and count to be integer columns, got %v", data.ColumnSubtypes)
+	}
+	if _, ok := data.ColumnSubtypes["weight"]; ok {
+		t.Errorf("expected weight to have no subtype, got %v", data.ColumnSubtypes)
+	}
+
+	want := [][]string{
+		{"1", "5", "4.20", "a"},
+		{"2", "1000000", "5.5", "b"},
+		{"3", "", "3.75", "c"},
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d: expected %v, got %v", i, want[i], rows[i])
+		}
+	}
+	if data.Data[0][1] != "5.0" {
+		t.Errorf("expected the grid data to be unchanged, got %q", data.Data[0][1])
+	}
+
+	// A fractional value entered later removes the integer subtype
+	data.Data[1][0] = "2.5"
+	rows = exportRows(data)
+	if data.ColumnSubtypes["id"] == columnSubtypeInteger || rows[1][0] != "2.5" {
+		t.Errorf("expected id to lose the integer subtype, got %v and %q", data.ColumnSubtypes, rows[1][0])
+	}
+}
+
+func TestParseBestCSVFormat(t *testing.T) {
+	// Semicolon-separated spectra with decimal commas, including in the numeric
+	// headers. Split at the commas, every row has four fields, so the file also parses
+	// as comma-separated: into mostly categorical columns such as "5;401".
+	content := "id;400,5;401,5;402,5\n" +
+		"s1;0,12;0,34;0,56\n" +
+		"s2;0,22;0,44;0,66\n" +
+		"s3;0,32;0,54;0,76\n"
+
+	comma := types.DefaultCSVFormat()
+	semicolon := comma
+	semicolon.FieldDelimiter = ';'
+	semicolon.DecimalSeparator = ','
+
+	// The first format that parses is the wrong one
+	if data, _, _, err := types.ParseCSVMixedWithTargets(strings.NewReader(content), comma, nil); err != nil || data.Columns == 0 {
+		t.Fatalf("expected the content to parse as comma-separated too, got %v", err)
+	}
+
+	parsed, confidence, err := parseBestCSVFormat(content, []types.CSVFormat{comma, semicolon})
+	if err != nil {
+		t.Fatalf("parseBestCSVFormat failed: %v", err)
+	}
+	if parsed.format.FieldDelimiter != ';' || parsed.format.DecimalSeparator != ',' {
+		t.Fatalf("expected the semicolon format, got delimiter %q and decimal %q",
+			parsed.format.FieldDelimiter, parsed.format.DecimalSeparator)
+	}
+	if want := []string{"400,5", "401,5", "402,5"}; strings.Join(parsed.data.Headers, "|") != strings.Join(want, "|") {
+		t.Errorf("expected headers %v, got %v", want, parsed.data.Headers)
+	}
+	if parsed.data.Matrix[1][2] != 0.66 {
+		t.Errorf("expected 0.66 in row 2, column 3, got %v", parsed.data.Matrix[1][2])
+	}
+	if !(confidence > 0.5 && confidence < 1) {
+		t.Errorf("expected a confidence between 0.5 and 1 with two formats parsing, got %v", confidence)
+	}
+
+	// A plain comma-separated file is unambiguous
+	parsed, confidence, err = parseBestCSVFormat("id,a,b\nr1,1.5,2\nr2,3,4.5\n", []types.CSVFormat{comma, semicolon})
+	if err != nil {
+		t.Fatalf("parseBestCSVFormat failed: %v", err)
+	}
+	if parsed.format.FieldDelimiter != ',' || parsed.data.Columns != 2 || confidence != 1 {
+		t.Errorf("expected the comma format with confidence 1, got %q, %d columns, %v",
+			parsed.format.FieldDelimiter, parsed.data.Columns, confidence)
+	}
+
+	if _, _, err := parseBestCSVFormat("", []types.CSVFormat{comma, semicolon}); err == nil {
+		t.Error("expected an error for empty content")
+	}
+}
+
+// TestFillMissingValuesByGroup tests that group-wise fills use the statistic of the
+// row's group, and of all rows for a group without observed values
+func TestFillMissingValuesByGroup(t *testing.T) {
+	data := &FileData{
+		FileData: types.FileData{
+			Headers: []string{"group", "x", "label"},
+			Data: [][]string{
+				{"a", "1", "p"},
+				{"a", "", "NA"},
+				{"a", "3", "p"},
+				{"b", "10", "q"},
+				{"b", "NA", ""},
+				{"b", "30", "q"},
+				{"c", "", ""},
+				{"", "", "q"},
+			},
+			ColumnTypes: map[string]string{"group": "categorical", "x": "numeric", "label": "categorical"},
+		},
+		Rows:    8,
+		Columns: 3,
+	}
+
+	app := NewApp()
+	result, err := app.FillMissingValues(data, FillMissingValuesRequest{Strategy: "mean", Column: "x", GroupBy: "group"})
+	if err != nil {
+		t.Fatalf("FillMissingValues failed: %v", err)
+	}
+	// Group means 2 and 20, and 11 over all rows for group c and the row without a group
+	for row, want := range map[int]string{1: "2", 4: "20", 6: "11", 7: "11"} {
+		if got := result.Data[row][1]; got != want {
+			t.Errorf("row %d: expected %s, got %q", row, want, got)
+		}
+	}
+	if data.Data[1][1] != "" {
+		t.Error("expected the input data to be unchanged")
+	}
+
+	result, err = app.FillMissingValues(data, FillMissingValuesRequest{Strategy: "mode", Column: "label", GroupBy: "group"})
+	if err != nil {
+		t.Fatalf("FillMissingValues failed: %v", err)
+	}
+	// Mode p of group a, q of group b and q over all rows for group c
+	for row, want := range map[int]string{1: "p", 4: "q", 6: "q"} {
+		if got := result.Data[row][2]; got != want {
+			t.Errorf("row %d: expected %s, got %q", row, want, got)
+		}
+	}
+
+	if _, err := app.FillMissingValues(data, FillMissingValuesRequest{Strategy: "forward", GroupBy: "group"}); err == nil {
+		t.Error("expected an error for a group-wise forward fill")
+	}
+	if _, err := app.FillMissingValues(data, FillMissingValuesRequest{Strategy: "mean", GroupBy: "missing"}); err == nil {
+		t.Error("expected an error for an unknown group column")
+	}
+}
+
+func TestRunQuickPCA(t *testing.T) {
+	content, ok := datasets.GetDataset("iris.csv")
+	if !ok {
+		t.Fatal("embedded iris dataset not found")
+	}
+	records, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse iris: %v", err)
+	}
+	data := &FileData{FileData: types.FileData{Headers: records[0][1:], ColumnTypes: map[string]string{}}, Columns: len(records[0]) - 1}
+	for _, record := range records[1:] {
+		data.RowNames = append(data.RowNames, record[0])
+		data.Data = append(data.Data, record[1:])
+	}
+	data.Rows = len(data.Data)
+	for _, header := range data.Headers {
+		data.ColumnTypes[header] = "numeric"
+	}
+	data.ColumnTypes["species"] = "categorical"
+	data.Data[0][2] = "" // One missing petal length, imputed with the column mean
+
+	app := NewApp()
+	preview, err := app.RunQuickPCA(data, 2)
+	if err != nil {
+		t.Fatalf("RunQuickPCA failed: %v", err)
+	}
+	if preview.Components != 2 || len(preview.ExplainedVariance) != 2 {
+		t.Fatalf("expected 2 components, got %d with %d variances", preview.Components, len(preview.ExplainedVariance))
+	}
+	if len(preview.Scores) != 150 || len(preview.Scores[0]) != 2 || len(preview.RowNames) != 150 {
+		t.Errorf("expected 150×2 scores with row names, got %d rows of %d and %d names",
+			len(preview.Scores), len(preview.Scores[0]), len(preview.RowNames))
+	}
+	// The standardized iris measurements have about 73% and 23% of the variance on PC1 and PC2
+	if v := preview.ExplainedVariance[0]; v < 65 || v > 80 {
+		t.Errorf("expected PC1 to explain about 73%% of the variance, got %.1f%%", v)
+	}
+	if v := preview.CumulativeVariance[1]; v < 90 || v > 100 {
+		t.Errorf("expected PC1 and PC2 to explain over 90%% of the variance, got %.1f%%", v)
+	}
+	if len(preview.Columns) != 4 || fmt.Sprint(preview.ExcludedColumns) != "[species]" {
+		t.Errorf("expected the 4 measurements analyzed and species excluded, got %v and %v",
+			preview.Columns, preview.ExcludedColumns)
+	}
+	if preview.ImputedValues != 1 {
+		t.Errorf("expected 1 imputed value, got %d", preview.ImputedValues)
+	}
+
+	for _, components := range []int{0, 5} {
+		if _, err := app.RunQuickPCA(data, components); err == nil {
+			t.Errorf("expected an error for %d components", components)
+		}
+	}
+}
+
+func TestPreviewFileRaggedRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ragged.csv")
+	if err := os.WriteFile(path, []byte("id,x,y\na,1,2\nb,3\nc,5,6\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	options := ImportOptions{Format: "csv", Delimiter: ",", HasHeaders: true, RowNameColumn: -1}
+
+	app := NewApp()
+	if _, err := app.PreviewFile(path, options); err == nil {
+		t.Error("expected an error for a short row with the default policy")
+	}
+
+	if err := app.SetRaggedRows("pad"); err != nil {
+		t.Fatalf("SetRaggedRows failed: %v", err)
+	}
+	preview, err := app.PreviewFile(path, options)
+	if err != nil {
+		t.Fatalf("PreviewFile failed: %v", err)
+	}
+	if len(preview.Data) != 3 || len(preview.Data[1]) != 3 || preview.Data[1][2] != "" {
+		t.Errorf("expected the short row padded with a missing value, got %v", preview.Data)
+	}
+	if len(preview.Issues) != 1 || !strings.Contains(preview.Issues[0], "row 3 has 2 fields") {
+		t.Errorf("expected one warning about row 3, got %v", preview.Issues)
+	}
+
+	if err := app.SetRaggedRows("stretch"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -8,7 +8,10 @@ package main
 
 import (
 	"fmt"
+	"math"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bitjungle/gopca/pkg/types"
@@ -76,6 +79,14 @@ func deepCopyFileData(data *FileData) *FileData {
 		}
 	}
 
+	// Deep copy column subtypes map
+	if data.ColumnSubtypes != nil {
+		copied.ColumnSubtypes = make(map[string]string)
+		for k, v := range data.ColumnSubtypes {
+			copied.ColumnSubtypes[k] = v
+		}
+	}
+
 	return copied
 }
 
@@ -103,6 +114,7 @@ type CommandHistory struct {
 	commands []Command // Linear history of executed commands
 	current  int       // Index of the last executed command (-1 if none)
 	maxSize  int       // Maximum number of commands to keep in history
+	trimmed  bool      // Whether the oldest commands were dropped to stay within maxSize
 }
 
 // NewCommandHistory creates a new command history with a maximum size
@@ -137,6 +149,7 @@ func (h *CommandHistory) Execute(cmd Command, data *FileData) error {
 	if len(h.commands) > h.maxSize {
 		h.commands = h.commands[1:]
 		h.current--
+		h.trimmed = true
 	}
 
 	return nil
@@ -192,10 +205,18 @@ func (h *CommandHistory) GetHistory() ([]string, int) {
 	return descriptions, h.current
 }
 
+// Applied returns the commands that are currently applied to the data, oldest first,
+// leaving out undone commands. complete is false when older commands were dropped
+// from the history.
+func (h *CommandHistory) Applied() (commands []Command, complete bool) {
+	return h.commands[:h.current+1], !h.trimmed
+}
+
 // Clear clears the command history
 func (h *CommandHistory) Clear() {
 	h.commands = make([]Command, 0)
 	h.current = -1
+	h.trimmed = false
 }
 
 // CellEditCommand represents an edit to a single cell
@@ -347,16 +368,18 @@ type FillMissingValuesCommand struct {
 	strategy    string
 	column      string
 	customValue string
+	groupBy     string
 }
 
 // NewFillMissingValuesCommand creates a new fill missing values command
-func NewFillMissingValuesCommand(app *App, data *FileData, strategy, column, customValue string) *FillMissingValuesCommand {
+func NewFillMissingValuesCommand(app *App, data *FileData, strategy, column, customValue, groupBy string) *FillMissingValuesCommand {
 	return &FillMissingValuesCommand{
 		app:         app,
 		oldData:     deepCopyFileData(data),
 		strategy:    strategy,
 		column:      column,
 		customValue: customValue,
+		groupBy:     groupBy,
 	}
 }
 
@@ -370,6 +393,7 @@ func (c *FillMissingValuesCommand) Execute(data *FileData) error {
 		Strategy: c.strategy,
 		Column:   c.column,
 		Value:    c.customValue,
+		GroupBy:  c.groupBy,
 	}
 
 	newData, err := c.app.FillMissingValues(data, request)
@@ -400,11 +424,18 @@ func (c *FillMissingValuesCommand) Undo(data *FileData) error {
 	data.CategoricalColumns = c.oldData.CategoricalColumns
 	data.NumericTargetColumns = c.oldData.NumericTargetColumns
 	data.ColumnTypes = c.oldData.ColumnTypes
+	data.ColumnSubtypes = c.oldData.ColumnSubtypes
 	return nil
 }
 
 // GetDescription returns a description of the command
 func (c *FillMissingValuesCommand) GetDescription() string {
+	if c.groupBy != "" {
+		if c.column == "" {
+			return fmt.Sprintf("Fill missing values (all columns) with %s by '%s'", c.strategy, c.groupBy)
+		}
+		return fmt.Sprintf("Fill missing values in '%s' with %s by '%s'", c.column, c.strategy, c.groupBy)
+	}
 	if c.column == "" {
 		return fmt.Sprintf("Fill missing values (all columns) with %s", c.strategy)
 	}
@@ -1024,6 +1055,176 @@ func (c *ToggleTargetColumnCommand) GetDescription() string {
 	return fmt.Sprintf("Mark '%s' as target column", c.oldName)
 }
 
+// TargetEncoding is the way a categorical column is encoded as a numeric target
+type TargetEncoding string
+
+const (
+	// TargetEncodingOrdinal replaces the categories with the codes 0, 1, ... in sorted order
+	TargetEncodingOrdinal TargetEncoding = "ordinal"
+	// TargetEncodingOneHot replaces the column with one 0/1 target column per category
+	TargetEncodingOneHot TargetEncoding = "onehot"
+)
+
+// maxTargetCategories is the largest number of categories a column may have to be
+// encoded as a target, as for one-hot encoding
+const maxTargetCategories = 20
+
+// EncodeTargetColumnCommand represents encoding a categorical column as a numeric
+// #target column, so that it can be used for eigencorrelations
+type EncodeTargetColumnCommand struct {
+	app        *App
+	oldData    *FileData
+	colIndex   int
+	encoding   TargetEncoding
+	categories []string
+}
+
+// NewEncodeTargetColumnCommand creates a new encode target column command. The column
+// must be categorical with between 2 and maxTargetCategories categories.
+func NewEncodeTargetColumnCommand(app *App, data *FileData, colIndex int,
+	encoding TargetEncoding) (*EncodeTargetColumnCommand, error) {
+	if colIndex < 0 || colIndex >= len(data.Headers) {
+		return nil, fmt.Errorf("invalid column index: %d", colIndex)
+	}
+	if encoding != TargetEncodingOrdinal && encoding != TargetEncodingOneHot {
+		return nil, fmt.Errorf("invalid target encoding %q: must be ordinal or onehot", encoding)
+	}
+	name := data.Headers[colIndex]
+	if data.ColumnTypes[name] != "categorical" {
+		return nil, fmt.Errorf("column '%s' is not categorical", name)
+	}
+
+	unique := make(map[string]bool)
+	for _, row := range data.Data {
+		if colIndex < len(row) && !isMissingValue(row[colIndex]) {
+			unique[strings.TrimSpace(row[colIndex])] = true
+		}
+	}
+	if len(unique) < 2 {
+		return nil, fmt.Errorf("column '%s' has %d categories; a target needs at least 2", name, len(unique))
+	}
+	if len(unique) > maxTargetCategories {
+		return nil, fmt.Errorf("column '%s' has too many categories (%d, at most %d)",
+			name, len(unique), maxTargetCategories)
+	}
+	categories := make([]string, 0, len(unique))
+	for category := range unique {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	return &EncodeTargetColumnCommand{
+		app:        app,
+		oldData:    deepCopyFileData(data),
+		colIndex:   colIndex,
+		encoding:   encoding,
+		categories: categories,
+	}, nil
+}
+
+// Execute replaces the categorical column with its encoded target columns. Missing
+// values stay missing.
+func (c *EncodeTargetColumnCommand) Execute(data *FileData) error {
+	name := data.Headers[c.colIndex]
+	codes := make(map[string]int, len(c.categories))
+	for k, category := range c.categories {
+		codes[category] = k
+	}
+
+	// Each new column holds, per row, the value for one category (one-hot) or the code
+	var newHeaders []string
+	if c.encoding == TargetEncodingOrdinal {
+		newHeaders = []string{name + "#target"}
+	} else {
+		for _, category := range c.categories {
+			newHeaders = append(newHeaders, fmt.Sprintf("%s_%s#target", name, category))
+		}
+	}
+	newColumns := make([][]string, len(newHeaders))
+	for j := range newColumns {
+		newColumns[j] = make([]string, len(data.Data))
+	}
+	for i, row := range data.Data {
+		value := ""
+		if c.colIndex < len(row) {
+			value = row[c.colIndex]
+		}
+		code, known := codes[strings.TrimSpace(value)]
+		for j := range newColumns {
+			switch {
+			case isMissingValue(value) || !known:
+				newColumns[j][i] = ""
+			case c.encoding == TargetEncodingOrdinal:
+				newColumns[j][i] = strconv.Itoa(code)
+			case code == j:
+				newColumns[j][i] = "1"
+			default:
+				newColumns[j][i] = "0"
+			}
+		}
+	}
+
+	for i, row := range data.Data {
+		if c.colIndex >= len(row) {
+			continue
+		}
+		cells := make([]string, len(newColumns))
+		for j := range newColumns {
+			cells[j] = newColumns[j][i]
+		}
+		data.Data[i] = slices.Concat(row[:c.colIndex], cells, row[c.colIndex+1:])
+	}
+	data.Headers = slices.Concat(data.Headers[:c.colIndex], newHeaders, data.Headers[c.colIndex+1:])
+	data.Columns = len(data.Headers)
+
+	if data.ColumnTypes == nil {
+		data.ColumnTypes = make(map[string]string)
+	}
+	if data.NumericTargetColumns == nil {
+		data.NumericTargetColumns = make(map[string][]types.JSONFloat64)
+	}
+	delete(data.ColumnTypes, name)
+	delete(data.CategoricalColumns, name)
+	for j, header := range newHeaders {
+		data.ColumnTypes[header] = "target"
+		values := make([]types.JSONFloat64, len(newColumns[j]))
+		for i, cell := range newColumns[j] {
+			values[i] = types.JSONFloat64(math.NaN())
+			if v, ok := parseNumericValue(cell); ok {
+				values[i] = types.JSONFloat64(v)
+			}
+		}
+		data.NumericTargetColumns[header] = values
+	}
+	updateColumnSubtypes(data)
+
+	return nil
+}
+
+// Undo restores the categorical column
+func (c *EncodeTargetColumnCommand) Undo(data *FileData) error {
+	restored := deepCopyFileData(c.oldData)
+	data.Headers = restored.Headers
+	data.RowNames = restored.RowNames
+	data.Data = restored.Data
+	data.Rows = restored.Rows
+	data.Columns = restored.Columns
+	data.CategoricalColumns = restored.CategoricalColumns
+	data.NumericTargetColumns = restored.NumericTargetColumns
+	data.ColumnTypes = restored.ColumnTypes
+	data.ColumnSubtypes = restored.ColumnSubtypes
+	return nil
+}
+
+// GetDescription returns a description of the command
+func (c *EncodeTargetColumnCommand) GetDescription() string {
+	name := c.oldData.Headers[c.colIndex]
+	if c.encoding == TargetEncodingOneHot {
+		return fmt.Sprintf("Encode '%s' as %d one-hot target columns", name, len(c.categories))
+	}
+	return fmt.Sprintf("Encode '%s' as ordinal target column", name)
+}
+
 // DuplicateRowCommand represents duplication of one or more rows
 type DuplicateRowCommand struct {
 	app                *App
@@ -1157,6 +1358,7 @@ func (c *TransformCommand) Execute(data *FileData) error {
 		data.CategoricalColumns = result.Data.CategoricalColumns
 		data.NumericTargetColumns = result.Data.NumericTargetColumns
 		data.ColumnTypes = result.Data.ColumnTypes
+		data.ColumnSubtypes = result.Data.ColumnSubtypes
 	}
 
 	return nil
@@ -1173,6 +1375,7 @@ func (c *TransformCommand) Undo(data *FileData) error {
 	data.CategoricalColumns = c.oldData.CategoricalColumns
 	data.NumericTargetColumns = c.oldData.NumericTargetColumns
 	data.ColumnTypes = c.oldData.ColumnTypes
+	data.ColumnSubtypes = c.oldData.ColumnSubtypes
 	return nil
 }
 
@@ -7,22 +7,29 @@
 package main
 
 import (
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
 )
 
 // TestMultiStepUndoRedo tests that multiple commands can be undone and redone
 func TestMultiStepUndoRedo(t *testing.T) {
 	// Create test data
 	data := &FileData{
-		Headers:  []string{"Col1", "Col2", "Col3"},
-		RowNames: []string{"Row1", "Row2"},
-		Data: [][]string{
-			{"1", "2", "3"},
-			{"4", "5", "6"},
+		FileData: types.FileData{
+			Headers:  []string{"Col1", "Col2", "Col3"},
+			RowNames: []string{"Row1", "Row2"},
+			Data: [][]string{
+				{"1", "2", "3"},
+				{"4", "5", "6"},
+			},
+			ColumnTypes: map[string]string{"Col1": "numeric", "Col2": "numeric", "Col3": "numeric"},
 		},
-		Rows:        2,
-		Columns:     3,
-		ColumnTypes: map[string]string{"Col1": "numeric", "Col2": "numeric", "Col3": "numeric"},
+		Rows:    2,
+		Columns: 3,
 	}
 
 	// Create command history
@@ -198,8 +205,10 @@ func TestMultiStepUndoRedo(t *testing.T) {
 func TestUndoRedoState(t *testing.T) {
 	history := NewCommandHistory(10)
 	data := &FileData{
-		Headers: []string{"Col1"},
-		Data:    [][]string{{"1"}},
+		FileData: types.FileData{
+			Headers: []string{"Col1"},
+			Data:    [][]string{{"1"}},
+		},
 		Rows:    1,
 		Columns: 1,
 	}
@@ -246,3 +255,98 @@ func TestUndoRedoState(t *testing.T) {
 		t.Error("Should not be able to redo after redoing all")
 	}
 }
+
+// TestEncodeTargetColumn tests encoding a two-category column as a 0/1 target and undoing it
+func TestEncodeTargetColumn(t *testing.T) {
+	data := &FileData{
+		FileData: types.FileData{
+			Headers:  []string{"x", "group", "y"},
+			RowNames: []string{"r1", "r2", "r3", "r4"},
+			Data: [][]string{
+				{"1", "control", "5"},
+				{"2", "treated", "6"},
+				{"3", "", "7"},
+				{"4", "treated", "8"},
+			},
+			ColumnTypes: map[string]string{"x": "numeric", "group": "categorical", "y": "numeric"},
+		},
+		Rows:               4,
+		Columns:            3,
+		CategoricalColumns: map[string][]string{"group": {"control", "treated", "", "treated"}},
+	}
+	original := deepCopyFileData(data)
+	history := NewCommandHistory(10)
+
+	cmd, err := NewEncodeTargetColumnCommand(nil, data, 1, TargetEncodingOrdinal)
+	if err != nil {
+		t.Fatalf("Failed to create command: %v", err)
+	}
+	if err := history.Execute(cmd, data); err != nil {
+		t.Fatalf("Failed to encode target column: %v", err)
+	}
+
+	if got := strings.Join(data.Headers, ","); got != "x,group#target,y" {
+		t.Errorf("Expected headers x,group#target,y, got %s", got)
+	}
+	var column []string
+	for _, row := range data.Data {
+		column = append(column, row[1])
+	}
+	if got := strings.Join(column, ","); got != "0,1,,1" {
+		t.Errorf("Expected encoded values 0,1,,1, got %s", got)
+	}
+	if data.ColumnTypes["group#target"] != "target" {
+		t.Errorf("Expected column type target, got %q", data.ColumnTypes["group#target"])
+	}
+	if _, ok := data.CategoricalColumns["group"]; ok {
+		t.Error("Expected group to be removed from the categorical columns")
+	}
+	if values := data.NumericTargetColumns["group#target"]; len(values) != 4 || values[1] != 1 || !math.IsNaN(float64(values[2])) {
+		t.Errorf("Unexpected numeric target values %v", values)
+	}
+
+	if err := history.Undo(data); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if !reflect.DeepEqual(data.Headers, original.Headers) || !reflect.DeepEqual(data.Data, original.Data) ||
+		!reflect.DeepEqual(data.ColumnTypes, original.ColumnTypes) ||
+		!reflect.DeepEqual(data.CategoricalColumns, original.CategoricalColumns) {
+		t.Errorf("Undo did not restore the original data: %+v", data)
+	}
+
+	// One-hot encoding replaces the column with one target column per category
+	if err := history.Redo(data); err != nil {
+		t.Fatalf("Failed to redo: %v", err)
+	}
+	if err := history.Undo(data); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	cmd, err = NewEncodeTargetColumnCommand(nil, data, 1, TargetEncodingOneHot)
+	if err != nil {
+		t.Fatalf("Failed to create one-hot command: %v", err)
+	}
+	if err := history.Execute(cmd, data); err != nil {
+		t.Fatalf("Failed to one-hot encode target column: %v", err)
+	}
+	if got := strings.Join(data.Headers, ","); got != "x,group_control#target,group_treated#target,y" {
+		t.Errorf("Unexpected one-hot headers %s", got)
+	}
+	if got := strings.Join(data.Data[1], ","); got != "2,0,1,6" {
+		t.Errorf("Expected row 2,0,1,6, got %s", got)
+	}
+
+	// Only categorical columns with a usable number of categories can be encoded
+	if _, err := NewEncodeTargetColumnCommand(nil, original, 0, TargetEncodingOrdinal); err == nil {
+		t.Error("Expected an error for a numeric column")
+	}
+	single := deepCopyFileData(original)
+	for _, row := range single.Data {
+		row[1] = "control"
+	}
+	if _, err := NewEncodeTargetColumnCommand(nil, single, 1, TargetEncodingOrdinal); err == nil {
+		t.Error("Expected an error for a column with one category")
+	}
+	if _, err := NewEncodeTargetColumnCommand(nil, original, 1, "binary"); err == nil {
+		t.Error("Expected an error for an unknown encoding")
+	}
+}
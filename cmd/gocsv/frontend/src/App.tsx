@@ -6,11 +6,11 @@
 
 import React, { useState, useRef, useEffect } from 'react';
 import './App.css';
-import { CSVGrid, ValidationResults, MissingValueSummary, MissingValueDialog, DataQualityDashboard, UndoRedoControls, ImportWizard, DataTransformDialog, DocumentationViewer } from './components';
+import { CSVGrid, ValidationResults, ValidationIssue, MissingValueSummary, MissingValueDialog, DataQualityDashboard, UndoRedoControls, ImportWizard, DataTransformDialog, DocumentationViewer, QuickPCAPreview } from './components';
 import { ConfirmDialog } from '@gopca/ui-components';
 import { ThemeProvider, ThemeToggle } from '@gopca/ui-components';
 import logo from './assets/images/GoCSV-logo-1024-transp.png';
-import { LoadCSV, SaveCSV, SaveExcel, ValidateForGoPCA, AnalyzeMissingValues, FillMissingValues, AnalyzeDataQuality, CheckGoPCAStatus, OpenInGoPCA, DownloadGoPCA, ExecuteCellEdit, ExecuteHeaderEdit, ExecuteFillMissingValues, ClearHistory, GetVersion } from '../wailsjs/go/main/App';
+import { LoadCSV, SaveCSV, SaveExcel, ValidateForGoPCA, AnalyzeMissingValues, FillMissingValues, AnalyzeDataQuality, CheckGoPCAStatus, OpenInGoPCA, DownloadGoPCA, ExecuteCellEdit, ExecuteHeaderEdit, ExecuteFillMissingValues, ClearHistory, GetVersion, RunQuickPCA, SetNormalityAlpha, ValidateCellTypes } from '../wailsjs/go/main/App';
 import { EventsOn, OnFileDrop, OnFileDropOff } from '../wailsjs/runtime/runtime';
 import { main } from '../wailsjs/go/models';
 
@@ -21,14 +21,18 @@ function AppContent() {
     const [fileName, setFileName] = useState<string | null>(null);
     const [fileData, setFileData] = useState<FileData | null>(null);
     const [isLoading, setIsLoading] = useState(false);
-    const [validationResult, setValidationResult] = useState<{ isValid: boolean; messages: string[] } | null>(null);
+    const [validationResult, setValidationResult] = useState<{ isValid: boolean; issues: ValidationIssue[] } | null>(null);
     const [isValidating, setIsValidating] = useState(false);
+    const [pcaPreview, setPcaPreview] = useState<main.PCAPreview | null>(null);
+    const [cellIssues, setCellIssues] = useState<main.CellIssue[]>([]);
+    const [isRunningPCA, setIsRunningPCA] = useState(false);
     const [missingValueStats, setMissingValueStats] = useState<main.MissingValueStats | null>(null);
     const [showMissingValueSummary, setShowMissingValueSummary] = useState(false);
     const [showMissingValueDialog, setShowMissingValueDialog] = useState(false);
     const [dataQualityReport, setDataQualityReport] = useState<main.DataQualityReport | null>(null);
     const [showDataQualityReport, setShowDataQualityReport] = useState(false);
     const [isAnalyzingQuality, setIsAnalyzingQuality] = useState(false);
+    const [normalityAlpha, setNormalityAlpha] = useState(0.05);
     const [gopcaStatus, setGopcaStatus] = useState<main.GoPCAStatus | null>(null);
     const [isCheckingGoPCA, setIsCheckingGoPCA] = useState(false);
     const [showImportWizard, setShowImportWizard] = useState(false);
@@ -42,6 +46,30 @@ function AppContent() {
     // Ref for the CSV grid component
     const gridRef = useRef<any>(null);
 
+    // A PCA preview is stale once the data is edited
+    useEffect(() => {
+        setPcaPreview(null);
+    }, [fileData]);
+
+    // Highlight cells whose value does not match the type of their column
+    useEffect(() => {
+        if (!fileData) {
+            setCellIssues([]);
+            return;
+        }
+        let cancelled = false;
+        ValidateCellTypes(fileData).then((issues) => {
+            if (!cancelled) {
+                setCellIssues(issues || []);
+            }
+        }).catch((err) => {
+            console.error('Failed to check cell types:', err);
+        });
+        return () => {
+            cancelled = true;
+        };
+    }, [fileData]);
+
     // Listen for file-loaded events from backend
     useEffect(() => {
         const unsubscribe = EventsOn('file-loaded', (filename: string) => {
@@ -213,21 +241,54 @@ return;
             const result = await ValidateForGoPCA(fileData);
             if (result) {
                 setValidationResult({
-                    isValid: result.isValid,
-                    messages: result.messages || []
+                    isValid: result.valid,
+                    issues: result.issues || []
                 });
             }
         } catch (error) {
             console.error('Validation error:', error);
             setValidationResult({
                 isValid: false,
-                messages: ['ERROR: Failed to validate data - ' + error]
+                issues: [{ severity: 'error', message: 'Failed to validate data - ' + error }]
             });
         } finally {
             setIsValidating(false);
         }
     };
 
+    // Run a quick PCA of the numeric columns for the embedded preview
+    const handleQuickPCA = async () => {
+        if (!fileData) {
+return;
+}
+
+        setIsRunningPCA(true);
+        try {
+            setPcaPreview(await RunQuickPCA(fileData, 2));
+        } catch (error) {
+            console.error('Quick PCA error:', error);
+            alert('Error running PCA: ' + error);
+        } finally {
+            setIsRunningPCA(false);
+        }
+    };
+
+    // Re-run the quality analysis with a new significance level for the normality test
+    const handleNormalityAlphaChange = async (alpha: number) => {
+        if (!fileData) {
+return;
+}
+
+        try {
+            await SetNormalityAlpha(alpha);
+            setNormalityAlpha(alpha);
+            setDataQualityReport(await AnalyzeDataQuality(fileData));
+        } catch (error) {
+            console.error('Error updating normality test:', error);
+            alert('Error updating normality test: ' + error);
+        }
+    };
+
     // Handle missing value analysis
     const handleAnalyzeMissingValues = async () => {
         if (!fileData) {
@@ -245,7 +306,7 @@ return;
     };
 
     // Handle missing value fill
-    const handleFillMissingValues = async (strategy: string, column: string, value?: string) => {
+    const handleFillMissingValues = async (strategy: string, column: string, value?: string, groupBy?: string) => {
         if (!fileData) {
 return;
 }
@@ -254,7 +315,8 @@ return;
             const request = {
                 strategy,
                 column,
-                value: value || ''
+                value: value || '',
+                groupBy: groupBy || ''
             };
             const result = await FillMissingValues(fileData, request);
             if (result) {
@@ -502,6 +564,7 @@ return;
                                     headers={fileData.headers}
                                     rowNames={fileData.rowNames}
                                     fileData={fileData}
+                                    cellIssues={cellIssues}
                                     onDataChange={handleDataChange}
                                     onHeaderChange={handleHeaderChange}
                                     onRowNameChange={(rowIndex, newRowName) => {
@@ -548,6 +611,13 @@ return;
                                     >
                                         {isValidating ? 'Validating...' : 'Validate for GoPCA'}
                                     </button>
+                                    <button
+                                        onClick={handleQuickPCA}
+                                        disabled={isRunningPCA}
+                                        className="flex-1 px-4 py-2 bg-indigo-600 text-white rounded-lg hover:bg-indigo-700 disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
+                                    >
+                                        {isRunningPCA ? 'Running PCA...' : 'Quick PCA Preview'}
+                                    </button>
                                     <button
                                         onClick={async () => {
                                             if (!fileData) {
@@ -579,11 +649,18 @@ return;
                                 {validationResult && (
                                     <ValidationResults
                                         isValid={validationResult.isValid}
-                                        messages={validationResult.messages}
+                                        issues={validationResult.issues}
                                         onClose={() => setValidationResult(null)}
                                     />
                                 )}
 
+                                {pcaPreview && (
+                                    <QuickPCAPreview
+                                        preview={pcaPreview}
+                                        onClose={() => setPcaPreview(null)}
+                                    />
+                                )}
+
                                 <div className="border-t border-gray-200 dark:border-gray-700 pt-4">
                                     <h3 className="text-sm font-medium text-gray-700 dark:text-gray-300 mb-2">
                                         Export Options
@@ -648,6 +725,8 @@ return;
                 report={dataQualityReport}
                 isOpen={showDataQualityReport}
                 onClose={() => setShowDataQualityReport(false)}
+                normalityAlpha={normalityAlpha}
+                onNormalityAlphaChange={handleNormalityAlphaChange}
             />
 
             {/* Import Wizard */}
@@ -10,7 +10,8 @@ import { ColDef, GridReadyEvent, CellValueChangedEvent, GridApi, ColumnApi, Cell
 import 'ag-grid-community/styles/ag-grid.css';
 import 'ag-grid-community/styles/ag-theme-quartz.css';
 import { useTheme } from '@gopca/ui-components';
-import { ExecuteDeleteRows, ExecuteDeleteColumns, ExecuteInsertRow, ExecuteInsertColumn, ExecuteToggleTargetColumn, ExecuteHeaderEdit, ExecuteDuplicateRows } from '../../wailsjs/go/main/App';
+import { ExecuteDeleteRows, ExecuteDeleteColumns, ExecuteInsertRow, ExecuteInsertColumn, ExecuteToggleTargetColumn, ExecuteEncodeTargetColumn, ExecuteHeaderEdit, ExecuteDuplicateRows } from '../../wailsjs/go/main/App';
+import { main } from '../../wailsjs/go/models';
 import { RenameDialog } from './RenameDialog';
 import { ConfirmDialog } from '@gopca/ui-components';
 import {
@@ -35,6 +36,7 @@ interface CSVGridProps {
     onHeaderChange?: (colIndex: number, newHeader: string) => void;
     onRowNameChange?: (rowIndex: number, newRowName: string) => void;
     onRefresh?: (updatedData?: any) => void; // Callback to refresh data after operations
+    cellIssues?: main.CellIssue[]; // Cells whose value does not match the column type
 }
 
 // Context menu component
@@ -119,7 +121,8 @@ export const CSVGrid = forwardRef<any, CSVGridProps>(({
     onDataChange,
     onHeaderChange,
     onRowNameChange,
-    onRefresh
+    onRefresh,
+    cellIssues
 }, ref) => {
     // Validate inputs
     if (!data || !headers || data.length === 0 || headers.length === 0) {
@@ -185,6 +188,18 @@ return 'text';
         const header = headers[colIndex];
         const isTargetColumn = header.toLowerCase().endsWith('#target') ||
                               header.toLowerCase().endsWith('# target');
+        const isCategorical = fileData?.columnTypes?.[header] === 'categorical';
+
+        const encodeTarget = async (encoding: string) => {
+            if (fileData) {
+                try {
+                    const updatedData = await ExecuteEncodeTargetColumn(fileData, colIndex, encoding);
+                    onRefresh?.(updatedData);
+                } catch (error) {
+                    console.error('Error encoding target column:', error);
+                }
+            }
+        };
 
         const items: ContextMenuItem[] = [
             {
@@ -201,6 +216,18 @@ return 'text';
                 },
                 icon: <TargetColumnMenuIcon />
             },
+            ...(isCategorical ? [
+                {
+                    label: 'Encode as Target (0, 1, …)',
+                    action: () => encodeTarget('ordinal'),
+                    icon: <TargetColumnMenuIcon />
+                },
+                {
+                    label: 'Encode as Target (One Column per Category)',
+                    action: () => encodeTarget('onehot'),
+                    icon: <TargetColumnMenuIcon />
+                }
+            ] : []),
             {
                 label: 'Rename Column',
                 action: () => {
@@ -334,6 +361,13 @@ return 'text';
         setContextMenu({ x: event.clientX, y: event.clientY, items });
     }, [fileData, gridApi, onRefresh]);
 
+    // Type mismatches by data row and column index
+    const cellIssueMap = useMemo(() => {
+        const issues = new Map<string, main.CellIssue>();
+        cellIssues?.forEach(issue => issues.set(`${issue.row}:${issue.col}`, issue));
+        return issues;
+    }, [cellIssues]);
+
     // Create column definitions
     const columnDefs = useMemo<ColDef[]>(() => {
         const cols: ColDef[] = [];
@@ -407,9 +441,19 @@ classes.push('target-column');
 
                     if (isMissing) {
 classes.push('missing-value');
+} else if (cellIssueMap.has(`${params.data?.id}:${index}`)) {
+classes.push('type-mismatch');
 }
                     return classes.join(' ');
                 },
+                tooltipValueGetter: (params) => {
+                    const issue = cellIssueMap.get(`${params.data?.id}:${index}`);
+                    if (!issue) {
+                        return undefined;
+                    }
+                    const message = `Expected a ${issue.expectedType} value`;
+                    return issue.suggestedFix ? `${message} (did you mean ${issue.suggestedFix}?)` : message;
+                },
                 headerClass: () => {
                     const classes = [];
                     if (colType === 'numeric') {
@@ -440,7 +484,7 @@ classes.push('target-header');
         });
 
         return cols;
-    }, [headers, detectColumnType, rowNames, theme, handleHeaderContextMenu]);
+    }, [headers, detectColumnType, rowNames, theme, handleHeaderContextMenu, cellIssueMap]);
 
     // Convert data to row format for ag-Grid
     const rowData = useMemo(() => {
@@ -680,6 +724,11 @@ return;
                         background-color: ${theme === 'dark' ? '#7f1d1d' : '#fee2e2'} !important;
                         opacity: 0.7;
                     }
+                    .type-mismatch {
+                        background-color: ${theme === 'dark' ? '#78350f' : '#fef3c7'} !important;
+                        outline: 1px solid ${theme === 'dark' ? '#f59e0b' : '#d97706'};
+                        outline-offset: -1px;
+                    }
                     .target-column {
                         background-color: ${theme === 'dark' ? '#1e293b' : '#f1f5f9'} !important;
                     }
@@ -14,9 +14,14 @@ interface DataQualityDashboardProps {
     report: main.DataQualityReport | null;
     isOpen: boolean;
     onClose: () => void;
+    normalityAlpha: number;
+    onNormalityAlphaChange: (alpha: number) => void;
 }
 
-export const DataQualityDashboard: React.FC<DataQualityDashboardProps> = ({ report, isOpen, onClose }) => {
+// Significance levels offered for the Shapiro-Wilk normality test
+const NORMALITY_ALPHAS = [0.01, 0.05, 0.1];
+
+export const DataQualityDashboard: React.FC<DataQualityDashboardProps> = ({ report, isOpen, onClose, normalityAlpha, onNormalityAlphaChange }) => {
     const [selectedTab, setSelectedTab] = useState<'overview' | 'columns' | 'issues' | 'recommendations'>('overview');
     const [selectedColumn, setSelectedColumn] = useState<string | null>(null);
 
@@ -428,6 +433,11 @@ const ColumnDetailsModal: React.FC<ColumnDetailsModalProps> = ({ column, onClose
                                 </div>
                             )}
                         </div>
+                        {column.stats.approximate && (
+                            <p className="text-xs text-gray-500 dark:text-gray-400 mt-3">
+                                Large dataset: median, quartiles and outliers are based on one-pass estimates
+                            </p>
+                        )}
                     </div>
 
                     {/* Distribution */}
@@ -436,10 +446,26 @@ const ColumnDetailsModal: React.FC<ColumnDetailsModalProps> = ({ column, onClose
                             <h4 className="text-md font-medium mb-3 text-gray-700 dark:text-gray-300">Distribution</h4>
                             <div className="bg-gray-50 dark:bg-gray-900 rounded-lg p-4">
                                 <PlotlyDistributionChart distribution={column.distribution} columnName={column.name} />
-                                <p className="text-sm text-gray-600 dark:text-gray-400 mt-2">
-                                    Type: {column.distribution.distType}
-                                    {column.distribution.isNormal && ' (Normal)'}
-                                </p>
+                                <div className="flex items-center justify-between mt-2">
+                                    <p className="text-sm text-gray-600 dark:text-gray-400">
+                                        Type: {column.distribution.distType}
+                                        {column.distribution.isNormal && ' (Normal)'}
+                                        {column.distribution.normalityPValue !== undefined &&
+                                            ` · ${column.distribution.normalityTest ?? 'Normality'} p = ${column.distribution.normalityPValue.toPrecision(3)}`}
+                                    </p>
+                                    <label className="text-sm text-gray-600 dark:text-gray-400 flex items-center gap-2">
+                                        Normality α
+                                        <select
+                                            value={normalityAlpha}
+                                            onChange={(e) => onNormalityAlphaChange(parseFloat(e.target.value))}
+                                            className="px-2 py-1 text-sm border border-gray-300 dark:border-gray-600 rounded bg-white dark:bg-gray-800 text-gray-700 dark:text-gray-300"
+                                        >
+                                            {NORMALITY_ALPHAS.map(alpha => (
+                                                <option key={alpha} value={alpha}>{alpha}</option>
+                                            ))}
+                                        </select>
+                                    </label>
+                                </div>
                             </div>
                         </div>
                     )}
@@ -15,9 +15,11 @@ interface FormatOptionsProps {
     fileInfo: ImportFileInfo;
     options: ImportOptions;
     onChange: (options: ImportOptions) => void;
+    raggedRows: string;
+    onRaggedRowsChange: (policy: string) => void;
 }
 
-export const FormatOptions: React.FC<FormatOptionsProps> = ({ fileInfo, options, onChange }) => {
+export const FormatOptions: React.FC<FormatOptionsProps> = ({ fileInfo, options, onChange, raggedRows, onRaggedRowsChange }) => {
     const formatBytes = (bytes: number) => {
         if (bytes === 0) {
 return '0 Bytes';
@@ -79,6 +81,23 @@ return '0 Bytes';
                             className="w-full"
                         />
                     </div>
+
+                    {/* Ragged rows */}
+                    <div>
+                        <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                            Rows with Too Few or Too Many Fields
+                        </label>
+                        <CustomSelect
+                            value={raggedRows}
+                            onChange={onRaggedRowsChange}
+                            options={[
+                                { value: 'error', label: 'Reject the file' },
+                                { value: 'pad', label: 'Pad short rows with missing values' },
+                                { value: 'truncate', label: 'Pad short rows and drop extra fields' }
+                            ]}
+                            className="w-full"
+                        />
+                    </div>
                 </div>
             )}
 
@@ -202,6 +221,26 @@ return '0 Bytes';
                         className="w-full px-3 py-2 text-sm border border-gray-300 dark:border-gray-600 rounded-lg bg-white dark:bg-gray-700 text-gray-900 dark:text-gray-100"
                     />
                 </div>
+
+                {/* Composite row names */}
+                <div>
+                    <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                        Index Columns (comma-separated, 0-based)
+                    </label>
+                    <input
+                        type="text"
+                        placeholder="e.g. 0,1 for subject|visit row names"
+                        defaultValue={options.indexColumns?.join(',') || ''}
+                        onBlur={(e) => onChange({
+                            ...options,
+                            indexColumns: e.target.value
+                                .split(',')
+                                .map(v => parseInt(v.trim()))
+                                .filter(v => !isNaN(v))
+                        })}
+                        className="w-full px-3 py-2 text-sm border border-gray-300 dark:border-gray-600 rounded-lg bg-white dark:bg-gray-700 text-gray-900 dark:text-gray-100"
+                    />
+                </div>
             </div>
         </div>
     );
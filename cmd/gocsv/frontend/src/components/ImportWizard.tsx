@@ -5,7 +5,7 @@
 // military, warfare, or surveillance applications.
 
 import React, { useState, useEffect } from 'react';
-import { SelectFileForImport, GetFileInfo, PreviewFile, ImportFile } from '../../wailsjs/go/main/App';
+import { SelectFileForImport, GetFileInfo, PreviewFile, ImportFile, SetRaggedRows } from '../../wailsjs/go/main/App';
 import { main } from '../../wailsjs/go/models';
 import { FileSelector, ProgressIndicator } from '@gopca/ui-components';
 import { FormatOptions } from './FormatOptions';
@@ -40,6 +40,7 @@ export const ImportWizard: React.FC<ImportWizardProps> = ({ isOpen, onClose, onI
         maxRows: 0,
         selectedColumns: []
     });
+    const [raggedRows, setRaggedRows] = useState('error');
     const [preview, setPreview] = useState<FilePreview | null>(null);
     const [isLoading, setIsLoading] = useState(false);
     const [error, setError] = useState<string | null>(null);
@@ -80,6 +81,15 @@ export const ImportWizard: React.FC<ImportWizardProps> = ({ isOpen, onClose, onI
         }
     };
 
+    const handleRaggedRowsChange = async (policy: string) => {
+        try {
+            await SetRaggedRows(policy);
+            setRaggedRows(policy);
+        } catch (err) {
+            setError(`Failed to set ragged row handling: ${err}`);
+        }
+    };
+
     const handleOptionsNext = async () => {
         if (!selectedFile) {
 return;
@@ -257,6 +267,8 @@ return null;
                                 fileInfo={fileInfo}
                                 options={importOptions}
                                 onChange={setImportOptions}
+                                raggedRows={raggedRows}
+                                onRaggedRowsChange={handleRaggedRowsChange}
                             />
                         )}
 
@@ -10,7 +10,7 @@ import { CustomSelect } from '@gopca/ui-components';
 interface MissingValueDialogProps {
     isOpen: boolean;
     onClose: () => void;
-    onFill: (strategy: string, column: string, value?: string) => void;
+    onFill: (strategy: string, column: string, value?: string, groupBy?: string) => void;
     columns: string[];
     columnTypes: Record<string, string>;
 }
@@ -25,13 +25,15 @@ export const MissingValueDialog: React.FC<MissingValueDialogProps> = ({
     const [strategy, setStrategy] = useState('mean');
     const [selectedColumn, setSelectedColumn] = useState('');
     const [customValue, setCustomValue] = useState('');
+    const [groupBy, setGroupBy] = useState('');
 
     if (!isOpen) {
 return null;
 }
 
     const handleFill = () => {
-        onFill(strategy, selectedColumn, strategy === 'custom' ? customValue : undefined);
+        onFill(strategy, selectedColumn, strategy === 'custom' ? customValue : undefined,
+            canGroup ? groupBy || undefined : undefined);
         onClose();
     };
 
@@ -50,6 +52,8 @@ return null;
     };
 
     const strategies = getAvailableStrategies();
+    const canGroup = strategy === 'mean' || strategy === 'median' || strategy === 'mode';
+    const groupColumns = columns.filter(col => columnTypes[col] === 'categorical' && col !== selectedColumn);
 
     return (
         <div className="fixed inset-0 bg-black bg-opacity-50 flex items-center justify-center z-50">
@@ -131,6 +135,24 @@ return null;
                         </div>
                     )}
 
+                    {/* Group Selection */}
+                    {canGroup && groupColumns.length > 0 && (
+                        <div>
+                            <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                                Within Groups Of
+                            </label>
+                            <CustomSelect
+                                value={groupBy}
+                                onChange={(value) => setGroupBy(value)}
+                                options={[
+                                    { value: '', label: 'No Grouping' },
+                                    ...groupColumns.map(col => ({ value: col, label: col }))
+                                ]}
+                                className="w-full"
+                            />
+                        </div>
+                    )}
+
                     {/* Strategy Description */}
                     <div className="bg-gray-50 dark:bg-gray-700 rounded-md p-3 text-sm text-gray-600 dark:text-gray-400">
                         {strategy === 'mean' && "Replace missing values with the column's average."}
@@ -139,6 +161,7 @@ return null;
                         {strategy === 'forward' && 'Replace missing values with the previous non-missing value.'}
                         {strategy === 'backward' && 'Replace missing values with the next non-missing value.'}
                         {strategy === 'custom' && 'Replace missing values with a specific value.'}
+                        {canGroup && groupBy && ` Computed within each group of '${groupBy}', or over all rows for a group without values.`}
                     </div>
                 </div>
 
@@ -0,0 +1,83 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+import React, { useMemo } from 'react';
+import { main } from '../../wailsjs/go/models';
+
+interface QuickPCAPreviewProps {
+    preview: main.PCAPreview;
+    onClose: () => void;
+}
+
+const PLOT_SIZE = 240;
+const PLOT_PADDING = 12;
+
+export const QuickPCAPreview: React.FC<QuickPCAPreviewProps> = ({ preview, onClose }) => {
+    // Scale the PC1/PC2 scores to the plot, with PC2 at zero for a single component
+    const points = useMemo(() => {
+        const xs = preview.scores.map(row => row[0]);
+        const ys = preview.scores.map(row => row[1] ?? 0);
+        const scale = (values: number[]) => {
+            const lo = Math.min(...values);
+            const hi = Math.max(...values);
+            const span = hi - lo || 1;
+            return (v: number) => PLOT_PADDING + ((v - lo) / span) * (PLOT_SIZE - 2 * PLOT_PADDING);
+        };
+        const sx = scale(xs);
+        const sy = scale(ys);
+        return xs.map((x, i) => ({ x: sx(x), y: PLOT_SIZE - sy(ys[i]), name: preview.rowNames?.[i] ?? `${i + 1}` }));
+    }, [preview]);
+
+    return (
+        <div className="mt-4 bg-gray-50 dark:bg-gray-700/50 rounded-lg p-4">
+            <div className="flex items-center justify-between mb-3">
+                <h4 className="text-sm font-semibold text-gray-700 dark:text-gray-300">
+                    Quick PCA Preview
+                </h4>
+                <button
+                    onClick={onClose}
+                    className="text-gray-400 hover:text-gray-600 dark:hover:text-gray-300"
+                >
+                    <svg className="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path strokeLinecap="round" strokeLinejoin="round" strokeWidth="2" d="M6 18L18 6M6 6l12 12" />
+                    </svg>
+                </button>
+            </div>
+
+            <div className="flex gap-6 items-start">
+                <svg
+                    width={PLOT_SIZE}
+                    height={PLOT_SIZE}
+                    className="flex-shrink-0 bg-white dark:bg-gray-800 rounded border border-gray-200 dark:border-gray-600"
+                >
+                    {points.map((p, i) => (
+                        <circle key={i} cx={p.x} cy={p.y} r={2.5} className="fill-blue-600 dark:fill-blue-400" opacity={0.7}>
+                            <title>{p.name}</title>
+                        </circle>
+                    ))}
+                </svg>
+
+                <div className="text-sm text-gray-700 dark:text-gray-300 space-y-1">
+                    {preview.componentLabels.map((label, i) => (
+                        <div key={label}>
+                            {label}: {preview.explainedVariance[i].toFixed(1)}%
+                            <span className="text-gray-500 dark:text-gray-400"> (cumulative {preview.cumulativeVariance[i].toFixed(1)}%)</span>
+                        </div>
+                    ))}
+                    <div className="pt-2 text-xs text-gray-500 dark:text-gray-400">
+                        {preview.columns.length} numeric columns, standardized
+                        {preview.imputedValues > 0 && `, ${preview.imputedValues} missing values imputed with column means`}
+                    </div>
+                    {preview.excludedColumns && preview.excludedColumns.length > 0 && (
+                        <div className="text-xs text-gray-500 dark:text-gray-400">
+                            Excluded: {preview.excludedColumns.join(', ')}
+                        </div>
+                    )}
+                </div>
+            </div>
+        </div>
+    );
+};
@@ -6,38 +6,44 @@
 
 import React from 'react';
 
+export interface ValidationIssue {
+    severity: string;
+    message: string;
+    column?: string;
+}
+
 interface ValidationResultsProps {
     isValid: boolean;
-    messages: string[];
+    issues: ValidationIssue[];
     onClose: () => void;
 }
 
-export const ValidationResults: React.FC<ValidationResultsProps> = ({ isValid, messages, onClose }) => {
-    const getMessageStyle = (message: string) => {
-        if (message.startsWith('ERROR:')) {
+export const ValidationResults: React.FC<ValidationResultsProps> = ({ isValid, issues, onClose }) => {
+    const getMessageStyle = (severity: string) => {
+        if (severity === 'error') {
             return 'text-red-600 dark:text-red-400';
-        } else if (message.startsWith('WARNING:')) {
+        } else if (severity === 'warning') {
             return 'text-yellow-600 dark:text-yellow-400';
-        } else if (message.startsWith('INFO:')) {
+        } else if (severity === 'info') {
             return 'text-blue-600 dark:text-blue-400';
         }
         return 'text-gray-600 dark:text-gray-400';
     };
 
-    const getMessageIcon = (message: string) => {
-        if (message.startsWith('ERROR:')) {
+    const getMessageIcon = (severity: string) => {
+        if (severity === 'error') {
             return (
                 <svg className="w-5 h-5 text-red-600 dark:text-red-400 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                     <path strokeLinecap="round" strokeLinejoin="round" strokeWidth="2" d="M12 8v4m0 4h.01M21 12a9 9 0 11-18 0 9 9 0 0118 0z" />
                 </svg>
             );
-        } else if (message.startsWith('WARNING:')) {
+        } else if (severity === 'warning') {
             return (
                 <svg className="w-5 h-5 text-yellow-600 dark:text-yellow-400 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                     <path strokeLinecap="round" strokeLinejoin="round" strokeWidth="2" d="M12 9v2m0 4h.01m-6.938 4h13.856c1.54 0 2.502-1.667 1.732-3L13.732 4c-.77-1.333-2.694-1.333-3.464 0L3.34 16c-.77 1.333.192 3 1.732 3z" />
                 </svg>
             );
-        } else if (message.startsWith('INFO:')) {
+        } else if (severity === 'info') {
             return (
                 <svg className="w-5 h-5 text-blue-600 dark:text-blue-400 flex-shrink-0" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                     <path strokeLinecap="round" strokeLinejoin="round" strokeWidth="2" d="M13 16h-1v-4h-1m1-4h.01M21 12a9 9 0 11-18 0 9 9 0 0118 0z" />
@@ -47,11 +53,6 @@ export const ValidationResults: React.FC<ValidationResultsProps> = ({ isValid, m
         return null;
     };
 
-    const formatMessage = (message: string) => {
-        // Remove the prefix for display
-        return message.replace(/^(ERROR:|WARNING:|INFO:)\s*/, '');
-    };
-
     return (
         <div className="mt-4 bg-gray-50 dark:bg-gray-700/50 rounded-lg p-4">
             <div className="flex items-center justify-between mb-3">
@@ -84,13 +85,13 @@ export const ValidationResults: React.FC<ValidationResultsProps> = ({ isValid, m
                 </div>
             )}
 
-            {messages.length > 0 && (
+            {issues.length > 0 && (
                 <div className="space-y-2 mt-3 max-h-64 overflow-y-auto">
-                    {messages.map((message, index) => (
+                    {issues.map((issue, index) => (
                         <div key={index} className="flex items-start gap-2">
-                            {getMessageIcon(message)}
-                            <span className={`text-sm ${getMessageStyle(message)}`}>
-                                {formatMessage(message)}
+                            {getMessageIcon(issue.severity)}
+                            <span className={`text-sm ${getMessageStyle(issue.severity)}`}>
+                                {issue.message.charAt(0).toUpperCase() + issue.message.slice(1)}
                             </span>
                         </div>
                     ))}
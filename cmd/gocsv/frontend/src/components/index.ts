@@ -6,6 +6,7 @@
 
 export { CSVGrid } from './CSVGrid';
 export { ValidationResults } from './ValidationResults';
+export type { ValidationIssue } from './ValidationResults';
 export { MissingValueSummary } from './MissingValueSummary';
 export { MissingValueDialog } from './MissingValueDialog';
 export { DataQualityDashboard } from './DataQualityDashboard';
@@ -19,6 +20,7 @@ export { DataPreview } from './DataPreview';
 export { DataTransformDialog } from './DataTransformDialog';
 export { DocumentationViewer } from './DocumentationViewer';
 export { RenameDialog } from './RenameDialog';
+export { QuickPCAPreview } from './QuickPCAPreview';
 export {
     TargetColumnIcon,
     CategoryColumnIcon,
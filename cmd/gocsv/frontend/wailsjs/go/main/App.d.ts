@@ -1,11 +1,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
+import {types} from '../models';
 
 export function AnalyzeDataQuality(arg1:main.FileData):Promise<main.DataQualityReport>;
 
 export function AnalyzeMissingValues(arg1:main.FileData):Promise<main.MissingValueStats>;
 
+export function ApplyTransformLog(arg1:main.FileData,arg2:Array<number>):Promise<main.EditLogResult>;
+
 export function ApplyTransformation(arg1:main.FileData,arg2:main.TransformOptions):Promise<main.TransformationResult>;
 
 export function CheckGoPCAStatus():Promise<main.GoPCAStatus>;
@@ -22,7 +25,9 @@ export function ExecuteDeleteRows(arg1:main.FileData,arg2:Array<number>):Promise
 
 export function ExecuteDuplicateRows(arg1:main.FileData,arg2:Array<number>):Promise<main.FileData>;
 
-export function ExecuteFillMissingValues(arg1:main.FileData,arg2:string,arg3:string,arg4:string):Promise<main.FileData>;
+export function ExecuteEncodeTargetColumn(arg1:main.FileData,arg2:number,arg3:string):Promise<main.FileData>;
+
+export function ExecuteFillMissingValues(arg1:main.FileData,arg2:string,arg3:string,arg4:string,arg5:string):Promise<main.FileData>;
 
 export function ExecuteHeaderEdit(arg1:main.FileData,arg2:number,arg3:string,arg4:string):Promise<main.FileData>;
 
@@ -32,6 +37,8 @@ export function ExecuteInsertRow(arg1:main.FileData,arg2:number):Promise<main.Fi
 
 export function ExecuteToggleTargetColumn(arg1:main.FileData,arg2:number):Promise<main.FileData>;
 
+export function ExportTransformLog():Promise<Array<number>>;
+
 export function FillMissingValues(arg1:main.FileData,arg2:main.FillMissingValuesRequest):Promise<main.FileData>;
 
 export function GetFileInfo(arg1:string):Promise<main.ImportFileInfo>;
@@ -52,12 +59,20 @@ export function PreviewFile(arg1:string,arg2:main.ImportOptions):Promise<main.Fi
 
 export function Redo(arg1:main.FileData):Promise<main.FileData>;
 
+export function RunQuickPCA(arg1:main.FileData,arg2:number):Promise<main.PCAPreview>;
+
 export function SaveCSV(arg1:main.FileData):Promise<void>;
 
 export function SaveExcel(arg1:main.FileData):Promise<void>;
 
 export function SelectFileForImport():Promise<string>;
 
+export function SetNormalityAlpha(arg1:number):Promise<void>;
+
+export function SetRaggedRows(arg1:string):Promise<void>;
+
 export function Undo(arg1:main.FileData):Promise<main.FileData>;
 
-export function ValidateForGoPCA(arg1:main.FileData):Promise<main.ValidationResult>;
+export function ValidateCellTypes(arg1:main.FileData):Promise<Array<main.CellIssue>>;
+
+export function ValidateForGoPCA(arg1:main.FileData):Promise<types.ValidationReport>;
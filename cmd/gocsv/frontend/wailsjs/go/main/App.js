@@ -10,6 +10,10 @@ export function AnalyzeMissingValues(arg1) {
   return window['go']['main']['App']['AnalyzeMissingValues'](arg1);
 }
 
+export function ApplyTransformLog(arg1, arg2) {
+  return window['go']['main']['App']['ApplyTransformLog'](arg1, arg2);
+}
+
 export function ApplyTransformation(arg1, arg2) {
   return window['go']['main']['App']['ApplyTransformation'](arg1, arg2);
 }
@@ -42,8 +46,12 @@ export function ExecuteDuplicateRows(arg1, arg2) {
   return window['go']['main']['App']['ExecuteDuplicateRows'](arg1, arg2);
 }
 
-export function ExecuteFillMissingValues(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['ExecuteFillMissingValues'](arg1, arg2, arg3, arg4);
+export function ExecuteEncodeTargetColumn(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExecuteEncodeTargetColumn'](arg1, arg2, arg3);
+}
+
+export function ExecuteFillMissingValues(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ExecuteFillMissingValues'](arg1, arg2, arg3, arg4, arg5);
 }
 
 export function ExecuteHeaderEdit(arg1, arg2, arg3, arg4) {
@@ -62,6 +70,10 @@ export function ExecuteToggleTargetColumn(arg1, arg2) {
   return window['go']['main']['App']['ExecuteToggleTargetColumn'](arg1, arg2);
 }
 
+export function ExportTransformLog() {
+  return window['go']['main']['App']['ExportTransformLog']();
+}
+
 export function FillMissingValues(arg1, arg2) {
   return window['go']['main']['App']['FillMissingValues'](arg1, arg2);
 }
@@ -102,6 +114,10 @@ export function Redo(arg1) {
   return window['go']['main']['App']['Redo'](arg1);
 }
 
+export function RunQuickPCA(arg1, arg2) {
+  return window['go']['main']['App']['RunQuickPCA'](arg1, arg2);
+}
+
 export function SaveCSV(arg1) {
   return window['go']['main']['App']['SaveCSV'](arg1);
 }
@@ -114,10 +130,22 @@ export function SelectFileForImport() {
   return window['go']['main']['App']['SelectFileForImport']();
 }
 
+export function SetNormalityAlpha(arg1) {
+  return window['go']['main']['App']['SetNormalityAlpha'](arg1);
+}
+
+export function SetRaggedRows(arg1) {
+  return window['go']['main']['App']['SetRaggedRows'](arg1);
+}
+
 export function Undo(arg1) {
   return window['go']['main']['App']['Undo'](arg1);
 }
 
+export function ValidateCellTypes(arg1) {
+  return window['go']['main']['App']['ValidateCellTypes'](arg1);
+}
+
 export function ValidateForGoPCA(arg1) {
   return window['go']['main']['App']['ValidateForGoPCA'](arg1);
 }
@@ -38,6 +38,7 @@ export namespace main {
 	    histogram?: HistogramBin[];
 	    isNormal: boolean;
 	    normalityPValue?: number;
+	    normalityTest?: string;
 	    distType: string;
 	
 	    static createFrom(source: any = {}) {
@@ -49,6 +50,7 @@ export namespace main {
 	        this.histogram = this.convertValues(source["histogram"], HistogramBin);
 	        this.isNormal = source["isNormal"];
 	        this.normalityPValue = source["normalityPValue"];
+	        this.normalityTest = source["normalityTest"];
 	        this.distType = source["distType"];
 	    }
 	
@@ -87,6 +89,7 @@ export namespace main {
 	    skewness?: number;
 	    kurtosis?: number;
 	    categories?: Record<string, number>;
+	    approximate?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new ColumnStatistics(source);
@@ -110,6 +113,7 @@ export namespace main {
 	        this.skewness = source["skewness"];
 	        this.kurtosis = source["kurtosis"];
 	        this.categories = source["categories"];
+	        this.approximate = source["approximate"];
 	    }
 	}
 	export class ColumnAnalysis {
@@ -152,6 +156,28 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class CellIssue {
+	    row: number;
+	    col: number;
+	    column: string;
+	    value: string;
+	    expectedType: string;
+	    suggestedFix?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CellIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.col = source["col"];
+	        this.column = source["column"];
+	        this.value = source["value"];
+	        this.expectedType = source["expectedType"];
+	        this.suggestedFix = source["suggestedFix"];
+	    }
+	}
 	export class ColumnMissing {
 	    name: string;
 	    totalValues: number;
@@ -239,12 +265,29 @@ export namespace main {
 	        this.impact = source["impact"];
 	    }
 	}
+	export class CorrelationCluster {
+	    columns: string[];
+	    representative: string;
+	    minCorrelation: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CorrelationCluster(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.columns = source["columns"];
+	        this.representative = source["representative"];
+	        this.minCorrelation = source["minCorrelation"];
+	    }
+	}
 	export class DataQualityReport {
 	    dataProfile: DataProfile;
 	    columnAnalysis: ColumnAnalysis[];
 	    qualityScore: number;
 	    issues: QualityIssue[];
 	    recommendations: Recommendation[];
+	    correlationClusters: CorrelationCluster[];
 	
 	    static createFrom(source: any = {}) {
 	        return new DataQualityReport(source);
@@ -257,6 +300,7 @@ export namespace main {
 	        this.qualityScore = source["qualityScore"];
 	        this.issues = this.convertValues(source["issues"], QualityIssue);
 	        this.recommendations = this.convertValues(source["recommendations"], Recommendation);
+	        this.correlationClusters = this.convertValues(source["correlationClusters"], CorrelationCluster);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -287,6 +331,7 @@ export namespace main {
 	    categoricalColumns?: Record<string, Array<string>>;
 	    numericTargetColumns?: Record<string, Array<number>>;
 	    columnTypes?: Record<string, string>;
+	    columnSubtypes?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileData(source);
@@ -302,7 +347,42 @@ export namespace main {
 	        this.categoricalColumns = source["categoricalColumns"];
 	        this.numericTargetColumns = source["numericTargetColumns"];
 	        this.columnTypes = source["columnTypes"];
+	        this.columnSubtypes = source["columnSubtypes"];
+	    }
+	}
+	export class EditLogResult {
+	    data?: FileData;
+	    applied: number;
+	    skipped?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EditLogResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data = this.convertValues(source["data"], FileData);
+	        this.applied = source["applied"];
+	        this.skipped = source["skipped"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class FilePreview {
 	    headers: string[];
@@ -332,6 +412,7 @@ export namespace main {
 	    strategy: string;
 	    column: string;
 	    value: string;
+	    groupBy?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new FillMissingValuesRequest(source);
@@ -342,6 +423,7 @@ export namespace main {
 	        this.strategy = source["strategy"];
 	        this.column = source["column"];
 	        this.value = source["value"];
+	        this.groupBy = source["groupBy"];
 	    }
 	}
 	export class GoPCAStatus {
@@ -398,6 +480,7 @@ export namespace main {
 	    skipRows: number;
 	    maxRows: number;
 	    selectedColumns?: number[];
+	    indexColumns?: number[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ImportOptions(source);
@@ -415,6 +498,35 @@ export namespace main {
 	        this.skipRows = source["skipRows"];
 	        this.maxRows = source["maxRows"];
 	        this.selectedColumns = source["selectedColumns"];
+	        this.indexColumns = source["indexColumns"];
+	    }
+	}
+	export class PCAPreview {
+	    components: number;
+	    componentLabels: string[];
+	    rowNames?: string[];
+	    scores: number[][];
+	    explainedVariance: number[];
+	    cumulativeVariance: number[];
+	    columns: string[];
+	    excludedColumns?: string[];
+	    imputedValues: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PCAPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.components = source["components"];
+	        this.componentLabels = source["componentLabels"];
+	        this.rowNames = source["rowNames"];
+	        this.scores = source["scores"];
+	        this.explainedVariance = source["explainedVariance"];
+	        this.cumulativeVariance = source["cumulativeVariance"];
+	        this.columns = source["columns"];
+	        this.excludedColumns = source["excludedColumns"];
+	        this.imputedValues = source["imputedValues"];
 	    }
 	}
 	export class RowMissing {
@@ -553,20 +665,71 @@ export namespace main {
 	        this.currentPos = source["currentPos"];
 	    }
 	}
-	export class ValidationResult {
-	    isValid: boolean;
-	    messages: string[];
+
+}
+
+export namespace types {
+	
+	export class ValidationIssue {
+	    severity: string;
+	    message: string;
+	    column?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ValidationResult(source);
+	        return new ValidationIssue(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.isValid = source["isValid"];
-	        this.messages = source["messages"];
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	        this.column = source["column"];
 	    }
 	}
+	export class ValidationReport {
+	    valid: boolean;
+	    rows: number;
+	    numeric_columns: number;
+	    categorical_columns: number;
+	    target_columns: number;
+	    missing_values: number;
+	    missing_percent: number;
+	    issues: ValidationIssue[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ValidationReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.valid = source["valid"];
+	        this.rows = source["rows"];
+	        this.numeric_columns = source["numeric_columns"];
+	        this.categorical_columns = source["categorical_columns"];
+	        this.target_columns = source["target_columns"];
+	        this.missing_values = source["missing_values"];
+	        this.missing_percent = source["missing_percent"];
+	        this.issues = this.convertValues(source["issues"], ValidationIssue);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -10,17 +10,18 @@ import (
 	"github.com/bitjungle/gopca/pkg/types"
 )
 
-// FileData represents the structure of loaded file data
-// This version uses JSONFloat64 to handle NaN values safely
+// FileData represents the structure of loaded file data: the shared text grid, with
+// the parsed categorical and target columns and details of the import. It uses
+// JSONFloat64 to handle NaN values safely
 type FileData struct {
-	Headers              []string                       `json:"headers"`
-	RowNames             []string                       `json:"rowNames,omitempty"`
-	Data                 [][]string                     `json:"data"`
+	types.FileData
 	Rows                 int                            `json:"rows"`
 	Columns              int                            `json:"columns"`
 	CategoricalColumns   map[string][]string            `json:"categoricalColumns,omitempty"`
 	NumericTargetColumns map[string][]types.JSONFloat64 `json:"numericTargetColumns,omitempty"`
-	ColumnTypes          map[string]string              `json:"columnTypes,omitempty"`
+	ColumnSubtypes       map[string]string              `json:"columnSubtypes,omitempty"`   // "integer" for numeric and target columns of whole numbers
+	Warnings             []string                       `json:"warnings,omitempty"`         // Problems repaired while loading, such as ragged rows
+	FormatConfidence     float64                        `json:"formatConfidence,omitempty"` // Confidence in the detected delimiter and decimal separator, from 0 to 1
 }
 
 // ConvertFloat64MapToJSON converts a map of float64 slices to JSONFloat64 slices
@@ -0,0 +1,117 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// DataScan holds dataset statistics gathered by ScanData in a single pass
+type DataScan struct {
+	Rows         int
+	Columns      int
+	TotalCells   int
+	MissingCells int
+
+	ColumnMissing     []int   // Missing values per column
+	ColumnMissingRows [][]int // Rows with a missing value in each column, in ascending order
+	ColumnNumeric     []int   // Non-missing values per column that parse as numbers
+	ColumnText        []int   // Non-missing values per column that do not parse as numbers
+	RowMissing        []int   // Missing values per row
+
+	DuplicateRows int // Rows identical to an earlier row
+	MemoryBytes   int // Estimated memory used by the cell strings
+}
+
+// MissingPercent returns the percentage of cells that are missing
+func (s *DataScan) MissingPercent() float64 {
+	if s.TotalCells == 0 {
+		return 0
+	}
+	return float64(s.MissingCells) / float64(s.TotalCells) * 100
+}
+
+// ScanData computes missing value counts, per-column value type counts, duplicate
+// rows and a memory estimate in one traversal of the data. Duplicate rows are
+// found by hashing and confirmed by comparing the rows.
+func ScanData(data *FileData) *DataScan {
+	scan := &DataScan{}
+	if data == nil {
+		return scan
+	}
+
+	rows := min(data.Rows, len(data.Data))
+	scan.Rows = data.Rows
+	scan.Columns = data.Columns
+	scan.TotalCells = data.Rows * data.Columns
+	scan.ColumnMissing = make([]int, data.Columns)
+	scan.ColumnMissingRows = make([][]int, data.Columns)
+	scan.ColumnNumeric = make([]int, data.Columns)
+	scan.ColumnText = make([]int, data.Columns)
+	scan.RowMissing = make([]int, data.Rows)
+
+	seen := make(map[uint64][]int) // Row hash to the first rows with that hash
+	hash := fnv.New64a()
+	cellSize := int(unsafe.Sizeof(""))
+
+	for rowIdx := 0; rowIdx < rows; rowIdx++ {
+		row := data.Data[rowIdx]
+		hash.Reset()
+		for colIdx, value := range row {
+			// A zero byte separates cells so that ["ab", "c"] and ["a", "bc"] differ
+			_, _ = hash.Write([]byte(value))
+			_, _ = hash.Write([]byte{0})
+			scan.MemoryBytes += cellSize + len(value)
+
+			if colIdx >= data.Columns {
+				continue
+			}
+			if isMissingValue(strings.TrimSpace(value)) {
+				scan.MissingCells++
+				scan.ColumnMissing[colIdx]++
+				scan.ColumnMissingRows[colIdx] = append(scan.ColumnMissingRows[colIdx], rowIdx)
+				scan.RowMissing[rowIdx]++
+			} else if _, ok := parseNumericValue(value); ok {
+				scan.ColumnNumeric[colIdx]++
+			} else {
+				scan.ColumnText[colIdx]++
+			}
+		}
+
+		key := hash.Sum64()
+		duplicate := false
+		for _, earlier := range seen[key] {
+			if slices.Equal(data.Data[earlier], row) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			scan.DuplicateRows++
+		} else {
+			seen[key] = append(seen[key], rowIdx)
+		}
+	}
+
+	return scan
+}
+
+// formatMemorySize formats a byte count with a binary unit
+func formatMemorySize(bytes int) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	} else if bytes < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	} else if bytes < 1024*1024*1024 {
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	}
+	return fmt.Sprintf("%.1f GB", float64(bytes)/(1024*1024*1024))
+}
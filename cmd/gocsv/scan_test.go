@@ -0,0 +1,106 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestScanDataMatchesSeparateCounts(t *testing.T) {
+	data := &FileData{
+		FileData: types.FileData{
+			Headers: []string{"A", "B", "C"},
+			Data: [][]string{
+				{"1", "", "x"},
+				{"2", "NA", "y"},
+				{"1", "", "x"},
+				{"3", "4", " "},
+				{"1", "", "x"},
+				{"", "5", "z"},
+			},
+		},
+		Rows:    6,
+		Columns: 3,
+	}
+
+	// Count missing values per column and row, and duplicate rows, one at a time
+	columnMissing := make([]int, data.Columns)
+	rowMissing := make([]int, data.Rows)
+	total := 0
+	for i, row := range data.Data {
+		for j, value := range row {
+			if isMissingValue(strings.TrimSpace(value)) {
+				columnMissing[j]++
+				rowMissing[i]++
+				total++
+			}
+		}
+	}
+	duplicates := 0
+	seen := make(map[string]bool)
+	for _, row := range data.Data {
+		key := strings.Join(row, "\x00")
+		if seen[key] {
+			duplicates++
+		}
+		seen[key] = true
+	}
+
+	scan := ScanData(data)
+	if scan.MissingCells != total {
+		t.Errorf("MissingCells = %d, want %d", scan.MissingCells, total)
+	}
+	for j, want := range columnMissing {
+		if scan.ColumnMissing[j] != want {
+			t.Errorf("ColumnMissing[%d] = %d, want %d", j, scan.ColumnMissing[j], want)
+		}
+	}
+	for i, want := range rowMissing {
+		if scan.RowMissing[i] != want {
+			t.Errorf("RowMissing[%d] = %d, want %d", i, scan.RowMissing[i], want)
+		}
+	}
+	if scan.DuplicateRows != duplicates || duplicates != 2 {
+		t.Errorf("DuplicateRows = %d, want %d (expected 2)", scan.DuplicateRows, duplicates)
+	}
+	if scan.ColumnNumeric[0] != 5 || scan.ColumnText[2] != 5 {
+		t.Errorf("unexpected type counts: numeric %v, text %v", scan.ColumnNumeric, scan.ColumnText)
+	}
+
+	// The public analysis methods report the same counts
+	app := NewApp()
+	stats := app.AnalyzeMissingValues(data)
+	if stats.MissingCells != total || stats.TotalCells != 18 {
+		t.Errorf("AnalyzeMissingValues counted %d of %d cells, want %d of 18",
+			stats.MissingCells, stats.TotalCells, total)
+	}
+	for j, header := range data.Headers {
+		if stats.ColumnStats[header].MissingValues != columnMissing[j] {
+			t.Errorf("column %s: %d missing, want %d", header, stats.ColumnStats[header].MissingValues, columnMissing[j])
+		}
+	}
+	for i, want := range rowMissing {
+		if got := stats.RowStats[i]; (want == 0) != (got == nil) || (got != nil && got.MissingValues != want) {
+			t.Errorf("row %d: got %+v, want %d missing", i, got, want)
+		}
+	}
+	if stats.ColumnStats["B"].Pattern != detectMissingPattern([]int{0, 1, 2, 4}, data.Rows) {
+		t.Errorf("column B pattern = %s", stats.ColumnStats["B"].Pattern)
+	}
+
+	report, err := app.AnalyzeDataQuality(data)
+	if err != nil {
+		t.Fatalf("AnalyzeDataQuality failed: %v", err)
+	}
+	if report.DataProfile.DuplicateRows != duplicates || report.DataProfile.MissingPercent != stats.MissingPercent {
+		t.Errorf("data profile reports %d duplicates and %.2f%% missing, want %d and %.2f%%",
+			report.DataProfile.DuplicateRows, report.DataProfile.MissingPercent, duplicates, stats.MissingPercent)
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// editLogVersion is the format version written to transform logs
+const editLogVersion = 1
+
+// Operations recorded in a transform log
+const (
+	logOpEditCell      = "edit_cell"
+	logOpEditHeader    = "edit_header"
+	logOpFillMissing   = "fill_missing"
+	logOpDeleteRows    = "delete_rows"
+	logOpDeleteColumns = "delete_columns"
+	logOpInsertRow     = "insert_row"
+	logOpInsertColumn  = "insert_column"
+	logOpToggleTarget  = "toggle_target"
+	logOpEncodeTarget  = "encode_target"
+	logOpDuplicateRows = "duplicate_rows"
+	logOpTransform     = "transform"
+	logOpBatch         = "batch"
+	logOpUnknown       = "unknown"
+)
+
+// EditLog is a record of the edits applied to a dataset, in order, that can be
+// replayed on another dataset to reproduce them
+type EditLog struct {
+	Version int `json:"version"`
+	// Complete is false when the oldest edits had dropped out of the undo history
+	// and are missing from the log
+	Complete bool          `json:"complete"`
+	Steps    []EditLogStep `json:"steps"`
+}
+
+// EditLogStep is one edit in a transform log
+type EditLogStep struct {
+	Operation   string          `json:"operation"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+	// Replayable is false for manual edits, such as cell edits, that are recorded for
+	// the record but not replayed
+	Replayable bool          `json:"replayable"`
+	Steps      []EditLogStep `json:"steps,omitempty"` // Steps of a batch operation
+}
+
+// EditLogResult is the outcome of replaying a transform log
+type EditLogResult struct {
+	Data    *FileData `json:"data"`
+	Applied int       `json:"applied"`           // Number of steps replayed
+	Skipped []string  `json:"skipped,omitempty"` // Descriptions of the steps that were not replayed
+}
+
+// Parameters of the logged operations. Fill missing values and transforms are
+// logged with FillMissingValuesRequest and TransformOptions.
+type (
+	cellEditParams struct {
+		Row      int    `json:"row"`
+		Column   int    `json:"column"`
+		OldValue string `json:"oldValue"`
+		NewValue string `json:"newValue"`
+	}
+	headerEditParams struct {
+		Column  int    `json:"column"`
+		OldName string `json:"oldName"`
+		NewName string `json:"newName"`
+	}
+	rowsParams struct {
+		Rows []int `json:"rows"`
+	}
+	columnsParams struct {
+		Columns []string `json:"columns"`
+	}
+	insertParams struct {
+		Index int    `json:"index"`
+		Name  string `json:"name,omitempty"`
+	}
+	targetParams struct {
+		Column   string         `json:"column"`
+		Encoding TargetEncoding `json:"encoding,omitempty"`
+	}
+)
+
+// loggedCommand is a command that can be recorded as a transform log step
+type loggedCommand interface {
+	Command
+	logStep() EditLogStep
+}
+
+// newLogStep returns the log step of a command with the given parameters
+func newLogStep(operation string, cmd Command, params any, replayable bool) EditLogStep {
+	raw, _ := json.Marshal(params) // The parameter types always marshal
+	return EditLogStep{
+		Operation:   operation,
+		Description: cmd.GetDescription(),
+		Parameters:  raw,
+		Replayable:  replayable,
+	}
+}
+
+// logStepOf returns the log step of a command. Commands that cannot describe their
+// parameters are recorded by description only and are not replayable.
+func logStepOf(cmd Command) EditLogStep {
+	if logged, ok := cmd.(loggedCommand); ok {
+		return logged.logStep()
+	}
+	return EditLogStep{Operation: logOpUnknown, Description: cmd.GetDescription()}
+}
+
+func (c *CellEditCommand) logStep() EditLogStep {
+	// A manual edit refers to a cell of the edited dataset, which need not mean the
+	// same in another one
+	return newLogStep(logOpEditCell, c, cellEditParams{c.row, c.col, c.oldValue, c.newValue}, false)
+}
+
+func (c *HeaderEditCommand) logStep() EditLogStep {
+	return newLogStep(logOpEditHeader, c, headerEditParams{c.col, c.oldValue, c.newValue}, true)
+}
+
+func (c *FillMissingValuesCommand) logStep() EditLogStep {
+	request := FillMissingValuesRequest{Strategy: c.strategy, Column: c.column, Value: c.customValue, GroupBy: c.groupBy}
+	return newLogStep(logOpFillMissing, c, request, true)
+}
+
+func (c *BatchCommand) logStep() EditLogStep {
+	step := EditLogStep{Operation: logOpBatch, Description: c.description, Replayable: true}
+	for _, cmd := range c.commands {
+		step.Steps = append(step.Steps, logStepOf(cmd))
+	}
+	return step
+}
+
+func (c *DeleteRowsCommand) logStep() EditLogStep {
+	rows := slices.Clone(c.rowIndices)
+	sort.Ints(rows)
+	return newLogStep(logOpDeleteRows, c, rowsParams{rows}, true)
+}
+
+func (c *DeleteColumnsCommand) logStep() EditLogStep {
+	return newLogStep(logOpDeleteColumns, c, columnsParams{c.oldHeaders}, true)
+}
+
+func (c *InsertRowCommand) logStep() EditLogStep {
+	return newLogStep(logOpInsertRow, c, insertParams{Index: c.index}, true)
+}
+
+func (c *InsertColumnCommand) logStep() EditLogStep {
+	return newLogStep(logOpInsertColumn, c, insertParams{Index: c.index, Name: c.name}, true)
+}
+
+func (c *ToggleTargetColumnCommand) logStep() EditLogStep {
+	return newLogStep(logOpToggleTarget, c, targetParams{Column: c.oldName}, true)
+}
+
+func (c *EncodeTargetColumnCommand) logStep() EditLogStep {
+	params := targetParams{Column: c.oldData.Headers[c.colIndex], Encoding: c.encoding}
+	return newLogStep(logOpEncodeTarget, c, params, true)
+}
+
+func (c *DuplicateRowCommand) logStep() EditLogStep {
+	return newLogStep(logOpDuplicateRows, c, rowsParams{c.sourceIndices}, true)
+}
+
+func (c *TransformCommand) logStep() EditLogStep {
+	return newLogStep(logOpTransform, c, c.options, true)
+}
+
+func (c *ApplyTransformLogCommand) logStep() EditLogStep {
+	return EditLogStep{Operation: logOpBatch, Description: c.GetDescription(), Replayable: true,
+		Steps: c.log.Steps}
+}
+
+// ApplyTransformLogCommand represents replaying a transform log as a single edit
+type ApplyTransformLogCommand struct {
+	app     *App
+	oldData *FileData
+	log     *EditLog
+	applied int
+	skipped []string
+}
+
+// NewApplyTransformLogCommand creates a new apply transform log command
+func NewApplyTransformLogCommand(app *App, data *FileData, log *EditLog) *ApplyTransformLogCommand {
+	return &ApplyTransformLogCommand{
+		app:     app,
+		oldData: deepCopyFileData(data),
+		log:     log,
+	}
+}
+
+// Execute replays the steps of the log in order. Steps that are not replayable are
+// skipped; if a step fails, the data is restored.
+func (c *ApplyTransformLogCommand) Execute(data *FileData) error {
+	if data == nil {
+		return fmt.Errorf("data is nil")
+	}
+
+	c.applied, c.skipped = 0, nil
+	if err := c.replay(data, c.log.Steps); err != nil {
+		_ = c.Undo(data) // Restoring a copy cannot fail
+		return err
+	}
+	return nil
+}
+
+// replay applies steps to data, descending into batch steps
+func (c *ApplyTransformLogCommand) replay(data *FileData, steps []EditLogStep) error {
+	for _, step := range steps {
+		if !step.Replayable {
+			c.skipped = append(c.skipped, step.Description)
+			continue
+		}
+		if step.Operation == logOpBatch {
+			if err := c.replay(data, step.Steps); err != nil {
+				return err
+			}
+			continue
+		}
+
+		cmd, err := c.app.commandFromLogStep(data, step)
+		if err == nil {
+			err = cmd.Execute(data)
+		}
+		if err != nil {
+			return fmt.Errorf("step %d (%s): %w", c.applied+len(c.skipped)+1, step.Description, err)
+		}
+		c.applied++
+	}
+	return nil
+}
+
+// Undo restores the data from before the log was replayed
+func (c *ApplyTransformLogCommand) Undo(data *FileData) error {
+	restored := deepCopyFileData(c.oldData)
+	data.Headers = restored.Headers
+	data.RowNames = restored.RowNames
+	data.Data = restored.Data
+	data.Rows = restored.Rows
+	data.Columns = restored.Columns
+	data.CategoricalColumns = restored.CategoricalColumns
+	data.NumericTargetColumns = restored.NumericTargetColumns
+	data.ColumnTypes = restored.ColumnTypes
+	data.ColumnSubtypes = restored.ColumnSubtypes
+	return nil
+}
+
+// GetDescription returns a description of the command
+func (c *ApplyTransformLogCommand) GetDescription() string {
+	return fmt.Sprintf("Apply transform log (%d steps)", len(c.log.Steps))
+}
+
+// commandFromLogStep creates the command that repeats a logged step on data.
+// Columns are looked up by name, so the log also applies to data with the columns
+// in another order; rows are referred to by position.
+func (a *App) commandFromLogStep(data *FileData, step EditLogStep) (Command, error) {
+	columnIndex := func(name string) (int, error) {
+		if i := slices.Index(data.Headers, name); i >= 0 {
+			return i, nil
+		}
+		return 0, fmt.Errorf("column '%s' not found", name)
+	}
+	checkRows := func(rows []int) error {
+		for _, row := range rows {
+			if row < 0 || row >= len(data.Data) {
+				return fmt.Errorf("row %d out of range (%d rows)", row+1, len(data.Data))
+			}
+		}
+		return nil
+	}
+
+	switch step.Operation {
+	case logOpEditHeader:
+		var p headerEditParams
+		if err := json.Unmarshal(step.Parameters, &p); err != nil {
+			return nil, err
+		}
+		col, err := columnIndex(p.OldName)
+		if err != nil {
+			return nil, err
+		}
+		return NewHeaderEditCommand(col, p.OldName, p.NewName), nil
+
+	case logOpFillMissing:
+		var p FillMissingValuesRequest
+		if err := json.Unmarshal(step.Parameters, &p); err != nil {
+			return nil, err
+		}
+		return NewFillMissingValuesCommand(a, data, p.Strategy, p.Column, p.Value, p.GroupBy), nil
+
+	case logOpDeleteRows, logOpDuplicateRows:
+		var p rowsParams
+		if err := json.Unmarshal(step.Parameters, &p); err != nil {
+			return nil, err
+		}
+		if err := checkRows(p.Rows); err != nil {
+			return nil, err
+		}
+		if step.Operation == logOpDeleteRows {
+			return NewDeleteRowsCommand(data, p.Rows), nil
+		}
+		return NewDuplicateRowCommand(a, data, p.Rows), nil
+
+	case logOpDeleteColumns:
+		var p columnsParams
+		if err := json.Unmarshal(step.Parameters, &p); err != nil {
+			return nil, err
+		}
+		indices := make([]int, len(p.Columns))
+		for i, name := range p.Columns {
+			col, err := columnIndex(name)
+			if err != nil {
+				return nil, err
+			}
+			indices[i] = col
+		}
+		return NewDeleteColumnsCommand(a, data, indices), nil
+
+	case logOpInsertRow, logOpInsertColumn:
+		var p insertParams
+		if err := json.Unmarshal(step.Parameters, &p); err != nil {
+			return nil, err
+		}
+		if step.Operation == logOpInsertRow {
+			return NewInsertRowCommand(a, data, p.Index), nil
+		}
+		return NewInsertColumnCommand(a, data, p.Index, p.Name), nil
+
+	case logOpToggleTarget, logOpEncodeTarget:
+		var p targetParams
+		if err := json.Unmarshal(step.Parameters, &p); err != nil {
+			return nil, err
+		}
+		col, err := columnIndex(p.Column)
+		if err != nil {
+			return nil, err
+		}
+		if step.Operation == logOpToggleTarget {
+			return NewToggleTargetColumnCommand(a, data, col), nil
+		}
+		return NewEncodeTargetColumnCommand(a, data, col, p.Encoding)
+
+	case logOpTransform:
+		var p TransformOptions
+		if err := json.Unmarshal(step.Parameters, &p); err != nil {
+			return nil, err
+		}
+		return NewTransformCommand(a, data, p), nil
+	}
+	return nil, fmt.Errorf("operation '%s' cannot be replayed", step.Operation)
+}
+
+// ExportTransformLog returns the edits applied since the file was loaded, oldest
+// first, as a JSON transform log that ApplyTransformLog can replay on a fresh copy
+// of the data. Undone edits are left out.
+func (a *App) ExportTransformLog() ([]byte, error) {
+	commands, complete := a.history.Applied()
+	log := EditLog{
+		Version:  editLogVersion,
+		Complete: complete,
+		Steps:    make([]EditLogStep, 0, len(commands)),
+	}
+	for _, cmd := range commands {
+		log.Steps = append(log.Steps, logStepOf(cmd))
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// ApplyTransformLog replays a transform log written by ExportTransformLog on data, as
+// a single edit that can be undone. Manual edits such as cell edits are not replayed;
+// they are listed in the result.
+func (a *App) ApplyTransformLog(data *FileData, log []byte) (*EditLogResult, error) {
+	if data == nil {
+		return nil, fmt.Errorf("apply transform log: no data loaded")
+	}
+	var parsed EditLog
+	if err := json.Unmarshal(log, &parsed); err != nil {
+		return nil, fmt.Errorf("apply transform log: invalid log: %w", err)
+	}
+	if parsed.Version != editLogVersion {
+		return nil, fmt.Errorf("apply transform log: unsupported version %d", parsed.Version)
+	}
+
+	cmd := NewApplyTransformLogCommand(a, data, &parsed)
+	if _, err := a.executeCommand(cmd, data, "apply transform log"); err != nil {
+		return nil, err
+	}
+	return &EditLogResult{Data: data, Applied: cmd.applied, Skipped: cmd.skipped}, nil
+}
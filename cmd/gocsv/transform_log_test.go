@@ -0,0 +1,112 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// TestTransformLog tests that replaying an exported log reproduces an edited dataset
+func TestTransformLog(t *testing.T) {
+	newData := func() *FileData {
+		return &FileData{
+			FileData: types.FileData{
+				Headers:  []string{"x", "y"},
+				RowNames: []string{"r1", "r2", "r3", "r4"},
+				Data: [][]string{
+					{"1", "10"},
+					{"", "20"},
+					{"3", "NA"},
+					{"4", "40"},
+				},
+				ColumnTypes: map[string]string{"x": "numeric", "y": "numeric"},
+			},
+			Rows:    4,
+			Columns: 2,
+		}
+	}
+
+	app := NewApp()
+	edited := newData()
+	if _, err := app.ExecuteFillMissingValues(edited, "mean", "", "", ""); err != nil {
+		t.Fatalf("Failed to fill missing values: %v", err)
+	}
+	if _, err := app.ApplyTransformation(edited, TransformOptions{Type: TransformStandardize, Columns: []string{"x", "y"}}); err != nil {
+		t.Fatalf("Failed to standardize: %v", err)
+	}
+	if _, err := app.ExecuteDeleteRows(edited, []int{1}); err != nil {
+		t.Fatalf("Failed to delete rows: %v", err)
+	}
+	expected := deepCopyFileData(edited)
+
+	// An undone edit is left out of the log, a manual cell edit is recorded but not replayed
+	if _, err := app.ExecuteInsertColumn(edited, 2, "z"); err != nil {
+		t.Fatalf("Failed to insert column: %v", err)
+	}
+	if err := app.history.Undo(edited); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if _, err := app.ExecuteCellEdit(edited, 0, 0, edited.Data[0][0], "99"); err != nil {
+		t.Fatalf("Failed to edit cell: %v", err)
+	}
+
+	logData, err := app.ExportTransformLog()
+	if err != nil {
+		t.Fatalf("ExportTransformLog failed: %v", err)
+	}
+	var log EditLog
+	if err := json.Unmarshal(logData, &log); err != nil {
+		t.Fatalf("Invalid transform log: %v", err)
+	}
+	var operations []string
+	for _, step := range log.Steps {
+		operations = append(operations, step.Operation)
+	}
+	if want := []string{"fill_missing", "transform", "delete_rows", "edit_cell"}; !reflect.DeepEqual(operations, want) {
+		t.Errorf("Expected operations %v, got %v", want, operations)
+	}
+	if !log.Complete || log.Steps[3].Replayable {
+		t.Errorf("Expected a complete log with a non-replayable cell edit, got %+v", log)
+	}
+
+	replayApp := NewApp()
+	fresh := newData()
+	result, err := replayApp.ApplyTransformLog(fresh, logData)
+	if err != nil {
+		t.Fatalf("ApplyTransformLog failed: %v", err)
+	}
+	if result.Applied != 3 || len(result.Skipped) != 1 {
+		t.Errorf("Expected 3 applied and 1 skipped step, got %d and %v", result.Applied, result.Skipped)
+	}
+	if !reflect.DeepEqual(fresh.Data, expected.Data) || !reflect.DeepEqual(fresh.RowNames, expected.RowNames) ||
+		!reflect.DeepEqual(fresh.Headers, expected.Headers) {
+		t.Errorf("Replay did not reproduce the edited data:\ngot  %v %v\nwant %v %v",
+			fresh.RowNames, fresh.Data, expected.RowNames, expected.Data)
+	}
+
+	// The replay is undone as one step
+	if err := replayApp.history.Undo(fresh); err != nil {
+		t.Fatalf("Failed to undo the replay: %v", err)
+	}
+	if !reflect.DeepEqual(fresh.Data, newData().Data) {
+		t.Errorf("Undo did not restore the original data: %v", fresh.Data)
+	}
+
+	// A step that does not fit the data fails and leaves the data unchanged
+	short := newData()
+	short.Data, short.RowNames, short.Rows = short.Data[:1], short.RowNames[:1], 1
+	if _, err := NewApp().ApplyTransformLog(short, logData); err == nil {
+		t.Error("Expected replaying a row deletion on too few rows to fail")
+	}
+	if short.Data[0][0] != "1" || short.Data[0][1] != "10" {
+		t.Errorf("Expected the failed replay to restore the data, got %v", short.Data)
+	}
+}
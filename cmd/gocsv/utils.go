@@ -7,8 +7,10 @@
 package main
 
 import (
+	"math"
 	"strconv"
 
+	"github.com/bitjungle/gopca/pkg/types"
 	"github.com/bitjungle/gopca/pkg/utils"
 )
 
@@ -47,6 +49,98 @@ func getNumericValues(data [][]string, colIdx int) []float64 {
 	return values
 }
 
+// numericMatrix extracts the numeric columns of data as a matrix, with NaN for
+// missing and non-numeric values
+func numericMatrix(data *FileData) types.Matrix {
+	cols := []int{}
+	for i, header := range data.Headers {
+		if data.ColumnTypes[header] == "numeric" {
+			cols = append(cols, i)
+		}
+	}
+	if len(cols) == 0 {
+		return nil
+	}
+
+	matrix := make(types.Matrix, len(data.Data))
+	for i, row := range data.Data {
+		matrix[i] = make([]float64, len(cols))
+		for k, colIdx := range cols {
+			matrix[i][k] = math.NaN()
+			if colIdx < len(row) {
+				if num, ok := parseNumericValue(row[colIdx]); ok {
+					matrix[i][k] = num
+				}
+			}
+		}
+	}
+	return matrix
+}
+
+// columnSubtypeInteger is the subtype of numeric and target columns holding only whole numbers
+const columnSubtypeInteger = "integer"
+
+// isIntegerColumn reports whether every non-missing value in a column parses as a
+// whole number, with at least one value present
+func isIntegerColumn(data [][]string, colIdx int) bool {
+	found := false
+	for _, row := range data {
+		if colIdx >= len(row) || isMissingValue(row[colIdx]) {
+			continue
+		}
+		v, ok := parseNumericValue(row[colIdx])
+		if !ok || !types.IsWholeNumber(v) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// updateColumnSubtypes marks the numeric and target columns of data whose values are
+// all whole numbers with the integer subtype, and clears the subtype of the others
+func updateColumnSubtypes(data *FileData) {
+	subtypes := make(map[string]string)
+	for colIdx, header := range data.Headers {
+		colType := data.ColumnTypes[header]
+		if (colType == "numeric" || colType == "target") && isIntegerColumn(data.Data, colIdx) {
+			subtypes[header] = columnSubtypeInteger
+		}
+	}
+	data.ColumnSubtypes = subtypes
+}
+
+// exportRows returns the data rows for writing to a file, with the cells of integer
+// columns written without a decimal point, so that 5.0 is exported as 5. The subtypes
+// are refreshed first since edits may have added fractional values.
+func exportRows(data *FileData) [][]string {
+	updateColumnSubtypes(data)
+
+	var intCols []int
+	for colIdx, header := range data.Headers {
+		if data.ColumnSubtypes[header] == columnSubtypeInteger {
+			intCols = append(intCols, colIdx)
+		}
+	}
+	if len(intCols) == 0 {
+		return data.Data
+	}
+
+	rows := make([][]string, len(data.Data))
+	for i, row := range data.Data {
+		rows[i] = append([]string(nil), row...)
+		for _, colIdx := range intCols {
+			if colIdx >= len(row) {
+				continue
+			}
+			if v, ok := parseNumericValue(row[colIdx]); ok {
+				rows[i][colIdx] = types.FormatNumber(v)
+			}
+		}
+	}
+	return rows
+}
+
 // getColumnMean calculates the mean of numeric values in a column
 // Returns 0 if no numeric values are found
 func getColumnMean(data [][]string, colIdx int) float64 {
@@ -8,8 +8,13 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/url"
@@ -17,6 +22,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bitjungle/gopca/internal/config"
@@ -26,6 +32,7 @@ import (
 	"github.com/bitjungle/gopca/internal/version"
 	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
 	"github.com/bitjungle/gopca/pkg/integration"
+	"github.com/bitjungle/gopca/pkg/security"
 	"github.com/bitjungle/gopca/pkg/types"
 	"github.com/bitjungle/gopca/pkg/validation"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -36,6 +43,18 @@ import (
 type App struct {
 	ctx        context.Context
 	fileToOpen string
+
+	// pcaCancel cancels the running PCA analysis, if any
+	pcaMu     sync.Mutex
+	pcaCancel context.CancelFunc
+
+	// The last NIPALS fit, kept so that rerunning it with more components only
+	// computes the new ones. nipalsKey identifies its data and configuration apart
+	// from the number of components.
+	nipalsMu     sync.Mutex
+	nipalsFit    types.ComponentExtender
+	nipalsResult *types.PCAResult
+	nipalsKey    string
 }
 
 // NewApp creates a new App application struct
@@ -220,6 +239,8 @@ type PCARequest struct {
 	ExcludedRows    []int       `json:"excludedRows,omitempty"`
 	ExcludedColumns []int       `json:"excludedColumns,omitempty"`
 	MissingStrategy string      `json:"missingStrategy,omitempty"`
+	// Drop rows whose values are all identical instead of warning about them
+	DropZeroVarianceRows bool `json:"dropZeroVarianceRows,omitempty"`
 	// Kernel PCA parameters
 	KernelType   string  `json:"kernelType,omitempty"`
 	KernelGamma  float64 `json:"kernelGamma,omitempty"`
@@ -257,6 +278,17 @@ type PCAResponse struct {
 	// This ensures the frontend uses properly aligned data when coloring by category
 	FilteredCategoricalColumns   map[string][]string  `json:"filteredCategoricalColumns,omitempty"`
 	FilteredNumericTargetColumns map[string][]float64 `json:"filteredNumericTargetColumns,omitempty"`
+	// FilteredRowNames contains the row names of the scores when rows were dropped
+	FilteredRowNames []string `json:"filteredRowNames,omitempty"`
+}
+
+// CancelPCA cancels the PCA analysis started by RunPCA, if one is running
+func (a *App) CancelPCA() {
+	a.pcaMu.Lock()
+	defer a.pcaMu.Unlock()
+	if a.pcaCancel != nil {
+		a.pcaCancel()
+	}
 }
 
 // RunPCA performs PCA analysis on the provided data
@@ -519,6 +551,8 @@ func (a *App) RunPCA(request PCARequest) (response PCAResponse) {
 		ExcludedRows:    request.ExcludedRows,
 		ExcludedColumns: request.ExcludedColumns,
 		MissingStrategy: types.MissingValueStrategy(request.MissingStrategy),
+		// Checked on the data as filtered above, before preprocessing
+		DropZeroVarianceRows: request.DropZeroVarianceRows,
 	}
 
 	// Add kernel parameters if using kernel PCA
@@ -537,16 +571,56 @@ func (a *App) RunPCA(request PCARequest) (response PCAResponse) {
 		}
 	}
 
-	// Perform PCA
-	engine := core.NewPCAEngineForMethod(config.Method)
-	result, err := engine.Fit(dataToAnalyze, config)
+	// Clamp an over-request to the maximum instead of failing
+	clampMsg := ""
+	if len(dataToAnalyze) > 0 && len(dataToAnalyze[0]) > 0 {
+		rows, cols := len(dataToAnalyze), len(dataToAnalyze[0])
+		maxComponents := core.MaxInformativeComponents(rows, cols)
+		if config.Method == "kernel" {
+			maxComponents = max(1, rows-1)
+		}
+		maxComponents = min(maxComponents, security.MaxComponents)
+		if config.Components > maxComponents {
+			clampMsg = fmt.Sprintf("Reduced components from %d to %d, the maximum for %d rows and %d columns.",
+				config.Components, maxComponents, rows, cols)
+			config.Components = maxComponents
+		}
+	}
+
+	// Perform PCA with a context that CancelPCA can cancel
+	pcaCtx, cancel := context.WithCancel(context.Background())
+	a.pcaMu.Lock()
+	a.pcaCancel = cancel
+	a.pcaMu.Unlock()
+	defer func() {
+		a.pcaMu.Lock()
+		a.pcaCancel = nil
+		a.pcaMu.Unlock()
+		cancel()
+	}()
+
+	result, err := a.fitPCA(pcaCtx, dataToAnalyze, config)
 	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return PCAResponse{
+				Success: false,
+				Error:   "PCA analysis cancelled",
+			}
+		}
 		return PCAResponse{
 			Success: false,
 			Error:   fmt.Sprintf("PCA fit failed: %v", err),
 		}
 	}
 
+	// Keep the data, row names, labels and metadata aligned with the scores when the
+	// fit dropped zero-variance rows
+	if len(result.DroppedRows) > 0 {
+		var keepRows []int
+		dataToAnalyze, keepRows = core.RemoveRows(dataToAnalyze, result.DroppedRows)
+		keepRequestRows(&request, len(result.DroppedRows)+len(keepRows), keepRows)
+	}
+
 	// Update component labels to use filtered headers if needed
 	if len(result.ComponentLabels) == 0 {
 		result.ComponentLabels = make([]string, request.Components)
@@ -693,6 +767,15 @@ func (a *App) RunPCA(request PCARequest) (response PCAResponse) {
 			infoMsg = fmt.Sprintf("Imputed %d missing values with column medians.", missingInfo.TotalMissing)
 		}
 	}
+	if clampMsg != "" {
+		infoMsg = strings.TrimSpace(infoMsg + " " + clampMsg)
+	}
+	for _, warning := range result.Warnings {
+		infoMsg = strings.TrimSpace(infoMsg + " Warning: " + warning + ".")
+	}
+	if d := result.KernelDiagnostics; d != nil && (d.ClampedEigenvalues > 0 || d.NearSingular) {
+		infoMsg = strings.TrimSpace(infoMsg + " " + core.DescribeKernelDiagnostics(d) + ".")
+	}
 
 	// Calculate confidence ellipses for all confidence levels if groups are provided
 	var groupEllipses90, groupEllipses95, groupEllipses99 map[string]EllipseParams
@@ -740,12 +823,17 @@ func (a *App) RunPCA(request PCARequest) (response PCAResponse) {
 	// We pass it to the frontend so it can use properly aligned data when coloring by category
 	var filteredCategoricalCols map[string][]string
 	var filteredNumericTargetCols map[string][]float64
+	var filteredRowNames []string
 
-	if (rowsDropped > 0 && request.MissingStrategy == "drop") || rowsExcluded > 0 {
+	if (rowsDropped > 0 && request.MissingStrategy == "drop") || rowsExcluded > 0 || len(result.DroppedRows) > 0 {
 		// Pass the already-filtered metadata columns
 		filteredCategoricalCols = request.MetadataCategorical
 		filteredNumericTargetCols = request.MetadataNumeric
 	}
+	// Row names are filtered with dropped rows, but not with excluded rows
+	if (rowsDropped > 0 || len(result.DroppedRows) > 0) && len(request.RowNames) == len(result.Scores) {
+		filteredRowNames = request.RowNames
+	}
 
 	return PCAResponse{
 		Success:                      true,
@@ -756,6 +844,105 @@ func (a *App) RunPCA(request PCARequest) (response PCAResponse) {
 		GroupEllipses99:              groupEllipses99,
 		FilteredCategoricalColumns:   filteredCategoricalCols,
 		FilteredNumericTargetColumns: filteredNumericTargetCols,
+		FilteredRowNames:             filteredRowNames,
+	}
+}
+
+// fitPCA fits a PCA model to data. A NIPALS analysis of the same data and
+// configuration as the last one, but with more components, extends the last fit
+// instead of recomputing the components it already has.
+func (a *App) fitPCA(ctx context.Context, data types.Matrix, config types.PCAConfig) (*types.PCAResult, error) {
+	if config.Method != "nipals" {
+		return types.FitContext(ctx, core.NewPCAEngineForMethod(config.Method), data, config)
+	}
+
+	// Take the last fit, so that concurrent analyses do not share its engine
+	key := nipalsFitKey(data, config)
+	a.nipalsMu.Lock()
+	extender, prev := a.nipalsFit, a.nipalsResult
+	sameModel := a.nipalsKey == key
+	a.nipalsFit, a.nipalsResult, a.nipalsKey = nil, nil, ""
+	a.nipalsMu.Unlock()
+
+	var result *types.PCAResult
+	if extender != nil && sameModel && prev.ComponentsComputed < config.Components {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		extended, err := extender.FitAdditionalComponents(prev, config.Components-prev.ComponentsComputed)
+		if err == nil {
+			result = extended
+		} else {
+			fmt.Printf("Warning: failed to extend the last NIPALS fit, refitting: %v\n", err)
+		}
+	}
+	if result == nil {
+		config.KeepFitData = true
+		engine := core.NewPCAEngineForMethod(config.Method)
+		var err error
+		result, err = types.FitContext(ctx, engine, data, config)
+		if err != nil {
+			return nil, err
+		}
+		extender, _ = engine.(types.ComponentExtender)
+	}
+
+	// Only a fit with every requested component can be extended; components beyond
+	// the rank of the data are discarded and cannot be added later either
+	if extender != nil && result.ComponentsComputed == config.Components {
+		a.nipalsMu.Lock()
+		a.nipalsFit, a.nipalsResult, a.nipalsKey = extender, result, key
+		a.nipalsMu.Unlock()
+	}
+	return result, nil
+}
+
+// nipalsFitKey returns a checksum of the data and of the configuration apart from
+// the number of components
+func nipalsFitKey(data types.Matrix, config types.PCAConfig) string {
+	config.Components = 0
+	configJSON, _ := json.Marshal(config)
+	hash := sha256.New()
+	hash.Write(configJSON)
+	var buf []byte
+	for _, row := range data {
+		buf = binary.LittleEndian.AppendUint64(buf[:0], uint64(len(row)))
+		for _, v := range row {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(v))
+		}
+		hash.Write(buf)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// keepRequestRows keeps only the given rows, in order, of the row names, group labels
+// and metadata columns of a request for n rows
+func keepRequestRows(request *PCARequest, n int, keepRows []int) {
+	keepStrings := func(values []string) []string {
+		if len(values) != n {
+			return values
+		}
+		kept := make([]string, len(keepRows))
+		for i, r := range keepRows {
+			kept[i] = values[r]
+		}
+		return kept
+	}
+
+	request.RowNames = keepStrings(request.RowNames)
+	request.GroupLabels = keepStrings(request.GroupLabels)
+	for colName, colData := range request.MetadataCategorical {
+		request.MetadataCategorical[colName] = keepStrings(colData)
+	}
+	for colName, colData := range request.MetadataNumeric {
+		if len(colData) != n {
+			continue
+		}
+		kept := make([]float64, len(keepRows))
+		for i, r := range keepRows {
+			kept[i] = colData[r]
+		}
+		request.MetadataNumeric[colName] = kept
 	}
 }
 
@@ -1062,6 +1249,8 @@ type ModelMetricsRequest struct {
 type ModelMetricsResponse struct {
 	MostInfluentialVariable string  `json:"mostInfluentialVariable"`
 	LoadingValue            float64 `json:"loadingValue"`
+	OverallTopVariable      string  `json:"overallTopVariable"` // Highest importance across all components
+	ImportanceValue         float64 `json:"importanceValue"`    // Normalized importance of OverallTopVariable
 	RecommendedComponents   int     `json:"recommendedComponents"`
 	VarianceCaptured        float64 `json:"varianceCaptured"`
 	KaiserComponents        int     `json:"kaiserComponents"` // -1 if not applicable
@@ -1109,6 +1298,16 @@ func (a *App) CalculateModelMetrics(request ModelMetricsRequest) ModelMetricsRes
 		}
 	}
 
+	// Find the most important variable across all components
+	overallTopVar := ""
+	maxImportance := 0.0
+	for i, importance := range core.VariableImportance(request.Loadings, request.ExplainedVariance) {
+		if i < len(request.VariableLabels) && importance > maxImportance {
+			maxImportance = importance
+			overallTopVar = request.VariableLabels[i]
+		}
+	}
+
 	// Calculate variance-based recommendation (80% threshold)
 	recommendedComponents := 0
 	varianceCaptured := 0.0
@@ -1219,6 +1418,8 @@ func (a *App) CalculateModelMetrics(request ModelMetricsRequest) ModelMetricsRes
 	return ModelMetricsResponse{
 		MostInfluentialVariable: mostInfluentialVar,
 		LoadingValue:            maxLoading,
+		OverallTopVariable:      overallTopVar,
+		ImportanceValue:         maxImportance,
 		RecommendedComponents:   recommendedComponents,
 		VarianceCaptured:        varianceCaptured,
 		KaiserComponents:        kaiserComponents,
@@ -1403,53 +1604,47 @@ func (a *App) OpenInGoCSV(data *FileData) error {
 	return nil
 }
 
-// exportDataToCSV exports FileData to a CSV file
+// exportDataToCSV exports FileData, with its categorical and target columns, to a
+// CSV file. Missing values are written as empty cells.
 func (a *App) exportDataToCSV(data *FileData, filePath string) error {
-	var lines []string
-
-	// Add headers (with row name header if row names exist)
-	headers := data.Headers
-	if len(data.RowNames) > 0 {
-		headers = append([]string{"Row"}, headers...)
+	csvData := &types.CSVData{
+		Headers:     data.Headers,
+		RowNames:    data.RowNames,
+		Matrix:      data.Data,
+		MissingMask: data.MissingMask,
+		Rows:        len(data.Data),
+		Columns:     len(data.Headers),
+	}
+	grid := types.CSVDataToFileData(csvData, data.CategoricalColumns, data.NumericTargetColumns, data.Headers)
+
+	// Add the row name header if row names exist
+	records := make([][]string, 0, len(grid.Data)+1)
+	if len(grid.RowNames) > 0 {
+		records = append(records, append([]string{"Row"}, grid.Headers...))
+	} else {
+		records = append(records, grid.Headers)
 	}
-	lines = append(lines, strings.Join(headers, ","))
-
-	// Add data rows
-	for i, row := range data.Data {
-		// Convert float64 values to strings
-		rowStrings := make([]string, 0, len(row)+1)
-
-		// Add row name if present
-		if len(data.RowNames) > 0 && i < len(data.RowNames) {
-			rowStrings = append(rowStrings, data.RowNames[i])
-		}
-
-		// Convert numeric values to strings
-		for _, value := range row {
-			// Handle missing values
-			if data.MissingMask != nil && i < len(data.MissingMask) {
-				colIdx := len(rowStrings) - len(data.RowNames)
-				if colIdx >= 0 && colIdx < len(data.MissingMask[i]) && data.MissingMask[i][colIdx] {
-					rowStrings = append(rowStrings, "")
-					continue
-				}
-			}
-
-			// Format float value
-			str := fmt.Sprintf("%g", value)
-			// Quote if contains special characters
-			if strings.Contains(str, ",") || strings.Contains(str, "\"") || strings.Contains(str, "\n") {
-				str = fmt.Sprintf("\"%s\"", strings.ReplaceAll(str, "\"", "\"\""))
+	for i, row := range grid.Data {
+		if len(grid.RowNames) > 0 {
+			name := ""
+			if i < len(grid.RowNames) {
+				name = grid.RowNames[i]
 			}
-			rowStrings = append(rowStrings, str)
+			row = append([]string{name}, row...)
 		}
-
-		lines = append(lines, strings.Join(rowStrings, ","))
+		records = append(records, row)
 	}
 
-	// Write to file
-	content := strings.Join(lines, "\n")
-	return os.WriteFile(filePath, []byte(content), 0644)
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
 
 // LaunchGoCSV launches GoCSV without a file
@@ -7,6 +7,10 @@
 package main
 
 import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -102,3 +106,158 @@ func TestCalculateEllipsesWithInvalidData(t *testing.T) {
 		t.Error("Expected failure with mismatched lengths")
 	}
 }
+
+func TestRunPCAClampsComponents(t *testing.T) {
+	app := &App{}
+
+	data := [][]float64{
+		{5.1, 3.5, 1.4},
+		{4.9, 3.0, 1.4},
+		{6.2, 2.9, 4.3},
+		{5.9, 3.0, 5.1},
+		{6.7, 3.1, 4.4},
+		{5.0, 3.4, 1.5},
+	}
+	response := app.RunPCA(PCARequest{
+		Data:       data,
+		Components: 10,
+		MeanCenter: true,
+		Method:     "svd",
+	})
+	if !response.Success {
+		t.Fatalf("Expected over-requested components to be clamped, got error: %s", response.Error)
+	}
+	if got := len(response.Result.ExplainedVar); got != 3 {
+		t.Errorf("Expected 3 components for 6 rows × 3 columns, got %d", got)
+	}
+	if !strings.Contains(response.Info, "Reduced components from 10 to 3") {
+		t.Errorf("Expected an info message about the clamping, got %q", response.Info)
+	}
+
+	// A request within the maximum is left alone
+	response = app.RunPCA(PCARequest{Data: data, Components: 2, MeanCenter: true, Method: "svd"})
+	if !response.Success {
+		t.Fatalf("RunPCA failed: %s", response.Error)
+	}
+	if got := len(response.Result.ExplainedVar); got != 2 || response.Info != "" {
+		t.Errorf("Expected 2 components and no info message, got %d and %q", got, response.Info)
+	}
+}
+
+func TestRunPCAExtendsNIPALSFit(t *testing.T) {
+	app := &App{}
+
+	data := [][]float64{
+		{5.1, 3.5, 1.4, 0.2},
+		{4.9, 3.0, 1.4, 0.2},
+		{6.2, 2.9, 4.3, 1.3},
+		{5.9, 3.0, 5.1, 1.8},
+		{6.7, 3.1, 4.4, 1.4},
+		{5.0, 3.4, 1.5, 0.2},
+		{6.3, 3.3, 6.0, 2.5},
+		{5.7, 2.8, 4.1, 1.3},
+	}
+	request := PCARequest{Data: data, Components: 2, MeanCenter: true, StandardScale: true, Method: "nipals"}
+	if response := app.RunPCA(request); !response.Success {
+		t.Fatalf("RunPCA failed: %s", response.Error)
+	}
+	first := app.nipalsFit
+	if first == nil {
+		t.Fatal("Expected the NIPALS fit to be kept")
+	}
+
+	// More components extend the kept fit, with the same result as a fit from scratch
+	request.Components = 3
+	extended := app.RunPCA(request)
+	if !extended.Success {
+		t.Fatalf("RunPCA failed: %s", extended.Error)
+	}
+	if app.nipalsFit != first {
+		t.Error("Expected the kept NIPALS fit to be extended")
+	}
+	scratch := (&App{}).RunPCA(request)
+	if !scratch.Success {
+		t.Fatalf("RunPCA failed: %s", scratch.Error)
+	}
+	if len(extended.Result.ExplainedVar) != 3 {
+		t.Fatalf("Expected 3 components, got %d", len(extended.Result.ExplainedVar))
+	}
+	for i, v := range scratch.Result.ExplainedVar {
+		if math.Abs(float64(extended.Result.ExplainedVar[i]-v)) > 1e-6 {
+			t.Errorf("PC%d: extended variance %g, fit from scratch %g", i+1, extended.Result.ExplainedVar[i], v)
+		}
+	}
+
+	// Other preprocessing is a new fit
+	request.StandardScale = false
+	request.Components = 4
+	if response := app.RunPCA(request); !response.Success {
+		t.Fatalf("RunPCA failed: %s", response.Error)
+	}
+	if app.nipalsFit == first {
+		t.Error("Expected a new NIPALS fit for other preprocessing")
+	}
+}
+
+func TestRunPCADropZeroVarianceRows(t *testing.T) {
+	app := &App{}
+
+	response := app.RunPCA(PCARequest{
+		Data: [][]float64{
+			{5.1, 3.5, 1.4},
+			{4.9, 3.0, 1.4},
+			{7.0, 7.0, 7.0},
+			{5.9, 3.0, 5.1},
+			{6.7, 3.1, 4.4},
+			{5.0, 3.4, 1.5},
+		},
+		RowNames:             []string{"a", "b", "c", "d", "e", "f"},
+		Components:           2,
+		MeanCenter:           true,
+		Method:               "svd",
+		DropZeroVarianceRows: true,
+		GroupLabels:          []string{"x", "x", "y", "y", "z", "z"},
+		MetadataCategorical:  map[string][]string{"group": {"x", "x", "y", "y", "z", "z"}},
+	})
+	if !response.Success {
+		t.Fatalf("RunPCA failed: %s", response.Error)
+	}
+	if got := len(response.Result.Scores); got != 5 {
+		t.Fatalf("Expected 5 score rows after dropping row c, got %d", got)
+	}
+	if got := strings.Join(response.FilteredRowNames, ","); got != "a,b,d,e,f" {
+		t.Errorf("Expected row names a,b,d,e,f, got %s", got)
+	}
+	if got := strings.Join(response.FilteredCategoricalColumns["group"], ","); got != "x,x,y,z,z" {
+		t.Errorf("Expected group labels x,x,y,z,z, got %s", got)
+	}
+	if !strings.Contains(response.Info, "dropped 1 row(s) with zero variance") {
+		t.Errorf("Expected an info message about the dropped row, got %q", response.Info)
+	}
+}
+
+func TestExportDataToCSV(t *testing.T) {
+	app := &App{}
+	data := &FileData{
+		Headers:              []string{"x", "y"},
+		RowNames:             []string{"s1", "s2"},
+		Data:                 [][]float64{{1, 0.5}, {2.25, math.NaN()}},
+		MissingMask:          [][]bool{{false, false}, {false, true}},
+		CategoricalColumns:   map[string][]string{"group": {"a", "b, c"}},
+		NumericTargetColumns: map[string][]float64{"conc#target": {10, math.NaN()}},
+	}
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := app.exportDataToCSV(data, path); err != nil {
+		t.Fatalf("exportDataToCSV failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Row,x,y,group,conc#target\ns1,1,0.5,a,10\ns2,2.25,,\"b, c\",\n"
+	if string(content) != want {
+		t.Errorf("expected\n%q\ngot\n%q", want, content)
+	}
+}
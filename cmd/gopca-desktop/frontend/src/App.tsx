@@ -6,7 +6,7 @@
 
 import React, { useState, useRef, useEffect, lazy, Suspense } from 'react';
 import './App.css';
-import { ParseCSV, RunPCA, LoadIrisDataset, LoadDatasetFile, GetVersion, CalculateEllipses, GetGUIConfig, LoadCSVFile, CheckGoCSVStatus, OpenInGoCSV, LaunchGoCSV, DownloadGoCSV, SaveFile } from '../wailsjs/go/main/App';
+import { ParseCSV, RunPCA, CancelPCA, LoadIrisDataset, LoadDatasetFile, GetVersion, CalculateEllipses, GetGUIConfig, LoadCSVFile, CheckGoCSVStatus, OpenInGoCSV, LaunchGoCSV, DownloadGoCSV, SaveFile } from '../wailsjs/go/main/App';
 import { Copy, Check } from 'lucide-react';
 import { EventsOn } from '../wailsjs/runtime/runtime';
 import { DataTable, SelectionTable, MatrixIllustration, HelpWrapper, DocumentationViewer, ModelOverview } from './components';
@@ -39,6 +39,7 @@ function AppContent() {
     const [fileName, setFileName] = useState<string>('');
     const [pcaResponse, setPcaResponse] = useState<PCAResponse | null>(null);
     const [loading, setLoading] = useState(false);
+    const [pcaRunning, setPcaRunning] = useState(false);
     const [fileError, setFileError] = useState<string | null>(null);
     const [pcaError, setPcaError] = useState<string | null>(null);
     const [version, setVersion] = useState<string>('');
@@ -79,6 +80,7 @@ function AppContent() {
         vectorNorm: false,
         method: 'SVD',
         missingStrategy: 'error',
+        dropZeroVarianceRows: false,
         // Kernel PCA parameters
         kernelType: 'rbf',
         kernelGamma: 1.0,
@@ -391,6 +393,9 @@ return;
         if (config.missingStrategy && config.missingStrategy !== 'error') {
             cmd += ` --missing-strategy ${config.missingStrategy}`;
         }
+        if (config.dropZeroVarianceRows) {
+            cmd += ' --drop-zero-variance-rows';
+        }
 
         // Add excluded columns if any
         if (excludedColumns.length > 0) {
@@ -425,6 +430,7 @@ return;
 }
 
         setLoading(true);
+        setPcaRunning(true);
         setPcaError(null);
 
         try {
@@ -487,6 +493,15 @@ return;
             setPcaError(`Failed to run PCA: ${err}`);
         } finally {
             setLoading(false);
+            setPcaRunning(false);
+        }
+    };
+
+    const cancelPCA = async () => {
+        try {
+            await CancelPCA();
+        } catch (err) {
+            console.error('Failed to cancel PCA:', err);
         }
     };
 
@@ -1016,6 +1031,14 @@ return;
                                         <p className="text-xs text-gray-500 dark:text-gray-400 mt-1">
                                             Choose how to handle missing values (NaN) in your data
                                         </p>
+                                        <label className="flex items-center gap-2 mt-3 text-sm">
+                                            <input
+                                                type="checkbox"
+                                                checked={config.dropZeroVarianceRows}
+                                                onChange={(e) => setConfig({ ...config, dropZeroVarianceRows: e.target.checked })}
+                                            />
+                                            Drop rows with all-identical values
+                                        </label>
                                     </HelpWrapper>
 
                                     {/* Diagnostic Metrics Option */}
@@ -1033,6 +1056,14 @@ return;
                                         {loading ? 'Running...' : 'Go PCA!'}
                                     </button>
                                 </HelpWrapper>
+                                {pcaRunning && (
+                                    <button
+                                        onClick={cancelPCA}
+                                        className="ml-3 px-6 py-2 bg-gray-600 hover:bg-gray-700 rounded-lg font-medium text-white"
+                                    >
+                                        Cancel
+                                    </button>
+                                )}
                             </div>
 
                             {/* CLI Command Preview */}
@@ -1377,7 +1408,7 @@ return;
                                         {selectedPlot === 'scores' && pcaResponse.result.scores.length > 0 && pcaResponse.result.scores[0].length >= 2 ? (
                                             <ScoresPlot
                                                 pcaResult={pcaResponse.result}
-                                                rowNames={fileData?.rowNames || []}
+                                                rowNames={pcaResponse.filteredRowNames || fileData?.rowNames || []}
                                                 xComponent={selectedXComponent}
                                                 yComponent={selectedYComponent}
                                                 groupColumn={selectedGroupColumn}
@@ -1398,7 +1429,7 @@ return;
                                         ) : selectedPlot === 'scores3d' && pcaResponse.result.scores.length > 0 && pcaResponse.result.scores[0].length >= 3 ? (
                                             <Scores3DPlot
                                                 pcaResult={pcaResponse.result}
-                                                rowNames={fileData?.rowNames || []}
+                                                rowNames={pcaResponse.filteredRowNames || fileData?.rowNames || []}
                                                 xComponent={selectedXComponent}
                                                 yComponent={selectedYComponent}
                                                 zComponent={selectedZComponent}
@@ -1428,7 +1459,7 @@ return;
                                         ) : selectedPlot === 'biplot' ? (
                                             <Biplot
                                                 pcaResult={pcaResponse.result}
-                                                rowNames={fileData?.rowNames || []}
+                                                rowNames={pcaResponse.filteredRowNames || fileData?.rowNames || []}
                                                 xComponent={selectedXComponent}
                                                 yComponent={selectedYComponent}
                                                 showRowLabels={showRowLabels}
@@ -1450,7 +1481,7 @@ return;
                                         ) : selectedPlot === 'biplot3d' ? (
                                             <Biplot3D
                                                 pcaResult={pcaResponse.result}
-                                                rowNames={fileData?.rowNames || []}
+                                                rowNames={pcaResponse.filteredRowNames || fileData?.rowNames || []}
                                                 xComponent={selectedXComponent}
                                                 yComponent={selectedYComponent}
                                                 zComponent={selectedZComponent}
@@ -1480,7 +1511,7 @@ return;
                                         ) : selectedPlot === 'diagnostics' && pcaResponse.result.method !== 'kernel' ? (
                                             <DiagnosticScatterPlot
                                                 pcaResult={pcaResponse.result}
-                                                rowNames={fileData?.rowNames || []}
+                                                rowNames={pcaResponse.filteredRowNames || fileData?.rowNames || []}
                                                 showRowLabels={showRowLabels}
                                                 maxLabelsToShow={maxLabelsToShow}
                                                 confidenceLevel={confidenceLevel === 0.90 ? 0.95 : confidenceLevel}
@@ -19,6 +19,8 @@ interface ModelOverviewProps {
 interface ModelMetrics {
   mostInfluentialVariable: string;
   loadingValue: number;
+  overallTopVariable: string;
+  importanceValue: number;
   recommendedComponents: number;
   varianceCaptured: number;
   kaiserComponents: number;
@@ -55,6 +57,8 @@ export const ModelOverview: React.FC<ModelOverviewProps> = ({ pcaResult, selecte
           setMetrics({
             mostInfluentialVariable: response.mostInfluentialVariable,
             loadingValue: response.loadingValue,
+            overallTopVariable: response.overallTopVariable,
+            importanceValue: response.importanceValue,
             recommendedComponents: response.recommendedComponents,
             varianceCaptured: response.varianceCaptured,
             kaiserComponents: response.kaiserComponents,
@@ -137,6 +141,11 @@ export const ModelOverview: React.FC<ModelOverviewProps> = ({ pcaResult, selecte
                 <div className="text-xs text-gray-500 dark:text-gray-400">
                   Loading: {metrics.loadingValue.toFixed(3)}
                 </div>
+                {metrics.overallTopVariable && (
+                  <div className="text-xs text-gray-500 dark:text-gray-400">
+                    Overall: {metrics.overallTopVariable} ({(metrics.importanceValue * 100).toFixed(1)}%)
+                  </div>
+                )}
               </div>
             </div>
           </HelpWrapper>
@@ -33,6 +33,7 @@ export interface PCARequest {
   excludedRows?: number[];
   excludedColumns?: number[];
   missingStrategy?: string;
+  dropZeroVarianceRows?: boolean;
   // Kernel PCA parameters
   kernelType?: string;
   kernelGamma?: number;
@@ -104,4 +105,6 @@ export interface PCAResponse {
   // These ensure proper alignment with the reduced scores matrix
   filteredCategoricalColumns?: Record<string, string[]>;
   filteredNumericTargetColumns?: Record<string, number[]>;
+  // Row names of the scores, when rows were dropped
+  filteredRowNames?: string[];
 }
\ No newline at end of file
@@ -7,24 +7,44 @@
 package cobra
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bitjungle/gopca/internal/core"
+	"github.com/bitjungle/gopca/internal/utils"
 	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
 	"github.com/bitjungle/gopca/pkg/types"
 	"github.com/spf13/cobra"
+	"gonum.org/v1/gonum/mat"
 )
 
 // AnalyzeOptions holds all the options for the analyze command
 type AnalyzeOptions struct {
 	// PCA parameters
-	Components int
-	Method     string
+	Components     int
+	ComponentsMax  bool    // Fit the largest number of components the data supports
+	VarianceTarget float64 // Cumulative explained variance fraction that selects the components; 0 to use Components
+	Method         string
+
+	// NIPALS parameters
+	OrthogonalizeScores bool
+	NIPALSMaxIter       int
+	NIPALSFallbackToSVD bool
 
 	// Kernel PCA parameters
 	KernelType   string
-	KernelGamma  float64
+	KernelGamma  string
 	KernelDegree int
 	KernelCoef0  float64
 
@@ -32,41 +52,136 @@ type AnalyzeOptions struct {
 	MeanCenter      bool
 	Scale           string // "none", "standard", "robust"
 	ScaleOnly       bool
+	CenterOnly      bool // Mean center without scaling, stated explicitly
 	SNV             bool
 	VectorNorm      bool
+	VectorNormType  string // "l1", "l2", "max"
 	NoMeanCentering bool
+	// RobustScaleParams are the center and scale statistics of --scale robust, e.g. "median,mad"
+	RobustScaleParams string
 
 	// Data format options
-	NoHeaders  bool
-	NoIndex    bool
-	Delimiter  string
-	NAValues   string
-	TargetCols string
+	FormatPreset       string // Named delimiter and decimal convention: us, european, tsv or pipe
+	Headers            string // Whether the first row holds column names: yes, no or auto
+	NoHeaders          bool
+	NoIndex            bool
+	Delimiter          string
+	DecimalSeparator   string
+	ThousandsSeparator string
+	NAValues           string
+	TargetCols         string
+	DetectTargets      bool
+	CommentChar        string
+	IndexColumns       string
+	RaggedRows         string
+	DropDuplicates     string
+	ArchiveEntry       string // CSV entry to read from a zip archive with several
+	MaxFieldLength     int    // Longest field in characters; 0 for the security limit
+	OnOversizeField    string // Fields longer than MaxFieldLength: error, truncate or skip-column
 
 	// Missing data handling
-	MissingStrategy string
-	MissingPercent  float64
+	MissingStrategy      string
+	ImputeGroupBy        string // Categorical column whose groups mean and median imputation is done within
+	MissingPercent       float64
+	DropMissingCols      float64 // Drop columns with a larger fraction of missing values; 0 to keep all
+	DropZeroVarianceRows bool
+	ImputeReport         string
+	CacheCleaned         string // Save the cleaned data to this file for --use-cleaned
+	UseCleaned           string // Load cleaned data saved by --cache-cleaned instead of parsing the input
 
 	// Output options
 	OutputFormat   string
 	OutputDir      string
+	OutputTemplate string // File name template with {base}, {method} and {components}
+	JSONToFile     bool   // Write JSON next to the input file rather than to stdout
 	OutputScores   bool
 	OutputLoadings bool
 	OutputVariance bool
 	OutputAll      bool
 	IncludeMetrics bool
+	LoadingsFormat string
+	ScoresFormat   string
+	TSV            bool
+	Precision      int
+	JSONCompact    bool   // Write the JSON model without indentation
+	ScoresNDJSON   string // Stream scores to this file, one JSON object per observation
+	ScoresOnly     bool   // Output scores and explained variance only, without loadings or metrics
+
+	OutputReconstruction bool   // Write the data reconstructed from the components in original units
+	NormalizeScores      bool   // Divide each score column by √eigenvalue for unit-variance scores
+	CarryCols            string // Categorical or target columns copied next to the scores in CSV and JSON output
+	ExportComponents     string // 1-based components whose scores and loadings are written to <base>_components.csv
+
+	// Outlier detection
+	OutlierMethod       string  // Statistic that flags outliers in the metrics: t2, mahalanobis, spe or combined
+	OutlierSignificance float64 // Significance level of the outlier test
+	WeightsFromMetrics  bool    // Refit with rows downweighted by their T² and SPE
+	RobustIterations    int     // Reweighting passes of WeightsFromMetrics
+	OutputInfluence     bool    // Export T² against Q with their limits for an influence plot
+
+	// Terminal plot
+	PlotASCII bool   // Draw PC1 against PC2 scores in the terminal
+	PlotGroup string // Categorical column whose categories get their own markers in the plot
+
+	LoadingsThreshold float64
+
+	// Supplementary points
+	SupplementaryGroups string
+
+	// Group score summary
+	GroupSummary string
+
+	// Per-group analysis
+	PerGroup string
+
+	// Eigencorrelations
+	CorrelationMethod string
+	RobustCovariance  bool
 
 	// Exclude options
 	ExcludeRows    string
 	ExcludeColumns string
 
+	// Row selection
+	SelectRows string // Rows to keep, by 1-based index, range or row name
+	BalanceBy  string // Downsample every category of this column to the smallest one
+	Seed       int64  // Seed for the row sample of BalanceBy
+
+	// Diagnostics
+	ScaleReport         bool
+	ScaleRatioThreshold float64
+	Recommend           bool
+	ValidateOnly        bool
+	ComponentAdvice     bool
+	ComponentStatus     string // Criterion labeling each component signal or noise: parallel, broken-stick or kaiser
+	OutputVarianceCurve bool   // Export the explained variance of all components with the elbow of the curve
+	CheckLoadings       bool
+	CheckTolerance      float64
+
+	// Batch mode
+	Batch bool
+	Jobs  int
+
 	// Verbose output
 	Verbose bool
+	Quiet   bool
+
+	// jsonStdout receives the JSON model instead of a file when no output directory
+	// or file name template is given
+	jsonStdout io.Writer
+
+	// excludedColumns are the 0-based input indices of the columns removed by
+	// --exclude-columns, recorded in the model metadata
+	excludedColumns []int
 }
 
+// stdinInput is the input argument that reads the data from standard input
+const stdinInput = "-"
+
 // NewAnalyzeCommand creates the analyze subcommand
 func NewAnalyzeCommand() *cobra.Command {
 	opts := &AnalyzeOptions{}
+	var components string
 
 	cmd := &cobra.Command{
 		Use:   "analyze [flags] <input.csv>",
@@ -77,6 +192,21 @@ The analyze command performs PCA on your data using various algorithms
 and preprocessing options. It supports multiple output formats and
 advanced diagnostics.
 
+Files with a .tsv or .tab extension are read as tab-separated unless
+--delimiter is given, and --tsv writes tab-separated output files.
+
+A zip archive holding a single CSV or TSV file is read like the file itself;
+name the entry with --archive-entry when it holds several.
+
+Give - as the input to read CSV from standard input. The JSON model is then
+written to standard output, with all other messages on standard error, unless
+--output-dir is given; output files are named stdin_pca.json and so on.
+
+With --batch, the argument is a directory and the same analysis is run on
+every *.csv, *.tsv and *.tab file in it. Results are written per file,
+failures are reported in a summary at the end, and up to --jobs files are
+analyzed in parallel.
+
 EXAMPLES:
   # Basic PCA with 2 components
   pca analyze data.csv --components 2
@@ -84,34 +214,209 @@ EXAMPLES:
   # PCA with standardization and metrics
   pca analyze --standard-scale --include-metrics data.csv
 
+  # Mean centering only, for variables already on a common scale
+  pca analyze --center-only data.csv
+
   # Kernel PCA with RBF kernel
   pca analyze --method kernel --kernel-type rbf data.csv
 
+  # RBF kernel with gamma chosen from the data
+  pca analyze --method kernel --kernel-gamma auto data.csv
+
+  # Row names from a composite key, e.g. "subjectA|visit1"
+  pca analyze --index-columns subject,visit data.csv
+
+  # Let the first row decide whether the file has column names
+  pca analyze --headers auto --verbose data.csv
+
+  # Skip '#' metadata lines at the top of an instrument export
+  pca analyze --comment-char '#' export.csv
+
+  # European number format such as 1.234,56
+  pca analyze --delimiter ';' --decimal-separator comma --thousands-sep dot data.csv
+
+  # The same convention as a preset, here with pipe-delimited fields
+  pca analyze --format-preset european --delimiter '|' data.csv
+
+  # Fill rows with missing trailing fields instead of failing
+  pca analyze --ragged-rows pad --missing-strategy mean data.csv
+
+  # Remove repeated measurements that appear as identical rows
+  pca analyze --drop-duplicates data.csv
+
+  # Analyze the first 50 rows and two named samples only
+  pca analyze --select-rows 1-50,ctrl_a,ctrl_b data.csv
+
+  # Downsample every species to the same size so no class dominates PC1
+  pca analyze --balance-by species --seed 42 iris.csv
+
   # Handle missing data by dropping rows
   pca analyze --missing-strategy drop data.csv
 
+  # Impute missing values and list every imputed cell for review
+  pca analyze --missing-strategy median --impute-report imputed.csv data.csv
+
+  # Impute with the median of each species rather than of all rows
+  pca analyze --missing-strategy median --impute-group-by species iris.csv
+
+  # Impute once, then reuse the cleaned data while trying other settings
+  pca analyze --missing-strategy median --cache-cleaned cleaned.json data.csv
+  pca analyze --missing-strategy median --use-cleaned cleaned.json --scale standard data.csv
+
   # NIPALS with native missing value handling
   pca analyze --method nipals --missing-strategy native data.csv
 
   # Output to JSON with full results
-  pca analyze -f json --output-dir results/ data.csv`,
+  pca analyze -f json --output-dir results/ data.csv
+
+  # Project species centroids as supplementary points
+  pca analyze --supplementary-groups species iris.csv
+
+  # Mean scores per species, to check group separation
+  pca analyze --group-summary species iris.csv
+
+  # Separate PCA model per batch, with a summary of explained variance
+  pca analyze --per-group batch -f csv --output-dir results/ data.csv
+
+  # Outlier-resistant eigencorrelations with target and categorical columns
+  pca analyze --target-columns yield --robust-covariance data.csv
+
+  # Analyze every CSV file in a directory, four files at a time
+  pca analyze --batch --jobs 4 --output-dir results/ data/
+
+  # Check which scaling the data needs without running PCA
+  pca analyze --recommend data.csv
+
+  # Check that the data is ready for PCA before a long run
+  pca analyze --validate-only --exclude-columns id data.csv
+
+  # Compare component selection heuristics before choosing --components
+  pca analyze --component-advice --scale standard data.csv
+
+  # Mark which of the first five components stand out from noise
+  pca analyze -c 5 --scale standard --component-status parallel data.csv
+
+  # Export the variance curve of all components with its elbow for a scree plot
+  pca analyze --output-explained-variance-curve -f csv data.csv
+
+  # Keep enough components to explain 90% of the variance
+  pca analyze --variance-target 0.90 --scale standard data.csv
+
+  # Fit as many components as the data supports
+  pca analyze --components max --scale standard data.csv
+
+  # Verify that the loadings are orthonormal and the scores orthogonal
+  pca analyze --check-loadings --method nipals data.csv
+
+  # Quick look at the score plot over SSH, one marker per species
+  pca analyze --plot-ascii --plot-group species iris.csv
+
+  # Hide small loadings to show the simple structure
+  pca analyze --loadings-threshold 0.3 iris.csv
+
+  # CSV files with loadings in tidy (long) format
+  pca analyze -f csv --loadings-format tidy data.csv
+
+  # Round exported values to 6 significant digits
+  pca analyze -f json --precision 6 data.csv
+
+  # Encode the settings in the file names when sweeping parameters
+  pca analyze -f json --components 3 --output-template "{base}_{method}_{components}pc" data.csv
+
+  # Write the 2-component approximation of the data in original units
+  pca analyze --components 2 --output-reconstruction data.csv
+
+  # Export PC1, PC3 and PC5 for a 3D plotting tool
+  pca analyze --components 5 --export-components 1,3,5 data.csv
+
+  # Unit-variance scores, e.g. for clustering
+  pca analyze -f csv --normalize-scores data.csv
+
+  # Flag observations far from the model plane (Q residuals) at the 1% level
+  pca analyze --include-metrics --outlier-method spe --outlier-significance 0.01 data.csv
+
+  # Downweight outlying rows by their T² and SPE and refit
+  pca analyze --weights-from-metrics --robust-iterations 5 data.csv
+
+  # Export T² against Q with their 95% limits for an influence plot
+  pca analyze --include-metrics --output-influence -f csv data.csv
+
+  # Compact JSON model with scores streamed one observation per line
+  pca analyze -f json --json-compact --scores-ndjson scores.ndjson data.csv
+
+  # Save the JSON model as data_pca.json next to the input instead of printing it
+  pca analyze -f json --json-to-file data.csv
+
+  # Tab-separated input and output files
+  pca analyze --tsv data.tsv
+
+  # Analyze CSV from a pipeline and process the JSON model
+  curl -s https://example.com/data.csv | pca analyze -f json - | jq .model.explained_variance_ratio`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if opts.Components, opts.ComponentsMax, err = parseComponents(components); err != nil {
+				return err
+			}
+			if opts.TSV {
+				if cmd.Flags().Changed("format") && opts.OutputFormat != "csv" {
+					return fmt.Errorf("--tsv cannot be combined with --format %s", opts.OutputFormat)
+				}
+				opts.OutputFormat = "csv"
+			}
+			if err := applyFormatPreset(cmd, opts); err != nil {
+				return err
+			}
+			if opts.ImputeReport != "" && opts.Batch {
+				return fmt.Errorf("--impute-report cannot be combined with --batch")
+			}
+			if opts.ScoresNDJSON != "" && opts.Batch {
+				return fmt.Errorf("--scores-ndjson cannot be combined with --batch")
+			}
+			if opts.ValidateOnly && opts.Batch {
+				return fmt.Errorf("--validate-only cannot be combined with --batch")
+			}
+			if opts.PlotASCII && opts.Batch {
+				return fmt.Errorf("--plot-ascii cannot be combined with --batch")
+			}
+			if (opts.CacheCleaned != "" || opts.UseCleaned != "") && opts.Batch {
+				return fmt.Errorf("--cache-cleaned and --use-cleaned cannot be combined with --batch")
+			}
+			if opts.Batch && args[0] == stdinInput {
+				return fmt.Errorf("--batch needs a directory and cannot read from standard input")
+			}
+			if opts.Batch {
+				// Batch results go to files, so default to JSON instead of a table
+				if !cmd.Flags().Changed("format") && !opts.TSV {
+					opts.OutputFormat = "json"
+				}
+				return runAnalyzeBatch(opts, args[0])
+			}
 			return runAnalyze(opts, args[0])
 		},
 	}
 
 	// PCA parameters
-	cmd.Flags().IntVarP(&opts.Components, "components", "c", 2,
-		"Number of principal components")
+	cmd.Flags().StringVarP(&components, "components", "c", "2",
+		"Number of principal components, or \"max\" for min(rows−1, columns)")
+	cmd.Flags().Float64Var(&opts.VarianceTarget, "variance-target", 0,
+		"Retain the fewest components explaining at least this fraction of the variance, in (0,1]; overrides --components")
 	cmd.Flags().StringVarP(&opts.Method, "method", "m", "svd",
 		"PCA method: svd, nipals, or kernel")
 
+	// NIPALS parameters
+	cmd.Flags().BoolVar(&opts.OrthogonalizeScores, "orthogonalize-scores", false,
+		"Re-orthogonalize NIPALS scores after each component (Gram-Schmidt)")
+	cmd.Flags().IntVar(&opts.NIPALSMaxIter, "nipals-max-iter", core.NIPALSDefaultMaxIter,
+		"Maximum NIPALS iterations per component")
+	cmd.Flags().BoolVar(&opts.NIPALSFallbackToSVD, "nipals-fallback-svd", false,
+		"Refit with SVD when a NIPALS component does not converge (complete data only)")
+
 	// Kernel PCA parameters
 	cmd.Flags().StringVar(&opts.KernelType, "kernel-type", "rbf",
 		"Kernel type for kernel PCA: linear, poly, rbf")
-	cmd.Flags().Float64Var(&opts.KernelGamma, "kernel-gamma", 0.01,
-		"Gamma parameter for RBF/poly kernels")
+	cmd.Flags().StringVar(&opts.KernelGamma, "kernel-gamma", "0.01",
+		"Gamma parameter for RBF/poly kernels, or auto (1/(n_features·var(X))) or median (1/median squared distance) for RBF")
 	cmd.Flags().IntVar(&opts.KernelDegree, "kernel-degree", 3,
 		"Degree for polynomial kernel")
 	cmd.Flags().Float64Var(&opts.KernelCoef0, "kernel-coef0", 0.0,
@@ -124,34 +429,82 @@ EXAMPLES:
 		"Scaling method: none, standard, robust")
 	cmd.Flags().BoolVar(&opts.ScaleOnly, "scale-only", false,
 		"Scale without centering")
+	cmd.Flags().BoolVar(&opts.CenterOnly, "center-only", false,
+		"Mean center without scaling; the defaults, stated explicitly")
 	cmd.Flags().BoolVar(&opts.SNV, "snv", false,
 		"Apply Standard Normal Variate transformation")
 	cmd.Flags().BoolVar(&opts.VectorNorm, "vector-norm", false,
-		"Apply L2 vector normalization (row-wise)")
+		"Apply vector normalization (row-wise)")
+	cmd.Flags().StringVar(&opts.VectorNormType, "vector-norm-type", string(core.VectorNormL2),
+		"Norm for --vector-norm: l1, l2, max")
+	cmd.Flags().StringVar(&opts.RobustScaleParams, "robust-scale-params", "median,mad",
+		"Center and scale statistics for --scale robust: <median|trimmed-mean>,<mad|iqr>")
 
 	// Data format options
+	cmd.Flags().StringVar(&opts.FormatPreset, "format-preset", "",
+		"Delimiter and decimal convention: "+strings.Join(pkgcsv.PresetNames(), ", ")+
+			"; --delimiter and --decimal-separator override it")
+	cmd.Flags().StringVar(&opts.Headers, "headers", "yes",
+		"Whether the first row contains column names: yes, no, or auto to detect it from the data")
 	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
-		"First row contains data, not column names")
+		"First row contains data, not column names (same as --headers no)")
 	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
 		"First column contains data, not row names")
-	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", ",",
-		"CSV field delimiter")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+	cmd.Flags().StringVar(&opts.DecimalSeparator, "decimal-separator", "dot",
+		"Decimal separator: dot or comma")
+	cmd.Flags().StringVar(&opts.ThousandsSeparator, "thousands-sep", "none",
+		"Digit grouping separator removed from numbers: none, dot, comma, space or apostrophe")
 	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
 		"Comma-separated list of strings representing missing values")
 	cmd.Flags().StringVar(&opts.TargetCols, "target-columns", "",
 		"Comma-separated list of target columns to exclude")
+	cmd.Flags().BoolVar(&opts.DetectTargets, "detect-targets", false,
+		"Warn when the last numeric column looks like a target (named y, response, target, conc, ... or uncorrelated with the others)")
+	cmd.Flags().StringVar(&opts.CommentChar, "comment-char", "",
+		"Skip lines starting with this prefix, such as '#' for instrument metadata")
+	cmd.Flags().StringVar(&opts.IndexColumns, "index-columns", "",
+		"Comma-separated column names or numbers joined with '|' into row names, instead of the first column")
+	cmd.Flags().StringVar(&opts.RaggedRows, "ragged-rows", "error",
+		"Rows with more or fewer fields than the header: error, pad (fill short rows with missing values) or truncate (also drop extra fields)")
+	cmd.Flags().StringVar(&opts.DropDuplicates, "drop-duplicates", "keep",
+		"Exact duplicate data rows, which bias the covariance: keep, drop-first (keep the first occurrence) or drop-last (keep the last); give the value as --drop-duplicates=drop-last, the bare flag means drop-first")
+	cmd.Flags().Lookup("drop-duplicates").NoOptDefVal = "drop-first"
+	cmd.Flags().StringVar(&opts.ArchiveEntry, "archive-entry", "",
+		"CSV entry to read when the input is a zip archive holding several CSV files")
+	cmd.Flags().IntVar(&opts.MaxFieldLength, "max-field-length", 0,
+		fmt.Sprintf("Longest field in characters (default: the security limit of %d)", security.MaxFieldLength))
+	cmd.Flags().StringVar(&opts.OnOversizeField, "on-oversize-field", "error",
+		"Fields longer than --max-field-length: error, truncate (cut to the limit) or skip-column (remove their columns)")
 
 	// Missing data handling
 	cmd.Flags().StringVar(&opts.MissingStrategy, "missing-strategy", "error",
 		"Strategy for missing values: error (default), mean, median, zero, drop, native (NIPALS only)")
+	cmd.Flags().StringVar(&opts.ImputeGroupBy, "impute-group-by", "",
+		"Categorical column whose groups the mean and median are computed within, falling back to all rows for groups without observed values")
 	cmd.Flags().Float64Var(&opts.MissingPercent, "missing-percent", 50.0,
 		"Maximum missing percentage before dropping")
+	cmd.Flags().Float64Var(&opts.DropMissingCols, "drop-missing-cols", 0,
+		"Drop numeric columns whose fraction of missing values exceeds this, in (0,1), before the missing strategy (default off)")
+	cmd.Flags().BoolVar(&opts.DropZeroVarianceRows, "drop-zero-variance-rows", false,
+		"Drop rows whose values are all identical instead of warning")
+	cmd.Flags().StringVar(&opts.ImputeReport, "impute-report", "",
+		"Write a CSV listing every imputed cell, or the cells that caused rows to be dropped")
+	cmd.Flags().StringVar(&opts.CacheCleaned, "cache-cleaned", "",
+		"Save the data after parsing and missing value handling to this file, for reuse with --use-cleaned")
+	cmd.Flags().StringVar(&opts.UseCleaned, "use-cleaned", "",
+		"Load data saved with --cache-cleaned instead of parsing and cleaning the input again")
 
 	// Output options
 	cmd.Flags().StringVarP(&opts.OutputFormat, "format", "f", "table",
-		"Output format: table, json")
+		"Output format: table, json, csv")
 	cmd.Flags().StringVarP(&opts.OutputDir, "output-dir", "o", "",
-		"Output directory for results")
+		"Output directory for results (created if it does not exist)")
+	cmd.Flags().StringVar(&opts.OutputTemplate, "output-template", "",
+		"Name output files after this template instead of the input file, e.g. \"{base}_{method}_{components}pc\"")
+	cmd.Flags().BoolVar(&opts.JSONToFile, "json-to-file", false,
+		"Write JSON output next to the input file when no output directory is given, instead of to stdout")
 	cmd.Flags().BoolVar(&opts.OutputScores, "output-scores", true,
 		"Include PC scores in output")
 	cmd.Flags().BoolVar(&opts.OutputLoadings, "output-loadings", true,
@@ -162,28 +515,509 @@ EXAMPLES:
 		"Output all results")
 	cmd.Flags().BoolVar(&opts.IncludeMetrics, "include-metrics", false,
 		"Calculate and include advanced metrics")
+	cmd.Flags().StringVar(&opts.LoadingsFormat, "loadings-format", "wide",
+		"CSV layout for loadings: wide (variables × components) or tidy (variable,component,loading)")
+	cmd.Flags().BoolVar(&opts.TSV, "tsv", false,
+		"Write tab-separated .tsv output files (shorthand for --format csv with tabs)")
+	cmd.Flags().StringVar(&opts.ScoresFormat, "scores-format", "wide",
+		"CSV layout for scores: wide (observations × components) or tidy (observation,component,score)")
+	cmd.Flags().IntVar(&opts.Precision, "precision", -1,
+		"Significant digits for scores, loadings and variance in JSON and CSV output (negative for full precision)")
+	cmd.Flags().BoolVar(&opts.JSONCompact, "json-compact", false,
+		"Write the JSON model on a single line without indentation")
+	cmd.Flags().StringVar(&opts.ScoresNDJSON, "scores-ndjson", "",
+		"Write scores to this file as newline-delimited JSON (one observation per line); the JSON model then omits scores")
+	cmd.Flags().BoolVar(&opts.ScoresOnly, "scores-only", false,
+		"Output only scores and explained variance, without loadings or metrics; the JSON cannot be used by transform")
+	cmd.Flags().BoolVar(&opts.OutputReconstruction, "output-reconstruction", false,
+		"Write the data reconstructed from the components, in original units, to <base>_reconstruction.csv")
+	cmd.Flags().BoolVar(&opts.NormalizeScores, "normalize-scores", false,
+		"Output scores scaled to unit variance per component (divided by √eigenvalue); loadings are not rescaled")
+	cmd.Flags().StringVar(&opts.CarryCols, "carry-cols", "",
+		"Comma-separated categorical or target columns to copy next to the scores in CSV and JSON output")
+	cmd.Flags().StringVar(&opts.ExportComponents, "export-components", "",
+		"Write the scores (and loadings) of these components, e.g. 1,3,5, to <base>_components.csv for 3D plotting tools")
+
+	// Outlier detection
+	cmd.Flags().StringVar(&opts.OutlierMethod, "outlier-method", core.OutlierMethodT2,
+		"Statistic that flags outliers in --include-metrics: t2, mahalanobis, spe (Q residuals) or combined (t2 or spe)")
+	cmd.Flags().Float64Var(&opts.OutlierSignificance, "outlier-significance", core.DefaultOutlierSignificance,
+		"Significance level of the outlier test; smaller values flag fewer observations")
+	cmd.Flags().BoolVar(&opts.WeightsFromMetrics, "weights-from-metrics", false,
+		"Refit with rows downweighted by their T² and SPE (Huber weights) for outlier-resistant components")
+	cmd.Flags().IntVar(&opts.RobustIterations, "robust-iterations", core.DefaultRobustIterations,
+		"Number of reweighting passes of --weights-from-metrics")
+	cmd.Flags().BoolVar(&opts.OutputInfluence, "output-influence", false,
+		"With --include-metrics, export the T² against Q influence plot coordinates with their 95% limits")
+
+	// Terminal plot
+	cmd.Flags().BoolVar(&opts.PlotASCII, "plot-ascii", false,
+		"Draw a scatter plot of PC1 against PC2 scores in the terminal (skipped if output is not a terminal)")
+	cmd.Flags().StringVar(&opts.PlotGroup, "plot-group", "",
+		"Categorical column whose categories are drawn with their own markers in --plot-ascii")
+
+	cmd.Flags().Float64Var(&opts.LoadingsThreshold, "loadings-threshold", 0,
+		"Hide loadings with absolute value below this threshold in table output (display only)")
+
+	// Supplementary points
+	cmd.Flags().StringVar(&opts.SupplementaryGroups, "supplementary-groups", "",
+		"Categorical column whose category centroids are projected as supplementary points")
+
+	// Group score summary
+	cmd.Flags().StringVar(&opts.GroupSummary, "group-summary", "",
+		"Categorical column whose per-category mean scores are shown in table output")
+
+	// Per-group analysis
+	cmd.Flags().StringVar(&opts.PerGroup, "per-group", "",
+		"Fit a separate PCA model for each category of this categorical column")
+
+	// Eigencorrelations
+	cmd.Flags().StringVar(&opts.CorrelationMethod, "correlation-method", "",
+		"Correlate scores with target and categorical columns: pearson, spearman, robust")
+	cmd.Flags().BoolVar(&opts.RobustCovariance, "robust-covariance", false,
+		"Use outlier-resistant Winsorized eigencorrelations (same as --correlation-method robust)")
 
 	// Exclude options
 	cmd.Flags().StringVar(&opts.ExcludeRows, "exclude-rows", "",
-		"Comma-separated list of row indices to exclude (1-based)")
+		"Rows to exclude: comma-separated 1-based indices, ranges (e.g. 1-50) or row names")
 	cmd.Flags().StringVar(&opts.ExcludeColumns, "exclude-columns", "",
-		"Comma-separated list of column names or indices to exclude")
+		"Columns to exclude: comma-separated 1-based indices, ranges (e.g. 4-6) or column names")
+
+	// Row selection
+	cmd.Flags().StringVar(&opts.SelectRows, "select-rows", "",
+		"Keep only these rows: comma-separated 1-based indices, ranges such as 1-50, or row names")
+	cmd.Flags().StringVar(&opts.BalanceBy, "balance-by", "",
+		"Downsample every category of this categorical column to the size of the smallest one")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 1,
+		"Seed for the random row sample of --balance-by")
+
+	// Diagnostics
+	cmd.Flags().BoolVar(&opts.ScaleReport, "center-and-scale-report", false,
+		"Print per-column ranges and variances before analysis")
+	cmd.Flags().Float64Var(&opts.ScaleRatioThreshold, "scale-ratio-threshold", core.DefaultScaleRatioThreshold,
+		"Warn when the largest/smallest column variance ratio exceeds this and no scaling is applied")
+	cmd.Flags().BoolVar(&opts.Recommend, "recommend", false,
+		"Print recommended preprocessing (scaling, SNV) for the data and exit without running PCA")
+	cmd.Flags().BoolVar(&opts.ValidateOnly, "validate-only", false,
+		"Check that the data is ready for PCA, report its dimensions after exclusions and exit without running PCA")
+	cmd.Flags().BoolVar(&opts.ComponentAdvice, "component-advice", false,
+		"Print the number of components suggested by Kaiser, broken-stick, parallel analysis and cumulative variance")
+	cmd.Flags().StringVar(&opts.ComponentStatus, "component-status", "",
+		"Label each component signal or noise by parallel (analysis), broken-stick or kaiser, in the variance table and JSON")
+	cmd.Flags().BoolVar(&opts.OutputVarianceCurve, "output-explained-variance-curve", false,
+		"Output the explained and cumulative variance of all components, with the elbow of the curve as a suggested number of components")
+	cmd.Flags().BoolVar(&opts.CheckLoadings, "check-loadings", false,
+		"Verify that loadings are orthonormal and scores orthogonal (in feature space for kernel PCA), failing if not")
+	cmd.Flags().Float64Var(&opts.CheckTolerance, "check-tolerance", core.DefaultOrthonormalityTolerance,
+		"Largest deviation accepted by --check-loadings")
+
+	// Batch mode
+	cmd.Flags().BoolVar(&opts.Batch, "batch", false,
+		"Treat the argument as a directory and analyze every *.csv, *.tsv and *.tab file in it")
+	cmd.Flags().IntVar(&opts.Jobs, "jobs", runtime.NumCPU(),
+		"Number of files to analyze in parallel in batch mode, or of workers computing eigencorrelations")
 
 	// Verbose output
 	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false,
 		"Enable verbose output")
+	cmd.Flags().BoolVarP(&opts.Quiet, "quiet", "q", false,
+		"Suppress warnings")
 
 	return cmd
 }
 
 // runAnalyze executes the analyze command
 func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
+	if err := validateCSVLayout("loadings-format", opts.LoadingsFormat); err != nil {
+		return err
+	}
+	if err := validateCSVLayout("scores-format", opts.ScoresFormat); err != nil {
+		return err
+	}
+	if opts.LoadingsThreshold < 0 {
+		return fmt.Errorf("loadings threshold must be non-negative, got %g", opts.LoadingsThreshold)
+	}
+	if opts.RobustCovariance {
+		if opts.CorrelationMethod != "" && opts.CorrelationMethod != "robust" {
+			return fmt.Errorf("--robust-covariance conflicts with --correlation-method %s", opts.CorrelationMethod)
+		}
+		opts.CorrelationMethod = "robust"
+	}
+	if opts.ScoresOnly {
+		if (opts.IncludeMetrics || opts.OutputAll) && !opts.Quiet {
+			fmt.Println("Warning: --scores-only omits loadings and metrics; ignoring --include-metrics and --output-all")
+		}
+		opts.OutputLoadings = false
+		opts.IncludeMetrics = false
+		opts.OutputAll = false
+		// The fit leaves out the loadings, so nothing that needs them can be requested
+		for _, option := range []struct {
+			flag string
+			set  bool
+		}{
+			{"--supplementary-groups", opts.SupplementaryGroups != ""},
+			{"--check-loadings", opts.CheckLoadings},
+			{"--output-reconstruction", opts.OutputReconstruction},
+			{"--weights-from-metrics", opts.WeightsFromMetrics},
+		} {
+			if option.set {
+				return fmt.Errorf("--scores-only cannot be combined with %s, which needs the loadings", option.flag)
+			}
+		}
+	}
+	switch opts.CorrelationMethod {
+	case "", "pearson", "spearman", "robust":
+	default:
+		return fmt.Errorf("invalid correlation method %q: must be pearson, spearman or robust", opts.CorrelationMethod)
+	}
+	if _, heuristic, err := parseKernelGamma(opts.KernelGamma); err != nil {
+		return err
+	} else if heuristic != "" && opts.Method == "kernel" && opts.KernelType != string(core.KernelRBF) {
+		return fmt.Errorf("--kernel-gamma %s is only supported for the rbf kernel", heuristic)
+	}
+	if _, err := core.ParseVectorNormType(opts.VectorNormType); err != nil {
+		return err
+	}
+	if opts.CenterOnly {
+		switch {
+		case opts.ScaleOnly:
+			return fmt.Errorf("--center-only cannot be combined with --scale-only")
+		case opts.NoMeanCentering:
+			return fmt.Errorf("--center-only cannot be combined with --no-mean-centering")
+		case opts.Scale != "none":
+			return fmt.Errorf("--center-only cannot be combined with --scale %s", opts.Scale)
+		}
+	}
+	if center, scale, err := core.ParseRobustScaleParams(opts.RobustScaleParams); err != nil {
+		return err
+	} else if (center != core.RobustCenterMedian || scale != core.RobustScaleMAD) && opts.Scale != "robust" {
+		return fmt.Errorf("--robust-scale-params %s requires --scale robust", opts.RobustScaleParams)
+	}
+	if opts.NIPALSMaxIter < 1 {
+		return fmt.Errorf("--nipals-max-iter must be at least 1, got %d", opts.NIPALSMaxIter)
+	}
+	if opts.CheckLoadings && opts.CheckTolerance <= 0 {
+		return fmt.Errorf("--check-tolerance must be positive, got %g", opts.CheckTolerance)
+	}
+	if opts.ComponentAdvice && opts.Method == "kernel" {
+		return fmt.Errorf("--component-advice is not available for kernel PCA")
+	}
+	switch opts.ComponentStatus {
+	case "", core.StatusCriterionParallel, core.StatusCriterionBrokenStick, core.StatusCriterionKaiser:
+	default:
+		return fmt.Errorf("invalid --component-status %q: must be parallel, broken-stick or kaiser", opts.ComponentStatus)
+	}
+	if opts.ComponentStatus != "" && opts.Method == "kernel" {
+		return fmt.Errorf("--component-status is not available for kernel PCA")
+	}
+	if opts.OutputVarianceCurve && opts.Method == "kernel" {
+		return fmt.Errorf("--output-explained-variance-curve is not available for kernel PCA")
+	}
+	if opts.DropMissingCols < 0 || opts.DropMissingCols >= 1 {
+		return fmt.Errorf("--drop-missing-cols must be in (0,1), got %g", opts.DropMissingCols)
+	}
+	if opts.VarianceTarget < 0 || opts.VarianceTarget > 1 {
+		return fmt.Errorf("--variance-target must be in (0,1], got %g", opts.VarianceTarget)
+	}
+	if opts.ImputeGroupBy != "" && opts.MissingStrategy != "mean" && opts.MissingStrategy != "median" {
+		return fmt.Errorf("--impute-group-by requires --missing-strategy mean or median")
+	}
+	if opts.ImputeReport != "" {
+		if opts.MissingStrategy == "error" || opts.MissingStrategy == "native" {
+			return fmt.Errorf("--impute-report requires --missing-strategy drop, mean or median")
+		}
+		if err := security.ValidateOutputPath(opts.ImputeReport); err != nil {
+			return fmt.Errorf("invalid impute report path: %w", err)
+		}
+	}
+	if opts.CacheCleaned != "" {
+		if opts.UseCleaned != "" {
+			return fmt.Errorf("--cache-cleaned cannot be combined with --use-cleaned")
+		}
+		if err := security.ValidateOutputPath(opts.CacheCleaned); err != nil {
+			return fmt.Errorf("invalid cleaned data cache path: %w", err)
+		}
+	}
+	if opts.UseCleaned != "" && opts.ImputeReport != "" {
+		return fmt.Errorf("--impute-report cannot be combined with --use-cleaned, which skips missing value handling")
+	}
+	if opts.OutputTemplate != "" {
+		if err := validateOutputTemplate(opts.OutputTemplate); err != nil {
+			return err
+		}
+	}
+	if opts.ScoresNDJSON != "" {
+		if err := security.ValidateOutputPath(opts.ScoresNDJSON); err != nil {
+			return fmt.Errorf("invalid scores NDJSON path: %w", err)
+		}
+	}
+	if opts.SelectRows != "" && opts.ExcludeRows != "" {
+		return fmt.Errorf("--select-rows cannot be combined with --exclude-rows")
+	}
+	if opts.ExportComponents != "" {
+		if components, err := utils.ParseRanges(opts.ExportComponents); err != nil {
+			return fmt.Errorf("invalid --export-components: %w", err)
+		} else if len(components) == 0 {
+			return fmt.Errorf("--export-components lists no components")
+		}
+	}
+	switch opts.OutlierMethod {
+	case core.OutlierMethodT2, core.OutlierMethodMahalanobis, core.OutlierMethodSPE, core.OutlierMethodCombined:
+	default:
+		return fmt.Errorf("invalid outlier method %q: must be t2, mahalanobis, spe or combined", opts.OutlierMethod)
+	}
+	if !(opts.OutlierSignificance > 0 && opts.OutlierSignificance < 1) {
+		return fmt.Errorf("outlier significance must be between 0 and 1, got %g", opts.OutlierSignificance)
+	}
+	if opts.WeightsFromMetrics {
+		if opts.Method == "kernel" {
+			return fmt.Errorf("--weights-from-metrics is not available for kernel PCA, which has no residuals")
+		}
+		if opts.RobustIterations < 1 {
+			return fmt.Errorf("--robust-iterations must be at least 1, got %d", opts.RobustIterations)
+		}
+	}
+	if opts.OutputInfluence {
+		if !opts.IncludeMetrics {
+			return fmt.Errorf("--output-influence requires --include-metrics")
+		}
+		if opts.Method == "kernel" {
+			return fmt.Errorf("--output-influence is not available for kernel PCA, which has no residuals")
+		}
+	}
+	if opts.NormalizeScores && opts.Method == "kernel" {
+		return fmt.Errorf("--normalize-scores is not available for kernel PCA, whose eigenvalues are not the variances of the scores")
+	}
+	if opts.OutputReconstruction {
+		if opts.Method == "kernel" {
+			return fmt.Errorf("--output-reconstruction is not available for kernel PCA, which has no loadings")
+		}
+		if opts.SNV || opts.VectorNorm {
+			return fmt.Errorf("--output-reconstruction cannot be combined with --snv or --vector-norm, " +
+				"as row-wise preprocessing cannot be inverted")
+		}
+	}
+
+	if inputFile == stdinInput {
+		if opts.PerGroup != "" {
+			return fmt.Errorf("--per-group cannot be combined with input from standard input")
+		}
+		if opts.CacheCleaned != "" || opts.UseCleaned != "" {
+			return fmt.Errorf("--cache-cleaned and --use-cleaned need an input file, not standard input")
+		}
+		if opts.OutputFormat == "csv" && opts.OutputDir == "" {
+			return fmt.Errorf("CSV output of standard input needs --output-dir")
+		}
+		if opts.JSONToFile {
+			return fmt.Errorf("--json-to-file needs an input file, use --output-dir for standard input")
+		}
+	}
+
+	// Without an output directory the JSON model goes to stdout, and all other
+	// messages to stderr so that the JSON can be piped on. An input file keeps it in
+	// a file when a file name template is given or --json-to-file is set, and per
+	// group models are always written to files.
+	if opts.OutputFormat == "json" && opts.OutputDir == "" &&
+		(inputFile == stdinInput || (opts.OutputTemplate == "" && !opts.JSONToFile && opts.PerGroup == "")) {
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+		opts.jsonStdout = stdout
+	}
+
+	parseOpts, err := analyzeParseOptions(opts, inputFile)
+	if err != nil {
+		return err
+	}
+
+	var data *pkgcsv.Data
+	var droppedColumns []string
+	if opts.UseCleaned != "" {
+		data, droppedColumns, err = readCleanedCache(opts.UseCleaned, inputFile, opts, parseOpts)
+		if err != nil {
+			return err
+		}
+		if !opts.Quiet {
+			fmt.Printf("Using cleaned data from %s (%d rows, %d columns)\n", opts.UseCleaned, data.Rows, data.Columns)
+		}
+	} else {
+		data, err = readAnalyzeInput(opts, inputFile, parseOpts)
+		if err != nil {
+			return err
+		}
+		if opts.SelectRows != "" || opts.ExcludeRows != "" || opts.BalanceBy != "" {
+			if data, err = selectAnalyzeRows(opts, data); err != nil {
+				return err
+			}
+		}
+		if opts.ExcludeColumns != "" {
+			if err := excludeAnalyzeColumns(opts, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.SupplementaryGroups != "" {
+		if opts.Method == "kernel" {
+			return fmt.Errorf("supplementary groups are not supported for kernel PCA")
+		}
+		if _, ok := data.CategoricalColumns[opts.SupplementaryGroups]; !ok {
+			return fmt.Errorf("supplementary groups column %q is not a categorical column", opts.SupplementaryGroups)
+		}
+	}
+
+	if opts.GroupSummary != "" {
+		if _, ok := data.CategoricalColumns[opts.GroupSummary]; !ok {
+			return fmt.Errorf("group summary column %q is not a categorical column", opts.GroupSummary)
+		}
+	}
+
+	if opts.PlotGroup != "" {
+		if !opts.PlotASCII {
+			return fmt.Errorf("--plot-group requires --plot-ascii")
+		}
+		if _, ok := data.CategoricalColumns[opts.PlotGroup]; !ok {
+			return fmt.Errorf("plot group column %q is not a categorical column", opts.PlotGroup)
+		}
+	}
+
+	for _, name := range carryColumnNames(opts.CarryCols) {
+		_, categorical := data.CategoricalColumns[name]
+		_, target := data.NumericTargetColumns[name]
+		if !categorical && !target {
+			return fmt.Errorf("carry column %q is not a categorical or target column", name)
+		}
+	}
+
+	if opts.PerGroup != "" {
+		if _, ok := data.CategoricalColumns[opts.PerGroup]; !ok {
+			return fmt.Errorf("per-group column %q is not a categorical column", opts.PerGroup)
+		}
+		if opts.ExcludeRows != "" {
+			return fmt.Errorf("--exclude-rows cannot be combined with --per-group")
+		}
+		if opts.ImputeReport != "" {
+			return fmt.Errorf("--impute-report cannot be combined with --per-group")
+		}
+		if opts.ScoresNDJSON != "" {
+			return fmt.Errorf("--scores-ndjson cannot be combined with --per-group")
+		}
+		if opts.CacheCleaned != "" || opts.UseCleaned != "" {
+			return fmt.Errorf("--cache-cleaned and --use-cleaned cannot be combined with --per-group")
+		}
+	}
+
+	if opts.ValidateOnly {
+		return validateAnalyzeInput(opts, data, inputFile)
+	}
+
+	if opts.Recommend {
+		outputPreprocessingRecommendations(core.RecommendPreprocessing(data.Matrix))
+		return nil
+	}
+
+	if opts.PerGroup != "" {
+		return runAnalyzePerGroup(opts, data, inputFile)
+	}
+
+	// Cached data has already been cleaned
+	if opts.UseCleaned == "" {
+		droppedColumns, err = cleanAnalyzeData(opts, data)
+		if err != nil {
+			return err
+		}
+		if opts.CacheCleaned != "" {
+			if err := writeCleanedCache(opts.CacheCleaned, inputFile, opts, parseOpts, data, droppedColumns); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = fitAnalyzeData(opts, data, inputFile, droppedColumns)
+	return err
+}
+
+// applyFormatPreset sets the delimiter and decimal separator of --format-preset,
+// unless they were given explicitly
+func applyFormatPreset(cmd *cobra.Command, opts *AnalyzeOptions) error {
+	if opts.FormatPreset == "" {
+		return nil
+	}
+	preset, err := pkgcsv.PresetOptions(opts.FormatPreset)
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("delimiter") {
+		opts.Delimiter = string(preset.Delimiter)
+	}
+	if !cmd.Flags().Changed("decimal-separator") {
+		opts.DecimalSeparator = string(preset.DecimalSeparator)
+	}
+	return nil
+}
+
+// analyzeParseOptions builds the CSV parsing options of the analyze command
+func analyzeParseOptions(opts *AnalyzeOptions, inputFile string) (pkgcsv.Options, error) {
+	decimal, err := parseDecimalSeparator(opts.DecimalSeparator)
+	if err != nil {
+		return pkgcsv.Options{}, err
+	}
+	thousands, err := parseThousandsSeparator(opts.ThousandsSeparator)
+	if err != nil {
+		return pkgcsv.Options{}, err
+	}
+	if thousands == decimal {
+		return pkgcsv.Options{}, fmt.Errorf("--thousands-sep must differ from --decimal-separator")
+	}
+	raggedRows, err := types.ParseRaggedRowPolicy(opts.RaggedRows)
+	if err != nil {
+		return pkgcsv.Options{}, err
+	}
+	duplicates, err := pkgcsv.ParseDuplicatePolicy(opts.DropDuplicates)
+	if err != nil {
+		return pkgcsv.Options{}, fmt.Errorf("--drop-duplicates: %w", err)
+	}
+	oversize, err := pkgcsv.ParseOversizePolicy(opts.OnOversizeField)
+	if err != nil {
+		return pkgcsv.Options{}, fmt.Errorf("--on-oversize-field: %w", err)
+	}
+	if opts.MaxFieldLength < 0 {
+		return pkgcsv.Options{}, fmt.Errorf("--max-field-length must not be negative, got %d", opts.MaxFieldLength)
+	}
+
 	// Parse CSV options
 	parseOpts := pkgcsv.DefaultOptions()
-	parseOpts.HasHeaders = !opts.NoHeaders
+	switch opts.Headers {
+	case "yes":
+		parseOpts.HasHeaders = !opts.NoHeaders
+	case "no":
+		parseOpts.HasHeaders = false
+	case "auto":
+		if opts.NoHeaders {
+			return pkgcsv.Options{}, fmt.Errorf("--no-headers cannot be combined with --headers auto")
+		}
+		parseOpts.AutoHeaders = true
+	default:
+		return pkgcsv.Options{}, fmt.Errorf("invalid --headers %q: must be yes, no or auto", opts.Headers)
+	}
 	parseOpts.HasRowNames = !opts.NoIndex
-	parseOpts.Delimiter = rune(opts.Delimiter[0])
+	parseOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+	if err := types.ValidateSeparators(parseOpts.Delimiter, decimal); err != nil {
+		return pkgcsv.Options{}, fmt.Errorf("--delimiter and --decimal-separator conflict: %w", err)
+	}
+	parseOpts.DecimalSeparator = decimal
+	parseOpts.ThousandsSeparator = thousands
 	parseOpts.ParseMode = pkgcsv.ParseMixedWithTargets
+	parseOpts.CommentPrefix = opts.CommentChar
+	parseOpts.SkipBlankLines = true
+	parseOpts.RaggedRows = raggedRows
+	parseOpts.Duplicates = duplicates
+	parseOpts.MaxFieldLength = opts.MaxFieldLength
+	parseOpts.OnOversizeField = oversize
+	parseOpts.ArchiveEntry = opts.ArchiveEntry
+	if opts.IndexColumns != "" {
+		setIndexColumns(&parseOpts, opts.IndexColumns)
+	}
 
 	// Parse NA values
 	if opts.NAValues != "" {
@@ -198,16 +1032,82 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 		parseOpts.TargetSuffix = "#target"
 	}
 
+	parseOpts.AutoDetectTargets = opts.DetectTargets
+	return parseOpts, nil
+}
+
+// readAnalyzeInput parses and validates the input file, printing parser warnings
+func readAnalyzeInput(opts *AnalyzeOptions, inputFile string, parseOpts pkgcsv.Options) (*pkgcsv.Data, error) {
 	// Load CSV data with target column detection
 	reader := pkgcsv.NewReader(parseOpts)
-	data, err := reader.ReadFile(inputFile)
-	if err != nil {
-		return fmt.Errorf("failed to parse CSV: %w", err)
+	var data *pkgcsv.Data
+	if inputFile == stdinInput {
+		// Standard input cannot be read twice or checked for size in advance, so it is
+		// read into memory up to the file size limit
+		input, err := pkgcsv.ReadAllLimited(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read standard input: %w", err)
+		}
+		data, err = reader.Read(bytes.NewReader(input))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+	} else {
+		var err error
+		data, err = reader.ReadFile(inputFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+	}
+	if parseOpts.AutoHeaders && opts.Verbose {
+		if data.HeaderDetected {
+			fmt.Println("Header row detected: reading the first row as column names")
+		} else {
+			fmt.Println("No header row detected: reading the first row as data")
+		}
+	}
+	if !opts.Quiet {
+		for _, warning := range data.Warnings {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+		for _, name := range data.SuggestedTargets {
+			fmt.Printf("Warning: column %q looks like a target variable but is included in the PCA; "+
+				"rename it to %q to exclude it\n", name, name+"#target")
+		}
 	}
 
 	// Validate data
 	if err := validateCSVData(data); err != nil {
-		return fmt.Errorf("data validation failed: %w", err)
+		return nil, fmt.Errorf("data validation failed: %w", err)
+	}
+	return data, nil
+}
+
+// analyzeData handles missing values, fits the PCA model on loaded data and
+// writes the results. Output file names are derived from inputFile.
+func analyzeData(opts *AnalyzeOptions, data *pkgcsv.Data, inputFile string) (*types.PCAResult, error) {
+	droppedColumns, err := cleanAnalyzeData(opts, data)
+	if err != nil {
+		return nil, err
+	}
+	return fitAnalyzeData(opts, data, inputFile, droppedColumns)
+}
+
+// cleanAnalyzeData drops mostly missing columns, handles missing values and
+// zero-variance rows in place, and returns the names of the dropped columns
+func cleanAnalyzeData(opts *AnalyzeOptions, data *pkgcsv.Data) ([]string, error) {
+	// Drop mostly missing columns first, so the missing value strategy has less to fill in
+	var droppedColumns []string
+	if opts.DropMissingCols > 0 {
+		var err error
+		droppedColumns, err = dropMissingColumns(data, opts.DropMissingCols)
+		if err != nil {
+			return nil, err
+		}
+		if len(droppedColumns) > 0 && !opts.Quiet {
+			fmt.Printf("Dropped %d column(s) with more than %g%% missing values: %s\n",
+				len(droppedColumns), opts.DropMissingCols*100, strings.Join(droppedColumns, ", "))
+		}
 	}
 
 	// Early detection and reporting of missing values
@@ -248,13 +1148,13 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 		// Validate method compatibility with native strategy
 		if opts.MissingStrategy == "native" {
 			if strings.ToLower(opts.Method) != "nipals" {
-				return fmt.Errorf("native missing value handling is only supported with the NIPALS method, not %s", opts.Method)
+				return nil, fmt.Errorf("native missing value handling is only supported with the NIPALS method, not %s", opts.Method)
 			}
 		}
 
 		// Check if using SVD with missing values without proper strategy
 		if strings.ToLower(opts.Method) == "svd" && opts.MissingStrategy == "error" {
-			return fmt.Errorf("missing values detected (%d values, %.1f%%). SVD requires complete data. "+
+			return nil, fmt.Errorf("missing values detected (%d values, %.1f%%). SVD requires complete data. "+
 				"Use --missing-strategy with one of: drop, mean, median, zero. "+
 				"Or use --method nipals with --missing-strategy native for native handling",
 				missingInfo.TotalMissing, missingPercent)
@@ -266,7 +1166,7 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 		// Handle missing values based on strategy
 		if opts.MissingStrategy != "drop" && opts.MissingStrategy != "mean" &&
 			opts.MissingStrategy != "median" && opts.MissingStrategy != "zero" {
-			return fmt.Errorf("invalid missing value strategy: %s. Valid options are: error, drop, mean, median, zero, native (NIPALS only)", opts.MissingStrategy)
+			return nil, fmt.Errorf("invalid missing value strategy: %s. Valid options are: error, drop, mean, median, zero, native (NIPALS only)", opts.MissingStrategy)
 		}
 
 		if opts.Verbose {
@@ -276,25 +1176,31 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 		if missingInfo.HasMissing() {
 			// Handle missing values using the specified strategy
 			handler := core.NewMissingValueHandler(types.MissingValueStrategy(opts.MissingStrategy))
-			cleanData, err := handler.HandleMissingValues(data.Matrix, missingInfo, selectedCols)
+			if opts.ImputeGroupBy != "" {
+				groups, ok := data.CategoricalColumns[opts.ImputeGroupBy]
+				if !ok {
+					return nil, fmt.Errorf("impute group column %q is not a categorical column", opts.ImputeGroupBy)
+				}
+				if err := handler.SetGroups(groups); err != nil {
+					return nil, err
+				}
+			}
+			cleanData, changes, err := handler.HandleMissingValuesWithChanges(data.Matrix, missingInfo, selectedCols)
 			if err != nil {
-				return fmt.Errorf("failed to handle missing values: %w", err)
+				return nil, fmt.Errorf("failed to handle missing values: %w", err)
 			}
 
-			// Update data matrix and affected row names for drop strategy
-			if opts.MissingStrategy == "drop" && len(data.RowNames) > 0 {
-				// Filter row names to match the cleaned data
-				cleanRowNames := make([]string, 0, len(cleanData))
-				droppedRows := make(map[int]bool)
-				for _, row := range missingInfo.RowsAffected {
-					droppedRows[row] = true
-				}
-				for i, name := range data.RowNames {
-					if !droppedRows[i] {
-						cleanRowNames = append(cleanRowNames, name)
-					}
+			// Row names are needed before dropped rows are removed
+			if opts.ImputeReport != "" {
+				if err := writeImputeReport(opts.ImputeReport, data, changes); err != nil {
+					return nil, err
 				}
-				data.RowNames = cleanRowNames
+			}
+
+			// Update row names and row-aligned columns for drop strategy
+			if opts.MissingStrategy == "drop" {
+				_, keepRows := core.RemoveRows(data.Matrix, missingInfo.RowsAffected)
+				filterDataRows(data, keepRows)
 			}
 
 			data.Matrix = cleanData
@@ -310,6 +1216,11 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 				}
 			}
 		}
+	} else if opts.ImputeReport != "" {
+		// Nothing to impute, but the report is still written so it can be relied on
+		if err := writeImputeReport(opts.ImputeReport, data, &core.MissingValueChanges{}); err != nil {
+			return nil, err
+		}
 	} else if opts.MissingStrategy == "native" && missingInfo.HasMissing() {
 		// NIPALS will handle missing values internally
 		if opts.Verbose {
@@ -317,6 +1228,41 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 		}
 	}
 
+	// Rows with all-identical values are checked on the raw data, before column preprocessing
+	zeroRows, err := core.CheckForZeroVarianceRows(data.Matrix)
+	if err != nil {
+		return nil, fmt.Errorf("data validation failed: %w", err)
+	}
+	if len(zeroRows) > 0 {
+		names := make([]string, len(zeroRows))
+		for i, r := range zeroRows {
+			names[i] = fmt.Sprintf("row %d", r+1)
+			if r < len(data.RowNames) {
+				names[i] = data.RowNames[r]
+			}
+		}
+		if opts.DropZeroVarianceRows {
+			var keepRows []int
+			data.Matrix, keepRows = core.RemoveRows(data.Matrix, zeroRows)
+			filterDataRows(data, keepRows)
+			data.Rows = len(data.Matrix)
+			if opts.Verbose {
+				fmt.Printf("Dropped %d zero-variance rows (%s). Data now has %d rows.\n",
+					len(zeroRows), strings.Join(names, ", "), data.Rows)
+			}
+		} else if !opts.Quiet {
+			fmt.Printf("Warning: %d row(s) have zero variance (all values identical): %s. "+
+				"Use --drop-zero-variance-rows to remove them.\n", len(zeroRows), strings.Join(names, ", "))
+		}
+	}
+
+	return droppedColumns, nil
+}
+
+// fitAnalyzeData fits the PCA model on cleaned data and writes the results. Output
+// file names are derived from inputFile.
+func fitAnalyzeData(opts *AnalyzeOptions, data *pkgcsv.Data, inputFile string,
+	droppedColumns []string) (*types.PCAResult, error) {
 	// Create PCA configuration
 	meanCenter := !opts.NoMeanCentering
 	standardScale := opts.Scale == "standard"
@@ -332,22 +1278,57 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 		SNV:             opts.SNV,
 		VectorNorm:      opts.VectorNorm,
 		MissingStrategy: types.MissingValueStrategy(opts.MissingStrategy),
+		DroppedColumns:  droppedColumns,
+		ScoresOnly:      opts.ScoresOnly,
+		// Zero-variance rows were handled on the raw data by cleanAnalyzeData
+		ZeroVarianceRowsChecked: true,
+	}
+	if opts.VectorNorm {
+		config.VectorNormType = opts.VectorNormType
+	}
+	if robustScale {
+		center, scale, err := core.ParseRobustScaleParams(opts.RobustScaleParams)
+		if err != nil {
+			return nil, err
+		}
+		config.RobustCenter, config.RobustScaleStat = string(center), string(scale)
+	}
+
+	if opts.Method == "nipals" {
+		config.OrthogonalizeScores = opts.OrthogonalizeScores
+		config.NIPALSMaxIter = opts.NIPALSMaxIter
+		config.NIPALSFallbackToSVD = opts.NIPALSFallbackToSVD
 	}
 
 	// Add kernel parameters if using kernel PCA
+	var gammaHeuristic string
+	var err error
 	if opts.Method == "kernel" {
 		config.KernelType = opts.KernelType
-		config.KernelGamma = opts.KernelGamma
+		config.KernelGamma, gammaHeuristic, err = parseKernelGamma(opts.KernelGamma)
+		if err != nil {
+			return nil, err
+		}
 		config.KernelDegree = opts.KernelDegree
 		config.KernelCoef0 = opts.KernelCoef0
 	}
 
-	// Parse exclude options
+	// Parse exclude options; the excluded columns are already removed from data
 	if opts.ExcludeRows != "" {
 		config.ExcludedRows = parseExcludeIndices(opts.ExcludeRows)
 	}
-	if opts.ExcludeColumns != "" {
-		config.ExcludedColumns = parseExcludeColumns(opts.ExcludeColumns, data.Headers)
+	config.ExcludedColumns = opts.excludedColumns
+
+	// Check for variables on very different scales
+	ranges, variances, scaleRatio := core.ScaleDiagnostics(data.Matrix)
+	if opts.ScaleReport {
+		outputScaleReport(data, ranges, variances, scaleRatio)
+	}
+	scaled := config.StandardScale || config.RobustScale || config.ScaleOnly
+	if !scaled && !opts.Quiet && scaleRatio > opts.ScaleRatioThreshold {
+		fmt.Printf("Warning: column variances differ by a factor of %.0f (threshold %.0f). "+
+			"Unscaled PCA will be dominated by the highest-variance variables; consider --scale standard.\n",
+			scaleRatio, opts.ScaleRatioThreshold)
 	}
 
 	// Create preprocessor
@@ -359,67 +1340,808 @@ func runAnalyze(opts *AnalyzeOptions, inputFile string) error {
 		config.SNV,
 		config.VectorNorm,
 	)
+	preprocessor.VectorNormType = core.VectorNormType(config.VectorNormType)
+	preprocessor.RobustCenter = core.RobustCenter(config.RobustCenter)
+	preprocessor.RobustScaleStat = core.RobustScaleStat(config.RobustScaleStat)
 
 	// Apply preprocessing
 	processedData, err := preprocessor.FitTransform(data.Matrix)
 	if err != nil {
-		return fmt.Errorf("preprocessing failed: %w", err)
+		return nil, fmt.Errorf("preprocessing failed: %w", err)
+	}
+	printWarnings(preprocessor.Warnings(), opts.Quiet)
+
+	// Choose gamma from the data the kernel is computed on
+	if gammaHeuristic != "" {
+		config.KernelGamma, err = core.EstimateKernelGamma(processedData, gammaHeuristic)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Verbose {
+			fmt.Printf("Kernel gamma (%s): %.6g\n", gammaHeuristic, config.KernelGamma)
+		}
+	}
+
+	// Fit every component to find how many reach the variance target
+	rows, cols := len(processedData), len(processedData[0])
+	if opts.VarianceTarget > 0 {
+		config.Components, err = componentsForVarianceTarget(processedData, config, opts.VarianceTarget)
+		if err != nil {
+			return nil, err
+		}
+		if !opts.Quiet {
+			fmt.Printf("Components selected: %d (variance target %g%%)\n",
+				config.Components, opts.VarianceTarget*100)
+		}
+	} else if opts.ComponentsMax {
+		config.Components = core.MaxInformativeComponents(rows, cols)
+		if config.Method == "kernel" {
+			// Kernel PCA has one component per sample, less one for centering
+			config.Components = max(1, rows-1)
+		}
+		config.Components = min(config.Components, security.MaxComponents)
+		if !opts.Quiet {
+			fmt.Printf("Components selected: %d (maximum for %d rows × %d columns)\n", config.Components, rows, cols)
+		}
+	} else {
+		maxComponents := core.CalculateMaxComponents(rows, cols)
+		if config.Method == "kernel" {
+			maxComponents = rows
+		}
+		if config.Components > maxComponents {
+			return nil, fmt.Errorf("requested %d components, but %d rows × %d columns support at most %d; "+
+				"use --components max to fit the maximum", config.Components, rows, cols, maxComponents)
+		}
+	}
+
+	// Compare selection heuristics; this does not change the number of components
+	if opts.ComponentAdvice {
+		advice, err := core.AdviseComponents(processedData)
+		if err != nil {
+			return nil, fmt.Errorf("component advice failed: %w", err)
+		}
+		outputComponentAdvice(advice, config.Components)
 	}
 
 	// Create and run PCA
 	pca := core.NewPCAEngineForMethod(config.Method)
 	result, err := pca.Fit(processedData, config)
 	if err != nil {
-		return fmt.Errorf("PCA analysis failed: %w", err)
+		return nil, fmt.Errorf("PCA analysis failed: %w", err)
+	}
+	printWarnings(result.Warnings, opts.Quiet)
+	if d := result.KernelDiagnostics; d != nil {
+		if d.NearSingular && !opts.Quiet {
+			fmt.Printf("Warning: %s\n", core.DescribeKernelDiagnostics(d))
+		} else if opts.Verbose {
+			fmt.Println(core.DescribeKernelDiagnostics(d))
+		}
+	}
+
+	// Refit with outlying rows downweighted; everything below uses the refitted model
+	if opts.WeightsFromMetrics {
+		var reweighting *core.RobustReweighting
+		result, reweighting, err = core.RobustReweightPCA(context.Background(), processedData, result, opts.RobustIterations)
+		if err != nil {
+			return nil, fmt.Errorf("robust reweighting failed: %w", err)
+		}
+		if !opts.Quiet {
+			outputRobustReweighting(reweighting, data)
+		}
+	}
+
+	// Label the components signal or noise from the eigenvalues of the preprocessed data
+	if opts.ComponentStatus != "" {
+		result.ComponentStatus, err = core.ComponentStatus(processedData, len(result.ComponentLabels), opts.ComponentStatus)
+		if err != nil {
+			return nil, fmt.Errorf("component status failed: %w", err)
+		}
+	}
+
+	// The curve covers all components of the preprocessed data, not only those fitted
+	if opts.OutputVarianceCurve {
+		eigenvalues, err := core.CovarianceEigenvalues(processedData)
+		if err != nil {
+			return nil, fmt.Errorf("explained variance curve failed: %w", err)
+		}
+		result.VarianceCurve = core.ExplainedVarianceCurve(eigenvalues)
+	}
+
+	// Project category centroids as supplementary points; they do not influence the fit
+	if opts.SupplementaryGroups != "" {
+		supplementary, err := core.ProjectGroupCentroids(processedData, result.Loadings,
+			data.CategoricalColumns[opts.SupplementaryGroups])
+		if err != nil {
+			return nil, fmt.Errorf("failed to project supplementary groups: %w", err)
+		}
+		supplementary.Column = opts.SupplementaryGroups
+		result.SupplementaryGroups = supplementary
+	}
+
+	// Correlate scores with metadata columns
+	if opts.CorrelationMethod != "" {
+		if len(data.NumericTargetColumns) == 0 && len(data.CategoricalColumns) == 0 {
+			if !opts.Quiet {
+				fmt.Println("Warning: no target or categorical columns found; skipping eigencorrelations")
+			}
+		} else {
+			eigencorrelations, err := calculateEigencorrelations(result, data, opts.CorrelationMethod, opts.Jobs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate eigencorrelations: %w", err)
+			}
+			result.Eigencorrelations = eigencorrelations
+		}
+	}
+
+	if opts.Verbose && config.Method == "nipals" {
+		fmt.Printf("Score orthogonality (max |off-diagonal of TᵀT|): %.3e\n",
+			core.ScoreOrthogonality(result.Scores))
+	}
+
+	if opts.CheckLoadings {
+		check, err := core.CheckOrthonormality(result, opts.CheckTolerance)
+		if err != nil {
+			return nil, fmt.Errorf("orthonormality check failed: %w", err)
+		}
+		outputOrthonormalityCheck(check)
+		if !check.Passed() {
+			return nil, fmt.Errorf("orthonormality check failed: deviation exceeds tolerance %g", check.Tolerance)
+		}
+	}
+
+	outputResult := result
+
+	// Metrics are calculated from the fitted scores and the preprocessed data, in
+	// which the residuals and the SPE limit are defined, before scores are normalized
+	if opts.IncludeMetrics && result.Method != "kernel" {
+		test := core.OutlierTest{Method: opts.OutlierMethod, Significance: opts.OutlierSignificance}
+		metrics, err := core.CalculateMetricsWithOutlierTest(result, processedData, test)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate metrics: %w", err)
+		}
+		withMetrics := *outputResult
+		withMetrics.Metrics = metrics
+		if opts.OutputInfluence {
+			limits := core.CalculateDiagnosticLimits(result)
+			withMetrics.T2Limit95, withMetrics.T2Limit99 = limits.T2Limit95, limits.T2Limit99
+			withMetrics.QLimit95, withMetrics.QLimit99 = limits.QLimit95, limits.QLimit99
+			withMetrics.Influence = core.InfluencePlot(metrics, limits)
+		}
+		outputResult = &withMetrics
+	}
+
+	// Normalized scores only change the output
+	if opts.NormalizeScores {
+		normalized, scale, err := core.NormalizeScores(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to normalize scores: %w", err)
+		}
+		normalizedResult := *outputResult
+		normalizedResult.Scores = normalized
+		normalizedResult.ScoreScale = scale
+		outputResult = &normalizedResult
+	}
+
+	// Exported components must have been fitted
+	var exportComponents []int
+	if opts.ExportComponents != "" {
+		exportComponents, _ = utils.ParseRanges(opts.ExportComponents)
+		if last := exportComponents[len(exportComponents)-1]; last >= len(result.ComponentLabels) {
+			return nil, fmt.Errorf("cannot export component %d: only %d components were computed; increase --components",
+				last+1, len(result.ComponentLabels))
+		}
 	}
 
 	// Output results based on format
 	switch opts.OutputFormat {
 	case "json":
-		return outputJSONFormat(result, data, inputFile, opts, config, preprocessor,
+		err = outputJSONFormat(outputResult, sanitizeDataLabels(data, opts.Quiet), inputFile, opts, config, preprocessor,
 			data.CategoricalColumns, data.NumericTargetColumns)
+	case "csv":
+		err = outputCSVFormat(outputResult, sanitizeDataLabels(data, opts.Quiet), inputFile, opts)
 	default: // table
 		outputScores := opts.OutputScores || opts.OutputAll
 		outputLoadings := opts.OutputLoadings || opts.OutputAll
 		outputVariance := opts.OutputVariance || opts.OutputAll
-		return outputTableFormat(result, data,
-			outputScores, outputLoadings, outputVariance, opts.IncludeMetrics)
+		err = outputTableFormat(outputResult, data,
+			outputScores, outputLoadings, outputVariance, opts.IncludeMetrics, opts.LoadingsThreshold,
+			opts.GroupSummary)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PlotASCII {
+		outputScorePlot(outputResult, data, opts)
+	}
+
+	if opts.ScoresNDJSON != "" {
+		if err := writeScoresNDJSON(opts.ScoresNDJSON, outputResult, sanitizeDataLabels(data, true), opts.Precision); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.OutputReconstruction {
+		if err := writeReconstruction(result, preprocessor, sanitizeDataLabels(data, true), inputFile, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(exportComponents) > 0 {
+		if err := writeExportedComponents(outputResult, sanitizeDataLabels(data, true), inputFile, opts,
+			exportComponents); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// outputScorePlot draws PC1 against PC2 scores in the terminal, scaled to its width,
+// with a marker per category of --plot-group. The plot is skipped with a warning when
+// standard output is not a terminal or there is only one component.
+func outputScorePlot(result *types.PCAResult, data *pkgcsv.Data, opts *AnalyzeOptions) {
+	if !stdoutIsTerminal() {
+		if !opts.Quiet {
+			fmt.Println("Warning: --plot-ascii skipped because standard output is not a terminal")
+		}
+		return
+	}
+	if len(result.Scores) == 0 || len(result.Scores[0]) < 2 {
+		if !opts.Quiet {
+			fmt.Println("Warning: --plot-ascii skipped because it needs at least 2 components")
+		}
+		return
+	}
+
+	x := make([]float64, len(result.Scores))
+	y := make([]float64, len(result.Scores))
+	for i, row := range result.Scores {
+		x[i], y[i] = row[0], row[1]
+	}
+	label := func(k int) string {
+		if k < len(result.ExplainedVarRatio) {
+			return fmt.Sprintf("PC%d (%.1f%%)", k+1, result.ExplainedVarRatio[k])
+		}
+		return fmt.Sprintf("PC%d", k+1)
+	}
+
+	// Leave room for the tick labels and axis of the plot
+	width := min(max(terminalWidth()-12, 20), 120)
+	plotOpts := utils.ScatterOptions{
+		Width:  width,
+		Height: min(max(width/3, 8), 30),
+		XLabel: label(0),
+		YLabel: label(1),
+	}
+	if opts.PlotGroup != "" {
+		plotOpts.Groups = data.CategoricalColumns[opts.PlotGroup]
 	}
+	if useColor {
+		plotOpts.Colors = []string{ansiCyan, ansiYellow, ansiGreen, ansiRed}
+	}
+
+	plot, err := utils.ASCIIScatter(x, y, plotOpts)
+	if err != nil {
+		if !opts.Quiet {
+			fmt.Printf("Warning: --plot-ascii skipped: %v\n", err)
+		}
+		return
+	}
+	fmt.Printf("\nScore Plot:\n%s", plot)
+}
+
+// outputScaleReport prints the range and variance of each column
+func outputScaleReport(data *pkgcsv.Data, ranges, variances []float64, ratio float64) {
+	fmt.Println("\nCenter and Scale Report:")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%-20s%20s%20s\n", "Variable", "Range", "Variance")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	for j := range variances {
+		name := fmt.Sprintf("Column_%d", j+1)
+		if j < len(data.Headers) {
+			name = data.Headers[j]
+		}
+		fmt.Printf("%-20s%20.4g%20.4g\n", name, ranges[j], variances[j])
+	}
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("Max/min variance ratio: %.4g\n", ratio)
+}
+
+// outputPreprocessingRecommendations prints the suggested preprocessing steps in order
+func outputPreprocessingRecommendations(recommendations []string) {
+	fmt.Println("\nPreprocessing Recommendations:")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	if len(recommendations) == 0 {
+		fmt.Println("Mean centering only: variables are on comparable scales without heavy outliers")
+		return
+	}
+	for i, rec := range recommendations {
+		fmt.Printf("%d. %s\n", i+1, rec)
+	}
+	fmt.Println("\nApply with --snv, --scale standard or --scale robust as recommended.")
 }
 
-// Helper functions for parsing exclude options
+// validateAnalyzeInput reports whether data is ready for PCA with the given options,
+// after dropping mostly missing columns, without running PCA. It returns the blocking
+// issues as an error.
+func validateAnalyzeInput(opts *AnalyzeOptions, data *pkgcsv.Data, inputFile string) error {
+	var droppedColumns []string
+	if opts.DropMissingCols > 0 && data.Columns > 0 {
+		var err error
+		if droppedColumns, err = dropMissingColumns(data, opts.DropMissingCols); err != nil {
+			return err
+		}
+	}
+
+	report := data.Validate(pkgcsv.DefaultValidationOptions())
+	method := strings.ToLower(opts.Method)
+	maxComponents := core.CalculateMaxComponents(data.Rows, data.Columns)
+	if method == "kernel" {
+		maxComponents = data.Rows
+	}
+	maxComponents = min(maxComponents, security.MaxComponents)
+
+	// Options that cannot be satisfied by this data are blocking too
+	if report.MissingValues > 0 {
+		switch {
+		case opts.MissingStrategy == "native" && method != "nipals":
+			report.Add(types.SeverityError, "", "native missing value handling is only supported with the NIPALS method, not %s", opts.Method)
+		case opts.MissingStrategy == "error":
+			report.Add(types.SeverityError, "", "missing values detected; use --missing-strategy with one of: drop, mean, median, zero")
+		}
+	}
+	if opts.VarianceTarget == 0 && !opts.ComponentsMax && data.Columns > 0 && opts.Components > maxComponents {
+		report.Add(types.SeverityError, "", "too many components requested: maximum %d, got %d", maxComponents, opts.Components)
+	}
+	if method == "kernel" {
+		if limit := min(security.MaxKernelPCASamples, core.MaxKernelPCASamplesForMemory()); data.Rows > limit {
+			report.Add(types.SeverityError, "", "kernel PCA is limited to %d samples (found %d)", limit, data.Rows)
+		}
+	}
+
+	fmt.Printf("Validating file: %s\n", inputFile)
+	fmt.Printf("  - Dimensions: %d rows × %d numeric columns (after exclusions)\n", data.Rows, data.Columns)
+	if len(droppedColumns) > 0 {
+		fmt.Printf("  - Dropped columns with more than %g%% missing values: %s\n",
+			opts.DropMissingCols*100, strings.Join(droppedColumns, ", "))
+	}
+	if report.MissingValues > 0 {
+		fmt.Printf("  - Missing values: %d (%.1f%%), handled with --missing-strategy %s\n",
+			report.MissingValues, report.MissingPercent, opts.MissingStrategy)
+	} else {
+		fmt.Println("  - Missing values: none")
+	}
+	if len(data.CategoricalColumns) > 0 {
+		fmt.Printf("  - Categorical columns: %s\n", strings.Join(slices.Sorted(maps.Keys(data.CategoricalColumns)), ", "))
+	}
+	if len(data.NumericTargetColumns) > 0 {
+		fmt.Printf("  - Target columns: %s (excluded from PCA)\n", strings.Join(slices.Sorted(maps.Keys(data.NumericTargetColumns)), ", "))
+	}
+	fmt.Printf("  - Maximum components: %d\n", maxComponents)
+
+	if warnings := report.Messages(types.SeverityWarning); len(warnings) > 0 && !opts.Quiet {
+		fmt.Println("\n" + colorize(ansiYellow, "⚠ Warnings:"))
+		for _, w := range warnings {
+			fmt.Printf("  - %s\n", colorize(ansiYellow, w))
+		}
+	}
+	if err := report.Err(); err != nil {
+		fmt.Println("\n" + colorize(ansiRed, "✗ Blocking issues:"))
+		for _, e := range report.Messages(types.SeverityError) {
+			fmt.Printf("  - %s\n", colorize(ansiRed, e))
+		}
+		return fmt.Errorf("data is not ready for PCA: %w", err)
+	}
+	fmt.Println("\n" + colorize(ansiGreen, "✓ Data is ready for PCA analysis"))
+	return nil
+}
+
+// componentsForVarianceTarget fits as many components as the data allows and returns the
+// fewest whose cumulative explained variance reaches target, a fraction in (0,1]
+func componentsForVarianceTarget(data types.Matrix, config types.PCAConfig, target float64) (int, error) {
+	config.Components = core.MaxInformativeComponents(len(data), len(data[0]))
+	if config.Method == "kernel" {
+		config.Components = max(1, len(data)-1)
+	}
+	config.Components = min(config.Components, security.MaxComponents)
+
+	result, err := core.NewPCAEngineForMethod(config.Method).Fit(data, config)
+	if err != nil {
+		return 0, fmt.Errorf("PCA analysis failed: %w", err)
+	}
+	for k, cumulative := range result.CumulativeVar {
+		// Allow for rounding when the target is reached exactly
+		if cumulative >= target*100-1e-9 {
+			return k + 1, nil
+		}
+	}
+	return len(result.CumulativeVar), nil
+}
+
+// printWarnings prints the warnings of a fit or transform from core, unless quiet
+func printWarnings(warnings []string, quiet bool) {
+	if quiet {
+		return
+	}
+	for _, warning := range warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
+
+// outputComponentAdvice prints the number of components suggested by each selection
+// heuristic next to the number used
+func outputComponentAdvice(advice []core.ComponentRecommendation, used int) {
+	fmt.Println("\nComponent Advice:")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%-26s%12s  %s\n", "Method", "Components", "Criterion")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	for _, rec := range advice {
+		fmt.Printf("%-26s%12d  %s\n", rec.Method, rec.Components, rec.Criterion)
+	}
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("Components used: %d (set with --components)\n", used)
+}
+
+// outputRobustReweighting prints the passes of --weights-from-metrics and the rows it
+// strongly downweighted, most downweighted first
+func outputRobustReweighting(reweighting *core.RobustReweighting, data *pkgcsv.Data) {
+	fmt.Printf("Robust reweighting: %d pass(es), %d row(s) strongly downweighted (weight < %g)\n",
+		reweighting.Iterations, len(reweighting.Downweighted), core.RobustStrongDownweight)
+	for _, row := range reweighting.Downweighted {
+		name := fmt.Sprintf("row %d", row+1)
+		if row < len(data.RowNames) && data.RowNames[row] != "" {
+			name = fmt.Sprintf("row %d (%s)", row+1, data.RowNames[row])
+		}
+		fmt.Printf("  %-30s weight %.3f\n", name, reweighting.Weights[row])
+	}
+}
+
+// outputOrthonormalityCheck prints the deviations found by --check-loadings
+func outputOrthonormalityCheck(check *core.OrthonormalityCheck) {
+	loadings := "n/a (kernel PCA)"
+	if !math.IsNaN(check.LoadingsDeviation) {
+		loadings = fmt.Sprintf("%.3e", check.LoadingsDeviation)
+	}
+	status := colorize(ansiGreen, "passed")
+	if !check.Passed() {
+		status = colorize(ansiRed, "FAILED")
+	}
+
+	fmt.Println("\nOrthonormality Check:")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%-36s%s\n", "Loadings max |PᵀP − I|", loadings)
+	fmt.Printf("%-36s%.3e\n", "Scores max |cos(tᵢ, tⱼ)|", check.ScoresDeviation)
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("Tolerance %g: %s\n", check.Tolerance, status)
+}
+
+// calculateEigencorrelations correlates the PC scores with the target and categorical columns,
+// spreading the work over the given number of workers
+func calculateEigencorrelations(result *types.PCAResult, data *pkgcsv.Data, method string,
+	workers int) (*types.EigencorrelationResult, error) {
+	scores := mat.NewDense(len(result.Scores), len(result.Scores[0]), nil)
+	for i, row := range result.Scores {
+		scores.SetRow(i, row)
+	}
+
+	corr, err := core.CalculateEigencorrelations(core.CorrelationRequest{
+		Scores:              scores,
+		MetadataNumeric:     data.NumericTargetColumns,
+		MetadataCategorical: data.CategoricalColumns,
+		Method:              method,
+		Workers:             workers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.EigencorrelationResult{
+		Correlations: corr.Correlations,
+		PValues:      corr.PValues,
+		Variables:    corr.Variables,
+		Components:   corr.Components,
+		Method:       method,
+	}, nil
+}
+
+// selectAnalyzeRows keeps the rows chosen by --select-rows, removes those of
+// --exclude-rows and then downsamples the categories of --balance-by to the same size. The returned data keeps row names and
+// categorical and target columns aligned, naming unnamed rows after their position in
+// the input.
+func selectAnalyzeRows(opts *AnalyzeOptions, data *pkgcsv.Data) (*pkgcsv.Data, error) {
+	total := data.Rows
+	if opts.SelectRows != "" {
+		rows, err := parseRowSelection(opts.SelectRows, data.RowNames, data.Rows)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --select-rows: %w", err)
+		}
+		data = subsetDataRows(data, rows)
+	}
+
+	if opts.ExcludeRows != "" {
+		excluded, err := parseRowSelection(opts.ExcludeRows, data.RowNames, data.Rows)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude-rows: %w", err)
+		}
+		rows := make([]int, 0, data.Rows)
+		for i := 0; i < data.Rows; i++ {
+			if _, found := slices.BinarySearch(excluded, i); !found {
+				rows = append(rows, i)
+			}
+		}
+		data = subsetDataRows(data, rows)
+	}
+
+	if opts.BalanceBy != "" {
+		groups, ok := data.CategoricalColumns[opts.BalanceBy]
+		if !ok {
+			return nil, fmt.Errorf("balance-by column %q is not a categorical column", opts.BalanceBy)
+		}
+		rows, err := core.BalancedRowSample(groups, opts.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to balance %s: %w", opts.BalanceBy, err)
+		}
+		data = subsetDataRows(data, rows)
+	}
+
+	if !opts.Quiet {
+		fmt.Printf("Selected %d of %d rows\n", data.Rows, total)
+	}
+	return data, nil
+}
+
+// parseRowSelection returns the 0-based indices, in ascending order, of the rows
+// listed in selection. Each comma-separated entry is a row name, a 1-based row index
+// or a range of indices such as 1-50; row names take precedence.
+func parseRowSelection(selection string, rowNames []string, rows int) ([]int, error) {
+	nameIndex := make(map[string]int, len(rowNames))
+	for i, name := range rowNames {
+		if _, ok := nameIndex[name]; !ok {
+			nameIndex[name] = i
+		}
+	}
+
+	selected := make(map[int]bool)
+	for _, entry := range strings.Split(selection, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if i, ok := nameIndex[entry]; ok {
+			selected[i] = true
+			continue
+		}
+		indices, err := utils.ParseRanges(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a row name nor a row index or range", entry)
+		}
+		for _, i := range indices {
+			if i >= rows {
+				return nil, fmt.Errorf("row %d is out of range (data has %d rows)", i+1, rows)
+			}
+			selected[i] = true
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no rows selected")
+	}
+
+	indices := make([]int, 0, len(selected))
+	for i := range selected {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+// dropMissingColumns removes the numeric columns whose fraction of missing values
+// exceeds threshold and returns their names
+func dropMissingColumns(data *pkgcsv.Data, threshold float64) ([]string, error) {
+	info := data.GetMissingValueInfo(nil)
+	drop := info.ColumnsAboveFraction(data.Rows, threshold)
+	if len(drop) == 0 {
+		return nil, nil
+	}
+	if len(drop) == data.Columns {
+		return nil, fmt.Errorf("all %d columns have more than %g%% missing values", data.Columns, threshold*100)
+	}
+
+	dropped := make([]string, len(drop))
+	for i, col := range drop {
+		dropped[i] = fmt.Sprintf("Column_%d", col+1)
+		if col < len(data.Headers) {
+			dropped[i] = data.Headers[col]
+		}
+	}
+	removeDataColumns(data, drop)
+	return dropped, nil
+}
+
+// excludeAnalyzeColumns removes the numeric columns of --exclude-columns from data
+// and records their input indices in opts, so that the fit and --validate-only see
+// the same columns
+func excludeAnalyzeColumns(opts *AnalyzeOptions, data *pkgcsv.Data) error {
+	excluded, err := parseExcludeColumns(opts.ExcludeColumns, data.Headers, data.Columns)
+	if err != nil {
+		return fmt.Errorf("invalid --exclude-columns: %w", err)
+	}
+	removeDataColumns(data, excluded)
+	opts.excludedColumns = excluded
+	return nil
+}
+
+// removeDataColumns removes the given numeric columns, in ascending order, from the
+// data matrix, missing value mask and headers
+func removeDataColumns(data *pkgcsv.Data, columns []int) {
+	keep := make([]int, 0, data.Columns)
+	for col, next := 0, 0; col < data.Columns; col++ {
+		if next < len(columns) && columns[next] == col {
+			next++
+			continue
+		}
+		keep = append(keep, col)
+	}
+
+	for i := range data.Matrix {
+		row := make([]float64, len(keep))
+		for j, col := range keep {
+			row[j] = data.Matrix[i][col]
+		}
+		data.Matrix[i] = row
+	}
+	for i := range data.MissingMask {
+		mask := make([]bool, len(keep))
+		for j, col := range keep {
+			mask[j] = data.MissingMask[i][col]
+		}
+		data.MissingMask[i] = mask
+	}
+	if len(data.Headers) > 0 {
+		headers := make([]string, len(keep))
+		for j, col := range keep {
+			headers[j] = data.Headers[col]
+		}
+		data.Headers = headers
+	}
+	data.Columns = len(keep)
+}
+
+// filterDataRows keeps only the given rows of the row names and of the
+// categorical and target columns, so they stay aligned with a filtered data matrix
+func filterDataRows(data *pkgcsv.Data, keepRows []int) {
+	if len(data.RowNames) > 0 {
+		rowNames := make([]string, len(keepRows))
+		for i, r := range keepRows {
+			rowNames[i] = data.RowNames[r]
+		}
+		data.RowNames = rowNames
+	}
+	for name, values := range data.CategoricalColumns {
+		filtered := make([]string, len(keepRows))
+		for i, r := range keepRows {
+			filtered[i] = values[r]
+		}
+		data.CategoricalColumns[name] = filtered
+	}
+	for name, values := range data.NumericTargetColumns {
+		filtered := make([]float64, len(keepRows))
+		for i, r := range keepRows {
+			filtered[i] = values[r]
+		}
+		data.NumericTargetColumns[name] = filtered
+	}
+}
+
+// writeImputeReport writes one row per imputed cell, or per missing cell in a dropped
+// row, as row,column,original,imputed,method. Rows are identified by row name or
+// 1-based row number, and dropped cells have an empty imputed value.
+func writeImputeReport(filename string, data *pkgcsv.Data, changes *core.MissingValueChanges) error {
+	rowLabel := func(row int) string {
+		if row < len(data.RowNames) && data.RowNames[row] != "" {
+			return data.RowNames[row]
+		}
+		return strconv.Itoa(row + 1)
+	}
+	columnLabel := func(col int) string {
+		if col < len(data.Headers) && data.Headers[col] != "" {
+			return data.Headers[col]
+		}
+		return fmt.Sprintf("Column %d", col+1)
+	}
+
+	records := [][]string{{"row", "column", "original", "imputed", "method"}}
+	for _, cell := range changes.Imputed {
+		records = append(records, []string{rowLabel(cell.Row), columnLabel(cell.Column), "NaN",
+			formatCSVFloat(cell.Value), string(changes.StrategyFor(cell.Column))})
+	}
+	for _, dropped := range changes.Dropped {
+		for _, col := range dropped.Columns {
+			records = append(records, []string{rowLabel(dropped.Row), columnLabel(col), "NaN", "", string(changes.StrategyFor(col))})
+		}
+	}
+
+	if err := writeCSVRecords(filename, records); err != nil {
+		return fmt.Errorf("failed to write impute report: %w", err)
+	}
+	fmt.Printf("Imputation report saved to: %s (%d cells)\n", filename, len(records)-1)
+	return nil
+}
+
+// parseKernelGamma parses the --kernel-gamma value, which is a number or the name of a
+// heuristic that chooses gamma from the data
+func parseKernelGamma(value string) (gamma float64, heuristic string, err error) {
+	switch strings.ToLower(value) {
+	case core.KernelGammaAuto, core.KernelGammaMedian:
+		return 0, strings.ToLower(value), nil
+	}
+	gamma, err = strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid --kernel-gamma %q: must be a number, %s or %s",
+			value, core.KernelGammaAuto, core.KernelGammaMedian)
+	}
+	return gamma, "", nil
+}
+
+// parseComponents parses the --components value: a positive number of components,
+// or "max" for as many as the data supports
+func parseComponents(value string) (components int, useMax bool, err error) {
+	if strings.EqualFold(value, "max") {
+		return 0, true, nil
+	}
+	components, err = strconv.Atoi(value)
+	if err != nil || components < 1 {
+		return 0, false, fmt.Errorf("invalid --components %q: must be a positive integer or max", value)
+	}
+	return components, false, nil
+}
+
+// validateCSVLayout checks the value of a wide/tidy layout flag
+func validateCSVLayout(flag, value string) error {
+	if value != "wide" && value != "tidy" {
+		return fmt.Errorf("invalid --%s %q: must be wide or tidy", flag, value)
+	}
+	return nil
+}
+
+// parseExcludeIndices returns the sorted 0-based indices of the 1-based row indices
+// and ranges in excludeStr. Row names, which have no index here, are skipped.
 func parseExcludeIndices(excludeStr string) []int {
 	var indices []int
-	parts := strings.Split(excludeStr, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		var idx int
-		if _, err := fmt.Sscanf(part, "%d", &idx); err == nil {
-			indices = append(indices, idx-1) // Convert to 0-based
+	for _, entry := range strings.Split(excludeStr, ",") {
+		if entryIndices, err := utils.ParseRanges(entry); err == nil {
+			indices = append(indices, entryIndices...)
 		}
 	}
-	return indices
+	slices.Sort(indices)
+	return slices.Compact(indices)
 }
 
-func parseExcludeColumns(excludeStr string, headers []string) []int {
+// parseExcludeColumns returns the 0-based indices, in ascending order, of the columns
+// listed in excludeStr. Each comma-separated entry is a column name, a 1-based column
+// index or a range of indices such as 2-4; column names take precedence.
+func parseExcludeColumns(excludeStr string, headers []string, columns int) ([]int, error) {
 	var indices []int
-	parts := strings.Split(excludeStr, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-
-		// Try to parse as index first
-		var idx int
-		if _, err := fmt.Sscanf(part, "%d", &idx); err == nil {
-			indices = append(indices, idx-1) // Convert to 0-based
-		} else {
-			// Try to match by name
-			for i, header := range headers {
-				if header == part {
-					indices = append(indices, i)
-					break
-				}
+	for _, entry := range strings.Split(excludeStr, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if i := slices.Index(headers, entry); i >= 0 {
+			indices = append(indices, i)
+			continue
+		}
+		entryIndices, err := utils.ParseRanges(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a column name nor a column index or range", entry)
+		}
+		for _, i := range entryIndices {
+			if i >= columns {
+				return nil, fmt.Errorf("column %d is out of range (data has %d numeric columns)", i+1, columns)
 			}
+			indices = append(indices, i)
 		}
 	}
-	return indices
+	slices.Sort(indices)
+	return slices.Compact(indices), nil
 }
@@ -0,0 +1,99 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// batchResult is the outcome of analyzing one file in batch mode
+type batchResult struct {
+	file string
+	err  error
+}
+
+// runAnalyzeBatch runs the analysis on every CSV file in dir with the shared options,
+// using a pool of opts.Jobs workers. Failures are collected and reported at the end
+// instead of stopping the batch.
+func runAnalyzeBatch(opts *AnalyzeOptions, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to access batch directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if opts.Jobs < 1 {
+		return fmt.Errorf("jobs must be at least 1, got %d", opts.Jobs)
+	}
+	if opts.OutputFormat == "table" {
+		return fmt.Errorf("batch mode writes results to files: use --format json or csv")
+	}
+
+	var files []string
+	for _, pattern := range []string{"*.csv", "*.tsv", "*.tab"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return fmt.Errorf("failed to list CSV files: %w", err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no CSV or TSV files found in %s", dir)
+	}
+	sort.Strings(files)
+
+	results := make([]batchResult, len(files))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.Jobs, len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				// Each file gets its own copy, since runAnalyze may adjust options. Files
+				// already run in parallel, so eigencorrelations are computed serially, and
+				// each JSON model goes to its own file.
+				fileOpts := *opts
+				fileOpts.Jobs = 1
+				fileOpts.JSONToFile = true
+				results[i] = batchResult{file: files[i], err: runAnalyze(&fileOpts, files[i])}
+			}
+		}()
+	}
+	for i := range files {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	// Summary
+	var failed []string
+	fmt.Printf("\nBatch Summary (%s):\n", dir)
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	for _, r := range results {
+		name := filepath.Base(r.file)
+		if r.err != nil {
+			failed = append(failed, name)
+			fmt.Printf("%-30s FAILED: %v\n", name, r.err)
+		} else {
+			fmt.Printf("%-30s ok\n", name)
+		}
+	}
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%d succeeded, %d failed\n", len(files)-len(failed), len(failed))
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d files failed: %s", len(failed), len(files), strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// CleanOptions holds all the options for the clean command
+type CleanOptions struct {
+	// Input format options
+	NoHeaders bool
+	NoIndex   bool
+	Delimiter string
+	NAValues  string
+
+	// Report options
+	Report string // CSV file listing the removed columns and why
+}
+
+// Reasons a column is removed by the clean command
+const (
+	cleanReasonEmpty     = "empty"     // Every value is missing
+	cleanReasonConstant  = "constant"  // Every observed value is the same, or a numeric column has zero variance
+	cleanReasonDuplicate = "duplicate" // Every value equals that of an earlier column
+)
+
+// cleanRemoval is a column removed by the clean command
+type cleanRemoval struct {
+	Column      int    // 0-based column of the text data, row names excluded
+	Name        string // Header, or Column_N without headers
+	Reason      string
+	DuplicateOf string // Column repeated by a duplicate
+}
+
+// NewCleanCommand creates the clean subcommand
+func NewCleanCommand() *cobra.Command {
+	opts := &CleanOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "clean [flags] <input> <output>",
+		Short: "Remove empty, constant and duplicate columns",
+		Long: `Remove columns that carry no information and write the remaining data.
+
+Columns are removed when every value is missing (empty), when every observed
+value is the same or a numeric column has zero variance (constant), or when
+every value equals that of an earlier column (duplicate; the first is kept).
+The removed columns are listed with the reason. Headers, row names and the
+kept columns are written unchanged. Input and output formats (CSV, TSV, Excel
+or JSON) are inferred from the file extensions.
+
+EXAMPLES:
+  # Remove uninformative columns before analysis
+  pca clean data.csv cleaned.csv
+
+  # Also write the removed columns to a report
+  pca clean --report removed.csv data.csv cleaned.csv`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runClean(opts, args[0], args[1])
+		},
+	}
+
+	// Input format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
+		"First column contains data, not row names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
+		"Comma-separated list of strings representing missing values")
+
+	// Report options
+	cmd.Flags().StringVar(&opts.Report, "report", "",
+		"Write a CSV listing the removed columns and why")
+
+	return cmd
+}
+
+// runClean executes the clean command
+func runClean(opts *CleanOptions, inputFile, outputFile string) error {
+	inputFormat, err := pkgcsv.FormatFromPath(inputFile)
+	if err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+	outputFormat, err := pkgcsv.FormatFromPath(outputFile)
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	if err := security.ValidateOutputPath(outputFile); err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	if opts.Report != "" {
+		if err := security.ValidateOutputPath(opts.Report); err != nil {
+			return fmt.Errorf("invalid report path: %w", err)
+		}
+	}
+
+	// Read input as text so the kept columns are written as they are
+	readOpts := pkgcsv.DefaultOptions()
+	readOpts.HasHeaders = !opts.NoHeaders
+	readOpts.HasRowNames = !opts.NoIndex
+	readOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+
+	data, err := pkgcsv.ReadTableFile(inputFile, inputFormat, readOpts)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+
+	naValues := make(map[string]bool)
+	for _, v := range strings.Split(opts.NAValues, ",") {
+		naValues[strings.TrimSpace(v)] = true
+	}
+
+	removals, err := findCleanColumns(data, naValues)
+	if err != nil {
+		return err
+	}
+	nColumns := 0
+	if len(data.StringData) > 0 {
+		nColumns = len(data.StringData[0])
+	}
+	if len(removals) > 0 && len(removals) == nColumns {
+		return fmt.Errorf("every column of %s is empty, constant or a duplicate", inputFile)
+	}
+
+	drop := make([]int, len(removals))
+	for i, r := range removals {
+		drop[i] = r.Column
+	}
+	removeTextColumns(data, drop)
+
+	writeOpts := pkgcsv.DefaultOptions()
+	writeOpts.HasHeaders = len(data.Headers) > 0
+	if err := pkgcsv.WriteTableFile(outputFile, outputFormat, data, writeOpts); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+
+	outputCleanRemovals(removals, nColumns)
+	written := []string{outputFile}
+	if opts.Report != "" {
+		rows := [][]string{{"column", "reason", "duplicate_of"}}
+		for _, r := range removals {
+			rows = append(rows, []string{r.Name, r.Reason, r.DuplicateOf})
+		}
+		if err := writeCSVRecords(opts.Report, rows); err != nil {
+			return fmt.Errorf("failed to write clean report: %w", err)
+		}
+		written = append(written, opts.Report)
+	}
+	fmt.Printf("\nResults saved to: %s\n", strings.Join(written, ", "))
+
+	return nil
+}
+
+// findCleanColumns returns the empty, constant and duplicate columns of text data in
+// column order. Constant numeric columns are found by their variance over the
+// observed values; a column that is empty or constant is not also reported as a
+// duplicate.
+func findCleanColumns(data *pkgcsv.Data, naValues map[string]bool) ([]cleanRemoval, error) {
+	if len(data.StringData) == 0 {
+		return nil, nil
+	}
+	nColumns := len(data.StringData[0])
+	name := func(col int) string {
+		if col < len(data.Headers) {
+			return data.Headers[col]
+		}
+		return fmt.Sprintf("Column_%d", col+1)
+	}
+	reasons := make([]string, nColumns)
+
+	// Numeric columns with zero variance over their observed values
+	numeric := imputeMatrix(data, naValues)
+	for k, col := range numeric.sourceColumns {
+		var observed types.Matrix
+		for i, row := range numeric.Matrix {
+			if !naValues[strings.TrimSpace(data.StringData[i][col])] {
+				observed = append(observed, []float64{row[k]})
+			}
+		}
+		constant, err := core.CheckForConstantColumns(observed)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", name(col), err)
+		}
+		if len(constant) > 0 {
+			reasons[col] = cleanReasonConstant
+		}
+	}
+
+	// Empty columns, and other columns with a single observed value
+	for col := 0; col < nColumns; col++ {
+		var values []string
+		for _, record := range data.StringData {
+			if value := strings.TrimSpace(record[col]); !naValues[value] && !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+		}
+		switch {
+		case len(values) == 0:
+			reasons[col] = cleanReasonEmpty
+		case len(values) == 1:
+			reasons[col] = cleanReasonConstant
+		}
+	}
+
+	// Columns equal to an earlier column in every row
+	var removals []cleanRemoval
+	first := make(map[string]int) // Column contents to the first column with them
+	for col := 0; col < nColumns; col++ {
+		if reasons[col] != "" {
+			removals = append(removals, cleanRemoval{Column: col, Name: name(col), Reason: reasons[col]})
+			continue
+		}
+		fields := make([]string, len(data.StringData))
+		for i, record := range data.StringData {
+			fields[i] = strings.TrimSpace(record[col])
+		}
+		// A zero byte separates fields so that ["ab", "c"] and ["a", "bc"] differ
+		key := strings.Join(fields, "\x00")
+		if earlier, ok := first[key]; ok {
+			removals = append(removals, cleanRemoval{Column: col, Name: name(col), Reason: cleanReasonDuplicate,
+				DuplicateOf: name(earlier)})
+			continue
+		}
+		first[key] = col
+	}
+	return removals, nil
+}
+
+// removeTextColumns removes the given columns, in ascending order, from the headers
+// and records of text data
+func removeTextColumns(data *pkgcsv.Data, columns []int) {
+	if len(columns) == 0 {
+		return
+	}
+	if len(data.Headers) > 0 {
+		var headers []string
+		for j, header := range data.Headers {
+			if !slices.Contains(columns, j) {
+				headers = append(headers, header)
+			}
+		}
+		data.Headers = headers
+	}
+	for i, record := range data.StringData {
+		kept := make([]string, 0, len(record)-len(columns))
+		for j, field := range record {
+			if !slices.Contains(columns, j) {
+				kept = append(kept, field)
+			}
+		}
+		data.StringData[i] = kept
+	}
+	if len(data.StringData) > 0 {
+		data.Columns = len(data.StringData[0])
+	}
+}
+
+// outputCleanRemovals prints the removed columns and the reason for each
+func outputCleanRemovals(removals []cleanRemoval, nColumns int) {
+	if len(removals) == 0 {
+		fmt.Printf("No columns removed: all %d columns kept\n", nColumns)
+		return
+	}
+	fmt.Printf("Removed %d of %d columns:\n", len(removals), nColumns)
+	fmt.Printf("%-25s%-12s%s\n", "Column", "Reason", "Duplicate of")
+	for _, r := range removals {
+		fmt.Printf("%-25s%-12s%s\n", r.Name, r.Reason, r.DuplicateOf)
+	}
+}
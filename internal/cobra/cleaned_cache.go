@@ -0,0 +1,222 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// cleanedCacheVersion is the format version of cleaned data cache files
+const cleanedCacheVersion = 1
+
+// cleanedCache is a dataset after parsing and missing value handling, written by
+// --cache-cleaned so that --use-cleaned can skip both steps. The checksums tie the
+// cache to the input file and the options that produced it.
+type cleanedCache struct {
+	Version        int    `json:"version"`
+	SourceChecksum string `json:"source_checksum"` // SHA-256 of the input file
+	ConfigChecksum string `json:"config_checksum"` // SHA-256 of the parsing and cleaning options
+
+	Headers              []string                       `json:"headers"`
+	RowNames             []string                       `json:"row_names,omitempty"`
+	Matrix               [][]types.JSONFloat64          `json:"matrix"` // Missing values (native strategy) are null
+	CategoricalColumns   map[string][]string            `json:"categorical_columns,omitempty"`
+	NumericTargetColumns map[string][]types.JSONFloat64 `json:"numeric_target_columns,omitempty"`
+	DroppedColumns       []string                       `json:"dropped_columns,omitempty"`
+	ExcludedColumns      []int                          `json:"excluded_columns,omitempty"` // Input indices of --exclude-columns
+}
+
+// cleanedCacheConfig holds the options that determine the cleaned data
+type cleanedCacheConfig struct {
+	Parse                pkgcsv.Options `json:"parse"`
+	DropMissingCols      float64        `json:"drop_missing_cols"`
+	MissingStrategy      string         `json:"missing_strategy"`
+	ImputeGroupBy        string         `json:"impute_group_by,omitempty"`
+	DropZeroVarianceRows bool           `json:"drop_zero_variance_rows"`
+	SelectRows           string         `json:"select_rows,omitempty"`
+	ExcludeColumns       string         `json:"exclude_columns,omitempty"`
+	BalanceBy            string         `json:"balance_by,omitempty"`
+	Seed                 int64          `json:"seed,omitempty"`
+}
+
+// cleanedCacheChecksums returns the checksums of the input file and of the options
+// that determine the cleaned data
+func cleanedCacheChecksums(inputFile string, opts *AnalyzeOptions, parseOpts pkgcsv.Options) (string, string, error) {
+	sourceChecksum, err := fileChecksum(inputFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	config := cleanedCacheConfig{
+		Parse:                parseOpts,
+		DropMissingCols:      opts.DropMissingCols,
+		MissingStrategy:      opts.MissingStrategy,
+		ImputeGroupBy:        opts.ImputeGroupBy,
+		DropZeroVarianceRows: opts.DropZeroVarianceRows,
+		SelectRows:           opts.SelectRows,
+		ExcludeColumns:       opts.ExcludeColumns,
+		BalanceBy:            opts.BalanceBy,
+	}
+	// The seed only affects the data when rows are balanced
+	if opts.BalanceBy != "" {
+		config.Seed = opts.Seed
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode options: %w", err)
+	}
+	checksum := sha256.Sum256(configJSON)
+
+	return sourceChecksum, hex.EncodeToString(checksum[:]), nil
+}
+
+// writeCleanedCache saves cleaned data together with the checksums of the input
+// file and options it was produced from
+func writeCleanedCache(filename, inputFile string, opts *AnalyzeOptions, parseOpts pkgcsv.Options,
+	data *pkgcsv.Data, droppedColumns []string) error {
+	sourceChecksum, configChecksum, err := cleanedCacheChecksums(inputFile, opts, parseOpts)
+	if err != nil {
+		return err
+	}
+
+	cache := cleanedCache{
+		Version:            cleanedCacheVersion,
+		SourceChecksum:     sourceChecksum,
+		ConfigChecksum:     configChecksum,
+		Headers:            data.Headers,
+		RowNames:           data.RowNames,
+		Matrix:             make([][]types.JSONFloat64, len(data.Matrix)),
+		CategoricalColumns: data.CategoricalColumns,
+		DroppedColumns:     droppedColumns,
+		ExcludedColumns:    opts.excludedColumns,
+	}
+	for i, row := range data.Matrix {
+		cache.Matrix[i] = toJSONFloats(row)
+	}
+	if len(data.NumericTargetColumns) > 0 {
+		cache.NumericTargetColumns = make(map[string][]types.JSONFloat64, len(data.NumericTargetColumns))
+		for name, values := range data.NumericTargetColumns {
+			cache.NumericTargetColumns[name] = toJSONFloats(values)
+		}
+	}
+
+	jsonData, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cleaned data: %w", err)
+	}
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write cleaned data cache: %w", err)
+	}
+	if !opts.Quiet {
+		fmt.Printf("Cleaned data saved to: %s (%d rows, %d columns)\n", filename, data.Rows, data.Columns)
+	}
+	return nil
+}
+
+// readCleanedCache loads data saved by writeCleanedCache and returns it with the
+// names of the columns dropped while cleaning, restoring the excluded columns in
+// opts. The cache is rejected if the input file or the parsing and cleaning options
+// have changed since it was written.
+func readCleanedCache(filename, inputFile string, opts *AnalyzeOptions,
+	parseOpts pkgcsv.Options) (*pkgcsv.Data, []string, error) {
+	jsonData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cleaned data cache: %w", err)
+	}
+	var cache cleanedCache
+	if err := json.Unmarshal(jsonData, &cache); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse cleaned data cache %s: %w", filename, err)
+	}
+	if cache.Version != cleanedCacheVersion {
+		return nil, nil, fmt.Errorf("cleaned data cache %s has unsupported version %d; recreate it with --cache-cleaned",
+			filename, cache.Version)
+	}
+
+	sourceChecksum, configChecksum, err := cleanedCacheChecksums(inputFile, opts, parseOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cache.SourceChecksum != sourceChecksum {
+		return nil, nil, fmt.Errorf("cleaned data cache %s is stale: %s has changed since it was cached; "+
+			"recreate it with --cache-cleaned", filename, inputFile)
+	}
+	if cache.ConfigChecksum != configChecksum {
+		return nil, nil, fmt.Errorf("cleaned data cache %s was created with different parsing or missing value "+
+			"options; recreate it with --cache-cleaned", filename)
+	}
+	if len(cache.Matrix) == 0 {
+		return nil, nil, fmt.Errorf("cleaned data cache %s contains no data", filename)
+	}
+
+	data := &pkgcsv.Data{
+		Headers:              cache.Headers,
+		RowNames:             cache.RowNames,
+		Matrix:               make(types.Matrix, len(cache.Matrix)),
+		Rows:                 len(cache.Matrix),
+		Columns:              len(cache.Matrix[0]),
+		CategoricalColumns:   cache.CategoricalColumns,
+		NumericTargetColumns: make(map[string][]float64, len(cache.NumericTargetColumns)),
+	}
+	if data.CategoricalColumns == nil {
+		data.CategoricalColumns = make(map[string][]string)
+	}
+	for name, values := range cache.NumericTargetColumns {
+		data.NumericTargetColumns[name] = fromJSONFloats(values)
+	}
+
+	// Values left missing by the native strategy are restored in the missing mask
+	hasMissing := false
+	for i, row := range cache.Matrix {
+		if len(row) != data.Columns {
+			return nil, nil, fmt.Errorf("cleaned data cache %s: row %d has %d values, expected %d",
+				filename, i+1, len(row), data.Columns)
+		}
+		data.Matrix[i] = fromJSONFloats(row)
+		for _, v := range data.Matrix[i] {
+			hasMissing = hasMissing || math.IsNaN(v)
+		}
+	}
+	if hasMissing {
+		data.MissingMask = make([][]bool, data.Rows)
+		for i, row := range data.Matrix {
+			data.MissingMask[i] = make([]bool, data.Columns)
+			for j, v := range row {
+				data.MissingMask[i][j] = math.IsNaN(v)
+			}
+		}
+	}
+
+	// The cached data is without the excluded columns, which the model still records
+	opts.excludedColumns = cache.ExcludedColumns
+	return data, cache.DroppedColumns, nil
+}
+
+// toJSONFloats converts values to floats that encode NaN as null
+func toJSONFloats(values []float64) []types.JSONFloat64 {
+	out := make([]types.JSONFloat64, len(values))
+	for i, v := range values {
+		out[i] = types.JSONFloat64(v)
+	}
+	return out
+}
+
+// fromJSONFloats converts floats decoded from JSON back to float64, with null as NaN
+func fromJSONFloats(values []types.JSONFloat64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = float64(v)
+	}
+	return out
+}
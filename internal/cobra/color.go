@@ -0,0 +1,74 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ANSI escape sequences used for colored terminal output
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiCyan   = "\033[36m"
+)
+
+// Colored table output settings
+const (
+	// screeBarWidth is the bar length for a component explaining all of the variance
+	screeBarWidth = 30
+	// highLoading is the absolute loading highlighted in colored tables
+	highLoading = 0.5
+)
+
+// useColor is set before each command runs and enables ANSI colors in table output
+var useColor bool
+
+// colorEnabled reports whether output should be colored: stdout must be a terminal,
+// and neither --no-color nor the NO_COLOR environment variable may be set
+func colorEnabled(noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return stdoutIsTerminal()
+}
+
+// stdoutIsTerminal reports whether standard output is a terminal
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width of the terminal from the COLUMNS environment
+// variable, or 80 if it is not set
+func terminalWidth() int {
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
+
+// colorize wraps text in an ANSI color when color output is enabled. Pad text before
+// coloring so escape sequences do not affect column widths.
+func colorize(color, text string) string {
+	if !useColor {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// screeBar returns a bar whose length is proportional to a percentage of explained variance
+func screeBar(percent float64) string {
+	n := int(percent/100*screeBarWidth + 0.5)
+	n = max(0, min(n, screeBarWidth))
+	return strings.Repeat("█", n)
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/spf13/cobra"
+)
+
+// ComparePreprocessingOptions holds all the options for the compare-preprocessing command
+type ComparePreprocessingOptions struct {
+	// Data format options
+	NoHeaders bool
+	NoIndex   bool
+	Delimiter string
+	NAValues  string
+
+	// Comparison options
+	Method      string
+	GroupColumn string
+
+	// Output options
+	OutputFormat string
+}
+
+// NewComparePreprocessingCommand creates the compare-preprocessing subcommand
+func NewComparePreprocessingCommand() *cobra.Command {
+	opts := &ComparePreprocessingOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "compare-preprocessing [flags] <input.csv>",
+		Short: "Compare PCA results under several preprocessing options",
+		Long: `Run the same two-component PCA under several preprocessing options and
+tabulate the variance explained by PC1 and PC2 for each, to help choose the
+preprocessing for analyze. The options compared are none, center (mean
+centering), standard, robust, and snv+standard.
+
+With --group-column, each option is also scored by how well the groups of
+a categorical column separate in the PC1–PC2 score plot: the ratio of the
+scatter between group means to the scatter within groups. Higher values
+mean tighter groups that lie further apart.
+
+A high explained variance alone does not make preprocessing better: without
+scaling, the variables with the largest variance dominate the first
+components. Missing values are not supported; fill them in first, for
+example with pca impute.
+
+EXAMPLES:
+  # Compare preprocessing options
+  pca compare-preprocessing data.csv
+
+  # Score how well the species separate under each option
+  pca compare-preprocessing --group-column species iris.csv
+
+  # Machine-readable comparison
+  pca compare-preprocessing -f json data.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runComparePreprocessing(opts, args[0])
+		},
+	}
+
+	// Data format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
+		"First column contains data, not row names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
+		"Comma-separated list of strings representing missing values")
+
+	// Comparison options
+	cmd.Flags().StringVarP(&opts.Method, "method", "m", "svd",
+		"PCA method: svd, nipals")
+	cmd.Flags().StringVar(&opts.GroupColumn, "group-column", "",
+		"Categorical column whose group separation is scored for each preprocessing option")
+
+	// Output options
+	cmd.Flags().StringVarP(&opts.OutputFormat, "format", "f", "table",
+		"Output format: table, json")
+
+	return cmd
+}
+
+// runComparePreprocessing executes the compare-preprocessing command
+func runComparePreprocessing(opts *ComparePreprocessingOptions, inputFile string) error {
+	if opts.Method != "svd" && opts.Method != "nipals" {
+		return fmt.Errorf("invalid method %q: must be svd or nipals", opts.Method)
+	}
+	if opts.OutputFormat != "table" && opts.OutputFormat != "json" {
+		return fmt.Errorf("invalid output format %q: must be table or json", opts.OutputFormat)
+	}
+
+	// Parse CSV options
+	parseOpts := pkgcsv.DefaultOptions()
+	parseOpts.HasHeaders = !opts.NoHeaders
+	parseOpts.HasRowNames = !opts.NoIndex
+	parseOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+	parseOpts.ParseMode = pkgcsv.ParseMixedWithTargets
+
+	// Parse NA values
+	if opts.NAValues != "" {
+		parseOpts.NullValues = strings.Split(opts.NAValues, ",")
+		for i := range parseOpts.NullValues {
+			parseOpts.NullValues[i] = strings.TrimSpace(parseOpts.NullValues[i])
+		}
+	}
+
+	reader := pkgcsv.NewReader(parseOpts)
+	data, err := reader.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if err := validateCSVData(data); err != nil {
+		return fmt.Errorf("data validation failed: %w", err)
+	}
+	for _, row := range data.Matrix {
+		for _, v := range row {
+			if math.IsNaN(v) {
+				return fmt.Errorf("data contains missing values; fill them in first, for example with pca impute")
+			}
+		}
+	}
+
+	var groups []string
+	if opts.GroupColumn != "" {
+		var ok bool
+		if groups, ok = data.CategoricalColumns[opts.GroupColumn]; !ok {
+			return fmt.Errorf("group column %q is not a categorical column", opts.GroupColumn)
+		}
+	}
+
+	comparisons, err := core.ComparePreprocessing(data.Matrix, opts.Method, groups)
+	if err != nil {
+		return fmt.Errorf("preprocessing comparison failed: %w", err)
+	}
+
+	if opts.OutputFormat == "json" {
+		jsonData, err := json.MarshalIndent(comparisons, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	outputPreprocessingComparison(comparisons, opts.GroupColumn)
+	return nil
+}
+
+// outputPreprocessingComparison prints the preprocessing comparison as a table
+func outputPreprocessingComparison(comparisons []core.PreprocessingComparison, groupColumn string) {
+	fmt.Println("\nPreprocessing Comparison:")
+	fmt.Println("────────────────────────────────────────────────────────────────────")
+	fmt.Printf("%-15s%12s%12s%15s", "Preprocessing", "PC1 (%)", "PC2 (%)", "Cumulative (%)")
+	if groupColumn != "" {
+		fmt.Printf("%14s", "Separation")
+	}
+	fmt.Println()
+	fmt.Println("────────────────────────────────────────────────────────────────────")
+
+	best := -1
+	for i, c := range comparisons {
+		if c.Error != "" {
+			fmt.Printf("%-15s  %s\n", c.Preprocessing, c.Error)
+			continue
+		}
+		variance := []string{"", ""}
+		for j := 0; j < len(variance) && j < len(c.ExplainedVarianceRatio); j++ {
+			variance[j] = fmt.Sprintf("%.1f", c.ExplainedVarianceRatio[j])
+		}
+		fmt.Printf("%-15s%12s%12s%15.1f", c.Preprocessing, variance[0], variance[1], c.CumulativeVariance)
+		if c.GroupSeparation != nil {
+			fmt.Printf("%14.3f", *c.GroupSeparation)
+			if best < 0 || *c.GroupSeparation > *comparisons[best].GroupSeparation {
+				best = i
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Println("────────────────────────────────────────────────────────────────────")
+
+	if best >= 0 {
+		fmt.Printf("\nBest separation of %s: %s\n", groupColumn, comparisons[best].Preprocessing)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/spf13/cobra"
+)
+
+// ConvertOptions holds all the options for the convert command
+type ConvertOptions struct {
+	// Input format options
+	NoHeaders        bool
+	NoIndex          bool
+	Delimiter        string
+	DecimalSeparator string
+	Encoding         string
+
+	// Output format options
+	OutputDelimiter        string
+	OutputDecimalSeparator string
+	Quoting                string
+}
+
+// NewConvertCommand creates the convert subcommand
+func NewConvertCommand() *cobra.Command {
+	opts := &ConvertOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "convert [flags] <input> <output>",
+		Short: "Convert between tabular file formats",
+		Long: `Convert a data file between CSV, TSV, Excel (.xlsx) and JSON formats.
+
+Formats are inferred from the file extensions. Headers and row names are
+preserved, and values are copied as text, so non-numeric columns are kept.
+Numeric values are converted between decimal separators when the input and
+output separators differ. For Excel input the first sheet is used.
+
+The JSON format is an object with "headers", "rowNames" and "data" arrays and
+the "indexHeader" of the row name column.
+
+EXAMPLES:
+  # Convert CSV to Excel
+  pca convert data.csv data.xlsx
+
+  # Convert a European CSV (semicolon, decimal comma) to TSV
+  pca convert --delimiter ';' --decimal-separator comma data.csv data.tsv
+
+  # Convert a Latin-1 encoded file to UTF-8 CSV with semicolons
+  pca convert --encoding latin1 --output-delimiter ';' legacy.csv data.csv
+
+  # Quote all text fields for tools that require it
+  pca convert --quoting nonnumeric data.xlsx data.csv`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConvert(opts, args[0], args[1])
+		},
+	}
+
+	// Input format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
+		"First column contains data, not row names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", ",",
+		"Input CSV field delimiter (ignored for TSV, Excel and JSON)")
+	cmd.Flags().StringVar(&opts.DecimalSeparator, "decimal-separator", "dot",
+		"Input decimal separator: dot or comma")
+	cmd.Flags().StringVar(&opts.Encoding, "encoding", pkgcsv.EncodingUTF8,
+		"Input text encoding: utf-8 or latin1")
+
+	// Output format options
+	cmd.Flags().StringVar(&opts.OutputDelimiter, "output-delimiter", ",",
+		"Output CSV field delimiter (ignored for TSV, Excel and JSON)")
+	cmd.Flags().StringVar(&opts.OutputDecimalSeparator, "output-decimal-separator", "dot",
+		"Output decimal separator for CSV and TSV: dot or comma")
+	cmd.Flags().StringVar(&opts.Quoting, "quoting", "minimal",
+		"Output quoting for CSV and TSV: minimal, all, nonnumeric or none")
+
+	return cmd
+}
+
+// runConvert executes the convert command
+func runConvert(opts *ConvertOptions, inputFile, outputFile string) error {
+	inputFormat, err := pkgcsv.FormatFromPath(inputFile)
+	if err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+	outputFormat, err := pkgcsv.FormatFromPath(outputFile)
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	if err := security.ValidateOutputPath(outputFile); err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+
+	decimal, err := parseDecimalSeparator(opts.DecimalSeparator)
+	if err != nil {
+		return err
+	}
+	outputDecimal, err := parseDecimalSeparator(opts.OutputDecimalSeparator)
+	if err != nil {
+		return err
+	}
+	delimiter, err := security.ValidateCSVDelimiter(opts.Delimiter)
+	if err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+	outputDelimiter, err := security.ValidateCSVDelimiter(opts.OutputDelimiter)
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	quoting, err := pkgcsv.ParseQuotingMode(opts.Quoting)
+	if err != nil {
+		return err
+	}
+
+	// Read input
+	readOpts := pkgcsv.DefaultOptions()
+	readOpts.HasHeaders = !opts.NoHeaders
+	readOpts.HasRowNames = !opts.NoIndex
+	readOpts.Delimiter = delimiter
+	readOpts.DecimalSeparator = decimal
+	readOpts.Encoding = opts.Encoding
+
+	data, err := pkgcsv.ReadTableFile(inputFile, inputFormat, readOpts)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+
+	// Write output
+	writeOpts := pkgcsv.DefaultOptions()
+	writeOpts.HasHeaders = len(data.Headers) > 0
+	writeOpts.Delimiter = outputDelimiter
+	writeOpts.DecimalSeparator = outputDecimal
+	writeOpts.Quoting = quoting
+
+	if err := pkgcsv.WriteTableFile(outputFile, outputFormat, data, writeOpts); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+
+	fmt.Printf("Converted %s (%s) to %s (%s): %d rows, %d columns\n",
+		inputFile, inputFormat, outputFile, outputFormat, data.Rows, data.Columns)
+
+	return nil
+}
+
+// parseDecimalSeparator converts a decimal separator flag value to a rune
+func parseDecimalSeparator(value string) (rune, error) {
+	switch value {
+	case "dot", ".":
+		return '.', nil
+	case "comma", ",":
+		return ',', nil
+	default:
+		return 0, fmt.Errorf("invalid decimal separator %q: must be dot or comma", value)
+	}
+}
+
+// parseThousandsSeparator converts a thousands separator flag value to a rune, with 0 for none
+func parseThousandsSeparator(value string) (rune, error) {
+	switch value {
+	case "none", "":
+		return 0, nil
+	case "dot", ".":
+		return '.', nil
+	case "comma", ",":
+		return ',', nil
+	case "space", " ":
+		return ' ', nil
+	case "apostrophe", "'":
+		return '\'', nil
+	default:
+		return 0, fmt.Errorf("invalid thousands separator %q: must be none, dot, comma, space or apostrophe", value)
+	}
+}
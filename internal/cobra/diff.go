@@ -0,0 +1,122 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bitjungle/gopca/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// DiffOptions holds all the options for the diff command
+type DiffOptions struct {
+	// Output options
+	OutputFormat string
+
+	// Fail if the models cannot be validated against the schema
+	RequireSchema bool
+}
+
+// NewDiffCommand creates the diff subcommand
+func NewDiffCommand() *cobra.Command {
+	opts := &DiffOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "diff [flags] <modelA.json> <modelB.json>",
+		Short: "Compare two PCA models",
+		Long: `Compare two PCA models exported by the analyze command.
+
+The diff command reports, for each component both models have in common,
+the correlation and RMS difference between the loading vectors and the
+change in explained variance. Component signs are arbitrary in PCA, so
+each component of the second model is sign-aligned to the first before
+comparing. Features are matched by name.
+
+EXAMPLES:
+  # Compare two runs with different preprocessing
+  pca diff run1_pca.json run2_pca.json
+
+  # Machine-readable comparison
+  pca diff -f json run1_pca.json run2_pca.json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(opts, args[0], args[1])
+		},
+	}
+
+	// Output options
+	cmd.Flags().StringVarP(&opts.OutputFormat, "format", "f", "table",
+		"Output format: table, json")
+	cmd.Flags().BoolVar(&opts.RequireSchema, "require-schema", false,
+		"Fail if the models cannot be validated against the model schema, instead of skipping schema validation with a warning")
+
+	return cmd
+}
+
+// runDiff executes the diff command
+func runDiff(opts *DiffOptions, modelFileA, modelFileB string) error {
+	modelA, err := core.ReadModelOutput(modelFileA, opts.RequireSchema)
+	if err != nil {
+		return fmt.Errorf("model A: %w", err)
+	}
+	modelB, err := core.ReadModelOutput(modelFileB, opts.RequireSchema)
+	if err != nil {
+		return fmt.Errorf("model B: %w", err)
+	}
+
+	diff, err := core.CompareModels(modelA, modelB)
+	if err != nil {
+		return fmt.Errorf("failed to compare models: %w", err)
+	}
+
+	switch opts.OutputFormat {
+	case "json":
+		jsonData, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	default: // table
+		return outputDiffTable(diff)
+	}
+}
+
+// outputDiffTable prints a model comparison as a table
+func outputDiffTable(diff *core.ModelDiff) error {
+	fmt.Println("\nModel Comparison:")
+	fmt.Println("──────────────────────────────────────────────────────────────────────────")
+	fmt.Printf("%-10s%14s%14s%12s%12s%12s\n",
+		"Component", "Correlation", "RMS Diff", "Var A (%)", "Var B (%)", "Delta (%)")
+	fmt.Println("──────────────────────────────────────────────────────────────────────────")
+
+	for _, c := range diff.Components {
+		label := c.Component
+		if c.SignFlipped {
+			label += "*"
+		}
+		fmt.Printf("%-10s%14.4f%14.4g%12.2f%12.2f%+12.2f\n",
+			label, c.LoadingCorrelation, c.RMSDifference,
+			c.ExplainedVarianceA, c.ExplainedVarianceB, c.ExplainedVarianceDelta)
+	}
+
+	fmt.Println("──────────────────────────────────────────────────────────────────────────")
+	fmt.Printf("Features compared:   %d\n", len(diff.Features))
+	fmt.Printf("Components compared: %d (model A: %d, model B: %d)\n",
+		diff.ComparedComponents, diff.ComponentsA, diff.ComponentsB)
+	for _, c := range diff.Components {
+		if c.SignFlipped {
+			fmt.Println("* Component sign flipped in model B to align with model A")
+			break
+		}
+	}
+	fmt.Printf("Verdict: %s\n", diff.Verdict)
+
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/spf13/cobra"
+)
+
+// EmbedOptions holds all the options for the embed command
+type EmbedOptions struct {
+	// Data format options
+	NoHeaders bool
+	NoIndex   bool
+	Delimiter string
+	NAValues  string
+
+	// Preprocessing options
+	Scale string
+
+	// Embedding parameters
+	Method     string
+	Perplexity float64
+	Iterations int
+	Seed       int64
+
+	// Output options
+	OutputDir string
+	Precision int
+}
+
+// NewEmbedCommand creates the embed subcommand
+func NewEmbedCommand() *cobra.Command {
+	opts := &EmbedOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "embed [flags] <input.csv>",
+		Short: "Compute a nonlinear 2D embedding for visualization",
+		Long: `Compute a two-dimensional t-SNE embedding of the samples, to explore
+cluster structure that linear PCA may not show.
+
+The embedding is for visualization only. It has no loadings or explained
+variance, cannot be applied to new data, and the distances between clusters
+and the sizes of clusters in the plot are not meaningful. Use analyze for a
+model of the data.
+
+The perplexity is roughly the number of neighbors each sample is compared
+with, and must be smaller than the number of samples; values between 5 and
+50 are typical. The random initial embedding is seeded, so the same seed
+gives the same result. Variables on different scales should be standardized
+with --scale standard. Missing values are not supported; fill them in first,
+for example with pca impute.
+
+The coordinates are written to <input>_tsne.csv with one row per sample,
+laid out like the scores of analyze -f csv.
+
+EXAMPLES:
+  # t-SNE embedding with the default perplexity of 30
+  pca embed --method tsne --scale standard data.csv
+
+  # Smaller neighborhoods for a small dataset, with another seed
+  pca embed --perplexity 10 --seed 7 -o results/ data.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEmbed(opts, args[0])
+		},
+	}
+
+	// Data format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
+		"First column contains data, not row names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
+		"Comma-separated list of strings representing missing values")
+
+	// Preprocessing options
+	cmd.Flags().StringVar(&opts.Scale, "scale", "none",
+		"Scaling method: none, standard, robust")
+
+	// Embedding parameters
+	cmd.Flags().StringVar(&opts.Method, "method", "tsne",
+		"Embedding method: tsne")
+	cmd.Flags().Float64Var(&opts.Perplexity, "perplexity", core.TSNEDefaultPerplexity,
+		"t-SNE perplexity, the effective number of neighbors (must be below the number of samples)")
+	cmd.Flags().IntVar(&opts.Iterations, "iterations", core.TSNEDefaultIterations,
+		"Number of t-SNE optimization iterations")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 1,
+		"Seed for the random initial embedding")
+
+	// Output options
+	cmd.Flags().StringVarP(&opts.OutputDir, "output-dir", "o", "",
+		"Output directory for results")
+	cmd.Flags().IntVar(&opts.Precision, "precision", -1,
+		"Significant digits for the coordinates (negative for full precision)")
+
+	return cmd
+}
+
+// runEmbed executes the embed command
+func runEmbed(opts *EmbedOptions, inputFile string) error {
+	if opts.Method != "tsne" {
+		return fmt.Errorf("invalid embedding method %q: must be tsne", opts.Method)
+	}
+	switch opts.Scale {
+	case "none", "standard", "robust":
+	default:
+		return fmt.Errorf("invalid scale method %q: must be none, standard or robust", opts.Scale)
+	}
+	if opts.Perplexity <= 0 {
+		return fmt.Errorf("--perplexity must be positive, got %g", opts.Perplexity)
+	}
+	if opts.Iterations < 1 {
+		return fmt.Errorf("--iterations must be at least 1, got %d", opts.Iterations)
+	}
+	if opts.OutputDir != "" {
+		if err := security.ValidateOutputPath(opts.OutputDir); err != nil {
+			return fmt.Errorf("invalid output directory: %w", err)
+		}
+	}
+
+	// Parse CSV options
+	parseOpts := pkgcsv.DefaultOptions()
+	parseOpts.HasHeaders = !opts.NoHeaders
+	parseOpts.HasRowNames = !opts.NoIndex
+	parseOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+	parseOpts.ParseMode = pkgcsv.ParseMixedWithTargets
+
+	// Parse NA values
+	if opts.NAValues != "" {
+		parseOpts.NullValues = strings.Split(opts.NAValues, ",")
+		for i := range parseOpts.NullValues {
+			parseOpts.NullValues[i] = strings.TrimSpace(parseOpts.NullValues[i])
+		}
+	}
+
+	reader := pkgcsv.NewReader(parseOpts)
+	data, err := reader.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if err := validateCSVData(data); err != nil {
+		return fmt.Errorf("data validation failed: %w", err)
+	}
+	for _, row := range data.Matrix {
+		for _, v := range row {
+			if math.IsNaN(v) {
+				return fmt.Errorf("data contains missing values; fill them in first, for example with pca impute")
+			}
+		}
+	}
+	if opts.Perplexity >= float64(data.Rows) {
+		return fmt.Errorf("--perplexity must be below the number of samples (%d), got %g", data.Rows, opts.Perplexity)
+	}
+
+	// Centering does not change the distances t-SNE uses, but is needed for scaling
+	preprocessor := core.NewPreprocessor(true, opts.Scale == "standard", opts.Scale == "robust")
+	processedData, err := preprocessor.FitTransform(data.Matrix)
+	if err != nil {
+		return fmt.Errorf("preprocessing failed: %w", err)
+	}
+
+	embedding, err := core.TSNEEmbed(processedData, opts.Perplexity, opts.Iterations,
+		rand.New(rand.NewSource(opts.Seed)))
+	if err != nil {
+		return fmt.Errorf("t-SNE failed: %w", err)
+	}
+
+	// Create output directory if needed
+	if opts.OutputDir != "" {
+		if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	labeled := sanitizeDataLabels(data, false)
+	observations := make([]string, len(embedding))
+	for i := range observations {
+		observations[i] = fmt.Sprintf("Sample_%d", i+1)
+		if i < len(labeled.RowNames) {
+			observations[i] = labeled.RowNames[i]
+		}
+	}
+	outputFile := generateOutputPath(inputFile, opts.OutputDir, "_tsne.csv")
+	if err := writeComponentMatrixCSV(outputFile, embedding, observations, []string{"TSNE1", "TSNE2"},
+		"observation", "coordinate", false, opts.Precision); err != nil {
+		return fmt.Errorf("failed to write embedding: %w", err)
+	}
+
+	fmt.Printf("t-SNE embedding of %d samples (perplexity %g, %d iterations, seed %d)\n",
+		data.Rows, opts.Perplexity, opts.Iterations, opts.Seed)
+	fmt.Printf("\nResults saved to: %s\n", outputFile)
+	return nil
+}
@@ -7,11 +7,18 @@
 package cobra
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"math"
+	"os"
+	"strconv"
 	"strings"
 
+	"github.com/bitjungle/gopca/internal/core"
 	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/types"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -56,47 +63,66 @@ func ProjectData(data, loadings [][]float64) [][]float64 {
 	return result
 }
 
-// validateCSVData performs basic validation on parsed CSV data
+// validateCSVData checks parsed CSV data for structural problems, columns with only
+// missing values and infinite values, which are listed with their locations. Missing
+// values (NaN) are left to each command. Commands that need more rows or columns
+// check that themselves.
 func validateCSVData(data *pkgcsv.Data) error {
-	if data == nil {
-		return fmt.Errorf("nil CSV data")
+	if err := data.Validate(pkgcsv.ValidationOptions{MinRows: 1, MinNumericColumns: 1}).Err(); err != nil {
+		return err
 	}
+	return core.ValidateNaNValues(data.Matrix, true)
+}
 
-	if len(data.Matrix) == 0 {
-		return fmt.Errorf("empty data matrix")
+// resolveDelimiter returns the field delimiter for inputFile. The names comma, semicolon
+// and tab (or "\t") are accepted, and an empty value selects a tab for .tsv and .tab
+// files and a comma otherwise.
+func resolveDelimiter(value, inputFile string) rune {
+	switch value {
+	case "":
+		if types.IsTSVFile(inputFile) {
+			return '\t'
+		}
+		return ','
+	case "comma":
+		return ','
+	case "semicolon":
+		return ';'
+	case "tab", `\t`:
+		return '\t'
 	}
+	return []rune(value)[0]
+}
 
-	if data.Rows != len(data.Matrix) {
-		return fmt.Errorf("row count mismatch")
+// fileChecksum returns the hex-encoded SHA-256 checksum of a file
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
 	}
+	defer func() { _ = file.Close() }()
 
-	// Check for consistent column count
-	for i, row := range data.Matrix {
-		if len(row) != data.Columns {
-			return fmt.Errorf("row %d has %d columns, expected %d",
-				i+1, len(row), data.Columns)
-		}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
 
-	// Check for all NaN columns
-	for j := 0; j < data.Columns; j++ {
-		allNaN := true
-		for i := 0; i < data.Rows; i++ {
-			if !math.IsNaN(data.Matrix[i][j]) {
-				allNaN = false
-				break
-			}
+// setIndexColumns configures composite row names from a comma-separated list of
+// column names or 1-based column numbers
+func setIndexColumns(opts *pkgcsv.Options, value string) {
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
 		}
-		if allNaN {
-			colName := fmt.Sprintf("%d", j+1)
-			if j < len(data.Headers) {
-				colName = data.Headers[j]
-			}
-			return fmt.Errorf("column '%s' contains only missing values", colName)
+		if n, err := strconv.Atoi(part); err == nil {
+			opts.IndexColumns = append(opts.IndexColumns, n-1)
+		} else {
+			opts.IndexColumnNames = append(opts.IndexColumnNames, part)
 		}
 	}
-
-	return nil
 }
 
 // getDataSummary returns a summary of the CSV data
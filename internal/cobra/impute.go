@@ -0,0 +1,356 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// ImputeOptions holds all the options for the impute command
+type ImputeOptions struct {
+	// Input format options
+	NoHeaders bool
+	NoIndex   bool
+	Delimiter string
+	NAValues  string
+
+	// Imputation options
+	MissingStrategy string
+	Neighbors       int
+	GroupBy         string
+	ImputeSpec      string // Per-column strategies as "column:strategy,..."
+	ImputeReport    string
+}
+
+// NewImputeCommand creates the impute subcommand
+func NewImputeCommand() *cobra.Command {
+	opts := &ImputeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "impute [flags] <input> <output>",
+		Short: "Fill in missing values without running PCA",
+		Long: `Fill in missing values in a data file and write the completed data.
+
+Missing values in numeric columns are imputed with the chosen strategy, using
+the same missing value handling as analyze. Headers, row names and non-numeric
+columns are written unchanged, as are numeric values that were not missing.
+Input and output formats (CSV, TSV, Excel or JSON) are inferred from the file
+extensions.
+
+Strategies:
+  mean         Column mean
+  median       Column median
+  knn          Mean of the column over the --neighbors most similar rows
+  iterative    Regression on the other columns, repeated until stable
+  interpolate  Linear interpolation between the neighbouring rows, for data
+               in time or sequence order
+  mode         Most frequent value of the column
+  forward      Last observed value above, for data in time or sequence order
+  drop         Remove rows with missing values
+
+--impute-spec sets the strategy of individual columns, overriding
+--missing-strategy for them. Only mean, median, mode, forward and interpolate can
+be set per column; the other columns use --missing-strategy.
+
+EXAMPLES:
+  # Impute with column medians
+  pca impute --missing-strategy median data.csv completed.csv
+
+  # Impute from the 10 most similar samples and list every imputed cell
+  pca impute --missing-strategy knn --neighbors 10 --impute-report imputed.csv data.csv completed.csv
+
+  # Impute with the mean of each species rather than of all rows
+  pca impute --impute-group-by species iris.csv completed.csv
+
+  # Fill gaps in a time series
+  pca impute --missing-strategy interpolate series.tsv completed.tsv
+
+  # Carry the last reading of a sensor forward and impute the other columns with medians
+  pca impute --missing-strategy median --impute-spec "pressure:forward,grade:mode" data.csv completed.csv`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImpute(opts, args[0], args[1])
+		},
+	}
+
+	// Input format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
+		"First column contains data, not row names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
+		"Comma-separated list of strings representing missing values")
+
+	// Imputation options
+	cmd.Flags().StringVar(&opts.MissingStrategy, "missing-strategy", "mean",
+		"Imputation strategy: mean, median, knn, iterative, interpolate, mode, forward, drop")
+	cmd.Flags().IntVar(&opts.Neighbors, "neighbors", core.DefaultImputeNeighbors,
+		"Number of similar rows averaged by the knn strategy")
+	cmd.Flags().StringVar(&opts.GroupBy, "impute-group-by", "",
+		"Column whose groups the mean and median are computed within, falling back to all rows for groups without observed values")
+	cmd.Flags().StringVar(&opts.ImputeSpec, "impute-spec", "",
+		"Strategies of individual columns overriding --missing-strategy, e.g. \"length:mean,width:forward\"")
+	cmd.Flags().StringVar(&opts.ImputeReport, "impute-report", "",
+		"Write a CSV listing every imputed cell, or the cells that caused rows to be dropped")
+
+	return cmd
+}
+
+// runImpute executes the impute command
+func runImpute(opts *ImputeOptions, inputFile, outputFile string) error {
+	strategy := types.MissingValueStrategy(opts.MissingStrategy)
+	switch strategy {
+	case types.MissingMean, types.MissingMedian, types.MissingKNN, types.MissingIterative,
+		types.MissingInterpolate, types.MissingMode, types.MissingForward, types.MissingDrop:
+	default:
+		return fmt.Errorf("invalid missing value strategy %q: must be mean, median, knn, iterative, interpolate, mode, forward or drop",
+			opts.MissingStrategy)
+	}
+	spec, err := parseImputeSpec(opts.ImputeSpec)
+	if err != nil {
+		return err
+	}
+	handler := core.NewMissingValueHandler(strategy)
+	if err := handler.SetNeighbors(opts.Neighbors); err != nil {
+		return err
+	}
+	if opts.GroupBy != "" && opts.NoHeaders {
+		return fmt.Errorf("--impute-group-by needs column names and cannot be combined with --no-headers")
+	}
+	if len(spec) > 0 && opts.NoHeaders {
+		return fmt.Errorf("--impute-spec needs column names and cannot be combined with --no-headers")
+	}
+
+	inputFormat, err := pkgcsv.FormatFromPath(inputFile)
+	if err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+	outputFormat, err := pkgcsv.FormatFromPath(outputFile)
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	if err := security.ValidateOutputPath(outputFile); err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	if opts.ImputeReport != "" {
+		if err := security.ValidateOutputPath(opts.ImputeReport); err != nil {
+			return fmt.Errorf("invalid impute report path: %w", err)
+		}
+	}
+
+	// Read input as text so non-numeric columns are kept as they are
+	readOpts := pkgcsv.DefaultOptions()
+	readOpts.HasHeaders = !opts.NoHeaders
+	readOpts.HasRowNames = !opts.NoIndex
+	readOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+
+	data, err := pkgcsv.ReadTableFile(inputFile, inputFormat, readOpts)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+
+	naValues := make(map[string]bool)
+	for _, v := range strings.Split(opts.NAValues, ",") {
+		naValues[strings.TrimSpace(v)] = true
+	}
+
+	numeric := imputeMatrix(data, naValues)
+	if numeric.Columns == 0 {
+		return fmt.Errorf("no numeric columns found in %s", inputFile)
+	}
+
+	if opts.GroupBy != "" {
+		col := slices.Index(data.Headers, opts.GroupBy)
+		if col < 0 {
+			return fmt.Errorf("impute group column %q not found (columns: %s)", opts.GroupBy, strings.Join(data.Headers, ", "))
+		}
+		groups := make([]string, len(data.StringData))
+		for i, record := range data.StringData {
+			if value := strings.TrimSpace(record[col]); !naValues[value] {
+				groups[i] = value
+			}
+		}
+		if err := handler.SetGroups(groups); err != nil {
+			return err
+		}
+	}
+
+	if len(spec) > 0 {
+		columnStrategies := make(map[int]types.MissingValueStrategy, len(spec))
+		for name, columnStrategy := range spec {
+			col := slices.Index(data.Headers, name)
+			if col < 0 {
+				return fmt.Errorf("impute spec column %q not found (columns: %s)", name, strings.Join(data.Headers, ", "))
+			}
+			numericCol := slices.Index(numeric.sourceColumns, col)
+			if numericCol < 0 {
+				return fmt.Errorf("impute spec column %q is not numeric, and only numeric columns can be imputed with %s",
+					name, columnStrategy)
+			}
+			columnStrategies[numericCol] = columnStrategy
+		}
+		if err := handler.SetColumnStrategies(columnStrategies); err != nil {
+			return err
+		}
+	}
+
+	missingInfo := numeric.GetMissingValueInfo(nil)
+	_, changes, err := handler.HandleMissingValuesWithChanges(numeric.Matrix, missingInfo, nil)
+	if err != nil {
+		return fmt.Errorf("failed to handle missing values: %w", err)
+	}
+
+	if opts.ImputeReport != "" {
+		if err := writeImputeReport(opts.ImputeReport, numeric.Data, changes); err != nil {
+			return err
+		}
+	}
+
+	// Write imputed values back into the text data, and remove dropped rows
+	for _, cell := range changes.Imputed {
+		data.StringData[cell.Row][numeric.sourceColumns[cell.Column]] = formatCSVFloat(cell.Value)
+	}
+	if len(changes.Dropped) > 0 {
+		dropped := make([]int, len(changes.Dropped))
+		for i, row := range changes.Dropped {
+			dropped[i] = row.Row
+		}
+		_, keepRows := core.RemoveRows(numeric.Matrix, dropped)
+		stringData := make([][]string, len(keepRows))
+		for i, r := range keepRows {
+			stringData[i] = data.StringData[r]
+		}
+		data.StringData = stringData
+		filterDataRows(data, keepRows)
+		data.Rows = len(stringData)
+	}
+
+	writeOpts := pkgcsv.DefaultOptions()
+	writeOpts.HasHeaders = len(data.Headers) > 0
+	if err := pkgcsv.WriteTableFile(outputFile, outputFormat, data, writeOpts); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+
+	strategies := fmt.Sprintf("the %s strategy", strategy)
+	if len(spec) > 0 {
+		strategies += " and the strategies of --impute-spec"
+	}
+	if len(changes.Imputed) > 0 || strategy != types.MissingDrop {
+		fmt.Printf("Imputed %d missing values in %d of %d numeric columns using %s\n",
+			len(changes.Imputed), len(missingInfo.ColumnIndices), numeric.Columns, strategies)
+	}
+	if strategy == types.MissingDrop {
+		fmt.Printf("Dropped %d rows with missing values in %d numeric columns\n",
+			len(changes.Dropped), len(missingInfo.ColumnIndices))
+	}
+	if numeric.otherMissing > 0 {
+		fmt.Printf("Warning: %d missing values in non-numeric columns were left unchanged\n", numeric.otherMissing)
+	}
+	fmt.Printf("\nResults saved to: %s\n", outputFile)
+
+	return nil
+}
+
+// parseImputeSpec parses per-column strategies given as "column:strategy,...", keyed
+// by column name. Only strategies that fill a column from its own values are allowed.
+func parseImputeSpec(spec string) (map[string]types.MissingValueStrategy, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	strategies := make(map[string]types.MissingValueStrategy)
+	for _, entry := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(entry, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid --impute-spec entry %q: expected column:strategy", entry)
+		}
+		if _, exists := strategies[name]; exists {
+			return nil, fmt.Errorf("column %q is given more than once in --impute-spec", name)
+		}
+		strategy := types.MissingValueStrategy(strings.ToLower(value))
+		if !core.ColumnLocalStrategy(strategy) {
+			return nil, fmt.Errorf("invalid strategy %q for column %q in --impute-spec: must be mean, median, mode, forward or interpolate",
+				value, name)
+		}
+		strategies[name] = strategy
+	}
+	return strategies, nil
+}
+
+// imputeData is the numeric part of a text table prepared for imputation
+type imputeData struct {
+	*pkgcsv.Data
+	sourceColumns []int // Column in the text data of each numeric column
+	otherMissing  int   // Missing values in non-numeric columns
+}
+
+// imputeMatrix extracts the numeric columns of string data as a matrix with NaN for
+// missing values. A column is numeric if all of its non-missing values parse as
+// numbers and at least one is present.
+func imputeMatrix(data *pkgcsv.Data, naValues map[string]bool) *imputeData {
+	result := &imputeData{Data: &pkgcsv.Data{RowNames: data.RowNames, Rows: data.Rows}}
+	nCols := 0
+	if len(data.StringData) > 0 {
+		nCols = len(data.StringData[0])
+	}
+
+	for col := 0; col < nCols; col++ {
+		values := make([]float64, len(data.StringData))
+		isNumeric, observed, missing := true, 0, 0
+		for row, record := range data.StringData {
+			value := strings.TrimSpace(record[col])
+			if naValues[value] {
+				values[row] = math.NaN()
+				missing++
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				isNumeric = false
+				break
+			}
+			values[row] = v
+			observed++
+		}
+		if !isNumeric || observed == 0 {
+			for _, record := range data.StringData {
+				if naValues[strings.TrimSpace(record[col])] {
+					result.otherMissing++
+				}
+			}
+			continue
+		}
+
+		if result.Matrix == nil {
+			result.Matrix = make(types.Matrix, len(data.StringData))
+		}
+		for row, v := range values {
+			result.Matrix[row] = append(result.Matrix[row], v)
+		}
+		header := fmt.Sprintf("Column %d", col+1)
+		if col < len(data.Headers) {
+			header = data.Headers[col]
+		}
+		result.Headers = append(result.Headers, header)
+		result.sourceColumns = append(result.sourceColumns, col)
+	}
+	result.Columns = len(result.sourceColumns)
+
+	return result
+}
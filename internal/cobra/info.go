@@ -0,0 +1,151 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// InfoOptions holds all the options for the info command
+type InfoOptions struct {
+	// Output options
+	OutputFormat string
+}
+
+// NewInfoCommand creates the info subcommand
+func NewInfoCommand() *cobra.Command {
+	opts := &InfoOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "info [flags] <model.json>",
+		Short: "Summarize a saved PCA model",
+		Long: `Summarize a PCA model exported by the analyze command: the method, the
+number of components, the preprocessing pipeline, the number of variables
+and observations, the explained variance of each component, the checksum
+of the training data and the GoPCA version that created the model.
+
+Model files written by older or newer versions of GoPCA are summarized as
+far as possible. Fields the file lacks are shown as unknown, and fields
+this version does not recognize are listed as warnings.
+
+EXAMPLES:
+  # Summarize a model
+  pca info data_pca.json
+
+  # Machine-readable summary
+  pca info -f json data_pca.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInfo(opts, args[0])
+		},
+	}
+
+	// Output options
+	cmd.Flags().StringVarP(&opts.OutputFormat, "format", "f", "table",
+		"Output format: table, json")
+
+	return cmd
+}
+
+// runInfo executes the info command
+func runInfo(opts *InfoOptions, modelFile string) error {
+	if opts.OutputFormat != "table" && opts.OutputFormat != "json" {
+		return fmt.Errorf("invalid output format %q: must be table or json", opts.OutputFormat)
+	}
+
+	data, err := os.ReadFile(modelFile)
+	if err != nil {
+		return fmt.Errorf("failed to read model file: %w", err)
+	}
+	info, err := core.SummarizeModel(data)
+	if err != nil {
+		return fmt.Errorf("model %s: %w", modelFile, err)
+	}
+
+	if opts.OutputFormat == "json" {
+		jsonData, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	outputInfoTable(info)
+	return nil
+}
+
+// outputInfoTable prints a model summary as a table
+func outputInfoTable(info *core.ModelInfo) {
+	unknown := func(s string) string {
+		if s == "" {
+			return "unknown"
+		}
+		return s
+	}
+	preprocessing := "none"
+	if len(info.Preprocessing) > 0 {
+		preprocessing = strings.Join(info.Preprocessing, " → ")
+	}
+	observations := "unknown"
+	if info.Observations > 0 {
+		observations = fmt.Sprintf("%d", info.Observations)
+	}
+
+	fmt.Println("\nModel Information:")
+	fmt.Println("──────────────────────────────────────────────────")
+	fmt.Printf("Method:           %s\n", unknown(strings.ToUpper(info.Method)))
+	fmt.Printf("Components:       %d\n", info.Components)
+	fmt.Printf("Preprocessing:    %s\n", preprocessing)
+	fmt.Printf("Variables:        %d\n", info.Variables)
+	fmt.Printf("Observations:     %s\n", observations)
+	if info.MissingStrategy != "" {
+		fmt.Printf("Missing values:   %s\n", info.MissingStrategy)
+	}
+	if len(info.DroppedColumns) > 0 {
+		fmt.Printf("Dropped columns:  %s\n", strings.Join(info.DroppedColumns, ", "))
+	}
+	fmt.Printf("Training data:    %s\n", unknown(info.DataFile))
+	fmt.Printf("Data checksum:    %s\n", unknown(info.DataChecksum))
+	fmt.Printf("GoPCA version:    %s\n", unknown(info.SoftwareVersion))
+	fmt.Printf("Created:          %s\n", unknown(info.CreatedAt))
+
+	fmt.Println("\nExplained Variance:")
+	fmt.Println("──────────────────────────────────────────────────")
+	fmt.Printf("%-12s%18s%18s\n", "Component", "Variance (%)", "Cumulative (%)")
+	fmt.Println("──────────────────────────────────────────────────")
+	for i := 0; i < info.Components; i++ {
+		label := fmt.Sprintf("PC%d", i+1)
+		if i < len(info.ComponentLabels) {
+			label = info.ComponentLabels[i]
+		}
+		variance, cumulative := "unknown", "unknown"
+		if i < len(info.ExplainedVarianceRatio) {
+			variance = fmt.Sprintf("%.2f", info.ExplainedVarianceRatio[i])
+		}
+		if i < len(info.CumulativeVariance) {
+			cumulative = fmt.Sprintf("%.2f", info.CumulativeVariance[i])
+		}
+		fmt.Printf("%-12s%18s%18s\n", label, variance, cumulative)
+	}
+	fmt.Println("──────────────────────────────────────────────────")
+
+	if len(info.MissingFields) > 0 {
+		fmt.Printf("\nWarning: model file does not record %s (written by an older version of GoPCA?)\n",
+			strings.Join(info.MissingFields, ", "))
+	}
+	if len(info.UnknownFields) > 0 {
+		fmt.Printf("Warning: ignoring unknown fields %s (written by a newer version of GoPCA?)\n",
+			strings.Join(info.UnknownFields, ", "))
+	}
+}
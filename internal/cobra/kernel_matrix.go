@@ -0,0 +1,286 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	"github.com/bitjungle/gopca/internal/utils"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// Kernel matrix diagnostics
+const (
+	// kernelConditionWarning is the condition number above which the kernel matrix is
+	// reported as near-singular
+	kernelConditionWarning = 1e12
+	// kernelNegativeTolerance is the relative size of a negative eigenvalue, compared to
+	// the largest eigenvalue, above which the kernel is reported as not positive semidefinite
+	kernelNegativeTolerance = 1e-8
+	// kernelConstantWarning is the ratio of the largest centered to uncentered eigenvalue
+	// below which the kernel matrix is reported as nearly constant
+	kernelConstantWarning = 1e-6
+	// kernelIdentityWarning is the mean off-diagonal RBF kernel entry below which the
+	// kernel matrix is reported as close to the identity
+	kernelIdentityWarning = 1e-6
+)
+
+// KernelMatrixOptions holds all the options for the kernel-matrix command
+type KernelMatrixOptions struct {
+	// Data format options
+	NoHeaders bool
+	NoIndex   bool
+	Delimiter string
+	NAValues  string
+
+	// Preprocessing options
+	NoMeanCentering bool
+	Scale           string
+
+	// Kernel parameters
+	KernelType   string
+	KernelGamma  string
+	KernelDegree int
+	KernelCoef0  float64
+
+	// Output options
+	Output     string
+	Uncentered bool
+}
+
+// NewKernelMatrixCommand creates the kernel-matrix subcommand
+func NewKernelMatrixCommand() *cobra.Command {
+	opts := &KernelMatrixOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "kernel-matrix [flags] <input.csv>",
+		Short: "Inspect the kernel matrix used by kernel PCA",
+		Long: `Compute the kernel (Gram) matrix that kernel PCA decomposes and print
+summary statistics for it, to help choose kernel parameters such as gamma.
+
+The data is preprocessed and the kernel computed as in analyze --method kernel.
+The table compares the kernel matrix with the centered kernel matrix: the range
+and mean of the entries, the extreme eigenvalues and the condition number.
+Warnings are printed when the matrix contains non-finite values, is
+near-singular, nearly constant or not positive semidefinite. For the RBF
+kernel, a nearly constant matrix means gamma is too small, and one close to
+the identity means gamma is too large.
+
+EXAMPLES:
+  # Summarize the RBF kernel matrix for standardized data
+  pca kernel-matrix --scale standard --kernel-gamma 0.5 data.csv
+
+  # Write the centered kernel matrix to CSV
+  pca kernel-matrix --kernel-gamma median -o kernel.csv data.csv
+
+  # Write the polynomial kernel matrix before centering
+  pca kernel-matrix --kernel-type poly --kernel-degree 2 --uncentered -o kernel.csv data.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runKernelMatrix(opts, args[0])
+		},
+	}
+
+	// Data format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
+		"First column contains data, not row names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
+		"Comma-separated list of strings representing missing values")
+
+	// Preprocessing options
+	cmd.Flags().BoolVar(&opts.NoMeanCentering, "no-mean-centering", false,
+		"Disable mean centering")
+	cmd.Flags().StringVar(&opts.Scale, "scale", "none",
+		"Scaling method: none, standard, robust")
+
+	// Kernel parameters
+	cmd.Flags().StringVar(&opts.KernelType, "kernel-type", "rbf",
+		"Kernel type: linear, poly, rbf")
+	cmd.Flags().StringVar(&opts.KernelGamma, "kernel-gamma", "0.01",
+		"Gamma parameter for RBF/poly kernels, or auto (1/(n_features·var(X))) or median (1/median squared distance) for RBF")
+	cmd.Flags().IntVar(&opts.KernelDegree, "kernel-degree", 3,
+		"Degree for polynomial kernel")
+	cmd.Flags().Float64Var(&opts.KernelCoef0, "kernel-coef0", 0.0,
+		"Coef0 for polynomial kernel")
+
+	// Output options
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Write the kernel matrix to this CSV file")
+	cmd.Flags().BoolVar(&opts.Uncentered, "uncentered", false,
+		"Write the kernel matrix before centering instead of the centered matrix")
+
+	return cmd
+}
+
+// runKernelMatrix executes the kernel-matrix command
+func runKernelMatrix(opts *KernelMatrixOptions, inputFile string) error {
+	switch opts.Scale {
+	case "none", "standard", "robust":
+	default:
+		return fmt.Errorf("invalid scale method %q: must be none, standard or robust", opts.Scale)
+	}
+	gamma, heuristic, err := parseKernelGamma(opts.KernelGamma)
+	if err != nil {
+		return err
+	}
+	if heuristic != "" && opts.KernelType != string(core.KernelRBF) {
+		return fmt.Errorf("--kernel-gamma %s is only supported for the rbf kernel", heuristic)
+	}
+	if opts.Output != "" {
+		if err := security.ValidateOutputPath(opts.Output); err != nil {
+			return fmt.Errorf("invalid output path: %w", err)
+		}
+	}
+
+	// Parse CSV options
+	parseOpts := pkgcsv.DefaultOptions()
+	parseOpts.HasHeaders = !opts.NoHeaders
+	parseOpts.HasRowNames = !opts.NoIndex
+	parseOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+	parseOpts.ParseMode = pkgcsv.ParseMixedWithTargets
+
+	// Parse NA values
+	if opts.NAValues != "" {
+		parseOpts.NullValues = strings.Split(opts.NAValues, ",")
+		for i := range parseOpts.NullValues {
+			parseOpts.NullValues[i] = strings.TrimSpace(parseOpts.NullValues[i])
+		}
+	}
+
+	reader := pkgcsv.NewReader(parseOpts)
+	data, err := reader.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if err := validateCSVData(data); err != nil {
+		return fmt.Errorf("data validation failed: %w", err)
+	}
+	for _, row := range data.Matrix {
+		for _, v := range row {
+			if math.IsNaN(v) {
+				return fmt.Errorf("data contains missing values; fill them in first, for example with pca impute")
+			}
+		}
+	}
+
+	preprocessor := core.NewPreprocessor(!opts.NoMeanCentering, opts.Scale == "standard", opts.Scale == "robust")
+	processedData, err := preprocessor.FitTransform(data.Matrix)
+	if err != nil {
+		return fmt.Errorf("preprocessing failed: %w", err)
+	}
+
+	config := types.PCAConfig{
+		KernelType:   opts.KernelType,
+		KernelGamma:  gamma,
+		KernelDegree: opts.KernelDegree,
+		KernelCoef0:  opts.KernelCoef0,
+	}
+	if heuristic != "" {
+		config.KernelGamma, err = core.EstimateKernelGamma(processedData, heuristic)
+		if err != nil {
+			return err
+		}
+	}
+
+	K, Kc, err := core.KernelMatrices(processedData, config)
+	if err != nil {
+		return err
+	}
+	stats, err := core.SummarizeKernelMatrix(K)
+	if err != nil {
+		return err
+	}
+	centeredStats, err := core.SummarizeKernelMatrix(Kc)
+	if err != nil {
+		return err
+	}
+
+	outputKernelMatrixStats(config, data.Rows, stats, centeredStats)
+
+	if opts.Output != "" {
+		matrix := Kc
+		if opts.Uncentered {
+			matrix = K
+		}
+		names := make([]string, data.Rows)
+		for i := range names {
+			names[i] = fmt.Sprintf("Sample_%d", i+1)
+			if i < len(data.RowNames) && data.RowNames[i] != "" {
+				names[i] = data.RowNames[i]
+			}
+		}
+		writeOpts := pkgcsv.DefaultOptions()
+		if err := pkgcsv.SaveMatrix(opts.Output, utils.DenseToMatrix(matrix), names, names, writeOpts); err != nil {
+			return fmt.Errorf("failed to write kernel matrix: %w", err)
+		}
+		fmt.Printf("\nResults saved to: %s\n", opts.Output)
+	}
+
+	return nil
+}
+
+// outputKernelMatrixStats prints summary statistics of the kernel matrix before and
+// after centering, followed by warnings about numerical problems
+func outputKernelMatrixStats(config types.PCAConfig, samples int, stats, centered core.KernelMatrixStats) {
+	params := ""
+	switch core.KernelType(config.KernelType) {
+	case core.KernelRBF:
+		params = fmt.Sprintf(", gamma = %.6g", config.KernelGamma)
+	case core.KernelPoly:
+		params = fmt.Sprintf(", gamma = %.6g, degree = %d, coef0 = %g",
+			config.KernelGamma, config.KernelDegree, config.KernelCoef0)
+	}
+
+	fmt.Printf("\nKernel Matrix (%s%s, %d samples):\n", config.KernelType, params, samples)
+	fmt.Println("──────────────────────────────────────────────────")
+	fmt.Printf("%-20s%15s%15s\n", "Statistic", "Kernel", "Centered")
+	fmt.Println("──────────────────────────────────────────────────")
+	fmt.Printf("%-20s%15.6g%15.6g\n", "Min", stats.Min, centered.Min)
+	fmt.Printf("%-20s%15.6g%15.6g\n", "Max", stats.Max, centered.Max)
+	fmt.Printf("%-20s%15.6g%15.6g\n", "Mean", stats.Mean, centered.Mean)
+	fmt.Printf("%-20s%15.6g%15.6g\n", "Min eigenvalue", stats.MinEigenvalue, centered.MinEigenvalue)
+	fmt.Printf("%-20s%15.6g%15.6g\n", "Max eigenvalue", stats.MaxEigenvalue, centered.MaxEigenvalue)
+	// Centering makes the matrix singular, so only the kernel's condition number is informative
+	fmt.Printf("%-20s%15.6g%15s\n", "Condition number", stats.ConditionNumber, "-")
+	fmt.Printf("%-20s%15d%15d\n", "Numerical rank", stats.NumericalRank, centered.NumericalRank)
+
+	if stats.NonFinite > 0 {
+		fmt.Printf("Warning: kernel matrix contains %d non-finite values; reduce gamma or the polynomial degree\n",
+			stats.NonFinite)
+		return
+	}
+	if stats.ConditionNumber > kernelConditionWarning {
+		fmt.Printf("Warning: kernel matrix is near-singular (condition number %.3g, numerical rank %d of %d); "+
+			"duplicate samples or a small gamma can cause this\n", stats.ConditionNumber, stats.NumericalRank, samples)
+	}
+	if centered.MaxEigenvalue < kernelConstantWarning*stats.MaxEigenvalue {
+		fmt.Println("Warning: kernel matrix is nearly constant, so little structure remains after centering; " +
+			"gamma is probably too small")
+	}
+	if stats.MinEigenvalue < -kernelNegativeTolerance*math.Abs(stats.MaxEigenvalue) {
+		fmt.Printf("Warning: kernel matrix is not positive semidefinite (min eigenvalue %.3g)\n",
+			stats.MinEigenvalue)
+	}
+	// RBF kernels have ones on the diagonal, so the mean gives the mean off-diagonal entry
+	if core.KernelType(config.KernelType) == core.KernelRBF && samples > 1 {
+		n := float64(samples)
+		if offDiagonal := (stats.Mean*n*n - n) / (n * (n - 1)); offDiagonal < kernelIdentityWarning {
+			fmt.Printf("Warning: kernel matrix is close to the identity (mean off-diagonal entry %.3g); "+
+				"gamma is probably too large\n", offDiagonal)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bitjungle/gopca/internal/core"
+	"github.com/bitjungle/gopca/internal/version"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// MergeModelsOptions holds all the options for the merge-models command
+type MergeModelsOptions struct {
+	// Output options
+	Output       string
+	OutputFormat string
+}
+
+// NewMergeModelsCommand creates the merge-models subcommand
+func NewMergeModelsCommand() *cobra.Command {
+	opts := &MergeModelsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "merge-models [flags] <model1.json> <model2.json> [model3.json...]",
+		Short: "Merge several PCA models into a consensus model",
+		Long: `Merge PCA models exported by the analyze command into a single consensus
+model, for example models fitted to bootstrap samples or to repeated runs.
+
+Each model's loadings are aligned to the first model with an orthogonal
+Procrustes rotation, which undoes sign flips and rotations of the
+components. The aligned loadings are averaged and replaced by the nearest
+orthonormal matrix. Explained variances and the fitted feature means,
+standard deviations, medians and MADs are averaged. Features are matched
+by name, and only the components all models have in common are merged.
+The models must use the same preprocessing.
+
+The Procrustes disparity of each model to the first, the relative squared
+difference of the aligned loadings, measures how consistent the models
+are: 0 means they span the same subspace. The consensus model has no
+sample scores or diagnostic limits, but can be used with transform.
+
+EXAMPLES:
+  # Merge three runs into a consensus model
+  pca merge-models -o consensus_pca.json run1_pca.json run2_pca.json run3_pca.json
+
+  # Machine-readable consistency report
+  pca merge-models -f json run*_pca.json`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMergeModels(opts, args)
+		},
+	}
+
+	// Output options
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "consensus_pca.json",
+		"Write the consensus model to this JSON file")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "format", "f", "table",
+		"Report format: table, json")
+
+	return cmd
+}
+
+// runMergeModels executes the merge-models command
+func runMergeModels(opts *MergeModelsOptions, modelFiles []string) error {
+	if err := security.ValidateOutputPath(opts.Output); err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+
+	models := make([]*types.PCAOutputData, len(modelFiles))
+	for i, path := range modelFiles {
+		model, err := core.ReadModelOutput(path, false)
+		if err != nil {
+			return fmt.Errorf("model %s: %w", path, err)
+		}
+		models[i] = model
+	}
+
+	merge, err := core.MergeModels(models)
+	if err != nil {
+		return fmt.Errorf("failed to merge models: %w", err)
+	}
+
+	names := make([]string, len(modelFiles))
+	for i, path := range modelFiles {
+		names[i] = filepath.Base(path)
+	}
+	consensus := merge.Consensus
+	consensus.Metadata.AnalysisID = uuid.New().String()
+	consensus.Metadata.SoftwareVersion = version.Version
+	consensus.Metadata.CreatedAt = time.Now().Format(time.RFC3339)
+	consensus.Metadata.Software = "gopca"
+	consensus.Metadata.Description = fmt.Sprintf("Consensus of %d models: %s",
+		len(modelFiles), strings.Join(names, ", "))
+
+	// Marshal JSON
+	jsonData, err := json.MarshalIndent(consensus, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// Write output
+	if err := os.WriteFile(opts.Output, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	switch opts.OutputFormat {
+	case "json":
+		jsonData, err := json.MarshalIndent(merge, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+	default: // table
+		outputMergeTable(merge, modelFiles)
+		fmt.Printf("\nResults saved to: %s\n", opts.Output)
+	}
+	return nil
+}
+
+// outputMergeTable prints the Procrustes disparity of each merged model
+func outputMergeTable(merge *core.ModelMerge, modelFiles []string) {
+	fmt.Println("\nModel Consistency:")
+	fmt.Println("──────────────────────────────────────────────────")
+	fmt.Printf("%-36s%14s\n", "Model", "Disparity")
+	fmt.Println("──────────────────────────────────────────────────")
+	for i, path := range modelFiles {
+		name := path
+		if i == 0 {
+			name += " (reference)"
+		}
+		fmt.Printf("%-36s%14.4g\n", name, merge.Disparities[i])
+	}
+	fmt.Println("──────────────────────────────────────────────────")
+	fmt.Printf("Features merged:   %d\n", merge.Features)
+	fmt.Printf("Components merged: %d\n", merge.Components)
+	fmt.Printf("Mean disparity:    %.4g (max %.4g)\n", merge.MeanDisparity, merge.MaxDisparity)
+}
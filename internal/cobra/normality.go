@@ -0,0 +1,166 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/spf13/cobra"
+)
+
+// NormalityOptions holds all the options for the normality command
+type NormalityOptions struct {
+	// Data format options
+	NoHeaders bool
+	NoIndex   bool
+	Delimiter string
+	NAValues  string
+
+	// Test options
+	Alpha float64
+
+	// Output options
+	OutputFormat string
+}
+
+// ColumnNormality is the normality test result for a single column
+type ColumnNormality struct {
+	Column string `json:"column"`
+	*core.NormalityTestResult
+	Error string `json:"error,omitempty"`
+}
+
+// NewNormalityCommand creates the normality subcommand
+func NewNormalityCommand() *cobra.Command {
+	opts := &NormalityOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "normality [flags] <input.csv>",
+		Short: "Test numeric columns for normality",
+		Long: `Test each numeric column of a CSV file for normality.
+
+The Shapiro-Wilk test is used for columns with up to 5000 non-missing
+values, and the D'Agostino-Pearson omnibus test for larger columns.
+A column is reported as normal when the p-value is at least alpha.
+
+EXAMPLES:
+  # Test all numeric columns at the 5% level
+  pca normality data.csv
+
+  # Use a stricter significance level and JSON output
+  pca normality --alpha 0.01 -f json data.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runNormality(opts, args[0])
+		},
+	}
+
+	// Data format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
+		"First column contains data, not row names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
+		"Comma-separated list of strings representing missing values")
+
+	// Test options
+	cmd.Flags().Float64Var(&opts.Alpha, "alpha", core.DefaultNormalityAlpha,
+		"Significance level for the normality test")
+
+	// Output options
+	cmd.Flags().StringVarP(&opts.OutputFormat, "format", "f", "table",
+		"Output format: table, json")
+
+	return cmd
+}
+
+// runNormality executes the normality command
+func runNormality(opts *NormalityOptions, inputFile string) error {
+	if opts.Alpha <= 0 || opts.Alpha >= 1 {
+		return fmt.Errorf("alpha must be between 0 and 1, got %g", opts.Alpha)
+	}
+
+	// Parse CSV options
+	parseOpts := pkgcsv.DefaultOptions()
+	parseOpts.HasHeaders = !opts.NoHeaders
+	parseOpts.HasRowNames = !opts.NoIndex
+	parseOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+	parseOpts.ParseMode = pkgcsv.ParseMixedWithTargets
+
+	// Parse NA values
+	if opts.NAValues != "" {
+		parseOpts.NullValues = strings.Split(opts.NAValues, ",")
+		for i := range parseOpts.NullValues {
+			parseOpts.NullValues[i] = strings.TrimSpace(parseOpts.NullValues[i])
+		}
+	}
+
+	reader := pkgcsv.NewReader(parseOpts)
+	data, err := reader.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse CSV: %w", err)
+	}
+
+	if err := validateCSVData(data); err != nil {
+		return fmt.Errorf("data validation failed: %w", err)
+	}
+
+	results := make([]ColumnNormality, data.Columns)
+	for j := 0; j < data.Columns; j++ {
+		colName := fmt.Sprintf("Column_%d", j+1)
+		if j < len(data.Headers) {
+			colName = data.Headers[j]
+		}
+
+		values := make([]float64, data.Rows)
+		for i := 0; i < data.Rows; i++ {
+			values[i] = data.Matrix[i][j]
+		}
+
+		results[j].Column = colName
+		normality, err := core.NormalityTest(values, opts.Alpha)
+		if err != nil {
+			results[j].Error = err.Error()
+			continue
+		}
+		results[j].NormalityTestResult = normality
+	}
+
+	switch opts.OutputFormat {
+	case "json":
+		jsonData, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+	default: // table
+		fmt.Printf("\nNormality Tests (alpha = %g):\n", opts.Alpha)
+		fmt.Println("──────────────────────────────────────────────────────────────────────────")
+		fmt.Printf("%-20s%8s%20s%12s%12s%10s\n", "Column", "N", "Method", "Statistic", "p-value", "Normal")
+		fmt.Println("──────────────────────────────────────────────────────────────────────────")
+		for _, r := range results {
+			if r.NormalityTestResult == nil {
+				fmt.Printf("%-20s  %s\n", r.Column, r.Error)
+				continue
+			}
+			normal := "no"
+			if r.IsNormal {
+				normal = "yes"
+			}
+			fmt.Printf("%-20s%8d%20s%12.4f%12.4g%10s\n",
+				r.Column, r.N, r.Method, r.Statistic, r.PValue, normal)
+		}
+	}
+
+	return nil
+}
@@ -7,25 +7,34 @@
 package cobra
 
 import (
+	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bitjungle/gopca/internal/core"
 	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
 	"github.com/bitjungle/gopca/pkg/types"
 )
 
-// outputTableFormat outputs PCA results in table format
+// outputTableFormat outputs PCA results in table format. Loadings with absolute
+// value below loadingsThreshold are shown blank; this only affects the display.
 func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
-	outputScores, outputLoadings, outputVariance, includeMetrics bool) error {
+	outputScores, outputLoadings, outputVariance, includeMetrics bool, loadingsThreshold float64,
+	groupSummary string) error {
 
 	// Calculate metrics if requested (skip for kernel PCA as it doesn't have loadings)
 	var metrics []types.SampleMetrics
 	if includeMetrics && outputScores {
-		if result.Method != "kernel" {
+		if result.Method != "kernel" && len(result.Metrics) == 0 {
 			var err error
 			metrics, err = core.CalculateMetricsFromPCAResult(result, data.Matrix)
 			if err != nil {
@@ -34,7 +43,8 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 				metrics = make([]types.SampleMetrics, len(result.Scores))
 			}
 		} else {
-			// For kernel PCA, use metrics from result if available
+			// Use metrics from result if available: kernel PCA, or metrics calculated
+			// before the scores were normalized
 			if len(result.Metrics) > 0 {
 				metrics = result.Metrics
 			} else {
@@ -46,7 +56,11 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 
 	// Output scores table
 	if outputScores {
-		fmt.Println("\nPCA Scores:")
+		if result.ScoreScale != nil {
+			fmt.Println("\nPCA Scores (normalized to unit variance):")
+		} else {
+			fmt.Println("\nPCA Scores:")
+		}
 		fmt.Println("──────────────────────────────────────────────────────────────")
 
 		// Print headers
@@ -90,7 +104,12 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 			if rowIdx < len(data.RowNames) {
 				sampleID = data.RowNames[rowIdx]
 			}
-			fmt.Printf("%-15s", sampleID)
+			isOutlier := includeMetrics && metrics != nil && metrics[rowIdx].IsOutlier
+			if isOutlier {
+				fmt.Print(colorize(ansiRed, fmt.Sprintf("%-15s", sampleID)))
+			} else {
+				fmt.Printf("%-15s", sampleID)
+			}
 
 			// PC scores
 			for j := 0; j < len(result.ComponentLabels); j++ {
@@ -100,11 +119,11 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 			// Metrics
 			if includeMetrics && metrics != nil {
 				metric := metrics[rowIdx]
-				outlierStr := "False"
-				if metric.IsOutlier {
-					outlierStr = "True"
+				outlierStr := fmt.Sprintf("%10s", "False")
+				if isOutlier {
+					outlierStr = colorize(ansiRed+ansiBold, fmt.Sprintf("%10s", "True"))
 				}
-				fmt.Printf("%15.4f%18.4f%10.4f%10s",
+				fmt.Printf("%15.4f%18.4f%10.4f%s",
 					metric.HotellingT2, metric.Mahalanobis, metric.RSS, outlierStr)
 			}
 
@@ -154,7 +173,16 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 
 				fmt.Printf("%-25s", data.Headers[featureIdx])
 				for j := 0; j < len(result.ComponentLabels); j++ {
-					fmt.Printf("%12.4f", result.Loadings[featureIdx][j])
+					loading := result.Loadings[featureIdx][j]
+					if math.Abs(loading) < loadingsThreshold {
+						fmt.Printf("%12s", "")
+						continue
+					}
+					if math.Abs(loading) >= highLoading {
+						fmt.Print(colorize(ansiBold, fmt.Sprintf("%12.4f", loading)))
+						continue
+					}
+					fmt.Printf("%12.4f", loading)
 				}
 				fmt.Println()
 			}
@@ -162,6 +190,12 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 			if nFeatures > 25 {
 				fmt.Printf("\nShowing first 20 and last 5 of %d features\n", nFeatures)
 			}
+			if loadingsThreshold > 0 {
+				fmt.Printf("\nLoadings with absolute value below %g are not shown\n", loadingsThreshold)
+			}
+
+			outputVariableImportance(result, data.Headers)
+			outputComponentInterpretation(result, data.Headers)
 		} else {
 			fmt.Println("\nNote: Loadings are not available for Kernel PCA")
 		}
@@ -171,14 +205,102 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 	if outputVariance {
 		fmt.Println("\nExplained Variance:")
 		fmt.Println("──────────────────────────────────────────────────────────────")
-		fmt.Printf("%-15s%15s%15s\n", "Component", "Variance", "Cumulative")
+		fmt.Printf("%-15s%15s%15s", "Component", "Variance", "Cumulative")
+		if len(result.ComponentStatus) > 0 {
+			fmt.Printf("%10s", "Status")
+		}
+		fmt.Println()
 		fmt.Println("──────────────────────────────────────────────────────────────")
 
 		for i := 0; i < len(result.ComponentLabels); i++ {
-			fmt.Printf("%-15s%14.1f%%%14.1f%%\n",
+			fmt.Printf("%-15s%14.1f%%%14.1f%%",
 				result.ComponentLabels[i],
 				result.ExplainedVarRatio[i],
 				result.CumulativeVar[i])
+			if len(result.ComponentStatus) > 0 {
+				fmt.Printf("%10s", result.ComponentStatus[i])
+			}
+			// Scree bars are only drawn on color terminals to keep plain output unchanged
+			if useColor {
+				fmt.Printf("  %s", colorize(ansiCyan, screeBar(result.ExplainedVarRatio[i])))
+			}
+			fmt.Println()
+		}
+
+		eigenvalues := result.AllEigenvalues
+		if len(eigenvalues) == 0 {
+			eigenvalues = result.ExplainedVar
+		}
+		if n := len(result.CumulativeVar); n > 0 {
+			fmt.Printf("\nSignal captured: %.1f%% of the total variance in %d components\n",
+				result.CumulativeVar[n-1], n)
+		}
+		fmt.Printf("Effective dimensionality: %.2f (participation ratio of all eigenvalues)\n",
+			core.EffectiveDimensionality(eigenvalues))
+	}
+
+	if result.VarianceCurve != nil {
+		outputVarianceCurve(result.VarianceCurve)
+	}
+
+	// Output supplementary group scores
+	if groups := result.SupplementaryGroups; groups != nil {
+		fmt.Printf("\nSupplementary Groups (%s):\n", groups.Column)
+		fmt.Println("──────────────────────────────────────────────────────────────")
+		fmt.Printf("%-15s%8s", "Category", "N")
+		for i := 0; i < len(result.ComponentLabels); i++ {
+			fmt.Printf("%12s", result.ComponentLabels[i])
+		}
+		fmt.Println()
+		fmt.Println("──────────────────────────────────────────────────────────────")
+		for k, category := range groups.Categories {
+			fmt.Printf("%-15s%8d", category, groups.Counts[k])
+			for j := 0; j < len(result.ComponentLabels); j++ {
+				fmt.Printf("%12.4f", groups.Scores[k][j])
+			}
+			fmt.Println()
+		}
+	}
+
+	// Output mean scores per category
+	if groupSummary != "" {
+		categories, counts, means, err := core.GroupScoreMeans(result.Scores, data.CategoricalColumns[groupSummary])
+		if err != nil {
+			return fmt.Errorf("failed to summarize scores by %s: %w", groupSummary, err)
+		}
+		fmt.Printf("\nMean Scores by Group (%s):\n", groupSummary)
+		fmt.Println("──────────────────────────────────────────────────────────────")
+		fmt.Printf("%-15s%8s", "Category", "N")
+		for i := 0; i < len(result.ComponentLabels); i++ {
+			fmt.Printf("%12s", result.ComponentLabels[i])
+		}
+		fmt.Println()
+		fmt.Println("──────────────────────────────────────────────────────────────")
+		for k, category := range categories {
+			fmt.Printf("%-15s%8d", category, counts[k])
+			for j := 0; j < len(result.ComponentLabels); j++ {
+				fmt.Printf("%12.4f", means[k][j])
+			}
+			fmt.Println()
+		}
+	}
+
+	// Output eigencorrelations
+	if eig := result.Eigencorrelations; eig != nil {
+		fmt.Printf("\nEigencorrelations (%s):\n", eig.Method)
+		fmt.Println("──────────────────────────────────────────────────────────────")
+		fmt.Printf("%-25s", "Variable")
+		for _, component := range eig.Components {
+			fmt.Printf("%12s", component)
+		}
+		fmt.Println()
+		fmt.Println("──────────────────────────────────────────────────────────────")
+		for _, variable := range eig.Variables {
+			fmt.Printf("%-25s", variable)
+			for _, corr := range eig.Correlations[variable] {
+				fmt.Printf("%12.4f", corr)
+			}
+			fmt.Println()
 		}
 	}
 
@@ -197,9 +319,115 @@ func outputTableFormat(result *types.PCAResult, data *pkgcsv.Data,
 		}
 	}
 
+	if len(result.Influence) > 0 {
+		outputInfluence(result.Influence, data.RowNames)
+	}
+
 	return nil
 }
 
+// outputVarianceCurve prints the explained variance of all components and the elbow
+// of the cumulative curve
+func outputVarianceCurve(curve *types.VarianceCurve) {
+	fmt.Println("\nExplained Variance Curve (all components):")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%-15s%15s%15s\n", "Component", "Variance", "Cumulative")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	for k, ratio := range curve.ExplainedVarianceRatio {
+		fmt.Printf("%-15s%14.1f%%%14.1f%%", fmt.Sprintf("PC%d", k+1), ratio, curve.CumulativeVariance[k])
+		if k+1 == curve.Elbow {
+			fmt.Print("  ← elbow")
+		}
+		fmt.Println()
+	}
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("Elbow: %d components (farthest from the line through the ends of the cumulative curve)\n",
+		curve.Elbow)
+}
+
+// outputInfluence prints the observations of the influence plot beyond the 95% T²
+// or Q limit, with the quadrant they fall in
+func outputInfluence(points []types.InfluencePoint, rowNames []string) {
+	fmt.Println("\nInfluence Plot (T² against Q, 95% limits):")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%-20s%15s%15s%12s\n", "Observation", "Hotelling T²", "Q (SPE)", "Status")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	beyond := 0
+	for i, p := range points {
+		if p.Status == core.InfluenceNormal {
+			continue
+		}
+		beyond++
+		name := fmt.Sprintf("Sample_%d", i+1)
+		if i < len(rowNames) {
+			name = rowNames[i]
+		}
+		fmt.Printf("%-20s%15.4f%15.4f%12s\n", name, p.T2, p.Q, p.Status)
+	}
+	fmt.Printf("%d of %d observations beyond a limit\n", beyond, len(points))
+}
+
+// outputVariableImportance prints variables ranked by their importance across all
+// retained components (squared loadings weighted by explained variance)
+func outputVariableImportance(result *types.PCAResult, headers []string) {
+	importance := core.VariableImportance(result.Loadings, result.ExplainedVarRatio)
+	if len(importance) == 0 {
+		return
+	}
+
+	order := make([]int, len(importance))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return importance[order[a]] > importance[order[b]]
+	})
+
+	fmt.Println("\nVariable Importance:")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%-25s%12s\n", "Variable", "Importance")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+
+	featuresToShow := len(order)
+	if featuresToShow > 20 {
+		featuresToShow = 20
+	}
+	for _, idx := range order[:featuresToShow] {
+		name := fmt.Sprintf("Feature_%d", idx+1)
+		if idx < len(headers) {
+			name = headers[idx]
+		}
+		fmt.Printf("%-25s%12.4f\n", name, importance[idx])
+	}
+	if len(order) > featuresToShow {
+		fmt.Printf("\nShowing top %d of %d features\n", featuresToShow, len(order))
+	}
+}
+
+// outputComponentInterpretation prints the variables with the largest positive and
+// negative loadings on each component as a reading aid
+func outputComponentInterpretation(result *types.PCAResult, headers []string) {
+	interpretations := core.TopLoadings(result.Loadings, headers, core.InterpretationTopN)
+	if len(interpretations) == 0 {
+		return
+	}
+
+	fmt.Println("\nComponent Interpretation:")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	for k, c := range interpretations {
+		label := c.Component
+		if k < len(result.ComponentLabels) {
+			label = result.ComponentLabels[k]
+		}
+		fmt.Printf("%-6s %s\n", label+":", c.Summary)
+		for _, l := range slices.Concat(c.Positive, c.Negative) {
+			fmt.Printf("         %-25s%10.4f\n", l.Variable, l.Loading)
+		}
+	}
+	fmt.Printf("\nListed are loadings of at least %g%% of the largest on each component; "+
+		"the sign of a component is arbitrary\n", core.TopLoadingsRelativeThreshold*100)
+}
+
 // outputJSONFormat outputs PCA results in JSON format
 func outputJSONFormat(result *types.PCAResult, data *pkgcsv.Data, inputFile string,
 	opts *AnalyzeOptions, config types.PCAConfig, preprocessor *core.Preprocessor,
@@ -209,28 +437,53 @@ func outputJSONFormat(result *types.PCAResult, data *pkgcsv.Data, inputFile stri
 	exportMeta := &pkgcsv.ExportMetadata{
 		InputFilename: filepath.Base(inputFile),
 	}
+	if inputFile == stdinInput {
+		exportMeta.InputFilename = "stdin"
+	} else if checksum, err := fileChecksum(inputFile); err == nil {
+		// Per-group output files are named after files that do not exist, so they get no checksum
+		exportMeta.InputHash = checksum
+	}
 	// Convert to PCAOutputData with metadata
 	outputData := pkgcsv.ConvertToPCAOutputDataWithMetadata(result, data, opts.IncludeMetrics,
 		config, preprocessor, categoricalData, targetData, exportMeta)
+	pkgcsv.RoundOutputData(outputData, opts.Precision)
+	if names := carryColumnNames(opts.CarryCols); len(names) > 0 {
+		outputData.Results.Samples.CarriedColumns = carriedColumns(data, names)
+	}
+	if opts.ScoresNDJSON != "" {
+		// Scores are streamed to the NDJSON file instead
+		outputData.Results.Samples.Scores = types.Matrix{}
+	}
 
-	// Generate output paths
-	outputFile := generateOutputPath(inputFile, opts.OutputDir, "_pca.json")
-
-	// Create output directory if needed
-	if opts.OutputDir != "" {
-		if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+	// The scores are encoded as they are written, so large score matrices are not
+	// held in memory as JSON
+	if opts.jsonStdout != nil {
+		if err := pkgcsv.WriteOutputJSON(opts.jsonStdout, outputData, opts.JSONCompact); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(opts.jsonStdout); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
 		}
+		return nil
 	}
 
-	// Marshal JSON
-	jsonData, err := json.MarshalIndent(outputData, "", "  ")
+	// Generate output paths
+	outputBase, err := analyzeOutputBase(inputFile, opts, result)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return err
 	}
+	outputFile := outputBase + "_pca.json"
 
 	// Write output
-	if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create JSON file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := pkgcsv.WriteOutputJSON(file, outputData, opts.JSONCompact); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+	if err := file.Close(); err != nil {
 		return fmt.Errorf("failed to write JSON file: %w", err)
 	}
 
@@ -239,6 +492,548 @@ func outputJSONFormat(result *types.PCAResult, data *pkgcsv.Data, inputFile stri
 	return nil
 }
 
+// scoresRecord is one line of the NDJSON scores file
+type scoresRecord struct {
+	Name   string              `json:"name"`
+	Scores []types.JSONFloat64 `json:"scores"`
+}
+
+// writeScoresNDJSON streams the scores to filename as newline-delimited JSON, one
+// {"name": ..., "scores": [...]} object per observation, so large score matrices can
+// be processed line by line. Observations without a row name are numbered from 1.
+func writeScoresNDJSON(filename string, result *types.PCAResult, data *pkgcsv.Data, precision int) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create scores NDJSON file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	record := scoresRecord{}
+	for i, row := range result.Scores {
+		record.Name = strconv.Itoa(i + 1)
+		if i < len(data.RowNames) && data.RowNames[i] != "" {
+			record.Name = data.RowNames[i]
+		}
+		record.Scores = record.Scores[:0]
+		for _, v := range row {
+			record.Scores = append(record.Scores, types.JSONFloat64(pkgcsv.RoundFloat(v, precision)))
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write scores NDJSON file: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write scores NDJSON file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write scores NDJSON file: %w", err)
+	}
+
+	fmt.Printf("Scores saved to: %s (%d observations)\n", filename, len(result.Scores))
+	return nil
+}
+
+// writeReconstruction writes the data reconstructed from the fitted components, in
+// original units, to <base>_reconstruction.csv (or .tsv with --tsv) with the row
+// names and variable names of the input
+func writeReconstruction(result *types.PCAResult, preprocessor *core.Preprocessor, data *pkgcsv.Data,
+	inputFile string, opts *AnalyzeOptions) error {
+	reconstruction, err := core.Reconstruct(result, preprocessor)
+	if err != nil {
+		return fmt.Errorf("reconstruction failed: %w", err)
+	}
+
+	outputBase, err := analyzeOutputBase(inputFile, opts, result)
+	if err != nil {
+		return err
+	}
+	outputFile := outputBase + "_reconstruction.csv"
+	if opts.TSV {
+		outputFile = outputBase + "_reconstruction.tsv"
+	}
+
+	observations := make([]string, len(reconstruction))
+	for i := range observations {
+		observations[i] = fmt.Sprintf("Sample_%d", i+1)
+		if i < len(data.RowNames) {
+			observations[i] = data.RowNames[i]
+		}
+	}
+	if err := writeComponentMatrixCSV(outputFile, reconstruction, observations, data.Headers,
+		"observation", "value", false, opts.Precision); err != nil {
+		return fmt.Errorf("failed to write reconstruction: %w", err)
+	}
+
+	fmt.Printf("Reconstruction saved to: %s (%d component(s))\n", outputFile, len(result.ComponentLabels))
+	return nil
+}
+
+// writeExportedComponents writes the scores of the given 0-based components to
+// <base>_components.csv (or .tsv with --tsv), followed by the --carry-cols columns,
+// and their loadings to <base>_components_loadings.csv if the model has loadings. The
+// columns are selected from the fitted results and keep their component labels.
+func writeExportedComponents(result *types.PCAResult, data *pkgcsv.Data, inputFile string,
+	opts *AnalyzeOptions, components []int) error {
+	ext := ".csv"
+	if opts.TSV {
+		ext = ".tsv"
+	}
+	outputBase, err := analyzeOutputBase(inputFile, opts, result)
+	if err != nil {
+		return err
+	}
+
+	labels := make([]string, len(components))
+	for k, c := range components {
+		labels[k] = result.ComponentLabels[c]
+	}
+
+	observations := make([]string, len(result.Scores))
+	for i := range observations {
+		observations[i] = fmt.Sprintf("Sample_%d", i+1)
+		if i < len(data.RowNames) {
+			observations[i] = data.RowNames[i]
+		}
+	}
+	scoresFile := outputBase + "_components" + ext
+	records := componentMatrixRecords(selectComponentColumns(result.Scores, components), observations, labels,
+		"observation", "score", false, opts.Precision)
+	if names := carryColumnNames(opts.CarryCols); len(names) > 0 {
+		appendCarriedColumns(records, data, names, false, len(labels))
+	}
+	if err := writeCSVRecords(scoresFile, records); err != nil {
+		return fmt.Errorf("failed to write exported components: %w", err)
+	}
+	fmt.Printf("Components %s saved to: %s\n", strings.Join(labels, ", "), scoresFile)
+
+	// Kernel PCA and scores-only results have no loadings
+	if len(result.Loadings) > 0 {
+		loadingsFile := outputBase + "_components_loadings" + ext
+		if err := writeComponentMatrixCSV(loadingsFile, selectComponentColumns(result.Loadings, components),
+			data.Headers, labels, "variable", "loading", false, opts.Precision); err != nil {
+			return fmt.Errorf("failed to write exported loadings: %w", err)
+		}
+		fmt.Printf("Loadings of components %s saved to: %s\n", strings.Join(labels, ", "), loadingsFile)
+	}
+	return nil
+}
+
+// selectComponentColumns returns the given columns of a matrix with one column per
+// component
+func selectComponentColumns(matrix types.Matrix, columns []int) types.Matrix {
+	selected := make(types.Matrix, len(matrix))
+	for i, row := range matrix {
+		selected[i] = make([]float64, len(columns))
+		for k, c := range columns {
+			selected[i][k] = row[c]
+		}
+	}
+	return selected
+}
+
+// outputCSVFormat writes scores, loadings and explained variance to CSV files, or to
+// tab-separated .tsv files with --tsv. Loadings and scores are written either as wide
+// matrices (one column per component) or in tidy long format (one row per variable
+// or observation and component).
+func outputCSVFormat(result *types.PCAResult, data *pkgcsv.Data, inputFile string,
+	opts *AnalyzeOptions) error {
+
+	ext := ".csv"
+	if opts.TSV {
+		ext = ".tsv"
+	}
+
+	outputBase, err := analyzeOutputBase(inputFile, opts, result)
+	if err != nil {
+		return err
+	}
+
+	var written []string
+
+	if opts.OutputScores || opts.OutputAll {
+		observations := make([]string, len(result.Scores))
+		for i := range observations {
+			observations[i] = fmt.Sprintf("Sample_%d", i+1)
+			if i < len(data.RowNames) {
+				observations[i] = data.RowNames[i]
+			}
+		}
+		outputFile := outputBase + "_scores" + ext
+		tidy := opts.ScoresFormat == "tidy"
+		records := componentMatrixRecords(result.Scores, observations, result.ComponentLabels,
+			"observation", "score", tidy, opts.Precision)
+		if names := carryColumnNames(opts.CarryCols); len(names) > 0 {
+			appendCarriedColumns(records, data, names, tidy, len(result.ComponentLabels))
+		}
+		if err := writeCSVRecords(outputFile, records); err != nil {
+			return fmt.Errorf("failed to write scores: %w", err)
+		}
+		written = append(written, outputFile)
+	}
+
+	// Kernel PCA has no loadings
+	if (opts.OutputLoadings || opts.OutputAll) && result.Method != "kernel" {
+		outputFile := outputBase + "_loadings" + ext
+		if err := writeComponentMatrixCSV(outputFile, result.Loadings, data.Headers, result.ComponentLabels,
+			"variable", "loading", opts.LoadingsFormat == "tidy", opts.Precision); err != nil {
+			return fmt.Errorf("failed to write loadings: %w", err)
+		}
+		written = append(written, outputFile)
+	}
+
+	if opts.OutputVariance || opts.OutputAll {
+		outputFile := outputBase + "_variance" + ext
+		rows := [][]string{{"component", "explained_variance", "explained_variance_ratio", "cumulative_variance"}}
+		if len(result.ComponentStatus) > 0 {
+			rows[0] = append(rows[0], "component_status")
+		}
+		for i, label := range result.ComponentLabels {
+			row := []string{label,
+				formatCSVFloatPrecision(result.ExplainedVar[i], opts.Precision),
+				formatCSVFloatPrecision(result.ExplainedVarRatio[i], opts.Precision),
+				formatCSVFloatPrecision(result.CumulativeVar[i], opts.Precision)}
+			if len(result.ComponentStatus) > 0 {
+				row = append(row, result.ComponentStatus[i])
+			}
+			rows = append(rows, row)
+		}
+		if err := writeCSVRecords(outputFile, rows); err != nil {
+			return fmt.Errorf("failed to write explained variance: %w", err)
+		}
+		written = append(written, outputFile)
+	}
+
+	if curve := result.VarianceCurve; curve != nil {
+		outputFile := outputBase + "_variance_curve" + ext
+		rows := [][]string{{"component", "explained_variance_ratio", "cumulative_variance", "elbow"}}
+		for k, ratio := range curve.ExplainedVarianceRatio {
+			rows = append(rows, []string{fmt.Sprintf("PC%d", k+1),
+				formatCSVFloatPrecision(ratio, opts.Precision),
+				formatCSVFloatPrecision(curve.CumulativeVariance[k], opts.Precision),
+				strconv.FormatBool(k+1 == curve.Elbow)})
+		}
+		if err := writeCSVRecords(outputFile, rows); err != nil {
+			return fmt.Errorf("failed to write explained variance curve: %w", err)
+		}
+		written = append(written, outputFile)
+	}
+
+	if len(result.Influence) > 0 {
+		outputFile := outputBase + "_influence" + ext
+		rows := [][]string{{"observation", "t2", "q", "t2_limit95", "q_limit95", "status"}}
+		for i, p := range result.Influence {
+			name := fmt.Sprintf("Sample_%d", i+1)
+			if i < len(data.RowNames) {
+				name = data.RowNames[i]
+			}
+			rows = append(rows, []string{name,
+				formatCSVFloatPrecision(p.T2, opts.Precision),
+				formatCSVFloatPrecision(p.Q, opts.Precision),
+				formatCSVFloatPrecision(p.T2Limit95, opts.Precision),
+				formatCSVFloatPrecision(p.QLimit95, opts.Precision),
+				p.Status})
+		}
+		if err := writeCSVRecords(outputFile, rows); err != nil {
+			return fmt.Errorf("failed to write influence plot: %w", err)
+		}
+		written = append(written, outputFile)
+	}
+
+	if groups := result.SupplementaryGroups; groups != nil {
+		outputFile := outputBase + "_supplementary" + ext
+		rows := [][]string{append([]string{"category", "count"}, result.ComponentLabels...)}
+		for k, category := range groups.Categories {
+			record := []string{category, strconv.Itoa(groups.Counts[k])}
+			for _, v := range groups.Scores[k] {
+				record = append(record, formatCSVFloatPrecision(v, opts.Precision))
+			}
+			rows = append(rows, record)
+		}
+		if err := writeCSVRecords(outputFile, rows); err != nil {
+			return fmt.Errorf("failed to write supplementary groups: %w", err)
+		}
+		written = append(written, outputFile)
+	}
+
+	fmt.Println("\nResults saved to:")
+	for _, file := range written {
+		fmt.Printf("  %s\n", file)
+	}
+
+	return nil
+}
+
+// writeComponentMatrixCSV writes a matrix with one column per component. In wide format the
+// first column holds the row labels; in tidy format each cell becomes a
+// (rowHeader, component, valueHeader) record. Values have precision significant
+// digits, or full precision if it is negative.
+func writeComponentMatrixCSV(filename string, matrix types.Matrix, rowLabels, componentLabels []string,
+	rowHeader, valueHeader string, tidy bool, precision int) error {
+	return writeCSVRecords(filename, componentMatrixRecords(matrix, rowLabels, componentLabels,
+		rowHeader, valueHeader, tidy, precision))
+}
+
+// componentMatrixRecords returns the records written by writeComponentMatrixCSV
+func componentMatrixRecords(matrix types.Matrix, rowLabels, componentLabels []string,
+	rowHeader, valueHeader string, tidy bool, precision int) [][]string {
+
+	var rows [][]string
+	if tidy {
+		rows = append(rows, []string{rowHeader, "component", valueHeader})
+		for i, values := range matrix {
+			for j, label := range componentLabels {
+				rows = append(rows, []string{rowLabels[i], label, formatCSVFloatPrecision(values[j], precision)})
+			}
+		}
+	} else {
+		rows = append(rows, append([]string{rowHeader}, componentLabels...))
+		for i, values := range matrix {
+			record := make([]string, 0, len(componentLabels)+1)
+			record = append(record, rowLabels[i])
+			for j := range componentLabels {
+				record = append(record, formatCSVFloatPrecision(values[j], precision))
+			}
+			rows = append(rows, record)
+		}
+	}
+
+	return rows
+}
+
+// carryColumnNames returns the column names listed in --carry-cols
+func carryColumnNames(carryCols string) []string {
+	var names []string
+	for _, name := range strings.Split(carryCols, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// carriedColumns returns the named categorical and target columns of data, which
+// are aligned with the rows of the scores
+func carriedColumns(data *pkgcsv.Data, names []string) *types.PreservedColumns {
+	carried := &types.PreservedColumns{}
+	for _, name := range names {
+		if values, ok := data.CategoricalColumns[name]; ok {
+			if carried.Categorical == nil {
+				carried.Categorical = make(map[string][]string)
+			}
+			carried.Categorical[name] = values
+		} else if values, ok := data.NumericTargetColumns[name]; ok {
+			if carried.NumericTarget == nil {
+				carried.NumericTarget = make(map[string][]float64)
+			}
+			carried.NumericTarget[name] = values
+		}
+	}
+	return carried
+}
+
+// appendCarriedColumns appends the named categorical and target columns of data to
+// score records from componentMatrixRecords, in which each data row has one record,
+// or nComponents records in tidy format
+func appendCarriedColumns(records [][]string, data *pkgcsv.Data, names []string, tidy bool, nComponents int) {
+	records[0] = append(records[0], names...)
+	for k := 1; k < len(records); k++ {
+		row := k - 1
+		if tidy {
+			row /= nComponents
+		}
+		for _, name := range names {
+			if values, ok := data.CategoricalColumns[name]; ok {
+				records[k] = append(records[k], values[row])
+			} else {
+				records[k] = append(records[k], formatCSVFloat(data.NumericTargetColumns[name][row]))
+			}
+		}
+	}
+}
+
+// labelReplacer replaces line breaks, which break line-oriented output, with spaces
+var labelReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// maxRenamedLabels is the number of renamed labels listed in a warning
+const maxRenamedLabels = 3
+
+// sanitizeLabels prepares row or variable labels for output files. Line breaks are
+// replaced with spaces, surrounding whitespace is trimmed, and repeated non-empty
+// labels get a numeric suffix ("a", "a_2", "a_3") so every label is unique. Delimiters,
+// tabs and quotes are left to the CSV and JSON encoders, which quote and escape them.
+// The returned warnings describe labels that were changed.
+func sanitizeLabels(labels []string) ([]string, []string) {
+	if labels == nil {
+		return nil, nil
+	}
+
+	clean := make([]string, len(labels))
+	taken := make(map[string]bool, len(labels))
+	replaced := 0
+	for i, label := range labels {
+		if strings.ContainsAny(label, "\r\n") {
+			replaced++
+		}
+		clean[i] = strings.TrimSpace(labelReplacer.Replace(label))
+		taken[clean[i]] = true
+	}
+
+	// Rename repeats, skipping suffixes that are labels of their own
+	seen := make(map[string]bool, len(clean))
+	next := make(map[string]int)
+	var renamed []string
+	for i, label := range clean {
+		if label == "" {
+			continue
+		}
+		if !seen[label] {
+			seen[label] = true
+			continue
+		}
+		if next[label] == 0 {
+			next[label] = 2
+		}
+		candidate := fmt.Sprintf("%s_%d", label, next[label])
+		for taken[candidate] {
+			next[label]++
+			candidate = fmt.Sprintf("%s_%d", label, next[label])
+		}
+		next[label]++
+		taken[candidate] = true
+		seen[candidate] = true
+		clean[i] = candidate
+		renamed = append(renamed, fmt.Sprintf("%q to %q", label, candidate))
+	}
+
+	var warnings []string
+	if replaced > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d label(s) contained line breaks, replaced with spaces", replaced))
+	}
+	if len(renamed) > 0 {
+		listed := renamed
+		if len(listed) > maxRenamedLabels {
+			listed = append(listed[:maxRenamedLabels:maxRenamedLabels], "...")
+		}
+		warnings = append(warnings, fmt.Sprintf("%d duplicate label(s) renamed: %s",
+			len(renamed), strings.Join(listed, ", ")))
+	}
+	return clean, warnings
+}
+
+// sanitizeDataLabels returns a shallow copy of data with row names and column headers
+// cleaned by sanitizeLabels, printing a warning for each change unless quiet is set
+func sanitizeDataLabels(data *pkgcsv.Data, quiet bool) *pkgcsv.Data {
+	labeled := *data
+	var rowWarnings, columnWarnings []string
+	labeled.RowNames, rowWarnings = sanitizeLabels(data.RowNames)
+	labeled.Headers, columnWarnings = sanitizeLabels(data.Headers)
+
+	if !quiet {
+		for _, w := range rowWarnings {
+			fmt.Printf("Warning: row names: %s\n", w)
+		}
+		for _, w := range columnWarnings {
+			fmt.Printf("Warning: column names: %s\n", w)
+		}
+	}
+	return &labeled
+}
+
+// writeCSVRecords writes records to a CSV file, tab-separated for .tsv and .tab files
+func writeCSVRecords(filename string, rows [][]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	writer := csv.NewWriter(file)
+	if types.IsTSVFile(filename) {
+		writer.Comma = '\t'
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// formatCSVFloat formats a value with the shortest representation that round-trips
+func formatCSVFloat(v float64) string {
+	return formatCSVFloatPrecision(v, -1)
+}
+
+// formatCSVFloatPrecision formats a value with precision significant digits, or like
+// formatCSVFloat if precision is negative
+func formatCSVFloatPrecision(v float64, precision int) string {
+	if precision < 0 {
+		precision = -1
+	}
+	return strconv.FormatFloat(v, 'g', precision, 64)
+}
+
+// outputTemplatePlaceholders are the placeholders --output-template may contain
+var outputTemplatePlaceholders = []string{"{base}", "{method}", "{components}"}
+
+// validateOutputTemplate checks that an --output-template only contains known
+// placeholders and characters that are safe in file names
+func validateOutputTemplate(template string) error {
+	name := template
+	for _, placeholder := range outputTemplatePlaceholders {
+		name = strings.ReplaceAll(name, placeholder, "x")
+	}
+	if strings.ContainsAny(name, "{}") {
+		return fmt.Errorf("invalid --output-template %q: unknown placeholder, must be one of %s",
+			template, strings.Join(outputTemplatePlaceholders, ", "))
+	}
+	if name == "" || security.SanitizeFilename(name) != name {
+		return fmt.Errorf("invalid --output-template %q: must give a file name without path separators or special characters",
+			template)
+	}
+	return nil
+}
+
+// expandOutputTemplate replaces the placeholders of an --output-template with the
+// input file name without extension, the PCA method and the number of components
+func expandOutputTemplate(template, base string, result *types.PCAResult) string {
+	return strings.NewReplacer(
+		"{base}", base,
+		"{method}", result.Method,
+		"{components}", strconv.Itoa(len(result.ComponentLabels)),
+	).Replace(template)
+}
+
+// analyzeOutputBase returns the path, without suffix, that the output files of an
+// analysis are named after, and creates the output directory if needed. The name is
+// the input file name without extension, or the expanded --output-template.
+func analyzeOutputBase(inputFile string, opts *AnalyzeOptions, result *types.PCAResult) (string, error) {
+	dir := filepath.Dir(inputFile)
+	if opts.OutputDir != "" {
+		dir = opts.OutputDir
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+	if inputFile == stdinInput {
+		name = "stdin"
+	}
+	if opts.OutputTemplate != "" {
+		name = expandOutputTemplate(opts.OutputTemplate, name, result)
+		if security.SanitizeFilename(name) != name {
+			return "", fmt.Errorf("--output-template %q gives the unsafe file name %q", opts.OutputTemplate, name)
+		}
+	}
+
+	base := filepath.Join(dir, name)
+	if err := security.ValidateOutputPath(base); err != nil {
+		return "", fmt.Errorf("invalid output path: %w", err)
+	}
+	return base, nil
+}
+
 // generateOutputPath creates an output file path based on input file and format
 func generateOutputPath(inputFile, outputDir, suffix string) string {
 	// Get the directory and base name of the input file
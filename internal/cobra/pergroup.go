@@ -0,0 +1,150 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// unsafeFileChars matches characters that are replaced in group names used in file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// groupSummary holds the fitted result for one group in a per-group analysis
+type groupSummary struct {
+	name    string
+	rows    int
+	result  *types.PCAResult
+	skipped string
+}
+
+// runAnalyzePerGroup fits an independent PCA model on the rows of each category of
+// the per-group column, writes per-group outputs and prints a summary comparing the
+// explained variance across groups. Rows with an empty category are not analyzed.
+func runAnalyzePerGroup(opts *AnalyzeOptions, data *pkgcsv.Data, inputFile string) error {
+	// Collect rows per category in order of first appearance
+	var groups []string
+	groupRows := make(map[string][]int)
+	for i, category := range data.CategoricalColumns[opts.PerGroup] {
+		if category == "" {
+			continue
+		}
+		if _, ok := groupRows[category]; !ok {
+			groups = append(groups, category)
+		}
+		groupRows[category] = append(groupRows[category], i)
+	}
+	if len(groups) == 0 {
+		return fmt.Errorf("per-group column %q has no categories", opts.PerGroup)
+	}
+
+	dir := filepath.Dir(inputFile)
+	ext := filepath.Ext(inputFile)
+	base := strings.TrimSuffix(filepath.Base(inputFile), ext)
+
+	summaries := make([]groupSummary, len(groups))
+	for k, group := range groups {
+		rows := groupRows[group]
+		summaries[k] = groupSummary{name: group, rows: len(rows)}
+		if len(rows) < 2 || !opts.ComponentsMax && len(rows) < opts.Components {
+			summaries[k].skipped = fmt.Sprintf("%d rows is too few for %d components", len(rows), opts.Components)
+			fmt.Printf("\nSkipping group %s: %s\n", group, summaries[k].skipped)
+			continue
+		}
+
+		fmt.Printf("\nGroup %s = %s (%d rows):\n", opts.PerGroup, group, len(rows))
+
+		// Output files are named after the input file and the group
+		groupFile := filepath.Join(dir, base+"_"+unsafeFileChars.ReplaceAllString(group, "_")+ext)
+		result, err := analyzeData(opts, subsetDataRows(data, rows), groupFile)
+		if err != nil {
+			return fmt.Errorf("group %s: %w", group, err)
+		}
+		summaries[k].result = result
+	}
+
+	outputPerGroupSummary(opts.PerGroup, summaries)
+	return nil
+}
+
+// subsetDataRows returns a copy of data containing only the given rows. Rows without
+// names are named after their position in the full data set.
+func subsetDataRows(data *pkgcsv.Data, rows []int) *pkgcsv.Data {
+	subset := &pkgcsv.Data{
+		Headers:              data.Headers,
+		Columns:              data.Columns,
+		Rows:                 len(rows),
+		Matrix:               make(types.Matrix, len(rows)),
+		RowNames:             data.RowNames,
+		CategoricalColumns:   make(map[string][]string, len(data.CategoricalColumns)),
+		NumericTargetColumns: make(map[string][]float64, len(data.NumericTargetColumns)),
+	}
+	if len(subset.RowNames) == 0 {
+		subset.RowNames = make([]string, data.Rows)
+		for i := range subset.RowNames {
+			subset.RowNames[i] = fmt.Sprintf("Sample_%d", i+1)
+		}
+	}
+	for name, values := range data.CategoricalColumns {
+		subset.CategoricalColumns[name] = values
+	}
+	for name, values := range data.NumericTargetColumns {
+		subset.NumericTargetColumns[name] = values
+	}
+
+	for i, r := range rows {
+		subset.Matrix[i] = append([]float64(nil), data.Matrix[r]...)
+	}
+	if data.MissingMask != nil {
+		subset.MissingMask = make([][]bool, len(rows))
+		for i, r := range rows {
+			subset.MissingMask[i] = append([]bool(nil), data.MissingMask[r]...)
+		}
+	}
+	filterDataRows(subset, rows)
+
+	return subset
+}
+
+// outputPerGroupSummary prints the explained variance of each group's model
+func outputPerGroupSummary(column string, summaries []groupSummary) {
+	nComponents := 0
+	for _, s := range summaries {
+		if s.result != nil && len(s.result.ExplainedVarRatio) > nComponents {
+			nComponents = len(s.result.ExplainedVarRatio)
+		}
+	}
+
+	fmt.Printf("\nPer-Group Summary (%s):\n", column)
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	fmt.Printf("%-15s%8s", "Group", "N")
+	for j := 0; j < nComponents; j++ {
+		fmt.Printf("%10s", fmt.Sprintf("PC%d", j+1))
+	}
+	fmt.Printf("%12s\n", "Cumulative")
+	fmt.Println("──────────────────────────────────────────────────────────────")
+	for _, s := range summaries {
+		fmt.Printf("%-15s%8d", s.name, s.rows)
+		if s.result == nil {
+			fmt.Printf("  skipped: %s\n", s.skipped)
+			continue
+		}
+		for j := 0; j < nComponents; j++ {
+			if j < len(s.result.ExplainedVarRatio) {
+				fmt.Printf("%9.1f%%", s.result.ExplainedVarRatio[j])
+			} else {
+				fmt.Printf("%10s", "")
+			}
+		}
+		fmt.Printf("%11.1f%%\n", s.result.CumulativeVar[len(s.result.CumulativeVar)-1])
+	}
+}
@@ -0,0 +1,631 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineOptions holds all the options for the pipeline command
+type PipelineOptions struct {
+	Vars   []string // Variables as name=value, overriding those of the spec
+	DryRun bool     // Validate the spec and list the steps without running them
+}
+
+// pipelineSpec is a pipeline file: the input data and the steps run on it in order
+type pipelineSpec struct {
+	Vars      map[string]string `yaml:"vars"`
+	Input     string            `yaml:"input"`
+	NoHeaders bool              `yaml:"no-headers"`
+	NoIndex   bool              `yaml:"no-index"`
+	Delimiter string            `yaml:"delimiter"`
+	Steps     []pipelineStep    `yaml:"steps"`
+}
+
+// pipelineStep is one step of a pipeline: the operation and its parameters
+type pipelineStep struct {
+	Op     string
+	Params map[string]any
+}
+
+// UnmarshalYAML reads a step as a mapping of op and the parameters of the operation
+func (s *pipelineStep) UnmarshalYAML(node *yaml.Node) error {
+	if err := node.Decode(&s.Params); err != nil {
+		return err
+	}
+	op, ok := s.Params["op"].(string)
+	if !ok {
+		return fmt.Errorf("line %d: step has no op", node.Line)
+	}
+	s.Op = op
+	delete(s.Params, "op")
+	return nil
+}
+
+// pipelineParams lists the parameters of each pipeline operation. The parameters of
+// analyze are its flags, which are looked up on the command instead.
+var pipelineParams = map[string][]string{
+	"impute":    {"strategy", "neighbors"},
+	"clean":     {},
+	"drop-cols": {"columns"},
+	"scale":     {"method", "columns"},
+	"write":     {"path"},
+	"analyze":   nil,
+}
+
+// NewPipelineCommand creates the pipeline subcommand
+func NewPipelineCommand() *cobra.Command {
+	opts := &PipelineOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "pipeline [flags] <spec.yaml>",
+		Short: "Run a multi-step workflow described in a YAML or JSON file",
+		Long: `Run a workflow of data cleaning, scaling and analysis described in a file.
+
+The spec names the input data and lists steps that are run in order, each on
+the data left by the previous one. JSON specs are read as well, since JSON is
+YAML. Intermediate data is kept in a temporary directory; use a write step to
+keep it. The whole spec is checked before the first step runs: an unknown
+operation or parameter is an error.
+
+Spec:
+  vars:       Variables, used as ${name} in any string of the spec
+  input:      Data file (CSV, TSV, Excel or JSON)
+  no-headers: First row contains data, not column names
+  no-index:   First column contains data, not row names
+  delimiter:  CSV field delimiter, or "tab"
+  steps:      List of steps, each with an op and its parameters
+
+Operations:
+  impute     Fill in missing values (strategy, neighbors), as the impute command
+  clean      Remove empty, constant and duplicate columns, as the clean command
+  drop-cols  Remove columns (columns: list of names)
+  scale      Scale numeric columns (method: standard, robust or center; columns:
+             list of names, default all numeric columns except #target columns)
+  analyze    Run PCA; every other parameter is an analyze flag, such as
+             components: 3 or format: csv. File output needs output-dir
+  write      Write the current data (path, format from the extension)
+
+Output files of analyze are named after the input file. Relative paths are
+relative to the working directory.
+
+EXAMPLES:
+  # Run a pipeline
+  pca pipeline workflow.yaml
+
+  # Run it on another dataset
+  pca pipeline --var name=wine workflow.yaml
+
+  # Check a spec without running it
+  pca pipeline --dry-run workflow.yaml
+
+  # A spec that standardizes and analyzes the data:
+  #   vars:
+  #     name: iris
+  #   input: data/${name}.csv
+  #   steps:
+  #     - op: scale
+  #       method: standard
+  #     - op: analyze
+  #       components: 3
+  #       format: csv
+  #       output-dir: results/${name}`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPipeline(opts, args[0])
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.Vars, "var", nil,
+		"Set a variable as name=value, overriding the spec (repeatable)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false,
+		"Validate the spec and list the steps without running them")
+
+	return cmd
+}
+
+// runPipeline executes the pipeline command
+func runPipeline(opts *PipelineOptions, specFile string) error {
+	spec, err := loadPipelineSpec(specFile, opts.Vars)
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		fmt.Printf("Pipeline %s: %d steps on %s\n", specFile, len(spec.Steps), spec.Input)
+		for i, step := range spec.Steps {
+			fmt.Printf("  %d. %s%s\n", i+1, step.Op, formatPipelineParams(step.Params))
+		}
+		return nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "gopca-pipeline-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The data passed between steps is a CSV file named after the input, so that
+	// analyze names its output files after the input as well
+	base := strings.TrimSuffix(filepath.Base(spec.Input), filepath.Ext(spec.Input))
+	current := filepath.Join(tempDir, "input", base+".csv")
+	readOpts := pkgcsv.DefaultOptions()
+	readOpts.HasHeaders = !spec.NoHeaders
+	readOpts.HasRowNames = !spec.NoIndex
+	readOpts.Delimiter = resolveDelimiter(spec.Delimiter, spec.Input)
+	if err := copyPipelineData(spec.Input, current, readOpts); err != nil {
+		return err
+	}
+
+	// Intermediate files are always comma-separated with the layout of the input
+	stepReadOpts := pkgcsv.DefaultOptions()
+	stepReadOpts.HasHeaders = !spec.NoHeaders
+	stepReadOpts.HasRowNames = !spec.NoIndex
+
+	for i, step := range spec.Steps {
+		fmt.Printf("\n=== Step %d/%d: %s ===\n", i+1, len(spec.Steps), step.Op)
+		next := filepath.Join(tempDir, fmt.Sprintf("step%d", i+1), base+".csv")
+		if err := os.MkdirAll(filepath.Dir(next), 0750); err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+
+		switch step.Op {
+		case "impute":
+			imputeOpts := &ImputeOptions{
+				NoHeaders:       spec.NoHeaders,
+				NoIndex:         spec.NoIndex,
+				NAValues:        strings.Join(pkgcsv.DefaultOptions().NullValues, ","),
+				MissingStrategy: "mean",
+				Neighbors:       core.DefaultImputeNeighbors,
+			}
+			if v, ok := step.Params["strategy"]; ok {
+				imputeOpts.MissingStrategy = fmt.Sprint(v)
+			}
+			if v, ok := step.Params["neighbors"]; ok {
+				n, ok := v.(int)
+				if !ok {
+					return fmt.Errorf("step %d (impute): neighbors must be an integer, got %v", i+1, v)
+				}
+				imputeOpts.Neighbors = n
+			}
+			err = runImpute(imputeOpts, current, next)
+		case "clean":
+			err = runClean(&CleanOptions{
+				NoHeaders: spec.NoHeaders,
+				NoIndex:   spec.NoIndex,
+				NAValues:  strings.Join(pkgcsv.DefaultOptions().NullValues, ","),
+			}, current, next)
+		case "drop-cols":
+			err = pipelineDropColumns(current, next, stepReadOpts, pipelineStrings(step.Params["columns"]))
+		case "scale":
+			method := "standard"
+			if v, ok := step.Params["method"]; ok {
+				method = fmt.Sprint(v)
+			}
+			err = pipelineScale(current, next, stepReadOpts, method, pipelineStrings(step.Params["columns"]))
+		case "write":
+			err = copyPipelineData(current, fmt.Sprint(step.Params["path"]), stepReadOpts)
+			if err == nil {
+				fmt.Printf("Data written to %s\n", step.Params["path"])
+			}
+			next = current
+		case "analyze":
+			analyzeCmd := NewAnalyzeCommand()
+			analyzeCmd.SilenceErrors = true
+			analyzeCmd.SilenceUsage = true
+			args := pipelineAnalyzeArgs(step.Params, spec)
+			analyzeCmd.SetArgs(append(args, current))
+			err = analyzeCmd.Execute()
+			next = current
+		}
+		if err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Op, err)
+		}
+		current = next
+	}
+
+	fmt.Printf("\nPipeline completed: %d steps\n", len(spec.Steps))
+	return nil
+}
+
+// loadPipelineSpec reads a pipeline spec, substitutes its variables and validates
+// its steps. vars holds name=value pairs overriding the variables of the spec.
+func loadPipelineSpec(specFile string, vars []string) (*pipelineSpec, error) {
+	if err := security.ValidateInputPath(specFile); err != nil {
+		return nil, fmt.Errorf("invalid spec path: %w", err)
+	}
+	content, err := os.ReadFile(specFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline spec: %w", err)
+	}
+
+	var spec pipelineSpec
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid pipeline spec %s: %w", specFile, err)
+	}
+
+	if spec.Vars == nil {
+		spec.Vars = make(map[string]string)
+	}
+	for _, v := range vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q: must be name=value", v)
+		}
+		spec.Vars[name] = value
+	}
+
+	// Substitute variables in the input and in every string parameter
+	if spec.Input, err = expandPipelineVars(spec.Input, spec.Vars); err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	for i := range spec.Steps {
+		for name, value := range spec.Steps[i].Params {
+			if spec.Steps[i].Params[name], err = expandPipelineValue(value, spec.Vars); err != nil {
+				return nil, fmt.Errorf("step %d (%s): %s: %w", i+1, spec.Steps[i].Op, name, err)
+			}
+		}
+	}
+
+	if err := validatePipelineSpec(&spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// validatePipelineSpec checks that the spec has an input and steps, and that every
+// step is a known operation with known parameters
+func validatePipelineSpec(spec *pipelineSpec) error {
+	if spec.Input == "" {
+		return fmt.Errorf("pipeline spec has no input")
+	}
+	if len(spec.Steps) == 0 {
+		return fmt.Errorf("pipeline spec has no steps")
+	}
+
+	ops := make([]string, 0, len(pipelineParams))
+	for op := range pipelineParams {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	analyzeFlags := NewAnalyzeCommand().Flags()
+	for i, step := range spec.Steps {
+		known, ok := pipelineParams[step.Op]
+		if !ok {
+			return fmt.Errorf("step %d: unknown operation %q (operations: %s)", i+1, step.Op, strings.Join(ops, ", "))
+		}
+		for name := range step.Params {
+			if step.Op == "analyze" {
+				if analyzeFlags.Lookup(name) == nil {
+					return fmt.Errorf("step %d (analyze): unknown analyze flag %q", i+1, name)
+				}
+				if name == "batch" || name == "per-group" {
+					return fmt.Errorf("step %d (analyze): %s is not available in a pipeline", i+1, name)
+				}
+			} else if !slices.Contains(known, name) {
+				return fmt.Errorf("step %d (%s): unknown parameter %q (parameters: %s)",
+					i+1, step.Op, name, strings.Join(known, ", "))
+			}
+		}
+
+		switch step.Op {
+		case "drop-cols":
+			if len(pipelineStrings(step.Params["columns"])) == 0 {
+				return fmt.Errorf("step %d (drop-cols): columns is required", i+1)
+			}
+		case "scale":
+			if method, ok := step.Params["method"]; ok {
+				switch fmt.Sprint(method) {
+				case "standard", "robust", "center":
+				default:
+					return fmt.Errorf("step %d (scale): invalid method %q: must be standard, robust or center", i+1, method)
+				}
+			}
+		case "write":
+			path, ok := step.Params["path"].(string)
+			if !ok || path == "" {
+				return fmt.Errorf("step %d (write): path is required", i+1)
+			}
+			if _, err := pkgcsv.FormatFromPath(path); err != nil {
+				return fmt.Errorf("step %d (write): %w", i+1, err)
+			}
+		case "analyze":
+			// Without an output directory, analyze would write into the temporary directory
+			format := fmt.Sprint(step.Params["format"])
+			tsv, _ := step.Params["tsv"].(bool)
+			if _, ok := step.Params["output-dir"]; !ok && (format == "json" || format == "csv" || tsv) {
+				return fmt.Errorf("step %d (analyze): file output needs output-dir", i+1)
+			}
+		}
+	}
+
+	if err := security.ValidateInputPath(spec.Input); err != nil {
+		return fmt.Errorf("invalid input path: %w", err)
+	}
+	return nil
+}
+
+// expandPipelineVars replaces ${name} and $name in s with the value of the variable.
+// An undefined variable is an error.
+func expandPipelineVars(s string, vars map[string]string) (string, error) {
+	var missing []string
+	expanded := os.Expand(s, func(name string) string {
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable %q", missing[0])
+	}
+	return expanded, nil
+}
+
+// expandPipelineValue substitutes variables in a string parameter or in the strings
+// of a list parameter
+func expandPipelineValue(value any, vars map[string]string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return expandPipelineVars(v, vars)
+	case []any:
+		expanded := make([]any, len(v))
+		for i, item := range v {
+			var err error
+			if expanded[i], err = expandPipelineValue(item, vars); err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	}
+	return value, nil
+}
+
+// pipelineStrings returns a list parameter, or a comma-separated string parameter,
+// as strings
+func pipelineStrings(value any) []string {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return items
+	case string:
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	case nil:
+		return nil
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+// pipelineAnalyzeArgs converts the parameters of an analyze step to command line
+// flags, in sorted order. The input layout of the spec is passed on unless the step
+// sets it.
+func pipelineAnalyzeArgs(params map[string]any, spec *pipelineSpec) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		switch v := params[name].(type) {
+		case bool:
+			args = append(args, fmt.Sprintf("--%s=%t", name, v))
+		case []any:
+			args = append(args, "--"+name, strings.Join(pipelineStrings(v), ","))
+		default:
+			args = append(args, "--"+name, fmt.Sprint(v))
+		}
+	}
+	if _, ok := params["no-headers"]; !ok && spec.NoHeaders {
+		args = append(args, "--no-headers")
+	}
+	if _, ok := params["no-index"]; !ok && spec.NoIndex {
+		args = append(args, "--no-index")
+	}
+	return args
+}
+
+// formatPipelineParams formats step parameters for the dry run listing
+func formatPipelineParams(params map[string]any) string {
+	if len(params) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%v", name, params[name])
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// copyPipelineData reads a data file as text and writes it in the format of the
+// output file extension
+func copyPipelineData(inputFile, outputFile string, readOpts pkgcsv.Options) error {
+	data, err := readPipelineData(inputFile, readOpts)
+	if err != nil {
+		return err
+	}
+	return writePipelineData(outputFile, data)
+}
+
+// readPipelineData reads a data file as text
+func readPipelineData(inputFile string, readOpts pkgcsv.Options) (*pkgcsv.Data, error) {
+	format, err := pkgcsv.FormatFromPath(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	data, err := pkgcsv.ReadTableFile(inputFile, format, readOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+	return data, nil
+}
+
+// writePipelineData writes text data in the format of the file extension
+func writePipelineData(outputFile string, data *pkgcsv.Data) error {
+	format, err := pkgcsv.FormatFromPath(outputFile)
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	if dir := filepath.Dir(outputFile); dir != "." {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := security.ValidateOutputPath(outputFile); err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	writeOpts := pkgcsv.DefaultOptions()
+	writeOpts.HasHeaders = len(data.Headers) > 0
+	if err := pkgcsv.WriteTableFile(outputFile, format, data, writeOpts); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	return nil
+}
+
+// pipelineColumnIndices returns the indices of the named columns, or an error naming
+// the first column that does not exist
+func pipelineColumnIndices(data *pkgcsv.Data, columns []string) ([]int, error) {
+	indices := make([]int, len(columns))
+	for i, name := range columns {
+		indices[i] = slices.Index(data.Headers, name)
+		if indices[i] < 0 {
+			return nil, fmt.Errorf("column %q not found", name)
+		}
+	}
+	return indices, nil
+}
+
+// pipelineDropColumns removes the named columns from a data file
+func pipelineDropColumns(inputFile, outputFile string, readOpts pkgcsv.Options, columns []string) error {
+	data, err := readPipelineData(inputFile, readOpts)
+	if err != nil {
+		return err
+	}
+	drop, err := pipelineColumnIndices(data, columns)
+	if err != nil {
+		return err
+	}
+	if len(drop) >= len(data.Headers) {
+		return fmt.Errorf("cannot drop every column")
+	}
+
+	var headers []string
+	for j, header := range data.Headers {
+		if !slices.Contains(drop, j) {
+			headers = append(headers, header)
+		}
+	}
+	for i, record := range data.StringData {
+		kept := make([]string, 0, len(headers))
+		for j, field := range record {
+			if !slices.Contains(drop, j) {
+				kept = append(kept, field)
+			}
+		}
+		data.StringData[i] = kept
+	}
+	data.Headers = headers
+	data.Columns = len(headers)
+
+	fmt.Printf("Dropped %d columns: %s\n", len(columns), strings.Join(columns, ", "))
+	return writePipelineData(outputFile, data)
+}
+
+// pipelineScale scales the named numeric columns of a data file, or all numeric
+// columns except #target columns, with the preprocessing of analyze --scale
+func pipelineScale(inputFile, outputFile string, readOpts pkgcsv.Options, method string, columns []string) error {
+	data, err := readPipelineData(inputFile, readOpts)
+	if err != nil {
+		return err
+	}
+
+	naValues := make(map[string]bool)
+	for _, v := range readOpts.NullValues {
+		naValues[v] = true
+	}
+	numeric := imputeMatrix(data, naValues)
+
+	// Select the numeric columns to scale
+	var selected []int
+	if len(columns) > 0 {
+		indices, err := pipelineColumnIndices(data, columns)
+		if err != nil {
+			return err
+		}
+		for i, col := range indices {
+			k := slices.Index(numeric.sourceColumns, col)
+			if k < 0 {
+				return fmt.Errorf("column %q is not numeric", columns[i])
+			}
+			selected = append(selected, k)
+		}
+	} else {
+		for k, header := range numeric.Headers {
+			if !strings.HasSuffix(strings.ToLower(header), "#target") {
+				selected = append(selected, k)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no numeric columns to scale")
+	}
+
+	matrix := make(types.Matrix, len(numeric.Matrix))
+	for i, row := range numeric.Matrix {
+		matrix[i] = make([]float64, len(selected))
+		for c, k := range selected {
+			matrix[i][c] = row[k]
+		}
+	}
+	if info := (&pkgcsv.Data{Matrix: matrix}).GetMissingValueInfo(nil); info.HasMissing() {
+		return fmt.Errorf("scaling needs complete data: impute missing values first")
+	}
+
+	preprocessor := core.NewPreprocessor(true, method == "standard", method == "robust")
+	scaled, err := preprocessor.FitTransform(matrix)
+	if err != nil {
+		return fmt.Errorf("failed to scale data: %w", err)
+	}
+	for i, row := range scaled {
+		for c, k := range selected {
+			data.StringData[i][numeric.sourceColumns[k]] = formatCSVFloat(row[c])
+		}
+	}
+
+	fmt.Printf("Scaled %d numeric columns (%s)\n", len(selected), method)
+	return writePipelineData(outputFile, data)
+}
@@ -22,6 +22,8 @@ var (
 
 // NewRootCommand creates the root cobra command
 func NewRootCommand() *cobra.Command {
+	var noColor bool
+
 	rootCmd := &cobra.Command{
 		Use:   "pca",
 		Short: "GoPCA - Principal Component Analysis CLI",
@@ -39,12 +41,30 @@ Features:
   • Integration with data pipelines`,
 		SilenceErrors: true,
 		SilenceUsage:  true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			useColor = colorEnabled(noColor)
+		},
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false,
+		"Disable colored output (also disabled by NO_COLOR and when output is not a terminal)")
+
 	// Add subcommands
 	rootCmd.AddCommand(
 		NewAnalyzeCommand(),
 		NewTransformCommand(),
+		NewDiffCommand(),
+		NewMergeModelsCommand(),
+		NewEmbedCommand(),
+		NewInfoCommand(),
+		NewNormalityCommand(),
+		NewComparePreprocessingCommand(),
+		NewConvertCommand(),
+		NewImputeCommand(),
+		NewCleanCommand(),
+		NewSplitCommand(),
+		NewPipelineCommand(),
+		NewKernelMatrixCommand(),
 		NewValidateCommand(),
 		NewVersionCommand(),
 		NewCompletionCommand(rootCmd),
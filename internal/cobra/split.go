@@ -0,0 +1,199 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package cobra
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/core"
+	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/spf13/cobra"
+)
+
+// SplitOptions holds all the options for the split command
+type SplitOptions struct {
+	// Input format options
+	NoHeaders bool
+	Delimiter string
+
+	// Split options
+	TestFrac  float64
+	Stratify  string
+	Seed      int64
+	OutPrefix string
+}
+
+// NewSplitCommand creates the split subcommand
+func NewSplitCommand() *cobra.Command {
+	opts := &SplitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "split [flags] <input>",
+		Short: "Split a data file into train and test files",
+		Long: `Split the rows of a data file at random into a train and a test file.
+
+The files <prefix>_train and <prefix>_test are written in the format of the
+input, with its headers and every column, row names included, unchanged and
+rows in their original order. With --stratify, every category of the given
+column is split separately so that the category proportions of both files match
+those of the input. The split is the same for the same --seed.
+
+EXAMPLES:
+  # Hold out 20% of the rows, writing data_train.csv and data_test.csv
+  pca split data.csv
+
+  # Stratified 30% test set with the same species proportions
+  pca split --test-frac 0.3 --stratify species --seed 42 --out-prefix iris iris.csv
+
+  # Fit on the train set and project the test set
+  pca analyze -f json data_train.csv && pca transform data_train_pca.json data_test.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSplit(opts, args[0])
+		},
+	}
+
+	// Input format options
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"First row contains data, not column names")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
+
+	// Split options
+	cmd.Flags().Float64Var(&opts.TestFrac, "test-frac", 0.2,
+		"Fraction of the rows in the test file, between 0 and 1")
+	cmd.Flags().StringVar(&opts.Stratify, "stratify", "",
+		"Column whose category proportions are kept in both files")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 1,
+		"Seed of the random split")
+	cmd.Flags().StringVar(&opts.OutPrefix, "out-prefix", "",
+		"Prefix of the output files (default: the input path without its extension)")
+
+	return cmd
+}
+
+// runSplit executes the split command
+func runSplit(opts *SplitOptions, inputFile string) error {
+	if !(opts.TestFrac > 0 && opts.TestFrac < 1) {
+		return fmt.Errorf("--test-frac must be between 0 and 1, got %g", opts.TestFrac)
+	}
+	if opts.Stratify != "" && opts.NoHeaders {
+		return fmt.Errorf("--stratify needs column names and cannot be combined with --no-headers")
+	}
+	format, err := pkgcsv.FormatFromPath(inputFile)
+	if err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+
+	prefix := opts.OutPrefix
+	if prefix == "" {
+		prefix = strings.TrimSuffix(inputFile, filepath.Ext(inputFile))
+	}
+	ext := filepath.Ext(inputFile)
+	trainFile, testFile := prefix+"_train"+ext, prefix+"_test"+ext
+	for _, file := range []string{trainFile, testFile} {
+		if err := security.ValidateOutputPath(file); err != nil {
+			return fmt.Errorf("invalid output path: %w", err)
+		}
+	}
+
+	// Read input as text, with any row names as an ordinary column, so that every
+	// column and header is written back as it is
+	readOpts := pkgcsv.DefaultOptions()
+	readOpts.HasHeaders = !opts.NoHeaders
+	readOpts.HasRowNames = false
+	readOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
+
+	data, err := pkgcsv.ReadTableFile(inputFile, format, readOpts)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+
+	var groups []string
+	if opts.Stratify != "" {
+		col := slices.Index(data.Headers, opts.Stratify)
+		if col < 0 {
+			return fmt.Errorf("stratify column %q not found (columns: %s)", opts.Stratify, strings.Join(data.Headers, ", "))
+		}
+		groups = make([]string, len(data.StringData))
+		for i, record := range data.StringData {
+			groups[i] = strings.TrimSpace(record[col])
+		}
+	}
+
+	train, test, err := core.TrainTestSplit(len(data.StringData), opts.TestFrac, groups, opts.Seed)
+	if err != nil {
+		return err
+	}
+
+	writeOpts := pkgcsv.DefaultOptions()
+	writeOpts.HasHeaders = len(data.Headers) > 0
+	writeOpts.Delimiter = readOpts.Delimiter
+	for _, part := range []struct {
+		file string
+		rows []int
+	}{{trainFile, train}, {testFile, test}} {
+		if err := pkgcsv.WriteTableFile(part.file, format, selectTableRows(data, part.rows), writeOpts); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.file, err)
+		}
+	}
+
+	fmt.Printf("Split %d rows into %d train and %d test rows\n", len(data.StringData), len(train), len(test))
+	if groups != nil {
+		outputSplitStrata(groups, test)
+	}
+	fmt.Printf("\nResults saved to: %s, %s\n", trainFile, testFile)
+	return nil
+}
+
+// selectTableRows returns a copy of text data holding only the given rows
+func selectTableRows(data *pkgcsv.Data, rows []int) *pkgcsv.Data {
+	selected := *data
+	selected.StringData = make([][]string, len(rows))
+	for i, r := range rows {
+		selected.StringData[i] = data.StringData[r]
+	}
+	selected.Rows = len(rows)
+	return &selected
+}
+
+// outputSplitStrata prints the rows of each category in the test set, warning about
+// categories too small to appear in it
+func outputSplitStrata(groups []string, test []int) {
+	total := make(map[string]int)
+	inTest := make(map[string]int)
+	for _, g := range groups {
+		total[g]++
+	}
+	for _, r := range test {
+		inTest[groups[r]]++
+	}
+	categories := make([]string, 0, len(total))
+	for g := range total {
+		categories = append(categories, g)
+	}
+	sort.Strings(categories)
+
+	fmt.Printf("%-20s%10s%10s\n", "Category", "Rows", "Test")
+	for _, g := range categories {
+		name := g
+		if name == "" {
+			name = "(empty)"
+		}
+		fmt.Printf("%-20s%10d%10d\n", name, total[g], inTest[g])
+	}
+	for _, g := range categories {
+		if inTest[g] == 0 {
+			fmt.Printf("Warning: category %q has too few rows (%d) to appear in the test set\n", g, total[g])
+		}
+	}
+}
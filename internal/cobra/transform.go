@@ -11,12 +11,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/bitjungle/gopca/internal/core"
 	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
 	"github.com/bitjungle/gopca/pkg/types"
-	"github.com/bitjungle/gopca/pkg/validation"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +31,9 @@ type TransformOptions struct {
 	NoIndex   bool
 	Delimiter string
 	NAValues  string
+
+	// Fail if the model cannot be validated against the schema
+	RequireSchema bool
 }
 
 // NewTransformCommand creates the transform subcommand
@@ -72,10 +75,12 @@ EXAMPLES:
 		"First row contains data, not column names")
 	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
 		"First column contains data, not row names")
-	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", ",",
-		"CSV field delimiter")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
 	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
 		"Comma-separated list of strings representing missing values")
+	cmd.Flags().BoolVar(&opts.RequireSchema, "require-schema", false,
+		"Fail if the model cannot be validated against the model schema, instead of skipping schema validation with a warning")
 
 	return cmd
 }
@@ -83,32 +88,16 @@ EXAMPLES:
 // runTransform executes the transform command
 func runTransform(opts *TransformOptions, modelFile, inputFile string) error {
 	// Load the PCA model
-	modelData, err := os.ReadFile(modelFile)
-	if err != nil {
-		return fmt.Errorf("failed to read model file: %w", err)
-	}
-
-	// Validate model against schema
-	validator, err := validation.NewModelValidator("v1")
+	model, config, preprocessor, err := core.LoadModel(modelFile, opts.RequireSchema)
 	if err != nil {
-		// Schema validation not available, continue without validation
-		fmt.Fprintf(os.Stderr, "Warning: Schema validation not available: %v\n", err)
-	} else {
-		if err := validator.ValidateModel(modelData); err != nil {
-			return fmt.Errorf("model validation failed: %w", err)
-		}
-	}
-
-	var pcaOutputData types.PCAOutputData
-	if err := json.Unmarshal(modelData, &pcaOutputData); err != nil {
-		return fmt.Errorf("failed to parse model JSON: %w", err)
+		return err
 	}
 
 	// Parse CSV options
 	parseOpts := pkgcsv.DefaultOptions()
 	parseOpts.HasHeaders = !opts.NoHeaders
 	parseOpts.HasRowNames = !opts.NoIndex
-	parseOpts.Delimiter = rune(opts.Delimiter[0])
+	parseOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
 	// Use ParseMixedWithTargets to properly identify and exclude target columns
 	parseOpts.ParseMode = pkgcsv.ParseMixedWithTargets
 
@@ -127,6 +116,17 @@ func runTransform(opts *TransformOptions, modelFile, inputFile string) error {
 		return fmt.Errorf("failed to parse CSV: %w", err)
 	}
 
+	// Columns dropped at fit time for missing values may be just as sparse here
+	if len(config.DroppedColumns) > 0 {
+		var drop []int
+		for j, header := range data.Headers {
+			if slices.Contains(config.DroppedColumns, header) {
+				drop = append(drop, j)
+			}
+		}
+		removeDataColumns(data, drop)
+	}
+
 	// Validate data
 	if err := validateCSVData(data); err != nil {
 		return fmt.Errorf("data validation failed: %w", err)
@@ -134,7 +134,7 @@ func runTransform(opts *TransformOptions, modelFile, inputFile string) error {
 
 	// Extract feature columns that match the model's feature labels
 	// This handles cases where target columns are present in the data
-	modelFeatures := pcaOutputData.Model.FeatureLabels
+	modelFeatures := model.VariableLabels
 
 	// Create a map for quick lookup of model feature indices
 	modelFeatureMap := make(map[string]int)
@@ -179,45 +179,24 @@ func runTransform(opts *TransformOptions, modelFile, inputFile string) error {
 	data.Columns = len(modelFeatures)
 	data.Headers = modelFeatures
 
-	// Create preprocessor from saved parameters
-	preprocessor := core.NewPreprocessorWithScaleOnly(
-		pcaOutputData.Preprocessing.MeanCenter,
-		pcaOutputData.Preprocessing.StandardScale,
-		pcaOutputData.Preprocessing.RobustScale,
-		pcaOutputData.Preprocessing.ScaleOnly,
-		pcaOutputData.Preprocessing.SNV,
-		pcaOutputData.Preprocessing.VectorNorm,
-	)
-
-	// Restore preprocessing parameters
-	if err := preprocessor.SetFittedParameters(
-		pcaOutputData.Preprocessing.Parameters.FeatureMeans,
-		pcaOutputData.Preprocessing.Parameters.FeatureStdDevs,
-		pcaOutputData.Preprocessing.Parameters.FeatureMedians,
-		pcaOutputData.Preprocessing.Parameters.FeatureMADs,
-		pcaOutputData.Preprocessing.Parameters.RowMeans,
-		pcaOutputData.Preprocessing.Parameters.RowStdDevs,
-	); err != nil {
-		return fmt.Errorf("failed to restore preprocessing parameters: %w", err)
+	// Project data using the loaded model
+	engine, err := core.NewPCAEngineFromModel(model, preprocessor)
+	if err != nil {
+		return fmt.Errorf("failed to restore model: %w", err)
 	}
-
-	// Apply preprocessing
-	processedData, err := preprocessor.Transform(data.Matrix)
+	scores, err := engine.Transform(data.Matrix)
 	if err != nil {
-		return fmt.Errorf("preprocessing failed: %w", err)
+		return fmt.Errorf("projection failed: %w", err)
 	}
 
-	// Project data using loadings
-	scores := ProjectData(processedData, pcaOutputData.Model.Loadings)
-
 	// Create result structure
 	result := &types.PCAResult{
 		Scores:          scores,
-		Loadings:        pcaOutputData.Model.Loadings,
-		ExplainedVar:    pcaOutputData.Model.ExplainedVariance,
-		CumulativeVar:   pcaOutputData.Model.CumulativeVariance,
-		ComponentLabels: pcaOutputData.Model.ComponentLabels,
-		Method:          pcaOutputData.Metadata.Config.Method,
+		Loadings:        model.Loadings,
+		ExplainedVar:    model.ExplainedVar,
+		CumulativeVar:   model.CumulativeVar,
+		ComponentLabels: model.ComponentLabels,
+		Method:          model.Method,
 	}
 
 	// Output results based on format
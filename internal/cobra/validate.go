@@ -8,10 +8,11 @@ package cobra
 
 import (
 	"fmt"
-	"math"
 	"strings"
 
+	"github.com/bitjungle/gopca/internal/core"
 	pkgcsv "github.com/bitjungle/gopca/pkg/csv"
+	"github.com/bitjungle/gopca/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -55,7 +56,7 @@ The validation includes:
   • File format and structure
   • Missing values detection
   • Data type consistency
-  • Numerical range checks
+  • Numerical range checks, including infinite values
   • Low variance detection
   • High missing value warnings`,
 		Args: cobra.ExactArgs(1),
@@ -69,8 +70,8 @@ The validation includes:
 		"First row contains data, not column names")
 	cmd.Flags().BoolVar(&opts.NoIndex, "no-index", false,
 		"First column contains data, not row names")
-	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", ",",
-		"CSV field delimiter")
+	cmd.Flags().StringVar(&opts.Delimiter, "delimiter", "",
+		"CSV field delimiter, or \"tab\" (default: tab for .tsv and .tab files, comma otherwise)")
 	cmd.Flags().StringVar(&opts.NAValues, "na-values", ",NA,N/A,nan,NaN,null,NULL,m",
 		"Comma-separated list of strings representing missing values")
 
@@ -89,7 +90,7 @@ func runValidate(opts *ValidateOptions, inputFile string) error {
 	parseOpts := pkgcsv.DefaultOptions()
 	parseOpts.HasHeaders = !opts.NoHeaders
 	parseOpts.HasRowNames = !opts.NoIndex
-	parseOpts.Delimiter = rune(opts.Delimiter[0])
+	parseOpts.Delimiter = resolveDelimiter(opts.Delimiter, inputFile)
 	parseOpts.ParseMode = pkgcsv.ParseMixedWithTargets
 
 	// Parse NA values
@@ -109,69 +110,18 @@ func runValidate(opts *ValidateOptions, inputFile string) error {
 		return fmt.Errorf("failed to parse CSV: %w", err)
 	}
 
-	// Basic validation
-	if err := validateCSVData(data); err != nil {
+	report := data.Validate(pkgcsv.DefaultValidationOptions())
+	if err := report.Err(); err != nil {
 		return fmt.Errorf("data validation failed: %w", err)
 	}
-
-	// Perform additional validation checks
-	warnings := []string{}
-
-	// Check for low variance columns
-	for j := 0; j < data.Columns; j++ {
-		var values []float64
-		for i := 0; i < data.Rows; i++ {
-			if !math.IsNaN(data.Matrix[i][j]) {
-				values = append(values, data.Matrix[i][j])
-			}
-		}
-
-		if len(values) > 1 {
-			// Calculate variance
-			mean := 0.0
-			for _, v := range values {
-				mean += v
-			}
-			mean /= float64(len(values))
-
-			variance := 0.0
-			for _, v := range values {
-				variance += (v - mean) * (v - mean)
-			}
-			variance /= float64(len(values) - 1)
-
-			if variance < 1e-10 {
-				colName := fmt.Sprintf("column %d", j+1)
-				if j < len(data.Headers) {
-					colName = data.Headers[j]
-				}
-				warnings = append(warnings, fmt.Sprintf("%s has near-zero variance", colName))
-			}
-		}
-	}
-
-	// Check for high missing value percentage per column
-	for j := 0; j < data.Columns; j++ {
-		missingCount := 0
-		for i := 0; i < data.Rows; i++ {
-			if math.IsNaN(data.Matrix[i][j]) {
-				missingCount++
-			}
-		}
-
-		missingPercent := float64(missingCount) / float64(data.Rows) * 100
-		if missingPercent > 50 {
-			colName := fmt.Sprintf("column %d", j+1)
-			if j < len(data.Headers) {
-				colName = data.Headers[j]
-			}
-			warnings = append(warnings, fmt.Sprintf("%s has %.1f%% missing values", colName, missingPercent))
-		}
+	if err := core.ValidateNaNValues(data.Matrix, true); err != nil {
+		return fmt.Errorf("data validation failed: %w", err)
 	}
+	warnings := report.Messages(types.SeverityWarning)
 
 	// Display results
-	fmt.Println("\n✓ Data format validation passed")
-	fmt.Printf("  - Dimensions: %d rows × %d columns\n", data.Rows, data.Columns)
+	fmt.Println("\n" + colorize(ansiGreen, "✓ Data format validation passed"))
+	fmt.Printf("  - Dimensions: %d rows × %d columns\n", report.Rows, report.NumericColumns)
 
 	// Show categorical columns if any
 	if len(data.CategoricalColumns) > 0 {
@@ -202,19 +152,19 @@ func runValidate(opts *ValidateOptions, inputFile string) error {
 	}
 
 	if len(warnings) > 0 {
-		fmt.Println("\n⚠ Warnings:")
+		fmt.Println("\n" + colorize(ansiYellow, "⚠ Warnings:"))
 		for _, w := range warnings {
-			fmt.Printf("  - %s\n", w)
+			fmt.Printf("  - %s\n", colorize(ansiYellow, w))
 		}
 
 		if opts.Strict {
 			return fmt.Errorf("validation failed with %d warnings in strict mode", len(warnings))
 		}
 	} else {
-		fmt.Println("\n✓ No warnings found")
+		fmt.Println("\n" + colorize(ansiGreen, "✓ No warnings found"))
 	}
 
-	fmt.Println("\n✓ Data is ready for PCA analysis")
+	fmt.Println("\n" + colorize(ansiGreen, "✓ Data is ready for PCA analysis"))
 
 	return nil
 }
@@ -0,0 +1,340 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Parallel analysis settings used by AdviseComponents
+const (
+	// ParallelAnalysisIterations is the number of random datasets generated
+	ParallelAnalysisIterations = 50
+	// ParallelAnalysisPercentile is the quantile of the random eigenvalues to exceed
+	ParallelAnalysisPercentile = 0.95
+	// parallelAnalysisSeed makes the random datasets, and so the advice, reproducible
+	parallelAnalysisSeed = 1
+)
+
+// Criteria of ComponentStatus
+const (
+	// StatusCriterionParallel keeps the components above the parallel analysis baseline
+	StatusCriterionParallel = "parallel"
+	// StatusCriterionBrokenStick keeps the components above the broken-stick expectation
+	StatusCriterionBrokenStick = "broken-stick"
+	// StatusCriterionKaiser keeps the components with an eigenvalue above the mean
+	StatusCriterionKaiser = "kaiser"
+)
+
+// Labels of ComponentStatus
+const (
+	ComponentSignal = "signal"
+	ComponentNoise  = "noise"
+)
+
+// AdviceVarianceThresholds are the cumulative variance percentages reported by AdviseComponents
+var AdviceVarianceThresholds = []float64{80, 90, 95}
+
+// ComponentRecommendation is the number of components suggested by one selection heuristic
+type ComponentRecommendation struct {
+	Method     string
+	Components int
+	Criterion  string
+}
+
+// AdviseComponents applies the Kaiser, broken-stick, parallel analysis, elbow and
+// cumulative variance heuristics to the preprocessed data X and returns their
+// recommendations side by side. X must not contain NaN.
+func AdviseComponents(X types.Matrix) ([]ComponentRecommendation, error) {
+	eigenvalues, err := CovarianceEigenvalues(X)
+	if err != nil {
+		return nil, err
+	}
+	parallel, err := ParallelAnalysisComponents(X, ParallelAnalysisIterations, parallelAnalysisSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	advice := []ComponentRecommendation{
+		{"Kaiser", KaiserComponents(eigenvalues), "eigenvalue above the mean eigenvalue (1 for standardized data)"},
+		{"Broken stick", BrokenStickComponents(eigenvalues), "variance share above the broken-stick expectation"},
+		{"Parallel analysis", parallel, fmt.Sprintf("eigenvalue above the %.0fth percentile of %d random datasets",
+			ParallelAnalysisPercentile*100, ParallelAnalysisIterations)},
+		{"Elbow", DetectElbow(CumulativeVariance(eigenvalues)),
+			"cumulative variance curve farthest from the line through its ends (Kneedle)"},
+	}
+	for _, threshold := range AdviceVarianceThresholds {
+		advice = append(advice, ComponentRecommendation{
+			Method:     fmt.Sprintf("Cumulative variance %g%%", threshold),
+			Components: VarianceComponents(eigenvalues, threshold),
+			Criterion:  fmt.Sprintf("fewest components explaining at least %g%% of the variance", threshold),
+		})
+	}
+	return advice, nil
+}
+
+// CovarianceEigenvalues returns the eigenvalues of the sample covariance matrix of X
+// in descending order, with small negative values from rounding set to zero
+func CovarianceEigenvalues(X types.Matrix) ([]float64, error) {
+	if len(X) < 2 || len(X[0]) == 0 {
+		return nil, fmt.Errorf("need at least 2 rows and 1 column, got %d rows", len(X))
+	}
+	n, p := len(X), len(X[0])
+	dense := mat.NewDense(n, p, nil)
+	for i, row := range X {
+		for j, v := range row {
+			if math.IsNaN(v) {
+				return nil, fmt.Errorf("data contains missing values")
+			}
+			dense.Set(i, j, v)
+		}
+	}
+
+	var cov mat.SymDense
+	stat.CovarianceMatrix(&cov, dense, nil)
+	var eig mat.EigenSym
+	if !eig.Factorize(&cov, false) {
+		return nil, fmt.Errorf("eigendecomposition of the covariance matrix failed")
+	}
+
+	values := eig.Values(nil)
+	sort.Sort(sort.Reverse(sort.Float64Slice(values)))
+	for i, v := range values {
+		values[i] = math.Max(v, 0)
+	}
+	return values, nil
+}
+
+// KaiserComponents returns the number of eigenvalues above their mean, the
+// Kaiser-Guttman criterion. For standardized data the mean eigenvalue is 1.
+func KaiserComponents(eigenvalues []float64) int {
+	if len(eigenvalues) == 0 {
+		return 0
+	}
+	mean := stat.Mean(eigenvalues, nil)
+	count := 0
+	for _, v := range eigenvalues {
+		if v > mean {
+			count++
+		}
+	}
+	return count
+}
+
+// BrokenStickComponents returns the number of leading components whose share of the
+// total variance exceeds the broken-stick expectation (1/p)·Σ_{i=k}^{p} 1/i, the
+// share of the k-th largest piece of a stick broken at random into p pieces.
+//
+// Reference: Jackson, D.A. (1993). Stopping rules in principal components analysis:
+// a comparison of heuristical and statistical approaches. Ecology, 74(8), 2204-2214.
+func BrokenStickComponents(eigenvalues []float64) int {
+	p := len(eigenvalues)
+	total := floats.Sum(eigenvalues)
+	if p == 0 || total <= 0 {
+		return 0
+	}
+
+	// expected[k] = (1/p) Σ_{i=k+1}^{p} 1/i, accumulated from the smallest piece
+	expected := make([]float64, p)
+	sum := 0.0
+	for k := p - 1; k >= 0; k-- {
+		sum += 1 / float64(k+1)
+		expected[k] = sum / float64(p)
+	}
+
+	count := 0
+	for k, v := range eigenvalues {
+		if v/total <= expected[k] {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// VarianceComponents returns the fewest components whose cumulative share of the
+// total variance reaches threshold, given as a percentage
+func VarianceComponents(eigenvalues []float64, threshold float64) int {
+	total := floats.Sum(eigenvalues)
+	if total <= 0 {
+		return 0
+	}
+	cumulative := 0.0
+	for k, v := range eigenvalues {
+		cumulative += v / total * 100
+		// Allow for rounding when the threshold is reached exactly
+		if cumulative >= threshold-1e-9 {
+			return k + 1
+		}
+	}
+	return len(eigenvalues)
+}
+
+// CumulativeVariance returns the cumulative share of the total variance of the
+// eigenvalues, as percentages
+func CumulativeVariance(eigenvalues []float64) []float64 {
+	total := floats.Sum(eigenvalues)
+	cumulative := make([]float64, len(eigenvalues))
+	sum := 0.0
+	for k, v := range eigenvalues {
+		sum += v
+		if total > 0 {
+			cumulative[k] = sum / total * 100
+		}
+	}
+	return cumulative
+}
+
+// ExplainedVarianceCurve returns the explained and cumulative variance of all
+// eigenvalues, in descending order, with the elbow of the cumulative curve
+func ExplainedVarianceCurve(eigenvalues []float64) *types.VarianceCurve {
+	total := floats.Sum(eigenvalues)
+	ratios := make([]float64, len(eigenvalues))
+	if total > 0 {
+		for k, v := range eigenvalues {
+			ratios[k] = v / total * 100
+		}
+	}
+	cumulative := CumulativeVariance(eigenvalues)
+	return &types.VarianceCurve{
+		ExplainedVarianceRatio: ratios,
+		CumulativeVariance:     cumulative,
+		Elbow:                  DetectElbow(cumulative),
+	}
+}
+
+// DetectElbow returns the number of components at the elbow of a cumulative
+// variance curve, by the Kneedle algorithm: with the component numbers and the
+// curve both scaled to [0, 1], the elbow is the point farthest from the line
+// connecting the first and last points. The earliest point is taken among equally
+// distant ones. Curves with fewer than three points have no interior point, and
+// their length is returned.
+//
+// Reference: Satopää, V., Albrecht, J., Irwin, D. & Raghavan, B. (2011). Finding a
+// "kneedle" in a haystack: detecting knee points in system behavior. ICDCS
+// Workshops, 166-171.
+func DetectElbow(cumulativeVar []float64) int {
+	n := len(cumulativeVar)
+	if n < 3 {
+		return n
+	}
+	first, last := cumulativeVar[0], cumulativeVar[n-1]
+	if last == first {
+		return 1
+	}
+
+	// In scaled coordinates the line through the ends is y = x, and the distance of
+	// a point from it is |y − x|/√2
+	elbow, best := 1, 0.0
+	for k := 1; k < n-1; k++ {
+		x := float64(k) / float64(n-1)
+		y := (cumulativeVar[k] - first) / (last - first)
+		if d := math.Abs(y-x) / math.Sqrt2; d > best+1e-12 {
+			elbow, best = k+1, d
+		}
+	}
+	return elbow
+}
+
+// ParallelAnalysisComponents returns the number of leading covariance eigenvalues of X
+// that exceed the ParallelAnalysisPercentile quantile of the eigenvalues of random
+// normal data with the same size and column variances. The seed makes the result
+// reproducible.
+//
+// Reference: Horn, J.L. (1965). A rationale and test for the number of factors in
+// factor analysis. Psychometrika, 30(2), 179-185.
+func ParallelAnalysisComponents(X types.Matrix, iterations int, seed int64) (int, error) {
+	if iterations < 1 {
+		return 0, fmt.Errorf("parallel analysis needs at least 1 iteration, got %d", iterations)
+	}
+	eigenvalues, err := CovarianceEigenvalues(X)
+	if err != nil {
+		return 0, err
+	}
+
+	n, p := len(X), len(X[0])
+	stdDevs := make([]float64, p)
+	for j := range stdDevs {
+		stdDevs[j] = math.Sqrt(stat.Variance(columnValues(X, j), nil))
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	random := make([][]float64, p) // random[k] holds the k-th eigenvalue of each dataset
+	sample := make(types.Matrix, n)
+	for i := range sample {
+		sample[i] = make([]float64, p)
+	}
+	for it := 0; it < iterations; it++ {
+		for i := range sample {
+			for j := range sample[i] {
+				sample[i][j] = rng.NormFloat64() * stdDevs[j]
+			}
+		}
+		values, err := CovarianceEigenvalues(sample)
+		if err != nil {
+			return 0, err
+		}
+		for k, v := range values {
+			random[k] = append(random[k], v)
+		}
+	}
+
+	count := 0
+	for k, v := range eigenvalues {
+		sort.Float64s(random[k])
+		if v <= stat.Quantile(ParallelAnalysisPercentile, stat.Empirical, random[k], nil) {
+			break
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ComponentStatus labels each of the first n components of the preprocessed data X
+// ComponentSignal if it is among the leading components the criterion retains, and
+// ComponentNoise otherwise, so that the interpretable components stand out. The
+// criterion is one of the StatusCriterion constants. X must not contain NaN.
+func ComponentStatus(X types.Matrix, n int, criterion string) ([]string, error) {
+	var signal int
+	switch criterion {
+	case StatusCriterionParallel:
+		var err error
+		signal, err = ParallelAnalysisComponents(X, ParallelAnalysisIterations, parallelAnalysisSeed)
+		if err != nil {
+			return nil, err
+		}
+	case StatusCriterionBrokenStick, StatusCriterionKaiser:
+		eigenvalues, err := CovarianceEigenvalues(X)
+		if err != nil {
+			return nil, err
+		}
+		if criterion == StatusCriterionKaiser {
+			signal = KaiserComponents(eigenvalues)
+		} else {
+			signal = BrokenStickComponents(eigenvalues)
+		}
+	default:
+		return nil, fmt.Errorf("invalid component status criterion %q: must be parallel, broken-stick or kaiser", criterion)
+	}
+
+	status := make([]string, n)
+	for k := range status {
+		status[k] = ComponentNoise
+		if k < signal {
+			status[k] = ComponentSignal
+		}
+	}
+	return status, nil
+}
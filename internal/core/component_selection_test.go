@@ -0,0 +1,141 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestComponentSelectionHeuristics(t *testing.T) {
+	// Shares of 60%, 20%, 10% and 10%
+	eigenvalues := []float64{3, 1, 0.5, 0.5}
+
+	if got := KaiserComponents(eigenvalues); got != 1 {
+		t.Errorf("Kaiser: expected 1, got %d", got)
+	}
+	// The broken-stick expectations are 52.1%, 27.1%, 14.6% and 6.3%
+	if got := BrokenStickComponents(eigenvalues); got != 1 {
+		t.Errorf("broken stick: expected 1, got %d", got)
+	}
+	for threshold, want := range map[float64]int{50: 1, 80: 2, 90: 3, 95: 4} {
+		if got := VarianceComponents(eigenvalues, threshold); got != want {
+			t.Errorf("cumulative variance %g%%: expected %d, got %d", threshold, want, got)
+		}
+	}
+	if got := KaiserComponents(nil); got != 0 {
+		t.Errorf("expected 0 components for no eigenvalues, got %d", got)
+	}
+}
+
+func TestDetectElbow(t *testing.T) {
+	// Three strong components followed by a flat tail of noise
+	eigenvalues := []float64{40, 30, 20, 2, 2, 2, 2, 2}
+	cumulative := CumulativeVariance(eigenvalues)
+	if !slices.Equal(cumulative, []float64{40, 70, 90, 92, 94, 96, 98, 100}) {
+		t.Fatalf("unexpected cumulative variance %v", cumulative)
+	}
+	if got := DetectElbow(cumulative); got != 3 {
+		t.Errorf("expected the elbow at component 3, got %d", got)
+	}
+
+	// Fractions give the same elbow as percentages
+	fractions := make([]float64, len(cumulative))
+	for k, v := range cumulative {
+		fractions[k] = v / 100
+	}
+	if got := DetectElbow(fractions); got != 3 {
+		t.Errorf("expected the elbow at component 3 for fractions, got %d", got)
+	}
+
+	curve := ExplainedVarianceCurve(eigenvalues)
+	if curve.Elbow != 3 || len(curve.ExplainedVarianceRatio) != 8 || curve.ExplainedVarianceRatio[1] != 30 {
+		t.Errorf("unexpected curve %+v", curve)
+	}
+
+	for _, tt := range []struct {
+		curve []float64
+		want  int
+	}{
+		{nil, 0},
+		{[]float64{80, 100}, 2},
+		{[]float64{25, 50, 75, 100}, 1}, // A straight line has no elbow
+	} {
+		if got := DetectElbow(tt.curve); got != tt.want {
+			t.Errorf("DetectElbow(%v): expected %d, got %d", tt.curve, tt.want, got)
+		}
+	}
+}
+
+func TestParallelAnalysisComponents(t *testing.T) {
+	// Six noisy columns driven by two latent factors
+	rng := rand.New(rand.NewSource(3))
+	X := make(types.Matrix, 200)
+	for i := range X {
+		f1, f2 := rng.NormFloat64(), rng.NormFloat64()
+		X[i] = []float64{
+			f1 + 0.3*rng.NormFloat64(), f1 + 0.3*rng.NormFloat64(), f1 + 0.3*rng.NormFloat64(),
+			f2 + 0.3*rng.NormFloat64(), f2 + 0.3*rng.NormFloat64(), f2 + 0.3*rng.NormFloat64(),
+		}
+	}
+
+	got, err := ParallelAnalysisComponents(X, ParallelAnalysisIterations, 1)
+	if err != nil {
+		t.Fatalf("ParallelAnalysisComponents failed: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("expected 2 components, got %d", got)
+	}
+
+	advice, err := AdviseComponents(X)
+	if err != nil {
+		t.Fatalf("AdviseComponents failed: %v", err)
+	}
+	if want := 4 + len(AdviceVarianceThresholds); len(advice) != want {
+		t.Fatalf("expected %d recommendations, got %d", want, len(advice))
+	}
+	for _, rec := range advice[:4] {
+		if rec.Components != 2 {
+			t.Errorf("%s: expected 2 components, got %d", rec.Method, rec.Components)
+		}
+	}
+}
+
+func TestComponentStatus(t *testing.T) {
+	// Six noisy columns driven by two latent factors
+	rng := rand.New(rand.NewSource(5))
+	X := make(types.Matrix, 200)
+	for i := range X {
+		f1, f2 := rng.NormFloat64(), rng.NormFloat64()
+		X[i] = []float64{
+			f1 + 0.3*rng.NormFloat64(), f1 + 0.3*rng.NormFloat64(), f1 + 0.3*rng.NormFloat64(),
+			f2 + 0.3*rng.NormFloat64(), f2 + 0.3*rng.NormFloat64(), f2 + 0.3*rng.NormFloat64(),
+		}
+	}
+	X, err := NewPreprocessor(true, false, false).FitTransform(X)
+	if err != nil {
+		t.Fatalf("preprocessing failed: %v", err)
+	}
+
+	want := []string{ComponentSignal, ComponentSignal, ComponentNoise, ComponentNoise, ComponentNoise}
+	for _, criterion := range []string{StatusCriterionParallel, StatusCriterionBrokenStick, StatusCriterionKaiser} {
+		status, err := ComponentStatus(X, 5, criterion)
+		if err != nil {
+			t.Fatalf("%s: ComponentStatus failed: %v", criterion, err)
+		}
+		if !slices.Equal(status, want) {
+			t.Errorf("%s: expected %v, got %v", criterion, want, status)
+		}
+	}
+
+	if _, err := ComponentStatus(X, 5, "scree"); err == nil {
+		t.Error("expected an error for an unknown criterion")
+	}
+}
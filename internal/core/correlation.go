@@ -9,10 +9,13 @@ package core
 import (
 	"fmt"
 	"math"
+	"runtime"
 	"sort"
+	"sync"
 
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
 )
 
 // CorrelationRequest defines the input for correlation calculations
@@ -21,7 +24,8 @@ type CorrelationRequest struct {
 	MetadataNumeric     map[string][]float64 // Numeric metadata columns
 	MetadataCategorical map[string][]string  // Categorical metadata columns
 	Components          []int                // Which PCs to include (0-based)
-	Method              string               // "pearson" or "spearman"
+	Method              string               // "pearson", "spearman" or "robust"
+	Workers             int                  // Goroutines computing correlations (0 for GOMAXPROCS, 1 for serial)
 }
 
 // CorrelationResult contains the correlation analysis results
@@ -34,9 +38,10 @@ type CorrelationResult struct {
 
 // CalculateEigencorrelations computes correlations between PC scores and metadata variables
 //
-// This function calculates Pearson or Spearman correlations between principal component
-// scores and external metadata variables (both numeric and categorical). For categorical
-// variables, one-hot encoding is performed before correlation calculation.
+// This function calculates Pearson, Spearman or robust correlations between principal
+// component scores and external metadata variables (both numeric and categorical). For
+// categorical variables, one-hot encoding is performed before correlation calculation.
+// The robust method (Winsorized correlation) limits the leverage of a few extreme scores.
 //
 // Reference: Jolliffe, I.T. (2002). Principal Component Analysis, 2nd edition. Springer.
 func CalculateEigencorrelations(request CorrelationRequest) (*CorrelationResult, error) {
@@ -50,8 +55,8 @@ func CalculateEigencorrelations(request CorrelationRequest) (*CorrelationResult,
 	}
 
 	// Validate method
-	if request.Method != "pearson" && request.Method != "spearman" {
-		return nil, fmt.Errorf("invalid correlation method: %s (must be 'pearson' or 'spearman')", request.Method)
+	if request.Method != "pearson" && request.Method != "spearman" && request.Method != "robust" {
+		return nil, fmt.Errorf("invalid correlation method: %s (must be 'pearson', 'spearman' or 'robust')", request.Method)
 	}
 
 	// Determine which components to use
@@ -92,83 +97,74 @@ func CalculateEigencorrelations(request CorrelationRequest) (*CorrelationResult,
 		result.Components[i] = fmt.Sprintf("PC%d", comp+1)
 	}
 
-	// Calculate correlations for numeric variables
+	// Collect the variables to correlate, one-hot encoding categorical variables
+	type variable struct {
+		name   string
+		values []float64
+	}
+	var variables []variable
 	for varName, values := range request.MetadataNumeric {
 		if len(values) != nSamples {
 			return nil, fmt.Errorf("numeric variable '%s' has %d values, expected %d", varName, len(values), nSamples)
 		}
-
-		correlations := make([]float64, len(componentsToUse))
-		pValues := make([]float64, len(componentsToUse))
-
-		for i := range componentsToUse {
-			// Extract PC scores for this component
-			pcScores := mat.Col(nil, i, selectedScores)
-
-			// Calculate correlation
-			var corr, pval float64
-			var err error
-			if request.Method == "pearson" {
-				corr, pval, err = pearsonCorrelation(pcScores, values)
-			} else {
-				corr, pval, err = spearmanCorrelation(pcScores, values)
-			}
-
-			if err != nil {
-				// Skip this variable if correlation fails
-				continue
-			}
-
-			correlations[i] = corr
-			pValues[i] = pval
-		}
-
-		result.Correlations[varName] = correlations
-		result.PValues[varName] = pValues
-		result.Variables = append(result.Variables, varName)
+		variables = append(variables, variable{varName, values})
 	}
-
-	// Calculate correlations for categorical variables (one-hot encoded)
 	for varName, categories := range request.MetadataCategorical {
 		if len(categories) != nSamples {
 			return nil, fmt.Errorf("categorical variable '%s' has %d values, expected %d", varName, len(categories), nSamples)
 		}
+		for encodedName, values := range oneHotEncode(categories) {
+			variables = append(variables, variable{fmt.Sprintf("%s_%s", varName, encodedName), values})
+		}
+	}
 
-		// One-hot encode the categorical variable
-		encodedVars := oneHotEncode(categories)
-
-		// Calculate correlations for each encoded variable
-		for encodedName, values := range encodedVars {
-			correlations := make([]float64, len(componentsToUse))
-			pValues := make([]float64, len(componentsToUse))
-
-			for i := range componentsToUse {
-				// Extract PC scores for this component
-				pcScores := mat.Col(nil, i, selectedScores)
-
-				// Calculate correlation
-				var corr, pval float64
-				var err error
-				if request.Method == "pearson" {
-					corr, pval, err = pearsonCorrelation(pcScores, values)
-				} else {
-					corr, pval, err = spearmanCorrelation(pcScores, values)
-				}
+	pcScores := make([][]float64, len(componentsToUse))
+	for i := range componentsToUse {
+		pcScores[i] = mat.Col(nil, i, selectedScores)
+	}
+
+	// Each (variable, component) cell is independent, so workers write their results
+	// straight into preallocated slots
+	correlations := make([][]float64, len(variables))
+	pValues := make([][]float64, len(variables))
+	for v := range variables {
+		correlations[v] = make([]float64, len(componentsToUse))
+		pValues[v] = make([]float64, len(componentsToUse))
+	}
 
+	workers := request.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	nCells := len(variables) * len(componentsToUse)
+	cells := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, nCells); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cell := range cells {
+				v, i := cell/len(componentsToUse), cell%len(componentsToUse)
+				corr, pval, err := correlate(request.Method, pcScores[i], variables[v].values)
 				if err != nil {
-					// Skip this variable if correlation fails
+					// Leave the cell at zero if the correlation fails
 					continue
 				}
-
-				correlations[i] = corr
-				pValues[i] = pval
+				correlations[v][i] = corr
+				pValues[v][i] = pval
 			}
+		}()
+	}
+	for cell := 0; cell < nCells; cell++ {
+		cells <- cell
+	}
+	close(cells)
+	wg.Wait()
 
-			fullName := fmt.Sprintf("%s_%s", varName, encodedName)
-			result.Correlations[fullName] = correlations
-			result.PValues[fullName] = pValues
-			result.Variables = append(result.Variables, fullName)
-		}
+	for v, variable := range variables {
+		result.Correlations[variable.name] = correlations[v]
+		result.PValues[variable.name] = pValues[v]
+		result.Variables = append(result.Variables, variable.name)
 	}
 
 	// Sort variables by PC1 correlation (highest positive to most negative)
@@ -185,6 +181,18 @@ func CalculateEigencorrelations(request CorrelationRequest) (*CorrelationResult,
 	return result, nil
 }
 
+// correlate calculates the correlation and p-value of x and y with the given method
+func correlate(method string, x, y []float64) (float64, float64, error) {
+	switch method {
+	case "spearman":
+		return spearmanCorrelation(x, y)
+	case "robust":
+		return robustCorrelation(x, y)
+	default:
+		return pearsonCorrelation(x, y)
+	}
+}
+
 // pearsonCorrelation calculates Pearson correlation coefficient and p-value
 //
 // Reference: Press, W.H. et al. (2007). Numerical Recipes: The Art of Scientific Computing.
@@ -267,6 +275,83 @@ func spearmanCorrelation(x, y []float64) (float64, float64, error) {
 	return pearsonCorrelation(ranksX, ranksY)
 }
 
+// robustCorrelation calculates the 20% Winsorized correlation coefficient and p-value
+//
+// In each variable the lowest and highest 20% of values are replaced by the nearest
+// remaining value before the Pearson correlation is computed, so a few extreme values
+// cannot dominate the result. Binary variables, such as one-hot indicators, have no
+// outliers and are left unchanged.
+//
+// Reference: Wilcox, R.R. (2012). Introduction to Robust Estimation and Hypothesis
+// Testing, 3rd edition. Academic Press.
+func robustCorrelation(x, y []float64) (float64, float64, error) {
+	if len(x) != len(y) {
+		return 0, 0, fmt.Errorf("input vectors must have the same length")
+	}
+
+	n := len(x)
+	if n < 3 {
+		return 0, 0, fmt.Errorf("need at least 3 observations for correlation")
+	}
+
+	// Handle missing values by pairwise deletion
+	validX := make([]float64, 0, n)
+	validY := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		if !math.IsNaN(x[i]) && !math.IsNaN(y[i]) && !math.IsInf(x[i], 0) && !math.IsInf(y[i], 0) {
+			validX = append(validX, x[i])
+			validY = append(validY, y[i])
+		}
+	}
+
+	if len(validX) < 3 {
+		return 0, 0, fmt.Errorf("insufficient valid observations after removing missing values")
+	}
+
+	corr, _, err := pearsonCorrelation(winsorize(validX, 0.2), winsorize(validY, 0.2))
+	if err != nil || math.Abs(corr) >= 1.0 {
+		return corr, 0.0, err
+	}
+
+	// The test statistic is that of the Pearson correlation, but with h-2 degrees of
+	// freedom, where h = n-2g is the number of values left after trimming g from each tail
+	n = len(validX)
+	h := n - 2*int(0.2*float64(n))
+	t := corr * math.Sqrt(float64(n-2)/(1-corr*corr))
+	pval := 2 * (1 - studentTCDF(math.Abs(t), float64(h-2)))
+
+	return corr, pval, nil
+}
+
+// winsorize returns a copy of x with the lowest and highest fraction gamma of values
+// replaced by the nearest remaining value. Variables with two or fewer distinct values
+// are returned unchanged.
+func winsorize(x []float64, gamma float64) []float64 {
+	result := append([]float64(nil), x...)
+
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	distinct := 1
+	for i := 1; i < len(sorted) && distinct <= 2; i++ {
+		if sorted[i] != sorted[i-1] {
+			distinct++
+		}
+	}
+	if distinct <= 2 {
+		return result
+	}
+
+	g := int(gamma * float64(len(sorted)))
+	if g == 0 {
+		return result
+	}
+	lower, upper := sorted[g], sorted[len(sorted)-g-1]
+	for i, v := range result {
+		result[i] = math.Max(lower, math.Min(upper, v))
+	}
+	return result
+}
+
 // rank converts values to their ranks, handling ties by average rank
 func rank(x []float64) []float64 {
 	n := len(x)
@@ -339,32 +424,10 @@ func oneHotEncode(categories []string) map[string][]float64 {
 	return encoded
 }
 
-// studentTCDF approximates the cumulative distribution function of Student's t-distribution
+// studentTCDF computes the cumulative distribution function of Student's t-distribution
 // For p-value calculation, we need P(T > |t|) = 2 * (1 - CDF(|t|))
 func studentTCDF(t, df float64) float64 {
-	// Use the fact that T^2 follows an F-distribution with (1, df) degrees of freedom
-	// And F(1,df) relates to the Beta distribution
-	// For simplicity and accuracy, we use an approximation suitable for our p-value needs
-
-	// For large df (>30), t-distribution approaches normal
-	if df > 30 {
-		return normalCDF(t)
-	}
-
-	// For smaller df, use approximation based on the relationship:
-	// P(T <= t) ≈ 0.5 + 0.5 * sign(t) * (1 - I_x(df/2, 0.5))
-	// where x = df/(df + t^2) and I_x is the regularized incomplete beta function
-
-	// Simplified but accurate approximation for our use case
-	x := df / (df + t*t)
-
-	// Use approximation of incomplete beta for common df values
-	// This gives reasonable p-values for hypothesis testing
-	if t >= 0 {
-		return 1 - 0.5*math.Pow(x, df/2)
-	} else {
-		return 0.5 * math.Pow(x, df/2)
-	}
+	return distuv.StudentsT{Mu: 0, Sigma: 1, Nu: df}.CDF(t)
 }
 
 // normalCDF computes the cumulative distribution function of the standard normal distribution
@@ -7,7 +7,9 @@
 package core
 
 import (
+	"fmt"
 	"math"
+	"math/rand"
 	"testing"
 
 	"gonum.org/v1/gonum/mat"
@@ -189,6 +191,116 @@ func TestSpearmanCorrelation(t *testing.T) {
 	}
 }
 
+// TestRobustCorrelationOutlier tests that one extreme score barely moves the robust
+// correlation while it reverses the sign of the Pearson correlation
+func TestRobustCorrelationOutlier(t *testing.T) {
+	// Scores and a metadata variable that are strongly positively correlated
+	scores := make([]float64, 20)
+	metadata := make([]float64, 20)
+	for i := range scores {
+		scores[i] = float64(i)
+		metadata[i] = float64(i) + 0.5*math.Sin(float64(i))
+	}
+
+	cleanPearson, _, err := pearsonCorrelation(scores, metadata)
+	if err != nil {
+		t.Fatalf("pearsonCorrelation() unexpected error = %v", err)
+	}
+	cleanRobust, _, err := robustCorrelation(scores, metadata)
+	if err != nil {
+		t.Fatalf("robustCorrelation() unexpected error = %v", err)
+	}
+
+	// Inject one extreme score row that disagrees with the trend
+	scores = append(scores, 500)
+	metadata = append(metadata, -200)
+
+	outlierPearson, _, err := pearsonCorrelation(scores, metadata)
+	if err != nil {
+		t.Fatalf("pearsonCorrelation() unexpected error = %v", err)
+	}
+	outlierRobust, _, err := robustCorrelation(scores, metadata)
+	if err != nil {
+		t.Fatalf("robustCorrelation() unexpected error = %v", err)
+	}
+
+	if outlierPearson > 0 {
+		t.Errorf("expected the outlier to flip the Pearson correlation, got %.3f (clean %.3f)",
+			outlierPearson, cleanPearson)
+	}
+	if math.Abs(outlierRobust-cleanRobust) > 0.2 {
+		t.Errorf("robust correlation moved from %.3f to %.3f with one outlier", cleanRobust, outlierRobust)
+	}
+	if outlierRobust < 0.7 {
+		t.Errorf("robust correlation = %.3f, want a strong positive correlation", outlierRobust)
+	}
+}
+
+// TestRobustCorrelationPValue tests the Winsorized correlation test against values
+// computed as in Wilcox's wincor: with n = 20, g = 4 values are Winsorized in each tail
+// and the t statistic has h-2 = 10 degrees of freedom rather than n-2 = 18
+func TestRobustCorrelationPValue(t *testing.T) {
+	x := []float64{2.1, 3.4, 1.9, 5.6, 4.2, 3.3, 6.8, 2.7, 4.9, 5.1, 3.8, 7.2, 1.5, 4.4, 6.1, 2.9, 5.7, 3.1, 4.6, 12.0}
+	y := []float64{1.8, 2.9, 2.5, 4.8, 3.1, 3.9, 5.2, 2.2, 4.1, 3.6, 3.0, 6.1, 1.9, 4.9, 4.7, 3.3, 4.2, 2.4, 3.5, 1.0}
+
+	corr, pval, err := robustCorrelation(x, y)
+	if err != nil {
+		t.Fatalf("robustCorrelation() unexpected error = %v", err)
+	}
+	if math.Abs(corr-0.717708127832799) > 1e-12 {
+		t.Errorf("robust correlation = %.15f, want 0.717708127832799", corr)
+	}
+	if math.Abs(pval-0.0013929706704212887) > 1e-8 {
+		t.Errorf("robust correlation p-value = %.10g, want 0.001392970670", pval)
+	}
+}
+
+// TestCorrelationPValues pins the Pearson and Spearman p-values to the exact two-sided
+// t-test with n-2 degrees of freedom, computed from the closed form of Student's t
+// distribution for integer degrees of freedom. These p-values changed when
+// studentTCDF replaced its approximation with the exact distribution.
+func TestCorrelationPValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		corr  func(x, y []float64) (float64, float64, error)
+		x, y  []float64
+		wantR float64
+		wantP float64
+	}{
+		{
+			name:  "pearson",
+			corr:  pearsonCorrelation,
+			x:     []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			y:     []float64{2.3, 1.9, 3.8, 3.1, 5.2, 4.0, 6.3, 4.9, 5.5, 7.4},
+			wantR: 0.8912321304082746,
+			wantP: 0.0005359674335621012,
+		},
+		{
+			name:  "spearman",
+			corr:  spearmanCorrelation,
+			x:     []float64{3.1, 1.2, 4.8, 2.2, 5.9, 3.3, 6.4, 2.8, 5.0, 4.1, 7.7, 1.9},
+			y:     []float64{2.0, 1.5, 3.9, 3.2, 4.1, 2.2, 5.5, 3.0, 3.7, 4.8, 6.0, 2.6},
+			wantR: 0.8391608391608392,
+			wantP: 0.0006428259886278598,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, p, err := tt.corr(tt.x, tt.y)
+			if err != nil {
+				t.Fatalf("unexpected error = %v", err)
+			}
+			if math.Abs(r-tt.wantR) > 1e-12 {
+				t.Errorf("r = %.15f, want %.15f", r, tt.wantR)
+			}
+			if math.Abs(p-tt.wantP) > 1e-10 {
+				t.Errorf("p-value = %.12g, want %.12g", p, tt.wantP)
+			}
+		})
+	}
+}
+
 // TestRank tests the ranking function
 func TestRank(t *testing.T) {
 	tests := []struct {
@@ -573,22 +685,110 @@ func TestStatisticalFunctions(t *testing.T) {
 	})
 
 	t.Run("studentTCDF", func(t *testing.T) {
-		// Just test that it returns reasonable values
+		// Quantiles of Student's t distribution
 		tests := []struct {
-			t  float64
-			df float64
+			t    float64
+			df   float64
+			want float64
 		}{
-			{0, 10},
-			{1, 10},
-			{2, 10},
-			{0, 50}, // Should use normal approximation
+			{0, 10, 0.5},
+			{1, 1, 0.75},
+			{2.228138851986, 10, 0.975},
+			{-2.228138851986, 10, 0.025},
+			{2.008559112100761, 50, 0.975},
 		}
 
 		for _, tt := range tests {
 			got := studentTCDF(tt.t, tt.df)
-			if got < 0 || got > 1 {
-				t.Errorf("studentTCDF(%v, %v) = %v, want value in [0,1]", tt.t, tt.df, got)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("studentTCDF(%v, %v) = %v, want %v", tt.t, tt.df, got, tt.want)
 			}
 		}
 	})
 }
+
+// eigencorrelationRequest builds a request with nComponents random PC scores and
+// nMetadata numeric metadata columns, plus one categorical column
+func eigencorrelationRequest(nSamples, nComponents, nMetadata int, method string) CorrelationRequest {
+	rng := rand.New(rand.NewSource(42))
+	scores := mat.NewDense(nSamples, nComponents, nil)
+	for i := 0; i < nSamples; i++ {
+		for j := 0; j < nComponents; j++ {
+			scores.Set(i, j, rng.NormFloat64())
+		}
+	}
+
+	numeric := make(map[string][]float64, nMetadata)
+	for k := 0; k < nMetadata; k++ {
+		values := make([]float64, nSamples)
+		for i := range values {
+			values[i] = scores.At(i, k%nComponents) + rng.NormFloat64()
+		}
+		numeric[fmt.Sprintf("var%d", k)] = values
+	}
+	groups := make([]string, nSamples)
+	for i := range groups {
+		groups[i] = []string{"a", "b", "c"}[rng.Intn(3)]
+	}
+
+	return CorrelationRequest{
+		Scores:              scores,
+		MetadataNumeric:     numeric,
+		MetadataCategorical: map[string][]string{"group": groups},
+		Method:              method,
+	}
+}
+
+// TestEigencorrelationsParallel tests that parallel workers give exactly the serial results
+func TestEigencorrelationsParallel(t *testing.T) {
+	for _, method := range []string{"pearson", "spearman", "robust"} {
+		t.Run(method, func(t *testing.T) {
+			request := eigencorrelationRequest(50, 5, 30, method)
+
+			request.Workers = 1
+			serial, err := CalculateEigencorrelations(request)
+			if err != nil {
+				t.Fatalf("Serial eigencorrelations failed: %v", err)
+			}
+			request.Workers = 8
+			parallel, err := CalculateEigencorrelations(request)
+			if err != nil {
+				t.Fatalf("Parallel eigencorrelations failed: %v", err)
+			}
+
+			if len(parallel.Variables) != len(serial.Variables) {
+				t.Fatalf("Expected %d variables, got %d", len(serial.Variables), len(parallel.Variables))
+			}
+			for _, name := range serial.Variables {
+				for i, want := range serial.Correlations[name] {
+					if got := parallel.Correlations[name][i]; got != want {
+						t.Errorf("%s PC%d: correlation %v differs from serial %v", name, i+1, got, want)
+					}
+					if got, want := parallel.PValues[name][i], serial.PValues[name][i]; got != want {
+						t.Errorf("%s PC%d: p-value %v differs from serial %v", name, i+1, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEigencorrelations benchmarks 20 PCs against 100 metadata columns
+func BenchmarkEigencorrelations(b *testing.B) {
+	for _, workers := range []int{1, 0} {
+		name := "serial"
+		if workers == 0 {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			request := eigencorrelationRequest(500, 20, 100, "spearman")
+			request.Workers = workers
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := CalculateEigencorrelations(request); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+// EffectiveDimensionality returns the participation ratio (Σλ)² / Σλ² of the eigenvalues,
+// a continuous count of the components that carry the variance: k equal eigenvalues
+// give k, and one dominant eigenvalue gives about 1. Pass all eigenvalues, not only the
+// retained ones. Negative eigenvalues from round-off are treated as zero. Returns 0 if
+// no eigenvalue is positive.
+func EffectiveDimensionality(eigenvalues []float64) float64 {
+	sum, sumSq := 0.0, 0.0
+	for _, v := range eigenvalues {
+		if v <= 0 {
+			continue
+		}
+		sum += v
+		sumSq += v * v
+	}
+	if sumSq == 0 {
+		return 0
+	}
+	return sum * sum / sumSq
+}
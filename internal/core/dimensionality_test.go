@@ -0,0 +1,74 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestEffectiveDimensionality(t *testing.T) {
+	tests := []struct {
+		name        string
+		eigenvalues []float64
+		want        float64
+	}{
+		{"three equal", []float64{2, 2, 2}, 3},
+		{"one dominant", []float64{100, 0.1, 0.1, 0.1}, 1.006},
+		{"round-off ignored", []float64{1, 1, -1e-15}, 2},
+		{"empty", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EffectiveDimensionality(tt.eigenvalues); math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("expected %f, got %f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEffectiveDimensionalityFromPCA(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	n := 2000
+
+	tests := []struct {
+		name   string
+		scales []float64 // Standard deviation of each independent column
+		want   float64
+		tol    float64
+	}{
+		// k independent columns with equal variance have k equal eigenvalues
+		{"four equal", []float64{1, 1, 1, 1}, 4, 0.2},
+		{"one dominant", []float64{10, 0.1, 0.1, 0.1}, 1, 0.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make(types.Matrix, n)
+			for i := range data {
+				data[i] = make([]float64, len(tt.scales))
+				for j, scale := range tt.scales {
+					data[i][j] = scale * rng.NormFloat64()
+				}
+			}
+
+			config := types.PCAConfig{Components: 2, MeanCenter: true, Method: "svd"}
+			result, err := NewPCAEngine().Fit(data, config)
+			if err != nil {
+				t.Fatalf("PCA fit failed: %v", err)
+			}
+
+			if got := EffectiveDimensionality(result.AllEigenvalues); math.Abs(got-tt.want) > tt.tol {
+				t.Errorf("expected about %g, got %f", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"flag"
+	"math"
+	"testing"
+
+	"github.com/bitjungle/gopca/internal/testdata"
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func init() {
+	flag.BoolVar(&testdata.UpdateGolden, "update-golden", false, "regenerate the golden PCA results of the benchmark datasets")
+}
+
+// TestGoldenResults fits the benchmark datasets with each method and compares the
+// eigenvalues and loadings, or kernel PCA scores, with the committed golden results.
+// After an intended change of the results, regenerate them with
+// go test ./internal/core -run TestGoldenResults -update-golden
+func TestGoldenResults(t *testing.T) {
+	tests := []struct {
+		name          string
+		dataset       string
+		preprocess    bool // Mean center the data before fitting
+		standardScale bool // Also scale it to unit variance
+		config        types.PCAConfig
+		tol           float64
+	}{
+		{"iris_svd", "iris", true, true, types.PCAConfig{Components: 3, Method: "svd"}, 1e-9},
+		{"iris_nipals", "iris", true, true, types.PCAConfig{Components: 3, Method: "nipals"}, 1e-6},
+		{"corn_svd", "corn", true, false, types.PCAConfig{Components: 5, Method: "svd"}, 1e-9},
+		{"corn_nipals", "corn", true, false, types.PCAConfig{Components: 5, Method: "nipals"}, 1e-6},
+		// Native missing value handling centers the data itself
+		{"met_nipals_native", "met", false, false,
+			types.PCAConfig{Components: 3, Method: "nipals", MissingStrategy: types.MissingNative}, 1e-6},
+		{"iris_kernel_rbf", "iris", true, true,
+			types.PCAConfig{Components: 2, Method: "kernel", KernelType: "rbf", KernelGamma: 0.5}, 1e-8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := testdata.Load(tt.dataset)
+			if err != nil {
+				t.Fatalf("failed to load %s: %v", tt.dataset, err)
+			}
+			processed := data.Matrix
+			if tt.preprocess {
+				processed, err = NewPreprocessor(true, tt.standardScale, false).FitTransform(data.Matrix)
+				if err != nil {
+					t.Fatalf("preprocessing failed: %v", err)
+				}
+			}
+
+			engine := NewPCAEngineForMethod(tt.config.Method)
+			result, err := engine.Fit(processed, tt.config)
+			if err != nil {
+				t.Fatalf("Fit failed: %v", err)
+			}
+			testdata.AssertPCAResultMatches(t, result, testdata.GoldenPath(tt.name+".json"), tt.tol)
+		})
+	}
+}
+
+// TestBenchmarkDatasets tests the sizes and missing values of the benchmark datasets
+func TestBenchmarkDatasets(t *testing.T) {
+	want := map[string]struct{ rows, columns, missing int }{
+		"corn": {80, 70, 0},
+		"iris": {150, 4, 0},
+		"met":  {720, 6, 15},
+	}
+	for _, name := range testdata.Names() {
+		data, err := testdata.Load(name)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", name, err)
+		}
+		missing := 0
+		for _, row := range data.Matrix {
+			for _, v := range row {
+				if math.IsNaN(v) {
+					missing++
+				}
+			}
+		}
+		got := want[name]
+		if len(data.Matrix) != got.rows || len(data.Headers) != got.columns || missing != got.missing {
+			t.Errorf("%s: expected %d×%d with %d missing, got %d×%d with %d missing", name,
+				got.rows, got.columns, got.missing, len(data.Matrix), len(data.Headers), missing)
+		}
+	}
+	if _, err := testdata.Load("unknown"); err == nil {
+		t.Error("expected an error for an unknown dataset")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// VariableImportance summarizes the contribution of each variable across all components
+// as Σ_k loading[var,k]² · explainedVar[k], normalized to sum to 1. explainedVar may be
+// given as fractions or percentages since only the relative weights matter. Components
+// without an explained variance entry are ignored. Returns nil if loadings are empty.
+func VariableImportance(loadings types.Matrix, explainedVar []float64) []float64 {
+	if len(loadings) == 0 {
+		return nil
+	}
+
+	importance := make([]float64, len(loadings))
+	total := 0.0
+	for i, row := range loadings {
+		for k, loading := range row {
+			if k >= len(explainedVar) {
+				break
+			}
+			importance[i] += loading * loading * explainedVar[k]
+		}
+		total += importance[i]
+	}
+
+	if total > 0 {
+		for i := range importance {
+			importance[i] /= total
+		}
+	}
+	return importance
+}
+
+// TopLoadingsRelativeThreshold is the fraction of a component's largest absolute
+// loading that a variable must reach to be listed by TopLoadings. A relative
+// threshold works for a few variables as well as for spectra, whose loadings are
+// all small.
+const TopLoadingsRelativeThreshold = 0.5
+
+// InterpretationTopN is the number of positive and negative variables listed per
+// component in exported models and the analyze table output
+const InterpretationTopN = 3
+
+// TopLoadings describes each component (column of loadings) by the variables with
+// the largest positive and negative loadings, at most topN of each, keeping only
+// loadings of at least TopLoadingsRelativeThreshold times the largest absolute
+// loading on the component. Components are labeled PC1, PC2, ... and variables
+// without a label Feature_1, Feature_2, ... Returns nil if loadings are empty.
+func TopLoadings(loadings types.Matrix, labels []string, topN int) []types.ComponentInterpretation {
+	if len(loadings) == 0 || len(loadings[0]) == 0 || topN < 1 {
+		return nil
+	}
+
+	name := func(i int) string {
+		if i < len(labels) {
+			return labels[i]
+		}
+		return fmt.Sprintf("Feature_%d", i+1)
+	}
+
+	components := make([]types.ComponentInterpretation, len(loadings[0]))
+	for k := range components {
+		largest := 0.0
+		for _, row := range loadings {
+			largest = math.Max(largest, math.Abs(row[k]))
+		}
+
+		order := make([]int, len(loadings))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return math.Abs(loadings[order[a]][k]) > math.Abs(loadings[order[b]][k])
+		})
+
+		interpretation := types.ComponentInterpretation{
+			Component: fmt.Sprintf("PC%d", k+1),
+			Positive:  []types.VariableLoading{},
+			Negative:  []types.VariableLoading{},
+		}
+		for _, i := range order {
+			loading := loadings[i][k]
+			if largest == 0 || math.Abs(loading) < TopLoadingsRelativeThreshold*largest {
+				break
+			}
+			entry := types.VariableLoading{Variable: name(i), Loading: loading}
+			if loading > 0 && len(interpretation.Positive) < topN {
+				interpretation.Positive = append(interpretation.Positive, entry)
+			} else if loading < 0 && len(interpretation.Negative) < topN {
+				interpretation.Negative = append(interpretation.Negative, entry)
+			}
+		}
+		interpretation.Summary = interpretationSummary(interpretation)
+		components[k] = interpretation
+	}
+	return components
+}
+
+// interpretationSummary phrases the top loadings of a component as a short hint
+func interpretationSummary(c types.ComponentInterpretation) string {
+	join := func(loadings []types.VariableLoading) string {
+		names := make([]string, len(loadings))
+		for i, l := range loadings {
+			names[i] = l.Variable
+		}
+		return strings.Join(names, ", ")
+	}
+
+	switch {
+	case len(c.Positive) > 0 && len(c.Negative) > 0:
+		return fmt.Sprintf("contrasts %s (+) against %s (−)", join(c.Positive), join(c.Negative))
+	case len(c.Positive) > 0:
+		return fmt.Sprintf("driven by %s (+)", join(c.Positive))
+	case len(c.Negative) > 0:
+		return fmt.Sprintf("driven by %s (−)", join(c.Negative))
+	default:
+		return "no dominant variables"
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// readIrisMeasurements reads the four iris measurements and their column names
+func readIrisMeasurements(t *testing.T) (types.Matrix, []string) {
+	t.Helper()
+
+	f, err := os.Open("../../testdata/iris/iris.csv")
+	if err != nil {
+		t.Fatalf("failed to open iris data: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read iris data: %v", err)
+	}
+
+	// Columns 1-4 are the measurements; the rest are class labels
+	headers := records[0][1:5]
+	data := make(types.Matrix, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make([]float64, 4)
+		for j := range row {
+			row[j], err = strconv.ParseFloat(record[j+1], 64)
+			if err != nil {
+				t.Fatalf("invalid value %q: %v", record[j+1], err)
+			}
+		}
+		data = append(data, row)
+	}
+	return data, headers
+}
+
+func TestVariableImportanceIris(t *testing.T) {
+	data, headers := readIrisMeasurements(t)
+
+	engine := NewPCAEngine()
+	result, err := engine.Fit(data, types.PCAConfig{
+		Components: 2,
+		MeanCenter: true,
+		Method:     "svd",
+	})
+	if err != nil {
+		t.Fatalf("PCA failed: %v", err)
+	}
+
+	importance := VariableImportance(result.Loadings, result.ExplainedVarRatio)
+	if len(importance) != len(headers) {
+		t.Fatalf("expected %d importances, got %d", len(headers), len(importance))
+	}
+
+	sum := 0.0
+	top := 0
+	for i, v := range importance {
+		if v < 0 {
+			t.Errorf("importance of %s is negative: %f", headers[i], v)
+		}
+		sum += v
+		if v > importance[top] {
+			top = i
+		}
+	}
+	if math.Abs(sum-1) > 1e-10 {
+		t.Errorf("importances sum to %f, expected 1", sum)
+	}
+	if headers[top] != "petal length (cm)" {
+		t.Errorf("expected petal length to rank highest, got %s (%v)", headers[top], importance)
+	}
+}
+
+func TestVariableImportanceEmpty(t *testing.T) {
+	if got := VariableImportance(nil, nil); got != nil {
+		t.Errorf("expected nil for empty loadings, got %v", got)
+	}
+
+	// Zero loadings give zero importance rather than NaN
+	got := VariableImportance(types.Matrix{{0, 0}, {0, 0}}, []float64{60, 40})
+	for i, v := range got {
+		if v != 0 {
+			t.Errorf("expected zero importance for variable %d, got %f", i, v)
+		}
+	}
+}
+
+func TestTopLoadingsIris(t *testing.T) {
+	data, headers := readIrisMeasurements(t)
+
+	engine := NewPCAEngine()
+	result, err := engine.Fit(data, types.PCAConfig{
+		Components: 2,
+		MeanCenter: true,
+		Method:     "svd",
+	})
+	if err != nil {
+		t.Fatalf("PCA failed: %v", err)
+	}
+
+	interpretations := TopLoadings(result.Loadings, headers, 2)
+	if len(interpretations) != 2 {
+		t.Fatalf("expected 2 components, got %d", len(interpretations))
+	}
+	pc1 := interpretations[0]
+	if pc1.Component != "PC1" {
+		t.Errorf("expected PC1, got %s", pc1.Component)
+	}
+	// The sign of PC1 is arbitrary, so its top variable may be on either side
+	top := pc1.Positive
+	if len(pc1.Negative) > 0 && (len(top) == 0 || math.Abs(pc1.Negative[0].Loading) > math.Abs(top[0].Loading)) {
+		top = pc1.Negative
+	}
+	if len(top) == 0 || !strings.HasPrefix(top[0].Variable, "petal") {
+		t.Errorf("expected a petal measurement as the top variable of PC1, got %+v", pc1)
+	}
+	if !strings.Contains(pc1.Summary, top[0].Variable) {
+		t.Errorf("expected the summary to name %s, got %q", top[0].Variable, pc1.Summary)
+	}
+}
+
+func TestTopLoadingsContrast(t *testing.T) {
+	loadings := types.Matrix{{0.7}, {0.1}, {-0.6}, {0.5}}
+	got := TopLoadings(loadings, []string{"a", "b", "c"}, 1)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 component, got %d", len(got))
+	}
+	// b is below half the largest loading; only the strongest positive is kept
+	if len(got[0].Positive) != 1 || got[0].Positive[0].Variable != "a" ||
+		len(got[0].Negative) != 1 || got[0].Negative[0].Variable != "c" {
+		t.Errorf("unexpected top loadings: %+v", got[0])
+	}
+	if want := "contrasts a (+) against c (−)"; got[0].Summary != want {
+		t.Errorf("expected summary %q, got %q", want, got[0].Summary)
+	}
+
+	if got := TopLoadings(nil, nil, 3); got != nil {
+		t.Errorf("expected nil for empty loadings, got %v", got)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/bitjungle/gopca/pkg/security"
+	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// kernelTileSize is the side of the square tiles in which the kernel matrix is
+	// computed, small enough that the rows of a tile stay in cache
+	kernelTileSize = 128
+	// kernelMatrixCopies is the number of n×n matrices held at once while fitting
+	// kernel PCA: the kernel matrix, centered in place, the eigenvectors computed by
+	// the eigendecomposition and the copy they are sorted from
+	kernelMatrixCopies = 3
+)
+
+// KernelPCAMemoryBytes estimates the peak memory, in bytes, of the n×n matrices
+// needed to fit kernel PCA to n samples
+func KernelPCAMemoryBytes(n int) int64 {
+	return kernelMatrixCopies * 8 * int64(n) * int64(n)
+}
+
+// MaxKernelPCASamplesForMemory returns the largest number of samples whose kernel
+// PCA fits in security.MaxMemoryUsageMB
+func MaxKernelPCASamplesForMemory() int {
+	limit := float64(security.MaxMemoryUsageMB) * 1024 * 1024
+	return int(math.Sqrt(limit / (kernelMatrixCopies * 8)))
+}
+
+// checkKernelPCAMemory returns an error suggesting a subsample size if kernel PCA of
+// n samples would need more than security.MaxMemoryUsageMB
+func checkKernelPCAMemory(n int) error {
+	need := KernelPCAMemoryBytes(n)
+	if need <= int64(security.MaxMemoryUsageMB)*1024*1024 {
+		return nil
+	}
+	return fmt.Errorf("kernel PCA of %d samples needs about %d MB for its %d×%d kernel matrices, "+
+		"more than the %d MB limit; subsample to at most %d samples or use SVD or NIPALS",
+		n, need/(1024*1024), n, n, security.MaxMemoryUsageMB, MaxKernelPCASamplesForMemory())
+}
+
+// kernelFunc returns the kernel function of the configured kernel type
+func (kpca *KernelPCAImpl) kernelFunc() (func(x, y []float64) float64, error) {
+	gamma, coef0, degree := kpca.config.KernelGamma, kpca.config.KernelCoef0, float64(kpca.config.KernelDegree)
+	switch kpca.kernelType {
+	case KernelRBF:
+		return func(x, y []float64) float64 {
+			sum := 0.0
+			for i := range x {
+				diff := x[i] - y[i]
+				sum += diff * diff
+			}
+			return math.Exp(-gamma * sum)
+		}, nil
+	case KernelLinear:
+		return dotProduct, nil
+	case KernelPoly:
+		return func(x, y []float64) float64 {
+			return math.Pow(gamma*dotProduct(x, y)+coef0, degree)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kernel type: %s", kpca.kernelType)
+	}
+}
+
+// dotProduct returns the dot product of two vectors of the same length
+func dotProduct(x, y []float64) float64 {
+	sum := 0.0
+	for i := range x {
+		sum += x[i] * y[i]
+	}
+	return sum
+}
+
+// computeKernelMatrix computes the full, symmetric kernel matrix of data. The upper
+// triangle is split into tiles of kernelTileSize rows and columns, computed in
+// parallel and mirrored to the lower triangle.
+func (kpca *KernelPCAImpl) computeKernelMatrix(ctx context.Context, data types.Matrix) (*mat.Dense, error) {
+	n := len(data)
+	for i := 1; i < n; i++ {
+		if err := ValidateVectorPair(data[0], data[i]); err != nil {
+			return nil, fmt.Errorf("error computing kernel at (0, %d): %w", i, err)
+		}
+	}
+	kernel, err := kpca.kernelFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	K := mat.NewDense(n, n, nil)
+	raw := K.RawMatrix()
+	nTiles := (n + kernelTileSize - 1) / kernelTileSize
+
+	// Tile (bi, bj) of the upper triangle also fills tile (bj, bi), so no two tiles
+	// write to the same elements
+	type tile struct{ bi, bj int }
+	tiles := make(chan tile)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), nTiles*(nTiles+1)/2); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tiles {
+				fillKernelTile(raw, data, kernel, t.bi, t.bj)
+			}
+		}()
+	}
+
+send:
+	for bi := 0; bi < nTiles; bi++ {
+		for bj := bi; bj < nTiles; bj++ {
+			select {
+			case tiles <- tile{bi, bj}:
+			case <-ctx.Done():
+				break send
+			}
+		}
+	}
+	close(tiles)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return K, nil
+}
+
+// fillKernelTile computes the kernel values of tile (bi, bj) of the upper triangle
+// and mirrors them to the lower triangle
+func fillKernelTile(raw blas64.General, data types.Matrix, kernel func(x, y []float64) float64, bi, bj int) {
+	n := len(data)
+	iEnd := min((bi+1)*kernelTileSize, n)
+	jEnd := min((bj+1)*kernelTileSize, n)
+	for i := bi * kernelTileSize; i < iEnd; i++ {
+		jStart := bj * kernelTileSize
+		if bi == bj {
+			jStart = i
+		}
+		for j := jStart; j < jEnd; j++ {
+			v := kernel(data[i], data[j])
+			raw.Data[i*raw.Stride+j] = v
+			raw.Data[j*raw.Stride+i] = v
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/mat"
+)
+
+// naiveKernelMatrix computes the kernel matrix element by element, as reference
+func naiveKernelMatrix(t testing.TB, kpca *KernelPCAImpl, data types.Matrix) *mat.Dense {
+	n := len(data)
+	K := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v, err := kpca.computeKernel(data[i], data[j])
+			if err != nil {
+				t.Fatalf("kernel at (%d, %d): %v", i, j, err)
+			}
+			K.Set(i, j, v)
+		}
+	}
+	return K
+}
+
+// randomKernelData returns n samples of m standard normal features
+func randomKernelData(n, m int) types.Matrix {
+	rng := rand.New(rand.NewSource(5))
+	data := make(types.Matrix, n)
+	for i := range data {
+		data[i] = make([]float64, m)
+		for j := range data[i] {
+			data[i][j] = rng.NormFloat64()
+		}
+	}
+	return data
+}
+
+func TestComputeKernelMatrixBlocked(t *testing.T) {
+	// Sizes below, at and across tile boundaries
+	for _, n := range []int{1, 7, kernelTileSize, 2*kernelTileSize + 13} {
+		data := randomKernelData(n, 4)
+		for _, kernel := range []KernelType{KernelRBF, KernelLinear, KernelPoly} {
+			t.Run(fmt.Sprintf("%s/%d", kernel, n), func(t *testing.T) {
+				kpca := &KernelPCAImpl{
+					config:     types.PCAConfig{KernelGamma: 0.3, KernelDegree: 3, KernelCoef0: 1},
+					kernelType: kernel,
+				}
+				K, err := kpca.computeKernelMatrix(context.Background(), data)
+				if err != nil {
+					t.Fatalf("computeKernelMatrix failed: %v", err)
+				}
+				if want := naiveKernelMatrix(t, kpca, data); !mat.Equal(K, want) {
+					t.Errorf("blocked kernel matrix differs from the element-wise one")
+				}
+			})
+		}
+	}
+}
+
+func TestComputeKernelMatrixCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	kpca := &KernelPCAImpl{config: types.PCAConfig{KernelGamma: 0.1}, kernelType: KernelRBF}
+	if _, err := kpca.computeKernelMatrix(ctx, randomKernelData(3*kernelTileSize, 2)); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCheckKernelPCAMemory(t *testing.T) {
+	limit := MaxKernelPCASamplesForMemory()
+	if err := checkKernelPCAMemory(limit); err != nil {
+		t.Errorf("expected %d samples to fit in memory, got %v", limit, err)
+	}
+	err := checkKernelPCAMemory(limit + 1)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("subsample to at most %d samples", limit)) {
+		t.Errorf("expected an error suggesting %d samples, got %v", limit, err)
+	}
+}
+
+func BenchmarkKernelMatrix(b *testing.B) {
+	data := randomKernelData(1000, 20)
+	kpca := &KernelPCAImpl{config: types.PCAConfig{KernelGamma: 0.05}, kernelType: KernelRBF}
+
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naiveKernelMatrix(b, kpca, data)
+		}
+	})
+	b.Run("blocked", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := kpca.computeKernelMatrix(context.Background(), data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -7,6 +7,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
@@ -14,6 +15,7 @@ import (
 	"github.com/bitjungle/gopca/pkg/security"
 	"github.com/bitjungle/gopca/pkg/types"
 	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
 )
 
 // KernelType represents the type of kernel function to use
@@ -28,6 +30,168 @@ const (
 	KernelPoly KernelType = "poly"
 )
 
+// Heuristics for choosing the RBF kernel gamma from the data
+const (
+	// KernelGammaAuto sets gamma to 1/(n_features·var(X)), with the variance taken over
+	// all values of X (the "scale" default of scikit-learn)
+	KernelGammaAuto = "auto"
+	// KernelGammaMedian sets gamma to 1/median of the squared Euclidean distances
+	// between all pairs of samples (the median heuristic)
+	KernelGammaMedian = "median"
+)
+
+// EstimateKernelGamma returns the RBF kernel gamma chosen by heuristic, either
+// KernelGammaAuto or KernelGammaMedian, for data as it will be passed to Fit.
+// Returns an error if the data has no spread.
+func EstimateKernelGamma(data types.Matrix, heuristic string) (float64, error) {
+	if len(data) < 2 || len(data[0]) == 0 {
+		return 0, fmt.Errorf("at least 2 samples are required to estimate kernel gamma")
+	}
+	nSamples, nFeatures := len(data), len(data[0])
+
+	switch heuristic {
+	case KernelGammaAuto:
+		values := make([]float64, 0, nSamples*nFeatures)
+		for _, row := range data {
+			values = append(values, row...)
+		}
+		_, variance := stat.PopMeanVariance(values, nil)
+		if variance <= MinVarianceThreshold {
+			return 0, fmt.Errorf("cannot estimate kernel gamma: data has zero variance")
+		}
+		return 1 / (float64(nFeatures) * variance), nil
+
+	case KernelGammaMedian:
+		distances := make([]float64, 0, nSamples*(nSamples-1)/2)
+		for i := 0; i < nSamples; i++ {
+			for j := i + 1; j < nSamples; j++ {
+				sum := 0.0
+				for k := range data[i] {
+					diff := data[i][k] - data[j][k]
+					sum += diff * diff
+				}
+				distances = append(distances, sum)
+			}
+		}
+		sort.Float64s(distances)
+		median := stat.Quantile(0.5, stat.Empirical, distances, nil)
+		if median <= 0 {
+			return 0, fmt.Errorf("cannot estimate kernel gamma: median distance between samples is zero")
+		}
+		return 1 / median, nil
+
+	default:
+		return 0, fmt.Errorf("unknown kernel gamma heuristic %q: must be %s or %s",
+			heuristic, KernelGammaAuto, KernelGammaMedian)
+	}
+}
+
+// withDefaultKernelGamma sets gamma to 1/n_features if it is not specified for the
+// RBF and polynomial kernels
+func withDefaultKernelGamma(config types.PCAConfig, nFeatures int) types.PCAConfig {
+	if config.KernelGamma == 0 && (KernelType(config.KernelType) == KernelRBF || KernelType(config.KernelType) == KernelPoly) {
+		config.KernelGamma = 1.0 / float64(nFeatures)
+	}
+	return config
+}
+
+// KernelMatrices returns the kernel (Gram) matrix of data for the kernel in config
+// and the same matrix centered in feature space, which is what kernel PCA
+// decomposes. Data is used as given, so preprocess it first; gamma defaults to
+// 1/n_features as in Fit.
+func KernelMatrices(data types.Matrix, config types.PCAConfig) (K, Kc *mat.Dense, err error) {
+	if err := ValidateKernelConfig(config); err != nil {
+		return nil, nil, fmt.Errorf("invalid kernel configuration: %w", err)
+	}
+	if len(data) == 0 || len(data[0]) == 0 {
+		return nil, nil, fmt.Errorf("empty data matrix")
+	}
+	if len(data) > security.MaxKernelPCASamples {
+		return nil, nil, fmt.Errorf("kernel matrix limited to %d samples for memory safety (got %d samples)",
+			security.MaxKernelPCASamples, len(data))
+	}
+
+	kpca := &KernelPCAImpl{
+		config:     withDefaultKernelGamma(config, len(data[0])),
+		kernelType: KernelType(config.KernelType),
+	}
+	K, err = kpca.computeKernelMatrix(context.Background(), data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error computing kernel matrix: %w", err)
+	}
+	Kc, err = kpca.centerKernelMatrix(mat.DenseCopyOf(K))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error centering kernel matrix: %w", err)
+	}
+	return K, Kc, nil
+}
+
+// KernelConditionWarning is the condition number of the retained eigenvalues of the
+// centered kernel matrix above which kernel PCA is reported as near-singular
+const KernelConditionWarning = 1e10
+
+// KernelMatrixStats summarizes the entries and spectrum of a kernel matrix
+type KernelMatrixStats struct {
+	Min       float64
+	Max       float64
+	Mean      float64
+	NonFinite int // NaN or infinite entries; the spectrum is not computed if there are any
+
+	MinEigenvalue   float64 // Clearly negative values mean the kernel is not positive semidefinite
+	MaxEigenvalue   float64
+	ConditionNumber float64 // Ratio of the largest to the smallest singular value, +Inf if singular
+	NumericalRank   int     // Eigenvalues above the rounding error tolerance n·eps·max|eigenvalue|
+}
+
+// SummarizeKernelMatrix returns summary statistics of the symmetric matrix K
+func SummarizeKernelMatrix(K *mat.Dense) (KernelMatrixStats, error) {
+	n, _ := K.Dims()
+	stats := KernelMatrixStats{Min: math.Inf(1), Max: math.Inf(-1)}
+	finite := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v := K.At(i, j)
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				stats.NonFinite++
+				continue
+			}
+			stats.Min = math.Min(stats.Min, v)
+			stats.Max = math.Max(stats.Max, v)
+			stats.Mean += v
+			finite++
+		}
+	}
+	if finite > 0 {
+		stats.Mean /= float64(finite)
+	}
+	if stats.NonFinite > 0 {
+		stats.MinEigenvalue, stats.MaxEigenvalue, stats.ConditionNumber = math.NaN(), math.NaN(), math.NaN()
+		return stats, nil
+	}
+
+	var eig mat.EigenSym
+	if !eig.Factorize(mat.NewSymDense(n, CopyMatrixData(K)), false) {
+		return stats, fmt.Errorf("eigendecomposition of the kernel matrix failed")
+	}
+	values := eig.Values(nil) // ascending
+	stats.MinEigenvalue, stats.MaxEigenvalue = values[0], values[n-1]
+
+	// For a symmetric matrix the singular values are the absolute eigenvalues
+	smallest, largest := math.Inf(1), 0.0
+	for _, v := range values {
+		smallest = math.Min(smallest, math.Abs(v))
+		largest = math.Max(largest, math.Abs(v))
+	}
+	stats.ConditionNumber = largest / smallest
+	tolerance := float64(n) * 0x1p-52 * largest
+	for _, v := range values {
+		if math.Abs(v) > tolerance {
+			stats.NumericalRank++
+		}
+	}
+	return stats, nil
+}
+
 // KernelPCAImpl implements the PCAEngine interface for Kernel PCA
 // Kernel PCA performs nonlinear dimensionality reduction by projecting data into a higher-dimensional
 // feature space using kernel functions, then performing PCA in that space.
@@ -43,6 +207,8 @@ type KernelPCAImpl struct {
 	// Precomputed values for centering
 	trainKernelMeans []float64
 	totalKernelMean  float64
+	// Eigenvalues at or below this are rounding error of the centering: n·eps·max|K|
+	eigenvalueTolerance float64
 	// Preprocessor for variance scaling
 	preprocessor *Preprocessor
 }
@@ -57,71 +223,32 @@ func (kpca *KernelPCAImpl) computeKernel(x, y []float64) (float64, error) {
 	if err := ValidateVectorPair(x, y); err != nil {
 		return 0, err
 	}
-
-	switch kpca.kernelType {
-	case KernelRBF:
-		sum := 0.0
-		for i := range x {
-			diff := x[i] - y[i]
-			sum += diff * diff
-		}
-		return math.Exp(-kpca.config.KernelGamma * sum), nil
-
-	case KernelLinear:
-		sum := 0.0
-		for i := range x {
-			sum += x[i] * y[i]
-		}
-		return sum, nil
-
-	case KernelPoly:
-		sum := 0.0
-		for i := range x {
-			sum += x[i] * y[i]
-		}
-		return math.Pow(kpca.config.KernelGamma*sum+kpca.config.KernelCoef0, float64(kpca.config.KernelDegree)), nil
-
-	default:
-		return 0, fmt.Errorf("unsupported kernel type: %s", kpca.kernelType)
-	}
-}
-
-// computeKernelMatrix computes the full kernel matrix
-func (kpca *KernelPCAImpl) computeKernelMatrix(data types.Matrix) (*mat.Dense, error) {
-	n := len(data)
-	K := mat.NewDense(n, n, nil)
-
-	for i := 0; i < n; i++ {
-		for j := i; j < n; j++ {
-			val, err := kpca.computeKernel(data[i], data[j])
-			if err != nil {
-				return nil, fmt.Errorf("error computing kernel at (%d, %d): %w", i, j, err)
-			}
-			K.Set(i, j, val)
-			if i != j {
-				K.Set(j, i, val) // Kernel matrix is symmetric
-			}
-		}
+	kernel, err := kpca.kernelFunc()
+	if err != nil {
+		return 0, err
 	}
-
-	return K, nil
+	return kernel(x, y), nil
 }
 
-// centerKernelMatrix centers the kernel matrix
+// centerKernelMatrix centers the kernel matrix in place, so that the implicit
+// feature vectors have zero mean, and returns it
 func (kpca *KernelPCAImpl) centerKernelMatrix(K *mat.Dense) (*mat.Dense, error) {
 	n, _ := K.Dims()
+	raw := K.RawMatrix()
 
 	// Compute row and column means
 	rowMeans := make([]float64, n)
 	colMeans := make([]float64, n)
 	totalMean := 0.0
+	maxAbs := 0.0
 
 	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			val := K.At(i, j)
+		row := raw.Data[i*raw.Stride : i*raw.Stride+n]
+		for j, val := range row {
 			rowMeans[i] += val
 			colMeans[j] += val
 			totalMean += val
+			maxAbs = math.Max(maxAbs, math.Abs(val))
 		}
 	}
 
@@ -129,34 +256,33 @@ func (kpca *KernelPCAImpl) centerKernelMatrix(K *mat.Dense) (*mat.Dense, error)
 		rowMeans[i] /= float64(n)
 		colMeans[i] /= float64(n)
 	}
-	totalMean /= float64(n * n)
+	totalMean /= float64(n) * float64(n)
 
 	// Store for transform method
 	kpca.trainKernelMeans = colMeans
 	kpca.totalKernelMean = totalMean
+	kpca.eigenvalueTolerance = float64(n) * 0x1p-52 * maxAbs
 
 	// Center the kernel matrix
-	Kc := mat.NewDense(n, n, nil)
 	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			val := K.At(i, j) - rowMeans[i] - colMeans[j] + totalMean
-			Kc.Set(i, j, val)
+		row := raw.Data[i*raw.Stride : i*raw.Stride+n]
+		for j := range row {
+			row[j] += totalMean - rowMeans[i] - colMeans[j]
 		}
 	}
 
-	return Kc, nil
+	return K, nil
 }
 
 // eigenDecomposition performs eigendecomposition and returns top k components and all eigenvalues
 func (kpca *KernelPCAImpl) eigenDecomposition(K *mat.Dense, k int) ([]float64, []float64, *mat.Dense, error) {
-	// Convert to symmetric matrix for eigendecomposition
+	// View the symmetric matrix as such, sharing its storage instead of copying it
 	n, _ := K.Dims()
-	symK := mat.NewSymDense(n, nil)
-	for i := 0; i < n; i++ {
-		for j := i; j < n; j++ {
-			symK.SetSym(i, j, K.At(i, j))
-		}
+	raw := K.RawMatrix()
+	if raw.Stride != n {
+		raw = mat.DenseCopyOf(K).RawMatrix()
 	}
+	symK := mat.NewSymDense(n, raw.Data)
 
 	var eig mat.EigenSym
 	if ok := eig.Factorize(symK, true); !ok {
@@ -177,14 +303,11 @@ func (kpca *KernelPCAImpl) eigenDecomposition(K *mat.Dense, k int) ([]float64, [
 		return vals[idx[i]] > vals[idx[j]]
 	})
 
-	// Store all eigenvalues in sorted order for variance calculation
+	// Store all eigenvalues in sorted order for variance calculation; negative ones
+	// are clamped by clampKernelEigenvalues
 	allSortedVals := make([]float64, nVals)
 	for i := 0; i < nVals; i++ {
 		allSortedVals[i] = vals[idx[i]]
-		// Handle near-zero or negative eigenvalues
-		if allSortedVals[i] < 1e-10 {
-			allSortedVals[i] = 1e-10
-		}
 	}
 
 	// Extract top k components
@@ -207,6 +330,17 @@ func (kpca *KernelPCAImpl) eigenDecomposition(K *mat.Dense, k int) ([]float64, [
 
 // Fit trains the Kernel PCA model on the provided data
 func (kpca *KernelPCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*types.PCAResult, error) {
+	return kpca.FitContext(context.Background(), data, config)
+}
+
+// FitContext trains the Kernel PCA model like Fit, but returns the context's error
+// when ctx is cancelled. Cancellation is checked between the kernel matrix,
+// centering and eigendecomposition stages.
+func (kpca *KernelPCAImpl) FitContext(ctx context.Context, data types.Matrix, config types.PCAConfig) (*types.PCAResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Validate configuration
 	if err := ValidateKernelConfig(config); err != nil {
 		return nil, fmt.Errorf("invalid kernel configuration: %w", err)
@@ -218,6 +352,14 @@ func (kpca *KernelPCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*type
 	if len(data) == 0 || len(data[0]) == 0 {
 		return nil, fmt.Errorf("empty data matrix")
 	}
+	data, droppedRows, warning, err := handleZeroVarianceRows(data, config)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
 
 	nSamples := len(data)
 	nFeatures := len(data[0])
@@ -230,18 +372,17 @@ func (kpca *KernelPCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*type
 			"3) Applying dimension reduction first",
 			security.MaxKernelPCASamples, nSamples)
 	}
+	if err := checkKernelPCAMemory(nSamples); err != nil {
+		return nil, err
+	}
 
 	if config.Components > nSamples {
 		return nil, fmt.Errorf("number of components (%d) cannot exceed number of samples (%d)",
 			config.Components, nSamples)
 	}
 
-	// Set default gamma to 1/n_features if not specified (for RBF and polynomial kernels)
-	if config.KernelGamma == 0 && (KernelType(config.KernelType) == KernelRBF || KernelType(config.KernelType) == KernelPoly) {
-		config.KernelGamma = 1.0 / float64(nFeatures)
-	}
-
 	// Store the configuration after setting defaults
+	config = withDefaultKernelGamma(config, nFeatures)
 	kpca.config = config
 
 	// Apply preprocessing if needed (only variance scaling, SNV, or vector norm for kernel PCA)
@@ -256,6 +397,9 @@ func (kpca *KernelPCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*type
 			config.SNV,        // SNV allowed
 			config.VectorNorm, // vector norm allowed
 		)
+		kpca.preprocessor.VectorNormType = VectorNormType(config.VectorNormType)
+		kpca.preprocessor.RobustCenter = RobustCenter(config.RobustCenter)
+		kpca.preprocessor.RobustScaleStat = RobustScaleStat(config.RobustScaleStat)
 
 		// Fit and transform
 		var err error
@@ -273,30 +417,45 @@ func (kpca *KernelPCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*type
 	}
 
 	// Compute kernel matrix using preprocessed data
-	K, err := kpca.computeKernelMatrix(processedData)
+	K, err := kpca.computeKernelMatrix(ctx, processedData)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("error computing kernel matrix: %w", err)
 	}
 
-	// Center kernel matrix
+	// Center kernel matrix in place
 	Kc, err := kpca.centerKernelMatrix(K)
 	if err != nil {
 		return nil, fmt.Errorf("error centering kernel matrix: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Perform eigendecomposition
 	eigvals, allEigvals, eigvecs, err := kpca.eigenDecomposition(Kc, config.Components)
 	if err != nil {
 		return nil, fmt.Errorf("error in eigendecomposition: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	diagnostics := clampKernelEigenvalues(allEigvals, len(eigvals), kpca.eigenvalueTolerance)
+	copy(eigvals, allEigvals)
 
 	kpca.eigvals = eigvals
 	kpca.eigvecs = eigvecs
 	kpca.fitted = true
 
-	// Compute projections for training data
+	// Compute projections for training data. Components with a numerically zero
+	// eigenvalue have no variance to scale by and keep zero scores.
 	scores := mat.NewDense(nSamples, config.Components, nil)
 	for i := 0; i < config.Components; i++ {
+		if eigvals[i] <= kpca.eigenvalueTolerance {
+			continue
+		}
 		norm := math.Sqrt(eigvals[i])
 		for j := 0; j < nSamples; j++ {
 			scores.Set(j, i, eigvecs.At(j, i)/norm)
@@ -347,9 +506,54 @@ func (kpca *KernelPCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*type
 		Method:               "kernel",
 		PreprocessingApplied: config.ScaleOnly || config.SNV || config.VectorNorm,
 		AllEigenvalues:       allEigvals,
+		KernelDiagnostics:    diagnostics,
+		DroppedRows:          droppedRows,
+		Warnings:             warnings,
 	}, nil
 }
 
+// clampKernelEigenvalues sets the negative eigenvalues of the centered kernel matrix,
+// sorted in descending order, to zero and reports them with the conditioning of the
+// first k. Eigenvalues at or below tolerance count as zero.
+func clampKernelEigenvalues(eigenvalues []float64, k int, tolerance float64) *types.KernelDiagnostics {
+	diagnostics := &types.KernelDiagnostics{}
+	for i, v := range eigenvalues {
+		if v < 0 {
+			diagnostics.ClampedEigenvalues++
+			diagnostics.MaxClamped = math.Max(diagnostics.MaxClamped, -v)
+			eigenvalues[i] = 0
+		}
+	}
+
+	smallest := 0.0
+	for _, v := range eigenvalues[:k] {
+		if v <= tolerance {
+			diagnostics.NullComponents++
+		} else {
+			smallest = v
+		}
+	}
+	if smallest > 0 {
+		diagnostics.ConditionNumber = eigenvalues[0] / smallest
+	}
+	diagnostics.NearSingular = diagnostics.NullComponents > 0 || diagnostics.ConditionNumber > KernelConditionWarning
+	return diagnostics
+}
+
+// DescribeKernelDiagnostics returns a one-line summary of the clamped eigenvalues and
+// conditioning of a kernel PCA fit, with advice if the centered kernel is near-singular
+func DescribeKernelDiagnostics(d *types.KernelDiagnostics) string {
+	summary := fmt.Sprintf("Kernel eigenvalues: %d negative clamped to zero (largest magnitude %.3g), condition number %.3g",
+		d.ClampedEigenvalues, d.MaxClamped, d.ConditionNumber)
+	if d.NullComponents > 0 {
+		summary += fmt.Sprintf(", %d components with zero eigenvalue and zero scores", d.NullComponents)
+	}
+	if d.NearSingular {
+		summary += "; the centered kernel is near-singular: adjust gamma or the kernel parameters, or retain fewer components"
+	}
+	return summary
+}
+
 // Transform projects new data into the kernel PCA space
 func (kpca *KernelPCAImpl) Transform(data types.Matrix) (types.Matrix, error) {
 	if !kpca.fitted {
@@ -401,6 +605,9 @@ func (kpca *KernelPCAImpl) Transform(data types.Matrix) (types.Matrix, error) {
 	for i := 0; i < nTest; i++ {
 		result[i] = make([]float64, nComponents)
 		for j := 0; j < nComponents; j++ {
+			if kpca.eigvals[j] <= kpca.eigenvalueTolerance {
+				continue
+			}
 			sum := 0.0
 			norm := math.Sqrt(kpca.eigvals[j])
 			for k := 0; k < nTrain; k++ {
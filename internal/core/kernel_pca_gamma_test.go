@@ -174,3 +174,41 @@ func TestKernelPCA_ExplicitGamma(t *testing.T) {
 		t.Errorf("Expected gamma to remain %f, got %f", explicitGamma, kpca.config.KernelGamma)
 	}
 }
+
+// TestEstimateKernelGamma tests the auto and median heuristics on a known matrix
+func TestEstimateKernelGamma(t *testing.T) {
+	data := types.Matrix{
+		{0, 0},
+		{1, 0},
+		{0, 2},
+	}
+
+	// auto: values {0,0,1,0,0,2} have mean 0.5 and population variance 3.5/6,
+	// so gamma = 1/(2·3.5/6) = 6/7
+	gamma, err := EstimateKernelGamma(data, KernelGammaAuto)
+	if err != nil {
+		t.Fatalf("auto heuristic failed: %v", err)
+	}
+	if math.Abs(gamma-6.0/7.0) > 1e-12 {
+		t.Errorf("auto: expected gamma %f, got %f", 6.0/7.0, gamma)
+	}
+
+	// median: squared distances are 1, 4 and 5, so gamma = 1/4
+	gamma, err = EstimateKernelGamma(data, KernelGammaMedian)
+	if err != nil {
+		t.Fatalf("median heuristic failed: %v", err)
+	}
+	if math.Abs(gamma-0.25) > 1e-12 {
+		t.Errorf("median: expected gamma 0.25, got %f", gamma)
+	}
+
+	constant := types.Matrix{{1, 1}, {1, 1}, {1, 1}}
+	for _, heuristic := range []string{KernelGammaAuto, KernelGammaMedian} {
+		if _, err := EstimateKernelGamma(constant, heuristic); err == nil {
+			t.Errorf("%s: expected error for constant data", heuristic)
+		}
+	}
+	if _, err := EstimateKernelGamma(data, "mean"); err == nil {
+		t.Error("Expected error for unknown heuristic")
+	}
+}
@@ -322,6 +322,82 @@ func TestKernelPCA_MoreComponentsThanSamples(t *testing.T) {
 	}
 }
 
+func TestKernelPCA_DegenerateKernel(t *testing.T) {
+	// Identical points center to a zero kernel matrix, whose eigenvalues are rounding
+	// error of either sign
+	data := make(types.Matrix, 10)
+	for i := range data {
+		data[i] = []float64{1.5, -2.0, 0.25}
+	}
+
+	for _, kernelType := range []string{"rbf", "linear", "poly"} {
+		t.Run(kernelType, func(t *testing.T) {
+			engine := NewKernelPCAEngine()
+			config := types.PCAConfig{
+				Components:   3,
+				Method:       "kernel",
+				KernelType:   kernelType,
+				KernelGamma:  1.0,
+				KernelDegree: 2,
+				KernelCoef0:  1.0,
+			}
+			result, err := engine.Fit(data, config)
+			if err != nil {
+				t.Fatalf("Fit failed on a degenerate kernel: %v", err)
+			}
+
+			for i, row := range result.Scores {
+				for j, v := range row {
+					if v != 0 {
+						t.Fatalf("expected zero scores, got %g at (%d, %d)", v, i, j)
+					}
+				}
+			}
+			for _, values := range [][]float64{result.AllEigenvalues, result.ExplainedVar, result.ExplainedVarRatio} {
+				for _, v := range values {
+					if math.IsNaN(v) || v < 0 {
+						t.Fatalf("expected finite, non-negative eigenvalues and ratios, got %v", values)
+					}
+				}
+			}
+
+			d := result.KernelDiagnostics
+			if d == nil {
+				t.Fatal("expected kernel diagnostics")
+			}
+			if d.NullComponents != config.Components || !d.NearSingular {
+				t.Errorf("expected %d null components of a near-singular kernel, got %+v", config.Components, d)
+			}
+			if d.ClampedEigenvalues > 0 && d.MaxClamped <= 0 {
+				t.Errorf("expected the magnitude of the clamped eigenvalues, got %+v", d)
+			}
+
+			projected, err := engine.Transform(data[:2])
+			if err != nil {
+				t.Fatalf("Transform failed: %v", err)
+			}
+			for _, row := range projected {
+				for _, v := range row {
+					if v != 0 {
+						t.Fatalf("expected zero projections, got %v", projected)
+					}
+				}
+			}
+		})
+	}
+
+	// A well-conditioned fit reports no null components
+	result, err := NewKernelPCAEngine().Fit(generateCircleData(), types.PCAConfig{
+		Components: 2, Method: "kernel", KernelType: "rbf", KernelGamma: 1.0,
+	})
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if d := result.KernelDiagnostics; d == nil || d.NullComponents != 0 || d.NearSingular || d.ConditionNumber < 1 {
+		t.Errorf("expected a well-conditioned kernel, got %+v", d)
+	}
+}
+
 func TestKernelPCA_ExplainedVarianceCalculation(t *testing.T) {
 	// Test that explained variance is calculated correctly
 	// Using all eigenvalues, not just selected components
@@ -420,3 +496,41 @@ func TestNewPCAEngineForMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestKernelMatrices_RBF(t *testing.T) {
+	data := generateCircleData()
+	config := types.PCAConfig{KernelType: "rbf", KernelGamma: 0.5}
+
+	K, Kc, err := KernelMatrices(data, config)
+	if err != nil {
+		t.Fatalf("KernelMatrices failed: %v", err)
+	}
+
+	n, _ := K.Dims()
+	for i := 0; i < n; i++ {
+		if math.Abs(K.At(i, i)-1) > 1e-12 {
+			t.Errorf("K[%d][%d] = %v, want 1", i, i, K.At(i, i))
+		}
+		rowSum := 0.0
+		for j := 0; j < n; j++ {
+			if K.At(i, j) != K.At(j, i) {
+				t.Errorf("K is not symmetric at (%d, %d): %v != %v", i, j, K.At(i, j), K.At(j, i))
+			}
+			rowSum += Kc.At(i, j)
+		}
+		if math.Abs(rowSum) > 1e-10 {
+			t.Errorf("centered row %d sums to %v, want 0", i, rowSum)
+		}
+	}
+
+	stats, err := SummarizeKernelMatrix(K)
+	if err != nil {
+		t.Fatalf("SummarizeKernelMatrix failed: %v", err)
+	}
+	if stats.Max != 1 || stats.Min <= 0 || stats.NonFinite != 0 {
+		t.Errorf("unexpected RBF kernel range: min=%v max=%v non-finite=%d", stats.Min, stats.Max, stats.NonFinite)
+	}
+	if stats.MinEigenvalue < -1e-10 {
+		t.Errorf("RBF kernel should be positive semidefinite, min eigenvalue %v", stats.MinEigenvalue)
+	}
+}
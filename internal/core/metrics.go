@@ -16,6 +16,42 @@ import (
 	"gonum.org/v1/gonum/stat/distuv"
 )
 
+// Outlier methods of OutlierTest
+const (
+	// OutlierMethodT2 flags rows whose Hotelling's T² exceeds the F-distribution limit
+	// p(n-1)/(n-p)·F(p, n-p), which assumes multivariate normal scores
+	OutlierMethodT2 = "t2"
+	// OutlierMethodMahalanobis flags rows whose squared Mahalanobis distance exceeds
+	// the χ²(p) quantile, the large-sample limit of T² for normal scores
+	OutlierMethodMahalanobis = "mahalanobis"
+	// OutlierMethodSPE flags rows whose squared prediction error (Q, the RSS) exceeds
+	// the Jackson–Mudholkar limit from the eigenvalues of the discarded components,
+	// which assumes normal residuals
+	OutlierMethodSPE = "spe"
+	// OutlierMethodCombined flags rows flagged by the T² or the SPE test
+	OutlierMethodCombined = "combined"
+)
+
+// Quadrants of the influence plot, by the 95% limits of T² and Q
+const (
+	InfluenceNormal = "normal"  // Within both limits
+	InfluenceHighT2 = "high-t2" // Beyond the T² limit only: extreme, but fitted by the model
+	InfluenceHighQ  = "high-q"  // Beyond the Q limit only: poorly fitted by the model
+	InfluenceBoth   = "both"    // Beyond both limits
+)
+
+// DefaultOutlierSignificance is the significance level of the outlier test unless
+// another is set
+const DefaultOutlierSignificance = 0.001
+
+// OutlierTest configures how PCAMetricsCalculator flags outliers
+type OutlierTest struct {
+	Method       string  // One of the OutlierMethod constants; empty for OutlierMethodT2
+	Significance float64 // Significance level α of the test; 0 for DefaultOutlierSignificance
+	// Eigenvalues of all components, retained and discarded, for the SPE limit
+	Eigenvalues []float64
+}
+
 // PCAMetricsCalculator calculates advanced metrics for PCA results
 type PCAMetricsCalculator struct {
 	// PCA model parameters
@@ -29,6 +65,9 @@ type PCAMetricsCalculator struct {
 
 	// Regularization for numerical stability
 	regularization float64
+
+	// How outliers are flagged
+	outlierTest OutlierTest
 }
 
 // NewPCAMetricsCalculator creates a new metrics calculator
@@ -52,7 +91,29 @@ func NewPCAMetricsCalculator(scores, loadings *mat.Dense, mean, stdDev []float64
 		nSamples:       nSamples,
 		nFeatures:      nFeatures,
 		regularization: regularization,
+		outlierTest:    OutlierTest{Method: OutlierMethodT2, Significance: DefaultOutlierSignificance},
+	}
+}
+
+// SetOutlierTest sets how CalculateMetrics flags outliers. Raising the significance
+// level lowers the limits, so more rows are flagged.
+func (m *PCAMetricsCalculator) SetOutlierTest(test OutlierTest) error {
+	if test.Method == "" {
+		test.Method = OutlierMethodT2
+	}
+	if test.Significance == 0 {
+		test.Significance = DefaultOutlierSignificance
+	}
+	switch test.Method {
+	case OutlierMethodT2, OutlierMethodMahalanobis, OutlierMethodSPE, OutlierMethodCombined:
+	default:
+		return fmt.Errorf("invalid outlier method %q: must be t2, mahalanobis, spe or combined", test.Method)
+	}
+	if !(test.Significance > 0 && test.Significance < 1) {
+		return fmt.Errorf("outlier significance must be between 0 and 1, got %g", test.Significance)
 	}
+	m.outlierTest = test
+	return nil
 }
 
 // CalculateMetrics computes all metrics for each sample
@@ -90,6 +151,8 @@ func (m *PCAMetricsCalculator) CalculateMetrics(originalData types.Matrix) ([]ty
 		scoreMeans[j] = sum / float64(m.nSamples)
 	}
 
+	limits := m.outlierLimits()
+
 	// Calculate metrics for each sample
 	for i := 0; i < m.nSamples; i++ {
 		// Get score vector for this sample
@@ -110,8 +173,8 @@ func (m *PCAMetricsCalculator) CalculateMetrics(originalData types.Matrix) ([]ty
 			return nil, fmt.Errorf("failed to calculate RSS for sample %d: %w", i, err)
 		}
 
-		// Determine if sample is an outlier based on Hotelling's T²
-		isOutlier := m.isOutlier(hotellingT2)
+		// Determine if sample is an outlier with the configured test
+		isOutlier := limits.flags(m.outlierTest.Method, hotellingT2, mahalanobis, rss)
 
 		metrics[i] = types.SampleMetrics{
 			HotellingT2: hotellingT2,
@@ -227,34 +290,49 @@ func (m *PCAMetricsCalculator) calculateRSS(sampleIdx int, originalData types.Ma
 	return rss, nil
 }
 
-// isOutlier determines if a sample is an outlier based on Hotelling's T²
+// outlierLimits holds the critical values of the outlier tests; +Inf where a limit
+// cannot be calculated, so that no row exceeds it
+type outlierLimits struct {
+	t2  float64 // Hotelling's T²
+	d2  float64 // Squared Mahalanobis distance
+	spe float64 // Squared prediction error (RSS)
+}
+
+// flags reports whether a row with the given statistics is an outlier by method
+func (l outlierLimits) flags(method string, t2, mahalanobis, rss float64) bool {
+	switch method {
+	case OutlierMethodMahalanobis:
+		return mahalanobis*mahalanobis > l.d2
+	case OutlierMethodSPE:
+		return rss > l.spe
+	case OutlierMethodCombined:
+		return t2 > l.t2 || rss > l.spe
+	default:
+		return t2 > l.t2
+	}
+}
+
+// outlierLimits calculates the critical values of the outlier tests at the
+// configured significance level
 // Reference: Hotelling, H. (1931). The generalization of Student's ratio.
 // Annals of Mathematical Statistics, 2(3), 360-378.
-func (m *PCAMetricsCalculator) isOutlier(hotellingT2 float64) bool {
-	// Calculate critical value using F-distribution
-	// T²_critical = p(n-1)/(n-p) * F_{p,n-p}(1-α)
-	// where p = number of components, n = number of samples, α = significance level
-
-	alpha := 0.001 // 99.9% confidence level - less sensitive to outliers
+func (m *PCAMetricsCalculator) outlierLimits() outlierLimits {
+	alpha := m.outlierTest.Significance
 	p := float64(m.nComponents)
 	n := float64(m.nSamples)
+	limits := outlierLimits{t2: math.Inf(1), d2: math.Inf(1), spe: math.Inf(1)}
 
-	if n <= p {
-		// Cannot calculate threshold with insufficient samples
-		return false
+	// T²_critical = p(n-1)/(n-p) * F_{p,n-p}(1-α)
+	// where p = number of components, n = number of samples, α = significance level
+	if n > p {
+		fDist := distuv.F{D1: p, D2: n - p}
+		limits.t2 = p * (n - 1) / (n - p) * fDist.Quantile(1-alpha)
 	}
-
-	// Create F-distribution
-	fDist := distuv.F{
-		D1: p,
-		D2: n - p,
+	limits.d2 = distuv.ChiSquared{K: p}.Quantile(1 - alpha)
+	if q := m.qLimit(m.outlierTest.Eigenvalues, len(m.outlierTest.Eigenvalues), 1-alpha); q > 0 {
+		limits.spe = q
 	}
-
-	// Calculate critical value
-	fCritical := fDist.Quantile(1 - alpha)
-	t2Critical := p * (n - 1) / (n - p) * fCritical
-
-	return hotellingT2 > t2Critical
+	return limits
 }
 
 // CalculateT2Limits calculates the confidence limits for Hotelling's T² statistic
@@ -289,43 +367,33 @@ func (m *PCAMetricsCalculator) CalculateT2Limits() (limit95, limit99 float64) {
 func (m *PCAMetricsCalculator) CalculateQLimits(eigenvalues []float64, totalComponents int) (limit95, limit99 float64) {
 	// Q-statistic (SPE) limit calculation based on Jackson & Mudholkar (1979)
 	// Uses the Box's approximation for the distribution of Q
+	return m.qLimit(eigenvalues, totalComponents, 0.95), m.qLimit(eigenvalues, totalComponents, 0.99)
+}
 
+// qLimit calculates the Q-residual limit at the given confidence level, or 0 when
+// the non-retained components have no variance
+func (m *PCAMetricsCalculator) qLimit(eigenvalues []float64, totalComponents int, confidence float64) float64 {
 	// Calculate theta values from eigenvalues of non-retained components
 	theta1, theta2, theta3 := 0.0, 0.0, 0.0
-
-	// Start from the first non-retained component
 	for i := m.nComponents; i < totalComponents && i < len(eigenvalues); i++ {
 		lambda := eigenvalues[i]
 		theta1 += lambda
 		theta2 += lambda * lambda
 		theta3 += lambda * lambda * lambda
 	}
-
 	if theta1 == 0 || theta2 == 0 {
-		// No variance in non-retained components
-		return 0, 0
+		return 0
 	}
 
 	// Calculate h0 parameter
 	h0 := 1 - (2*theta1*theta3)/(3*theta2*theta2)
 
-	// Calculate critical values from standard normal distribution
-	normDist := distuv.Normal{Mu: 0, Sigma: 1}
-	z95 := normDist.Quantile(0.95)
-	z99 := normDist.Quantile(0.99)
-
 	// Jackson & Mudholkar approximation formula:
-	// Q_α = θ₁[c_α√(2θ₂h₀)/θ₁ + 1 + θ₂h₀(h₀-1)/θ₁²]^(1/h₀)
+	// Q_α = θ₁[c_α√(2θ₂h₀²)/θ₁ + 1 + θ₂h₀(h₀-1)/θ₁²]^(1/h₀)
 	// where c_α is the normal quantile
-
-	// Calculate Q limits
-	term95 := z95*math.Sqrt(2*theta2*h0)/theta1 + 1 + theta2*h0*(h0-1)/(theta1*theta1)
-	limit95 = theta1 * math.Pow(term95, 1/h0)
-
-	term99 := z99*math.Sqrt(2*theta2*h0)/theta1 + 1 + theta2*h0*(h0-1)/(theta1*theta1)
-	limit99 = theta1 * math.Pow(term99, 1/h0)
-
-	return limit95, limit99
+	c := distuv.Normal{Mu: 0, Sigma: 1}.Quantile(confidence)
+	term := c*h0*math.Sqrt(2*theta2)/theta1 + 1 + theta2*h0*(h0-1)/(theta1*theta1)
+	return theta1 * math.Pow(term, 1/h0)
 }
 
 // CalculateMetricsFromPCAResult is a convenience function that calculates metrics directly from PCAResult
@@ -341,3 +409,55 @@ func CalculateMetricsFromPCAResult(result *types.PCAResult, preprocessedData typ
 	// Note: preprocessedData should be the same preprocessed data that was used for PCA fitting
 	return calculator.CalculateMetrics(preprocessedData)
 }
+
+// CalculateMetricsWithOutlierTest calculates metrics like CalculateMetricsFromPCAResult,
+// flagging outliers with the given test. The eigenvalues for the SPE limit default
+// to result.AllEigenvalues.
+func CalculateMetricsWithOutlierTest(result *types.PCAResult, preprocessedData types.Matrix, test OutlierTest) ([]types.SampleMetrics, error) {
+	if test.Eigenvalues == nil {
+		test.Eigenvalues = result.AllEigenvalues
+	}
+	calculator := NewPCAMetricsCalculator(utils.MatrixToDense(result.Scores), utils.MatrixToDense(result.Loadings), result.Means, result.StdDevs)
+	if err := calculator.SetOutlierTest(test); err != nil {
+		return nil, err
+	}
+	return calculator.CalculateMetrics(preprocessedData)
+}
+
+// CalculateDiagnosticLimits returns the 95% and 99% limits of Hotelling's T² and of
+// the Q residuals of a fit. The Q limits are 0 when every component is retained.
+func CalculateDiagnosticLimits(result *types.PCAResult) types.DiagnosticLimits {
+	calculator := NewPCAMetricsCalculator(utils.MatrixToDense(result.Scores), utils.MatrixToDense(result.Loadings),
+		result.Means, result.StdDevs)
+	var limits types.DiagnosticLimits
+	limits.T2Limit95, limits.T2Limit99 = calculator.CalculateT2Limits()
+	limits.QLimit95, limits.QLimit99 = calculator.CalculateQLimits(result.AllEigenvalues, len(result.AllEigenvalues))
+	return limits
+}
+
+// InfluencePlot returns the influence plot coordinates of each sample from its
+// metrics: T² against Q, with the 95% limits and the quadrant the sample falls in
+func InfluencePlot(metrics []types.SampleMetrics, limits types.DiagnosticLimits) []types.InfluencePoint {
+	points := make([]types.InfluencePoint, len(metrics))
+	for i, m := range metrics {
+		highT2 := limits.T2Limit95 > 0 && m.HotellingT2 > limits.T2Limit95
+		highQ := limits.QLimit95 > 0 && m.RSS > limits.QLimit95
+		status := InfluenceNormal
+		switch {
+		case highT2 && highQ:
+			status = InfluenceBoth
+		case highT2:
+			status = InfluenceHighT2
+		case highQ:
+			status = InfluenceHighQ
+		}
+		points[i] = types.InfluencePoint{
+			T2:        m.HotellingT2,
+			Q:         m.RSS,
+			T2Limit95: limits.T2Limit95,
+			QLimit95:  limits.QLimit95,
+			Status:    status,
+		}
+	}
+	return points
+}
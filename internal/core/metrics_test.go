@@ -8,6 +8,7 @@ package core
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/bitjungle/gopca/pkg/types"
@@ -523,6 +524,16 @@ func TestCalculateQLimits(t *testing.T) {
 
 	t.Logf("Q limits calculated: 95%%=%f, 99%%=%f", limit95, limit99)
 
+	// Jackson & Mudholkar (1979) for the non-retained eigenvalues 0.8, 0.5, 0.3, 0.1
+	// and 0.05, calculated independently. Simulating Q = Σλᵢzᵢ² gives quantiles of
+	// about 4.5 and 6.7.
+	if math.Abs(limit95-4.585971575730457) > 1e-9 {
+		t.Errorf("Expected 95%% Q limit 4.585972, got %f", limit95)
+	}
+	if math.Abs(limit99-7.030316022854073) > 1e-9 {
+		t.Errorf("Expected 99%% Q limit 7.030316, got %f", limit99)
+	}
+
 	// Test edge case: all variance in retained components
 	eigenvaluesNoResidual := []float64{5.0, 3.0, 1.5, 0.0, 0.0, 0.0}
 	limit95Zero, limit99Zero := calc.CalculateQLimits(eigenvaluesNoResidual, 6)
@@ -553,3 +564,156 @@ func TestCalculateLimitsEdgeCases(t *testing.T) {
 		t.Errorf("Q limits should be 0 with empty eigenvalues, got 95%%=%f, 99%%=%f", qLimit95, qLimit99)
 	}
 }
+
+// outlierTestData returns centered data along one direction with a score-space
+// outlier (row 0, far along the direction) and a residual-space outlier (row 1, off
+// the direction), and the PCA fitted to it with one component
+func outlierTestData(t *testing.T) (*types.PCAResult, types.Matrix) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(3))
+	data := make(types.Matrix, 60)
+	for i := range data {
+		s := 3 * rng.NormFloat64()
+		switch i {
+		case 0:
+			s = 20
+		case 1:
+			s = 0
+		}
+		data[i] = []float64{s + 0.1*rng.NormFloat64(), s + 0.1*rng.NormFloat64(), s + 0.1*rng.NormFloat64()}
+	}
+	data[1][0] += 2
+	data[1][1] -= 2
+
+	result, err := NewPCAEngine().Fit(data, types.PCAConfig{Components: 1, MeanCenter: true, Method: "svd"})
+	if err != nil {
+		t.Fatalf("fit failed: %v", err)
+	}
+	centered := make(types.Matrix, len(data))
+	for i, row := range data {
+		centered[i] = make([]float64, len(row))
+		for j, v := range row {
+			centered[i][j] = v - result.Means[j]
+		}
+	}
+	return result, centered
+}
+
+func TestOutlierMethods(t *testing.T) {
+	result, data := outlierTestData(t)
+
+	tests := []struct {
+		method          string
+		score, residual bool
+	}{
+		{OutlierMethodT2, true, false},
+		{OutlierMethodMahalanobis, true, false},
+		{OutlierMethodSPE, false, true},
+		{OutlierMethodCombined, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			metrics, err := CalculateMetricsWithOutlierTest(result, data, OutlierTest{Method: tt.method})
+			if err != nil {
+				t.Fatalf("failed to calculate metrics: %v", err)
+			}
+			if metrics[0].IsOutlier != tt.score || metrics[1].IsOutlier != tt.residual {
+				t.Errorf("expected score-space outlier %v and residual-space outlier %v, got %v and %v",
+					tt.score, tt.residual, metrics[0].IsOutlier, metrics[1].IsOutlier)
+			}
+		})
+	}
+}
+
+func TestOutlierSignificance(t *testing.T) {
+	result, data := outlierTestData(t)
+
+	for _, method := range []string{OutlierMethodT2, OutlierMethodMahalanobis, OutlierMethodSPE, OutlierMethodCombined} {
+		// A stricter test, with a smaller significance level α, has higher limits and
+		// flags no more rows than a looser one
+		previous := -1
+		for _, alpha := range []float64{0.2, 0.05, 0.001, 1e-6} {
+			metrics, err := CalculateMetricsWithOutlierTest(result, data, OutlierTest{Method: method, Significance: alpha})
+			if err != nil {
+				t.Fatalf("%s at %g: %v", method, alpha, err)
+			}
+			flagged := 0
+			for _, m := range metrics {
+				if m.IsOutlier {
+					flagged++
+				}
+			}
+			if previous >= 0 && flagged > previous {
+				t.Errorf("%s: %d rows flagged at significance %g, more than %d at a looser level",
+					method, flagged, alpha, previous)
+			}
+			// The residual-space outlier dominates the discarded variance and so the SPE
+			// limit, which masks the small residuals of the other rows
+			if alpha == 0.2 && method != OutlierMethodSPE && flagged < 3 {
+				t.Errorf("%s: expected a loose test to flag more than the planted outliers, got %d", method, flagged)
+			}
+			previous = flagged
+		}
+	}
+
+	for _, test := range []OutlierTest{{Method: "iqr"}, {Significance: 1.5}, {Significance: -0.1}} {
+		if _, err := CalculateMetricsWithOutlierTest(result, data, test); err == nil {
+			t.Errorf("expected an error for outlier test %+v", test)
+		}
+	}
+}
+
+func TestInfluencePlot(t *testing.T) {
+	// 100 rows along (1, 1, 0) with small noise, fitted with one component, and three
+	// injected rows: far along the component, far off it and far in both
+	rng := rand.New(rand.NewSource(3))
+	var data types.Matrix
+	for i := 0; i < 100; i++ {
+		a := 3 * rng.NormFloat64()
+		data = append(data, []float64{a + 0.3*rng.NormFloat64(), a + 0.3*rng.NormFloat64(), 0.3 * rng.NormFloat64()})
+	}
+	data = append(data, []float64{15, 15, 0}, []float64{0, 0, 4}, []float64{15, 15, 4})
+	expected := map[int]string{100: InfluenceHighT2, 101: InfluenceHighQ, 102: InfluenceBoth}
+
+	processed, err := NewPreprocessor(true, false, false).FitTransform(data)
+	if err != nil {
+		t.Fatalf("preprocessing failed: %v", err)
+	}
+	result, err := NewPCAEngine().Fit(processed, types.PCAConfig{Components: 1, Method: "svd"})
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	metrics, err := CalculateMetricsFromPCAResult(result, processed)
+	if err != nil {
+		t.Fatalf("CalculateMetricsFromPCAResult failed: %v", err)
+	}
+
+	limits := CalculateDiagnosticLimits(result)
+	if limits.T2Limit95 <= 0 || limits.QLimit95 <= 0 || limits.T2Limit99 <= limits.T2Limit95 || limits.QLimit99 <= limits.QLimit95 {
+		t.Fatalf("unexpected limits %+v", limits)
+	}
+
+	points := InfluencePlot(metrics, limits)
+	if len(points) != len(data) {
+		t.Fatalf("expected %d points, got %d", len(data), len(points))
+	}
+	for i, p := range points {
+		if p.T2 != metrics[i].HotellingT2 || p.Q != metrics[i].RSS ||
+			p.T2Limit95 != limits.T2Limit95 || p.QLimit95 != limits.QLimit95 {
+			t.Fatalf("point %d does not match its metrics and limits: %+v", i, p)
+		}
+		if want, ok := expected[i]; ok && p.Status != want {
+			t.Errorf("row %d: expected status %s, got %s (T² %.3f, Q %.3f)", i, want, p.Status, p.T2, p.Q)
+		}
+		if p.Status == InfluenceBoth && (p.T2 <= p.T2Limit95 || p.Q <= p.QLimit95) {
+			t.Errorf("row %d is marked %s within a limit: %+v", i, InfluenceBoth, p)
+		}
+	}
+
+	// A limit of 0 is never exceeded
+	for _, p := range InfluencePlot(metrics, types.DiagnosticLimits{T2Limit95: limits.T2Limit95}) {
+		if p.Status == InfluenceHighQ || p.Status == InfluenceBoth {
+			t.Fatalf("expected no Q status without a Q limit, got %+v", p)
+		}
+	}
+}
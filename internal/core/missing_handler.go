@@ -9,20 +9,93 @@ package core
 import (
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 
 	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/stat"
 )
 
+// Parameters for the knn and iterative strategies
+const (
+	// DefaultImputeNeighbors is the number of rows averaged by the knn strategy
+	DefaultImputeNeighbors = 5
+	// iterativeMaxIterations bounds the rounds of the iterative strategy
+	iterativeMaxIterations = 100
+	// iterativeTolerance stops the iterative strategy when no imputed value moves by
+	// more than this fraction of its column's standard deviation
+	iterativeTolerance = 1e-4
+)
+
 // MissingValueHandler handles missing values in data matrices
 type MissingValueHandler struct {
-	strategy types.MissingValueStrategy
+	strategy  types.MissingValueStrategy
+	neighbors int
+	groups    []string // Group of each row for group-wise mean and median imputation
+	// Strategy of each column that overrides strategy, by column index
+	columnStrategies map[int]types.MissingValueStrategy
 }
 
 // NewMissingValueHandler creates a new missing value handler
 func NewMissingValueHandler(strategy types.MissingValueStrategy) *MissingValueHandler {
-	return &MissingValueHandler{strategy: strategy}
+	return &MissingValueHandler{strategy: strategy, neighbors: DefaultImputeNeighbors}
+}
+
+// SetNeighbors sets the number of rows averaged by the knn strategy
+func (h *MissingValueHandler) SetNeighbors(k int) error {
+	if k < 1 {
+		return fmt.Errorf("number of neighbors must be at least 1, got %d", k)
+	}
+	h.neighbors = k
+	return nil
+}
+
+// SetGroups makes the mean and median strategies impute each missing value with the
+// statistic of its column over the rows of the same group, one label per row. Rows
+// with an empty label, and groups without an observed value in the column, use the
+// statistic over all rows.
+func (h *MissingValueHandler) SetGroups(groups []string) error {
+	if h.strategy != types.MissingMean && h.strategy != types.MissingMedian {
+		return fmt.Errorf("group-wise imputation needs the mean or median strategy, not %s", h.strategy)
+	}
+	h.groups = groups
+	return nil
+}
+
+// SetColumnStrategies overrides the strategy for individual columns, by column index.
+// Only strategies that fill a column from its own values can be set per column: mean,
+// median, mode, forward and interpolate. Columns without an override use the handler's
+// strategy, which is applied after the overrides, so that the knn and iterative
+// strategies see the overridden columns filled in.
+func (h *MissingValueHandler) SetColumnStrategies(strategies map[int]types.MissingValueStrategy) error {
+	for col, strategy := range strategies {
+		if !ColumnLocalStrategy(strategy) {
+			return fmt.Errorf("column %d: %s cannot be set for a single column, use mean, median, mode, forward or interpolate",
+				col+1, strategy)
+		}
+	}
+	h.columnStrategies = strategies
+	return nil
+}
+
+// ColumnLocalStrategy reports whether a strategy fills each column from its own
+// values only, so that it can be set for a single column
+func ColumnLocalStrategy(strategy types.MissingValueStrategy) bool {
+	switch strategy {
+	case types.MissingMean, types.MissingMedian, types.MissingMode, types.MissingForward,
+		types.MissingInterpolate:
+		return true
+	}
+	return false
+}
+
+// StrategyFor returns the strategy used for a column
+func (h *MissingValueHandler) StrategyFor(col int) types.MissingValueStrategy {
+	if strategy, ok := h.columnStrategies[col]; ok {
+		return strategy
+	}
+	return h.strategy
 }
 
 // HandleMissingValues processes missing values according to the specified strategy
@@ -32,22 +105,175 @@ func (h *MissingValueHandler) HandleMissingValues(data types.Matrix, missingInfo
 		// No missing values, return data as-is
 		return data, nil
 	}
+	if len(h.columnStrategies) > 0 {
+		return h.imputeByColumn(data, missingInfo, selectedCols)
+	}
 
 	switch h.strategy {
 	case types.MissingDrop:
 		return h.dropRows(data, missingInfo.RowsAffected)
 
 	case types.MissingMean:
+		if h.groups != nil {
+			return h.imputeByGroup(data, missingInfo, true)
+		}
 		return h.imputeWithMean(data, missingInfo, selectedCols)
 
 	case types.MissingMedian:
+		if h.groups != nil {
+			return h.imputeByGroup(data, missingInfo, false)
+		}
 		return h.imputeWithMedian(data, missingInfo, selectedCols)
 
+	case types.MissingKNN:
+		return h.imputeWithKNN(data, missingInfo)
+
+	case types.MissingIterative:
+		return h.imputeIterative(data, missingInfo)
+
+	case types.MissingInterpolate:
+		return h.imputeByInterpolation(data, missingInfo)
+
+	case types.MissingMode:
+		return h.imputeWithMode(data, missingInfo)
+
+	case types.MissingForward:
+		return h.imputeForward(data, missingInfo)
+
 	default:
 		return nil, fmt.Errorf("unsupported missing value strategy: %s", h.strategy)
 	}
 }
 
+// ImputedCell records a missing value replaced during imputation
+type ImputedCell struct {
+	Row    int
+	Column int
+	Value  float64
+}
+
+// DroppedRow records a row removed by the drop strategy and the columns whose
+// missing values caused the drop
+type DroppedRow struct {
+	Row     int
+	Columns []int
+}
+
+// MissingValueChanges lists every cell changed by missing value handling, with row
+// and column indices referring to the input matrix
+type MissingValueChanges struct {
+	Strategy types.MissingValueStrategy
+	// Strategy of each column that overrides Strategy, by column index
+	ColumnStrategies map[int]types.MissingValueStrategy
+	Imputed          []ImputedCell
+	Dropped          []DroppedRow
+}
+
+// StrategyFor returns the strategy that changed the cells of a column
+func (c *MissingValueChanges) StrategyFor(col int) types.MissingValueStrategy {
+	if strategy, ok := c.ColumnStrategies[col]; ok {
+		return strategy
+	}
+	return c.Strategy
+}
+
+// HandleMissingValuesWithChanges works like HandleMissingValues and also returns the
+// imputed cells or dropped rows, in row order, so the changes can be audited
+func (h *MissingValueHandler) HandleMissingValuesWithChanges(data types.Matrix, missingInfo *types.MissingValueInfo, selectedCols []int) (types.Matrix, *MissingValueChanges, error) {
+	cleanData, err := h.HandleMissingValues(data, missingInfo, selectedCols)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := &MissingValueChanges{Strategy: h.strategy, ColumnStrategies: h.columnStrategies}
+	if !missingInfo.HasMissing() {
+		return cleanData, changes, nil
+	}
+
+	columns := append([]int(nil), missingInfo.ColumnIndices...)
+	sort.Ints(columns)
+
+	// Rows are dropped for missing values in the columns of the drop strategy, and the
+	// rows kept move up in the clean data
+	cleanRow := 0
+	for row := range data {
+		var dropped DroppedRow
+		for _, col := range columns {
+			if math.IsNaN(data[row][col]) && changes.StrategyFor(col) == types.MissingDrop {
+				dropped.Columns = append(dropped.Columns, col)
+			}
+		}
+		if dropped.Columns != nil {
+			dropped.Row = row
+			changes.Dropped = append(changes.Dropped, dropped)
+			continue
+		}
+		for _, col := range columns {
+			if math.IsNaN(data[row][col]) {
+				changes.Imputed = append(changes.Imputed, ImputedCell{Row: row, Column: col, Value: cleanData[cleanRow][col]})
+			}
+		}
+		cleanRow++
+	}
+	return cleanData, changes, nil
+}
+
+// imputeByColumn fills the columns with their own strategies first and then the
+// remaining columns with the handler's strategy
+func (h *MissingValueHandler) imputeByColumn(data types.Matrix, missingInfo *types.MissingValueInfo, selectedCols []int) (types.Matrix, error) {
+	columnsByStrategy := make(map[types.MissingValueStrategy][]int)
+	var remaining []int
+	for _, col := range missingInfo.ColumnIndices {
+		if strategy, ok := h.columnStrategies[col]; ok {
+			columnsByStrategy[strategy] = append(columnsByStrategy[strategy], col)
+		} else {
+			remaining = append(remaining, col)
+		}
+	}
+
+	imputedData := copyMatrix(data)
+	for strategy, columns := range columnsByStrategy {
+		handler := &MissingValueHandler{strategy: strategy, neighbors: h.neighbors}
+		if strategy == types.MissingMean || strategy == types.MissingMedian {
+			handler.groups = h.groups
+		}
+		filled, err := handler.HandleMissingValues(data, columnMissingInfo(data, columns), selectedCols)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range columns {
+			for row := range data {
+				imputedData[row][col] = filled[row][col]
+			}
+		}
+	}
+
+	if len(remaining) == 0 {
+		return imputedData, nil
+	}
+	handler := &MissingValueHandler{strategy: h.strategy, neighbors: h.neighbors, groups: h.groups}
+	return handler.HandleMissingValues(imputedData, columnMissingInfo(imputedData, remaining), selectedCols)
+}
+
+// columnMissingInfo describes the missing values of data in the given columns
+func columnMissingInfo(data types.Matrix, columns []int) *types.MissingValueInfo {
+	info := &types.MissingValueInfo{ColumnIndices: columns, MissingByColumn: make(map[int]int)}
+	for row := range data {
+		missing := false
+		for _, col := range columns {
+			if math.IsNaN(data[row][col]) {
+				info.MissingByColumn[col]++
+				info.TotalMissing++
+				missing = true
+			}
+		}
+		if missing {
+			info.RowsAffected = append(info.RowsAffected, row)
+		}
+	}
+	return info
+}
+
 // dropRows removes rows that contain missing values in selected columns
 func (h *MissingValueHandler) dropRows(data types.Matrix, rowsToRemove []int) (types.Matrix, error) {
 	if len(rowsToRemove) == 0 {
@@ -131,6 +357,341 @@ func (h *MissingValueHandler) imputeWithMedian(data types.Matrix, missingInfo *t
 	return imputedData, nil
 }
 
+// imputeByGroup replaces missing values with the mean or median of their column over
+// the rows of the same group, falling back to the statistic over all rows for rows
+// without a group and groups without an observed value in the column
+func (h *MissingValueHandler) imputeByGroup(data types.Matrix, missingInfo *types.MissingValueInfo, calculateMean bool) (types.Matrix, error) {
+	if len(h.groups) != len(data) {
+		return nil, fmt.Errorf("got %d group labels for %d rows", len(h.groups), len(data))
+	}
+	global := h.calculateColumnStatistics(data, missingInfo.ColumnIndices, calculateMean)
+
+	rowsByGroup := make(map[string][]int)
+	for row, group := range h.groups {
+		if group != "" {
+			rowsByGroup[group] = append(rowsByGroup[group], row)
+		}
+	}
+
+	imputedData := copyMatrix(data)
+	for _, col := range missingInfo.ColumnIndices {
+		for row := range data {
+			if math.IsNaN(data[row][col]) {
+				imputedData[row][col] = global[col]
+			}
+		}
+	}
+	for _, rows := range rowsByGroup {
+		group := make(types.Matrix, len(rows))
+		for k, row := range rows {
+			group[k] = data[row]
+		}
+		for _, col := range missingInfo.ColumnIndices {
+			observed := slices.ContainsFunc(group, func(row []float64) bool { return !math.IsNaN(row[col]) })
+			if !observed {
+				continue
+			}
+			value := h.calculateColumnStatistics(group, []int{col}, calculateMean)[col]
+			for _, row := range rows {
+				if math.IsNaN(data[row][col]) {
+					imputedData[row][col] = value
+				}
+			}
+		}
+	}
+	return imputedData, nil
+}
+
+// imputeWithKNN replaces each missing value with the mean of that column over the
+// nearest rows where it is observed. Distances are Euclidean over the columns observed
+// in both rows, with columns standardized and the sum scaled up for missing columns.
+// Values without any donor row fall back to the column mean.
+func (h *MissingValueHandler) imputeWithKNN(data types.Matrix, missingInfo *types.MissingValueInfo) (types.Matrix, error) {
+	nCols := len(data[0])
+	colMeans := h.calculateColumnStatistics(data, allColumns(nCols), true)
+	colStds := observedStdDevs(data)
+
+	imputedData := copyMatrix(data)
+
+	type neighbor struct {
+		row      int
+		distance float64
+	}
+	for _, row := range missingInfo.RowsAffected {
+		// Distances from this row to every other row
+		var candidates []neighbor
+		for other := range data {
+			if other == row {
+				continue
+			}
+			sum, shared := 0.0, 0
+			for j := 0; j < nCols; j++ {
+				a, b := data[row][j], data[other][j]
+				if math.IsNaN(a) || math.IsNaN(b) {
+					continue
+				}
+				d := (a - b) / colStds[j]
+				sum += d * d
+				shared++
+			}
+			if shared > 0 {
+				candidates = append(candidates, neighbor{other, math.Sqrt(sum * float64(nCols) / float64(shared))})
+			}
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].distance < candidates[b].distance
+		})
+
+		for _, col := range missingInfo.ColumnIndices {
+			if !math.IsNaN(data[row][col]) {
+				continue
+			}
+			sum, count := 0.0, 0
+			for _, c := range candidates {
+				if v := data[c.row][col]; !math.IsNaN(v) {
+					sum += v
+					count++
+					if count == h.neighbors {
+						break
+					}
+				}
+			}
+			if count > 0 {
+				imputedData[row][col] = sum / float64(count)
+			} else {
+				imputedData[row][col] = colMeans[col]
+			}
+		}
+	}
+
+	return imputedData, nil
+}
+
+// imputeIterative starts from mean imputation and repeatedly predicts the missing
+// values of each column by least squares regression on all other columns, fitted on
+// the rows where the column is observed, until the imputed values are stable
+func (h *MissingValueHandler) imputeIterative(data types.Matrix, missingInfo *types.MissingValueInfo) (types.Matrix, error) {
+	imputedData, err := h.imputeWithMean(data, missingInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+	nCols := len(data[0])
+	if nCols < 2 {
+		return imputedData, nil
+	}
+	colStds := observedStdDevs(data)
+
+	for iter := 0; iter < iterativeMaxIterations; iter++ {
+		maxChange := 0.0
+		for _, col := range missingInfo.ColumnIndices {
+			beta, err := regressColumn(imputedData, data, col)
+			if err != nil {
+				return nil, fmt.Errorf("iterative imputation of column %d: %w", col+1, err)
+			}
+			for row := range data {
+				if !math.IsNaN(data[row][col]) {
+					continue
+				}
+				prediction := beta[0]
+				k := 1
+				for j := 0; j < nCols; j++ {
+					if j != col {
+						prediction += beta[k] * imputedData[row][j]
+						k++
+					}
+				}
+				maxChange = math.Max(maxChange, math.Abs(prediction-imputedData[row][col])/colStds[col])
+				imputedData[row][col] = prediction
+			}
+		}
+		if maxChange < iterativeTolerance {
+			break
+		}
+	}
+
+	return imputedData, nil
+}
+
+// regressColumn fits column col of current on an intercept and the other columns,
+// using the rows where col is observed in original. A tiny ridge term keeps the
+// normal equations solvable for collinear columns.
+func regressColumn(current, original types.Matrix, col int) ([]float64, error) {
+	nCols := len(current[0])
+	var xs []float64
+	var ys []float64
+	nRows := 0
+	for row := range original {
+		if math.IsNaN(original[row][col]) {
+			continue
+		}
+		xs = append(xs, 1)
+		for j := 0; j < nCols; j++ {
+			if j != col {
+				xs = append(xs, current[row][j])
+			}
+		}
+		ys = append(ys, current[row][col])
+		nRows++
+	}
+	if nRows == 0 {
+		return nil, fmt.Errorf("no observed values")
+	}
+
+	X := mat.NewDense(nRows, nCols, xs)
+	y := mat.NewVecDense(nRows, ys)
+
+	var xtx mat.SymDense
+	xtx.SymOuterK(1, X.T())
+	ridge := 1e-8 * mat.Trace(&xtx) / float64(nCols)
+	for i := 0; i < nCols; i++ {
+		xtx.SetSym(i, i, xtx.At(i, i)+ridge)
+	}
+	var xty mat.VecDense
+	xty.MulVec(X.T(), y)
+
+	var chol mat.Cholesky
+	if ok := chol.Factorize(&xtx); !ok {
+		return nil, fmt.Errorf("regression is singular")
+	}
+	var beta mat.VecDense
+	if err := chol.SolveVecTo(&beta, &xty); err != nil {
+		return nil, err
+	}
+	return beta.RawVector().Data, nil
+}
+
+// imputeByInterpolation fills missing values linearly between the nearest observed
+// values above and below in the same column, in row order. Values before the first
+// or after the last observation take that observation's value, and columns without
+// observations are filled with 0.
+func (h *MissingValueHandler) imputeByInterpolation(data types.Matrix, missingInfo *types.MissingValueInfo) (types.Matrix, error) {
+	imputedData := copyMatrix(data)
+
+	for _, col := range missingInfo.ColumnIndices {
+		prev := -1
+		for row := 0; row <= len(data); row++ {
+			if row < len(data) && math.IsNaN(data[row][col]) {
+				continue
+			}
+			// Fill the gap between prev and row
+			for gap := prev + 1; gap < row; gap++ {
+				switch {
+				case prev < 0 && row == len(data):
+					imputedData[gap][col] = 0
+				case prev < 0:
+					imputedData[gap][col] = data[row][col]
+				case row == len(data):
+					imputedData[gap][col] = data[prev][col]
+				default:
+					t := float64(gap-prev) / float64(row-prev)
+					imputedData[gap][col] = data[prev][col] + t*(data[row][col]-data[prev][col])
+				}
+			}
+			prev = row
+		}
+	}
+
+	return imputedData, nil
+}
+
+// imputeWithMode replaces missing values with the most frequent observed value of
+// their column, the smallest of equally frequent values, or 0 for columns without
+// observations
+func (h *MissingValueHandler) imputeWithMode(data types.Matrix, missingInfo *types.MissingValueInfo) (types.Matrix, error) {
+	imputedData := copyMatrix(data)
+
+	for _, col := range missingInfo.ColumnIndices {
+		counts := make(map[float64]int)
+		for row := range data {
+			if v := data[row][col]; !math.IsNaN(v) {
+				counts[v]++
+			}
+		}
+		mode, best := 0.0, 0
+		for v, n := range counts {
+			if n > best || (n == best && v < mode) {
+				mode, best = v, n
+			}
+		}
+		for row := range data {
+			if math.IsNaN(data[row][col]) {
+				imputedData[row][col] = mode
+			}
+		}
+	}
+
+	return imputedData, nil
+}
+
+// imputeForward replaces missing values with the last observed value above them in
+// the same column, in row order. Values before the first observation take that
+// observation's value, and columns without observations are filled with 0.
+func (h *MissingValueHandler) imputeForward(data types.Matrix, missingInfo *types.MissingValueInfo) (types.Matrix, error) {
+	imputedData := copyMatrix(data)
+
+	for _, col := range missingInfo.ColumnIndices {
+		last := math.NaN()
+		for row := range data {
+			if v := data[row][col]; !math.IsNaN(v) {
+				last = v
+				break
+			}
+		}
+		if math.IsNaN(last) {
+			last = 0
+		}
+		for row := range data {
+			if v := data[row][col]; math.IsNaN(v) {
+				imputedData[row][col] = last
+			} else {
+				last = v
+			}
+		}
+	}
+
+	return imputedData, nil
+}
+
+// copyMatrix returns a deep copy of data
+func copyMatrix(data types.Matrix) types.Matrix {
+	result := make(types.Matrix, len(data))
+	for i := range data {
+		result[i] = make([]float64, len(data[i]))
+		copy(result[i], data[i])
+	}
+	return result
+}
+
+// allColumns returns the indices 0..n-1
+func allColumns(n int) []int {
+	cols := make([]int, n)
+	for i := range cols {
+		cols[i] = i
+	}
+	return cols
+}
+
+// observedStdDevs returns the standard deviation of the observed values in each
+// column, or 1 where it is zero or undefined so it can be used as a divisor
+func observedStdDevs(data types.Matrix) []float64 {
+	stds := make([]float64, len(data[0]))
+	for j := range stds {
+		var values []float64
+		for _, row := range data {
+			if !math.IsNaN(row[j]) {
+				values = append(values, row[j])
+			}
+		}
+		stds[j] = 1
+		if len(values) > 1 {
+			if sd := stat.StdDev(values, nil); sd > 0 {
+				stds[j] = sd
+			}
+		}
+	}
+	return stds
+}
+
 // calculateColumnStatistics calculates mean or median for specified columns
 func (h *MissingValueHandler) calculateColumnStatistics(data types.Matrix, columns []int, calculateMean bool) map[int]float64 {
 	stats := make(map[int]float64)
@@ -312,3 +312,285 @@ func TestCalculateColumnStatistics(t *testing.T) {
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && s[:len(substr)] == substr || len(s) > len(substr) && contains(s[1:], substr)
 }
+
+func TestMissingValueHandler_Changes(t *testing.T) {
+	data := types.Matrix{
+		{1.0, math.NaN(), 3.0},
+		{4.0, 5.0, math.NaN()},
+		{7.0, math.NaN(), math.NaN()},
+		{10.0, 11.0, 12.0},
+	}
+	missingInfo := &types.MissingValueInfo{
+		ColumnIndices:   []int{1, 2},
+		RowsAffected:    []int{0, 1, 2},
+		TotalMissing:    4,
+		MissingByColumn: map[int]int{1: 2, 2: 2},
+	}
+
+	t.Run("Impute", func(t *testing.T) {
+		result, changes, err := NewMissingValueHandler(types.MissingMean).
+			HandleMissingValuesWithChanges(data, missingInfo, []int{0, 1, 2})
+		if err != nil {
+			t.Fatalf("HandleMissingValuesWithChanges failed: %v", err)
+		}
+		if len(changes.Imputed) != missingInfo.TotalMissing {
+			t.Fatalf("Expected %d imputed cells, got %d", missingInfo.TotalMissing, len(changes.Imputed))
+		}
+		for _, cell := range changes.Imputed {
+			if !math.IsNaN(data[cell.Row][cell.Column]) {
+				t.Errorf("Cell [%d][%d] was not missing", cell.Row, cell.Column)
+			}
+			if cell.Value != result[cell.Row][cell.Column] {
+				t.Errorf("Cell [%d][%d] recorded %g, result has %g",
+					cell.Row, cell.Column, cell.Value, result[cell.Row][cell.Column])
+			}
+		}
+		if first := changes.Imputed[0]; first.Row != 0 || first.Column != 1 || first.Value != 8.0 {
+			t.Errorf("Expected first change [0][1] = 8, got %+v", first)
+		}
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		_, changes, err := NewMissingValueHandler(types.MissingDrop).
+			HandleMissingValuesWithChanges(data, missingInfo, []int{0, 1, 2})
+		if err != nil {
+			t.Fatalf("HandleMissingValuesWithChanges failed: %v", err)
+		}
+		if len(changes.Imputed) != 0 || len(changes.Dropped) != 3 {
+			t.Fatalf("Expected 3 dropped rows and no imputed cells, got %+v", changes)
+		}
+		if last := changes.Dropped[2]; last.Row != 2 || len(last.Columns) != 2 {
+			t.Errorf("Expected row 2 dropped for columns 1 and 2, got %+v", last)
+		}
+	})
+}
+
+func TestMissingValueHandler_ModelStrategies(t *testing.T) {
+	nan := math.NaN()
+	infoFor := func(data types.Matrix) *types.MissingValueInfo {
+		info := &types.MissingValueInfo{MissingByColumn: map[int]int{}}
+		rows := map[int]bool{}
+		for i, row := range data {
+			for j, v := range row {
+				if math.IsNaN(v) {
+					if info.MissingByColumn[j] == 0 {
+						info.ColumnIndices = append(info.ColumnIndices, j)
+					}
+					info.MissingByColumn[j]++
+					info.TotalMissing++
+					if !rows[i] {
+						rows[i] = true
+						info.RowsAffected = append(info.RowsAffected, i)
+					}
+				}
+			}
+		}
+		return info
+	}
+
+	t.Run("KNN", func(t *testing.T) {
+		// Two well separated clusters; the missing value belongs to the second
+		data := types.Matrix{
+			{1.0, 1.0, 10.0},
+			{1.1, 0.9, 11.0},
+			{0.9, 1.1, 12.0},
+			{9.0, 9.0, 100.0},
+			{9.1, 8.9, 110.0},
+			{8.9, 9.1, nan},
+		}
+		handler := NewMissingValueHandler(types.MissingKNN)
+		if err := handler.SetNeighbors(2); err != nil {
+			t.Fatal(err)
+		}
+		result, err := handler.HandleMissingValues(data, infoFor(data), nil)
+		if err != nil {
+			t.Fatalf("knn imputation failed: %v", err)
+		}
+		if result[5][2] != 105.0 {
+			t.Errorf("Expected the mean of the two nearest rows (105), got %g", result[5][2])
+		}
+		if err := handler.SetNeighbors(0); err == nil {
+			t.Error("Expected error for zero neighbors")
+		}
+	})
+
+	t.Run("Iterative", func(t *testing.T) {
+		// Column 1 is an exact linear function of column 0
+		data := types.Matrix{
+			{1, 3}, {2, 5}, {3, nan}, {4, 9}, {5, 11}, {nan, 13},
+		}
+		result, err := NewMissingValueHandler(types.MissingIterative).HandleMissingValues(data, infoFor(data), nil)
+		if err != nil {
+			t.Fatalf("iterative imputation failed: %v", err)
+		}
+		if math.Abs(result[2][1]-7) > 1e-3 || math.Abs(result[5][0]-6) > 1e-3 {
+			t.Errorf("Expected 7 and 6 from the linear relation, got %g and %g", result[2][1], result[5][0])
+		}
+	})
+
+	t.Run("Interpolate", func(t *testing.T) {
+		data := types.Matrix{
+			{nan, 1}, {2, nan}, {nan, nan}, {8, 4}, {nan, 5},
+		}
+		result, err := NewMissingValueHandler(types.MissingInterpolate).HandleMissingValues(data, infoFor(data), nil)
+		if err != nil {
+			t.Fatalf("interpolation failed: %v", err)
+		}
+		want := types.Matrix{
+			{2, 1}, {2, 2}, {5, 3}, {8, 4}, {8, 5},
+		}
+		for i := range want {
+			for j := range want[i] {
+				if math.Abs(result[i][j]-want[i][j]) > 1e-12 {
+					t.Errorf("[%d][%d] = %g, want %g", i, j, result[i][j], want[i][j])
+				}
+			}
+		}
+	})
+}
+
+func TestMissingValueHandler_Groups(t *testing.T) {
+	// Heights of two groups with different means; group c has no observed height
+	nan := math.NaN()
+	data := types.Matrix{
+		{150, 1}, {160, 2}, {nan, 3}, {170, 4},
+		{180, 5}, {190, 6}, {nan, 7}, {200, 8},
+		{nan, 9}, {nan, 10},
+	}
+	groups := []string{"a", "a", "a", "a", "b", "b", "b", "b", "c", ""}
+	missingInfo := &types.MissingValueInfo{
+		ColumnIndices:   []int{0},
+		RowsAffected:    []int{2, 6, 8, 9},
+		TotalMissing:    4,
+		MissingByColumn: map[int]int{0: 4},
+	}
+
+	tests := []struct {
+		strategy types.MissingValueStrategy
+		want     map[int]float64 // Imputed row to value
+	}{
+		// Group means 160 and 190, overall mean 175
+		{types.MissingMean, map[int]float64{2: 160, 6: 190, 8: 175, 9: 175}},
+		// Group medians 160 and 190, overall median 175
+		{types.MissingMedian, map[int]float64{2: 160, 6: 190, 8: 175, 9: 175}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			handler := NewMissingValueHandler(tt.strategy)
+			if err := handler.SetGroups(groups); err != nil {
+				t.Fatalf("SetGroups failed: %v", err)
+			}
+			result, err := handler.HandleMissingValues(data, missingInfo, nil)
+			if err != nil {
+				t.Fatalf("HandleMissingValues failed: %v", err)
+			}
+			for row, want := range tt.want {
+				if got := result[row][0]; math.Abs(got-want) > 1e-12 {
+					t.Errorf("row %d (group %q): expected %g, got %g", row, groups[row], want, got)
+				}
+			}
+			if result[0][0] != 150 || !math.IsNaN(data[2][0]) {
+				t.Error("expected observed values kept and the input unchanged")
+			}
+		})
+	}
+
+	handler := NewMissingValueHandler(types.MissingMean)
+	if err := handler.SetGroups(groups[:3]); err != nil {
+		t.Fatalf("SetGroups failed: %v", err)
+	}
+	if _, err := handler.HandleMissingValues(data, missingInfo, nil); err == nil {
+		t.Error("expected an error for fewer group labels than rows")
+	}
+	if err := NewMissingValueHandler(types.MissingKNN).SetGroups(groups); err == nil {
+		t.Error("expected an error for group-wise knn imputation")
+	}
+}
+
+func TestMissingValueHandler_ColumnStrategies(t *testing.T) {
+	nan := math.NaN()
+	data := types.Matrix{
+		{1, 10, 7, 0},
+		{nan, nan, 7, 1},
+		{3, 30, nan, 2},
+		{8, nan, 9, nan},
+		{nan, 40, 9, 4},
+		{4, nan, 5, 5},
+	}
+	info := columnMissingInfo(data, allColumns(4))
+
+	t.Run("overrides and fallback", func(t *testing.T) {
+		// Column 0 by mean, column 1 forward filled, column 2 by mode and column 3 by
+		// the median fallback, all in one pass
+		handler := NewMissingValueHandler(types.MissingMedian)
+		err := handler.SetColumnStrategies(map[int]types.MissingValueStrategy{
+			0: types.MissingMean, 1: types.MissingForward, 2: types.MissingMode,
+		})
+		if err != nil {
+			t.Fatalf("SetColumnStrategies failed: %v", err)
+		}
+		result, changes, err := handler.HandleMissingValuesWithChanges(data, info, nil)
+		if err != nil {
+			t.Fatalf("HandleMissingValues failed: %v", err)
+		}
+		want := map[[2]int]float64{
+			{1, 0}: 4, {4, 0}: 4, // Mean of 1, 3, 8 and 4
+			{1, 1}: 10, {3, 1}: 30, {5, 1}: 40, // Last value above
+			{2, 2}: 7, // 7 and 9 appear twice, and the smaller is taken
+			{3, 3}: 2, // Median of 0, 1, 2, 4 and 5
+		}
+		if len(changes.Imputed) != len(want) {
+			t.Errorf("expected %d imputed cells, got %d", len(want), len(changes.Imputed))
+		}
+		for _, cell := range changes.Imputed {
+			w, ok := want[[2]int{cell.Row, cell.Column}]
+			if !ok || math.Abs(result[cell.Row][cell.Column]-w) > 1e-12 || cell.Value != result[cell.Row][cell.Column] {
+				t.Errorf("[%d][%d] = %g, want %g", cell.Row, cell.Column, result[cell.Row][cell.Column], w)
+			}
+		}
+		if got := changes.StrategyFor(1); got != types.MissingForward {
+			t.Errorf("expected strategy forward for column 1, got %s", got)
+		}
+		if got := changes.StrategyFor(3); got != types.MissingMedian {
+			t.Errorf("expected strategy median for column 3, got %s", got)
+		}
+	})
+
+	t.Run("drop fallback", func(t *testing.T) {
+		// Rows missing column 2 or 3 are dropped after the other columns are imputed
+		handler := NewMissingValueHandler(types.MissingDrop)
+		err := handler.SetColumnStrategies(map[int]types.MissingValueStrategy{
+			0: types.MissingMedian, 1: types.MissingInterpolate,
+		})
+		if err != nil {
+			t.Fatalf("SetColumnStrategies failed: %v", err)
+		}
+		result, changes, err := handler.HandleMissingValuesWithChanges(data, info, nil)
+		if err != nil {
+			t.Fatalf("HandleMissingValues failed: %v", err)
+		}
+		if len(result) != 4 || len(changes.Dropped) != 2 || changes.Dropped[0].Row != 2 || changes.Dropped[1].Row != 3 {
+			t.Fatalf("expected rows 2 and 3 dropped, got %d rows and %+v", len(result), changes.Dropped)
+		}
+		// Row 4 of the input is row 2 of the result
+		want := []ImputedCell{{1, 0, 3.5}, {1, 1, 20}, {4, 0, 3.5}, {5, 1, 40}}
+		if len(changes.Imputed) != len(want) {
+			t.Fatalf("expected %d imputed cells, got %+v", len(want), changes.Imputed)
+		}
+		for i, cell := range changes.Imputed {
+			if cell != want[i] {
+				t.Errorf("imputed cell %d: expected %+v, got %+v", i, want[i], cell)
+			}
+		}
+		if result[2][0] != 3.5 || result[3][1] != 40 {
+			t.Errorf("expected the imputed values in the kept rows, got %v", result)
+		}
+	})
+
+	for _, strategy := range []types.MissingValueStrategy{types.MissingKNN, types.MissingIterative, types.MissingDrop} {
+		err := NewMissingValueHandler(types.MissingMean).SetColumnStrategies(map[int]types.MissingValueStrategy{0: strategy})
+		if err == nil {
+			t.Errorf("expected an error for the %s strategy on a single column", strategy)
+		}
+	}
+}
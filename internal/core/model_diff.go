@@ -0,0 +1,217 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// Verdicts reported by CompareModels
+const (
+	DiffVerdictIdentical  = "identical"
+	DiffVerdictEquivalent = "equivalent"
+	DiffVerdictSimilar    = "similar"
+	DiffVerdictDifferent  = "different"
+)
+
+// ComponentDiff describes how a single principal component differs between two models
+type ComponentDiff struct {
+	Component              string  `json:"component"`
+	LoadingCorrelation     float64 `json:"loading_correlation"` // Pearson correlation after sign alignment
+	RMSDifference          float64 `json:"rms_difference"`      // RMS of loading differences after sign alignment
+	SignFlipped            bool    `json:"sign_flipped"`        // True if model B's component was negated to align
+	ExplainedVarianceA     float64 `json:"explained_variance_a"`
+	ExplainedVarianceB     float64 `json:"explained_variance_b"`
+	ExplainedVarianceDelta float64 `json:"explained_variance_delta"` // B - A, in percent
+}
+
+// ModelDiff summarizes the differences between two PCA models
+type ModelDiff struct {
+	ComponentsA        int             `json:"components_a"`
+	ComponentsB        int             `json:"components_b"`
+	ComparedComponents int             `json:"compared_components"`
+	Features           []string        `json:"features"`
+	Components         []ComponentDiff `json:"components"`
+	MinCorrelation     float64         `json:"min_correlation"`
+	MaxRMSDifference   float64         `json:"max_rms_difference"`
+	Verdict            string          `json:"verdict"`
+}
+
+// CompareModels compares the loadings and explained variance of two exported models.
+// Features are matched by label, and only the overlapping components are compared.
+// Component signs are arbitrary in PCA, so each component of model B is sign-aligned
+// to model A before the loading correlation and RMS difference are computed.
+func CompareModels(a, b *types.PCAOutputData) (*ModelDiff, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("both models are required")
+	}
+
+	loadingsA, loadingsB := a.Model.Loadings, b.Model.Loadings
+	if len(loadingsA) == 0 || len(loadingsB) == 0 {
+		return nil, fmt.Errorf("models must contain loadings")
+	}
+
+	// Match features by label, falling back to position when labels are absent
+	var features []string
+	var rowsA, rowsB []int
+	if len(a.Model.FeatureLabels) > 0 && len(b.Model.FeatureLabels) > 0 {
+		indexB := make(map[string]int, len(b.Model.FeatureLabels))
+		for i, label := range b.Model.FeatureLabels {
+			indexB[label] = i
+		}
+		for i, label := range a.Model.FeatureLabels {
+			if j, ok := indexB[label]; ok && i < len(loadingsA) && j < len(loadingsB) {
+				features = append(features, label)
+				rowsA = append(rowsA, i)
+				rowsB = append(rowsB, j)
+			}
+		}
+		if len(features) == 0 {
+			return nil, fmt.Errorf("models have no features in common")
+		}
+	} else {
+		if len(loadingsA) != len(loadingsB) {
+			return nil, fmt.Errorf("models have different numbers of features (%d vs %d) and no feature labels",
+				len(loadingsA), len(loadingsB))
+		}
+		for i := range loadingsA {
+			features = append(features, fmt.Sprintf("Feature_%d", i+1))
+			rowsA = append(rowsA, i)
+			rowsB = append(rowsB, i)
+		}
+	}
+
+	compsA, compsB := len(loadingsA[0]), len(loadingsB[0])
+	nComp := compsA
+	if compsB < nComp {
+		nComp = compsB
+	}
+
+	diff := &ModelDiff{
+		ComponentsA:        compsA,
+		ComponentsB:        compsB,
+		ComparedComponents: nComp,
+		Features:           features,
+		Components:         make([]ComponentDiff, nComp),
+		MinCorrelation:     1.0,
+	}
+
+	for k := 0; k < nComp; k++ {
+		x := make([]float64, len(rowsA))
+		y := make([]float64, len(rowsB))
+		dot := 0.0
+		for i := range rowsA {
+			x[i] = loadingsA[rowsA[i]][k]
+			y[i] = loadingsB[rowsB[i]][k]
+			dot += x[i] * y[i]
+		}
+
+		// Align the sign of B's component with A's
+		flipped := dot < 0
+		if flipped {
+			for i := range y {
+				y[i] = -y[i]
+			}
+		}
+
+		sumSq := 0.0
+		for i := range x {
+			d := x[i] - y[i]
+			sumSq += d * d
+		}
+		rms := math.Sqrt(sumSq / float64(len(x)))
+
+		corr := loadingCorrelation(x, y)
+
+		label := fmt.Sprintf("PC%d", k+1)
+		if k < len(a.Model.ComponentLabels) {
+			label = a.Model.ComponentLabels[k]
+		}
+
+		cd := ComponentDiff{
+			Component:          label,
+			LoadingCorrelation: corr,
+			RMSDifference:      rms,
+			SignFlipped:        flipped,
+		}
+		if k < len(a.Model.ExplainedVarianceRatio) {
+			cd.ExplainedVarianceA = a.Model.ExplainedVarianceRatio[k]
+		}
+		if k < len(b.Model.ExplainedVarianceRatio) {
+			cd.ExplainedVarianceB = b.Model.ExplainedVarianceRatio[k]
+		}
+		cd.ExplainedVarianceDelta = cd.ExplainedVarianceB - cd.ExplainedVarianceA
+		diff.Components[k] = cd
+
+		if corr < diff.MinCorrelation {
+			diff.MinCorrelation = corr
+		}
+		if rms > diff.MaxRMSDifference {
+			diff.MaxRMSDifference = rms
+		}
+	}
+
+	diff.Verdict = diffVerdict(diff)
+	return diff, nil
+}
+
+// loadingCorrelation returns the Pearson correlation of two loading vectors.
+// Constant vectors are treated as perfectly correlated when they are equal.
+func loadingCorrelation(x, y []float64) float64 {
+	n := float64(len(x))
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		for i := range x {
+			if math.Abs(x[i]-y[i]) > 1e-12 {
+				return 0
+			}
+		}
+		return 1
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// diffVerdict summarizes a model comparison in a single word
+func diffVerdict(diff *ModelDiff) string {
+	const identicalTol = 1e-10
+
+	maxVarDelta := 0.0
+	for _, c := range diff.Components {
+		if d := math.Abs(c.ExplainedVarianceDelta); d > maxVarDelta {
+			maxVarDelta = d
+		}
+	}
+
+	switch {
+	case diff.MaxRMSDifference < identicalTol && maxVarDelta < identicalTol &&
+		diff.ComponentsA == diff.ComponentsB:
+		return DiffVerdictIdentical
+	case diff.MinCorrelation >= 0.99 && maxVarDelta < 1.0:
+		return DiffVerdictEquivalent
+	case diff.MinCorrelation >= 0.9:
+		return DiffVerdictSimilar
+	default:
+		return DiffVerdictDifferent
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// createDiffTestModel builds a minimal exported model for comparison tests
+func createDiffTestModel() *types.PCAOutputData {
+	return &types.PCAOutputData{
+		Model: types.ModelComponents{
+			Loadings: types.Matrix{
+				{0.5, 0.1, -0.3},
+				{0.6, -0.4, 0.2},
+				{-0.2, 0.7, 0.5},
+				{0.6, 0.6, -0.8},
+			},
+			ExplainedVarianceRatio: []float64{60, 25, 10},
+			ComponentLabels:        []string{"PC1", "PC2", "PC3"},
+			FeatureLabels:          []string{"a", "b", "c", "d"},
+		},
+	}
+}
+
+func TestCompareModelsIdentical(t *testing.T) {
+	model := createDiffTestModel()
+
+	diff, err := CompareModels(model, model)
+	if err != nil {
+		t.Fatalf("CompareModels failed: %v", err)
+	}
+
+	if diff.ComparedComponents != 3 {
+		t.Errorf("Expected 3 compared components, got %d", diff.ComparedComponents)
+	}
+	for _, c := range diff.Components {
+		if math.Abs(c.LoadingCorrelation-1) > 1e-12 {
+			t.Errorf("%s: expected correlation 1, got %f", c.Component, c.LoadingCorrelation)
+		}
+		if c.RMSDifference != 0 {
+			t.Errorf("%s: expected zero RMS difference, got %g", c.Component, c.RMSDifference)
+		}
+		if c.ExplainedVarianceDelta != 0 {
+			t.Errorf("%s: expected zero variance delta, got %g", c.Component, c.ExplainedVarianceDelta)
+		}
+		if c.SignFlipped {
+			t.Errorf("%s: unexpected sign flip", c.Component)
+		}
+	}
+	if diff.Verdict != DiffVerdictIdentical {
+		t.Errorf("Expected verdict %q, got %q", DiffVerdictIdentical, diff.Verdict)
+	}
+}
+
+func TestCompareModelsSignFlipped(t *testing.T) {
+	modelA := createDiffTestModel()
+	modelB := createDiffTestModel()
+
+	// Flip the sign of PC1 and PC3
+	for i := range modelB.Model.Loadings {
+		modelB.Model.Loadings[i][0] = -modelB.Model.Loadings[i][0]
+		modelB.Model.Loadings[i][2] = -modelB.Model.Loadings[i][2]
+	}
+
+	diff, err := CompareModels(modelA, modelB)
+	if err != nil {
+		t.Fatalf("CompareModels failed: %v", err)
+	}
+
+	expectedFlips := []bool{true, false, true}
+	for k, c := range diff.Components {
+		if c.SignFlipped != expectedFlips[k] {
+			t.Errorf("%s: expected SignFlipped=%v, got %v", c.Component, expectedFlips[k], c.SignFlipped)
+		}
+		if c.RMSDifference > 1e-12 {
+			t.Errorf("%s: expected zero RMS difference after alignment, got %g", c.Component, c.RMSDifference)
+		}
+	}
+	if diff.Verdict != DiffVerdictIdentical {
+		t.Errorf("Expected verdict %q, got %q", DiffVerdictIdentical, diff.Verdict)
+	}
+}
+
+func TestCompareModelsOverlap(t *testing.T) {
+	modelA := createDiffTestModel()
+	modelB := createDiffTestModel()
+
+	// Model B keeps only two components and lists its features in a different order
+	modelB.Model.FeatureLabels = []string{"d", "c", "b", "a"}
+	reordered := make(types.Matrix, 4)
+	for i := range reordered {
+		reordered[i] = modelA.Model.Loadings[3-i][:2]
+	}
+	modelB.Model.Loadings = reordered
+	modelB.Model.ExplainedVarianceRatio = []float64{62, 20}
+
+	diff, err := CompareModels(modelA, modelB)
+	if err != nil {
+		t.Fatalf("CompareModels failed: %v", err)
+	}
+
+	if diff.ComparedComponents != 2 || diff.ComponentsA != 3 || diff.ComponentsB != 2 {
+		t.Errorf("Unexpected component counts: compared=%d a=%d b=%d",
+			diff.ComparedComponents, diff.ComponentsA, diff.ComponentsB)
+	}
+	if diff.MaxRMSDifference > 1e-12 {
+		t.Errorf("Expected matched loadings after feature alignment, got RMS %g", diff.MaxRMSDifference)
+	}
+	if math.Abs(diff.Components[1].ExplainedVarianceDelta-(-5)) > 1e-12 {
+		t.Errorf("Expected PC2 variance delta -5, got %g", diff.Components[1].ExplainedVarianceDelta)
+	}
+	if diff.Verdict == DiffVerdictIdentical {
+		t.Errorf("Models with different variance should not be reported as identical")
+	}
+}
+
+func TestCompareModelsNoCommonFeatures(t *testing.T) {
+	modelA := createDiffTestModel()
+	modelB := createDiffTestModel()
+	modelB.Model.FeatureLabels = []string{"w", "x", "y", "z"}
+
+	if _, err := CompareModels(modelA, modelB); err == nil {
+		t.Error("Expected error for models without common features")
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// ModelInfo summarizes an exported model file
+type ModelInfo struct {
+	Method     string `json:"method"`
+	Components int    `json:"components"`
+	// Preprocessing lists the preprocessing steps in the order they are applied
+	Preprocessing []string `json:"preprocessing"`
+	Variables     int      `json:"variables"`
+	// Observations is the number of samples the model was fitted to, 0 if unknown
+	Observations           int       `json:"observations"`
+	ComponentLabels        []string  `json:"component_labels"`
+	ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"` // Percent per component
+	CumulativeVariance     []float64 `json:"cumulative_variance"`
+	DroppedColumns         []string  `json:"dropped_columns,omitempty"`
+	MissingStrategy        string    `json:"missing_strategy,omitempty"`
+
+	DataFile        string `json:"data_file,omitempty"`
+	DataChecksum    string `json:"data_checksum,omitempty"` // SHA-256 of the training data file
+	SoftwareVersion string `json:"software_version,omitempty"`
+	CreatedAt       string `json:"created_at,omitempty"`
+	AnalysisID      string `json:"analysis_id,omitempty"`
+
+	// MissingFields lists summary fields the model file does not record, as older
+	// versions of GoPCA did not write them
+	MissingFields []string `json:"missing_fields,omitempty"`
+	// UnknownFields lists fields in the model file that this version does not know,
+	// for example from a newer version of GoPCA
+	UnknownFields []string `json:"unknown_fields,omitempty"`
+}
+
+// SummarizeModel summarizes exported model JSON. Unlike ParseModelOutput it does not
+// validate the model against the schema, so that files written by older or newer
+// versions of GoPCA can still be inspected; fields they lack or add are listed in
+// MissingFields and UnknownFields.
+func SummarizeModel(data []byte) (*ModelInfo, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse model JSON: %w", err)
+	}
+	var output types.PCAOutputData
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse model JSON: %w", err)
+	}
+
+	meta := output.Metadata
+	model := output.Model
+	info := &ModelInfo{
+		Method:                 meta.Config.Method,
+		Components:             meta.Config.NComponents,
+		Preprocessing:          preprocessingSteps(output.Preprocessing),
+		Variables:              len(model.FeatureLabels),
+		Observations:           len(output.Results.Samples.Names),
+		ComponentLabels:        model.ComponentLabels,
+		ExplainedVarianceRatio: model.ExplainedVarianceRatio,
+		CumulativeVariance:     model.CumulativeVariance,
+		DroppedColumns:         meta.Config.DroppedColumns,
+		MissingStrategy:        string(meta.Config.MissingStrategy),
+		SoftwareVersion:        meta.SoftwareVersion,
+		CreatedAt:              meta.CreatedAt,
+		AnalysisID:             meta.AnalysisID,
+		UnknownFields:          unknownJSONFields(raw, reflect.TypeOf(output), ""),
+	}
+	if info.Components == 0 {
+		info.Components = len(model.ComponentLabels)
+	}
+	if info.Variables == 0 && len(model.Loadings) > 0 {
+		info.Variables = len(model.Loadings)
+	}
+	if source := meta.DataSource; source != nil {
+		info.DataFile = source.Filename
+		info.DataChecksum = source.Hash
+		if source.NRowsOriginal > 0 {
+			info.Observations = source.NRowsOriginal
+		}
+	}
+
+	missing := []struct {
+		name    string
+		missing bool
+	}{
+		{"metadata.config.method", info.Method == ""},
+		{"metadata.software_version", info.SoftwareVersion == ""},
+		{"metadata.created_at", info.CreatedAt == ""},
+		{"metadata.data_source", meta.DataSource == nil},
+		{"metadata.data_source.hash", meta.DataSource != nil && info.DataChecksum == ""},
+		{"model.explained_variance_ratio", len(info.ExplainedVarianceRatio) == 0},
+	}
+	for _, field := range missing {
+		if field.missing {
+			info.MissingFields = append(info.MissingFields, field.name)
+		}
+	}
+	if len(info.CumulativeVariance) == 0 && len(info.ExplainedVarianceRatio) > 0 {
+		cumulative := 0.0
+		for _, ratio := range info.ExplainedVarianceRatio {
+			cumulative += ratio
+			info.CumulativeVariance = append(info.CumulativeVariance, cumulative)
+		}
+	}
+
+	return info, nil
+}
+
+// preprocessingSteps describes the preprocessing of a model as steps in the order
+// the Preprocessor applies them: row-wise normalization, then centering and scaling
+func preprocessingSteps(pre types.PreprocessingInfo) []string {
+	steps := []string{}
+	if pre.SNV {
+		steps = append(steps, "snv")
+	}
+	if pre.VectorNorm {
+		step := "vector-norm"
+		if pre.VectorNormType != "" {
+			step += " (" + pre.VectorNormType + ")"
+		}
+		steps = append(steps, step)
+	}
+	if pre.MeanCenter {
+		steps = append(steps, "mean-center")
+	}
+	switch {
+	case pre.RobustScale:
+		step := "robust-scale"
+		if pre.RobustCenter != "" || pre.RobustScaleStat != "" {
+			center, _ := ParseRobustCenter(pre.RobustCenter)
+			scale, _ := ParseRobustScaleStat(pre.RobustScaleStat)
+			step += fmt.Sprintf(" (%s, %s)", center, scale)
+		}
+		steps = append(steps, step)
+	case pre.StandardScale:
+		steps = append(steps, "standard-scale")
+	case pre.ScaleOnly:
+		steps = append(steps, "scale-only")
+	}
+	return steps
+}
+
+// unknownJSONFields returns the dotted paths of the keys in raw that have no
+// matching field in the struct type t, recursing into nested objects
+func unknownJSONFields(raw map[string]any, t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+
+	var unknown []string
+	for key, value := range raw {
+		fieldType, ok := fields[key]
+		if !ok {
+			unknown = append(unknown, prefix+key)
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			unknown = append(unknown, unknownJSONFields(nested, fieldType, prefix+key+".")...)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
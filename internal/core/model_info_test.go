@@ -0,0 +1,89 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeModel(t *testing.T) {
+	model := []byte(`{
+		"metadata": {
+			"analysis_id": "abc",
+			"software_version": "1.2.0",
+			"created_at": "2025-01-01T00:00:00Z",
+			"software": "gopca",
+			"config": {"method": "nipals", "n_components": 2, "missing_strategy": "native"},
+			"data_source": {"filename": "data.csv", "n_rows_original": 10, "hash": "abc123"}
+		},
+		"preprocessing": {"mean_center": true, "standard_scale": true, "snv": true},
+		"model": {
+			"loadings": [[0.6, 0.8], [0.8, -0.6], [0.0, 0.0]],
+			"explained_variance_ratio": [70, 20],
+			"cumulative_variance": [70, 90],
+			"component_labels": ["PC1", "PC2"],
+			"feature_labels": ["a", "b", "c"]
+		},
+		"results": {"samples": {"names": ["s1", "s2"], "scores": [[1, 2], [3, 4]]}}
+	}`)
+
+	info, err := SummarizeModel(model)
+	if err != nil {
+		t.Fatalf("SummarizeModel failed: %v", err)
+	}
+	if info.Method != "nipals" || info.Components != 2 || info.Variables != 3 {
+		t.Errorf("Expected nipals with 2 components and 3 variables, got %s, %d, %d",
+			info.Method, info.Components, info.Variables)
+	}
+	if info.Observations != 10 {
+		t.Errorf("Expected the original row count of 10 observations, got %d", info.Observations)
+	}
+	if want := []string{"snv", "mean-center", "standard-scale"}; !reflect.DeepEqual(info.Preprocessing, want) {
+		t.Errorf("Expected preprocessing %v, got %v", want, info.Preprocessing)
+	}
+	if info.DataChecksum != "abc123" || info.SoftwareVersion != "1.2.0" {
+		t.Errorf("Expected checksum abc123 and version 1.2.0, got %q and %q", info.DataChecksum, info.SoftwareVersion)
+	}
+	if len(info.MissingFields) != 0 || len(info.UnknownFields) != 0 {
+		t.Errorf("Expected no missing or unknown fields, got %v and %v", info.MissingFields, info.UnknownFields)
+	}
+}
+
+func TestSummarizeModelLegacyAndUnknownFields(t *testing.T) {
+	// An old model without version or data source, with a field from a newer version
+	model := []byte(`{
+		"metadata": {"config": {"method": "svd", "n_components": 2}, "future_field": 1},
+		"preprocessing": {"mean_center": true},
+		"model": {
+			"loadings": [[0.6, 0.8], [0.8, -0.6]],
+			"explained_variance_ratio": [80, 15],
+			"component_labels": ["PC1", "PC2"],
+			"feature_labels": ["a", "b"]
+		},
+		"extra": {"x": 1}
+	}`)
+
+	info, err := SummarizeModel(model)
+	if err != nil {
+		t.Fatalf("SummarizeModel failed: %v", err)
+	}
+	if want := []float64{80, 95}; !reflect.DeepEqual(info.CumulativeVariance, want) {
+		t.Errorf("Expected cumulative variance %v, got %v", want, info.CumulativeVariance)
+	}
+	wantMissing := []string{"metadata.software_version", "metadata.created_at", "metadata.data_source"}
+	if !reflect.DeepEqual(info.MissingFields, wantMissing) {
+		t.Errorf("Expected missing fields %v, got %v", wantMissing, info.MissingFields)
+	}
+	if want := []string{"extra", "metadata.future_field"}; !reflect.DeepEqual(info.UnknownFields, want) {
+		t.Errorf("Expected unknown fields %v, got %v", want, info.UnknownFields)
+	}
+
+	if _, err := SummarizeModel([]byte("not json")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
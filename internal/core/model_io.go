@@ -0,0 +1,216 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/bitjungle/gopca/pkg/validation"
+)
+
+// ModelSchemaVersion is the model file schema version this build can read
+const ModelSchemaVersion = "v1"
+
+// newModelValidator creates the model schema validator; replaced in tests
+var newModelValidator = validation.NewModelValidator
+
+// ReadModelOutput reads an exported model JSON file, validates it against the
+// model schema and checks that it is compatible with this version of GoPCA.
+// If no schema validator is available, schema validation is skipped with a
+// warning, unless requireSchema is set.
+func ReadModelOutput(path string, requireSchema bool) (*types.PCAOutputData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model file: %w", err)
+	}
+	return ParseModelOutput(data, requireSchema)
+}
+
+// ParseModelOutput parses and validates exported model JSON data, skipping schema
+// validation like ReadModelOutput if no validator is available
+func ParseModelOutput(data []byte, requireSchema bool) (*types.PCAOutputData, error) {
+	var output types.PCAOutputData
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse model JSON: %w", err)
+	}
+	// Scores-only output is valid analyze output but lacks the loadings of a model
+	if output.Metadata.Config.ScoresOnly {
+		return nil, fmt.Errorf("file was written by analyze --scores-only and has no loadings; " +
+			"rerun analyze without --scores-only to save a model")
+	}
+
+	validator, err := newModelValidator(ModelSchemaVersion)
+	if err != nil {
+		if requireSchema {
+			return nil, fmt.Errorf("schema validation not available: %w", err)
+		}
+		// Schema validation not available, continue with the checks below
+		fmt.Fprintf(os.Stderr, "Warning: Schema validation not available: %v\n", err)
+	} else if err := validator.ValidateModel(data); err != nil {
+		return nil, fmt.Errorf("model validation failed: %w", err)
+	}
+
+	if err := validateModelOutput(&output); err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// validateModelOutput checks schema version compatibility and the fields needed for projection
+func validateModelOutput(output *types.PCAOutputData) error {
+	// The $schema field is optional; when present it must reference a supported version
+	if output.Schema != "" && !strings.Contains(output.Schema, "/"+ModelSchemaVersion+"/") {
+		return fmt.Errorf("unsupported model schema %q (expected version %s)", output.Schema, ModelSchemaVersion)
+	}
+
+	model := output.Model
+	if len(model.Loadings) == 0 || len(model.Loadings[0]) == 0 {
+		return fmt.Errorf("model has no loadings")
+	}
+	nFeatures, nComponents := len(model.Loadings), len(model.Loadings[0])
+	for i, row := range model.Loadings {
+		if len(row) != nComponents {
+			return fmt.Errorf("loadings row %d has %d components, expected %d", i, len(row), nComponents)
+		}
+	}
+	if len(model.FeatureLabels) != nFeatures {
+		return fmt.Errorf("model has %d feature labels but %d loading rows", len(model.FeatureLabels), nFeatures)
+	}
+
+	params := output.Preprocessing.Parameters
+	checkLen := func(name string, values []float64, required bool) error {
+		if len(values) == 0 {
+			if required {
+				return fmt.Errorf("model is missing preprocessing parameter %s", name)
+			}
+			return nil
+		}
+		if len(values) != nFeatures {
+			return fmt.Errorf("preprocessing parameter %s has %d values, expected %d", name, len(values), nFeatures)
+		}
+		return nil
+	}
+	pre := output.Preprocessing
+	if err := checkLen("feature_means", params.FeatureMeans, pre.MeanCenter); err != nil {
+		return err
+	}
+	if err := checkLen("feature_stddevs", params.FeatureStdDevs, pre.StandardScale || pre.ScaleOnly); err != nil {
+		return err
+	}
+	if err := checkLen("feature_medians", params.FeatureMedians, pre.RobustScale); err != nil {
+		return err
+	}
+	if err := checkLen("feature_mads", params.FeatureMADs, pre.RobustScale); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadModel reads an exported model file and reconstructs the PCA result,
+// the configuration used to fit it and the fitted preprocessor.
+// The preprocessor is nil if the model was fitted without preprocessing.
+// requireSchema is passed to ReadModelOutput.
+func LoadModel(path string, requireSchema bool) (*types.PCAResult, types.PCAConfig, *Preprocessor, error) {
+	output, err := ReadModelOutput(path, requireSchema)
+	if err != nil {
+		return nil, types.PCAConfig{}, nil, err
+	}
+	return ModelFromOutput(output)
+}
+
+// ModelFromOutput reconstructs the PCA result, configuration and fitted
+// preprocessor from already parsed model output data
+func ModelFromOutput(output *types.PCAOutputData) (*types.PCAResult, types.PCAConfig, *Preprocessor, error) {
+	if output == nil {
+		return nil, types.PCAConfig{}, nil, fmt.Errorf("model data is required")
+	}
+	if err := validateModelOutput(output); err != nil {
+		return nil, types.PCAConfig{}, nil, err
+	}
+
+	meta := output.Metadata.Config
+	pre := output.Preprocessing
+
+	config := types.PCAConfig{
+		Components:      len(output.Model.Loadings[0]),
+		MeanCenter:      pre.MeanCenter,
+		StandardScale:   pre.StandardScale,
+		RobustScale:     pre.RobustScale,
+		ScaleOnly:       pre.ScaleOnly,
+		SNV:             pre.SNV,
+		VectorNorm:      pre.VectorNorm,
+		VectorNormType:  pre.VectorNormType,
+		RobustCenter:    pre.RobustCenter,
+		RobustScaleStat: pre.RobustScaleStat,
+		Method:          meta.Method,
+		ExcludedRows:    meta.ExcludedRows,
+		ExcludedColumns: meta.ExcludedColumns,
+		DroppedColumns:  meta.DroppedColumns,
+		MissingStrategy: meta.MissingStrategy,
+		KernelType:      meta.KernelType,
+		KernelGamma:     meta.KernelGamma,
+		KernelDegree:    meta.KernelDegree,
+		KernelCoef0:     meta.KernelCoef0,
+	}
+
+	var preprocessor *Preprocessor
+	if pre.MeanCenter || pre.StandardScale || pre.RobustScale || pre.ScaleOnly || pre.SNV || pre.VectorNorm {
+		preprocessor = NewPreprocessorWithScaleOnly(pre.MeanCenter, pre.StandardScale, pre.RobustScale,
+			pre.ScaleOnly, pre.SNV, pre.VectorNorm)
+		preprocessor.VectorNormType = VectorNormType(pre.VectorNormType)
+		preprocessor.RobustCenter = RobustCenter(pre.RobustCenter)
+		preprocessor.RobustScaleStat = RobustScaleStat(pre.RobustScaleStat)
+		params := pre.Parameters
+		if err := preprocessor.SetFittedParameters(params.FeatureMeans, params.FeatureStdDevs,
+			params.FeatureMedians, params.FeatureMADs, params.RowMeans, params.RowStdDevs); err != nil {
+			return nil, types.PCAConfig{}, nil, fmt.Errorf("failed to restore preprocessing parameters: %w", err)
+		}
+	}
+
+	result := &types.PCAResult{
+		Scores:               output.Results.Samples.Scores,
+		Loadings:             output.Model.Loadings,
+		ExplainedVar:         output.Model.ExplainedVariance,
+		ExplainedVarRatio:    output.Model.ExplainedVarianceRatio,
+		CumulativeVar:        output.Model.CumulativeVariance,
+		ComponentLabels:      output.Model.ComponentLabels,
+		VariableLabels:       output.Model.FeatureLabels,
+		ComponentsComputed:   config.Components,
+		Method:               meta.Method,
+		PreprocessingApplied: preprocessor != nil,
+		T2Limit95:            output.Diagnostics.T2Limit95,
+		T2Limit99:            output.Diagnostics.T2Limit99,
+		QLimit95:             output.Diagnostics.QLimit95,
+		QLimit99:             output.Diagnostics.QLimit99,
+		Eigencorrelations:    output.Eigencorrelations,
+	}
+	if preprocessor != nil {
+		result.Means = preprocessor.GetMeans()
+		result.StdDevs = preprocessor.GetStdDevs()
+	}
+
+	return result, config, preprocessor, nil
+}
+
+// NewPCAEngineFromModel creates a fitted PCA engine from a loaded model, ready for Transform
+func NewPCAEngineFromModel(result *types.PCAResult, preprocessor *Preprocessor) (*PCAImpl, error) {
+	if result == nil || len(result.Loadings) == 0 {
+		return nil, fmt.Errorf("model result with loadings is required")
+	}
+	engine := &PCAImpl{}
+	if err := engine.SetLoadings(result.Loadings, len(result.Loadings[0])); err != nil {
+		return nil, err
+	}
+	engine.SetPreprocessor(preprocessor)
+	return engine, nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/bitjungle/gopca/pkg/validation"
+)
+
+// exportTestModel fits a model and builds the same output structure the exporters write
+func exportTestModel(t *testing.T, data types.Matrix, config types.PCAConfig) (*types.PCAResult, *types.PCAOutputData) {
+	t.Helper()
+
+	engine := NewPCAEngine().(*PCAImpl)
+	result, err := engine.Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+
+	features := make([]string, len(data[0]))
+	for i := range features {
+		features[i] = string(rune('a' + i))
+	}
+
+	output := &types.PCAOutputData{
+		Schema: "https://github.com/bitjungle/gopca/schemas/v1/pca-output.schema.json",
+		Metadata: types.ModelMetadata{
+			AnalysisID:      "test",
+			SoftwareVersion: "test",
+			CreatedAt:       "2025-01-01T00:00:00Z",
+			Software:        "gopca",
+			Config: types.ModelConfig{
+				Method:          result.Method,
+				NComponents:     result.ComponentsComputed,
+				MissingStrategy: types.MissingError,
+			},
+		},
+		Preprocessing: types.PreprocessingInfo{
+			MeanCenter:    config.MeanCenter,
+			StandardScale: config.StandardScale,
+			RobustScale:   config.RobustScale,
+			Parameters: types.PreprocessingParams{
+				FeatureMeans:   engine.preprocessor.GetMeans(),
+				FeatureStdDevs: engine.preprocessor.GetStdDevs(),
+				FeatureMedians: engine.preprocessor.GetMedians(),
+				FeatureMADs:    engine.preprocessor.GetMADs(),
+			},
+		},
+		Model: types.ModelComponents{
+			Loadings:               result.Loadings,
+			ExplainedVariance:      result.ExplainedVar,
+			ExplainedVarianceRatio: result.ExplainedVarRatio,
+			CumulativeVariance:     result.CumulativeVar,
+			ComponentLabels:        result.ComponentLabels,
+			FeatureLabels:          features,
+		},
+		Results: types.ResultsData{
+			Samples: types.SamplesResults{
+				Scores: result.Scores,
+			},
+		},
+	}
+
+	return result, output
+}
+
+func writeTestModel(t *testing.T, output *types.PCAOutputData) string {
+	t.Helper()
+
+	jsonData, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal model: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+	return path
+}
+
+func TestLoadModelRoundTrip(t *testing.T) {
+	data := types.Matrix{
+		{2.5, 2.4, 1.2},
+		{0.5, 0.7, 0.3},
+		{2.2, 2.9, 1.9},
+		{1.9, 2.2, 0.8},
+		{3.1, 3.0, 2.2},
+		{2.3, 2.7, 1.1},
+		{2.0, 1.6, 1.4},
+		{1.0, 1.1, 0.2},
+	}
+
+	configs := map[string]types.PCAConfig{
+		"standard": {Components: 2, MeanCenter: true, StandardScale: true, Method: "svd"},
+		"robust":   {Components: 2, RobustScale: true, Method: "svd"},
+		"nipals":   {Components: 3, MeanCenter: true, Method: "nipals"},
+	}
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			original, output := exportTestModel(t, data, config)
+			path := writeTestModel(t, output)
+
+			result, loadedConfig, preprocessor, err := LoadModel(path, false)
+			if err != nil {
+				t.Fatalf("LoadModel failed: %v", err)
+			}
+			if preprocessor == nil {
+				t.Fatal("Expected a fitted preprocessor")
+			}
+			if loadedConfig.Components != original.ComponentsComputed {
+				t.Errorf("Expected %d components, got %d", original.ComponentsComputed, loadedConfig.Components)
+			}
+			if loadedConfig.StandardScale != config.StandardScale || loadedConfig.RobustScale != config.RobustScale {
+				t.Errorf("Preprocessing flags not restored: %+v", loadedConfig)
+			}
+
+			engine, err := NewPCAEngineFromModel(result, preprocessor)
+			if err != nil {
+				t.Fatalf("NewPCAEngineFromModel failed: %v", err)
+			}
+			scores, err := engine.Transform(data)
+			if err != nil {
+				t.Fatalf("Transform failed: %v", err)
+			}
+
+			for i := range scores {
+				for j := range scores[i] {
+					if math.Abs(scores[i][j]-original.Scores[i][j]) > 1e-9 {
+						t.Fatalf("Score [%d][%d] mismatch: got %f, expected %f",
+							i, j, scores[i][j], original.Scores[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadModelValidation(t *testing.T) {
+	data := types.Matrix{
+		{1, 2, 3},
+		{2, 1, 4},
+		{3, 5, 1},
+		{4, 3, 2},
+	}
+	config := types.PCAConfig{Components: 2, MeanCenter: true, StandardScale: true, Method: "svd"}
+
+	tests := []struct {
+		name    string
+		mutate  func(o *types.PCAOutputData)
+		wantErr string
+	}{
+		{
+			name: "unsupported schema version",
+			mutate: func(o *types.PCAOutputData) {
+				o.Schema = "https://github.com/bitjungle/gopca/schemas/v2/pca-output.schema.json"
+			},
+			wantErr: "schema",
+		},
+		{
+			name:    "feature label mismatch",
+			mutate:  func(o *types.PCAOutputData) { o.Model.FeatureLabels = o.Model.FeatureLabels[:2] },
+			wantErr: "feature labels",
+		},
+		{
+			name:    "missing scaling parameters",
+			mutate:  func(o *types.PCAOutputData) { o.Preprocessing.Parameters.FeatureStdDevs = nil },
+			wantErr: "feature_stddevs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output := exportTestModel(t, data, config)
+			tt.mutate(output)
+			path := writeTestModel(t, output)
+
+			_, _, _, err := LoadModel(path, false)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, _, _, err := LoadModel(filepath.Join(t.TempDir(), "missing.json"), false); err == nil {
+		t.Error("Expected error for missing model file")
+	}
+}
+
+func TestLoadModelWithoutSchemaValidator(t *testing.T) {
+	data := types.Matrix{
+		{1, 2, 3},
+		{2, 1, 4},
+		{3, 5, 1},
+		{4, 3, 2},
+	}
+	_, output := exportTestModel(t, data, types.PCAConfig{Components: 2, MeanCenter: true, Method: "svd"})
+	path := writeTestModel(t, output)
+
+	defer func(original func(string) (*validation.ModelValidator, error)) { newModelValidator = original }(newModelValidator)
+	newModelValidator = func(string) (*validation.ModelValidator, error) {
+		return nil, fmt.Errorf("schema not embedded")
+	}
+
+	// Validation is skipped unless it was requested
+	if _, _, _, err := LoadModel(path, false); err != nil {
+		t.Errorf("Expected the model to load without a schema validator, got %v", err)
+	}
+	_, _, _, err := LoadModel(path, true)
+	if err == nil || !strings.Contains(err.Error(), "schema validation not available") {
+		t.Errorf("Expected a schema validation error when it is required, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bitjungle/gopca/internal/utils"
+	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ModelMerge is the consensus of several PCA models together with how consistent
+// the models were
+type ModelMerge struct {
+	// Consensus is the merged model. It has no sample scores or diagnostic limits,
+	// since the models may have been fitted to different samples.
+	Consensus  *types.PCAOutputData `json:"-"`
+	Models     int                  `json:"models"`
+	Features   int                  `json:"features"`
+	Components int                  `json:"components"`
+	// Disparities holds the Procrustes disparity of each model to the first model
+	// after alignment; the first entry is always zero
+	Disparities   []float64 `json:"disparities"`
+	MeanDisparity float64   `json:"mean_disparity"` // Mean disparity of the models after the first
+	MaxDisparity  float64   `json:"max_disparity"`
+}
+
+// Procrustes finds the orthogonal matrix R that minimizes ‖source·R − target‖ and
+// returns source·R together with the disparity ‖source·R − target‖² / ‖target‖².
+// Both matrices must have the same shape. The alignment undoes sign flips and
+// rotations of the components, so a disparity near zero means source and target
+// span the same subspace with the same orientation.
+//
+// Reference: Schönemann, P.H. (1966). A generalized solution of the orthogonal
+// Procrustes problem. Psychometrika, 31(1), 1-10.
+func Procrustes(source, target types.Matrix) (types.Matrix, float64, error) {
+	if len(target) == 0 || len(target[0]) == 0 {
+		return nil, 0, fmt.Errorf("target matrix is empty")
+	}
+	if len(source) != len(target) || len(source[0]) != len(target[0]) {
+		return nil, 0, fmt.Errorf("matrix dimensions differ: %dx%d vs %dx%d",
+			len(source), len(source[0]), len(target), len(target[0]))
+	}
+
+	S := utils.MatrixToDense(source)
+	T := utils.MatrixToDense(target)
+
+	// R = U·Vᵀ where U·Σ·Vᵀ is the SVD of Sᵀ·T
+	var cross mat.Dense
+	cross.Mul(S.T(), T)
+	var svd mat.SVD
+	if !svd.Factorize(&cross, mat.SVDFull) {
+		return nil, 0, fmt.Errorf("SVD failed during Procrustes alignment")
+	}
+	var U, V, R mat.Dense
+	svd.UTo(&U)
+	svd.VTo(&V)
+	R.Mul(&U, V.T())
+
+	var aligned, residual mat.Dense
+	aligned.Mul(S, &R)
+	residual.Sub(&aligned, T)
+
+	targetNorm := mat.Norm(T, 2)
+	disparity := 0.0
+	if targetNorm > 0 {
+		disparity = math.Pow(mat.Norm(&residual, 2)/targetNorm, 2)
+	}
+	return utils.DenseToMatrix(&aligned), disparity, nil
+}
+
+// MergeModels combines several exported models of the same features into a
+// consensus model. Each model's loadings are aligned to the first model with
+// Procrustes, averaged and then replaced by the nearest orthonormal matrix.
+// Explained variances and the fitted preprocessing parameters are averaged.
+// Features are matched by label, and only the components all models have in
+// common are merged. The models must use the same preprocessing.
+func MergeModels(models []*types.PCAOutputData) (*ModelMerge, error) {
+	if len(models) < 2 {
+		return nil, fmt.Errorf("at least 2 models are required, got %d", len(models))
+	}
+	for i, m := range models {
+		if m == nil {
+			return nil, fmt.Errorf("model %d is missing", i+1)
+		}
+		if err := validateModelOutput(m); err != nil {
+			return nil, fmt.Errorf("model %d: %w", i+1, err)
+		}
+	}
+
+	ref := models[0]
+	features := ref.Model.FeatureLabels
+	nComp := len(ref.Model.Loadings[0])
+
+	// rows[i][j] is the loading row of feature j of the reference in model i
+	rows := make([][]int, len(models))
+	for i, m := range models {
+		if i > 0 && !samePreprocessing(ref.Preprocessing, m.Preprocessing) {
+			return nil, fmt.Errorf("model %d uses different preprocessing than model 1", i+1)
+		}
+		if len(m.Model.FeatureLabels) != len(features) {
+			return nil, fmt.Errorf("model %d has %d features, expected %d",
+				i+1, len(m.Model.FeatureLabels), len(features))
+		}
+		index := make(map[string]int, len(m.Model.FeatureLabels))
+		for j, label := range m.Model.FeatureLabels {
+			index[label] = j
+		}
+		rows[i] = make([]int, len(features))
+		for j, label := range features {
+			k, ok := index[label]
+			if !ok {
+				return nil, fmt.Errorf("model %d has no feature %q", i+1, label)
+			}
+			rows[i][j] = k
+		}
+		nComp = min(nComp, len(m.Model.Loadings[0]))
+	}
+
+	merge := &ModelMerge{
+		Models:      len(models),
+		Features:    len(features),
+		Components:  nComp,
+		Disparities: make([]float64, len(models)),
+	}
+
+	// Loadings of each model restricted to the common components, in reference feature order
+	selectLoadings := func(i int) types.Matrix {
+		loadings := make(types.Matrix, len(features))
+		for j, r := range rows[i] {
+			loadings[j] = append([]float64(nil), models[i].Model.Loadings[r][:nComp]...)
+		}
+		return loadings
+	}
+
+	target := selectLoadings(0)
+	sum := utils.MatrixToDense(target)
+	for i := 1; i < len(models); i++ {
+		aligned, disparity, err := Procrustes(selectLoadings(i), target)
+		if err != nil {
+			return nil, fmt.Errorf("model %d: %w", i+1, err)
+		}
+		merge.Disparities[i] = disparity
+		merge.MeanDisparity += disparity / float64(len(models)-1)
+		merge.MaxDisparity = math.Max(merge.MaxDisparity, disparity)
+		sum.Add(sum, utils.MatrixToDense(aligned))
+	}
+
+	// The nearest orthonormal matrix to the mean is U·Vᵀ from its SVD; scaling by
+	// the number of models does not change it
+	var svd mat.SVD
+	if !svd.Factorize(sum, mat.SVDThin) {
+		return nil, fmt.Errorf("SVD failed while orthonormalizing the consensus loadings")
+	}
+	var U, V, loadings mat.Dense
+	svd.UTo(&U)
+	svd.VTo(&V)
+	loadings.Mul(&U, V.T())
+
+	consensus := &types.PCAOutputData{
+		Schema: ref.Schema,
+		Metadata: types.ModelMetadata{
+			Config: ref.Metadata.Config,
+		},
+		Preprocessing: types.PreprocessingInfo{
+			MeanCenter:      ref.Preprocessing.MeanCenter,
+			StandardScale:   ref.Preprocessing.StandardScale,
+			RobustScale:     ref.Preprocessing.RobustScale,
+			ScaleOnly:       ref.Preprocessing.ScaleOnly,
+			SNV:             ref.Preprocessing.SNV,
+			VectorNorm:      ref.Preprocessing.VectorNorm,
+			VectorNormType:  ref.Preprocessing.VectorNormType,
+			RobustCenter:    ref.Preprocessing.RobustCenter,
+			RobustScaleStat: ref.Preprocessing.RobustScaleStat,
+			// Row means and standard deviations belong to the fitted samples, so they are not merged
+			Parameters: types.PreprocessingParams{
+				FeatureMeans: meanFeatureParameter(models, rows,
+					func(p types.PreprocessingParams) []float64 { return p.FeatureMeans }),
+				FeatureStdDevs: meanFeatureParameter(models, rows,
+					func(p types.PreprocessingParams) []float64 { return p.FeatureStdDevs }),
+				FeatureMedians: meanFeatureParameter(models, rows,
+					func(p types.PreprocessingParams) []float64 { return p.FeatureMedians }),
+				FeatureMADs: meanFeatureParameter(models, rows,
+					func(p types.PreprocessingParams) []float64 { return p.FeatureMADs }),
+			},
+		},
+		Model: types.ModelComponents{
+			Loadings: utils.DenseToMatrix(&loadings),
+			ExplainedVariance: meanComponentValues(models, nComp,
+				func(m types.ModelComponents) []float64 { return m.ExplainedVariance }),
+			ExplainedVarianceRatio: meanComponentValues(models, nComp,
+				func(m types.ModelComponents) []float64 { return m.ExplainedVarianceRatio }),
+			ComponentLabels: make([]string, nComp),
+			FeatureLabels:   append([]string(nil), features...),
+		},
+		Results: types.ResultsData{
+			Samples: types.SamplesResults{
+				Names:  []string{},
+				Scores: types.Matrix{},
+			},
+		},
+	}
+	consensus.Metadata.Config.NComponents = nComp
+	consensus.Metadata.Config.ExcludedRows = nil
+
+	cumulative := 0.0
+	consensus.Model.CumulativeVariance = make([]float64, nComp)
+	for k := 0; k < nComp; k++ {
+		cumulative += consensus.Model.ExplainedVarianceRatio[k]
+		consensus.Model.CumulativeVariance[k] = cumulative
+		consensus.Model.ComponentLabels[k] = fmt.Sprintf("PC%d", k+1)
+		if k < len(ref.Model.ComponentLabels) {
+			consensus.Model.ComponentLabels[k] = ref.Model.ComponentLabels[k]
+		}
+	}
+
+	merge.Consensus = consensus
+	return merge, nil
+}
+
+// samePreprocessing reports whether two models were preprocessed the same way
+func samePreprocessing(a, b types.PreprocessingInfo) bool {
+	if a.VectorNorm && b.VectorNorm {
+		// Models written before the norm was selectable record no type and used L2
+		normA, _ := ParseVectorNormType(a.VectorNormType)
+		normB, _ := ParseVectorNormType(b.VectorNormType)
+		if normA != normB {
+			return false
+		}
+	}
+	if a.RobustScale && b.RobustScale {
+		// Models written before the statistics were selectable used median and MAD
+		centerA, _ := ParseRobustCenter(a.RobustCenter)
+		centerB, _ := ParseRobustCenter(b.RobustCenter)
+		scaleA, _ := ParseRobustScaleStat(a.RobustScaleStat)
+		scaleB, _ := ParseRobustScaleStat(b.RobustScaleStat)
+		if centerA != centerB || scaleA != scaleB {
+			return false
+		}
+	}
+	return a.MeanCenter == b.MeanCenter && a.StandardScale == b.StandardScale &&
+		a.RobustScale == b.RobustScale && a.ScaleOnly == b.ScaleOnly &&
+		a.SNV == b.SNV && a.VectorNorm == b.VectorNorm
+}
+
+// meanFeatureParameter averages a per-feature preprocessing parameter over the
+// models, in the feature order of the first model. It returns nil if any model
+// lacks the parameter.
+func meanFeatureParameter(models []*types.PCAOutputData, rows [][]int,
+	get func(types.PreprocessingParams) []float64) []float64 {
+	mean := make([]float64, len(rows[0]))
+	for i, m := range models {
+		values := get(m.Preprocessing.Parameters)
+		if len(values) == 0 {
+			return nil
+		}
+		for j, r := range rows[i] {
+			mean[j] += values[r] / float64(len(models))
+		}
+	}
+	return mean
+}
+
+// meanComponentValues averages the first nComp values of a per-component
+// quantity over the models, treating missing values as zero
+func meanComponentValues(models []*types.PCAOutputData, nComp int,
+	get func(types.ModelComponents) []float64) []float64 {
+	mean := make([]float64, nComp)
+	for _, m := range models {
+		values := get(m.Model)
+		for k := 0; k < nComp && k < len(values); k++ {
+			mean[k] += values[k] / float64(len(models))
+		}
+	}
+	return mean
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// createMergeTestModel builds a mean-centered model with orthonormal loadings
+func createMergeTestModel() *types.PCAOutputData {
+	return &types.PCAOutputData{
+		Preprocessing: types.PreprocessingInfo{
+			MeanCenter: true,
+			Parameters: types.PreprocessingParams{
+				FeatureMeans: []float64{1, 2, 3, 4},
+			},
+		},
+		Model: types.ModelComponents{
+			Loadings: types.Matrix{
+				{0.5, 0.5},
+				{0.5, -0.5},
+				{0.5, 0.5},
+				{0.5, -0.5},
+			},
+			ExplainedVariance:      []float64{3, 1},
+			ExplainedVarianceRatio: []float64{60, 20},
+			ComponentLabels:        []string{"PC1", "PC2"},
+			FeatureLabels:          []string{"a", "b", "c", "d"},
+		},
+	}
+}
+
+func TestMergeModelsSignFlipped(t *testing.T) {
+	model := createMergeTestModel()
+
+	// The same model with PC2 negated and the features in reverse order
+	flipped := createMergeTestModel()
+	n := len(model.Model.FeatureLabels)
+	for j := 0; j < n; j++ {
+		src := n - 1 - j
+		flipped.Model.FeatureLabels[j] = model.Model.FeatureLabels[src]
+		flipped.Model.Loadings[j] = []float64{model.Model.Loadings[src][0], -model.Model.Loadings[src][1]}
+		flipped.Preprocessing.Parameters.FeatureMeans[j] = model.Preprocessing.Parameters.FeatureMeans[src]
+	}
+
+	merge, err := MergeModels([]*types.PCAOutputData{model, flipped})
+	if err != nil {
+		t.Fatalf("MergeModels failed: %v", err)
+	}
+
+	if merge.Components != 2 || merge.Features != 4 {
+		t.Errorf("Expected 4 features and 2 components, got %d and %d", merge.Features, merge.Components)
+	}
+	if merge.MaxDisparity > 1e-12 {
+		t.Errorf("Expected near-zero disparity for a sign-flipped copy, got %g", merge.MaxDisparity)
+	}
+
+	consensus := merge.Consensus
+	for j, row := range consensus.Model.Loadings {
+		for k, v := range row {
+			if math.Abs(v-model.Model.Loadings[j][k]) > 1e-12 {
+				t.Errorf("Loading [%d][%d] = %f, expected %f", j, k, v, model.Model.Loadings[j][k])
+			}
+		}
+	}
+	for j, v := range consensus.Preprocessing.Parameters.FeatureMeans {
+		if v != model.Preprocessing.Parameters.FeatureMeans[j] {
+			t.Errorf("Feature mean %d = %f, expected %f", j, v, model.Preprocessing.Parameters.FeatureMeans[j])
+		}
+	}
+	if got := consensus.Model.CumulativeVariance; len(got) != 2 || got[1] != 80 {
+		t.Errorf("Expected cumulative variance [60 80], got %v", got)
+	}
+	if dev := LoadingsOrthonormality(consensus.Model.Loadings); dev > 1e-12 {
+		t.Errorf("Consensus loadings are not orthonormal (deviation %g)", dev)
+	}
+}
+
+func TestMergeModelsDisparity(t *testing.T) {
+	model := createMergeTestModel()
+
+	// Replace PC2 with a direction orthogonal to the original plane
+	other := createMergeTestModel()
+	other.Model.Loadings = types.Matrix{
+		{0.5, 0.5},
+		{0.5, 0.5},
+		{0.5, -0.5},
+		{0.5, -0.5},
+	}
+
+	merge, err := MergeModels([]*types.PCAOutputData{model, other})
+	if err != nil {
+		t.Fatalf("MergeModels failed: %v", err)
+	}
+	if merge.MeanDisparity < 0.1 {
+		t.Errorf("Expected a clear disparity for models spanning different planes, got %g", merge.MeanDisparity)
+	}
+	if dev := LoadingsOrthonormality(merge.Consensus.Model.Loadings); dev > 1e-12 {
+		t.Errorf("Consensus loadings are not orthonormal (deviation %g)", dev)
+	}
+}
+
+func TestMergeModelsErrors(t *testing.T) {
+	model := createMergeTestModel()
+
+	if _, err := MergeModels([]*types.PCAOutputData{model}); err == nil {
+		t.Error("Expected an error when merging a single model")
+	}
+
+	scaled := createMergeTestModel()
+	scaled.Preprocessing.StandardScale = true
+	scaled.Preprocessing.Parameters.FeatureStdDevs = []float64{1, 1, 1, 1}
+	if _, err := MergeModels([]*types.PCAOutputData{model, scaled}); err == nil {
+		t.Error("Expected an error when the preprocessing differs")
+	}
+
+	renamed := createMergeTestModel()
+	renamed.Model.FeatureLabels[0] = "x"
+	if _, err := MergeModels([]*types.PCAOutputData{model, renamed}); err == nil {
+		t.Error("Expected an error when the features differ")
+	}
+}
@@ -7,6 +7,7 @@
 package core
 
 import (
+	"context"
 	"math"
 	"testing"
 
@@ -221,7 +222,7 @@ func TestNIPALSConvergenceWithMissing(t *testing.T) {
 	impl := &PCAImpl{}
 	X := utils.MatrixToDense(data)
 
-	scores, loadings, _, err := impl.nipalsAlgorithmWithMissing(X, 3)
+	scores, loadings, _, err := impl.nipalsAlgorithmWithMissing(context.Background(), X, 3)
 	if err != nil {
 		t.Fatalf("NIPALS failed to converge: %v", err)
 	}
@@ -305,3 +306,53 @@ func TestNIPALSEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// TestNIPALSAllMissingColumn tests that a column without observed values is excluded
+// from native NIPALS and gets zero loadings
+func TestNIPALSAllMissingColumn(t *testing.T) {
+	withColumn := types.Matrix{
+		{2.5, math.NaN(), 2.4, 3.5},
+		{0.5, math.NaN(), 0.7, math.NaN()},
+		{2.2, math.NaN(), 2.9, 3.2},
+		{math.NaN(), math.NaN(), 2.2, 2.9},
+		{3.1, math.NaN(), 3.0, 4.1},
+		{2.3, math.NaN(), 2.7, 3.3},
+	}
+	without := make(types.Matrix, len(withColumn))
+	for i, row := range withColumn {
+		without[i] = []float64{row[0], row[2], row[3]}
+	}
+
+	impl := &PCAImpl{}
+	_, P, _, err := impl.nipalsAlgorithmWithMissing(context.Background(), utils.MatrixToDense(withColumn), 2)
+	if err != nil {
+		t.Fatalf("nipalsAlgorithmWithMissing() error = %v", err)
+	}
+	if len(impl.allMissingColumns) != 1 || impl.allMissingColumns[0] != 1 {
+		t.Errorf("allMissingColumns = %v, want [1]", impl.allMissingColumns)
+	}
+
+	ref := &PCAImpl{}
+	_, refP, _, err := ref.nipalsAlgorithmWithMissing(context.Background(), utils.MatrixToDense(without), 2)
+	if err != nil {
+		t.Fatalf("reference fit error = %v", err)
+	}
+
+	rows, cols := P.Dims()
+	if rows != 4 || cols != 2 {
+		t.Fatalf("loadings are %dx%d, want 4x2", rows, cols)
+	}
+	if !floats.Equal(mat.Row(nil, 1, P), []float64{0, 0}) {
+		t.Errorf("loadings of the missing column = %v, want zeros", mat.Row(nil, 1, P))
+	}
+	for k, j := range []int{0, 2, 3} {
+		if !floats.EqualApprox(mat.Row(nil, j, P), mat.Row(nil, k, refP), 1e-12) {
+			t.Errorf("loadings of column %d = %v, want %v", j+1, mat.Row(nil, j, P), mat.Row(nil, k, refP))
+		}
+	}
+
+	allMissing := types.Matrix{{math.NaN(), math.NaN()}, {math.NaN(), math.NaN()}}
+	if _, _, _, err := impl.nipalsAlgorithmWithMissing(context.Background(), utils.MatrixToDense(allMissing), 1); err == nil {
+		t.Error("expected an error when all columns are missing")
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// DefaultNormalityAlpha is the default significance level for normality tests
+const DefaultNormalityAlpha = 0.05
+
+// Normality test methods
+const (
+	NormalityShapiroWilk  = "shapiro-wilk"
+	NormalityDAgostinoK2  = "dagostino-pearson"
+	shapiroWilkMaxSamples = 5000
+)
+
+// NormalityTestResult holds the outcome of a normality test
+type NormalityTestResult struct {
+	Method    string  `json:"method"`    // Test used: shapiro-wilk or dagostino-pearson
+	N         int     `json:"n"`         // Number of non-missing values tested
+	Statistic float64 `json:"statistic"` // W for Shapiro-Wilk, K² for D'Agostino-Pearson
+	PValue    float64 `json:"p_value"`
+	Alpha     float64 `json:"alpha"`
+	IsNormal  bool    `json:"is_normal"` // True if normality is not rejected at Alpha
+}
+
+// NormalityTest tests the null hypothesis that values come from a normal distribution.
+// Shapiro-Wilk is used for 3 ≤ n ≤ 5000 and the D'Agostino-Pearson omnibus test for
+// larger samples. NaN values are ignored. If alpha is not in (0, 1),
+// DefaultNormalityAlpha is used.
+func NormalityTest(values []float64, alpha float64) (*NormalityTestResult, error) {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = DefaultNormalityAlpha
+	}
+
+	x := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			x = append(x, v)
+		}
+	}
+
+	n := len(x)
+	if n < 3 {
+		return nil, fmt.Errorf("normality test requires at least 3 values, got %d", n)
+	}
+
+	sort.Float64s(x)
+	if x[0] == x[n-1] {
+		return nil, fmt.Errorf("normality test requires non-constant data")
+	}
+
+	result := &NormalityTestResult{N: n, Alpha: alpha}
+	if n <= shapiroWilkMaxSamples {
+		result.Method = NormalityShapiroWilk
+		result.Statistic, result.PValue = shapiroWilk(x)
+	} else {
+		result.Method = NormalityDAgostinoK2
+		result.Statistic, result.PValue = dagostinoPearson(x)
+	}
+	result.IsNormal = result.PValue >= alpha
+
+	return result, nil
+}
+
+// shapiroWilk computes the Shapiro-Wilk W statistic and its p-value for sorted data
+//
+// Reference: Royston, P. (1995). Remark AS R94: A remark on algorithm AS 181:
+// The W-test for normality. Applied Statistics, 44(4), 547-551.
+func shapiroWilk(x []float64) (float64, float64) {
+	n := len(x)
+	nf := float64(n)
+	norm := distuv.UnitNormal
+
+	// Coefficients a_i (antisymmetric, so only the upper half is needed)
+	a := make([]float64, n)
+	if n == 3 {
+		a[0], a[2] = -math.Sqrt(0.5), math.Sqrt(0.5)
+	} else {
+		m := make([]float64, n)
+		msum := 0.0
+		for i := 0; i < n; i++ {
+			m[i] = norm.Quantile((float64(i+1) - 0.375) / (nf + 0.25))
+			msum += m[i] * m[i]
+		}
+		rsm := math.Sqrt(msum)
+		u := 1 / math.Sqrt(nf)
+
+		an := m[n-1]/rsm + polyval([]float64{0, 0.221157, -0.147981, -2.071190, 4.434685, -2.706056}, u)
+		a[n-1], a[0] = an, -an
+
+		first := 1
+		var phi float64
+		if n > 5 {
+			an1 := m[n-2]/rsm + polyval([]float64{0, 0.042981, -0.293762, -1.752461, 5.682633, -3.582633}, u)
+			a[n-2], a[1] = an1, -an1
+			phi = (msum - 2*m[n-1]*m[n-1] - 2*m[n-2]*m[n-2]) / (1 - 2*an*an - 2*an1*an1)
+			first = 2
+		} else {
+			phi = (msum - 2*m[n-1]*m[n-1]) / (1 - 2*an*an)
+		}
+		sqrtPhi := math.Sqrt(phi)
+		for i := first; i < n-first; i++ {
+			a[i] = m[i] / sqrtPhi
+		}
+	}
+
+	// W = (Σ a_i x_(i))² / Σ (x_i - x̄)²
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= nf
+	var num, ss float64
+	for i, v := range x {
+		num += a[i] * v
+		d := v - mean
+		ss += d * d
+	}
+	w := num * num / ss
+	if w > 1 {
+		w = 1
+	}
+
+	// p-value
+	if n == 3 {
+		p := 6 / math.Pi * (math.Asin(math.Sqrt(w)) - math.Asin(math.Sqrt(0.75)))
+		return w, math.Max(0, math.Min(1, p))
+	}
+
+	y := math.Log(1 - w)
+	var z float64
+	if n <= 11 {
+		gamma := polyval([]float64{-2.273, 0.459}, nf)
+		if y >= gamma {
+			return w, 0
+		}
+		y = -math.Log(gamma - y)
+		mu := polyval([]float64{0.5440, -0.39978, 0.025054, -6.714e-4}, nf)
+		sigma := math.Exp(polyval([]float64{1.3822, -0.77857, 0.062767, -0.0020322}, nf))
+		z = (y - mu) / sigma
+	} else {
+		ln := math.Log(nf)
+		mu := polyval([]float64{-1.5861, -0.31082, -0.083751, 0.0038915}, ln)
+		sigma := math.Exp(polyval([]float64{-0.4803, -0.082676, 0.0030302}, ln))
+		z = (y - mu) / sigma
+	}
+
+	return w, 1 - norm.CDF(z)
+}
+
+// dagostinoPearson computes the D'Agostino-Pearson K² statistic and its p-value
+//
+// Reference: D'Agostino, R.B., Belanger, A. & D'Agostino, R.B. Jr. (1990).
+// A suggestion for using powerful and informative tests of normality.
+// The American Statistician, 44(4), 316-321.
+func dagostinoPearson(x []float64) (float64, float64) {
+	n := float64(len(x))
+
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= n
+	var m2, m3, m4 float64
+	for _, v := range x {
+		d := v - mean
+		d2 := d * d
+		m2 += d2
+		m3 += d2 * d
+		m4 += d2 * d2
+	}
+	m2 /= n
+	m3 /= n
+	m4 /= n
+
+	// Skewness test
+	g1 := m3 / math.Pow(m2, 1.5)
+	y := g1 * math.Sqrt((n+1)*(n+3)/(6*(n-2)))
+	beta2 := 3 * (n*n + 27*n - 70) * (n + 1) * (n + 3) / ((n - 2) * (n + 5) * (n + 7) * (n + 9))
+	w2 := -1 + math.Sqrt(2*(beta2-1))
+	delta := 1 / math.Sqrt(0.5*math.Log(w2))
+	alpha := math.Sqrt(2 / (w2 - 1))
+	zs := delta * math.Asinh(y/alpha)
+
+	// Kurtosis test
+	b2 := m4 / (m2 * m2)
+	e := 3 * (n - 1) / (n + 1)
+	varb2 := 24 * n * (n - 2) * (n - 3) / ((n + 1) * (n + 1) * (n + 3) * (n + 5))
+	xk := (b2 - e) / math.Sqrt(varb2)
+	sqrtBeta1 := 6 * (n*n - 5*n + 2) / ((n + 7) * (n + 9)) * math.Sqrt(6*(n+3)*(n+5)/(n*(n-2)*(n-3)))
+	a := 6 + 8/sqrtBeta1*(2/sqrtBeta1+math.Sqrt(1+4/(sqrtBeta1*sqrtBeta1)))
+	term1 := 1 - 2/(9*a)
+	denom := 1 + xk*math.Sqrt(2/(a-4))
+	term2 := math.Cbrt((1 - 2/a) / denom)
+	zk := (term1 - term2) / math.Sqrt(2/(9*a))
+
+	k2 := zs*zs + zk*zk
+	// K² is approximately chi-squared with 2 degrees of freedom
+	return k2, math.Exp(-k2 / 2)
+}
+
+// polyval evaluates c[0] + c[1]*x + c[2]*x² + ...
+func polyval(c []float64, x float64) float64 {
+	result := 0.0
+	for i := len(c) - 1; i >= 0; i-- {
+		result = result*x + c[i]
+	}
+	return result
+}
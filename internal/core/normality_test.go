@@ -0,0 +1,110 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNormalityNormalSamples(t *testing.T) {
+	for _, n := range []int{20, 200, 6000} {
+		passed := 0
+		const trials = 20
+		for seed := int64(1); seed <= trials; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			values := make([]float64, n)
+			for i := range values {
+				values[i] = 10 + 3*rng.NormFloat64()
+			}
+
+			result, err := NormalityTest(values, 0.05)
+			if err != nil {
+				t.Fatalf("n=%d: NormalityTest failed: %v", n, err)
+			}
+			if result.PValue < 0 || result.PValue > 1 {
+				t.Fatalf("n=%d: p-value out of range: %f", n, result.PValue)
+			}
+			if result.IsNormal {
+				passed++
+			}
+		}
+
+		// At alpha = 0.05 roughly 1 in 20 normal samples is expected to be rejected
+		if passed < trials-4 {
+			t.Errorf("n=%d: only %d of %d normal samples passed the normality test", n, passed, trials)
+		}
+	}
+}
+
+func TestNormalityExponentialSample(t *testing.T) {
+	for _, n := range []int{50, 6000} {
+		rng := rand.New(rand.NewSource(42))
+		values := make([]float64, n)
+		for i := range values {
+			values[i] = rng.ExpFloat64()
+		}
+
+		result, err := NormalityTest(values, 0.05)
+		if err != nil {
+			t.Fatalf("n=%d: NormalityTest failed: %v", n, err)
+		}
+		if result.IsNormal {
+			t.Errorf("n=%d: exponential sample passed normality test (p=%g)", n, result.PValue)
+		}
+		if result.PValue > 0.001 {
+			t.Errorf("n=%d: expected a very small p-value for exponential data, got %g", n, result.PValue)
+		}
+	}
+}
+
+func TestNormalityMethodSelection(t *testing.T) {
+	small := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	result, err := NormalityTest(small, 0)
+	if err != nil {
+		t.Fatalf("NormalityTest failed: %v", err)
+	}
+	if result.Method != NormalityShapiroWilk {
+		t.Errorf("Expected %s for small samples, got %s", NormalityShapiroWilk, result.Method)
+	}
+	if result.Alpha != DefaultNormalityAlpha {
+		t.Errorf("Expected default alpha %f, got %f", DefaultNormalityAlpha, result.Alpha)
+	}
+
+	large := make([]float64, shapiroWilkMaxSamples+1)
+	for i := range large {
+		large[i] = float64(i % 97)
+	}
+	result, err = NormalityTest(large, 0.05)
+	if err != nil {
+		t.Fatalf("NormalityTest failed: %v", err)
+	}
+	if result.Method != NormalityDAgostinoK2 {
+		t.Errorf("Expected %s for large samples, got %s", NormalityDAgostinoK2, result.Method)
+	}
+}
+
+func TestShapiroWilkThreeValues(t *testing.T) {
+	// Equally spaced values give W = 1 and p = 1
+	result, err := NormalityTest([]float64{1, 2, 3}, 0.05)
+	if err != nil {
+		t.Fatalf("NormalityTest failed: %v", err)
+	}
+	if math.Abs(result.Statistic-1) > 1e-12 || math.Abs(result.PValue-1) > 1e-12 {
+		t.Errorf("Expected W=1, p=1, got W=%f, p=%f", result.Statistic, result.PValue)
+	}
+}
+
+func TestNormalityErrors(t *testing.T) {
+	if _, err := NormalityTest([]float64{1, math.NaN(), 2}, 0.05); err == nil {
+		t.Error("Expected error for fewer than 3 non-missing values")
+	}
+	if _, err := NormalityTest([]float64{5, 5, 5, 5}, 0.05); err == nil {
+		t.Error("Expected error for constant data")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// DefaultOrthonormalityTolerance is the largest deviation accepted by CheckOrthonormality
+const DefaultOrthonormalityTolerance = 1e-6
+
+// OrthonormalityCheck reports how far a PCA result is from orthonormal loadings and
+// orthogonal scores
+type OrthonormalityCheck struct {
+	// LoadingsDeviation is the largest absolute element of PᵀP − I. It is NaN for
+	// kernel PCA, which has no loadings.
+	LoadingsDeviation float64
+	// ScoresDeviation is the largest absolute cosine between two score vectors. For
+	// mean-centered data this is their correlation; for kernel PCA it measures the
+	// orthogonality of the principal axes in feature space.
+	ScoresDeviation float64
+	Tolerance       float64
+}
+
+// Passed reports whether both deviations are within the tolerance
+func (c *OrthonormalityCheck) Passed() bool {
+	loadingsOK := math.IsNaN(c.LoadingsDeviation) || c.LoadingsDeviation <= c.Tolerance
+	return loadingsOK && c.ScoresDeviation <= c.Tolerance
+}
+
+// CheckOrthonormality verifies that the loadings of result are orthonormal (PᵀP ≈ I)
+// and that its score vectors are mutually orthogonal. Kernel PCA results are only
+// checked in feature space, through their scores.
+func CheckOrthonormality(result *types.PCAResult, tolerance float64) (*OrthonormalityCheck, error) {
+	if result == nil || len(result.Scores) == 0 {
+		return nil, fmt.Errorf("no scores to check")
+	}
+	if tolerance <= 0 {
+		return nil, fmt.Errorf("tolerance must be positive, got %g", tolerance)
+	}
+
+	check := &OrthonormalityCheck{
+		LoadingsDeviation: math.NaN(),
+		ScoresDeviation:   ScoreCosines(result.Scores),
+		Tolerance:         tolerance,
+	}
+	if result.Method != "kernel" && len(result.Loadings) > 0 {
+		check.LoadingsDeviation = LoadingsOrthonormality(result.Loadings)
+	}
+	return check, nil
+}
+
+// LoadingsOrthonormality returns the largest absolute element of PᵀP − I for the
+// loadings P (features × components). A value of zero means P is exactly orthonormal.
+func LoadingsOrthonormality(loadings types.Matrix) float64 {
+	if len(loadings) == 0 {
+		return 0
+	}
+	k := len(loadings[0])
+	maxDev := 0.0
+	for a := 0; a < k; a++ {
+		for b := a; b < k; b++ {
+			var dot float64
+			for j := range loadings {
+				dot += loadings[j][a] * loadings[j][b]
+			}
+			if a == b {
+				dot--
+			}
+			maxDev = math.Max(maxDev, math.Abs(dot))
+		}
+	}
+	return maxDev
+}
+
+// ScoreCosines returns the largest absolute cosine of the angle between two score
+// vectors, a scale-free version of ScoreOrthogonality. Score vectors that are all
+// zero are skipped.
+func ScoreCosines(scores types.Matrix) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	k := len(scores[0])
+	norms := make([]float64, k)
+	for a := 0; a < k; a++ {
+		for i := range scores {
+			norms[a] += scores[i][a] * scores[i][a]
+		}
+		norms[a] = math.Sqrt(norms[a])
+	}
+
+	maxCos := 0.0
+	for a := 0; a < k; a++ {
+		for b := a + 1; b < k; b++ {
+			if norms[a] == 0 || norms[b] == 0 {
+				continue
+			}
+			var dot float64
+			for i := range scores {
+				dot += scores[i][a] * scores[i][b]
+			}
+			maxCos = math.Max(maxCos, math.Abs(dot)/(norms[a]*norms[b]))
+		}
+	}
+	return maxCos
+}
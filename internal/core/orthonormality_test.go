@@ -0,0 +1,61 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestCheckOrthonormality(t *testing.T) {
+	data := types.Matrix{
+		{2.5, 2.4, 1.2},
+		{0.5, 0.7, 0.3},
+		{2.2, 2.9, 1.1},
+		{1.9, 2.2, 0.8},
+		{3.1, 3.0, 1.6},
+		{2.3, 2.7, 0.9},
+		{2.0, 1.6, 1.4},
+		{1.0, 1.1, 0.2},
+	}
+	result, err := NewPCAEngine().Fit(data, types.PCAConfig{Components: 3, Method: "svd", MeanCenter: true})
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+
+	check, err := CheckOrthonormality(result, DefaultOrthonormalityTolerance)
+	if err != nil {
+		t.Fatalf("CheckOrthonormality failed: %v", err)
+	}
+	if !check.Passed() {
+		t.Errorf("SVD result failed the check: loadings %g, scores %g", check.LoadingsDeviation, check.ScoresDeviation)
+	}
+
+	// Mixing two loading vectors breaks orthonormality
+	for j := range result.Loadings {
+		result.Loadings[j][1] += 0.1 * result.Loadings[j][0]
+	}
+	check, err = CheckOrthonormality(result, DefaultOrthonormalityTolerance)
+	if err != nil {
+		t.Fatalf("CheckOrthonormality failed: %v", err)
+	}
+	if check.Passed() || check.LoadingsDeviation < 0.09 {
+		t.Errorf("corrupted loadings passed the check: loadings deviation %g", check.LoadingsDeviation)
+	}
+
+	// Kernel PCA is checked through its scores only
+	kernel := &types.PCAResult{Method: "kernel", Scores: types.Matrix{{1, 1}, {1, -1}, {0, 0}}}
+	check, err = CheckOrthonormality(kernel, DefaultOrthonormalityTolerance)
+	if err != nil {
+		t.Fatalf("CheckOrthonormality failed: %v", err)
+	}
+	if !math.IsNaN(check.LoadingsDeviation) || !check.Passed() {
+		t.Errorf("orthogonal kernel scores failed the check: %+v", check)
+	}
+}
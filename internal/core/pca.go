@@ -7,8 +7,11 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strings"
 
 	"github.com/bitjungle/gopca/internal/utils"
 	"github.com/bitjungle/gopca/pkg/types"
@@ -25,6 +28,19 @@ type PCAImpl struct {
 
 	// Configuration
 	config types.PCAConfig
+
+	// Columns with no observed values, excluded from the last native NIPALS fit
+	allMissingColumns []int
+
+	// Preprocessed data of the last NIPALS fit on complete data, kept when
+	// config.KeepFitData is set so that FitAdditionalComponents can extend the model
+	fitData *mat.Dense
+
+	// Warnings of the last fit, returned in its result
+	warnings []string
+
+	// Power iterations spent by the last NIPALS fit
+	iterations int
 }
 
 // NewPCAEngine creates a new PCA engine instance
@@ -44,11 +60,36 @@ func NewPCAEngineForMethod(method string) types.PCAEngine {
 
 // Fit trains the PCA model on the provided data
 func (p *PCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*types.PCAResult, error) {
+	return p.FitContext(context.Background(), data, config)
+}
+
+// FitContext trains the PCA model like Fit, but stops early and returns the context's
+// error when ctx is cancelled. Cancellation is checked between NIPALS components and
+// iterations and between the stages of the SVD algorithm.
+func (p *PCAImpl) FitContext(ctx context.Context, data types.Matrix, config types.PCAConfig) (*types.PCAResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if err := ValidatePCAInput(data, config); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
 	p.config = config
+	p.warnings = nil
+
+	data, droppedRows, warning, err := handleZeroVarianceRows(data, config)
+	if err != nil {
+		return nil, err
+	}
+	if warning != "" {
+		p.warnings = append(p.warnings, warning)
+	}
+	if len(droppedRows) > 0 {
+		if err := ValidatePCAInput(data, config); err != nil {
+			return nil, fmt.Errorf("validation failed after dropping %d zero-variance rows: %w", len(droppedRows), err)
+		}
+	}
 
 	// Convert to gonum matrix
 	X := utils.MatrixToDense(data)
@@ -78,6 +119,9 @@ func (p *PCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*types.PCAResu
 	if !usingNativeMissing && (config.MeanCenter || config.StandardScale || config.RobustScale || config.ScaleOnly || config.SNV || config.VectorNorm) {
 		// Create preprocessor with the appropriate settings
 		p.preprocessor = NewPreprocessorWithScaleOnly(config.MeanCenter, config.StandardScale, config.RobustScale, config.ScaleOnly, config.SNV, config.VectorNorm)
+		p.preprocessor.VectorNormType = VectorNormType(config.VectorNormType)
+		p.preprocessor.RobustCenter = RobustCenter(config.RobustCenter)
+		p.preprocessor.RobustScaleStat = RobustScaleStat(config.RobustScaleStat)
 
 		// Convert to types.Matrix for preprocessor
 		typeMatrix := utils.DenseToMatrix(X)
@@ -88,39 +132,90 @@ func (p *PCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*types.PCAResu
 			return nil, fmt.Errorf("preprocessing failed: %w", err)
 		}
 
+		p.warnings = append(p.warnings, p.preprocessor.Warnings()...)
+
 		// Convert back to mat.Dense
 		X = utils.MatrixToDense(processedData)
 	} else if usingNativeMissing && (config.StandardScale || config.RobustScale || config.ScaleOnly || config.SNV || config.VectorNorm) {
-		// Log warning: preprocessing (except mean centering) is not supported with native missing value handling
+		// Preprocessing (except mean centering) is not supported with native missing value handling
 		// Mean centering is handled internally by the NIPALS algorithm for missing data
-		fmt.Printf("Warning: Preprocessing options (except mean centering) are not supported with NIPALS native missing value handling. These options were ignored.\n")
+		p.warnings = append(p.warnings, "preprocessing options (except mean centering) are not supported with "+
+			"NIPALS native missing value handling and were ignored")
 	}
 
 	// Select PCA method
 	var scores, loadings *mat.Dense
 	var allEigenvalues []float64
-	var err error
+	method := config.Method
 
 	// Note: hasMissing has already been checked above to determine preprocessing behavior
 
 	switch config.Method {
 	case "svd", "":
-		scores, loadings, allEigenvalues, err = p.svdAlgorithm(X, config.Components)
+		scores, loadings, allEigenvalues, err = p.svdAlgorithm(ctx, X, config.Components)
 	case "nipals":
 		// Use native missing value handling only if strategy is native AND data has missing values
 		if config.MissingStrategy == types.MissingNative && hasMissing {
-			scores, loadings, allEigenvalues, err = p.nipalsAlgorithmWithMissing(X, config.Components)
+			scores, loadings, allEigenvalues, err = p.nipalsAlgorithmWithMissing(ctx, X, config.Components)
 		} else {
-			scores, loadings, allEigenvalues, err = p.nipalsAlgorithm(X, config.Components)
+			scores, loadings, allEigenvalues, err = p.nipalsAlgorithm(ctx, X, config.Components, nil, nil)
 		}
 	default:
 		return nil, fmt.Errorf("invalid PCA method: %s", config.Method)
 	}
 
+	// SVD gives the same components for complete data, so a NIPALS fit that does not
+	// converge can be recovered
+	var convergenceErr *NIPALSConvergenceError
+	if errors.As(err, &convergenceErr) && config.NIPALSFallbackToSVD && !hasMissing {
+		p.warnings = append(p.warnings, fmt.Sprintf("%v; refitting with SVD", err))
+		scores, loadings, allEigenvalues, err = p.svdAlgorithm(ctx, X, config.Components)
+		method = "svd"
+	}
+
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("PCA computation failed: %w", err)
 	}
 
+	p.fitData = nil
+	if method == "nipals" && !hasMissing && config.KeepFitData {
+		p.fitData = X
+	}
+
+	return p.buildResult(X, scores, loadings, allEigenvalues, method, droppedRows), nil
+}
+
+// buildResult discards components beyond the numerical rank of X, stores the
+// loadings for Transform and assembles the result of a fit from the scores, loadings
+// and eigenvalues computed by the algorithm
+func (p *PCAImpl) buildResult(X, scores, loadings *mat.Dense, allEigenvalues []float64, method string,
+	droppedRows []int) *types.PCAResult {
+	config := p.config
+
+	// Components beyond the numerical rank of the data carry only round-off, which
+	// gives arbitrary directions and meaningless percentages, so they are discarded
+	if allEigenvalues != nil {
+		n, m := X.Dims()
+		rank := stabilizeEigenvalues(allEigenvalues, max(n, m))
+		if _, nComponents := scores.Dims(); rank < nComponents {
+			keep := max(rank, 1)
+			discarded := make([]string, 0, nComponents-keep)
+			for k := keep; k < nComponents; k++ {
+				discarded = append(discarded, fmt.Sprintf("PC%d", k+1))
+			}
+			p.warnings = append(p.warnings, fmt.Sprintf("data has numerical rank %d, fewer than the %d components "+
+				"requested (rank-deficient or collinear columns); discarding near-zero components %s",
+				rank, nComponents, strings.Join(discarded, ", ")))
+			nScores, _ := scores.Dims()
+			nLoadings, _ := loadings.Dims()
+			scores = mat.DenseCopyOf(scores.Slice(0, nScores, 0, keep))
+			loadings = mat.DenseCopyOf(loadings.Slice(0, nLoadings, 0, keep))
+		}
+	}
+
 	// Store loadings for transform
 	p.loadings = loadings
 	_, actualComponents := scores.Dims()
@@ -195,20 +290,72 @@ func (p *PCAImpl) Fit(data types.Matrix, config types.PCAConfig) (*types.PCAResu
 		stddevs = p.preprocessor.GetStdDevs()
 	}
 
+	var loadingsMatrix types.Matrix
+	if !config.ScoresOnly {
+		loadingsMatrix = utils.DenseToMatrix(loadings)
+	}
+
 	return &types.PCAResult{
 		Scores:               utils.DenseToMatrix(scores),
-		Loadings:             utils.DenseToMatrix(loadings),
+		Loadings:             loadingsMatrix,
 		ExplainedVar:         eigenvalues,
 		ExplainedVarRatio:    explainedVarRatio,
 		CumulativeVar:        cumulativeVar,
 		ComponentLabels:      componentLabels,
 		ComponentsComputed:   actualComponents,
-		Method:               config.Method,
+		Method:               method,
 		PreprocessingApplied: config.MeanCenter || config.StandardScale || config.RobustScale,
 		Means:                means,
 		StdDevs:              stddevs,
 		AllEigenvalues:       allEigenvalues,
-	}, nil
+		DroppedRows:          droppedRows,
+		Warnings:             p.warnings,
+	}
+}
+
+// FitAdditionalComponents extends a NIPALS model fitted by this engine with extra
+// components, as when the number of components is increased interactively. The
+// components of prev are reused as they are and deflated from the data, so only the
+// new components are iterated; the result matches a fit with all the components from
+// scratch to within the NIPALS convergence tolerance. prev must be the result of the
+// last Fit of this engine with KeepFitData set, or of an earlier call to
+// FitAdditionalComponents, on complete data.
+func (p *PCAImpl) FitAdditionalComponents(prev *types.PCAResult, extra int) (*types.PCAResult, error) {
+	if !p.fitted || p.fitData == nil {
+		return nil, fmt.Errorf("additional components need a NIPALS model fitted on complete data with KeepFitData by this engine")
+	}
+	if prev == nil || len(prev.Scores) == 0 || len(prev.Loadings) == 0 {
+		return nil, fmt.Errorf("previous result has no scores or loadings")
+	}
+	if extra < 1 {
+		return nil, fmt.Errorf("number of additional components must be at least 1, got %d", extra)
+	}
+	p.warnings = nil
+
+	n, m := p.fitData.Dims()
+	k := len(prev.Scores[0])
+	if len(prev.Scores) != n || len(prev.Loadings) != m || len(prev.Loadings[0]) != k {
+		return nil, fmt.Errorf("previous result (%d×%d scores, %d×%d loadings) does not match the fitted data (%d×%d)",
+			len(prev.Scores), k, len(prev.Loadings), len(prev.Loadings[0]), n, m)
+	}
+	if k+extra > min(n, m) {
+		return nil, fmt.Errorf("cannot compute %d components for %d×%d data", k+extra, n, m)
+	}
+
+	p.config.Components = k + extra
+	scores, loadings, allEigenvalues, err := p.nipalsAlgorithm(context.Background(), p.fitData, k+extra,
+		utils.MatrixToDense(prev.Scores), utils.MatrixToDense(prev.Loadings))
+	if err != nil {
+		return nil, fmt.Errorf("PCA computation failed: %w", err)
+	}
+	return p.buildResult(p.fitData, scores, loadings, allEigenvalues, "nipals", prev.DroppedRows), nil
+}
+
+// Iterations returns the number of NIPALS power iterations spent by the last fit,
+// counting only the components computed by FitAdditionalComponents when it was last
+// called
+func (p *PCAImpl) Iterations() int {
+	return p.iterations
 }
 
 // Transform applies the fitted PCA model to new data
@@ -248,10 +395,78 @@ func (p *PCAImpl) FitTransform(data types.Matrix, config types.PCAConfig) (*type
 	return p.Fit(data, config)
 }
 
+// stabilizeEigenvalues clamps the small negative eigenvalues left by round-off to zero
+// and returns the numerical rank: the number of leading eigenvalues above the round-off
+// level of the largest. As for matrix rank, a singular value counts if it exceeds
+// maxDim·ε times the largest, so an eigenvalue counts if it exceeds (maxDim·ε)² times
+// the largest, where maxDim is the larger dimension of the data.
+func stabilizeEigenvalues(eigenvalues []float64, maxDim int) int {
+	largest := 0.0
+	for i, v := range eigenvalues {
+		if v < 0 {
+			eigenvalues[i] = 0
+		}
+		largest = max(largest, eigenvalues[i])
+	}
+	if largest == 0 {
+		return 0
+	}
+
+	tolerance := float64(maxDim) * machineEpsilon
+	tolerance *= tolerance * largest
+	for i, v := range eigenvalues {
+		if v <= tolerance {
+			return i
+		}
+	}
+	return len(eigenvalues)
+}
+
+// machineEpsilon is the spacing between 1 and the next larger float64
+var machineEpsilon = math.Nextafter(1, 2) - 1
+
+// NIPALSDefaultMaxIter is the number of iterations per component after which NIPALS
+// gives up, unless PCAConfig.NIPALSMaxIter is set
+const NIPALSDefaultMaxIter = 1000
+
+// NIPALSConvergenceError reports a NIPALS component that did not converge. Scores and
+// Loadings hold the components computed before it, which may help to decide how many
+// components the data supports.
+type NIPALSConvergenceError struct {
+	Component  int          // 1-based component that did not converge
+	Iterations int          // Iterations spent on it
+	Scores     types.Matrix // Scores of the converged components (n × Component-1)
+	Loadings   types.Matrix // Loadings of the converged components (m × Component-1)
+}
+
+// Error implements the error interface
+func (e *NIPALSConvergenceError) Error() string {
+	return fmt.Sprintf("NIPALS did not converge for component %d after %d iterations (%d component(s) computed)",
+		e.Component, e.Iterations, e.Component-1)
+}
+
+// newNIPALSConvergenceError builds the error for component k (0-based) from the
+// first k columns of the scores and loadings
+func newNIPALSConvergenceError(k, iterations int, T, P *mat.Dense) *NIPALSConvergenceError {
+	n, _ := T.Dims()
+	m, _ := P.Dims()
+	convergenceErr := &NIPALSConvergenceError{Component: k + 1, Iterations: iterations}
+	if k > 0 {
+		convergenceErr.Scores = utils.DenseToMatrix(T.Slice(0, n, 0, k).(*mat.Dense))
+		convergenceErr.Loadings = utils.DenseToMatrix(P.Slice(0, m, 0, k).(*mat.Dense))
+	}
+	return convergenceErr
+}
+
 // nipalsAlgorithm implements the NIPALS (Nonlinear Iterative Partial Least Squares) algorithm for PCA
 // Reference: Wold, H. (1966). Estimation of principal components and related models by iterative least squares.
 // In P.R. Krishnaiah (Ed.), Multivariate Analysis (pp. 391-420). Academic Press.
-func (p *PCAImpl) nipalsAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *mat.Dense, []float64, error) {
+//
+// If prevScores and prevLoadings are given, their components are taken as the first
+// components of the model: they are deflated from X and only the remaining components
+// are computed.
+func (p *PCAImpl) nipalsAlgorithm(ctx context.Context, X *mat.Dense, nComponents int,
+	prevScores, prevLoadings *mat.Dense) (*mat.Dense, *mat.Dense, []float64, error) {
 	n, m := X.Dims()
 
 	// Initialize matrices
@@ -260,11 +475,33 @@ func (p *PCAImpl) nipalsAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *m
 	// Working copy of X for deflation
 	Xwork := CreateWorkingCopy(X)
 
+	start := 0
+	if prevScores != nil {
+		_, start = prevScores.Dims()
+		for k := 0; k < start; k++ {
+			tData := mat.Col(nil, k, prevScores)
+			pData := mat.Col(nil, k, prevLoadings)
+			T.SetCol(k, tData)
+			P.SetCol(k, pData)
+			deflateComponent(Xwork, tData, pData)
+		}
+	}
+	iterations := 0
+
 	// Tolerance for convergence
 	const tolerance = 1e-8
-	const maxIter = 1000
+	maxIter := p.config.NIPALSMaxIter
+	if maxIter <= 0 {
+		maxIter = NIPALSDefaultMaxIter
+	}
+
+	orthogonalize := p.config.OrthogonalizeScores
+
+	for k := start; k < nComponents; k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
 
-	for k := 0; k < nComponents; k++ {
 		// Initialize score vector t with column having maximum variance
 		t := mat.NewVecDense(n, nil)
 		maxVar := 0.0
@@ -306,6 +543,10 @@ func (p *PCAImpl) nipalsAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *m
 		var p *mat.VecDense
 
 		for iter := 0; iter < maxIter; iter++ {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, nil, err
+			}
+
 			// Save old t for convergence check
 			tOld = mat.NewVecDense(n, nil)
 			tOld.CopyVec(t)
@@ -332,6 +573,7 @@ func (p *PCAImpl) nipalsAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *m
 			t.ScaleVec(1.0/pNormSq, t)
 
 			// Check convergence
+			iterations++
 			diff := mat.NewVecDense(n, nil)
 			diff.SubVec(t, tOld)
 			if mat.Norm(diff, 2) < tolerance {
@@ -341,7 +583,7 @@ func (p *PCAImpl) nipalsAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *m
 		}
 
 		if !converged {
-			return nil, nil, nil, fmt.Errorf("NIPALS did not converge for component %d", k+1)
+			return nil, nil, nil, newNIPALSConvergenceError(k, maxIter, T, P)
 		}
 
 		// Store component
@@ -353,20 +595,18 @@ func (p *PCAImpl) nipalsAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *m
 		for i := 0; i < m; i++ {
 			pData[i] = p.AtVec(i)
 		}
+
+		// Optionally remove any drift towards previously extracted scores
+		if orthogonalize && k > 0 {
+			orthogonalizeAgainst(tData, T, k)
+		}
+
 		T.SetCol(k, tData)
 		P.SetCol(k, pData)
 
-		// Deflate X: X = X - t * p^T
-		// This removes the contribution of the current component from the data matrix,
-		// allowing subsequent components to capture remaining variance.
-		// Mathematical explanation: We're projecting X onto the space orthogonal to
-		// the current principal component, ensuring orthogonality between components.
-		tMat := mat.NewDense(n, 1, tData) // Score vector as column matrix (n×1)
-		pMat := mat.NewDense(1, m, pData) // Loading vector as row matrix (1×m)
-		deflation := mat.NewDense(n, m, nil)
-		deflation.Mul(tMat, pMat)   // Outer product: t * p^T gives rank-1 approximation
-		Xwork.Sub(Xwork, deflation) // Remove this component's contribution
+		deflateComponent(Xwork, tData, pData)
 	}
+	p.iterations = iterations
 
 	// Calculate eigenvalues from scores for retained components
 	// For NIPALS, eigenvalue = variance of the score vector
@@ -417,9 +657,34 @@ func (p *PCAImpl) nipalsAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *m
 	return T, P, allEigenvalues, nil
 }
 
-// nipalsAlgorithmWithMissing implements NIPALS with native missing value handling
-func (p *PCAImpl) nipalsAlgorithmWithMissing(X *mat.Dense, nComponents int) (*mat.Dense, *mat.Dense, []float64, error) {
+// deflateComponent removes the contribution of a component from X: X = X - t * p^T.
+// This allows subsequent components to capture the remaining variance.
+// Mathematical explanation: We're projecting X onto the space orthogonal to
+// the current principal component, ensuring orthogonality between components.
+func deflateComponent(X *mat.Dense, tData, pData []float64) {
 	n, m := X.Dims()
+	tMat := mat.NewDense(n, 1, tData) // Score vector as column matrix (n×1)
+	pMat := mat.NewDense(1, m, pData) // Loading vector as row matrix (1×m)
+	deflation := mat.NewDense(n, m, nil)
+	deflation.Mul(tMat, pMat) // Outer product: t * p^T gives rank-1 approximation
+	X.Sub(X, deflation)       // Remove this component's contribution
+}
+
+// nipalsAlgorithmWithMissing implements NIPALS with native missing value handling.
+// Columns without any observed values carry no information; they are excluded from
+// the fit with a warning and get zero loadings.
+func (p *PCAImpl) nipalsAlgorithmWithMissing(ctx context.Context, X *mat.Dense, nComponents int) (*mat.Dense, *mat.Dense, []float64, error) {
+	n, m := X.Dims()
+
+	p.allMissingColumns = allMissingColumns(X)
+	if len(p.allMissingColumns) == m {
+		return nil, nil, nil, fmt.Errorf("all columns contain only missing values")
+	}
+	if len(p.allMissingColumns) > 0 {
+		p.warnings = append(p.warnings, fmt.Sprintf("%d column(s) contain only missing values and were excluded from NIPALS: %s",
+			len(p.allMissingColumns), formatColumnNumbers(p.allMissingColumns)))
+		return p.nipalsWithoutColumns(ctx, X, nComponents, p.allMissingColumns)
+	}
 
 	// Initialize matrices
 	T, P := InitializeScoresAndLoadings(n, m, nComponents)
@@ -458,9 +723,18 @@ func (p *PCAImpl) nipalsAlgorithmWithMissing(X *mat.Dense, nComponents int) (*ma
 
 	// Tolerance for convergence
 	const tolerance = 1e-8
-	const maxIter = 1000
+	maxIter := p.config.NIPALSMaxIter
+	if maxIter <= 0 {
+		maxIter = NIPALSDefaultMaxIter
+	}
+
+	orthogonalize := p.config.OrthogonalizeScores
 
 	for k := 0; k < nComponents; k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+
 		// Initialize score vector t with column having maximum non-missing variance
 		t := mat.NewVecDense(n, nil)
 		maxVar := 0.0
@@ -526,6 +800,10 @@ func (p *PCAImpl) nipalsAlgorithmWithMissing(X *mat.Dense, nComponents int) (*ma
 		var p *mat.VecDense
 
 		for iter := 0; iter < maxIter; iter++ {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, nil, err
+			}
+
 			// Save old t for convergence check
 			tOld = mat.NewVecDense(n, nil)
 			tOld.CopyVec(t)
@@ -598,7 +876,7 @@ func (p *PCAImpl) nipalsAlgorithmWithMissing(X *mat.Dense, nComponents int) (*ma
 		}
 
 		if !converged {
-			return nil, nil, nil, fmt.Errorf("NIPALS did not converge for component %d", k+1)
+			return nil, nil, nil, newNIPALSConvergenceError(k, maxIter, T, P)
 		}
 
 		// Store component
@@ -610,6 +888,12 @@ func (p *PCAImpl) nipalsAlgorithmWithMissing(X *mat.Dense, nComponents int) (*ma
 		for i := 0; i < m; i++ {
 			pData[i] = p.AtVec(i)
 		}
+
+		// Optionally remove any drift towards previously extracted scores
+		if orthogonalize && k > 0 {
+			orthogonalizeAgainst(tData, T, k)
+		}
+
 		T.SetCol(k, tData)
 		P.SetCol(k, pData)
 
@@ -681,6 +965,121 @@ func (p *PCAImpl) nipalsAlgorithmWithMissing(X *mat.Dense, nComponents int) (*ma
 	return T, P, allEigenvalues, nil
 }
 
+// nipalsWithoutColumns fits native NIPALS on X without the excluded columns and
+// reinserts zero loadings for them
+func (p *PCAImpl) nipalsWithoutColumns(ctx context.Context, X *mat.Dense, nComponents int,
+	excluded []int) (*mat.Dense, *mat.Dense, []float64, error) {
+	n, m := X.Dims()
+	skip := make(map[int]bool, len(excluded))
+	for _, j := range excluded {
+		skip[j] = true
+	}
+	kept := make([]int, 0, m-len(excluded))
+	for j := 0; j < m; j++ {
+		if !skip[j] {
+			kept = append(kept, j)
+		}
+	}
+
+	reduced := mat.NewDense(n, len(kept), nil)
+	for k, j := range kept {
+		reduced.SetCol(k, mat.Col(nil, j, X))
+	}
+
+	T, reducedP, eigenvalues, err := p.nipalsAlgorithmWithMissing(ctx, reduced, min(nComponents, len(kept)))
+	var convergenceErr *NIPALSConvergenceError
+	if errors.As(err, &convergenceErr) && convergenceErr.Loadings != nil {
+		// Report the partial loadings for all columns, with zeros for the excluded ones
+		loadings := make(types.Matrix, m)
+		for j := range loadings {
+			loadings[j] = make([]float64, len(convergenceErr.Loadings[0]))
+		}
+		for k, j := range kept {
+			loadings[j] = convergenceErr.Loadings[k]
+		}
+		convergenceErr.Loadings = loadings
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	p.allMissingColumns = excluded
+
+	_, nComp := reducedP.Dims()
+	P := mat.NewDense(m, nComp, nil)
+	for k, j := range kept {
+		P.SetRow(j, mat.Row(nil, k, reducedP))
+	}
+	return T, P, eigenvalues, nil
+}
+
+// allMissingColumns returns the indices of the columns of X that contain only NaN
+func allMissingColumns(X *mat.Dense) []int {
+	n, m := X.Dims()
+	var cols []int
+	for j := 0; j < m; j++ {
+		missing := true
+		for i := 0; i < n && missing; i++ {
+			missing = math.IsNaN(X.At(i, j))
+		}
+		if missing {
+			cols = append(cols, j)
+		}
+	}
+	return cols
+}
+
+// orthogonalizeAgainst removes the projections of t onto the first k columns of T
+// using classical Gram-Schmidt with one reorthogonalization pass ("twice is enough").
+// t is modified in place.
+func orthogonalizeAgainst(t []float64, T *mat.Dense, k int) {
+	n := len(t)
+	for pass := 0; pass < 2; pass++ {
+		// Classical Gram-Schmidt: all coefficients are computed from the same t
+		coeffs := make([]float64, k)
+		for j := 0; j < k; j++ {
+			var dot, norm float64
+			for i := 0; i < n; i++ {
+				v := T.At(i, j)
+				dot += v * t[i]
+				norm += v * v
+			}
+			if norm > 0 {
+				coeffs[j] = dot / norm
+			}
+		}
+		for j := 0; j < k; j++ {
+			if coeffs[j] == 0 {
+				continue
+			}
+			for i := 0; i < n; i++ {
+				t[i] -= coeffs[j] * T.At(i, j)
+			}
+		}
+	}
+}
+
+// ScoreOrthogonality returns the largest absolute off-diagonal element of TᵀT.
+// A value of zero means the score vectors are exactly orthogonal.
+func ScoreOrthogonality(scores types.Matrix) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	k := len(scores[0])
+	maxOff := 0.0
+	for a := 0; a < k; a++ {
+		for b := a + 1; b < k; b++ {
+			var dot float64
+			for i := range scores {
+				dot += scores[i][a] * scores[i][b]
+			}
+			if math.Abs(dot) > maxOff {
+				maxOff = math.Abs(dot)
+			}
+		}
+	}
+	return maxOff
+}
+
 // svdAlgorithm implements SVD-based PCA using Singular Value Decomposition
 // The scores are computed as T = U * Σ and loadings as P = V
 //
@@ -690,15 +1089,22 @@ func (p *PCAImpl) nipalsAlgorithmWithMissing(X *mat.Dense, nComponents int) (*ma
 //     (Chapter 8: The Singular Value Decomposition)
 //
 // Algorithm complexity: O(min(mn², m²n)) where m = samples, n = features
-func (p *PCAImpl) svdAlgorithm(X *mat.Dense, nComponents int) (*mat.Dense, *mat.Dense, []float64, error) {
+func (p *PCAImpl) svdAlgorithm(ctx context.Context, X *mat.Dense, nComponents int) (*mat.Dense, *mat.Dense, []float64, error) {
 	n, m := X.Dims()
 
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
 	// Perform SVD: X = U * Σ * V^T
 	var svd mat.SVD
 	ok := svd.Factorize(X, mat.SVDThin)
 	if !ok {
 		return nil, nil, nil, fmt.Errorf("SVD factorization failed")
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
 
 	// Get U and V matrices
 	var u, v mat.Dense
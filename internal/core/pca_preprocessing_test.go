@@ -15,12 +15,12 @@ import (
 
 // TestPCAWithDifferentPreprocessing tests that PCA works correctly with different preprocessing options
 func TestPCAWithDifferentPreprocessing(t *testing.T) {
-	// Create test data
+	// Create test data, of rank 2 after centering so that two components are meaningful
 	data := types.Matrix{
 		{1.0, 2.0, 3.0},
-		{4.0, 5.0, 6.0},
+		{4.0, 5.5, 5.5},
 		{7.0, 8.0, 9.0},
-		{10.0, 11.0, 12.0},
+		{10.0, 10.5, 12.5},
 		{13.0, 14.0, 15.0},
 	}
 
@@ -222,11 +222,11 @@ func TestMutuallyExclusivePreprocessing(t *testing.T) {
 
 // TestPreprocessingWithTransform tests that transform applies the same preprocessing as fit
 func TestPreprocessingWithTransform(t *testing.T) {
-	// Training data
+	// Training data, of rank 2 after centering so that two components are meaningful
 	trainData := types.Matrix{
 		{1.0, 2.0, 3.0},
-		{4.0, 5.0, 6.0},
-		{7.0, 8.0, 9.0},
+		{4.0, 5.5, 6.0},
+		{7.0, 8.0, 9.5},
 		{10.0, 11.0, 12.0},
 	}
 
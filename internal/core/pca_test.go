@@ -7,7 +7,11 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"math"
+	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -191,6 +195,41 @@ func TestTransform(t *testing.T) {
 	}
 }
 
+func TestPCAScoresOnly(t *testing.T) {
+	data := createTestMatrix()
+	config := types.PCAConfig{Components: 2, MeanCenter: true, Method: "svd"}
+
+	full, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+
+	config.ScoresOnly = true
+	engine := NewPCAEngine()
+	result, err := engine.Fit(data, config)
+	if err != nil {
+		t.Fatalf("Scores-only fit failed: %v", err)
+	}
+	if result.Loadings != nil {
+		t.Errorf("Expected no loadings in a scores-only result, got %v", result.Loadings)
+	}
+	for i := range full.Scores {
+		for j := range full.Scores[i] {
+			if math.Abs(result.Scores[i][j]-full.Scores[i][j]) > 1e-12 {
+				t.Fatalf("Score [%d][%d] = %g, want %g", i, j, result.Scores[i][j], full.Scores[i][j])
+			}
+		}
+	}
+	if len(result.ExplainedVarRatio) != 2 {
+		t.Errorf("Expected the explained variance of 2 components, got %v", result.ExplainedVarRatio)
+	}
+
+	// The engine keeps the loadings it needs to project new data
+	if _, err := engine.Transform(data); err != nil {
+		t.Errorf("Transform after a scores-only fit failed: %v", err)
+	}
+}
+
 // Test SVD-based PCA
 func TestPCASVD(t *testing.T) {
 	data := createTestMatrix()
@@ -412,3 +451,408 @@ func BenchmarkSVD(b *testing.B) {
 		}
 	}
 }
+
+// Test that Gram-Schmidt reorthogonalization keeps NIPALS scores orthogonal
+// on near-collinear data
+func TestNIPALSOrthogonalizeScores(t *testing.T) {
+	// Columns share one dominant latent signal plus weaker signals whose
+	// strength decays geometrically, giving a wide eigenvalue spread
+	n, m := 60, 12
+	data := make(types.Matrix, n)
+	for i := 0; i < n; i++ {
+		data[i] = make([]float64, m)
+		x := float64(i)
+		for j := 0; j < m; j++ {
+			v := 0.0
+			for k := 0; k < m; k++ {
+				v += math.Pow(10, -0.7*float64(k)) * math.Sin(x*float64(k+1)*0.37+float64(j*k)*0.11)
+			}
+			data[i][j] = v
+		}
+	}
+
+	config := types.PCAConfig{
+		Components: 8,
+		MeanCenter: true,
+		Method:     "nipals",
+	}
+
+	plain, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+
+	config.OrthogonalizeScores = true
+	ortho, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit with orthogonalization failed: %v", err)
+	}
+
+	plainOff := ScoreOrthogonality(plain.Scores)
+	orthoOff := ScoreOrthogonality(ortho.Scores)
+	t.Logf("max |off-diagonal of TᵀT|: plain=%.3e orthogonalized=%.3e", plainOff, orthoOff)
+
+	if orthoOff >= plainOff {
+		t.Errorf("Orthogonalized scores less orthogonal than plain scores: %.3e > %.3e", orthoOff, plainOff)
+	}
+	if orthoOff > 1e-12 {
+		t.Errorf("Orthogonalized scores not orthogonal, max off-diagonal = %.3e", orthoOff)
+	}
+}
+
+func TestPCADropZeroVarianceRows(t *testing.T) {
+	data := types.Matrix{
+		{1.0, 2.0, 3.5},
+		{2.5, 1.0, 4.0},
+		{7.0, 7.0, 7.0}, // all-identical row
+		{3.0, 4.5, 2.0},
+		{4.0, 3.0, 5.5},
+		{0.5, 2.5, 1.0},
+	}
+	config := types.PCAConfig{Components: 2, MeanCenter: true, Method: "svd"}
+
+	// Without dropping, the row is kept and reported
+	result, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	if len(result.Scores) != 6 || len(result.DroppedRows) != 0 {
+		t.Errorf("Expected all 6 rows kept, got %d score rows and dropped %v", len(result.Scores), result.DroppedRows)
+	}
+	if warnings := strings.Join(result.Warnings, "\n"); !strings.Contains(warnings, "zero variance") ||
+		!strings.Contains(warnings, "rows 3") {
+		t.Errorf("Expected a warning about row 3, got %q", warnings)
+	}
+
+	// With dropping, scores match a fit on the data without the row, row for row
+	for _, method := range []string{"svd", "kernel"} {
+		config := config
+		config.Method = method
+		if method == "kernel" {
+			config = types.PCAConfig{Components: 2, Method: "kernel", KernelType: "rbf", KernelGamma: 0.1}
+		}
+		config.DropZeroVarianceRows = true
+		result, err := NewPCAEngineForMethod(method).Fit(data, config)
+		if err != nil {
+			t.Fatalf("%s: PCA fit failed: %v", method, err)
+		}
+		if len(result.DroppedRows) != 1 || result.DroppedRows[0] != 2 {
+			t.Errorf("%s: expected dropped rows [2], got %v", method, result.DroppedRows)
+		}
+		if !strings.Contains(strings.Join(result.Warnings, "\n"), "dropped 1 row(s)") {
+			t.Errorf("%s: expected a warning about the dropped row, got %q", method, result.Warnings)
+		}
+
+		cleanData, keepRows := RemoveRows(data, result.DroppedRows)
+		if len(keepRows) != 5 || keepRows[2] != 3 {
+			t.Errorf("%s: unexpected kept rows %v", method, keepRows)
+		}
+		config.DropZeroVarianceRows = false
+		expected, err := NewPCAEngineForMethod(method).Fit(cleanData, config)
+		if err != nil {
+			t.Fatalf("%s: PCA fit on clean data failed: %v", method, err)
+		}
+		if len(result.Scores) != len(expected.Scores) {
+			t.Fatalf("%s: expected %d score rows, got %d", method, len(expected.Scores), len(result.Scores))
+		}
+		for i := range expected.Scores {
+			for j := range expected.Scores[i] {
+				if math.Abs(result.Scores[i][j]-expected.Scores[i][j]) > 1e-10 {
+					t.Errorf("%s: score [%d][%d]: expected %f, got %f", method, i, j, expected.Scores[i][j], result.Scores[i][j])
+				}
+			}
+		}
+	}
+
+	// A caller that checked the rows itself gets no second check
+	config.ZeroVarianceRowsChecked = true
+	result, err = NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings with ZeroVarianceRowsChecked, got %q", result.Warnings)
+	}
+}
+
+func TestCheckForZeroVarianceRows(t *testing.T) {
+	data := types.Matrix{
+		{1.0, 2.0, 3.5},
+		{2.5, 1.0, 4.0},
+		{7.0, 7.0, 7.0}, // all-identical row
+		{3.0, 4.5, 2.0},
+		{math.NaN(), 5.0, 5.0}, // identical non-missing values
+		{0.5, 2.5, 1.0},
+	}
+
+	zeroRows, err := CheckForZeroVarianceRows(data)
+	if err != nil {
+		t.Fatalf("CheckForZeroVarianceRows failed: %v", err)
+	}
+	if len(zeroRows) != 2 || zeroRows[0] != 2 || zeroRows[1] != 4 {
+		t.Fatalf("Expected zero-variance rows [2 4], got %v", zeroRows)
+	}
+
+	cleanData, keepRows := RemoveRows(data, zeroRows)
+	if len(cleanData) != 4 || len(keepRows) != 4 || keepRows[2] != 3 || keepRows[3] != 5 {
+		t.Errorf("Unexpected kept rows %v", keepRows)
+	}
+
+	// Fitting leaves rows alone, including one that is all zeros once centered
+	centered := types.Matrix{{1, 2, 7}, {3, 6, 5}, {2, 4, 6}, {0, 5, 9}, {4, 3, 3}}
+	result, err := NewPCAEngine().Fit(centered, types.PCAConfig{Components: 2, MeanCenter: true, Method: "svd"})
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	if len(result.Scores) != len(centered) {
+		t.Errorf("Expected %d score rows, got %d", len(centered), len(result.Scores))
+	}
+}
+
+func TestPCAFitContextCancelNIPALS(t *testing.T) {
+	// Large enough that a full NIPALS fit takes far longer than the cancel delay
+	rng := rand.New(rand.NewSource(42))
+	data := make(types.Matrix, 2000)
+	for i := range data {
+		data[i] = make([]float64, 300)
+		for j := range data[i] {
+			data[i][j] = rng.NormFloat64()
+		}
+	}
+
+	config := types.PCAConfig{
+		Components: 100,
+		MeanCenter: true,
+		Method:     "nipals",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := types.FitContext(ctx, NewPCAEngine(), data, config)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if result != nil {
+		t.Error("expected nil result after cancellation")
+	}
+	if elapsed > time.Second {
+		t.Errorf("FitContext took %v to return after cancellation", elapsed)
+	}
+
+	// An already cancelled context returns before any work is done
+	if _, err := types.FitContext(ctx, NewPCAEngine(), data, config); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled for cancelled context, got %v", err)
+	}
+}
+
+// nonConvergingData has one dominant direction, which NIPALS finds in a few
+// iterations, and two with nearly equal variance, which take many
+func nonConvergingData() types.Matrix {
+	rng := rand.New(rand.NewSource(7))
+	data := make(types.Matrix, 30)
+	for i := range data {
+		data[i] = []float64{100 * rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}
+	}
+	return data
+}
+
+func TestNIPALSFallbackToSVD(t *testing.T) {
+	data := nonConvergingData()
+	config := types.PCAConfig{
+		Components:    2,
+		MeanCenter:    true,
+		Method:        "nipals",
+		NIPALSMaxIter: 20,
+	}
+
+	_, err := NewPCAEngine().Fit(data, config)
+	var convergenceErr *NIPALSConvergenceError
+	if !errors.As(err, &convergenceErr) {
+		t.Fatalf("expected a NIPALSConvergenceError, got %v", err)
+	}
+	if convergenceErr.Component != 2 || len(convergenceErr.Loadings) != 3 || len(convergenceErr.Loadings[0]) != 1 {
+		t.Errorf("expected component 2 to fail after one converged component, got %+v", convergenceErr)
+	}
+
+	config.NIPALSFallbackToSVD = true
+	result, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("fit with SVD fallback failed: %v", err)
+	}
+	if result.Method != "svd" || result.ComponentsComputed != 2 {
+		t.Errorf("expected 2 SVD components, got %d from %s", result.ComponentsComputed, result.Method)
+	}
+
+	config.Method = "svd"
+	want, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("SVD fit failed: %v", err)
+	}
+	for k := range want.ExplainedVar {
+		if math.Abs(result.ExplainedVar[k]-want.ExplainedVar[k]) > 1e-10 {
+			t.Errorf("component %d: explained variance %g, want %g", k+1, result.ExplainedVar[k], want.ExplainedVar[k])
+		}
+	}
+}
+
+func TestNIPALSMissingNonConvergence(t *testing.T) {
+	data := nonConvergingData()
+	data[3][1] = math.NaN()
+	config := types.PCAConfig{
+		Components:          2,
+		MeanCenter:          true,
+		Method:              "nipals",
+		MissingStrategy:     types.MissingNative,
+		NIPALSMaxIter:       20,
+		NIPALSFallbackToSVD: true,
+	}
+
+	// SVD cannot fit data with missing values, so there is no fallback
+	_, err := NewPCAEngine().Fit(data, config)
+	var convergenceErr *NIPALSConvergenceError
+	if !errors.As(err, &convergenceErr) {
+		t.Fatalf("expected a NIPALSConvergenceError, got %v", err)
+	}
+	if len(convergenceErr.Scores) != len(data) || len(convergenceErr.Scores[0]) != convergenceErr.Component-1 {
+		t.Errorf("expected the scores of %d converged component(s), got %v",
+			convergenceErr.Component-1, convergenceErr.Scores)
+	}
+}
+
+func TestPCARankDeficient(t *testing.T) {
+	// Columns 3 and 4 are linear combinations of columns 1 and 2, so the centered data has rank 2
+	data := types.Matrix{
+		{1.0, 2.0, 3.0, -1.0},
+		{2.0, 1.0, 3.0, 1.0},
+		{3.0, 5.0, 8.0, -2.0},
+		{4.0, 3.0, 7.0, 1.0},
+		{5.0, 7.0, 12.0, -2.0},
+		{6.0, 4.0, 10.0, 2.0},
+	}
+	config := types.PCAConfig{Components: 4, MeanCenter: true, Method: "svd"}
+
+	result, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("fit failed: %v", err)
+	}
+
+	if result.ComponentsComputed != 2 || len(result.Scores[0]) != 2 || len(result.Loadings[0]) != 2 ||
+		len(result.ExplainedVarRatio) != 2 {
+		t.Fatalf("expected 2 components, got %d", result.ComponentsComputed)
+	}
+	for k, ratio := range result.ExplainedVarRatio {
+		if math.IsNaN(ratio) || ratio <= 0 {
+			t.Errorf("PC%d: explained variance ratio %g", k+1, ratio)
+		}
+	}
+	if total := result.CumulativeVar[1]; math.Abs(total-100) > 1e-8 {
+		t.Errorf("expected 2 components to explain 100%% of the variance, got %g", total)
+	}
+	for _, v := range result.AllEigenvalues {
+		if v < 0 {
+			t.Errorf("negative eigenvalue %g", v)
+		}
+	}
+	output := strings.Join(result.Warnings, "\n")
+	if want := "numerical rank 2, fewer than the 4 components requested"; !strings.Contains(output, want) ||
+		!strings.Contains(output, "PC3, PC4") {
+		t.Errorf("expected a warning about rank 2 discarding PC3, PC4, got %q", output)
+	}
+}
+
+func TestFitAdditionalComponents(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	n, m := 40, 6
+	data := make(types.Matrix, n)
+	for i := range data {
+		data[i] = make([]float64, m)
+		for j := range data[i] {
+			data[i][j] = rng.NormFloat64() * float64(m-j)
+		}
+	}
+	config := types.PCAConfig{Components: 3, MeanCenter: true, Method: "nipals", KeepFitData: true}
+
+	warmEngine := &PCAImpl{}
+	prev, err := warmEngine.Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	warm, err := warmEngine.FitAdditionalComponents(prev, 1)
+	if err != nil {
+		t.Fatalf("FitAdditionalComponents failed: %v", err)
+	}
+	warmIterations := warmEngine.Iterations()
+
+	config.Components = 4
+	coldEngine := &PCAImpl{}
+	cold, err := coldEngine.Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	coldIterations := coldEngine.Iterations()
+
+	if warm.ComponentsComputed != 4 {
+		t.Fatalf("expected 4 components, got %d", warm.ComponentsComputed)
+	}
+	for i := range cold.Scores {
+		for k := range cold.Scores[i] {
+			if math.Abs(warm.Scores[i][k]-cold.Scores[i][k]) > 1e-6 {
+				t.Errorf("scores[%d][%d] = %g, cold fit %g", i, k, warm.Scores[i][k], cold.Scores[i][k])
+			}
+		}
+	}
+	for k := range cold.ExplainedVarRatio {
+		if math.Abs(warm.ExplainedVarRatio[k]-cold.ExplainedVarRatio[k]) > 1e-6 {
+			t.Errorf("explained variance of PC%d = %g, cold fit %g", k+1, warm.ExplainedVarRatio[k], cold.ExplainedVarRatio[k])
+		}
+	}
+
+	// Only the new component is iterated
+	t.Logf("power iterations: warm start %d, cold fit %d", warmIterations, coldIterations)
+	if warmIterations == 0 || warmIterations >= coldIterations {
+		t.Errorf("expected the warm start to need fewer iterations than the cold fit, got %d and %d",
+			warmIterations, coldIterations)
+	}
+
+	// Transform uses the extended model
+	projected, err := warmEngine.Transform(data[:1])
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+	if len(projected[0]) != 4 {
+		t.Errorf("expected 4 projected components, got %d", len(projected[0]))
+	}
+
+	if _, err := warmEngine.FitAdditionalComponents(warm, 0); err == nil {
+		t.Error("expected an error for zero additional components")
+	}
+	svdEngine := &PCAImpl{}
+	svdResult, err := svdEngine.Fit(data, types.PCAConfig{Components: 2, MeanCenter: true, Method: "svd"})
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	if _, err := svdEngine.FitAdditionalComponents(svdResult, 1); err == nil {
+		t.Error("expected an error when extending an SVD model")
+	}
+
+	// The fit data is only kept on request
+	config.KeepFitData = false
+	plainEngine := &PCAImpl{}
+	plainResult, err := plainEngine.Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	if plainEngine.fitData != nil {
+		t.Error("expected the fit data to be released without KeepFitData")
+	}
+	if _, err := plainEngine.FitAdditionalComponents(plainResult, 1); err == nil {
+		t.Error("expected an error when extending a model fitted without KeepFitData")
+	}
+	if _, ok := NewPCAEngine().(types.ComponentExtender); !ok {
+		t.Error("expected the PCA engine to be a ComponentExtender")
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/stat"
 )
 
@@ -20,6 +22,103 @@ const (
 	MinVarianceThreshold = 1e-8
 )
 
+// VectorNormType selects the norm vector normalization divides each row by
+type VectorNormType string
+
+const (
+	// VectorNormL2 divides each row by its Euclidean length (the default)
+	VectorNormL2 VectorNormType = "l2"
+	// VectorNormL1 divides each row by the sum of its absolute values
+	VectorNormL1 VectorNormType = "l1"
+	// VectorNormMax divides each row by its largest absolute value
+	VectorNormMax VectorNormType = "max"
+)
+
+// ParseVectorNormType parses a vector norm type name; the empty string selects L2
+func ParseVectorNormType(s string) (VectorNormType, error) {
+	switch VectorNormType(s) {
+	case "", VectorNormL2:
+		return VectorNormL2, nil
+	case VectorNormL1, VectorNormMax:
+		return VectorNormType(s), nil
+	}
+	return "", fmt.Errorf("invalid vector norm type %q: must be l1, l2 or max", s)
+}
+
+// RobustCenter selects the center statistic robust scaling subtracts
+type RobustCenter string
+
+const (
+	// RobustCenterMedian subtracts the column median (the default)
+	RobustCenterMedian RobustCenter = "median"
+	// RobustCenterTrimmedMean subtracts the mean of the column after discarding the
+	// lowest and highest robustTrimFraction of its values
+	RobustCenterTrimmedMean RobustCenter = "trimmed-mean"
+)
+
+// RobustScaleStat selects the scale statistic robust scaling divides by
+type RobustScaleStat string
+
+const (
+	// RobustScaleMAD divides by the median absolute deviation times 1.4826 (the default)
+	RobustScaleMAD RobustScaleStat = "mad"
+	// RobustScaleIQR divides by the interquartile range divided by 1.349
+	RobustScaleIQR RobustScaleStat = "iqr"
+)
+
+const (
+	// robustTrimFraction is the fraction of values discarded at each end of a column
+	// by the trimmed mean
+	robustTrimFraction = 0.1
+	// iqrNormalFactor is the interquartile range of the standard normal distribution,
+	// so IQR/1.349 estimates the standard deviation of normal data
+	iqrNormalFactor = 1.349
+)
+
+// ParseRobustScaleParams parses robust scaling parameters of the form
+// "<center>,<scale>", such as "median,mad" or "trimmed-mean,iqr". The empty string
+// selects median and MAD.
+func ParseRobustScaleParams(s string) (RobustCenter, RobustScaleStat, error) {
+	if s == "" {
+		return RobustCenterMedian, RobustScaleMAD, nil
+	}
+	centerName, scaleName, ok := strings.Cut(s, ",")
+	if !ok {
+		return "", "", fmt.Errorf("invalid robust scale parameters %q: must be <center>,<scale>, e.g. median,mad", s)
+	}
+	center, err := ParseRobustCenter(strings.TrimSpace(centerName))
+	if err != nil {
+		return "", "", err
+	}
+	scale, err := ParseRobustScaleStat(strings.TrimSpace(scaleName))
+	if err != nil {
+		return "", "", err
+	}
+	return center, scale, nil
+}
+
+// ParseRobustCenter parses a robust center statistic name; the empty string selects the median
+func ParseRobustCenter(s string) (RobustCenter, error) {
+	switch RobustCenter(s) {
+	case "", RobustCenterMedian:
+		return RobustCenterMedian, nil
+	case RobustCenterTrimmedMean:
+		return RobustCenterTrimmedMean, nil
+	}
+	return "", fmt.Errorf("invalid robust center %q: must be median or trimmed-mean", s)
+}
+
+// ParseRobustScaleStat parses a robust scale statistic name; the empty string selects MAD
+func ParseRobustScaleStat(s string) (RobustScaleStat, error) {
+	switch RobustScaleStat(s) {
+	case "", RobustScaleMAD:
+		return RobustScaleMAD, nil
+	case RobustScaleIQR:
+		return RobustScaleIQR, nil
+	}
+	return "", fmt.Errorf("invalid robust scale statistic %q: must be mad or iqr", s)
+}
+
 // Preprocessor handles data preprocessing for PCA
 type Preprocessor struct {
 	// Preprocessing parameters
@@ -29,6 +128,11 @@ type Preprocessor struct {
 	ScaleOnly     bool
 	SNV           bool
 	VectorNorm    bool
+	// VectorNormType is the norm used by VectorNorm; empty means L2
+	VectorNormType VectorNormType
+	// RobustCenter and RobustScaleStat define RobustScale; empty means median and MAD
+	RobustCenter    RobustCenter
+	RobustScaleStat RobustScaleStat
 
 	// Fitted parameters
 	mean        []float64
@@ -42,6 +146,9 @@ type Preprocessor struct {
 	// Kept for potential future use in specialized inverse transforms
 	rowMeans   []float64
 	rowStdDevs []float64
+
+	// Warnings of the last transform
+	warnings []string
 }
 
 // NewPreprocessor creates a new preprocessor instance
@@ -110,21 +217,20 @@ func (p *Preprocessor) applyRowWisePreprocessing(row []float64, storeStats bool,
 			}
 		}
 	} else if p.VectorNorm {
-		// Apply L2 Vector Normalization: x / ||x||
-		norm := 0.0
-		for _, val := range result {
-			norm += val * val
-		}
-		norm = math.Sqrt(norm)
+		// Apply Vector Normalization: x / ||x||
+		norm := p.rowNorm(result)
 
 		// Store norm for potential inverse transform (though currently not used)
 		if storeStats && p.rowStdDevs != nil && rowIndex >= 0 && rowIndex < len(p.rowStdDevs) {
 			p.rowStdDevs[rowIndex] = norm
 		}
 
-		if norm > MinVarianceThreshold {
-			for j := range result {
+		// A row without magnitude has no direction, so it is left as zeros rather than NaN
+		for j := range result {
+			if norm > MinVarianceThreshold {
 				result[j] /= norm
+			} else {
+				result[j] = 0
 			}
 		}
 	}
@@ -132,6 +238,18 @@ func (p *Preprocessor) applyRowWisePreprocessing(row []float64, storeStats bool,
 	return result
 }
 
+// rowNorm returns the norm of a row selected by VectorNormType
+func (p *Preprocessor) rowNorm(row []float64) float64 {
+	switch p.VectorNormType {
+	case VectorNormL1:
+		return floats.Norm(row, 1)
+	case VectorNormMax:
+		return floats.Norm(row, math.Inf(1))
+	default:
+		return floats.Norm(row, 2)
+	}
+}
+
 // FitTransform fits the preprocessor and transforms the data
 func (p *Preprocessor) FitTransform(data types.Matrix) (types.Matrix, error) {
 	// If row-wise preprocessing is enabled, we need to fit column statistics on row-normalized data
@@ -165,6 +283,12 @@ func (p *Preprocessor) FitTransform(data types.Matrix) (types.Matrix, error) {
 	return p.Transform(data)
 }
 
+// Warnings returns the warnings of the last Transform or FitTransform, such as rows
+// that could not be normalized
+func (p *Preprocessor) Warnings() []string {
+	return p.warnings
+}
+
 // Fit calculates preprocessing parameters from the data
 func (p *Preprocessor) Fit(data types.Matrix) error {
 	if len(data) == 0 || len(data[0]) == 0 {
@@ -210,8 +334,16 @@ func (p *Preprocessor) Fit(data types.Matrix) error {
 			copy(sortedCol, col)
 			sort.Float64s(sortedCol)
 
-			p.median[j] = stat.Quantile(0.5, stat.Empirical, sortedCol, nil)
-			p.mad[j] = medianAbsoluteDeviation(col, p.median[j])
+			median := stat.Quantile(0.5, stat.Empirical, sortedCol, nil)
+			p.median[j] = median
+			if p.RobustCenter == RobustCenterTrimmedMean {
+				p.median[j] = trimmedMean(sortedCol, robustTrimFraction)
+			}
+			if p.RobustScaleStat == RobustScaleIQR {
+				p.mad[j] = interquartileRange(sortedCol) / iqrNormalFactor
+			} else {
+				p.mad[j] = medianAbsoluteDeviation(col, median)
+			}
 			if p.mad[j] < MinVarianceThreshold {
 				p.mad[j] = 1.0 // Avoid division by zero
 			}
@@ -227,6 +359,7 @@ func (p *Preprocessor) Transform(data types.Matrix) (types.Matrix, error) {
 	if !p.fitted {
 		return nil, fmt.Errorf("preprocessor not fitted: call Fit first")
 	}
+	p.warnings = nil
 
 	if len(data) == 0 || len(data[0]) == 0 {
 		return nil, fmt.Errorf("empty data matrix")
@@ -248,9 +381,17 @@ func (p *Preprocessor) Transform(data types.Matrix) (types.Matrix, error) {
 	if p.SNV || p.VectorNorm {
 		// For transformation of new data, we calculate fresh row statistics
 		// This is critical: we do NOT use stored row statistics from training
+		zeroNormRows := 0
 		for i := 0; i < n; i++ {
+			if !p.SNV && p.rowNorm(result[i]) <= MinVarianceThreshold {
+				zeroNormRows++
+			}
 			result[i] = p.applyRowWisePreprocessing(result[i], false, -1)
 		}
+		if zeroNormRows > 0 {
+			p.warnings = append(p.warnings, fmt.Sprintf(
+				"%d row(s) have zero norm and were left as zeros by vector normalization", zeroNormRows))
+		}
 	}
 
 	// Then apply column-wise preprocessing
@@ -260,7 +401,7 @@ func (p *Preprocessor) Transform(data types.Matrix) (types.Matrix, error) {
 
 			// Apply centering and scaling
 			if p.RobustScale {
-				// Robust scaling: (x - median) / MAD
+				// Robust scaling: (x - median) / MAD, or the chosen center and scale statistics
 				val = (val - p.median[j]) / p.mad[j]
 			} else if p.ScaleOnly {
 				// Scale-only (variance scaling): divide by std dev without mean centering
@@ -282,15 +423,24 @@ func (p *Preprocessor) Transform(data types.Matrix) (types.Matrix, error) {
 	return result, nil
 }
 
-// InverseTransform reverses the preprocessing
-// Note: When SNV is combined with column-wise preprocessing, the inverse transform
-// only reverses the column-wise operations. Full reversal of SNV after column
-// preprocessing would require storing the full transformed matrix.
+// InverseTransform reverses the column-wise preprocessing, mapping data such as
+// reconstructions back to original units. Steps are undone in reverse order of
+// Transform: scaling first, then centering. Row-wise SNV and vector normalization
+// discard each row's location and scale and cannot be reversed, so an error is
+// returned if either was applied.
 func (p *Preprocessor) InverseTransform(data types.Matrix) (types.Matrix, error) {
 	if !p.fitted {
 		return nil, fmt.Errorf("preprocessor not fitted")
 	}
 
+	if p.SNV || p.VectorNorm {
+		return nil, fmt.Errorf("inverse transform is not possible after row-wise preprocessing (SNV or vector normalization)")
+	}
+
+	if len(data) == 0 || len(data[0]) == 0 {
+		return nil, fmt.Errorf("empty data matrix")
+	}
+
 	n, m := len(data), len(data[0])
 	if m != len(p.mean) {
 		return nil, fmt.Errorf("data has %d features, expected %d", m, len(p.mean))
@@ -303,7 +453,7 @@ func (p *Preprocessor) InverseTransform(data types.Matrix) (types.Matrix, error)
 		for j := 0; j < m; j++ {
 			val := data[i][j]
 
-			// Reverse scaling and centering (column-wise operations only)
+			// Reverse scaling and centering
 			if p.RobustScale {
 				// Reverse robust scaling
 				val = val*p.mad[j] + p.median[j]
@@ -324,9 +474,6 @@ func (p *Preprocessor) InverseTransform(data types.Matrix) (types.Matrix, error)
 		}
 	}
 
-	// Note: SNV reversal is not performed when combined with column preprocessing
-	// as it would require the intermediate state after SNV but before column operations
-
 	return result, nil
 }
 
@@ -352,6 +499,19 @@ func medianAbsoluteDeviation(data []float64, median float64) float64 {
 	return stat.Quantile(0.5, stat.Empirical, deviations, nil) * 1.4826 // Scale factor for consistency with std dev
 }
 
+// trimmedMean returns the mean of sorted data after discarding the fraction trim of
+// the values at each end, rounded down to whole values
+func trimmedMean(sorted []float64, trim float64) float64 {
+	cut := int(trim * float64(len(sorted)))
+	return stat.Mean(sorted[cut:len(sorted)-cut], nil)
+}
+
+// interquartileRange returns the difference between the empirical third and first
+// quartiles of sorted data
+func interquartileRange(sorted []float64) float64 {
+	return stat.Quantile(0.75, stat.Empirical, sorted, nil) - stat.Quantile(0.25, stat.Empirical, sorted, nil)
+}
+
 // HandleMissingValues provides strategies for dealing with missing data
 type MissingValueStrategy string
 
@@ -657,6 +817,54 @@ func GetVarianceByColumn(data types.Matrix) ([]float64, error) {
 	return variances, nil
 }
 
+// DefaultScaleRatioThreshold is the variance ratio above which unscaled PCA is
+// likely to be dominated by the highest-variance variables
+const DefaultScaleRatioThreshold = 1000.0
+
+// ScaleDiagnostics reports the range and variance of each column together with the
+// ratio between the largest and smallest non-zero column variance. NaN values are
+// ignored. A large ratio means unscaled PCA will be dominated by a few variables.
+func ScaleDiagnostics(X types.Matrix) (ranges, variances []float64, ratio float64) {
+	if len(X) == 0 || len(X[0]) == 0 {
+		return nil, nil, 1
+	}
+
+	m := len(X[0])
+	ranges = make([]float64, m)
+	variances = make([]float64, m)
+
+	minVar, maxVar := math.Inf(1), 0.0
+	for j := 0; j < m; j++ {
+		col := make([]float64, 0, len(X))
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for i := range X {
+			v := X[i][j]
+			if math.IsNaN(v) {
+				continue
+			}
+			col = append(col, v)
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+		if len(col) < 2 {
+			continue
+		}
+		ranges[j] = hi - lo
+		variances[j] = stat.Variance(col, nil)
+
+		if variances[j] > MinVarianceThreshold {
+			minVar = math.Min(minVar, variances[j])
+			maxVar = math.Max(maxVar, variances[j])
+		}
+	}
+
+	ratio = 1
+	if maxVar > 0 && !math.IsInf(minVar, 1) {
+		ratio = maxVar / minVar
+	}
+	return ranges, variances, ratio
+}
+
 // GetColumnRanks returns column indices sorted by variance (descending)
 func GetColumnRanks(data types.Matrix) ([]int, error) {
 	variances, err := GetVarianceByColumn(data)
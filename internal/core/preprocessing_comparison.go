@@ -0,0 +1,146 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// PreprocessingCandidate is a named preprocessing configuration compared by
+// ComparePreprocessing
+type PreprocessingCandidate struct {
+	Name   string
+	Config types.PCAConfig
+}
+
+// PreprocessingCandidates returns the preprocessing configurations that
+// ComparePreprocessing sweeps, from no preprocessing to SNV with standard scaling
+func PreprocessingCandidates() []PreprocessingCandidate {
+	return []PreprocessingCandidate{
+		{Name: "none", Config: types.PCAConfig{}},
+		{Name: "center", Config: types.PCAConfig{MeanCenter: true}},
+		{Name: "standard", Config: types.PCAConfig{MeanCenter: true, StandardScale: true}},
+		{Name: "robust", Config: types.PCAConfig{MeanCenter: true, RobustScale: true}},
+		{Name: "snv+standard", Config: types.PCAConfig{SNV: true, MeanCenter: true, StandardScale: true}},
+	}
+}
+
+// PreprocessingComparison is the outcome of PCA under one preprocessing configuration
+type PreprocessingComparison struct {
+	Preprocessing string `json:"preprocessing"`
+	// ExplainedVarianceRatio is the percent of variance explained by PC1 and PC2
+	ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"`
+	CumulativeVariance     float64   `json:"cumulative_variance"`
+	// GroupSeparation is the ratio of between-group to within-group scatter of the
+	// PC1 and PC2 scores, or nil if no groups were given
+	GroupSeparation *float64 `json:"group_separation,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// ComparePreprocessing fits a two-component PCA to data under each of
+// PreprocessingCandidates, with the given method, and reports the variance explained
+// by PC1 and PC2. If groups has one label per row, the separation of the groups in
+// the PC1–PC2 score plot is reported as well. A configuration that fails records its
+// error instead of stopping the sweep.
+func ComparePreprocessing(data types.Matrix, method string, groups []string) ([]PreprocessingComparison, error) {
+	if len(data) < 3 || len(data[0]) < 2 {
+		return nil, fmt.Errorf("need at least 3 rows and 2 columns")
+	}
+	if groups != nil && len(groups) != len(data) {
+		return nil, fmt.Errorf("group labels (%d) do not match data rows (%d)", len(groups), len(data))
+	}
+
+	candidates := PreprocessingCandidates()
+	comparisons := make([]PreprocessingComparison, len(candidates))
+	for i, candidate := range candidates {
+		comparisons[i].Preprocessing = candidate.Name
+
+		config := candidate.Config
+		config.Components = 2
+		config.Method = method
+		// Fit preprocesses its own copy of the data
+		result, err := NewPCAEngineForMethod(method).Fit(data, config)
+		if err != nil {
+			comparisons[i].Error = err.Error()
+			continue
+		}
+
+		comparisons[i].ExplainedVarianceRatio = result.ExplainedVarRatio
+		if n := len(result.CumulativeVar); n > 0 {
+			comparisons[i].CumulativeVariance = result.CumulativeVar[n-1]
+		}
+		if groups != nil {
+			separation, err := GroupSeparation(result.Scores, groups)
+			if err != nil {
+				comparisons[i].Error = err.Error()
+				continue
+			}
+			comparisons[i].GroupSeparation = &separation
+		}
+	}
+	return comparisons, nil
+}
+
+// GroupSeparation measures how well groups separate in score space as the ratio of
+// between-group scatter (group sizes times squared distances of the group means from
+// the overall mean) to within-group scatter (squared distances of the scores from
+// their group mean). Higher values mean tighter, further apart groups. Rows with an
+// empty group are ignored, and at least two groups are required.
+func GroupSeparation(scores types.Matrix, groups []string) (float64, error) {
+	categories, counts, means, err := GroupScoreMeans(scores, groups)
+	if err != nil {
+		return 0, err
+	}
+	if len(categories) < 2 {
+		return 0, fmt.Errorf("group separation needs at least 2 groups, found %d", len(categories))
+	}
+	index := make(map[string]int, len(categories))
+	for k, category := range categories {
+		index[category] = k
+	}
+
+	nComponents := len(scores[0])
+	overall := make([]float64, nComponents)
+	total := 0
+	for k, mean := range means {
+		for c, v := range mean {
+			overall[c] += float64(counts[k]) * v
+		}
+		total += counts[k]
+	}
+	for c := range overall {
+		overall[c] /= float64(total)
+	}
+
+	between := 0.0
+	for k, mean := range means {
+		for c, v := range mean {
+			between += float64(counts[k]) * (v - overall[c]) * (v - overall[c])
+		}
+	}
+	within := 0.0
+	for i, group := range groups {
+		if group == "" {
+			continue
+		}
+		mean := means[index[group]]
+		for c, v := range scores[i] {
+			within += (v - mean[c]) * (v - mean[c])
+		}
+	}
+
+	if math.IsNaN(between) || math.IsNaN(within) {
+		return 0, fmt.Errorf("scores contain missing values")
+	}
+	if within == 0 {
+		return 0, fmt.Errorf("group separation is undefined when every group's scores coincide")
+	}
+	return between / within, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestComparePreprocessing(t *testing.T) {
+	data := types.Matrix{
+		{2.5, 240, 1.2, 0.5},
+		{0.5, 70, 0.3, 0.9},
+		{2.2, 290, 1.1, 0.4},
+		{1.9, 220, 0.8, 0.7},
+		{3.1, 300, 1.6, 0.2},
+		{2.3, 270, 0.9, 0.6},
+		{2.0, 160, 1.3, 0.8},
+		{1.0, 110, 0.2, 1.0},
+	}
+	groups := []string{"a", "b", "a", "b", "a", "a", "b", "b"}
+
+	comparisons, err := ComparePreprocessing(data, "svd", groups)
+	if err != nil {
+		t.Fatalf("ComparePreprocessing failed: %v", err)
+	}
+
+	candidates := PreprocessingCandidates()
+	if len(comparisons) != len(candidates) {
+		t.Fatalf("expected %d comparisons, got %d", len(candidates), len(comparisons))
+	}
+	for i, c := range comparisons {
+		if c.Preprocessing != candidates[i].Name {
+			t.Errorf("comparison %d: expected %s, got %s", i, candidates[i].Name, c.Preprocessing)
+		}
+		if c.Error != "" {
+			t.Errorf("%s: unexpected error %s", c.Preprocessing, c.Error)
+			continue
+		}
+		if len(c.ExplainedVarianceRatio) != 2 {
+			t.Fatalf("%s: expected 2 components, got %d", c.Preprocessing, len(c.ExplainedVarianceRatio))
+		}
+		pc1, pc2 := c.ExplainedVarianceRatio[0], c.ExplainedVarianceRatio[1]
+		if pc1 < pc2 || pc2 < 0 || c.CumulativeVariance > 100+1e-9 {
+			t.Errorf("%s: implausible explained variance PC1 %g, PC2 %g, cumulative %g",
+				c.Preprocessing, pc1, pc2, c.CumulativeVariance)
+		}
+		if c.GroupSeparation == nil || *c.GroupSeparation <= 0 {
+			t.Errorf("%s: expected a positive group separation", c.Preprocessing)
+		}
+	}
+
+	// Standardizing removes the dominance of the large second column
+	if comparisons[1].ExplainedVarianceRatio[0] <= comparisons[2].ExplainedVarianceRatio[0] {
+		t.Errorf("expected centered PC1 (%g%%) to explain more than standardized PC1 (%g%%)",
+			comparisons[1].ExplainedVarianceRatio[0], comparisons[2].ExplainedVarianceRatio[0])
+	}
+}
+
+func TestGroupSeparation(t *testing.T) {
+	tight := types.Matrix{{0, 0}, {0.1, 0}, {5, 5}, {5.1, 5}}
+	loose := types.Matrix{{0, 0}, {3, 0}, {5, 5}, {8, 5}}
+	groups := []string{"a", "a", "b", "b"}
+
+	tightSeparation, err := GroupSeparation(tight, groups)
+	if err != nil {
+		t.Fatalf("GroupSeparation failed: %v", err)
+	}
+	looseSeparation, err := GroupSeparation(loose, groups)
+	if err != nil {
+		t.Fatalf("GroupSeparation failed: %v", err)
+	}
+	if tightSeparation <= looseSeparation {
+		t.Errorf("expected tight groups to separate better: %g <= %g", tightSeparation, looseSeparation)
+	}
+
+	if _, err := GroupSeparation(tight, []string{"a", "a", "a", ""}); err == nil {
+		t.Error("expected an error for a single group")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/floats"
 )
 
 // Test basic preprocessing
@@ -219,6 +220,57 @@ func TestInverseTransform(t *testing.T) {
 	}
 }
 
+// Test inverse transform for robust scaling and non-invertible row-wise preprocessing
+func TestInverseTransformRobustAndRowWise(t *testing.T) {
+	data := types.Matrix{
+		{1.0, 20.0, 0.5},
+		{3.0, 40.0, 0.1},
+		{5.0, 10.0, 0.9},
+		{2.0, 30.0, 0.3},
+		{8.0, 50.0, 0.7},
+	}
+
+	prep := NewPreprocessor(false, false, true)
+	transformed, err := prep.FitTransform(data)
+	if err != nil {
+		t.Fatalf("FitTransform failed: %v", err)
+	}
+	inversed, err := prep.InverseTransform(transformed)
+	if err != nil {
+		t.Fatalf("InverseTransform failed: %v", err)
+	}
+	for i := range data {
+		for j := range data[i] {
+			if math.Abs(inversed[i][j]-data[i][j]) > 1e-10 {
+				t.Errorf("Robust inverse transform failed at [%d,%d]: expected %f, got %f",
+					i, j, data[i][j], inversed[i][j])
+			}
+		}
+	}
+
+	// Row-wise preprocessing cannot be reversed
+	for _, prep := range []*Preprocessor{
+		NewPreprocessorFull(true, false, false, true, false),
+		NewPreprocessorFull(true, false, false, false, true),
+	} {
+		transformed, err := prep.FitTransform(data)
+		if err != nil {
+			t.Fatalf("FitTransform failed: %v", err)
+		}
+		if _, err := prep.InverseTransform(transformed); err == nil {
+			t.Errorf("Expected error for inverse transform after SNV=%v VectorNorm=%v", prep.SNV, prep.VectorNorm)
+		}
+	}
+
+	// Empty data is rejected
+	if _, err := NewPreprocessor(true, false, false).InverseTransform(types.Matrix{}); err == nil {
+		t.Error("Expected error for unfitted preprocessor")
+	}
+	if _, err := prep.InverseTransform(types.Matrix{}); err == nil {
+		t.Error("Expected error for empty data")
+	}
+}
+
 // Test Vector Normalization
 func TestVectorNormalization(t *testing.T) {
 	// Test data
@@ -255,6 +307,52 @@ func TestVectorNormalization(t *testing.T) {
 	}
 }
 
+func TestVectorNormTypes(t *testing.T) {
+	data := types.Matrix{
+		{3.0, -4.0, 1.0},
+		{-2.0, 0.5, 0.5},
+		{0.0, 0.0, 0.0}, // Zero norm: left as zeros
+	}
+
+	tests := []struct {
+		normType VectorNormType
+		measure  func(row []float64) float64
+	}{
+		{VectorNormL1, func(row []float64) float64 { return floats.Norm(row, 1) }},
+		{VectorNormL2, func(row []float64) float64 { return floats.Norm(row, 2) }},
+		{VectorNormMax, func(row []float64) float64 { return floats.Norm(row, math.Inf(1)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.normType), func(t *testing.T) {
+			prep := NewPreprocessorFull(false, false, false, false, true)
+			prep.VectorNormType = tt.normType
+			transformed, err := prep.FitTransform(data)
+			if err != nil {
+				t.Fatalf("FitTransform failed: %v", err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if got := tt.measure(transformed[i]); math.Abs(got-1) > 1e-12 {
+					t.Errorf("row %d has %s norm %g, expected 1", i, tt.normType, got)
+				}
+			}
+			for j, v := range transformed[2] {
+				if v != 0 {
+					t.Errorf("zero row column %d = %g, expected 0", j, v)
+				}
+			}
+		})
+	}
+
+	if _, err := ParseVectorNormType("l3"); err == nil {
+		t.Error("expected an error for vector norm type l3")
+	}
+	if normType, err := ParseVectorNormType(""); err != nil || normType != VectorNormL2 {
+		t.Errorf("ParseVectorNormType(\"\") = %q, %v; expected l2", normType, err)
+	}
+}
+
 // Test SNV against reference implementation
 func TestSNVReferenceImplementation(t *testing.T) {
 	// Test with known input/output from Python reference
@@ -463,6 +561,40 @@ func TestGetVarianceByColumn(t *testing.T) {
 	}
 }
 
+// Test scale mismatch diagnostics
+func TestScaleDiagnostics(t *testing.T) {
+	data := types.Matrix{
+		{1.0, 1000.0, 7.0},
+		{2.0, 2000.0, 7.0},
+		{3.0, 3000.0, 7.0},
+		{4.0, math.NaN(), 7.0},
+	}
+
+	ranges, variances, ratio := ScaleDiagnostics(data)
+
+	if ranges[0] != 3 || ranges[1] != 2000 {
+		t.Errorf("Unexpected ranges: %v", ranges)
+	}
+	if variances[2] != 0 {
+		t.Errorf("Constant column should have zero variance, got %f", variances[2])
+	}
+
+	// Column 2 is 1000x larger than column 1 (variance ratio 1e6 after accounting
+	// for the missing value); constant columns are ignored in the ratio
+	expected := variances[1] / variances[0]
+	if math.Abs(ratio-expected) > 1e-6*expected {
+		t.Errorf("Expected ratio %f, got %f", expected, ratio)
+	}
+	if ratio < DefaultScaleRatioThreshold {
+		t.Errorf("Expected ratio above default threshold, got %f", ratio)
+	}
+
+	_, _, ratio = ScaleDiagnostics(types.Matrix{{1, 2}, {2, 4}, {3, 6}})
+	if ratio != 4 {
+		t.Errorf("Expected ratio 4, got %f", ratio)
+	}
+}
+
 // Test column ranking by variance
 func TestGetColumnRanks(t *testing.T) {
 	data := types.Matrix{
@@ -482,3 +614,59 @@ func TestGetColumnRanks(t *testing.T) {
 		t.Errorf("Expected column 1 to rank first, got column %d", ranks[0])
 	}
 }
+
+// Test the center and scale statistics of robust scaling on a column with an outlier
+func TestRobustScaleParams(t *testing.T) {
+	data := make(types.Matrix, 10)
+	for i := range data {
+		data[i] = []float64{float64(i + 1)}
+	}
+	data[9][0] = 100 // 1, 2, ..., 9, 100
+
+	tests := []struct {
+		center  RobustCenter
+		scale   RobustScaleStat
+		wantMid float64
+		wantDiv float64
+	}{
+		// Median 5; |x-5| has median 2, so MAD = 2 × 1.4826
+		{RobustCenterMedian, RobustScaleMAD, 5, 2 * 1.4826},
+		// Quartiles 3 and 8, so IQR/1.349 = 5/1.349
+		{RobustCenterMedian, RobustScaleIQR, 5, 5 / 1.349},
+		// 10% trimmed mean drops 1 and 100: mean of 2..9 = 5.5
+		{RobustCenterTrimmedMean, RobustScaleMAD, 5.5, 2 * 1.4826},
+		{RobustCenterTrimmedMean, RobustScaleIQR, 5.5, 5 / 1.349},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.center)+","+string(tt.scale), func(t *testing.T) {
+			prep := NewPreprocessor(true, false, true)
+			prep.RobustCenter = tt.center
+			prep.RobustScaleStat = tt.scale
+			transformed, err := prep.FitTransform(data)
+			if err != nil {
+				t.Fatalf("FitTransform failed: %v", err)
+			}
+
+			if got := prep.GetMedians()[0]; math.Abs(got-tt.wantMid) > 1e-12 {
+				t.Errorf("Expected center %g, got %g", tt.wantMid, got)
+			}
+			if got := prep.GetMADs()[0]; math.Abs(got-tt.wantDiv) > 1e-12 {
+				t.Errorf("Expected divisor %g, got %g", tt.wantDiv, got)
+			}
+			if want := (1 - tt.wantMid) / tt.wantDiv; math.Abs(transformed[0][0]-want) > 1e-12 {
+				t.Errorf("Expected scaled value %g, got %g", want, transformed[0][0])
+			}
+		})
+	}
+
+	for _, s := range []string{"median", "mean,mad", "median,std", "median,mad,iqr"} {
+		if _, _, err := ParseRobustScaleParams(s); err == nil {
+			t.Errorf("Expected an error for robust scale parameters %q", s)
+		}
+	}
+	center, scale, err := ParseRobustScaleParams("trimmed-mean, iqr")
+	if err != nil || center != RobustCenterTrimmedMean || scale != RobustScaleIQR {
+		t.Errorf("Expected trimmed-mean and iqr, got %q, %q, %v", center, scale, err)
+	}
+}
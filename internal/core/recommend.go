@@ -0,0 +1,217 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Thresholds used by RecommendPreprocessing
+const (
+	// RecommendScaleRatio is the column variance ratio above which standardization is recommended
+	RecommendScaleRatio = 100.0
+	// andersonDarlingCritical is the critical value of the adjusted A² statistic at the
+	// 1% level when mean and variance are estimated from the data
+	andersonDarlingCritical = 1.035
+	// outlierModifiedZ is the modified z-score above which a value is an outlier
+	// (Iglewicz and Hoaglin)
+	outlierModifiedZ = 3.5
+	// spectraMinColumns is the minimum number of columns for spectra-like data
+	spectraMinColumns = 10
+	// spectraAdjacentCorrelation is the mean correlation between neighbouring columns
+	// above which columns are treated as a continuous signal
+	spectraAdjacentCorrelation = 0.9
+	// spectraOffsetShare is the share of the average column variance explained by
+	// row offsets above which baseline shifts are considered dominant
+	spectraOffsetShare = 0.5
+	// minRecommendSamples is the minimum number of values for the distribution checks
+	minRecommendSamples = 8
+)
+
+// RecommendPreprocessing inspects X and returns preprocessing suggestions, each as
+// "method: rationale", in the order the steps would be applied. Spectra-like data
+// (many smoothly varying columns with row-wise baseline shifts) gets SNV only. Columns
+// with outliers that also fail the Anderson-Darling normality test get robust
+// scaling; otherwise columns whose variances differ by more than RecommendScaleRatio
+// get standard scaling. NaN values are ignored. An empty result means mean
+// centering alone is adequate.
+func RecommendPreprocessing(X types.Matrix) []string {
+	if len(X) < 2 || len(X[0]) == 0 {
+		return nil
+	}
+
+	var recommendations []string
+
+	if share, corr, ok := baselineShift(X); ok {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"SNV: columns behave like a spectrum (mean adjacent correlation %.2f) and row offsets "+
+				"account for %.0f%% of the average column variance; SNV removes baseline shifts between rows",
+			corr, share*100))
+		// Column scaling would inflate noise in flat spectral regions, so it is not suggested
+		return recommendations
+	}
+
+	_, _, ratio := ScaleDiagnostics(X)
+	outlierCols := heavyOutlierColumns(X)
+	switch {
+	case len(outlierCols) > 0:
+		rationale := fmt.Sprintf("%d of %d variables have outliers (modified z-score > %g) and fail the "+
+			"Anderson-Darling normality test", len(outlierCols), len(X[0]), outlierModifiedZ)
+		if ratio > RecommendScaleRatio {
+			rationale += fmt.Sprintf(", and column variances differ by a factor of %.0f", ratio)
+		}
+		recommendations = append(recommendations,
+			"robust scaling: "+rationale+"; median/MAD scaling limits their influence")
+	case ratio > RecommendScaleRatio:
+		recommendations = append(recommendations, fmt.Sprintf(
+			"standard scaling: column variances differ by a factor of %.0f; unscaled PCA would be "+
+				"dominated by the highest-variance variables", ratio))
+	}
+
+	return recommendations
+}
+
+// heavyOutlierColumns returns the indices of columns that contain outliers by
+// modified z-score and whose distribution is rejected as normal by Anderson-Darling
+func heavyOutlierColumns(X types.Matrix) []int {
+	var cols []int
+	for j := range X[0] {
+		values := columnValues(X, j)
+		if len(values) < minRecommendSamples {
+			continue
+		}
+		sort.Float64s(values)
+
+		median := stat.Quantile(0.5, stat.Empirical, values, nil)
+		deviations := make([]float64, len(values))
+		for i, v := range values {
+			deviations[i] = math.Abs(v - median)
+		}
+		sort.Float64s(deviations)
+		mad := stat.Quantile(0.5, stat.Empirical, deviations, nil)
+		if mad == 0 {
+			continue
+		}
+
+		hasOutlier := false
+		for _, v := range values {
+			if 0.6745*math.Abs(v-median)/mad > outlierModifiedZ {
+				hasOutlier = true
+				break
+			}
+		}
+		if hasOutlier && andersonDarling(values) > andersonDarlingCritical {
+			cols = append(cols, j)
+		}
+	}
+	return cols
+}
+
+// andersonDarling returns the Anderson-Darling A² statistic for sorted values against a
+// normal distribution with estimated mean and variance, adjusted for sample size
+//
+// Reference: Stephens, M.A. (1974). EDF statistics for goodness of fit and some
+// comparisons. Journal of the American Statistical Association, 69(347), 730-737.
+func andersonDarling(sorted []float64) float64 {
+	n := len(sorted)
+	mean, std := stat.MeanStdDev(sorted, nil)
+	if std == 0 {
+		return 0
+	}
+
+	norm := distuv.UnitNormal
+	sum := 0.0
+	for i, v := range sorted {
+		// Clamp to keep the logarithms finite for extreme values
+		lo := math.Max(norm.CDF((v-mean)/std), 1e-300)
+		hi := math.Max(1-norm.CDF((sorted[n-1-i]-mean)/std), 1e-300)
+		sum += float64(2*i+1) * (math.Log(lo) + math.Log(hi))
+	}
+	nf := float64(n)
+	a2 := -nf - sum/nf
+	return a2 * (1 + 0.75/nf + 2.25/(nf*nf))
+}
+
+// baselineShift reports whether X looks like spectra with row-wise baseline shifts. It
+// returns the share of the average column variance explained by row offsets and the mean
+// correlation between neighbouring columns.
+func baselineShift(X types.Matrix) (share, adjacentCorr float64, ok bool) {
+	m := len(X[0])
+	if m < spectraMinColumns || len(X) < 3 {
+		return 0, 0, false
+	}
+
+	// Neighbouring columns of a spectrum are strongly correlated
+	pairs := 0
+	for j := 0; j+1 < m; j++ {
+		x, y := pairedValues(X, j, j+1)
+		if len(x) < 3 {
+			continue
+		}
+		r := stat.Correlation(x, y, nil)
+		if math.IsNaN(r) {
+			continue
+		}
+		adjacentCorr += r
+		pairs++
+	}
+	if pairs == 0 {
+		return 0, 0, false
+	}
+	adjacentCorr /= float64(pairs)
+
+	// An additive offset per row shows up as variance in the row means
+	rowMeans := make([]float64, 0, len(X))
+	for _, row := range X {
+		sum, count := 0.0, 0
+		for _, v := range row {
+			if !math.IsNaN(v) {
+				sum += v
+				count++
+			}
+		}
+		if count > 0 {
+			rowMeans = append(rowMeans, sum/float64(count))
+		}
+	}
+	_, variances, _ := ScaleDiagnostics(X)
+	meanVar := stat.Mean(variances, nil)
+	if len(rowMeans) < 3 || meanVar <= MinVarianceThreshold {
+		return 0, adjacentCorr, false
+	}
+	share = stat.Variance(rowMeans, nil) / meanVar
+
+	return share, adjacentCorr, adjacentCorr > spectraAdjacentCorrelation && share > spectraOffsetShare
+}
+
+// columnValues returns the non-NaN values of column j
+func columnValues(X types.Matrix, j int) []float64 {
+	values := make([]float64, 0, len(X))
+	for _, row := range X {
+		if !math.IsNaN(row[j]) {
+			values = append(values, row[j])
+		}
+	}
+	return values
+}
+
+// pairedValues returns the values of columns j and k for rows where both are present
+func pairedValues(X types.Matrix, j, k int) (x, y []float64) {
+	for _, row := range X {
+		if !math.IsNaN(row[j]) && !math.IsNaN(row[k]) {
+			x = append(x, row[j])
+			y = append(y, row[k])
+		}
+	}
+	return x, y
+}
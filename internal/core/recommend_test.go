@@ -0,0 +1,106 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// normalMatrix returns n rows of independent normal columns with the given standard deviations
+func normalMatrix(rng *rand.Rand, n int, stds ...float64) types.Matrix {
+	X := make(types.Matrix, n)
+	for i := range X {
+		X[i] = make([]float64, len(stds))
+		for j, s := range stds {
+			X[i][j] = 10 + s*rng.NormFloat64()
+		}
+	}
+	return X
+}
+
+// recommendedMethods returns the method part of each recommendation
+func recommendedMethods(recommendations []string) []string {
+	methods := make([]string, len(recommendations))
+	for i, rec := range recommendations {
+		methods[i], _, _ = strings.Cut(rec, ":")
+	}
+	return methods
+}
+
+func TestRecommendPreprocessing(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	outliers := normalMatrix(rng, 100, 1, 1.5, 2)
+	outliers[3][0] = 60
+	outliers[40][0] = -45
+	outliers[77][0] = 80
+
+	// Spectra: a peak whose height varies per row, on a per-row baseline offset
+	spectra := make(types.Matrix, 30)
+	for i := range spectra {
+		height := 1 + 0.2*rng.Float64()
+		baseline := 5 * rng.Float64()
+		spectra[i] = make([]float64, 50)
+		for j := range spectra[i] {
+			peak := math.Exp(-math.Pow(float64(j-25)/8, 2))
+			spectra[i][j] = baseline + height*peak + 0.01*rng.NormFloat64()
+		}
+	}
+
+	tests := []struct {
+		name string
+		data types.Matrix
+		want []string
+	}{
+		{"comparable scales", normalMatrix(rng, 100, 1, 2, 1.5), []string{}},
+		{"variance disparity", normalMatrix(rng, 100, 1, 10, 100), []string{"standard scaling"}},
+		{"heavy outliers", outliers, []string{"robust scaling"}},
+		{"spectra with baseline shifts", spectra, []string{"SNV"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := recommendedMethods(RecommendPreprocessing(tt.data))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, RecommendPreprocessing(tt.data))
+			}
+		})
+	}
+}
+
+func TestAndersonDarling(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+
+	normal := make([]float64, 200)
+	exponential := make([]float64, 200)
+	for i := range normal {
+		normal[i] = rng.NormFloat64()
+		exponential[i] = rng.ExpFloat64()
+	}
+
+	for _, tt := range []struct {
+		name     string
+		values   []float64
+		rejected bool
+	}{
+		{"normal", normal, false},
+		{"exponential", exponential, true},
+	} {
+		sorted := append([]float64(nil), tt.values...)
+		sort.Float64s(sorted)
+		a2 := andersonDarling(sorted)
+		if (a2 > andersonDarlingCritical) != tt.rejected {
+			t.Errorf("%s: A² = %.3f, expected rejected=%v", tt.name, a2, tt.rejected)
+		}
+	}
+}
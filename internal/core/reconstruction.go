@@ -0,0 +1,52 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// Reconstruct approximates the data from the fitted components as T·Pᵀ (scores times
+// transposed loadings) and maps it back to original units with the preprocessor's
+// inverse transform. With all components the input data is recovered. Kernel PCA has
+// no loadings and row-wise preprocessing (SNV, vector normalization) cannot be
+// inverted, so both return an error. A nil preprocessor leaves the reconstruction in
+// preprocessed units.
+func Reconstruct(result *types.PCAResult, preprocessor *Preprocessor) (types.Matrix, error) {
+	if result.Method == "kernel" {
+		return nil, fmt.Errorf("kernel PCA has no loadings to reconstruct the data from")
+	}
+	if len(result.Scores) == 0 || len(result.Loadings) == 0 {
+		return nil, fmt.Errorf("scores and loadings are required")
+	}
+	nComponents := len(result.Loadings[0])
+	if len(result.Scores[0]) != nComponents {
+		return nil, fmt.Errorf("scores have %d components, loadings have %d", len(result.Scores[0]), nComponents)
+	}
+
+	// X̂ = T·Pᵀ
+	reconstruction := make(types.Matrix, len(result.Scores))
+	for i, scores := range result.Scores {
+		reconstruction[i] = make([]float64, len(result.Loadings))
+		for j, loadings := range result.Loadings {
+			for k := 0; k < nComponents; k++ {
+				reconstruction[i][j] += scores[k] * loadings[k]
+			}
+		}
+	}
+
+	if preprocessor == nil {
+		return reconstruction, nil
+	}
+	original, err := preprocessor.InverseTransform(reconstruction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map reconstruction to original units: %w", err)
+	}
+	return original, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestReconstruct(t *testing.T) {
+	data := types.Matrix{
+		{2.5, 24.0, 1.2},
+		{0.5, 7.0, 0.3},
+		{2.2, 29.0, 1.1},
+		{1.9, 22.0, 0.8},
+		{3.1, 30.0, 1.6},
+		{2.3, 27.0, 0.9},
+		{2.0, 16.0, 1.3},
+		{1.0, 11.0, 0.2},
+	}
+
+	for _, scale := range []string{"none", "standard", "robust"} {
+		t.Run(scale, func(t *testing.T) {
+			preprocessor := NewPreprocessor(true, scale == "standard", scale == "robust")
+			processed, err := preprocessor.FitTransform(data)
+			if err != nil {
+				t.Fatalf("Preprocessing failed: %v", err)
+			}
+			config := types.PCAConfig{Components: 3, MeanCenter: true, StandardScale: scale == "standard",
+				RobustScale: scale == "robust", Method: "svd"}
+			result, err := NewPCAEngine().Fit(processed, config)
+			if err != nil {
+				t.Fatalf("PCA fit failed: %v", err)
+			}
+
+			reconstruction, err := Reconstruct(result, preprocessor)
+			if err != nil {
+				t.Fatalf("Reconstruct failed: %v", err)
+			}
+			for i := range data {
+				for j := range data[i] {
+					if math.Abs(reconstruction[i][j]-data[i][j]) > 1e-8 {
+						t.Errorf("reconstruction[%d][%d] = %g, want %g", i, j, reconstruction[i][j], data[i][j])
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("row-wise preprocessing", func(t *testing.T) {
+		preprocessor := NewPreprocessorFull(true, false, false, true, false)
+		processed, err := preprocessor.FitTransform(data)
+		if err != nil {
+			t.Fatalf("Preprocessing failed: %v", err)
+		}
+		result, err := NewPCAEngine().Fit(processed, types.PCAConfig{Components: 2, Method: "svd"})
+		if err != nil {
+			t.Fatalf("PCA fit failed: %v", err)
+		}
+		if _, err := Reconstruct(result, preprocessor); err == nil {
+			t.Error("expected an error after SNV")
+		}
+	})
+
+	t.Run("kernel", func(t *testing.T) {
+		result := &types.PCAResult{Method: "kernel", Scores: types.Matrix{{1}}}
+		if _, err := Reconstruct(result, nil); err == nil {
+			t.Error("expected an error for kernel PCA")
+		}
+	})
+}
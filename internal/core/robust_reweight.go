@@ -0,0 +1,256 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/bitjungle/gopca/internal/utils"
+	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// DefaultRobustIterations is the number of reweighting passes unless another is set
+	DefaultRobustIterations = 3
+	// RobustStrongDownweight is the weight below which a row counts as strongly
+	// downweighted
+	RobustStrongDownweight = 0.5
+	// robustHuberK is the Huber tuning constant on the standardized distance: rows
+	// closer than this keep their full weight
+	robustHuberK = 2.0
+	// robustWeightTolerance stops the reweighting once no weight changes by more
+	// than this between passes
+	robustWeightTolerance = 1e-6
+)
+
+// RobustReweighting describes the row weights of an iteratively reweighted fit
+type RobustReweighting struct {
+	Weights    []float64 // Final weight of each row, 1 for rows fitted in full
+	Iterations int       // Reweighting passes made after the first fit
+	// Downweighted holds the rows, 0-based, whose final weight is below
+	// RobustStrongDownweight, most downweighted first
+	Downweighted []int
+}
+
+// RobustReweightPCA refits result, a fit to the preprocessed data, with rows weighted
+// by how far they lie from the model. In each pass the Hotelling's T² and SPE of every
+// row are calculated from the current fit, divided by their medians and combined
+// into a distance d, and the row gets the Huber weight min(1, k/d). The components are
+// then refitted as the eigenvectors of the weighted covariance about the weighted
+// mean. Passes stop after iterations or once the weights settle.
+//
+// This resists outliers that a single fit would turn components towards, without
+// the cost of a full minimum covariance determinant estimate. Scores are the
+// projections of data on the refitted loadings, as Transform gives them, and the
+// explained variance is that of the weighted covariance.
+func RobustReweightPCA(ctx context.Context, data types.Matrix, result *types.PCAResult,
+	iterations int) (*types.PCAResult, *RobustReweighting, error) {
+	if iterations < 1 {
+		return nil, nil, fmt.Errorf("robust iterations must be at least 1, got %d", iterations)
+	}
+	if result == nil || len(result.Loadings) == 0 {
+		return nil, nil, fmt.Errorf("robust reweighting needs a fit with loadings")
+	}
+	n := len(data)
+	if n < 3 {
+		return nil, nil, fmt.Errorf("robust reweighting needs at least 3 rows, got %d", n)
+	}
+	for i, row := range data {
+		for j, v := range row {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, nil, fmt.Errorf("robust reweighting needs complete data: row %d, column %d is not finite", i+1, j+1)
+			}
+		}
+	}
+
+	k := len(result.Loadings[0])
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	current := result
+	passes := 0
+	for passes < iterations {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		metrics, err := CalculateMetricsFromPCAResult(current, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("robust reweighting pass %d: %w", passes+1, err)
+		}
+		next := huberWeights(metrics)
+		change := 0.0
+		for i := range next {
+			change = max(change, math.Abs(next[i]-weights[i]))
+		}
+		if change < robustWeightTolerance {
+			break
+		}
+		weights = next
+		passes++
+
+		loadings, eigenvalues, err := weightedPCA(data, weights, k)
+		if err != nil {
+			return nil, nil, fmt.Errorf("robust reweighting pass %d: %w", passes, err)
+		}
+		alignLoadingSigns(loadings, current.Loadings)
+		current = reweightedResult(result, data, loadings, eigenvalues)
+	}
+
+	reweighting := &RobustReweighting{Weights: weights, Iterations: passes}
+	for i, w := range weights {
+		if w < RobustStrongDownweight {
+			reweighting.Downweighted = append(reweighting.Downweighted, i)
+		}
+	}
+	slices.SortStableFunc(reweighting.Downweighted, func(a, b int) int {
+		return cmp.Compare(weights[a], weights[b])
+	})
+	return current, reweighting, nil
+}
+
+// huberWeights returns the Huber weight of each row from its T² and SPE, each
+// divided by its median so that the two are on the same scale. A statistic with a
+// zero median, such as the SPE of a model with every component, is left out.
+func huberWeights(metrics []types.SampleMetrics) []float64 {
+	t2 := make([]float64, len(metrics))
+	spe := make([]float64, len(metrics))
+	for i, m := range metrics {
+		t2[i], spe[i] = m.HotellingT2, m.RSS
+	}
+	t2Median, speMedian := median(t2), median(spe)
+
+	weights := make([]float64, len(metrics))
+	for i := range metrics {
+		d2, terms := 0.0, 0
+		if t2Median > 0 {
+			d2 += t2[i] / t2Median
+			terms++
+		}
+		if speMedian > 0 {
+			d2 += spe[i] / speMedian
+			terms++
+		}
+		weights[i] = 1
+		if terms == 0 {
+			continue
+		}
+		if d := math.Sqrt(d2 / float64(terms)); d > robustHuberK {
+			weights[i] = robustHuberK / d
+		}
+	}
+	return weights
+}
+
+// median returns the median of values without changing their order
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// weightedPCA returns the first k eigenvectors, as columns, and all eigenvalues of
+// the weighted covariance of data about its weighted mean, from the SVD of the
+// centered rows scaled by the square roots of their weights
+func weightedPCA(data types.Matrix, weights []float64, k int) (*mat.Dense, []float64, error) {
+	n, m := len(data), len(data[0])
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 1 {
+		return nil, nil, fmt.Errorf("total row weight %.3g is too small to fit", total)
+	}
+	mean := make([]float64, m)
+	for i, row := range data {
+		for j, v := range row {
+			mean[j] += weights[i] * v
+		}
+	}
+	for j := range mean {
+		mean[j] /= total
+	}
+
+	Y := mat.NewDense(n, m, nil)
+	for i, row := range data {
+		s := math.Sqrt(weights[i])
+		for j, v := range row {
+			Y.Set(i, j, s*(v-mean[j]))
+		}
+	}
+	var svd mat.SVD
+	if !svd.Factorize(Y, mat.SVDThin) {
+		return nil, nil, fmt.Errorf("SVD of the weighted data failed")
+	}
+	var V mat.Dense
+	svd.VTo(&V)
+	values := svd.Values(nil)
+	eigenvalues := make([]float64, len(values))
+	for i, s := range values {
+		eigenvalues[i] = s * s / (total - 1)
+	}
+	k = min(k, len(values))
+	return mat.DenseCopyOf(V.Slice(0, m, 0, k)), eigenvalues, nil
+}
+
+// alignLoadingSigns flips each column of loadings that points away from the same
+// column of reference, so that the sign of a component is stable across passes
+func alignLoadingSigns(loadings *mat.Dense, reference types.Matrix) {
+	m, k := loadings.Dims()
+	for c := 0; c < k && c < len(reference[0]); c++ {
+		dot := 0.0
+		for j := 0; j < m; j++ {
+			dot += loadings.At(j, c) * reference[j][c]
+		}
+		if dot < 0 {
+			for j := 0; j < m; j++ {
+				loadings.Set(j, c, -loadings.At(j, c))
+			}
+		}
+	}
+}
+
+// reweightedResult copies base with the scores, loadings and variance of a weighted
+// fit
+func reweightedResult(base *types.PCAResult, data types.Matrix, loadings *mat.Dense, eigenvalues []float64) *types.PCAResult {
+	var scores mat.Dense
+	scores.Mul(utils.MatrixToDense(data), loadings)
+	_, k := loadings.Dims()
+
+	totalVar := 0.0
+	for _, v := range eigenvalues {
+		totalVar += v
+	}
+	ratio := make([]float64, k)
+	cumulative := make([]float64, k)
+	cumSum := 0.0
+	for c := 0; c < k; c++ {
+		if totalVar > 0 {
+			ratio[c] = eigenvalues[c] / totalVar * 100
+		}
+		cumSum += ratio[c]
+		cumulative[c] = cumSum
+	}
+
+	reweighted := *base
+	reweighted.Scores = utils.DenseToMatrix(&scores)
+	reweighted.Loadings = utils.DenseToMatrix(loadings)
+	reweighted.ExplainedVar = slices.Clone(eigenvalues[:k])
+	reweighted.ExplainedVarRatio = ratio
+	reweighted.CumulativeVar = cumulative
+	reweighted.AllEigenvalues = eigenvalues
+	return &reweighted
+}
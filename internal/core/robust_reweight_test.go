@@ -0,0 +1,81 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestRobustReweightPCA(t *testing.T) {
+	// 100 rows spread along (1, 1, 0, 0, 0)/√2 with small noise, and 6 outliers off
+	// that direction along the fifth axis, which turn PC1 of a single fit towards them
+	rng := rand.New(rand.NewSource(7))
+	clean := []float64{1 / math.Sqrt2, 1 / math.Sqrt2, 0, 0, 0}
+	var data types.Matrix
+	for i := 0; i < 100; i++ {
+		a := 3 * rng.NormFloat64()
+		row := make([]float64, len(clean))
+		for j := range row {
+			row[j] = a*clean[j] + 0.5*rng.NormFloat64()
+		}
+		data = append(data, row)
+	}
+	outliers := []int{100, 101, 102, 103, 104, 105}
+	for range outliers {
+		data = append(data, []float64{6 + 0.5*rng.NormFloat64(), 6 + 0.5*rng.NormFloat64(), 0, 0, 10})
+	}
+	processed, err := NewPreprocessor(true, false, false).FitTransform(data)
+	if err != nil {
+		t.Fatalf("preprocessing failed: %v", err)
+	}
+
+	result, err := NewPCAEngine().Fit(processed, types.PCAConfig{Components: 1, Method: "svd"})
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	reweighted, reweighting, err := RobustReweightPCA(context.Background(), processed, result, DefaultRobustIterations)
+	if err != nil {
+		t.Fatalf("RobustReweightPCA failed: %v", err)
+	}
+
+	pc1Alignment := func(r *types.PCAResult) float64 {
+		dot := 0.0
+		for j := range clean {
+			dot += r.Loadings[j][0] * clean[j]
+		}
+		return math.Abs(dot)
+	}
+	single, robust := pc1Alignment(result), pc1Alignment(reweighted)
+	if robust <= single || robust < 0.97 {
+		t.Errorf("expected the reweighted PC1 to align with the clean direction better than a single fit, "+
+			"got |cos| %.4f against %.4f", robust, single)
+	}
+
+	if reweighting.Iterations < 1 || reweighting.Iterations > DefaultRobustIterations {
+		t.Errorf("expected 1 to %d passes, got %d", DefaultRobustIterations, reweighting.Iterations)
+	}
+	downweighted := slices.Sorted(slices.Values(reweighting.Downweighted))
+	if !slices.Equal(downweighted, outliers) {
+		t.Errorf("expected the outliers %v to be strongly downweighted, got %v", outliers, downweighted)
+	}
+	if len(reweighted.Scores) != len(data) || len(reweighted.Scores[0]) != 1 {
+		t.Errorf("expected %d×1 scores, got %d×%d", len(data), len(reweighted.Scores), len(reweighted.Scores[0]))
+	}
+	if reweighted.CumulativeVar[0] > 100+1e-9 || reweighted.ExplainedVarRatio[0] < 50 {
+		t.Errorf("unexpected explained variance %v", reweighted.ExplainedVarRatio)
+	}
+
+	if _, _, err := RobustReweightPCA(context.Background(), processed, result, 0); err == nil {
+		t.Error("expected an error for zero iterations")
+	}
+}
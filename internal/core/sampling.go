@@ -0,0 +1,105 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// BalancedRowSample returns the indices, in ascending order, of a random sample of
+// rows that holds the same number of rows from every group: each group is
+// downsampled to the size of the smallest one, so that a majority group cannot
+// dominate the first components. Rows with an empty group are left out. The sample
+// is the same for the same groups and seed.
+func BalancedRowSample(groups []string, seed int64) ([]int, error) {
+	members := make(map[string][]int)
+	for i, group := range groups {
+		if group != "" {
+			members[group] = append(members[group], i)
+		}
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no rows have a group")
+	}
+
+	categories := make([]string, 0, len(members))
+	size := len(groups)
+	for category, rows := range members {
+		categories = append(categories, category)
+		size = min(size, len(rows))
+	}
+	sort.Strings(categories)
+
+	rng := rand.New(rand.NewSource(seed))
+	sample := make([]int, 0, size*len(categories))
+	for _, category := range categories {
+		rows := members[category]
+		for _, k := range rng.Perm(len(rows))[:size] {
+			sample = append(sample, rows[k])
+		}
+	}
+	sort.Ints(sample)
+	return sample, nil
+}
+
+// TrainTestSplit splits the rows 0…n-1 into a train and a test set, both in ascending
+// order, with round(testFrac·n) rows in the test set. When groups is not nil the
+// split is stratified: every group contributes round(testFrac·size) of its rows to
+// the test set, so that the group proportions of both sets match those of the data.
+// Rows with an empty group form a group of their own. The split is the same for the
+// same inputs and seed.
+func TrainTestSplit(n int, testFrac float64, groups []string, seed int64) (train, test []int, err error) {
+	if !(testFrac > 0 && testFrac < 1) {
+		return nil, nil, fmt.Errorf("test fraction must be between 0 and 1, got %g", testFrac)
+	}
+	if groups != nil && len(groups) != n {
+		return nil, nil, fmt.Errorf("groups (%d) do not match rows (%d)", len(groups), n)
+	}
+	if n < 2 {
+		return nil, nil, fmt.Errorf("need at least 2 rows to split, got %d", n)
+	}
+
+	members := map[string][]int{"": nil}
+	if groups == nil {
+		for i := 0; i < n; i++ {
+			members[""] = append(members[""], i)
+		}
+	} else {
+		for i, group := range groups {
+			members[group] = append(members[group], i)
+		}
+	}
+	categories := make([]string, 0, len(members))
+	for category := range members {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	rng := rand.New(rand.NewSource(seed))
+	inTest := make([]bool, n)
+	for _, category := range categories {
+		rows := members[category]
+		size := int(math.Round(testFrac * float64(len(rows))))
+		for _, k := range rng.Perm(len(rows))[:size] {
+			inTest[rows[k]] = true
+		}
+	}
+	for i, isTest := range inTest {
+		if isTest {
+			test = append(test, i)
+		} else {
+			train = append(train, i)
+		}
+	}
+	if len(test) == 0 || len(train) == 0 {
+		return nil, nil, fmt.Errorf("a test fraction of %g of %d rows leaves the train or test set empty", testFrac, n)
+	}
+	return train, test, nil
+}
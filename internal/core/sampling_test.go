@@ -0,0 +1,142 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestBalancedRowSample(t *testing.T) {
+	// 90 rows of class a spread along x, 10 rows of class b offset along y
+	var data types.Matrix
+	var groups []string
+	for i := 0; i < 90; i++ {
+		data = append(data, []float64{-3.5 + 7*float64(i)/89, 0})
+		groups = append(groups, "a")
+	}
+	for i := 0; i < 10; i++ {
+		data = append(data, []float64{-0.5 + float64(i)/9, 5})
+		groups = append(groups, "b")
+	}
+
+	sample, err := BalancedRowSample(groups, 1)
+	if err != nil {
+		t.Fatalf("BalancedRowSample failed: %v", err)
+	}
+	counts := map[string]int{}
+	for k, r := range sample {
+		counts[groups[r]]++
+		if k > 0 && r <= sample[k-1] {
+			t.Fatalf("expected ascending row indices, got %v", sample)
+		}
+	}
+	if counts["a"] != 10 || counts["b"] != 10 {
+		t.Errorf("expected 10 rows of each class, got %v", counts)
+	}
+
+	again, _ := BalancedRowSample(groups, 1)
+	if !reflect.DeepEqual(sample, again) {
+		t.Error("expected the same sample for the same seed")
+	}
+
+	// PC1 follows the spread of the majority class until the classes are balanced
+	config := types.PCAConfig{Components: 1, MeanCenter: true, Method: "svd"}
+	full, err := NewPCAEngine().Fit(data, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	balanced := make(types.Matrix, len(sample))
+	for k, r := range sample {
+		balanced[k] = data[r]
+	}
+	result, err := NewPCAEngine().Fit(balanced, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+	if math.Abs(full.Loadings[0][0]) < 0.9 {
+		t.Errorf("expected PC1 along x for the imbalanced data, got loadings %v", full.Loadings)
+	}
+	if math.Abs(result.Loadings[1][0]) < 0.9 {
+		t.Errorf("expected PC1 along y for the balanced data, got loadings %v", result.Loadings)
+	}
+
+	if _, err := BalancedRowSample([]string{"", ""}, 1); err == nil {
+		t.Error("expected an error when no rows have a group")
+	}
+}
+
+func TestTrainTestSplit(t *testing.T) {
+	// 60 rows of class a, 30 of b and 10 of c
+	var groups []string
+	for i := 0; i < 100; i++ {
+		switch {
+		case i%10 == 0:
+			groups = append(groups, "c")
+		case i%3 == 0:
+			groups = append(groups, "b")
+		default:
+			groups = append(groups, "a")
+		}
+	}
+	counts := map[string]int{}
+	for _, g := range groups {
+		counts[g]++
+	}
+
+	train, test, err := TrainTestSplit(len(groups), 0.2, groups, 42)
+	if err != nil {
+		t.Fatalf("TrainTestSplit failed: %v", err)
+	}
+	if len(train)+len(test) != len(groups) || len(test) != 20 {
+		t.Fatalf("expected 80 train and 20 test rows, got %d and %d", len(train), len(test))
+	}
+	seen := make(map[int]bool)
+	for _, rows := range [][]int{train, test} {
+		for k, r := range rows {
+			if seen[r] {
+				t.Fatalf("row %d is in both sets", r)
+			}
+			seen[r] = true
+			if k > 0 && r <= rows[k-1] {
+				t.Fatalf("expected ascending row indices, got %v", rows)
+			}
+		}
+	}
+	testCounts := map[string]int{}
+	for _, r := range test {
+		testCounts[groups[r]]++
+	}
+	for g, n := range counts {
+		if want := int(math.Round(0.2 * float64(n))); testCounts[g] != want {
+			t.Errorf("class %s: expected %d of %d rows in the test set, got %d", g, want, n, testCounts[g])
+		}
+	}
+
+	again, _, _ := TrainTestSplit(len(groups), 0.2, groups, 42)
+	if !reflect.DeepEqual(again, train) {
+		t.Error("expected the same split for the same seed")
+	}
+
+	// Without groups the rows are split as one group
+	train, test, err = TrainTestSplit(10, 0.3, nil, 1)
+	if err != nil || len(train) != 7 || len(test) != 3 {
+		t.Errorf("expected 7 train and 3 test rows, got %v and %v (%v)", train, test, err)
+	}
+
+	for _, frac := range []float64{0, 1, -0.5, math.NaN()} {
+		if _, _, err := TrainTestSplit(10, frac, nil, 1); err == nil {
+			t.Errorf("expected an error for test fraction %g", frac)
+		}
+	}
+	if _, _, err := TrainTestSplit(3, 0.1, nil, 1); err == nil {
+		t.Error("expected an error when the test set would be empty")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// NormalizeScores returns the scores of result divided per component by the square
+// root of its eigenvalue (explained variance), so that each score column has unit
+// variance, together with the divisors. The loadings are not rescaled: T·Pᵀ no longer
+// reconstructs the data from the normalized scores, and projecting new data onto the
+// loadings gives unnormalized scores.
+func NormalizeScores(result *types.PCAResult) (types.Matrix, []float64, error) {
+	if len(result.Scores) == 0 {
+		return nil, nil, fmt.Errorf("no scores to normalize")
+	}
+	nComponents := len(result.Scores[0])
+	if len(result.ExplainedVar) < nComponents {
+		return nil, nil, fmt.Errorf("scores have %d components, explained variance has %d",
+			nComponents, len(result.ExplainedVar))
+	}
+
+	scale := make([]float64, nComponents)
+	for k := range scale {
+		if !(result.ExplainedVar[k] > 0) {
+			return nil, nil, fmt.Errorf("component %d has no variance to normalize by", k+1)
+		}
+		scale[k] = math.Sqrt(result.ExplainedVar[k])
+	}
+
+	normalized := make(types.Matrix, len(result.Scores))
+	for i, row := range result.Scores {
+		normalized[i] = make([]float64, nComponents)
+		for k, v := range row {
+			normalized[i][k] = v / scale[k]
+		}
+	}
+	return normalized, scale, nil
+}
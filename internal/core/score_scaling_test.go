@@ -0,0 +1,63 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestNormalizeScores(t *testing.T) {
+	data := types.Matrix{
+		{2.5, 24.0, 1.2},
+		{0.5, 7.0, 0.3},
+		{2.2, 29.0, 1.1},
+		{1.9, 22.0, 0.8},
+		{3.1, 30.0, 1.6},
+		{2.3, 27.0, 0.9},
+		{2.0, 16.0, 1.3},
+		{1.0, 11.0, 0.2},
+	}
+
+	for _, method := range []string{"svd", "nipals"} {
+		t.Run(method, func(t *testing.T) {
+			processed, err := NewPreprocessor(true, true, false).FitTransform(data)
+			if err != nil {
+				t.Fatalf("Preprocessing failed: %v", err)
+			}
+			config := types.PCAConfig{Components: 3, MeanCenter: true, StandardScale: true, Method: method}
+			result, err := NewPCAEngineForMethod(method).Fit(processed, config)
+			if err != nil {
+				t.Fatalf("PCA fit failed: %v", err)
+			}
+
+			normalized, scale, err := NormalizeScores(result)
+			if err != nil {
+				t.Fatalf("NormalizeScores failed: %v", err)
+			}
+			for k := range scale {
+				column := make([]float64, len(normalized))
+				for i := range normalized {
+					column[i] = normalized[i][k]
+				}
+				if v := stat.Variance(column, nil); math.Abs(v-1) > 1e-6 {
+					t.Errorf("PC%d: expected unit variance, got %g", k+1, v)
+				}
+				if math.Abs(normalized[0][k]*scale[k]-result.Scores[0][k]) > 1e-12 {
+					t.Errorf("PC%d: scale %g does not undo the normalization", k+1, scale[k])
+				}
+			}
+		})
+	}
+
+	if _, _, err := NormalizeScores(&types.PCAResult{Scores: types.Matrix{{1}}, ExplainedVar: []float64{0}}); err == nil {
+		t.Error("expected an error for a component without variance")
+	}
+}
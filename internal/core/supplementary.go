@@ -0,0 +1,124 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// ProjectGroupCentroids projects the centroid of each category onto the fitted loadings
+// as a supplementary point. data must be preprocessed the same way as the data the
+// model was fitted on, with one row per entry in groups. Rows with an empty
+// category are ignored. Categories are returned in order of first appearance.
+func ProjectGroupCentroids(data types.Matrix, loadings types.Matrix, groups []string) (*types.SupplementaryGroups, error) {
+	if len(data) == 0 || len(loadings) == 0 {
+		return nil, fmt.Errorf("data and loadings are required")
+	}
+	if len(groups) != len(data) {
+		return nil, fmt.Errorf("group labels (%d) do not match data rows (%d)", len(groups), len(data))
+	}
+	nFeatures, nComponents := len(loadings), len(loadings[0])
+	if len(data[0]) != nFeatures {
+		return nil, fmt.Errorf("data has %d features, loadings have %d", len(data[0]), nFeatures)
+	}
+
+	// Accumulate centroids per category
+	index := make(map[string]int)
+	result := &types.SupplementaryGroups{}
+	var sums types.Matrix
+	for i, group := range groups {
+		if group == "" {
+			continue
+		}
+		k, ok := index[group]
+		if !ok {
+			k = len(result.Categories)
+			index[group] = k
+			result.Categories = append(result.Categories, group)
+			result.Counts = append(result.Counts, 0)
+			sums = append(sums, make([]float64, nFeatures))
+		}
+		result.Counts[k]++
+		for j, v := range data[i] {
+			sums[k][j] += v
+		}
+	}
+	if len(result.Categories) == 0 {
+		return nil, fmt.Errorf("no categories found")
+	}
+
+	// Project each centroid: score = centroid · loadings
+	result.Scores = make(types.Matrix, len(result.Categories))
+	for k, sum := range sums {
+		scores := make([]float64, nComponents)
+		n := float64(result.Counts[k])
+		for j, s := range sum {
+			centroid := s / n
+			for c := 0; c < nComponents; c++ {
+				scores[c] += centroid * loadings[j][c]
+			}
+		}
+		result.Scores[k] = scores
+	}
+
+	return result, nil
+}
+
+// GroupScoreMeans returns the mean score of each category on every component, with one
+// entry in groups per row of scores. Rows with an empty category are ignored, as are
+// NaN scores. Categories are returned in order of first appearance.
+func GroupScoreMeans(scores types.Matrix, groups []string) (categories []string, counts []int, means types.Matrix, err error) {
+	if len(scores) == 0 {
+		return nil, nil, nil, fmt.Errorf("scores are required")
+	}
+	if len(groups) != len(scores) {
+		return nil, nil, nil, fmt.Errorf("group labels (%d) do not match score rows (%d)", len(groups), len(scores))
+	}
+	nComponents := len(scores[0])
+
+	index := make(map[string]int)
+	var observed [][]int
+	for i, group := range groups {
+		if group == "" {
+			continue
+		}
+		k, ok := index[group]
+		if !ok {
+			k = len(categories)
+			index[group] = k
+			categories = append(categories, group)
+			counts = append(counts, 0)
+			means = append(means, make([]float64, nComponents))
+			observed = append(observed, make([]int, nComponents))
+		}
+		counts[k]++
+		for c, v := range scores[i] {
+			if !math.IsNaN(v) {
+				means[k][c] += v
+				observed[k][c]++
+			}
+		}
+	}
+	if len(categories) == 0 {
+		return nil, nil, nil, fmt.Errorf("no categories found")
+	}
+
+	for k := range means {
+		for c := range means[k] {
+			if observed[k][c] == 0 {
+				means[k][c] = math.NaN()
+				continue
+			}
+			means[k][c] /= float64(observed[k][c])
+		}
+	}
+
+	return categories, counts, means, nil
+}
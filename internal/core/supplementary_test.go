@@ -0,0 +1,129 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestProjectGroupCentroids(t *testing.T) {
+	data := types.Matrix{
+		{2.5, 2.4, 1.2},
+		{0.5, 0.7, 0.3},
+		{2.2, 2.9, 1.1},
+		{1.9, 2.2, 0.8},
+		{3.1, 3.0, 1.6},
+		{2.3, 2.7, 0.9},
+		{2.0, 1.6, 1.3},
+		{1.0, 1.1, 0.2},
+	}
+	groups := []string{"a", "b", "a", "c", "a", "", "c", "b"}
+
+	preprocessor := NewPreprocessor(true, true, false)
+	processed, err := preprocessor.FitTransform(data)
+	if err != nil {
+		t.Fatalf("Preprocessing failed: %v", err)
+	}
+
+	config := types.PCAConfig{Components: 2, MeanCenter: true, StandardScale: true, Method: "svd"}
+	result, err := NewPCAEngine().Fit(processed, config)
+	if err != nil {
+		t.Fatalf("PCA fit failed: %v", err)
+	}
+
+	supplementary, err := ProjectGroupCentroids(processed, result.Loadings, groups)
+	if err != nil {
+		t.Fatalf("ProjectGroupCentroids failed: %v", err)
+	}
+
+	expectedCategories := []string{"a", "b", "c"}
+	expectedMembers := [][]int{{0, 2, 4}, {1, 7}, {3, 6}}
+	if len(supplementary.Categories) != len(expectedCategories) {
+		t.Fatalf("Expected categories %v, got %v", expectedCategories, supplementary.Categories)
+	}
+
+	for k, category := range expectedCategories {
+		if supplementary.Categories[k] != category {
+			t.Errorf("Category %d: expected %q, got %q", k, category, supplementary.Categories[k])
+		}
+		members := expectedMembers[k]
+		if supplementary.Counts[k] != len(members) {
+			t.Errorf("%s: expected count %d, got %d", category, len(members), supplementary.Counts[k])
+		}
+
+		// Project the mean of the member rows directly
+		mean := make([]float64, len(processed[0]))
+		for _, i := range members {
+			for j, v := range processed[i] {
+				mean[j] += v / float64(len(members))
+			}
+		}
+		for c := 0; c < config.Components; c++ {
+			expected := 0.0
+			meanScore := 0.0
+			for j, v := range mean {
+				expected += v * result.Loadings[j][c]
+			}
+			for _, i := range members {
+				meanScore += result.Scores[i][c] / float64(len(members))
+			}
+			got := supplementary.Scores[k][c]
+			if math.Abs(got-expected) > 1e-12 {
+				t.Errorf("%s PC%d: expected projected centroid %f, got %f", category, c+1, expected, got)
+			}
+			// For linear PCA the centroid score is also the mean of the member scores
+			if math.Abs(got-meanScore) > 1e-10 {
+				t.Errorf("%s PC%d: expected mean member score %f, got %f", category, c+1, meanScore, got)
+			}
+		}
+	}
+
+	if _, err := ProjectGroupCentroids(processed, result.Loadings, groups[:3]); err == nil {
+		t.Error("Expected error for mismatched group labels")
+	}
+}
+
+func TestGroupScoreMeans(t *testing.T) {
+	scores := types.Matrix{
+		{1, 2},
+		{-1, 0},
+		{3, math.NaN()},
+		{5, 4},
+		{0, 1},
+	}
+	groups := []string{"a", "b", "a", "a", ""}
+
+	categories, counts, means, err := GroupScoreMeans(scores, groups)
+	if err != nil {
+		t.Fatalf("GroupScoreMeans failed: %v", err)
+	}
+
+	if len(categories) != 2 || categories[0] != "a" || categories[1] != "b" {
+		t.Fatalf("Expected categories [a b], got %v", categories)
+	}
+	if counts[0] != 3 || counts[1] != 1 {
+		t.Errorf("Expected counts [3 1], got %v", counts)
+	}
+	expected := types.Matrix{{3, 3}, {-1, 0}}
+	for k := range expected {
+		for c := range expected[k] {
+			if math.Abs(means[k][c]-expected[k][c]) > 1e-12 {
+				t.Errorf("%s PC%d: expected %f, got %f", categories[k], c+1, expected[k][c], means[k][c])
+			}
+		}
+	}
+
+	if _, _, _, err := GroupScoreMeans(scores, groups[:2]); err == nil {
+		t.Error("Expected error for mismatched group labels")
+	}
+	if _, _, _, err := GroupScoreMeans(scores, make([]string, len(scores))); err == nil {
+		t.Error("Expected error when no row has a category")
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// t-SNE optimization settings used by TSNEEmbed, following van der Maaten (2014)
+const (
+	// TSNEDefaultPerplexity is the default effective number of neighbors
+	TSNEDefaultPerplexity = 30
+	// TSNEDefaultIterations is the default number of gradient descent iterations
+	TSNEDefaultIterations = 1000
+
+	// tsneTheta is the Barnes-Hut accuracy: cells smaller than theta times their
+	// distance are treated as a single point
+	tsneTheta = 0.5
+	// tsneExaggeration multiplies the input affinities during the early iterations,
+	// which lets clusters form before the embedding is fine-tuned
+	tsneExaggeration = 12.0
+	// tsneExaggerationIterations is the maximum number of exaggerated iterations
+	tsneExaggerationIterations = 250
+	tsneInitialMomentum        = 0.5
+	tsneFinalMomentum          = 0.8
+	tsneMinGain                = 0.01
+	// tsnePerplexityTolerance is the accepted error of the row entropy, in nats
+	tsnePerplexityTolerance = 1e-5
+	tsnePerplexitySteps     = 200
+	// tsneMaxTreeDepth stops the quadtree from splitting (near-)duplicate points forever
+	tsneMaxTreeDepth = 50
+)
+
+// TSNEEmbed computes a two-dimensional t-SNE embedding of the rows of data for
+// visualization. Input affinities are computed from the 3·perplexity nearest
+// neighbors of each row, and the embedding is optimized with Barnes-Hut gradient
+// descent. The initial embedding is drawn from rng, so a seeded rng gives
+// reproducible results.
+//
+// Unlike PCA, the embedding has no loadings or explained variance, and distances
+// between clusters and cluster sizes in the embedding are not meaningful. It should
+// only be used to explore cluster structure visually.
+//
+// Reference: van der Maaten, L. (2014). Accelerating t-SNE using tree-based
+// algorithms. Journal of Machine Learning Research, 15, 3221-3245.
+func TSNEEmbed(data types.Matrix, perplexity float64, iterations int, rng *rand.Rand) (types.Matrix, error) {
+	n := len(data)
+	if n < 3 {
+		return nil, fmt.Errorf("t-SNE needs at least 3 samples, got %d", n)
+	}
+	if perplexity <= 0 || perplexity >= float64(n) {
+		return nil, fmt.Errorf("perplexity must be in (0,%d) for %d samples, got %g", n, n, perplexity)
+	}
+	if iterations < 1 {
+		return nil, fmt.Errorf("t-SNE needs at least 1 iteration, got %d", iterations)
+	}
+	if rng == nil {
+		return nil, fmt.Errorf("t-SNE needs a random number generator")
+	}
+	for i, row := range data {
+		if len(row) != len(data[0]) {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", i+1, len(row), len(data[0]))
+		}
+		for _, v := range row {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("row %d contains missing or infinite values", i+1)
+			}
+		}
+	}
+
+	P := tsneAffinities(data, perplexity)
+
+	Y := make(types.Matrix, n)
+	update := make(types.Matrix, n)
+	gains := make(types.Matrix, n)
+	grad := make(types.Matrix, n)
+	for i := range Y {
+		Y[i] = []float64{rng.NormFloat64() * 1e-4, rng.NormFloat64() * 1e-4}
+		update[i] = make([]float64, 2)
+		gains[i] = []float64{1, 1}
+		grad[i] = make([]float64, 2)
+	}
+
+	learningRate := math.Max(float64(n)/tsneExaggeration, 50)
+	exaggerated := min(tsneExaggerationIterations, iterations/4)
+	for it := 0; it < iterations; it++ {
+		exaggeration, momentum := 1.0, tsneFinalMomentum
+		if it < exaggerated {
+			exaggeration, momentum = tsneExaggeration, tsneInitialMomentum
+		}
+		tsneGradient(Y, P, exaggeration, grad)
+
+		for i := range Y {
+			for d := 0; d < 2; d++ {
+				// Increase the gain while the gradient keeps its direction
+				if (grad[i][d] > 0) != (update[i][d] > 0) {
+					gains[i][d] += 0.2
+				} else {
+					gains[i][d] = math.Max(gains[i][d]*0.8, tsneMinGain)
+				}
+				update[i][d] = momentum*update[i][d] - learningRate*gains[i][d]*grad[i][d]
+				Y[i][d] += update[i][d]
+			}
+		}
+
+		// Keep the embedding centered at the origin
+		var meanX, meanY float64
+		for i := range Y {
+			meanX += Y[i][0] / float64(n)
+			meanY += Y[i][1] / float64(n)
+		}
+		for i := range Y {
+			Y[i][0] -= meanX
+			Y[i][1] -= meanY
+		}
+	}
+
+	return Y, nil
+}
+
+// tsneNeighbor is a nonzero input affinity between two samples
+type tsneNeighbor struct {
+	index int
+	p     float64
+}
+
+// tsneAffinities returns the symmetric input affinities P as sparse rows. Each
+// sample gets conditional affinities to its 3·perplexity nearest neighbors with a
+// Gaussian bandwidth chosen so their perplexity matches; P is the symmetrized
+// conditional affinities, normalized to sum to 1.
+func tsneAffinities(data types.Matrix, perplexity float64) [][]tsneNeighbor {
+	n := len(data)
+	k := min(n-1, int(3*perplexity))
+	target := math.Log(perplexity)
+
+	conditional := make([][]tsneNeighbor, n)
+	lookup := make([]map[int]float64, n)
+	distances := make([]float64, n)
+	order := make([]int, 0, n-1)
+	for i := range data {
+		order = order[:0]
+		for j := range data {
+			var d float64
+			for c := range data[i] {
+				diff := data[i][c] - data[j][c]
+				d += diff * diff
+			}
+			distances[j] = d
+			if j != i {
+				order = append(order, j)
+			}
+		}
+		sort.Slice(order, func(a, b int) bool { return distances[order[a]] < distances[order[b]] })
+		neighbors := order[:k]
+
+		// Binary search for the precision beta = 1/(2σ²) that gives the target entropy.
+		// Distances are shifted by the nearest one, which leaves the affinities unchanged.
+		nearest := distances[neighbors[0]]
+		weights := make([]float64, k)
+		beta, lo, hi := 1.0, 0.0, math.Inf(1)
+		for step := 0; step < tsnePerplexitySteps; step++ {
+			var sum, weighted float64
+			for a, j := range neighbors {
+				weights[a] = math.Exp(-beta * (distances[j] - nearest))
+				sum += weights[a]
+				weighted += weights[a] * (distances[j] - nearest)
+			}
+			entropy := math.Log(sum) + beta*weighted/sum
+			if math.Abs(entropy-target) < tsnePerplexityTolerance {
+				break
+			}
+			if entropy > target {
+				lo = beta
+				if math.IsInf(hi, 1) {
+					beta *= 2
+				} else {
+					beta = (beta + hi) / 2
+				}
+			} else {
+				hi = beta
+				beta = (beta + lo) / 2
+			}
+		}
+
+		var sum float64
+		for _, w := range weights {
+			sum += w
+		}
+		conditional[i] = make([]tsneNeighbor, k)
+		lookup[i] = make(map[int]float64, k)
+		for a, j := range neighbors {
+			conditional[i][a] = tsneNeighbor{index: j, p: weights[a] / sum}
+			lookup[i][j] = weights[a] / sum
+		}
+	}
+
+	// P_ij = (p_j|i + p_i|j) / 2n. The slices, not the maps, are iterated so the
+	// order of the affinities, and so the embedding, is reproducible.
+	P := make([][]tsneNeighbor, n)
+	for i := range conditional {
+		for _, nb := range conditional[i] {
+			j, p := nb.index, nb.p
+			if q, ok := lookup[j][i]; ok {
+				// Mutual neighbors are added once, from the lower index
+				if j < i {
+					continue
+				}
+				p += q
+			}
+			p /= 2 * float64(n)
+			P[i] = append(P[i], tsneNeighbor{index: j, p: p})
+			P[j] = append(P[j], tsneNeighbor{index: i, p: p})
+		}
+	}
+	return P
+}
+
+// tsneGradient stores the gradient of the Kullback-Leibler divergence between the
+// input affinities P, multiplied by exaggeration, and the Student-t affinities of
+// the embedding Y in grad. Repulsive forces are approximated with a Barnes-Hut quadtree.
+func tsneGradient(Y types.Matrix, P [][]tsneNeighbor, exaggeration float64, grad types.Matrix) {
+	tree := newTSNEQuadTree(Y)
+
+	var sumQ float64
+	repulsive := make(types.Matrix, len(Y))
+	for i, y := range Y {
+		repulsive[i] = make([]float64, 2)
+		tree.repulsion(i, y[0], y[1], repulsive[i], &sumQ)
+	}
+
+	for i, y := range Y {
+		var attractX, attractY float64
+		for _, nb := range P[i] {
+			dx, dy := y[0]-Y[nb.index][0], y[1]-Y[nb.index][1]
+			q := 1 / (1 + dx*dx + dy*dy)
+			attractX += exaggeration * nb.p * q * dx
+			attractY += exaggeration * nb.p * q * dy
+		}
+		grad[i][0] = 4 * (attractX - repulsive[i][0]/sumQ)
+		grad[i][1] = 4 * (attractY - repulsive[i][1]/sumQ)
+	}
+}
+
+// tsneQuadTree is a node of the Barnes-Hut quadtree over the embedding
+type tsneQuadTree struct {
+	centerX, centerY, half float64 // Cell center and half width
+	sumX, sumY             float64 // Sum of the points in the cell, for the center of mass
+	count                  int
+	point                  int // Index of the only point in a leaf, -1 otherwise
+	children               []*tsneQuadTree
+}
+
+// newTSNEQuadTree builds a quadtree containing the points of Y
+func newTSNEQuadTree(Y types.Matrix) *tsneQuadTree {
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, y := range Y {
+		minX, maxX = math.Min(minX, y[0]), math.Max(maxX, y[0])
+		minY, maxY = math.Min(minY, y[1]), math.Max(maxY, y[1])
+	}
+	half := math.Max(maxX-minX, maxY-minY)/2 + 1e-5
+	root := &tsneQuadTree{centerX: (minX + maxX) / 2, centerY: (minY + maxY) / 2, half: half, point: -1}
+	for i, y := range Y {
+		root.insert(i, y[0], y[1], 0)
+	}
+	return root
+}
+
+// insert adds point i at (x, y) to the cell
+func (t *tsneQuadTree) insert(i int, x, y float64, depth int) {
+	t.sumX += x
+	t.sumY += y
+	t.count++
+	if t.count == 1 {
+		t.point = i
+		return
+	}
+	if t.children == nil {
+		if depth >= tsneMaxTreeDepth {
+			// Keep (near-)duplicate points together in one leaf
+			t.point = -1
+			return
+		}
+		half := t.half / 2
+		t.children = make([]*tsneQuadTree, 4)
+		for c := range t.children {
+			dx, dy := -half, -half
+			if c&1 != 0 {
+				dx = half
+			}
+			if c&2 != 0 {
+				dy = half
+			}
+			t.children[c] = &tsneQuadTree{centerX: t.centerX + dx, centerY: t.centerY + dy, half: half, point: -1}
+		}
+		if t.point >= 0 {
+			t.child(t.sumX-x, t.sumY-y).insert(t.point, t.sumX-x, t.sumY-y, depth+1)
+			t.point = -1
+		}
+	}
+	t.child(x, y).insert(i, x, y, depth+1)
+}
+
+// child returns the quadrant of the cell containing (x, y)
+func (t *tsneQuadTree) child(x, y float64) *tsneQuadTree {
+	c := 0
+	if x > t.centerX {
+		c |= 1
+	}
+	if y > t.centerY {
+		c |= 2
+	}
+	return t.children[c]
+}
+
+// repulsion adds the unnormalized repulsive force on point i at (x, y) to force
+// and its Student-t affinities to sumQ, treating distant cells as single points
+func (t *tsneQuadTree) repulsion(i int, x, y float64, force []float64, sumQ *float64) {
+	if t.count == 0 || (t.count == 1 && t.point == i) {
+		return
+	}
+	dx := x - t.sumX/float64(t.count)
+	dy := y - t.sumY/float64(t.count)
+	d2 := dx*dx + dy*dy
+	if t.children == nil || 4*t.half*t.half < tsneTheta*tsneTheta*d2 {
+		q := 1 / (1 + d2)
+		weight := float64(t.count) * q
+		*sumQ += weight
+		force[0] += weight * q * dx
+		force[1] += weight * q * dy
+		return
+	}
+	for _, c := range t.children {
+		c.repulsion(i, x, y, force, sumQ)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestTSNEEmbedSeparatesClusters(t *testing.T) {
+	// Three well-separated Gaussian blobs in 5 dimensions
+	rng := rand.New(rand.NewSource(42))
+	const perCluster = 30
+	var data types.Matrix
+	var labels []int
+	for c := 0; c < 3; c++ {
+		for i := 0; i < perCluster; i++ {
+			row := make([]float64, 5)
+			for j := range row {
+				row[j] = rng.NormFloat64()
+			}
+			row[c] += 20
+			data = append(data, row)
+			labels = append(labels, c)
+		}
+	}
+
+	embedding, err := TSNEEmbed(data, 10, 500, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("TSNEEmbed failed: %v", err)
+	}
+	if len(embedding) != len(data) || len(embedding[0]) != 2 {
+		t.Fatalf("Expected a %dx2 embedding, got %dx%d", len(data), len(embedding), len(embedding[0]))
+	}
+
+	var within, between float64
+	var nWithin, nBetween int
+	maxWithin, minBetween := 0.0, math.Inf(1)
+	for i := range embedding {
+		for j := i + 1; j < len(embedding); j++ {
+			d := math.Hypot(embedding[i][0]-embedding[j][0], embedding[i][1]-embedding[j][1])
+			if labels[i] == labels[j] {
+				within += d
+				nWithin++
+				maxWithin = math.Max(maxWithin, d)
+			} else {
+				between += d
+				nBetween++
+				minBetween = math.Min(minBetween, d)
+			}
+		}
+	}
+	within /= float64(nWithin)
+	between /= float64(nBetween)
+	if within >= between/2 {
+		t.Errorf("Expected mean within-cluster distance (%f) to be well below mean between-cluster distance (%f)",
+			within, between)
+	}
+	if maxWithin >= minBetween {
+		t.Errorf("Expected every within-cluster distance (max %f) to be smaller than every between-cluster distance (min %f)",
+			maxWithin, minBetween)
+	}
+}
+
+func TestTSNEEmbedReproducible(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	data := make(types.Matrix, 20)
+	for i := range data {
+		data[i] = []float64{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}
+	}
+
+	first, err := TSNEEmbed(data, 5, 100, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("TSNEEmbed failed: %v", err)
+	}
+	second, err := TSNEEmbed(data, 5, 100, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("TSNEEmbed failed: %v", err)
+	}
+	for i := range first {
+		if first[i][0] != second[i][0] || first[i][1] != second[i][1] {
+			t.Fatalf("Row %d differs between runs with the same seed: %v vs %v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestTSNEEmbedErrors(t *testing.T) {
+	data := types.Matrix{{1, 2}, {3, 4}, {5, 6}, {7, 8}}
+	rng := rand.New(rand.NewSource(1))
+
+	if _, err := TSNEEmbed(data, 4, 100, rng); err == nil {
+		t.Error("Expected an error when the perplexity is not below the number of samples")
+	}
+	if _, err := TSNEEmbed(data, 0, 100, rng); err == nil {
+		t.Error("Expected an error for zero perplexity")
+	}
+	if _, err := TSNEEmbed(data, 2, 0, rng); err == nil {
+		t.Error("Expected an error for zero iterations")
+	}
+	if _, err := TSNEEmbed(types.Matrix{{1, 2}, {3, math.NaN()}, {5, 6}}, 1, 100, rng); err == nil {
+		t.Error("Expected an error for missing values")
+	}
+}
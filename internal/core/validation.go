@@ -9,6 +9,8 @@ package core
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 
 	"github.com/bitjungle/gopca/pkg/types"
 )
@@ -41,24 +43,97 @@ func ValidateDataMatrix(data types.Matrix) error {
 	return nil
 }
 
-// ValidateNaNValues checks for NaN values in the data matrix
-func ValidateNaNValues(data types.Matrix, allowNaN bool) error {
-	if allowNaN {
-		return nil
+// maxNonFiniteExamples is the number of non-finite value locations listed in errors
+const maxNonFiniteExamples = 5
+
+// NonFiniteValue is the location of a NaN or infinite value in a data matrix
+type NonFiniteValue struct {
+	Row    int // 0-based row index
+	Column int // 0-based column index
+	Value  float64
+}
+
+// NonFiniteValuesError reports every NaN and infinite value found in a data matrix
+type NonFiniteValuesError struct {
+	Values   []NonFiniteValue // Row-major order
+	NaNCount int
+	InfCount int
+}
+
+// Error summarizes the counts and lists the first few locations, with 1-based row and
+// column numbers
+func (e *NonFiniteValuesError) Error() string {
+	var counts []string
+	if e.NaNCount > 0 {
+		counts = append(counts, fmt.Sprintf("%d NaN", e.NaNCount))
+	}
+	if e.InfCount > 0 {
+		counts = append(counts, fmt.Sprintf("%d infinite", e.InfCount))
 	}
 
-	n := len(data)
-	m := len(data[0])
+	examples := make([]string, 0, maxNonFiniteExamples)
+	for _, v := range e.Values[:min(len(e.Values), maxNonFiniteExamples)] {
+		examples = append(examples, fmt.Sprintf("%g at row %d, column %d", v.Value, v.Row+1, v.Column+1))
+	}
+	if more := len(e.Values) - len(examples); more > 0 {
+		examples = append(examples, fmt.Sprintf("and %d more", more))
+	}
 
-	for i := 0; i < n; i++ {
-		for j := 0; j < m; j++ {
-			if math.IsNaN(data[i][j]) {
-				return fmt.Errorf("NaN value found at row %d, column %d - use missing value handling before PCA", i+1, j+1)
+	hint := "use missing value handling before PCA"
+	if e.InfCount > 0 {
+		hint = "infinite values often come from division by zero in an upstream transform"
+		if e.NaNCount > 0 {
+			hint += "; use missing value handling for NaN"
+		}
+	}
+	return fmt.Sprintf("data contains %s value(s): %s - %s",
+		strings.Join(counts, " and "), strings.Join(examples, "; "), hint)
+}
+
+// FindNonFiniteValues returns the location of every NaN and ±Inf value in data, or
+// nil if all values are finite
+func FindNonFiniteValues(data types.Matrix) *NonFiniteValuesError {
+	var report NonFiniteValuesError
+	for i, row := range data {
+		for j, v := range row {
+			switch {
+			case math.IsNaN(v):
+				report.NaNCount++
+			case math.IsInf(v, 0):
+				report.InfCount++
+			default:
+				continue
 			}
+			report.Values = append(report.Values, NonFiniteValue{Row: i, Column: j, Value: v})
 		}
 	}
+	if len(report.Values) == 0 {
+		return nil
+	}
+	return &report
+}
 
-	return nil
+// ValidateNaNValues checks the data matrix for NaN and infinite values. Infinite
+// values are always an error; NaN values are accepted if allowNaN is set. The error
+// is a *NonFiniteValuesError listing the offending locations.
+func ValidateNaNValues(data types.Matrix, allowNaN bool) error {
+	report := FindNonFiniteValues(data)
+	if report == nil {
+		return nil
+	}
+	if allowNaN {
+		if report.InfCount == 0 {
+			return nil
+		}
+		infinite := &NonFiniteValuesError{InfCount: report.InfCount}
+		for _, v := range report.Values {
+			if math.IsInf(v.Value, 0) {
+				infinite.Values = append(infinite.Values, v)
+			}
+		}
+		return infinite
+	}
+	return report
 }
 
 // ValidateComponentCount validates the number of components requested
@@ -82,6 +157,12 @@ func CalculateMaxComponents(rows, cols int) int {
 	return rows
 }
 
+// MaxInformativeComponents returns the largest number of components that can carry
+// variance in mean-centered data, min(rows−1, cols), and at least 1
+func MaxInformativeComponents(rows, cols int) int {
+	return max(1, min(rows-1, cols))
+}
+
 // ValidatePCAInput performs complete validation for PCA input
 func ValidatePCAInput(data types.Matrix, config types.PCAConfig) error {
 	// Basic matrix validation
@@ -170,3 +251,107 @@ func CheckForConstantColumns(data types.Matrix) ([]int, error) {
 
 	return constantCols, nil
 }
+
+// CheckForZeroVarianceRows returns the indices of rows whose non-missing values are all
+// identical (including rows with no non-missing values). Such rows carry no
+// information about the variables and can produce spurious components.
+func CheckForZeroVarianceRows(data types.Matrix) ([]int, error) {
+	if len(data) == 0 || len(data[0]) == 0 {
+		return nil, fmt.Errorf("empty data matrix")
+	}
+
+	zeroRows := []int{}
+	for i, row := range data {
+		var sum float64
+		count := 0
+		for _, val := range row {
+			if !math.IsNaN(val) {
+				sum += val
+				count++
+			}
+		}
+		if count == 0 {
+			zeroRows = append(zeroRows, i)
+			continue
+		}
+
+		mean := sum / float64(count)
+		var sumSq float64
+		for _, val := range row {
+			if !math.IsNaN(val) {
+				sumSq += (val - mean) * (val - mean)
+			}
+		}
+		if sumSq/float64(count) < MinVarianceThreshold {
+			zeroRows = append(zeroRows, i)
+		}
+	}
+
+	return zeroRows, nil
+}
+
+// handleZeroVarianceRows checks the rows of data for zero variance, unless
+// config.ZeroVarianceRowsChecked, and drops them if config.DropZeroVarianceRows. It
+// returns the data to fit, the dropped rows and a warning describing the rows found,
+// or "" if there are none.
+func handleZeroVarianceRows(data types.Matrix, config types.PCAConfig) (types.Matrix, []int, string, error) {
+	if config.ZeroVarianceRowsChecked {
+		return data, nil, "", nil
+	}
+	zeroRows, err := CheckForZeroVarianceRows(data)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("validation failed: %w", err)
+	}
+	if len(zeroRows) == 0 {
+		return data, nil, "", nil
+	}
+
+	if !config.DropZeroVarianceRows {
+		return data, nil, fmt.Sprintf("%d row(s) have zero variance (all values identical): %s",
+			len(zeroRows), formatRowNumbers(zeroRows)), nil
+	}
+	if len(zeroRows) == len(data) {
+		return nil, nil, "", fmt.Errorf("all %d rows have zero variance", len(data))
+	}
+	data, _ = RemoveRows(data, zeroRows)
+	return data, zeroRows, fmt.Sprintf("dropped %d row(s) with zero variance (all values identical): %s",
+		len(zeroRows), formatRowNumbers(zeroRows)), nil
+}
+
+// RemoveRows returns the data without the given rows, together with the indices of the rows kept
+func RemoveRows(data types.Matrix, rows []int) (types.Matrix, []int) {
+	drop := make(map[int]bool, len(rows))
+	for _, r := range rows {
+		drop[r] = true
+	}
+
+	keepRows := make([]int, 0, len(data))
+	cleanData := make(types.Matrix, 0, len(data))
+	for i, row := range data {
+		if !drop[i] {
+			keepRows = append(keepRows, i)
+			cleanData = append(cleanData, row)
+		}
+	}
+
+	return cleanData, keepRows
+}
+
+// formatRowNumbers formats 0-based row indices as a list of 1-based row numbers
+func formatRowNumbers(rows []int) string {
+	return "rows " + formatOneBased(rows)
+}
+
+// formatColumnNumbers formats 0-based column indices as a list of 1-based column numbers
+func formatColumnNumbers(cols []int) string {
+	return "columns " + formatOneBased(cols)
+}
+
+// formatOneBased joins 0-based indices as comma-separated 1-based numbers
+func formatOneBased(indices []int) string {
+	numbers := make([]string, len(indices))
+	for i, idx := range indices {
+		numbers[i] = strconv.Itoa(idx + 1)
+	}
+	return strings.Join(numbers, ", ")
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package core
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestFindNonFiniteValues(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	data := types.Matrix{
+		{1, 2, nan, 4},
+		{5, inf, 7, 8},
+		{9, 10, 11, 12},
+		{-inf, 14, 15, nan},
+	}
+
+	report := FindNonFiniteValues(data)
+	if report == nil {
+		t.Fatal("expected non-finite values to be found")
+	}
+	if report.NaNCount != 2 || report.InfCount != 2 {
+		t.Errorf("expected 2 NaN and 2 infinite values, got %d and %d", report.NaNCount, report.InfCount)
+	}
+
+	want := []struct{ row, col int }{{0, 2}, {1, 1}, {3, 0}, {3, 3}}
+	if len(report.Values) != len(want) {
+		t.Fatalf("expected %d locations, got %d", len(want), len(report.Values))
+	}
+	for i, w := range want {
+		got := report.Values[i]
+		if got.Row != w.row || got.Column != w.col {
+			t.Errorf("location %d: expected (%d, %d), got (%d, %d)", i, w.row, w.col, got.Row, got.Column)
+		}
+		if !math.IsNaN(got.Value) && !math.IsInf(got.Value, 0) {
+			t.Errorf("location %d: expected a non-finite value, got %g", i, got.Value)
+		}
+	}
+
+	msg := report.Error()
+	for _, part := range []string{"2 NaN and 2 infinite", "NaN at row 1, column 3", "+Inf at row 2, column 2",
+		"-Inf at row 4, column 1", "NaN at row 4, column 4"} {
+		if !strings.Contains(msg, part) {
+			t.Errorf("expected %q in error %q", part, msg)
+		}
+	}
+
+	if FindNonFiniteValues(types.Matrix{{1, 2}, {3, 4}}) != nil {
+		t.Error("expected no non-finite values in finite data")
+	}
+}
+
+func TestFindNonFiniteValuesExamples(t *testing.T) {
+	data := make(types.Matrix, 10)
+	for i := range data {
+		data[i] = []float64{math.Inf(1), 1}
+	}
+
+	msg := FindNonFiniteValues(data).Error()
+	if strings.Count(msg, "+Inf at") != maxNonFiniteExamples {
+		t.Errorf("expected %d examples in %q", maxNonFiniteExamples, msg)
+	}
+	if !strings.Contains(msg, "and 5 more") {
+		t.Errorf("expected the remaining count in %q", msg)
+	}
+}
+
+func TestValidateNaNValues(t *testing.T) {
+	withNaN := types.Matrix{{1, math.NaN()}, {3, 4}}
+	withInf := types.Matrix{{1, math.NaN()}, {math.Inf(-1), 4}}
+
+	if err := ValidateNaNValues(withNaN, true); err != nil {
+		t.Errorf("expected NaN to be allowed, got %v", err)
+	}
+	if err := ValidateNaNValues(withNaN, false); err == nil {
+		t.Error("expected an error for NaN")
+	}
+
+	// Infinite values are rejected even when NaN is allowed
+	err := ValidateNaNValues(withInf, true)
+	var nonFinite *NonFiniteValuesError
+	if !errors.As(err, &nonFinite) {
+		t.Fatalf("expected a *NonFiniteValuesError, got %v", err)
+	}
+	if nonFinite.InfCount != 1 || nonFinite.NaNCount != 0 || len(nonFinite.Values) != 1 {
+		t.Errorf("expected only the infinite value, got %+v", nonFinite)
+	}
+
+	// Fitting data with infinite values fails instead of returning garbage
+	config := types.PCAConfig{Components: 1, MeanCenter: true, Method: "svd"}
+	data := types.Matrix{{1, 2}, {3, math.Inf(1)}, {5, 7}}
+	if _, err := NewPCAEngine().Fit(data, config); err == nil || !strings.Contains(err.Error(), "infinite") {
+		t.Errorf("expected fit to report the infinite value, got %v", err)
+	}
+}
+
+func TestMaxInformativeComponents(t *testing.T) {
+	tests := []struct {
+		rows, cols, want int
+	}{
+		{150, 4, 4},
+		{5, 10, 4},
+		{10, 10, 9},
+		{1, 3, 1},
+	}
+	for _, tt := range tests {
+		if got := MaxInformativeComponents(tt.rows, tt.cols); got != tt.want {
+			t.Errorf("MaxInformativeComponents(%d, %d) = %d, want %d", tt.rows, tt.cols, got, tt.want)
+		}
+	}
+}
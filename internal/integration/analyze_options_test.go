@@ -0,0 +1,2169 @@
+package integration
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/internal/testdata"
+)
+
+// Tests checking golden files have Golden in their name, which make update-golden
+// selects
+func init() {
+	flag.BoolVar(&testdata.UpdateGolden, "update-golden", false, "regenerate the golden CLI output files")
+}
+
+// TestAnalyzeScaleWarning tests the scale mismatch warning of the analyze command
+func TestAnalyzeScaleWarning(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// Column "big" is 1000x larger than the others
+	data := [][]string{{"id", "a", "b", "big"}}
+	for i := 0; i < 12; i++ {
+		a := float64(i%5) + 0.1*float64(i)
+		b := float64((i*7)%11) * 0.5
+		big := 1000 * (float64((i*3)%7) + 0.2*float64(i))
+		data = append(data, []string{
+			fmt.Sprintf("s%d", i+1),
+			fmt.Sprintf("%g", a),
+			fmt.Sprintf("%g", b),
+			fmt.Sprintf("%g", big),
+		})
+	}
+	path := tc.CreateTestCSV(t, "scales.csv", data)
+
+	output, err := tc.RunCLI(t, "analyze", path)
+	AssertNoError(t, err, "Unscaled analysis failed")
+	AssertContains(t, output, "Warning: column variances differ", "unscaled analysis output")
+
+	output, err = tc.RunCLI(t, "analyze", "--scale", "standard", path)
+	AssertNoError(t, err, "Standardized analysis failed")
+	if strings.Contains(output, "Warning: column variances differ") {
+		t.Error("Scale warning should not be shown with --scale standard")
+	}
+
+	output, err = tc.RunCLI(t, "analyze", "--quiet", path)
+	AssertNoError(t, err, "Quiet analysis failed")
+	if strings.Contains(output, "Warning: column variances differ") {
+		t.Error("Scale warning should not be shown with --quiet")
+	}
+
+	output, err = tc.RunCLI(t, "analyze", "--scale-ratio-threshold", "1e9", path)
+	AssertNoError(t, err, "Analysis with high threshold failed")
+	if strings.Contains(output, "Warning: column variances differ") {
+		t.Error("Scale warning should respect --scale-ratio-threshold")
+	}
+
+	output, err = tc.RunCLI(t, "analyze", "--center-and-scale-report", "--quiet", path)
+	AssertNoError(t, err, "Analysis with scale report failed")
+	AssertContains(t, output, "Center and Scale Report", "scale report output")
+	AssertContains(t, output, "Max/min variance ratio", "scale report output")
+}
+
+// TestAnalyzeQuietFitWarnings tests that --quiet also silences the warnings of the fit
+func TestAnalyzeQuietFitWarnings(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// Column b is twice column a, so the data has rank 2
+	path := tc.CreateTestCSV(t, "collinear.csv", [][]string{
+		{"id", "a", "b", "c"},
+		{"s1", "1", "2", "3"},
+		{"s2", "2", "4", "1"},
+		{"s3", "3", "6", "4"},
+		{"s4", "4", "8", "1"},
+		{"s5", "5", "10", "5"},
+		{"s6", "6", "12", "9"},
+	})
+
+	output, err := tc.RunCLI(t, "analyze", "--scale", "standard", "--components", "3", path)
+	AssertNoError(t, err, "Rank-deficient analysis failed")
+	AssertContains(t, output, "Warning: data has numerical rank 2", "rank-deficient analysis output")
+
+	output, err = tc.RunCLI(t, "analyze", "--quiet", "--scale", "standard", "--components", "3", path)
+	AssertNoError(t, err, "Quiet rank-deficient analysis failed")
+	if strings.Contains(output, "Warning:") {
+		t.Errorf("Expected no warnings with --quiet, got:\n%s", output)
+	}
+}
+
+// readCSVRecords reads all records of a CSV output file
+func readCSVRecords(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	AssertNoError(t, err, "Failed to open "+filepath.Base(path))
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	AssertNoError(t, err, "Failed to parse "+filepath.Base(path))
+	return records
+}
+
+// TestAnalyzeTidyCSVExport tests that tidy loadings and scores match the wide matrices
+func TestAnalyzeTidyCSVExport(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	path := tc.CreateTestCSV(t, "tidy.csv", GenerateTestMatrix(15, 5, 3.0))
+	wideDir := filepath.Join(tc.TempDir, "wide")
+	tidyDir := filepath.Join(tc.TempDir, "tidy")
+
+	_, err := tc.RunCLI(t, "analyze", "-f", "csv", "-c", "3", "--scale", "standard",
+		"--output-dir", wideDir, path)
+	AssertNoError(t, err, "Wide CSV export failed")
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "-c", "3", "--scale", "standard",
+		"--loadings-format", "tidy", "--scores-format", "tidy", "--output-dir", tidyDir, path)
+	AssertNoError(t, err, "Tidy CSV export failed")
+
+	for _, tt := range []struct {
+		file    string
+		headers []string
+	}{
+		{"tidy_loadings.csv", []string{"variable", "component", "loading"}},
+		{"tidy_scores.csv", []string{"observation", "component", "score"}},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			wide := readCSVRecords(t, filepath.Join(wideDir, tt.file))
+			tidy := readCSVRecords(t, filepath.Join(tidyDir, tt.file))
+
+			components := wide[0][1:]
+			rows := wide[1:]
+			if len(components) != 3 {
+				t.Fatalf("Expected 3 components in wide output, got %d", len(components))
+			}
+			if strings.Join(tidy[0], ",") != strings.Join(tt.headers, ",") {
+				t.Errorf("Expected tidy headers %v, got %v", tt.headers, tidy[0])
+			}
+			if len(tidy)-1 != len(rows)*len(components) {
+				t.Fatalf("Expected %d tidy rows, got %d", len(rows)*len(components), len(tidy)-1)
+			}
+
+			wideValues := make(map[string]string)
+			for _, row := range rows {
+				for j, component := range components {
+					wideValues[row[0]+"/"+component] = row[j+1]
+				}
+			}
+			for _, record := range tidy[1:] {
+				key := record[0] + "/" + record[1]
+				if wideValues[key] != record[2] {
+					t.Errorf("%s: tidy value %s does not match wide value %s", key, record[2], wideValues[key])
+				}
+			}
+		})
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--loadings-format", "long", path)
+	AssertError(t, err, "Expected error for invalid --loadings-format")
+}
+
+// TestAnalyzeDropZeroVarianceRows tests that dropping all-identical rows keeps row names aligned
+func TestAnalyzeDropZeroVarianceRows(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	data := [][]string{
+		{"id", "a", "b", "c"},
+		{"s1", "1.0", "2.0", "3.5"},
+		{"s2", "2.5", "1.0", "4.0"},
+		{"flat", "7", "7", "7"},
+		{"s4", "3.0", "4.5", "2.0"},
+		{"s5", "4.0", "3.0", "5.5"},
+		{"s6", "0.5", "2.5", "1.0"},
+	}
+	path := tc.CreateTestCSV(t, "flat.csv", data)
+
+	output, err := tc.RunCLI(t, "analyze", path)
+	AssertNoError(t, err, "Analysis with zero-variance row failed")
+	AssertContains(t, output, "Warning: 1 row(s) have zero variance", "analysis output")
+	AssertContains(t, output, "flat", "zero-variance warning")
+
+	outputDir := filepath.Join(tc.TempDir, "dropped")
+	output, err = tc.RunCLI(t, "analyze", "--drop-zero-variance-rows", "-f", "csv",
+		"--output-dir", outputDir, path)
+	AssertNoError(t, err, "Analysis with --drop-zero-variance-rows failed")
+	if strings.Contains(output, "zero variance") {
+		t.Error("Zero-variance warning should not be shown when rows are dropped")
+	}
+
+	scores := readCSVRecords(t, filepath.Join(outputDir, "flat_scores.csv"))
+	var names []string
+	for _, record := range scores[1:] {
+		names = append(names, record[0])
+	}
+	if got := strings.Join(names, ","); got != "s1,s2,s4,s5,s6" {
+		t.Errorf("Expected score rows s1,s2,s4,s5,s6, got %s", got)
+	}
+
+	// A row equal to the column means is all zeros once centered, but not constant
+	centroid := tc.CreateTestCSV(t, "centroid.csv", [][]string{
+		{"id", "a", "b", "c"},
+		{"s1", "1", "2", "7"},
+		{"s2", "3", "6", "5"},
+		{"s3", "2", "4", "6"},
+		{"s4", "0", "5", "9"},
+		{"s5", "4", "3", "3"},
+	})
+	output, err = tc.RunCLI(t, "analyze", "--drop-zero-variance-rows", "-f", "csv",
+		"--output-dir", outputDir, centroid)
+	AssertNoError(t, err, "Analysis of data with a centroid row failed")
+	if strings.Contains(output, "zero variance") {
+		t.Error("A row equal to the column means should not be reported as zero-variance")
+	}
+	scores = readCSVRecords(t, filepath.Join(outputDir, "centroid_scores.csv"))
+	if len(scores) != 6 || scores[3][0] != "s3" {
+		t.Errorf("Expected score rows s1 to s5 with s3 kept, got %v", scores)
+	}
+}
+
+// TestAnalyzeSupplementaryGroups tests projecting category centroids as supplementary points
+func TestAnalyzeSupplementaryGroups(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	data := [][]string{
+		{"id", "a", "b", "c", "group"},
+		{"s1", "2.5", "2.4", "1.2", "x"},
+		{"s2", "0.5", "0.7", "0.3", "y"},
+		{"s3", "2.2", "2.9", "1.1", "x"},
+		{"s4", "1.9", "2.2", "0.8", "z"},
+		{"s5", "3.1", "3.0", "1.6", "x"},
+		{"s6", "2.0", "1.6", "1.3", "z"},
+		{"s7", "1.0", "1.1", "0.2", "y"},
+	}
+	path := tc.CreateTestCSV(t, "groups.csv", data)
+	outputDir := filepath.Join(tc.TempDir, "supplementary")
+
+	_, err := tc.RunCLI(t, "analyze", "-f", "json", "--scale", "standard",
+		"--supplementary-groups", "group", "--output-dir", outputDir, path)
+	AssertNoError(t, err, "Analysis with supplementary groups failed")
+
+	result := tc.LoadJSONResult(t, filepath.Join(outputDir, "groups_pca.json"))
+	groups, ok := result["supplementaryGroups"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Output has no supplementaryGroups")
+	}
+	categories := groups["categories"].([]interface{})
+	if len(categories) != 3 || categories[0] != "x" || categories[1] != "y" || categories[2] != "z" {
+		t.Errorf("Expected categories [x y z], got %v", categories)
+	}
+
+	// The centroid score of a group equals the mean score of its members
+	scores := toMatrix(result["results"].(map[string]interface{})["samples"].(map[string]interface{})["scores"])
+	centroids := toMatrix(groups["scores"])
+	members := [][]int{{0, 2, 4}, {1, 6}, {3, 5}}
+	for k, rows := range members {
+		for c := range centroids[k] {
+			mean := 0.0
+			for _, i := range rows {
+				mean += scores[i][c] / float64(len(rows))
+			}
+			if math.Abs(centroids[k][c]-mean) > 1e-9 {
+				t.Errorf("Group %v PC%d: expected %f, got %f", categories[k], c+1, mean, centroids[k][c])
+			}
+		}
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--supplementary-groups", "missing", path)
+	AssertError(t, err, "Expected error for unknown supplementary groups column")
+}
+
+// TestAnalyzeGroupSummary tests the table of mean scores per species for iris
+func TestAnalyzeGroupSummary(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "--group-summary", "species",
+		"--output-scores=false", irisPath)
+	AssertNoError(t, err, "Analysis with group summary failed")
+
+	start := strings.Index(output, "Mean Scores by Group (species):")
+	if start < 0 {
+		t.Fatalf("Group summary not found in output:\n%s", output)
+	}
+
+	// Rows after the header and two rule lines: category, N, PC1, PC2
+	pc1 := make(map[string]float64)
+	lines := strings.Split(strings.TrimSpace(output[start:]), "\n")
+	for _, line := range lines[4:] {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			break
+		}
+		if fields[1] != "50" {
+			t.Errorf("Expected 50 rows for %s, got %s", fields[0], fields[1])
+		}
+		v, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			t.Fatalf("Invalid PC1 mean in %q: %v", line, err)
+		}
+		pc1[fields[0]] = v
+	}
+	if len(pc1) != 3 {
+		t.Fatalf("Expected 3 group rows, got %v", pc1)
+	}
+	if math.Abs(pc1["setosa"]-pc1["virginica"]) < 1 {
+		t.Errorf("Expected setosa and virginica to separate on PC1, got %f and %f",
+			pc1["setosa"], pc1["virginica"])
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--group-summary", "missing", irisPath)
+	AssertError(t, err, "Expected error for unknown group summary column")
+}
+
+// TestAnalyzeLabelSanitization tests that labels with delimiters, line breaks and
+// duplicates give valid output with unique labels
+func TestAnalyzeLabelSanitization(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	data := [][]string{
+		{"id", "x,y", "x,y", "multi\nline", "d"},
+		{"s1", "2.5", "2.4", "1.2", "0.5"},
+		{"s, 2", "0.5", "0.7", "0.3", "0.1"},
+		{"s1", "2.2", "2.9", "1.1", "0.6"},
+		{"line\nbreak", "1.9", "2.2", "0.8", "0.9"},
+		{"s1", "3.1", "3.0", "1.6", "0.2"},
+	}
+	path := tc.CreateTestCSV(t, "labels.csv", data)
+	wantRows := []string{"s1", "s, 2", "s1_2", "line break", "s1_3"}
+	wantVariables := []string{"x,y", "x,y_2", "multi line", "d"}
+
+	assertLabels := func(t *testing.T, kind string, got, want []string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("Expected %s %q, got %q", kind, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s %d: expected %q, got %q", kind, i, want[i], got[i])
+			}
+		}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		outputDir := filepath.Join(tc.TempDir, "labels_csv")
+		output, err := tc.RunCLI(t, "analyze", "-f", "csv", "--output-all", "--output-dir", outputDir, path)
+		AssertNoError(t, err, "Analysis with messy labels failed")
+		AssertContains(t, output, "duplicate label(s) renamed", "Expected a warning about duplicates")
+
+		scores := readCSVRecords(t, filepath.Join(outputDir, "labels_scores.csv"))
+		var observations []string
+		for _, record := range scores[1:] {
+			observations = append(observations, record[0])
+		}
+		assertLabels(t, "observation", observations, wantRows)
+
+		loadings := readCSVRecords(t, filepath.Join(outputDir, "labels_loadings.csv"))
+		var variables []string
+		for _, record := range loadings[1:] {
+			variables = append(variables, record[0])
+		}
+		assertLabels(t, "variable", variables, wantVariables)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		outputDir := filepath.Join(tc.TempDir, "labels_json")
+		_, err := tc.RunCLI(t, "analyze", "-f", "json", "--output-dir", outputDir, path)
+		AssertNoError(t, err, "Analysis with messy labels failed")
+
+		result := tc.LoadJSONResult(t, filepath.Join(outputDir, "labels_pca.json"))
+		toStrings := func(v interface{}) []string {
+			var out []string
+			for _, s := range v.([]interface{}) {
+				out = append(out, s.(string))
+			}
+			return out
+		}
+		samples := result["results"].(map[string]interface{})["samples"].(map[string]interface{})
+		assertLabels(t, "sample name", toStrings(samples["names"]), wantRows)
+		model := result["model"].(map[string]interface{})
+		assertLabels(t, "feature label", toStrings(model["feature_labels"]), wantVariables)
+	})
+}
+
+// TestAnalyzeBatch tests analyzing a directory of CSV files with a malformed file
+func TestAnalyzeBatch(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	if err := os.MkdirAll(filepath.Join(tc.TempDir, "batch"), 0755); err != nil {
+		t.Fatalf("Failed to create batch directory: %v", err)
+	}
+	data := [][]string{
+		{"id", "a", "b", "c"},
+		{"s1", "1.0", "2.0", "3.5"},
+		{"s2", "2.5", "1.0", "4.0"},
+		{"s3", "3.0", "4.5", "2.0"},
+		{"s4", "4.0", "3.0", "5.5"},
+		{"s5", "0.5", "2.5", "1.0"},
+	}
+	tc.CreateTestCSV(t, "batch/first.csv", data)
+	tc.CreateTestCSV(t, "batch/second.csv", data)
+	// Header only, no data rows
+	tc.CreateTestCSV(t, "batch/malformed.csv", data[:1])
+
+	outputDir := filepath.Join(tc.TempDir, "results")
+	_, err := tc.RunCLI(t, "analyze", "--batch", "--jobs", "2", "--output-dir", outputDir,
+		filepath.Join(tc.TempDir, "batch"))
+	AssertError(t, err, "Batch with a malformed file should report a failure")
+	AssertContains(t, err.Error(), "1 of 3 files failed: malformed.csv", "batch error")
+
+	// The failure does not stop the other files from being analyzed
+	for _, name := range []string{"first_pca.json", "second_pca.json"} {
+		CheckFileExists(t, filepath.Join(outputDir, name))
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "malformed_pca.json")); err == nil {
+		t.Error("No output expected for the malformed file")
+	}
+}
+
+// TestAnalyzeLoadingsThresholdGolden compares the thresholded loadings table for iris
+// with a golden file. Run with -update to regenerate the golden file.
+func TestAnalyzeLoadingsThresholdGolden(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "--loadings-threshold", "0.3",
+		"--output-scores=false", "--output-variance=false", irisPath)
+	AssertNoError(t, err, "Analysis with loadings threshold failed")
+
+	start := strings.Index(output, "PCA Loadings:")
+	if start < 0 {
+		t.Fatalf("Loadings table not found in output:\n%s", output)
+	}
+	// Trailing spaces from blanked loadings are not significant
+	lines := strings.Split(strings.TrimSpace(output[start:]), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	got := strings.Join(lines, "\n") + "\n"
+
+	goldenPath := filepath.Join("testdata", "iris_loadings_threshold_0.3.golden")
+	if testdata.UpdateGolden {
+		if err := os.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Thresholded loadings table does not match %s\ngot:\n%s\nwant:\n%s", goldenPath, got, want)
+	}
+}
+
+// TestAnalyzePerGroup tests fitting a separate PCA model for each category
+func TestAnalyzePerGroup(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	data := [][]string{
+		{"id", "a", "b", "c", "batch"},
+		{"a1", "1.0", "2.0", "3.5", "A"},
+		{"b1", "9.5", "1.0", "4.0", "B"},
+		{"a2", "2.5", "1.0", "4.0", "A"},
+		{"b2", "8.0", "3.5", "2.5", "B"},
+		{"a3", "3.0", "4.5", "2.0", "A"},
+		{"b3", "7.5", "2.0", "5.5", "B"},
+		{"a4", "4.0", "3.0", "5.5", "A"},
+		{"b4", "9.0", "4.0", "1.0", "B"},
+		{"c1", "5.0", "5.0", "5.0", "C"},
+	}
+	path := tc.CreateTestCSV(t, "batches.csv", data)
+
+	outputDir := filepath.Join(tc.TempDir, "groups")
+	output, err := tc.RunCLI(t, "analyze", "--per-group", "batch", "-f", "csv",
+		"--output-dir", outputDir, path)
+	AssertNoError(t, err, "Per-group analysis failed")
+	AssertContains(t, output, "Skipping group C", "per-group output")
+	AssertContains(t, output, "Per-Group Summary (batch)", "per-group output")
+
+	for group, want := range map[string]string{"A": "a1,a2,a3,a4", "B": "b1,b2,b3,b4"} {
+		scores := readCSVRecords(t, filepath.Join(outputDir, "batches_"+group+"_scores.csv"))
+		var names []string
+		for _, record := range scores[1:] {
+			names = append(names, record[0])
+		}
+		if got := strings.Join(names, ","); got != want {
+			t.Errorf("Group %s: expected score rows %s, got %s", group, want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "batches_C_scores.csv")); err == nil {
+		t.Error("No output expected for the skipped group")
+	}
+}
+
+// TestAnalyzeTSV tests that .tsv input is read as tab-separated and --tsv writes
+// tab-separated output, quoting fields that contain tabs
+func TestAnalyzeTSV(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	input := filepath.Join(tc.TempDir, "samples.tsv")
+	content := "\t\"len\tmm\"\twidth\theight\tgroup\n" +
+		"s1\t1.0\t2.1\t0.5\t\"a\tb\"\n" +
+		"s2\t2.0\t\t1.4\tc\n" +
+		"s3\t3.1\t6.2\t1.6\tc\n" +
+		"s4\t4.0\t8.1\t2.3\t\"a\tb\"\n" +
+		"s5\t5.2\t9.8\t2.4\tc\n"
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "out")
+
+	_, err := tc.RunCLI(t, "analyze", "--tsv", "-c", "2", "--missing-strategy", "mean",
+		"--output-dir", outDir, input)
+	AssertNoError(t, err, "TSV analysis failed")
+
+	file, err := os.Open(filepath.Join(outDir, "samples_loadings.tsv"))
+	AssertNoError(t, err, "Failed to open TSV loadings")
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.Comma = '\t'
+	records, err := reader.ReadAll()
+	AssertNoError(t, err, "Failed to parse TSV loadings")
+
+	if strings.Join(records[0], ",") != "variable,PC1,PC2" {
+		t.Errorf("Unexpected loadings header: %v", records[0])
+	}
+	if len(records) != 4 || records[1][0] != "len\tmm" {
+		t.Errorf("Expected 3 numeric variables starting with a tab-containing name, got %q", records)
+	}
+	CheckFileExists(t, filepath.Join(outDir, "samples_scores.tsv"))
+
+	_, err = tc.RunCLI(t, "analyze", "--tsv", "-f", "json", input)
+	AssertError(t, err, "Expected error for --tsv with --format json")
+}
+
+// TestAnalyzeImputeReport tests that --impute-report lists every imputed cell, and
+// the cells that caused rows to be dropped with --missing-strategy drop
+func TestAnalyzeImputeReport(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	input := tc.CreateTestCSV(t, "missing.csv", [][]string{
+		{"", "a", "b", "c"},
+		{"r1", "1.0", "NA", "3.0"},
+		{"r2", "4.0", "5.0", ""},
+		{"r3", "7.0", "", "NA"},
+		{"r4", "2.0", "3.0", "5.0"},
+		{"r5", "6.0", "1.0", "2.0"},
+		{"r6", "3.0", "4.0", "8.0"},
+	})
+	report := filepath.Join(tc.TempDir, "imputed.csv")
+
+	_, err := tc.RunCLI(t, "analyze", "--missing-strategy", "mean", "--impute-report", report, input)
+	AssertNoError(t, err, "Analysis with impute report failed")
+
+	records := readCSVRecords(t, report)
+	if strings.Join(records[0], ",") != "row,column,original,imputed,method" {
+		t.Errorf("Unexpected report header: %v", records[0])
+	}
+	if len(records)-1 != 4 {
+		t.Fatalf("Expected 4 imputed cells, got %d: %v", len(records)-1, records)
+	}
+	// Mean of column b without missing values: (5 + 3 + 1 + 4) / 4
+	if strings.Join(records[1], ",") != "r1,b,NaN,3.25,mean" {
+		t.Errorf("Unexpected first report row: %v", records[1])
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--missing-strategy", "drop", "--impute-report", report, input)
+	AssertNoError(t, err, "Analysis with drop report failed")
+
+	records = readCSVRecords(t, report)
+	rows := make(map[string]int)
+	for _, record := range records[1:] {
+		if record[3] != "" || record[4] != "drop" {
+			t.Errorf("Unexpected drop report row: %v", record)
+		}
+		rows[record[0]]++
+	}
+	if len(rows) != 3 || rows["r3"] != 2 {
+		t.Errorf("Expected rows r1, r2 and r3 (twice) in drop report, got %v", rows)
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--impute-report", report, input)
+	AssertError(t, err, "Expected error for --impute-report without an imputing strategy")
+}
+
+// TestAnalyzeImputeGroupBy tests that --impute-group-by imputes with the mean of the
+// row's group, and the mean of all rows for a group without observed values
+func TestAnalyzeImputeGroupBy(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	input := tc.CreateTestCSV(t, "groups.csv", [][]string{
+		{"", "group", "a", "b"},
+		{"r1", "x", "1.0", "NA"},
+		{"r2", "x", "2.0", "2.0"},
+		{"r3", "x", "3.0", "4.0"},
+		{"r4", "y", "4.0", "NA"},
+		{"r5", "y", "5.0", "20.0"},
+		{"r6", "y", "6.0", "30.0"},
+		{"r7", "z", "7.0", "NA"},
+	})
+	report := filepath.Join(tc.TempDir, "imputed.csv")
+
+	_, err := tc.RunCLI(t, "analyze", "--missing-strategy", "mean", "--impute-group-by", "group",
+		"--impute-report", report, input)
+	AssertNoError(t, err, "Analysis with group-wise imputation failed")
+
+	records := readCSVRecords(t, report)
+	// Means of b in group x (3), group y (25) and over all rows for group z (14)
+	want := []string{"r1,b,NaN,3,mean", "r4,b,NaN,25,mean", "r7,b,NaN,14,mean"}
+	if len(records)-1 != len(want) {
+		t.Fatalf("Expected %d imputed cells, got %d: %v", len(want), len(records)-1, records)
+	}
+	for i, w := range want {
+		if got := strings.Join(records[i+1], ","); got != w {
+			t.Errorf("Expected report row %q, got %q", w, got)
+		}
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--missing-strategy", "mean", "--impute-group-by", "a", input)
+	AssertError(t, err, "Expected error for a numeric group column")
+
+	_, err = tc.RunCLI(t, "analyze", "--missing-strategy", "zero", "--impute-group-by", "group", input)
+	AssertError(t, err, "Expected error for --impute-group-by with the zero strategy")
+}
+
+// significantDigits counts the significant digits of a formatted number
+func significantDigits(s string) int {
+	s = strings.TrimLeft(s, "-")
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimLeft(strings.Replace(s, ".", "", 1), "0")
+	return len(s)
+}
+
+// TestAnalyzePrecision tests that --precision limits the significant digits of
+// CSV and JSON exports
+func TestAnalyzePrecision(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "out")
+
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--precision", "6", "--output-dir", outDir, irisPath)
+	AssertNoError(t, err, "CSV analysis with precision failed")
+
+	for _, suffix := range []string{"_scores.csv", "_loadings.csv", "_variance.csv"} {
+		records := readCSVRecords(t, filepath.Join(outDir, "iris"+suffix))
+		for _, record := range records[1:] {
+			for _, field := range record[1:] {
+				if significantDigits(field) > 6 {
+					t.Errorf("%s: %q has more than 6 significant digits", suffix, field)
+				}
+			}
+		}
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "-f", "json", "--precision", "6", "--output-dir", outDir, irisPath)
+	AssertNoError(t, err, "JSON analysis with precision failed")
+
+	file, err := os.Open(filepath.Join(outDir, "iris_pca.json"))
+	AssertNoError(t, err, "Failed to open JSON output")
+	defer file.Close()
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	var output struct {
+		Model struct {
+			Loadings [][]json.Number `json:"loadings"`
+		} `json:"model"`
+		Results struct {
+			Samples struct {
+				Scores [][]json.Number `json:"scores"`
+			} `json:"samples"`
+		} `json:"results"`
+	}
+	AssertNoError(t, decoder.Decode(&output), "Failed to parse JSON output")
+
+	checked := 0
+	for _, matrix := range [][][]json.Number{output.Model.Loadings, output.Results.Samples.Scores} {
+		for _, row := range matrix {
+			for _, v := range row {
+				if significantDigits(v.String()) > 6 {
+					t.Errorf("JSON value %s has more than 6 significant digits", v)
+				}
+				checked++
+			}
+		}
+	}
+	if checked == 0 {
+		t.Error("No scores or loadings found in JSON output")
+	}
+}
+
+// TestAnalyzeNoColor verifies that no ANSI escape codes reach output that is not a
+// terminal, with or without --no-color, and that both outputs are identical
+func TestAnalyzeNoColor(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	args := []string{"analyze", "--include-metrics", "--output-all", irisPath}
+	piped, err := tc.RunCLI(t, args...)
+	AssertNoError(t, err, "Analysis with piped output failed")
+	noColor, err := tc.RunCLI(t, append([]string{"--no-color"}, args...)...)
+	AssertNoError(t, err, "Analysis with --no-color failed")
+
+	for name, output := range map[string]string{"piped": piped, "--no-color": noColor} {
+		if strings.Contains(output, "\x1b[") {
+			t.Errorf("%s output contains ANSI escape codes", name)
+		}
+	}
+	if piped != noColor {
+		t.Error("Output with --no-color differs from piped output")
+	}
+}
+
+// TestAnalyzeComponentAdvice verifies that --component-advice lists every heuristic
+// with a recommendation in range, without changing the components used
+func TestAnalyzeComponentAdvice(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "--component-advice", "--scale", "standard",
+		"--components", "3", "--output-variance", "--output-scores=false", irisPath)
+	AssertNoError(t, err, "Analysis with component advice failed")
+
+	methods := []string{"Kaiser", "Broken stick", "Parallel analysis", "Elbow",
+		"Cumulative variance 80%", "Cumulative variance 90%", "Cumulative variance 95%"}
+	for _, method := range methods {
+		idx := strings.Index(output, "\n"+method+" ")
+		if idx < 0 {
+			t.Errorf("No advice row for %s in output:\n%s", method, output)
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(output[idx+1+len(method):], " "))
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 1 || n > 4 {
+			t.Errorf("%s: expected a recommendation between 1 and 4, got %q", method, fields[0])
+		}
+	}
+	AssertContains(t, output, "Components used: 3", "Advice should report the components used")
+
+	// The analysis itself still uses the requested number of components
+	AssertContains(t, output, "PC3", "Analysis should keep --components 3")
+}
+
+// TestAnalyzeThousandsSeparator tests that grouped numbers give the same analysis as plain ones
+func TestAnalyzeThousandsSeparator(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	plain := [][]string{
+		{"id", "a", "b", "c"},
+		{"r1", "1234.5", "2.1", "3"},
+		{"r2", "2345.1", "3.3", "1"},
+		{"r3", "3001", "1", "2"},
+		{"r4", "4100.2", "0.5", "5"},
+	}
+	grouped := [][]string{
+		{"id", "a", "b", "c"},
+		{"r1", "1,234.5", "2.1", "3"},
+		{"r2", "2,345.1", "3.3", "1"},
+		{"r3", "3,001", "1", "2"},
+		{"r4", "4,100.2", "0.5", "5"},
+	}
+	plainPath := tc.CreateTestCSV(t, "plain.csv", plain)
+	groupedPath := tc.CreateTestCSV(t, "grouped.csv", grouped)
+
+	want, err := tc.RunCLI(t, "analyze", "--scale", "standard", plainPath)
+	AssertNoError(t, err, "Analysis of plain numbers failed")
+	got, err := tc.RunCLI(t, "analyze", "--scale", "standard", "--thousands-sep", "comma", groupedPath)
+	AssertNoError(t, err, "Analysis with --thousands-sep failed")
+	if got != want {
+		t.Errorf("Grouped numbers gave different output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestAnalyzeCheckLoadings tests the orthonormality check of loadings and scores
+func TestAnalyzeCheckLoadings(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	for _, method := range []string{"svd", "nipals", "kernel"} {
+		output, err := tc.RunCLI(t, "analyze", "--check-loadings", "--method", method,
+			"--output-scores=false", irisPath)
+		AssertNoError(t, err, "Analysis with --check-loadings failed for "+method)
+		AssertContains(t, output, "Tolerance 1e-06: passed", "Orthonormality check for "+method)
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--check-loadings", "--check-tolerance", "0", irisPath)
+	if err == nil {
+		t.Error("Expected an error for a zero tolerance")
+	}
+}
+
+// TestAnalyzeVarianceTarget tests choosing the number of components from a cumulative variance target
+func TestAnalyzeVarianceTarget(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	// Standardized iris: PC1 and PC2 explain about 73% and 23% of the variance
+	tests := []struct {
+		target     string
+		components string
+	}{
+		{"0.95", "Components selected: 2"},
+		{"0.99", "Components selected: 3"},
+		{"1", "Components selected: 4"},
+	}
+	for _, tt := range tests {
+		output, err := tc.RunCLI(t, "analyze", "--scale", "standard", "--components", "1",
+			"--variance-target", tt.target, "--output-scores=false", irisPath)
+		AssertNoError(t, err, "Analysis with --variance-target "+tt.target+" failed")
+		AssertContains(t, output, tt.components, "Selected components for target "+tt.target)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "--scale", "standard", "--variance-target", "0.95",
+		"--output-scores=false", irisPath)
+	AssertNoError(t, err, "Analysis with --variance-target failed")
+	AssertContains(t, output, "in 2 components", "Explained variance summary")
+
+	// Wide data: 6 centered rows carry at most 5 components, so none is discarded for rank
+	wide := [][]string{{"id", "v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8", "v9", "v10"}}
+	for i := 0; i < 6; i++ {
+		row := []string{fmt.Sprintf("r%d", i+1)}
+		for j := 0; j < 10; j++ {
+			row = append(row, strconv.Itoa((i*37+j*101+i*j*j*13)%97))
+		}
+		wide = append(wide, row)
+	}
+	widePath := tc.CreateTestCSV(t, "wide.csv", wide)
+	for _, method := range []string{"svd", "kernel"} {
+		output, err = tc.RunCLI(t, "analyze", "--method", method, "--variance-target", "0.99",
+			"--output-scores=false", widePath)
+		AssertNoError(t, err, "Analysis of wide data with --variance-target failed")
+		AssertContains(t, output, "Components selected: 5", "Selected components for wide data")
+		if strings.Contains(output, "Warning") {
+			t.Errorf("Expected no warning for wide data with --method %s, got:\n%s", method, output)
+		}
+	}
+
+	for _, target := range []string{"1.5", "-0.5"} {
+		if _, err := tc.RunCLI(t, "analyze", "--variance-target", target, irisPath); err == nil {
+			t.Errorf("Expected an error for --variance-target %s", target)
+		}
+	}
+}
+
+// TestAnalyzeDropMissingColumns tests dropping mostly missing columns before imputation
+func TestAnalyzeDropMissingColumns(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// Column b is 60% missing and column c 10% missing
+	data := [][]string{{"id", "a", "b", "c", "d"}}
+	values := []string{"1.2", "0.4", "2.8", "1.9", "3.3", "0.7", "2.1", "1.5", "2.6", "0.9"}
+	for i, v := range values {
+		b := v
+		if i < 6 {
+			b = ""
+		}
+		c := values[(i+3)%len(values)]
+		if i == 3 {
+			c = ""
+		}
+		d := values[(i+7)%len(values)]
+		data = append(data, []string{fmt.Sprintf("s%d", i+1), v, b, c, d})
+	}
+	csvPath := tc.CreateTestCSV(t, "drop_missing.csv", data)
+	outputDir := filepath.Join(tc.TempDir, "drop_missing")
+
+	output, err := tc.RunCLI(t, "analyze", "--drop-missing-cols", "0.5", "--missing-strategy", "mean",
+		"--format", "json", "--output-dir", outputDir, csvPath)
+	AssertNoError(t, err, "Analysis with --drop-missing-cols failed")
+	AssertContains(t, output, "Dropped 1 column(s) with more than 50% missing values: b", "Dropped columns report")
+
+	modelPath := filepath.Join(outputDir, "drop_missing_pca.json")
+	modelData, err := os.ReadFile(modelPath)
+	AssertNoError(t, err, "Failed to read model")
+	var model struct {
+		Metadata struct {
+			Config struct {
+				DroppedColumns []string `json:"dropped_columns"`
+			} `json:"config"`
+		} `json:"metadata"`
+		Model struct {
+			FeatureLabels []string `json:"feature_labels"`
+		} `json:"model"`
+	}
+	AssertNoError(t, json.Unmarshal(modelData, &model), "Failed to parse model")
+
+	if got := strings.Join(model.Metadata.Config.DroppedColumns, ","); got != "b" {
+		t.Errorf("Expected dropped columns [b] in the model, got %v", model.Metadata.Config.DroppedColumns)
+	}
+	if got := strings.Join(model.Model.FeatureLabels, ","); got != "a,c,d" {
+		t.Errorf("Expected features [a c d], got %v", model.Model.FeatureLabels)
+	}
+
+	// The model ignores the dropped column when transforming data that still has it
+	_, err = tc.RunCLI(t, "transform", modelPath, tc.CreateTestCSV(t, "drop_missing_new.csv", [][]string{
+		{"id", "a", "b", "c", "d"},
+		{"n1", "1.0", "", "2.0", "3.0"},
+	}))
+	AssertNoError(t, err, "transform with a model fitted after dropping columns failed")
+
+	if _, err := tc.RunCLI(t, "analyze", "--drop-missing-cols", "1.5", csvPath); err == nil {
+		t.Error("Expected an error for a threshold above 1")
+	}
+}
+
+// TestAnalyzeCenterOnly tests that --center-only centers the data without scaling it,
+// and that it conflicts with the other centering and scaling options
+func TestAnalyzeCenterOnly(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// Columns on very different scales, so that scaling would change the variances
+	data := [][]string{{"id", "a", "b", "c"}}
+	columns := [][]float64{
+		{1, 2, 3, 4, 5, 6, 7, 9},
+		{100, 300, 200, 500, 400, 800, 600, 700},
+		{0.5, 0.1, 0.4, 0.2, 0.9, 0.3, 0.8, 0.6},
+	}
+	for i := range columns[0] {
+		data = append(data, []string{fmt.Sprintf("s%d", i+1),
+			fmt.Sprint(columns[0][i]), fmt.Sprint(columns[1][i]), fmt.Sprint(columns[2][i])})
+	}
+	csvPath := tc.CreateTestCSV(t, "center_only.csv", data)
+	outputDir := filepath.Join(tc.TempDir, "center_only")
+
+	_, err := tc.RunCLI(t, "analyze", "--center-only", "--components", "3", "--format", "json",
+		"--output-dir", outputDir, csvPath)
+	AssertNoError(t, err, "Analysis with --center-only failed")
+
+	modelData, err := os.ReadFile(filepath.Join(outputDir, "center_only_pca.json"))
+	AssertNoError(t, err, "Failed to read model")
+	var model struct {
+		Preprocessing struct {
+			MeanCenter    bool `json:"mean_center"`
+			StandardScale bool `json:"standard_scale"`
+			RobustScale   bool `json:"robust_scale"`
+			ScaleOnly     bool `json:"scale_only"`
+			Parameters    struct {
+				FeatureMeans []float64 `json:"feature_means"`
+			} `json:"parameters"`
+		} `json:"preprocessing"`
+		Model struct {
+			ExplainedVariance []float64 `json:"explained_variance"`
+		} `json:"model"`
+	}
+	AssertNoError(t, json.Unmarshal(modelData, &model), "Failed to parse model")
+
+	pre := model.Preprocessing
+	if !pre.MeanCenter || pre.StandardScale || pre.RobustScale || pre.ScaleOnly {
+		t.Errorf("Expected centering without scaling, got %+v", pre)
+	}
+	// The eigenvalues of unscaled data add up to the sum of the column variances
+	totalVariance, totalEigenvalues := 0.0, 0.0
+	for j, column := range columns {
+		mean := 0.0
+		for _, v := range column {
+			mean += v / float64(len(column))
+		}
+		if j >= len(pre.Parameters.FeatureMeans) || math.Abs(pre.Parameters.FeatureMeans[j]-mean) > 1e-9 {
+			t.Errorf("Expected feature mean %g for column %d, got %v", mean, j+1, pre.Parameters.FeatureMeans)
+		}
+		for _, v := range column {
+			totalVariance += (v - mean) * (v - mean) / float64(len(column)-1)
+		}
+	}
+	for _, v := range model.Model.ExplainedVariance {
+		totalEigenvalues += v
+	}
+	if math.Abs(totalEigenvalues-totalVariance) > 1e-6*totalVariance {
+		t.Errorf("Expected eigenvalues adding up to the unscaled variance %g, got %g", totalVariance, totalEigenvalues)
+	}
+
+	for _, conflict := range [][]string{{"--scale-only"}, {"--no-mean-centering"}, {"--scale", "standard"}} {
+		args := append([]string{"analyze", "--center-only"}, conflict...)
+		_, err := tc.RunCLI(t, append(args, csvPath)...)
+		AssertError(t, err, "Expected error for --center-only with "+strings.Join(conflict, " "))
+	}
+}
+
+// TestAnalyzeJSONCompactAndScoresNDJSON tests compact JSON output and streaming scores as NDJSON
+func TestAnalyzeJSONCompactAndScoresNDJSON(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	datasets := tc.CreateSampleDatasets(t)
+	inputPath := datasets["small"].Path
+	outputDir := filepath.Join(tc.TempDir, "ndjson")
+	ndjsonPath := filepath.Join(tc.TempDir, "scores.ndjson")
+
+	output, err := tc.RunCLI(t, "analyze", "--format", "json", "--json-compact", "--components", "2",
+		"--scores-ndjson", ndjsonPath, "--output-dir", outputDir, inputPath)
+	AssertNoError(t, err, "Analysis with --json-compact and --scores-ndjson failed")
+	AssertContains(t, output, "Scores saved to: "+ndjsonPath, "NDJSON output message")
+
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	modelData, err := os.ReadFile(filepath.Join(outputDir, base+"_pca.json"))
+	AssertNoError(t, err, "Failed to read model")
+	if strings.Contains(strings.TrimSpace(string(modelData)), "\n") {
+		t.Error("Expected compact JSON without newlines")
+	}
+	var model struct {
+		Results struct {
+			Samples struct {
+				Names  []string    `json:"names"`
+				Scores [][]float64 `json:"scores"`
+			} `json:"samples"`
+		} `json:"results"`
+	}
+	AssertNoError(t, json.Unmarshal(modelData, &model), "Failed to parse model")
+	if len(model.Results.Samples.Scores) != 0 {
+		t.Errorf("Expected the model to omit scores, got %d rows", len(model.Results.Samples.Scores))
+	}
+
+	ndjsonData, err := os.ReadFile(ndjsonPath)
+	AssertNoError(t, err, "Failed to read NDJSON scores")
+	lines := strings.Split(strings.TrimSuffix(string(ndjsonData), "\n"), "\n")
+	if len(lines) != len(model.Results.Samples.Names) {
+		t.Fatalf("Expected %d NDJSON lines, got %d", len(model.Results.Samples.Names), len(lines))
+	}
+	for i, line := range lines {
+		var record struct {
+			Name   string    `json:"name"`
+			Scores []float64 `json:"scores"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %v", i+1, err)
+		}
+		if record.Name != model.Results.Samples.Names[i] {
+			t.Errorf("Line %d: expected name %q, got %q", i+1, model.Results.Samples.Names[i], record.Name)
+		}
+		if len(record.Scores) != 2 {
+			t.Errorf("Line %d: expected 2 scores, got %d", i+1, len(record.Scores))
+		}
+	}
+
+	if _, err := tc.RunCLI(t, "analyze", "--batch", "--scores-ndjson", ndjsonPath, inputPath); err == nil {
+		t.Error("Expected an error when combining --scores-ndjson with --batch")
+	}
+}
+
+// TestAnalyzeOutputTemplate tests creating the output directory and naming output
+// files after --output-template
+func TestAnalyzeOutputTemplate(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	datasets := tc.CreateSampleDatasets(t)
+	inputPath := datasets["small"].Path
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	outputDir := filepath.Join(tc.TempDir, "missing", "nested")
+
+	_, err := tc.RunCLI(t, "analyze", "--format", "json", "--output-dir", outputDir, inputPath)
+	AssertNoError(t, err, "Analysis into a non-existent output directory failed")
+	CheckFileExists(t, filepath.Join(outputDir, base+"_pca.json"))
+
+	_, err = tc.RunCLI(t, "analyze", "--format", "csv", "--method", "nipals", "--components", "2",
+		"--output-template", "{base}_{method}_{components}pc", "--output-dir", outputDir, inputPath)
+	AssertNoError(t, err, "Analysis with --output-template failed")
+	CheckFileExists(t, filepath.Join(outputDir, base+"_nipals_2pc_scores.csv"))
+	CheckFileExists(t, filepath.Join(outputDir, base+"_nipals_2pc_loadings.csv"))
+
+	for _, template := range []string{"{base}_{scale}", "../{base}", "{base}/sub"} {
+		if _, err := tc.RunCLI(t, "analyze", "--output-template", template, inputPath); err == nil {
+			t.Errorf("Expected an error for --output-template %q", template)
+		}
+	}
+}
+
+// TestAnalyzeCleanedCache tests saving cleaned data with --cache-cleaned and reusing
+// it with --use-cleaned until the input or the cleaning options change
+func TestAnalyzeCleanedCache(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	csvPath := tc.CreateTestCSV(t, "cache.csv", [][]string{
+		{"id", "a", "b", "c"},
+		{"s1", "1.2", "2.1", "0.5"},
+		{"s2", "2.4", "", "1.1"},
+		{"s3", "3.1", "1.7", "2.6"},
+		{"s4", "4.8", "3.9", ""},
+		{"s5", "5.5", "4.2", "3.3"},
+		{"s6", "6.1", "5.8", "2.9"},
+	})
+	cachePath := filepath.Join(tc.TempDir, "cleaned.json")
+
+	fresh, err := tc.RunCLI(t, "analyze", "--missing-strategy", "mean", "--cache-cleaned", cachePath,
+		"--output-variance", csvPath)
+	AssertNoError(t, err, "Analysis with --cache-cleaned failed")
+	AssertContains(t, fresh, "Cleaned data saved to: "+cachePath, "Cache message")
+	CheckFileExists(t, cachePath)
+
+	cached, err := tc.RunCLI(t, "analyze", "--missing-strategy", "mean", "--use-cleaned", cachePath,
+		"--output-variance", csvPath)
+	AssertNoError(t, err, "Analysis with --use-cleaned failed")
+	AssertContains(t, cached, "Using cleaned data from "+cachePath, "Cache reuse message")
+
+	// The cached run gives the same results as the fresh run
+	varianceTable := func(output string) string {
+		if i := strings.Index(output, "Explained Variance"); i >= 0 {
+			return output[i:]
+		}
+		return ""
+	}
+	if varianceTable(fresh) == "" || varianceTable(cached) != varianceTable(fresh) {
+		t.Errorf("Expected the same explained variance with cached data\nfresh:\n%s\ncached:\n%s", fresh, cached)
+	}
+
+	// A different missing value strategy invalidates the cache
+	_, err = tc.RunCLI(t, "analyze", "--missing-strategy", "median", "--use-cleaned", cachePath, csvPath)
+	if err == nil {
+		t.Error("Expected an error when the cleaning options changed")
+	}
+
+	// So does a changed input file
+	data, err := os.ReadFile(csvPath)
+	AssertNoError(t, err, "Failed to read input")
+	AssertNoError(t, os.WriteFile(csvPath, append(data, []byte("s7,7.0,6.1,4.4\n")...), 0644), "Failed to modify input")
+	_, err = tc.RunCLI(t, "analyze", "--missing-strategy", "mean", "--use-cleaned", cachePath, csvPath)
+	if err == nil {
+		t.Error("Expected an error when the input file changed")
+	}
+}
+
+// TestAnalyzeDelimiterDecimalConflict tests that a comma delimiter with decimal commas
+// is rejected, while semicolon-delimited data with decimal commas is accepted
+func TestAnalyzeDelimiterDecimalConflict(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	plainPath := tc.CreateTestCSV(t, "dot.csv", [][]string{
+		{"id", "a", "b", "c"},
+		{"r1", "1.5", "2.1", "3"},
+		{"r2", "2.25", "3.3", "1"},
+		{"r3", "3", "1", "2"},
+		{"r4", "4.75", "0.5", "5"},
+	})
+	europeanPath := filepath.Join(tc.TempDir, "comma.csv")
+	european := "id;a;b;c\nr1;1,5;2,1;3\nr2;2,25;3,3;1\nr3;3;1;2\nr4;4,75;0,5;5\n"
+	AssertNoError(t, os.WriteFile(europeanPath, []byte(european), 0644), "Failed to write test data")
+
+	_, err := tc.RunCLI(t, "analyze", "--delimiter", ",", "--decimal-separator", "comma", europeanPath)
+	if err == nil {
+		t.Fatal("Expected an error for a comma delimiter with decimal commas")
+	}
+	AssertContains(t, err.Error(), "use ';' as the delimiter", "Conflict message")
+
+	want, err := tc.RunCLI(t, "analyze", plainPath)
+	AssertNoError(t, err, "Analysis of decimal points failed")
+	got, err := tc.RunCLI(t, "analyze", "--delimiter", ";", "--decimal-separator", "comma", europeanPath)
+	AssertNoError(t, err, "Analysis of semicolon-delimited decimal commas failed")
+	if got != want {
+		t.Errorf("Decimal commas gave different output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestAnalyzeDropDuplicates tests removing identical rows before PCA
+func TestAnalyzeDropDuplicates(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	path := tc.CreateTestCSV(t, "dups.csv", [][]string{
+		{"id", "a", "b", "c"},
+		{"r1", "1", "2", "3"},
+		{"r2", "2", "3", "1"},
+		{"r3", "1", "2", "3"},
+		{"r4", "3", "1", "2"},
+		{"r5", "1", "2", "3"},
+		{"r6", "4", "0.5", "5"},
+	})
+
+	output, err := tc.RunCLI(t, "analyze", "--drop-duplicates", "-f", "json", "-o", tc.TempDir, path)
+	AssertNoError(t, err, "analyze --drop-duplicates failed")
+	AssertContains(t, output, "dropped 2 duplicate row(s) (drop-first): r3, r5", "Duplicate warning")
+
+	jsonData, err := os.ReadFile(filepath.Join(tc.TempDir, "dups_pca.json"))
+	AssertNoError(t, err, "Failed to read JSON output")
+	var result struct {
+		Results struct {
+			Samples struct {
+				Names []string `json:"names"`
+			} `json:"samples"`
+		} `json:"results"`
+	}
+	AssertNoError(t, json.Unmarshal(jsonData, &result), "Failed to parse JSON output")
+	if got := strings.Join(result.Results.Samples.Names, ","); got != "r1,r2,r4,r6" {
+		t.Errorf("Expected samples r1,r2,r4,r6, got %s", got)
+	}
+
+	if _, err := tc.RunCLI(t, "analyze", "--drop-duplicates=first", path); err == nil {
+		t.Error("Expected an error for an invalid duplicate policy")
+	}
+}
+
+// TestAnalyzeStdin tests piping CSV into analyze with "-" as the input
+func TestAnalyzeStdin(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// The short row makes analyze print a warning, which must not end up in the JSON
+	input := "id,a,b,c\nr1,1.5,2.1,3\nr2,2.25,3.3,1\nr3,3,1\nr4,4.75,0.5,5\nr5,2,2,2.5\n"
+	output, err := tc.RunCLIWithStdin(t, input, "analyze", "-", "--format", "json",
+		"--ragged-rows", "pad", "--missing-strategy", "mean")
+	AssertNoError(t, err, "analyze of standard input failed")
+
+	var result struct {
+		Metadata struct {
+			DataSource struct {
+				Filename string `json:"filename"`
+			} `json:"data_source"`
+		} `json:"metadata"`
+		Results struct {
+			Samples struct {
+				Names []string `json:"names"`
+			} `json:"samples"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Expected only JSON on stdout: %v\n%s", err, output)
+	}
+	if got := strings.Join(result.Results.Samples.Names, ","); got != "r1,r2,r3,r4,r5" {
+		t.Errorf("Expected samples r1 to r5, got %s", got)
+	}
+	if result.Metadata.DataSource.Filename != "stdin" {
+		t.Errorf("Expected data source stdin, got %q", result.Metadata.DataSource.Filename)
+	}
+
+	// With an output directory the model is written to stdin_pca.json
+	outputDir := filepath.Join(tc.TempDir, "stdin")
+	_, err = tc.RunCLIWithStdin(t, input, "analyze", "-", "-f", "json", "-o", outputDir,
+		"--ragged-rows", "pad", "--missing-strategy", "mean")
+	AssertNoError(t, err, "analyze of standard input to a directory failed")
+	CheckFileExists(t, filepath.Join(outputDir, "stdin_pca.json"))
+
+	if _, err := tc.RunCLIWithStdin(t, input, "analyze", "-", "-f", "csv"); err == nil {
+		t.Error("Expected an error for CSV output of standard input without an output directory")
+	}
+}
+
+// TestAnalyzeJSONStdout tests that without an output directory the JSON model of an
+// input file goes to stdout, and to a file next to the input with --json-to-file
+func TestAnalyzeJSONStdout(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	inputDir := filepath.Join(tc.TempDir, "input")
+	AssertNoError(t, os.MkdirAll(inputDir, 0755), "Failed to create input directory")
+	path := filepath.Join(inputDir, "data.csv")
+	AssertNoError(t, os.WriteFile(path, []byte("id,a,b,c\nr1,1.5,2.1,3\nr2,2.25,3.3,1\nr3,3,1,4\nr4,4.75,0.5,5\nr5,2,2,2.5\n"), 0644),
+		"Failed to write input")
+
+	output, err := tc.RunCLI(t, "analyze", "-f", "json", path)
+	AssertNoError(t, err, "analyze -f json without an output directory failed")
+	var result struct {
+		Results struct {
+			Samples struct {
+				Names []string `json:"names"`
+			} `json:"samples"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Expected only JSON on stdout: %v\n%s", err, output)
+	}
+	if got := strings.Join(result.Results.Samples.Names, ","); got != "r1,r2,r3,r4,r5" {
+		t.Errorf("Expected samples r1 to r5, got %s", got)
+	}
+	for _, dir := range []string{inputDir, tc.TempDir} {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*_pca.json"))
+		if len(matches) > 0 {
+			t.Errorf("Expected no JSON file without an output directory, found %v", matches)
+		}
+	}
+
+	// --json-to-file keeps the model in data_pca.json next to the input
+	output, err = tc.RunCLI(t, "analyze", "-f", "json", "--json-to-file", path)
+	AssertNoError(t, err, "analyze --json-to-file failed")
+	CheckFileExists(t, filepath.Join(inputDir, "data_pca.json"))
+	if json.Valid([]byte(output)) {
+		t.Error("Expected no JSON model on stdout with --json-to-file")
+	}
+
+	if _, err := tc.RunCLIWithStdin(t, "a,b\n1,2\n3,5\n4,4\n", "analyze", "-f", "json", "--json-to-file", "-"); err == nil {
+		t.Error("Expected an error for --json-to-file with standard input")
+	}
+}
+
+// TestAnalyzeScoresOnly tests that --scores-only writes scores without loadings,
+// and that transform rejects the result as a model
+func TestAnalyzeScoresOnly(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	datasets := tc.CreateSampleDatasets(t)
+	path := datasets["small"].Path
+
+	output, err := tc.RunCLI(t, "analyze", "--scores-only", "--include-metrics", "-f", "json", "-o", tc.TempDir, path)
+	AssertNoError(t, err, "analyze --scores-only failed")
+	AssertContains(t, output, "ignoring --include-metrics", "Metrics warning")
+
+	modelPath := filepath.Join(tc.TempDir, "small_pca.json")
+	jsonData, err := os.ReadFile(modelPath)
+	AssertNoError(t, err, "Failed to read JSON output")
+	var result struct {
+		Model   map[string]json.RawMessage `json:"model"`
+		Results struct {
+			Samples map[string]json.RawMessage `json:"samples"`
+		} `json:"results"`
+	}
+	AssertNoError(t, json.Unmarshal(jsonData, &result), "Failed to parse JSON output")
+	for _, key := range []string{"loadings", "variable_importance", "component_interpretation"} {
+		if _, ok := result.Model[key]; ok {
+			t.Errorf("Expected no model.%s in scores-only output", key)
+		}
+	}
+	if _, ok := result.Model["explained_variance_ratio"]; !ok {
+		t.Error("Expected explained variance in scores-only output")
+	}
+	if _, ok := result.Results.Samples["metrics"]; ok {
+		t.Error("Expected no metrics in scores-only output")
+	}
+	var scores [][]float64
+	AssertNoError(t, json.Unmarshal(result.Results.Samples["scores"], &scores), "Failed to parse scores")
+	if len(scores) == 0 {
+		t.Error("Expected scores in scores-only output")
+	}
+
+	if _, err := tc.RunCLI(t, "transform", modelPath, path, "-o", tc.TempDir); err == nil {
+		t.Error("Expected transform to reject a scores-only model")
+	} else {
+		AssertContains(t, err.Error(), "--scores-only", "Transform error")
+	}
+
+	// The fit leaves out the loadings, so options that need them are rejected
+	_, err = tc.RunCLI(t, "analyze", "--scores-only", "--output-reconstruction", path)
+	AssertError(t, err, "Expected --scores-only with --output-reconstruction to fail")
+	AssertContains(t, err.Error(), "needs the loadings", "Scores-only conflict")
+}
+
+func TestAnalyzeOutputReconstruction(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	data := [][]string{
+		{"id", "a", "b", "c"},
+		{"r1", "1.5", "10", "3"},
+		{"r2", "2", "14", "5.5"},
+		{"r3", "3", "13", "4"},
+		{"r4", "5", "20", "9"},
+		{"r5", "4", "18", "6"},
+	}
+	path := tc.CreateTestCSV(t, "recon.csv", data)
+
+	// With all components the reconstruction equals the input
+	output, err := tc.RunCLI(t, "analyze", "--components", "3", "--scale", "standard",
+		"--output-reconstruction", "-o", tc.TempDir, path)
+	AssertNoError(t, err, "analyze --output-reconstruction failed")
+	AssertContains(t, output, "Reconstruction saved to:", "Reconstruction message")
+
+	file, err := os.Open(filepath.Join(tc.TempDir, "recon_reconstruction.csv"))
+	AssertNoError(t, err, "Failed to open reconstruction")
+	defer func() { _ = file.Close() }()
+	records, err := csv.NewReader(file).ReadAll()
+	AssertNoError(t, err, "Failed to read reconstruction")
+	if len(records) != len(data) {
+		t.Fatalf("Expected %d rows, got %d", len(data), len(records))
+	}
+	if strings.Join(records[0], ",") != "observation,a,b,c" {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+	for i := 1; i < len(data); i++ {
+		if records[i][0] != data[i][0] {
+			t.Errorf("Row %d: expected name %s, got %s", i, data[i][0], records[i][0])
+		}
+		for j := 1; j < len(data[i]); j++ {
+			want, _ := strconv.ParseFloat(data[i][j], 64)
+			got, err := strconv.ParseFloat(records[i][j], 64)
+			AssertNoError(t, err, "Failed to parse reconstructed value")
+			if math.Abs(got-want) > 1e-8 {
+				t.Errorf("Row %d, column %d: expected %g, got %g", i, j, want, got)
+			}
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--method", "kernel"},
+		{"--snv"},
+	} {
+		cmdArgs := append([]string{"analyze", "--output-reconstruction"}, args...)
+		if _, err := tc.RunCLI(t, append(cmdArgs, path)...); err == nil {
+			t.Errorf("Expected --output-reconstruction with %v to fail", args)
+		}
+	}
+}
+
+func TestAnalyzeSelectRows(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// 18 rows of class a and 2 of class b
+	data := [][]string{{"id", "x", "y", "z", "class"}}
+	for i := 1; i <= 20; i++ {
+		class := "a"
+		if i > 18 {
+			class = "b"
+		}
+		data = append(data, []string{fmt.Sprintf("r%d", i), strconv.Itoa(i), strconv.Itoa(i * i % 7),
+			strconv.Itoa(i % 5), class})
+	}
+	path := tc.CreateTestCSV(t, "rows.csv", data)
+
+	scoreNames := func(dir string) []string {
+		scores := readCSVRecords(t, filepath.Join(dir, "rows_scores.csv"))
+		var names []string
+		for _, record := range scores[1:] {
+			names = append(names, record[0])
+		}
+		return names
+	}
+
+	selectedDir := filepath.Join(tc.TempDir, "selected")
+	output, err := tc.RunCLI(t, "analyze", "--select-rows", "r20,1-4,r2", "-f", "csv",
+		"--output-dir", selectedDir, path)
+	AssertNoError(t, err, "analyze --select-rows failed")
+	AssertContains(t, output, "Selected 5 of 20 rows", "row selection message")
+	if got := strings.Join(scoreNames(selectedDir), ","); got != "r1,r2,r3,r4,r20" {
+		t.Errorf("Expected score rows r1,r2,r3,r4,r20, got %s", got)
+	}
+
+	balancedDir := filepath.Join(tc.TempDir, "balanced")
+	output, err = tc.RunCLI(t, "analyze", "--balance-by", "class", "--seed", "7", "-f", "csv",
+		"--output-dir", balancedDir, path)
+	AssertNoError(t, err, "analyze --balance-by failed")
+	AssertContains(t, output, "Selected 4 of 20 rows", "row selection message")
+	names := scoreNames(balancedDir)
+	if len(names) != 4 || names[2] != "r19" || names[3] != "r20" {
+		t.Errorf("Expected two rows of class a followed by r19 and r20, got %v", names)
+	}
+
+	for _, args := range [][]string{
+		{"--select-rows", "25"},
+		{"--select-rows", "nosuchrow"},
+		{"--balance-by", "x"},
+		{"--select-rows", "1-5", "--exclude-rows", "2"},
+	} {
+		cmdArgs := append([]string{"analyze"}, args...)
+		if _, err := tc.RunCLI(t, append(cmdArgs, path)...); err == nil {
+			t.Errorf("Expected analyze %v to fail", args)
+		}
+	}
+}
+
+func TestAnalyzeHeadersAuto(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	rows := [][]string{
+		{"1.5", "2.0", "3.1"},
+		{"2.5", "1.0", "4.0"},
+		{"3.0", "4.5", "2.0"},
+		{"4.0", "3.0", "5.5"},
+		{"0.5", "2.5", "1.0"},
+	}
+	withHeader := tc.CreateTestCSV(t, "with_header.csv", append([][]string{{"a", "b", "c"}}, rows...))
+	headerless := tc.CreateTestCSV(t, "headerless.csv", rows)
+
+	output, err := tc.RunCLI(t, "analyze", "--headers", "auto", "--no-index", "--verbose", withHeader)
+	AssertNoError(t, err, "analyze --headers auto failed on a file with a header")
+	AssertContains(t, output, "Header row detected", "header decision")
+	AssertContains(t, output, "Sample_5", "scores of all five rows")
+
+	output, err = tc.RunCLI(t, "analyze", "--headers", "auto", "--no-index", "--verbose", headerless)
+	AssertNoError(t, err, "analyze --headers auto failed on a headerless file")
+	AssertContains(t, output, "No header row detected", "header decision")
+	AssertContains(t, output, "Sample_5", "scores of all five rows")
+
+	if _, err := tc.RunCLI(t, "analyze", "--headers", "auto", "--no-headers", headerless); err == nil {
+		t.Error("Expected --headers auto with --no-headers to fail")
+	}
+	if _, err := tc.RunCLI(t, "analyze", "--headers", "maybe", headerless); err == nil {
+		t.Error("Expected an invalid --headers value to fail")
+	}
+}
+
+// TestAnalyzePlotASCII tests that --plot-ascii is skipped when the output is not a terminal
+func TestAnalyzePlotASCII(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "--plot-ascii", "--plot-group", "species", irisPath)
+	AssertNoError(t, err, "analyze --plot-ascii failed")
+	AssertContains(t, output, "--plot-ascii skipped because standard output is not a terminal", "skip warning")
+	AssertContains(t, output, "PCA Scores", "regular table output")
+
+	if _, err := tc.RunCLI(t, "analyze", "--plot-ascii", "--plot-group", "sepal length (cm)", irisPath); err == nil {
+		t.Error("Expected a numeric --plot-group column to fail")
+	}
+	if _, err := tc.RunCLI(t, "analyze", "--plot-group", "species", irisPath); err == nil {
+		t.Error("Expected --plot-group without --plot-ascii to fail")
+	}
+}
+
+// TestAnalyzeNormalizeScores tests that --normalize-scores writes unit-variance score columns
+func TestAnalyzeNormalizeScores(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "normalized")
+
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--scale", "standard", "--components", "3",
+		"--normalize-scores", "-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --normalize-scores failed")
+
+	records := readCSVRecords(t, filepath.Join(outDir, "iris_scores.csv"))
+	for k := 1; k < len(records[0]); k++ {
+		var sum, sumSq float64
+		n := float64(len(records) - 1)
+		for _, record := range records[1:] {
+			v, err := strconv.ParseFloat(record[k], 64)
+			AssertNoError(t, err, "Failed to parse score")
+			sum += v
+			sumSq += v * v
+		}
+		variance := (sumSq - sum*sum/n) / (n - 1)
+		if math.Abs(variance-1) > 1e-6 {
+			t.Errorf("%s: expected unit variance, got %g", records[0][k], variance)
+		}
+	}
+
+	if _, err := tc.RunCLI(t, "analyze", "--method", "kernel", "--normalize-scores", irisPath); err == nil {
+		t.Error("Expected --normalize-scores with kernel PCA to fail")
+	}
+}
+
+// TestAnalyzeOutlierMethod tests that --outlier-method and --outlier-significance
+// control which observations the metrics flag as outliers
+func TestAnalyzeOutlierMethod(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	flagged := func(method, significance string) []bool {
+		outDir := filepath.Join(tc.TempDir, method+"_"+significance)
+		_, err := tc.RunCLI(t, "analyze", "-f", "json", "--include-metrics", "--outlier-method", method,
+			"--outlier-significance", significance, "-o", outDir, irisPath)
+		AssertNoError(t, err, "analyze --outlier-method failed")
+		modelData, err := os.ReadFile(filepath.Join(outDir, "iris_pca.json"))
+		AssertNoError(t, err, "Failed to read model")
+		var model struct {
+			Results struct {
+				Samples struct {
+					Metrics struct {
+						IsOutlier []bool `json:"is_outlier"`
+					} `json:"metrics"`
+				} `json:"samples"`
+			} `json:"results"`
+		}
+		AssertNoError(t, json.Unmarshal(modelData, &model), "Failed to parse model")
+		return model.Results.Samples.Metrics.IsOutlier
+	}
+	count := func(mask []bool) int {
+		n := 0
+		for _, v := range mask {
+			if v {
+				n++
+			}
+		}
+		return n
+	}
+
+	t2Loose, t2Strict := flagged("t2", "0.05"), flagged("t2", "0.001")
+	if count(t2Loose) <= count(t2Strict) {
+		t.Errorf("Expected significance 0.001 to flag fewer rows than 0.05, got %d and %d",
+			count(t2Strict), count(t2Loose))
+	}
+	spe, combined := flagged("spe", "0.05"), flagged("combined", "0.05")
+	if slices.Equal(spe, t2Loose) {
+		t.Error("Expected the spe test to flag other rows than the t2 test")
+	}
+	for i := range combined {
+		if combined[i] != (t2Loose[i] || spe[i]) {
+			t.Errorf("Row %d: combined flag %v is not the t2 flag %v or the spe flag %v", i, combined[i], t2Loose[i], spe[i])
+		}
+	}
+
+	if _, err := tc.RunCLI(t, "analyze", "--outlier-method", "iqr", irisPath); err == nil {
+		t.Error("Expected --outlier-method iqr to fail")
+	}
+	if _, err := tc.RunCLI(t, "analyze", "--outlier-significance", "1.5", irisPath); err == nil {
+		t.Error("Expected --outlier-significance 1.5 to fail")
+	}
+}
+
+// TestAnalyzeMetricsPreprocessedSpace tests that the residuals of --include-metrics
+// are calculated from the preprocessed data: with every component retained the
+// model reconstructs the standardized data exactly
+func TestAnalyzeMetricsPreprocessedSpace(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "-f", "json", "--include-metrics", "--components", "4",
+		"--scale", "standard", "-o", tc.TempDir, irisPath)
+	AssertNoError(t, err, "analyze --include-metrics failed")
+	modelData, err := os.ReadFile(filepath.Join(tc.TempDir, "iris_pca.json"))
+	AssertNoError(t, err, "Failed to read model")
+	var model struct {
+		Results struct {
+			Samples struct {
+				Metrics struct {
+					RSS []float64 `json:"rss"`
+				} `json:"metrics"`
+			} `json:"samples"`
+		} `json:"results"`
+	}
+	AssertNoError(t, json.Unmarshal(modelData, &model), "Failed to parse model")
+
+	rss := model.Results.Samples.Metrics.RSS
+	if len(rss) != 150 {
+		t.Fatalf("Expected 150 RSS values, got %d", len(rss))
+	}
+	for i, v := range rss {
+		if v > 1e-9 {
+			t.Errorf("Row %d: expected no residual with all components retained, got RSS %g", i, v)
+			break
+		}
+	}
+}
+
+// TestAnalyzeCarryCols tests that --carry-cols writes the species column next to the
+// scores, aligned with the rows left after --exclude-rows
+func TestAnalyzeCarryCols(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	species := make(map[string]string)
+	for _, record := range readCSVRecords(t, irisPath)[1:] {
+		species[record[0]] = record[len(record)-1]
+	}
+	outDir := filepath.Join(tc.TempDir, "carry")
+
+	// Rows 1-10 by index, named 0-9, and the row named 60
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--exclude-rows", "1-10,60", "--carry-cols", "species",
+		"-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --carry-cols failed")
+
+	records := readCSVRecords(t, filepath.Join(outDir, "iris_scores.csv"))
+	if got := strings.Join(records[0], ","); got != "observation,PC1,PC2,species" {
+		t.Fatalf("Expected the species column after the scores, got header %s", got)
+	}
+	if len(records)-1 != 139 {
+		t.Errorf("Expected 139 rows after excluding 11, got %d", len(records)-1)
+	}
+	for _, record := range records[1:] {
+		if record[0] == "9" || record[0] == "60" {
+			t.Errorf("Excluded row %s is in the scores", record[0])
+		}
+		if want := species[record[0]]; record[3] != want {
+			t.Errorf("Row %s: expected species %s, got %s", record[0], want, record[3])
+		}
+	}
+
+	if _, err := tc.RunCLI(t, "analyze", "--carry-cols", "petal length (cm)", irisPath); err == nil {
+		t.Error("Expected --carry-cols with a numeric data column to fail")
+	}
+}
+
+func TestAnalyzeValidateOnly(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "validate-only")
+	output, err := tc.RunCLI(t, "analyze", "--validate-only", "--exclude-columns", "1",
+		"--exclude-rows", "1-10", "-f", "json", "-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --validate-only failed")
+	for _, want := range []string{"140 rows × 3 numeric columns", "Categorical columns: species",
+		"Maximum components: 3", "ready for PCA"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got:\n%s", want, output)
+		}
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Error("Expected --validate-only to write no results")
+	}
+
+	// The fit excludes the same columns as the validation
+	_, err = tc.RunCLI(t, "analyze", "--exclude-columns", "1,2", "-f", "json", "-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --exclude-columns failed")
+	result := tc.LoadJSONResult(t, filepath.Join(outDir, "iris_pca.json"))
+	features := result["model"].(map[string]interface{})["feature_labels"].([]interface{})
+	if len(features) != 2 || features[0] != "petal length (cm)" || features[1] != "petal width (cm)" {
+		t.Errorf("Expected the petal columns after excluding columns 1 and 2, got %v", features)
+	}
+
+	// Entries that match no column are rejected rather than skipped
+	for _, entry := range []string{"5-", "bogus", "9"} {
+		_, err = tc.RunCLI(t, "analyze", "--validate-only", "--exclude-columns", "1,"+entry, irisPath)
+		AssertError(t, err, "Expected an invalid --exclude-columns entry to fail")
+		if !strings.Contains(err.Error(), entry) {
+			t.Errorf("Expected the error to name %q, got: %v", entry, err)
+		}
+	}
+
+	path := tc.CreateTestCSV(t, "one_column.csv", [][]string{
+		{"id", "value", "group"},
+		{"a", "1.0", "x"},
+		{"b", "2.0", "y"},
+		{"c", "4.0", "x"},
+	})
+	_, err = tc.RunCLI(t, "analyze", "--validate-only", path)
+	AssertError(t, err, "Expected --validate-only to fail with one numeric column")
+	if !strings.Contains(err.Error(), "need at least 2 numeric columns") {
+		t.Errorf("Expected a message about too few numeric columns, got: %v", err)
+	}
+}
+
+func TestAnalyzeComponentsMax(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// 6 rows of 8 columns: at most min(n−1, p) = 5 components carry variance
+	path := tc.CreateTestCSV(t, "wide.csv", GenerateTestMatrix(6, 8, 2.0))
+	outDir := filepath.Join(tc.TempDir, "max")
+	output, err := tc.RunCLI(t, "analyze", "--components", "max", "-f", "csv", "-o", outDir, path)
+	AssertNoError(t, err, "analyze --components max failed")
+	AssertContains(t, output, "Components selected: 5", "Maximum number of components")
+	records := readCSVRecords(t, filepath.Join(outDir, "wide_scores.csv"))
+	if got := len(records[0]) - 1; got != 5 {
+		t.Errorf("Expected 5 score columns, got %d", got)
+	}
+
+	// Over-requesting suggests max and prints the maximum
+	_, err = tc.RunCLI(t, "analyze", "--components", "7", path)
+	AssertError(t, err, "Expected over-requesting components to fail")
+	if !strings.Contains(err.Error(), "support at most 6") || !strings.Contains(err.Error(), "--components max") {
+		t.Errorf("Expected the maximum and a hint to use max, got: %v", err)
+	}
+
+	if _, err := tc.RunCLI(t, "analyze", "--components", "many", path); err == nil {
+		t.Error("Expected an error for --components many")
+	}
+}
+
+func TestAnalyzeExportComponents(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "export")
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--components", "4", "--export-components", "4,1,3",
+		"-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --export-components failed")
+
+	// The exported columns are the matching columns of the full scores and loadings
+	for _, kind := range []string{"scores", "loadings"} {
+		full := readCSVRecords(t, filepath.Join(outDir, "iris_"+kind+".csv"))
+		name := "iris_components.csv"
+		if kind == "loadings" {
+			name = "iris_components_loadings.csv"
+		}
+		exported := readCSVRecords(t, filepath.Join(outDir, name))
+		if got := strings.Join(exported[0][1:], ","); got != "PC1,PC3,PC4" {
+			t.Fatalf("Expected %s columns PC1,PC3,PC4, got %s", kind, got)
+		}
+		if len(exported) != len(full) {
+			t.Fatalf("Expected %d %s rows, got %d", len(full)-1, kind, len(exported)-1)
+		}
+		for i := 1; i < len(full); i++ {
+			want := []string{full[i][0], full[i][1], full[i][3], full[i][4]}
+			if !slices.Equal(exported[i], want) {
+				t.Errorf("%s row %d: expected %v, got %v", kind, i, want, exported[i])
+			}
+		}
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--components", "2", "--export-components", "1,3", "-o", outDir, irisPath)
+	AssertError(t, err, "Expected exporting a component that was not computed to fail")
+	if !strings.Contains(err.Error(), "only 2 components were computed") {
+		t.Errorf("Expected an error naming the computed components, got: %v", err)
+	}
+}
+
+func TestAnalyzeWeightsFromMetrics(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	// 40 rows along x = y with small noise in z, and 3 outliers far out along z
+	var b strings.Builder
+	b.WriteString("id,x,y,z\n")
+	for i := 0; i < 40; i++ {
+		v := float64(i-20) / 4
+		fmt.Fprintf(&b, "s%d,%.4f,%.4f,%.4f\n", i+1, v+0.3*math.Sin(float64(i)), v-0.3*math.Sin(float64(i)),
+			0.3*math.Cos(1.7*float64(i)))
+	}
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintf(&b, "bad%d,%d,%d,15\n", i, 4+i, 4-i)
+	}
+	input := filepath.Join(tc.TempDir, "outliers.csv")
+	if err := os.WriteFile(input, []byte(b.String()), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "--components", "1", "--weights-from-metrics", "--robust-iterations", "5",
+		"-o", filepath.Join(tc.TempDir, "out"), input)
+	AssertNoError(t, err, "analyze --weights-from-metrics failed")
+	AssertContains(t, output, "3 row(s) strongly downweighted", "Expected the outliers to be reported")
+	for i := 1; i <= 3; i++ {
+		AssertContains(t, output, fmt.Sprintf("row %d (bad%d)", 40+i, i), "Expected each outlier to be named")
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--weights-from-metrics", "--robust-iterations", "0", input)
+	AssertError(t, err, "Expected zero robust iterations to fail")
+}
+
+func TestAnalyzeFormatPreset(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	european := filepath.Join(tc.TempDir, "european.csv")
+	content := "id;a;b;c\nr1;1,5;2;3\nr2;3;4,25;1\nr3;2;1;0,5\nr4;5;2;2\n"
+	AssertNoError(t, os.WriteFile(european, []byte(content), 0644), "Failed to write input")
+	outDir := filepath.Join(tc.TempDir, "out")
+	_, err := tc.RunCLI(t, "analyze", "-f", "csv", "--format-preset", "european", "-o", outDir, european)
+	AssertNoError(t, err, "analyze --format-preset european failed")
+	if loadings := readCSVRecords(t, filepath.Join(outDir, "european_loadings.csv")); len(loadings) != 4 {
+		t.Errorf("Expected loadings for 3 variables, got %v", loadings)
+	}
+
+	// An explicit delimiter overrides the preset's, keeping its comma decimals
+	pipe := filepath.Join(tc.TempDir, "pipe.csv")
+	AssertNoError(t, os.WriteFile(pipe, []byte(strings.ReplaceAll(content, ";", "|")), 0644), "Failed to write input")
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--format-preset", "european", "--delimiter", "|", "-o", outDir, pipe)
+	AssertNoError(t, err, "analyze --format-preset european --delimiter | failed")
+	if loadings := readCSVRecords(t, filepath.Join(outDir, "pipe_loadings.csv")); len(loadings) != 4 {
+		t.Errorf("Expected loadings for 3 variables, got %v", loadings)
+	}
+
+	// An explicit decimal separator overrides the preset's, leaving no numeric column
+	_, err = tc.RunCLI(t, "analyze", "--format-preset", "european", "--decimal-separator", "dot", european)
+	AssertError(t, err, "Expected dot decimals to leave the European sample without numeric columns")
+
+	_, err = tc.RunCLI(t, "analyze", "--format-preset", "klingon", european)
+	AssertError(t, err, "Expected an unknown preset to fail")
+}
+
+func TestAnalyzeComponentStatus(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "status")
+	_, err = tc.RunCLI(t, "analyze", "-f", "json", "-c", "4", "--scale", "standard",
+		"--component-status", "parallel", "-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --component-status failed")
+
+	jsonData, err := os.ReadFile(filepath.Join(outDir, "iris_pca.json"))
+	AssertNoError(t, err, "Failed to read JSON output")
+	var result struct {
+		Model struct {
+			ComponentStatus []string `json:"component_status"`
+		} `json:"model"`
+	}
+	AssertNoError(t, json.Unmarshal(jsonData, &result), "Failed to parse JSON output")
+	// Only PC1 of standardized iris exceeds the parallel analysis baseline
+	if want := []string{"signal", "noise", "noise", "noise"}; !slices.Equal(result.Model.ComponentStatus, want) {
+		t.Errorf("Expected component status %v, got %v", want, result.Model.ComponentStatus)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "-c", "2", "--component-status", "broken-stick", irisPath)
+	AssertNoError(t, err, "analyze --component-status broken-stick failed")
+	AssertContains(t, output, "Status", "Expected a status column in the variance table")
+
+	_, err = tc.RunCLI(t, "analyze", "--component-status", "scree", irisPath)
+	AssertError(t, err, "Expected an unknown criterion to fail")
+}
+
+// TestAnalyzeVarianceCurve tests that --output-explained-variance-curve exports the
+// variance of all components with the elbow, beyond the components fitted
+func TestAnalyzeVarianceCurve(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "curve")
+	_, err = tc.RunCLI(t, "analyze", "-f", "json", "-c", "2", "--scale", "standard",
+		"--output-explained-variance-curve", "-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --output-explained-variance-curve failed")
+
+	jsonData, err := os.ReadFile(filepath.Join(outDir, "iris_pca.json"))
+	AssertNoError(t, err, "Failed to read JSON output")
+	var result struct {
+		Model struct {
+			CumulativeVariance []float64 `json:"cumulative_variance"`
+			VarianceCurve      struct {
+				ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"`
+				CumulativeVariance     []float64 `json:"cumulative_variance"`
+				Elbow                  int       `json:"elbow"`
+			} `json:"variance_curve"`
+		} `json:"model"`
+	}
+	AssertNoError(t, json.Unmarshal(jsonData, &result), "Failed to parse JSON output")
+	curve := result.Model.VarianceCurve
+	// All four components of iris, although only two were fitted, with the elbow
+	// after the second
+	if len(curve.CumulativeVariance) != 4 || len(curve.ExplainedVarianceRatio) != 4 {
+		t.Fatalf("Expected the curve of all 4 components, got %+v", curve)
+	}
+	if math.Abs(curve.CumulativeVariance[3]-100) > 1e-9 ||
+		math.Abs(curve.CumulativeVariance[1]-result.Model.CumulativeVariance[1]) > 1e-6 {
+		t.Errorf("Expected the curve to match the fitted components and end at 100%%, got %v", curve.CumulativeVariance)
+	}
+	if curve.Elbow != 2 {
+		t.Errorf("Expected the elbow at 2 components, got %d", curve.Elbow)
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--scale", "standard",
+		"--output-explained-variance-curve", "-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --output-explained-variance-curve -f csv failed")
+	records := readCSVRecords(t, filepath.Join(outDir, "iris_variance_curve.csv"))
+	if len(records) != 5 || records[2][3] != "true" || records[1][3] != "false" {
+		t.Errorf("Expected 4 components with the elbow at PC2, got %v", records)
+	}
+
+	output, err := tc.RunCLI(t, "analyze", "--scale", "standard", "--output-explained-variance-curve", irisPath)
+	AssertNoError(t, err, "analyze --output-explained-variance-curve failed")
+	AssertContains(t, output, "Elbow: 2 components", "Expected the elbow in the table output")
+
+	_, err = tc.RunCLI(t, "analyze", "--method", "kernel", "--output-explained-variance-curve", irisPath)
+	AssertError(t, err, "Expected an error for kernel PCA")
+}
+
+func TestAnalyzeOutputInfluence(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outDir := filepath.Join(tc.TempDir, "influence")
+	_, err = tc.RunCLI(t, "analyze", "-f", "json", "-c", "2", "--scale", "standard",
+		"--include-metrics", "--output-influence", "-o", outDir, irisPath)
+	AssertNoError(t, err, "analyze --output-influence failed")
+
+	jsonData, err := os.ReadFile(filepath.Join(outDir, "iris_pca.json"))
+	AssertNoError(t, err, "Failed to read JSON output")
+	var result struct {
+		Results struct {
+			Samples struct {
+				Influence []struct {
+					T2        float64 `json:"t2"`
+					Q         float64 `json:"q"`
+					T2Limit95 float64 `json:"t2_limit95"`
+					QLimit95  float64 `json:"q_limit95"`
+					Status    string  `json:"status"`
+				} `json:"influence"`
+			} `json:"samples"`
+		} `json:"results"`
+	}
+	AssertNoError(t, json.Unmarshal(jsonData, &result), "Failed to parse JSON output")
+	influence := result.Results.Samples.Influence
+	if len(influence) != 150 {
+		t.Fatalf("Expected 150 influence points, got %d", len(influence))
+	}
+	for i, p := range influence {
+		if p.T2Limit95 <= 0 || p.QLimit95 <= 0 {
+			t.Fatalf("Point %d has no limits: %+v", i, p)
+		}
+		want := "normal"
+		switch {
+		case p.T2 > p.T2Limit95 && p.Q > p.QLimit95:
+			want = "both"
+		case p.T2 > p.T2Limit95:
+			want = "high-t2"
+		case p.Q > p.QLimit95:
+			want = "high-q"
+		}
+		if p.Status != want {
+			t.Errorf("Point %d: expected status %s, got %s", i, want, p.Status)
+		}
+	}
+
+	csvDir := filepath.Join(tc.TempDir, "influence-csv")
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--include-metrics", "--output-influence", "-o", csvDir, irisPath)
+	AssertNoError(t, err, "analyze --output-influence -f csv failed")
+	records := readCSVRecords(t, filepath.Join(csvDir, "iris_influence.csv"))
+	if want := []string{"observation", "t2", "q", "t2_limit95", "q_limit95", "status"}; !slices.Equal(records[0], want) {
+		t.Errorf("Expected header %v, got %v", want, records[0])
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "--output-influence", irisPath)
+	AssertError(t, err, "Expected --output-influence without --include-metrics to fail")
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestCleanCommand tests that duplicate, constant and empty columns are removed and
+// reported while informative columns are kept
+func TestCleanCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	input := filepath.Join(tc.TempDir, "data.csv")
+	content := `id,height,weight,height_copy,batch,notes,site
+s1,1.5,60,1.5,7,,north
+s2,1.7,72,1.7,7,NA,south
+s3,1.6,65,1.6,7.0,,north
+s4,1.8,80,1.8,7,,north
+`
+	if err := os.WriteFile(input, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	output := filepath.Join(tc.TempDir, "cleaned.csv")
+	report := filepath.Join(tc.TempDir, "removed.csv")
+
+	stdout, err := tc.RunCLI(t, "clean", "--report", report, input, output)
+	AssertNoError(t, err, "clean failed")
+	AssertContains(t, stdout, "Removed 3 of 6 columns", "clean summary")
+
+	cleaned := readCSVRecords(t, output)
+	if want := []string{"id", "height", "weight", "site"}; !slices.Equal(cleaned[0], want) {
+		t.Errorf("Expected columns %v, got %v", want, cleaned[0])
+	}
+	if want := []string{"s2", "1.7", "72", "south"}; !slices.Equal(cleaned[2], want) {
+		t.Errorf("Expected the kept values %v unchanged, got %v", want, cleaned[2])
+	}
+
+	removed := readCSVRecords(t, report)
+	want := [][]string{
+		{"column", "reason", "duplicate_of"},
+		{"height_copy", "duplicate", "height"},
+		{"batch", "constant", ""},
+		{"notes", "empty", ""},
+	}
+	if len(removed) != len(want) {
+		t.Fatalf("Expected report %v, got %v", want, removed)
+	}
+	for i := range want {
+		if !slices.Equal(removed[i], want[i]) {
+			t.Errorf("Report line %d: expected %v, got %v", i, want[i], removed[i])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestComparePreprocessingCommand tests the preprocessing sweep on iris with group separation
+func TestComparePreprocessingCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+
+	output, err := tc.RunCLI(t, "compare-preprocessing", "--group-column", "species", "-f", "json", irisPath)
+	AssertNoError(t, err, "compare-preprocessing failed")
+
+	var comparisons []struct {
+		Preprocessing          string    `json:"preprocessing"`
+		ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"`
+		CumulativeVariance     float64   `json:"cumulative_variance"`
+		GroupSeparation        *float64  `json:"group_separation"`
+	}
+	AssertNoError(t, json.Unmarshal([]byte(output), &comparisons), "Failed to parse JSON output")
+
+	want := []string{"none", "center", "standard", "robust", "snv+standard"}
+	if len(comparisons) != len(want) {
+		t.Fatalf("Expected %d rows, got %d", len(want), len(comparisons))
+	}
+	for i, c := range comparisons {
+		if c.Preprocessing != want[i] {
+			t.Errorf("Row %d: expected %s, got %s", i, want[i], c.Preprocessing)
+		}
+		if len(c.ExplainedVarianceRatio) != 2 {
+			t.Fatalf("%s: expected PC1 and PC2, got %v", c.Preprocessing, c.ExplainedVarianceRatio)
+		}
+		pc1, pc2 := c.ExplainedVarianceRatio[0], c.ExplainedVarianceRatio[1]
+		if pc1 < pc2 || pc2 < 0 || pc1+pc2 > 100+1e-9 {
+			t.Errorf("%s: implausible explained variance PC1 %g, PC2 %g", c.Preprocessing, pc1, pc2)
+		}
+		if c.GroupSeparation == nil || *c.GroupSeparation <= 0 {
+			t.Errorf("%s: expected a positive group separation", c.Preprocessing)
+		}
+	}
+
+	output, err = tc.RunCLI(t, "compare-preprocessing", "--group-column", "species", irisPath)
+	AssertNoError(t, err, "compare-preprocessing table output failed")
+	AssertContains(t, output, "Best separation of species:", "Best separation line")
+	if strings.Count(output, "snv+standard") != 1 {
+		t.Errorf("Expected one snv+standard row in\n%s", output)
+	}
+
+	if _, err := tc.RunCLI(t, "compare-preprocessing", "--group-column", "missing", irisPath); err == nil {
+		t.Error("Expected an error for an unknown group column")
+	}
+}
@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestConvertExcelRoundTrip tests that data survives a csv→xlsx→csv conversion
+func TestConvertExcelRoundTrip(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	data := [][]string{
+		{"id", "length", "width", "species"},
+		{"s1", "5.1", "3.5", "setosa"},
+		{"s2", "4.9", "-0.125", "versicolor"},
+		{"s3", "6.2", "", "virginica, var. b"},
+		{"s4", "1e-05", "2.25", "setosa"},
+	}
+	input := tc.CreateTestCSV(t, "convert.csv", data)
+	xlsx := filepath.Join(tc.TempDir, "convert.xlsx")
+	output := filepath.Join(tc.TempDir, "roundtrip.csv")
+
+	_, err := tc.RunCLI(t, "convert", input, xlsx)
+	AssertNoError(t, err, "csv→xlsx conversion failed")
+	CheckFileExists(t, xlsx)
+
+	_, err = tc.RunCLI(t, "convert", xlsx, output)
+	AssertNoError(t, err, "xlsx→csv conversion failed")
+
+	records := readCSVRecords(t, output)
+	if len(records) != len(data) {
+		t.Fatalf("Expected %d records, got %d", len(data), len(records))
+	}
+	// The header of the row name column is preserved too
+	if strings.Join(records[0], ",") != strings.Join(data[0], ",") {
+		t.Errorf("Expected headers %v, got %v", data[0], records[0])
+	}
+	// Excel stores numbers, so compare numeric cells by value
+	for i := 1; i < len(data); i++ {
+		if len(records[i]) != len(data[i]) {
+			t.Fatalf("Row %d: expected %d fields, got %d", i, len(data[i]), len(records[i]))
+		}
+		for j, want := range data[i] {
+			got := records[i][j]
+			wantNum, errWant := strconv.ParseFloat(want, 64)
+			gotNum, errGot := strconv.ParseFloat(got, 64)
+			if errWant == nil && errGot == nil {
+				if wantNum != gotNum {
+					t.Errorf("Row %d, column %d: expected %v, got %v", i, j, wantNum, gotNum)
+				}
+			} else if got != want {
+				t.Errorf("Row %d, column %d: expected %q, got %q", i, j, want, got)
+			}
+		}
+	}
+}
+
+// TestConvertEuropeanToTSV tests converting a semicolon/decimal-comma file to TSV
+func TestConvertEuropeanToTSV(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	input := filepath.Join(tc.TempDir, "european.csv")
+	content := "id;a;b;note\nr1;1,5;2;x\nr2;3,25;-4,125;y, z\n"
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	output := filepath.Join(tc.TempDir, "european.tsv")
+
+	_, err := tc.RunCLI(t, "convert", "--delimiter", ";", "--decimal-separator", "comma", input, output)
+	AssertNoError(t, err, "European csv→tsv conversion failed")
+
+	got, err := os.ReadFile(output)
+	AssertNoError(t, err, "Failed to read TSV output")
+
+	expected := "id\ta\tb\tnote\nr1\t1.5\t2\tx\nr2\t3.25\t-4.125\ty, z\n"
+	if string(got) != expected {
+		t.Errorf("Unexpected TSV output:\n%q\nexpected:\n%q", got, expected)
+	}
+
+	_, err = tc.RunCLI(t, "convert", input, filepath.Join(tc.TempDir, "european.parquet"))
+	AssertError(t, err, "Expected error for unsupported output format")
+}
+
+// TestImpute tests that impute fills every missing numeric cell and leaves
+// row names and categorical columns untouched
+func TestImpute(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	data := [][]string{
+		{"id", "length", "width", "depth", "species"},
+		{"s1", "5.1", "NA", "1.4", "setosa"},
+		{"s2", "4.9", "3.0", "", "setosa"},
+		{"s3", "", "3.2", "1.3", "NA"},
+		{"s4", "6.4", "3.2", "4.5", "versicolor"},
+		{"s5", "6.9", "3.1", "4.9", "versicolor"},
+		{"s6", "6.3", "", "6.0", "virginica"},
+		{"s7", "5.8", "2.7", "5.1", "virginica"},
+	}
+	input := tc.CreateTestCSV(t, "impute.csv", data)
+
+	for _, strategy := range []string{"mean", "knn"} {
+		t.Run(strategy, func(t *testing.T) {
+			output := filepath.Join(tc.TempDir, "imputed_"+strategy+".csv")
+			_, err := tc.RunCLI(t, "impute", "--missing-strategy", strategy, "--neighbors", "2", input, output)
+			AssertNoError(t, err, "impute failed")
+
+			records := readCSVRecords(t, output)
+			if len(records) != len(data) {
+				t.Fatalf("Expected %d records, got %d", len(data), len(records))
+			}
+			for i := 1; i < len(data); i++ {
+				if records[i][0] != data[i][0] || records[i][4] != data[i][4] {
+					t.Errorf("Row %d: row name or species changed: %v", i, records[i])
+				}
+				for j := 1; j <= 3; j++ {
+					if _, err := strconv.ParseFloat(records[i][j], 64); err != nil {
+						t.Errorf("Row %d, column %d: expected a number, got %q", i, j, records[i][j])
+					}
+					if data[i][j] != "" && data[i][j] != "NA" && records[i][j] != data[i][j] {
+						t.Errorf("Row %d, column %d: observed value %q changed to %q", i, j, data[i][j], records[i][j])
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("group-by", func(t *testing.T) {
+		output := filepath.Join(tc.TempDir, "imputed_grouped.csv")
+		_, err := tc.RunCLI(t, "impute", "--missing-strategy", "median", "--impute-group-by", "species", input, output)
+		AssertNoError(t, err, "group-wise impute failed")
+
+		// Medians of the row's species, and of all rows for s3 without a species
+		records := readCSVRecords(t, output)
+		for _, cell := range []struct {
+			row, col int
+			want     string
+		}{{1, 2, "3"}, {2, 3, "1.4"}, {3, 1, "6.05"}, {6, 2, "2.7"}} {
+			if got := records[cell.row][cell.col]; got != cell.want {
+				t.Errorf("Row %d, column %d: expected %s, got %q", cell.row, cell.col, cell.want, got)
+			}
+		}
+	})
+
+	t.Run("spec", func(t *testing.T) {
+		output := filepath.Join(tc.TempDir, "imputed_spec.csv")
+		report := filepath.Join(tc.TempDir, "imputed_spec_report.csv")
+		_, err := tc.RunCLI(t, "impute", "--missing-strategy", "median", "--impute-spec", "length:mean, width:forward",
+			"--impute-report", report, input, output)
+		AssertNoError(t, err, "impute with per-column strategies failed")
+
+		// Mean of length, width carried forward (and back for s1), median of depth
+		records := readCSVRecords(t, output)
+		for _, cell := range []struct {
+			row, col int
+			want     float64
+		}{{3, 1, 5.9}, {1, 2, 3.0}, {6, 2, 3.1}, {2, 3, 4.7}} {
+			got, err := strconv.ParseFloat(records[cell.row][cell.col], 64)
+			if err != nil || math.Abs(got-cell.want) > 1e-9 {
+				t.Errorf("Row %d, column %d: expected %g, got %q", cell.row, cell.col, cell.want, records[cell.row][cell.col])
+			}
+		}
+
+		methods := make(map[string]string)
+		for _, record := range readCSVRecords(t, report)[1:] {
+			methods[record[0]+"/"+record[1]] = record[4]
+		}
+		want := map[string]string{"s3/length": "mean", "s1/width": "forward", "s6/width": "forward", "s2/depth": "median"}
+		if !reflect.DeepEqual(methods, want) {
+			t.Errorf("Expected report methods %v, got %v", want, methods)
+		}
+	})
+
+	for _, spec := range []string{"species:mode", "length:knn", "height:mean", "length:mean,length:median", "length"} {
+		_, err := tc.RunCLI(t, "impute", "--impute-spec", spec, input, filepath.Join(tc.TempDir, "x.csv"))
+		AssertError(t, err, "Expected error for --impute-spec "+spec)
+	}
+
+	_, err := tc.RunCLI(t, "impute", "--missing-strategy", "native", input, filepath.Join(tc.TempDir, "x.csv"))
+	AssertError(t, err, "Expected error for a strategy that does not impute")
+
+	_, err = tc.RunCLI(t, "impute", "--missing-strategy", "knn", "--impute-group-by", "species", input,
+		filepath.Join(tc.TempDir, "x.csv"))
+	AssertError(t, err, "Expected error for group-wise knn imputation")
+}
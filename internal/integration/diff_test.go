@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestDiffCommand tests comparing a model with itself via the diff command
+func TestDiffCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	datasets := tc.CreateSampleDatasets(t)
+	outputDir := filepath.Join(tc.TempDir, "diff")
+
+	_, err := tc.RunCLI(t,
+		"analyze",
+		"--components", "3",
+		"--format", "json",
+		"--output-dir", outputDir,
+		datasets["small"].Path,
+	)
+	AssertNoError(t, err, "PCA analysis failed")
+
+	modelPath := filepath.Join(outputDir, "small_pca.json")
+	CheckFileExists(t, modelPath)
+
+	output, err := tc.RunCLI(t, "diff", "--format", "json", modelPath, modelPath)
+	AssertNoError(t, err, "diff failed")
+
+	result, err := ExtractJSONFromOutput(output)
+	AssertNoError(t, err, "Failed to parse diff output")
+
+	if verdict, _ := result["verdict"].(string); verdict != "identical" {
+		t.Errorf("Expected verdict 'identical' when comparing a model to itself, got %q", verdict)
+	}
+	if compared, _ := result["compared_components"].(float64); compared != 3 {
+		t.Errorf("Expected 3 compared components, got %v", result["compared_components"])
+	}
+
+	tableOutput, err := tc.RunCLI(t, "diff", modelPath, modelPath)
+	AssertNoError(t, err, "diff table output failed")
+	AssertContains(t, tableOutput, "Verdict: identical", "diff table output")
+}
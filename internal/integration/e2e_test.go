@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -280,8 +281,22 @@ func TestE2EExportFormats(t *testing.T) {
 				AssertNoError(t, err, "Invalid JSON format")
 			},
 		},
-		// CSV and TSV formats are not supported by the CLI
-		// Only "json" and "table" formats are available
+		{
+			format: "csv",
+			ext:    ".csv",
+			validate: func(t *testing.T, path string) {
+				file, err := os.Open(path)
+				AssertNoError(t, err, "Failed to open CSV")
+				defer file.Close()
+
+				records, err := csv.NewReader(file).ReadAll()
+				AssertNoError(t, err, "Invalid CSV format")
+				if len(records) < 2 {
+					t.Errorf("Expected header and data rows in %s", filepath.Base(path))
+				}
+			},
+		},
+		// TSV format is not supported by the CLI
 	}
 
 	for _, f := range formats {
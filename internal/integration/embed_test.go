@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestEmbedCommand tests writing a t-SNE embedding of iris with row names
+func TestEmbedCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	outputDir := filepath.Join(tc.TempDir, "embed")
+
+	output, err := tc.RunCLI(t, "embed", "--method", "tsne", "--perplexity", "30", "--scale", "standard",
+		"--iterations", "300", "--output-dir", outputDir, irisPath)
+	AssertNoError(t, err, "embed failed")
+	AssertContains(t, output, "t-SNE embedding of 150 samples", "embed summary")
+
+	file, err := os.Open(filepath.Join(outputDir, "iris_tsne.csv"))
+	AssertNoError(t, err, "Failed to open embedding")
+	defer func() { _ = file.Close() }()
+	records, err := csv.NewReader(file).ReadAll()
+	AssertNoError(t, err, "Failed to read embedding")
+
+	if len(records) != 151 {
+		t.Fatalf("Expected a header and 150 rows, got %d rows", len(records))
+	}
+	if got := records[0]; len(got) != 3 || got[1] != "TSNE1" || got[2] != "TSNE2" {
+		t.Errorf("Expected header [observation TSNE1 TSNE2], got %v", got)
+	}
+
+	if _, err := tc.RunCLI(t, "embed", "--perplexity", "200", irisPath); err == nil {
+		t.Error("Expected an error for a perplexity above the number of samples")
+	}
+}
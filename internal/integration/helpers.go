@@ -121,9 +121,16 @@ func (tc *TestConfig) BuildCLI(t *testing.T) {
 // RunCLI executes the CLI with given arguments
 func (tc *TestConfig) RunCLI(t *testing.T, args ...string) (string, error) {
 	t.Helper()
+	return tc.RunCLIWithStdin(t, "", args...)
+}
+
+// RunCLIWithStdin runs the CLI like RunCLI, with stdin as its standard input
+func (tc *TestConfig) RunCLIWithStdin(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
 
 	cmd := exec.Command(tc.CLIPath, args...)
 	cmd.Dir = tc.TempDir
+	cmd.Stdin = strings.NewReader(stdin)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// TestInfoCommand tests summarizing a freshly exported model
+func TestInfoCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	datasets := tc.CreateSampleDatasets(t)
+	outputDir := filepath.Join(tc.TempDir, "info")
+	_, err := tc.RunCLI(t, "analyze", "-f", "json", "--components", "2", "--scale", "standard",
+		"-o", outputDir, datasets["small"].Path)
+	AssertNoError(t, err, "analyze failed")
+
+	modelFile := filepath.Join(outputDir, "small_pca.json")
+	CheckFileExists(t, modelFile)
+
+	output, err := tc.RunCLI(t, "info", modelFile)
+	AssertNoError(t, err, "info failed")
+	AssertContains(t, output, "Method:           SVD", "method")
+	AssertContains(t, output, "Components:       2", "component count")
+	AssertContains(t, output, "mean-center → standard-scale", "preprocessing")
+	AssertContains(t, output, "Training data:    small.csv", "training data")
+	AssertContains(t, output, "Explained Variance:", "explained variance table")
+
+	output, err = tc.RunCLI(t, "info", "-f", "json", modelFile)
+	AssertNoError(t, err, "info -f json failed")
+	var info struct {
+		Method                 string    `json:"method"`
+		Components             int       `json:"components"`
+		ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"`
+		DataChecksum           string    `json:"data_checksum"`
+		SoftwareVersion        string    `json:"software_version"`
+		MissingFields          []string  `json:"missing_fields"`
+	}
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatalf("Failed to parse info JSON: %v\n%s", err, output)
+	}
+	if info.Method != "svd" || info.Components != 2 || len(info.ExplainedVarianceRatio) != 2 {
+		t.Errorf("Unexpected summary: %+v", info)
+	}
+	if len(info.DataChecksum) != 64 {
+		t.Errorf("Expected a SHA-256 data checksum, got %q", info.DataChecksum)
+	}
+	if info.SoftwareVersion == "" || len(info.MissingFields) != 0 {
+		t.Errorf("Expected a software version and no missing fields, got %q and %v",
+			info.SoftwareVersion, info.MissingFields)
+	}
+}
@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestKernelMatrixCommand tests exporting the RBF kernel matrix before centering
+func TestKernelMatrixCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	datasets := tc.CreateSampleDatasets(t)
+	outputPath := filepath.Join(tc.TempDir, "kernel.csv")
+
+	output, err := tc.RunCLI(t, "kernel-matrix", "--scale", "standard", "--kernel-gamma", "0.5",
+		"--uncentered", "-o", outputPath, datasets["small"].Path)
+	AssertNoError(t, err, "kernel-matrix failed")
+	AssertContains(t, output, "Kernel Matrix (rbf, gamma = 0.5", "kernel-matrix summary")
+	AssertContains(t, output, "Condition number", "kernel-matrix summary")
+
+	file, err := os.Open(outputPath)
+	AssertNoError(t, err, "Failed to open kernel matrix")
+	defer func() { _ = file.Close() }()
+	records, err := csv.NewReader(file).ReadAll()
+	AssertNoError(t, err, "Failed to read kernel matrix")
+
+	n := len(records) - 1
+	if n < 2 || len(records[0]) != n+1 {
+		t.Fatalf("Expected a square matrix with header and row names, got %d rows and %d columns",
+			len(records), len(records[0]))
+	}
+	K := make([][]float64, n)
+	for i := range K {
+		K[i] = make([]float64, n)
+		for j := range K[i] {
+			K[i][j], err = strconv.ParseFloat(records[i+1][j+1], 64)
+			AssertNoError(t, err, "Failed to parse kernel value")
+		}
+	}
+	for i := range K {
+		if math.Abs(K[i][i]-1) > 1e-12 {
+			t.Errorf("K[%d][%d] = %v, want 1", i, i, K[i][i])
+		}
+		for j := range K {
+			if K[i][j] != K[j][i] {
+				t.Errorf("Kernel matrix is not symmetric at (%d, %d)", i, j)
+			}
+		}
+	}
+}
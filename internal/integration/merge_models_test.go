@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestMergeModelsCommand tests merging models fitted with different algorithms
+func TestMergeModelsCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	datasets := tc.CreateSampleDatasets(t)
+
+	var models []string
+	for _, method := range []string{"svd", "nipals"} {
+		outputDir := filepath.Join(tc.TempDir, "merge_"+method)
+		_, err := tc.RunCLI(t,
+			"analyze",
+			"--method", method,
+			"--components", "2",
+			"--format", "json",
+			"--output-dir", outputDir,
+			datasets["small"].Path,
+		)
+		AssertNoError(t, err, "PCA analysis failed")
+
+		modelPath := filepath.Join(outputDir, "small_pca.json")
+		CheckFileExists(t, modelPath)
+		models = append(models, modelPath)
+	}
+
+	consensusPath := filepath.Join(tc.TempDir, "consensus_pca.json")
+	output, err := tc.RunCLI(t, append([]string{"merge-models", "--format", "json", "-o", consensusPath}, models...)...)
+	AssertNoError(t, err, "merge-models failed")
+	CheckFileExists(t, consensusPath)
+
+	result, err := ExtractJSONFromOutput(output)
+	AssertNoError(t, err, "Failed to parse merge-models output")
+
+	if components, _ := result["components"].(float64); components != 2 {
+		t.Errorf("Expected 2 merged components, got %v", result["components"])
+	}
+	if disparity, _ := result["max_disparity"].(float64); disparity > 1e-6 {
+		t.Errorf("Expected near-zero disparity between SVD and NIPALS models, got %g", disparity)
+	}
+
+	// The consensus model must be usable for projection
+	tableOutput, err := tc.RunCLI(t, "transform", consensusPath, datasets["small"].Path)
+	AssertNoError(t, err, "transform with consensus model failed")
+	AssertContains(t, tableOutput, "Transformed Scores", "transform output")
+
+	// A single model cannot be merged
+	if _, err := tc.RunCLI(t, "merge-models", models[0]); err == nil {
+		t.Error("Expected an error when merging a single model")
+	}
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// TestModelTransformRoundTrip verifies that transforming the training data with an
+// exported model reproduces the training scores
+func TestModelTransformRoundTrip(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	trainPath := tc.CreateTestCSV(t, "train.csv", GenerateTestMatrix(30, 6, 4.0))
+
+	for _, scale := range []string{"none", "standard", "robust"} {
+		t.Run(scale, func(t *testing.T) {
+			analyzeDir := filepath.Join(tc.TempDir, "analyze_"+scale)
+			transformDir := filepath.Join(tc.TempDir, "transform_"+scale)
+
+			_, err := tc.RunCLI(t,
+				"analyze",
+				"--components", "3",
+				"--scale", scale,
+				"--format", "json",
+				"--output-dir", analyzeDir,
+				trainPath,
+			)
+			AssertNoError(t, err, "PCA analysis failed")
+
+			modelPath := filepath.Join(analyzeDir, "train_pca.json")
+			CheckFileExists(t, modelPath)
+
+			_, err = tc.RunCLI(t,
+				"transform",
+				"--format", "json",
+				"--output", transformDir,
+				modelPath,
+				trainPath,
+			)
+			AssertNoError(t, err, "Transform failed")
+
+			transformPath := filepath.Join(transformDir, "train_transformed.json")
+			CheckFileExists(t, transformPath)
+
+			model := tc.LoadJSONResult(t, modelPath)
+			transformed := tc.LoadJSONResult(t, transformPath)
+
+			trainScores := toMatrix(model["results"].(map[string]interface{})["samples"].(map[string]interface{})["scores"])
+			samples := transformed["samples"].([]interface{})
+			if len(samples) != len(trainScores) {
+				t.Fatalf("Expected %d transformed samples, got %d", len(trainScores), len(samples))
+			}
+
+			labels := []string{"PC1", "PC2", "PC3"}
+			for i, s := range samples {
+				scores := s.(map[string]interface{})["scores"].(map[string]interface{})
+				for j, label := range labels {
+					got := toFloat64(scores[label])
+					if math.Abs(got-trainScores[i][j]) > 1e-6 {
+						t.Fatalf("Sample %d %s: transformed score %f differs from training score %f",
+							i, label, got, trainScores[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+// toMatrix converts a decoded JSON array of arrays to [][]float64
+func toMatrix(v interface{}) [][]float64 {
+	rows, _ := v.([]interface{})
+	result := make([][]float64, len(rows))
+	for i, row := range rows {
+		result[i] = toFloatSlice(row)
+	}
+	return result
+}
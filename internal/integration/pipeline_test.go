@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestPipelineStandardizeAnalyze tests a pipeline that standardizes the data and
+// then analyzes it, against analyze --scale standard on the same data
+func TestPipelineStandardizeAnalyze(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	spec := filepath.Join(tc.TempDir, "pipeline.yaml")
+	content := `vars:
+  data: ` + irisPath + `
+input: ${data}
+steps:
+  - op: scale
+    method: standard
+  - op: analyze
+    components: 2
+    format: csv
+    output-dir: ${out}/pipeline
+`
+	AssertNoError(t, os.WriteFile(spec, []byte(content), 0644), "Failed to write pipeline spec")
+
+	_, err = tc.RunCLI(t, "pipeline", "--var", "out="+tc.TempDir, spec)
+	AssertNoError(t, err, "pipeline failed")
+	for _, name := range []string{"iris_scores.csv", "iris_loadings.csv", "iris_variance.csv"} {
+		CheckFileExists(t, filepath.Join(tc.TempDir, "pipeline", name))
+	}
+
+	_, err = tc.RunCLI(t, "analyze", "-f", "csv", "--scale", "standard", "-o", filepath.Join(tc.TempDir, "direct"), irisPath)
+	AssertNoError(t, err, "analyze failed")
+	got := readCSVRecords(t, filepath.Join(tc.TempDir, "pipeline", "iris_scores.csv"))
+	want := readCSVRecords(t, filepath.Join(tc.TempDir, "direct", "iris_scores.csv"))
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d score rows, got %d", len(want), len(got))
+	}
+	for i := 1; i < len(want); i++ {
+		for j := 1; j < len(want[i]); j++ {
+			g, _ := strconv.ParseFloat(got[i][j], 64)
+			w, _ := strconv.ParseFloat(want[i][j], 64)
+			if math.Abs(g-w) > 1e-9 {
+				t.Fatalf("Row %d, column %d: expected score %g, got %g", i, j, w, g)
+			}
+		}
+	}
+
+	// The spec is validated before any step runs
+	bad := filepath.Join(tc.TempDir, "bad.yaml")
+	AssertNoError(t, os.WriteFile(bad, []byte("input: "+irisPath+"\nsteps:\n  - op: normalize\n"), 0644),
+		"Failed to write pipeline spec")
+	_, err = tc.RunCLI(t, "pipeline", bad)
+	AssertError(t, err, "Expected an unknown operation to fail")
+}
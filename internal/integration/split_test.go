@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestSplitCommand tests a stratified train/test split of iris
+func TestSplitCommand(t *testing.T) {
+	SkipIfShort(t)
+
+	tc := NewTestConfig(t)
+	tc.BuildCLI(t)
+
+	irisPath, err := filepath.Abs(filepath.Join("..", "..", "testdata", "iris", "iris.csv"))
+	if err != nil {
+		t.Fatalf("Failed to resolve iris path: %v", err)
+	}
+	prefix := filepath.Join(tc.TempDir, "iris")
+
+	output, err := tc.RunCLI(t, "split", "--test-frac", "0.3", "--stratify", "species", "--seed", "42",
+		"--out-prefix", prefix, irisPath)
+	AssertNoError(t, err, "split failed")
+	AssertContains(t, output, "Split 150 rows into 105 train and 45 test rows", "split summary")
+
+	input := readCSVRecords(t, irisPath)
+	train := readCSVRecords(t, prefix+"_train.csv")
+	test := readCSVRecords(t, prefix+"_test.csv")
+	if !slices.Equal(train[0], input[0]) || !slices.Equal(test[0], input[0]) {
+		t.Fatalf("Expected the input header %v in both files, got %v and %v", input[0], train[0], test[0])
+	}
+	if len(train) != 106 || len(test) != 46 {
+		t.Fatalf("Expected 105 train and 45 test rows, got %d and %d", len(train)-1, len(test)-1)
+	}
+
+	// Every input row is in exactly one file, unchanged
+	rows := make(map[string][]string)
+	for _, record := range input[1:] {
+		rows[record[0]] = record
+	}
+	species := slices.Index(input[0], "species")
+	testCounts := make(map[string]int)
+	for _, part := range [][][]string{train[1:], test[1:]} {
+		for _, record := range part {
+			original, ok := rows[record[0]]
+			if !ok {
+				t.Fatalf("Row %s is missing from the input or in both files", record[0])
+			}
+			if !slices.Equal(record, original) {
+				t.Errorf("Row %s changed: expected %v, got %v", record[0], original, record)
+			}
+			delete(rows, record[0])
+		}
+	}
+	if len(rows) != 0 {
+		t.Errorf("Expected every row in a file, %d left out", len(rows))
+	}
+	for _, record := range test[1:] {
+		testCounts[record[species]]++
+	}
+	for _, name := range []string{"setosa", "versicolor", "virginica"} {
+		if testCounts[name] != 15 {
+			t.Errorf("Expected 15 %s rows in the test set, got %d", name, testCounts[name])
+		}
+	}
+
+	_, err = tc.RunCLI(t, "split", "--test-frac", "1.5", "--out-prefix", prefix, irisPath)
+	AssertError(t, err, "Expected a test fraction above 1 to fail")
+	_, err = tc.RunCLI(t, "split", "--stratify", "genus", "--out-prefix", prefix, irisPath)
+	AssertError(t, err, "Expected an unknown stratify column to fail")
+}
@@ -0,0 +1,323 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+// Package stats provides one-pass summary statistics for data too large to sort
+// or hold in memory.
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Moments accumulates the count, extremes and central moments of a stream of values
+// in one pass, using Welford's update extended to the third and fourth moments.
+// The zero value is ready to use.
+type Moments struct {
+	n              float64
+	mean           float64
+	m2, m3, m4     float64
+	minVal, maxVal float64
+}
+
+// Add adds a value to the stream
+func (m *Moments) Add(x float64) {
+	if m.n == 0 {
+		m.minVal, m.maxVal = x, x
+	} else {
+		m.minVal = math.Min(m.minVal, x)
+		m.maxVal = math.Max(m.maxVal, x)
+	}
+
+	n1 := m.n
+	m.n++
+	delta := x - m.mean
+	deltaN := delta / m.n
+	deltaN2 := deltaN * deltaN
+	term1 := delta * deltaN * n1
+
+	m.mean += deltaN
+	m.m4 += term1*deltaN2*(m.n*m.n-3*m.n+3) + 6*deltaN2*m.m2 - 4*deltaN*m.m3
+	m.m3 += term1*deltaN*(m.n-2) - 3*deltaN*m.m2
+	m.m2 += term1
+}
+
+// Count returns the number of values added
+func (m *Moments) Count() int {
+	return int(m.n)
+}
+
+// Mean returns the mean, or 0 if no values were added
+func (m *Moments) Mean() float64 {
+	return m.mean
+}
+
+// Variance returns the population variance (divided by n)
+func (m *Moments) Variance() float64 {
+	if m.n == 0 {
+		return 0
+	}
+	return m.m2 / m.n
+}
+
+// SampleVariance returns the sample variance (divided by n-1)
+func (m *Moments) SampleVariance() float64 {
+	if m.n < 2 {
+		return 0
+	}
+	return m.m2 / (m.n - 1)
+}
+
+// StdDev returns the population standard deviation
+func (m *Moments) StdDev() float64 {
+	return math.Sqrt(m.Variance())
+}
+
+// Min returns the smallest value, or 0 if no values were added
+func (m *Moments) Min() float64 {
+	return m.minVal
+}
+
+// Max returns the largest value, or 0 if no values were added
+func (m *Moments) Max() float64 {
+	return m.maxVal
+}
+
+// Skewness returns the sample skewness n/((n-1)(n-2)) Σz³, with z standardized by the
+// population standard deviation. Returns 0 for fewer than 3 values or constant data.
+func (m *Moments) Skewness() float64 {
+	if m.n < 3 || m.m2 == 0 {
+		return 0
+	}
+	n := m.n
+	sumZ3 := m.m3 / math.Pow(m.Variance(), 1.5)
+	return n / ((n - 1) * (n - 2)) * sumZ3
+}
+
+// Kurtosis returns the sample excess kurtosis
+// n(n+1)/((n-1)(n-2)(n-3)) Σz⁴ - 3(n-1)²/((n-2)(n-3)), with z standardized by the
+// population standard deviation. Returns 0 for fewer than 4 values or constant data.
+func (m *Moments) Kurtosis() float64 {
+	if m.n < 4 || m.m2 == 0 {
+		return 0
+	}
+	n := m.n
+	variance := m.Variance()
+	sumZ4 := m.m4 / (variance * variance)
+	return n*(n+1)/((n-1)*(n-2)*(n-3))*sumZ4 - 3*(n-1)*(n-1)/((n-2)*(n-3))
+}
+
+// P2Quantile estimates a quantile of a stream of values in constant memory using the
+// P² algorithm, which tracks five markers whose heights are adjusted with piecewise
+// parabolic interpolation.
+//
+// Reference: Jain, R. & Chlamtac, I. (1985). The P² algorithm for dynamic calculation
+// of quantiles and histograms without storing observations. Communications of the
+// ACM, 28(10), 1076-1085.
+type P2Quantile struct {
+	p       float64
+	count   int
+	heights [5]float64
+	pos     [5]float64 // Actual marker positions (1-based)
+	desired [5]float64 // Desired marker positions
+	incr    [5]float64 // Increments of the desired positions
+}
+
+// NewP2Quantile returns an estimator for the p-quantile, 0 < p < 1
+func NewP2Quantile(p float64) *P2Quantile {
+	return &P2Quantile{
+		p:       p,
+		desired: [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5},
+		incr:    [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+// Add adds a value to the stream
+func (q *P2Quantile) Add(x float64) {
+	// The first five values initialize the markers
+	if q.count < 5 {
+		q.heights[q.count] = x
+		q.count++
+		if q.count == 5 {
+			sort.Float64s(q.heights[:])
+			for i := range q.pos {
+				q.pos[i] = float64(i + 1)
+			}
+		}
+		return
+	}
+	q.count++
+
+	// Find the cell containing x, extending the extreme markers if needed
+	var k int
+	switch {
+	case x < q.heights[0]:
+		q.heights[0] = x
+		k = 0
+	case x >= q.heights[4]:
+		q.heights[4] = x
+		k = 3
+	default:
+		// heights[0] <= x < heights[4], so this stops at k <= 3
+		for x >= q.heights[k+1] {
+			k++
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		q.pos[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.incr[i]
+	}
+
+	// Move the middle markers towards their desired positions
+	for i := 1; i <= 3; i++ {
+		d := q.desired[i] - q.pos[i]
+		if (d >= 1 && q.pos[i+1]-q.pos[i] > 1) || (d <= -1 && q.pos[i-1]-q.pos[i] < -1) {
+			s := math.Copysign(1, d)
+			h := q.parabolic(i, s)
+			if q.heights[i-1] < h && h < q.heights[i+1] {
+				q.heights[i] = h
+			} else {
+				q.heights[i] = q.linear(i, s)
+			}
+			q.pos[i] += s
+		}
+	}
+}
+
+// parabolic returns the piecewise parabolic prediction for marker i moved by s
+func (q *P2Quantile) parabolic(i int, s float64) float64 {
+	n, h := q.pos, q.heights
+	return h[i] + s/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+s)*(h[i+1]-h[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-s)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+// linear returns the linear prediction for marker i moved by s
+func (q *P2Quantile) linear(i int, s float64) float64 {
+	j := i + int(s)
+	return q.heights[i] + s*(q.heights[j]-q.heights[i])/(q.pos[j]-q.pos[i])
+}
+
+// Value returns the current quantile estimate. With fewer than five values the exact
+// quantile is returned, interpolating linearly between order statistics. Returns NaN
+// if no values were added.
+func (q *P2Quantile) Value() float64 {
+	if q.count == 0 {
+		return math.NaN()
+	}
+	if q.count < 5 {
+		values := append([]float64(nil), q.heights[:q.count]...)
+		sort.Float64s(values)
+		index := q.p * float64(len(values)-1)
+		lower := int(math.Floor(index))
+		upper := int(math.Ceil(index))
+		weight := index - float64(lower)
+		return values[lower]*(1-weight) + values[upper]*weight
+	}
+	return q.heights[2]
+}
+
+// Summary accumulates moments and approximate quartiles of a stream of values
+type Summary struct {
+	Moments
+	q1, median, q3 *P2Quantile
+}
+
+// NewSummary returns an empty summary
+func NewSummary() *Summary {
+	return &Summary{
+		q1:     NewP2Quantile(0.25),
+		median: NewP2Quantile(0.5),
+		q3:     NewP2Quantile(0.75),
+	}
+}
+
+// Add adds a value to the summary
+func (s *Summary) Add(x float64) {
+	s.Moments.Add(x)
+	s.q1.Add(x)
+	s.median.Add(x)
+	s.q3.Add(x)
+}
+
+// Quartiles returns the approximate first quartile, median and third quartile
+func (s *Summary) Quartiles() (q1, median, q3 float64) {
+	return s.q1.Value(), s.median.Value(), s.q3.Value()
+}
+
+// DistinctCounter counts the distinct values of a stream exactly up to a limit, and
+// stops tracking new values once the limit is reached, so that its memory stays
+// bounded however many values are added
+type DistinctCounter struct {
+	limit int
+	seen  map[float64]struct{}
+}
+
+// NewDistinctCounter returns a counter that tracks at most limit distinct values
+func NewDistinctCounter(limit int) *DistinctCounter {
+	return &DistinctCounter{limit: limit, seen: make(map[float64]struct{})}
+}
+
+// Add adds a value to the stream
+func (c *DistinctCounter) Add(x float64) {
+	if len(c.seen) < c.limit {
+		c.seen[x] = struct{}{}
+	}
+}
+
+// Count returns the number of distinct values, which is a lower bound once Capped
+// reports true
+func (c *DistinctCounter) Count() int {
+	return len(c.seen)
+}
+
+// Capped reports whether the limit was reached, so that further distinct values may
+// not have been counted
+func (c *DistinctCounter) Capped() bool {
+	return len(c.seen) >= c.limit
+}
+
+// Reservoir keeps a uniform random sample of fixed size from a stream of values
+// (Vitter's algorithm R). Its random source is seeded, so the same stream always gives
+// the same sample.
+type Reservoir struct {
+	sample []float64
+	size   int
+	seen   int
+	rng    *rand.Rand
+}
+
+// NewReservoir returns a reservoir holding at most size values
+func NewReservoir(size int) *Reservoir {
+	return &Reservoir{
+		sample: make([]float64, 0, size),
+		size:   size,
+		rng:    rand.New(rand.NewSource(1)),
+	}
+}
+
+// Add adds a value to the stream, keeping it in the sample with probability size/n
+// after n values
+func (r *Reservoir) Add(x float64) {
+	r.seen++
+	if len(r.sample) < r.size {
+		r.sample = append(r.sample, x)
+		return
+	}
+	if j := r.rng.Intn(r.seen); j < r.size {
+		r.sample[j] = x
+	}
+}
+
+// Sample returns the sampled values, all values if no more than size were added. The
+// slice is owned by the reservoir.
+func (r *Reservoir) Sample() []float64 {
+	return r.sample
+}
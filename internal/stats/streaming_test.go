@@ -0,0 +1,139 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestMomentsMatchExact(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 10000)
+	var m Moments
+	for i := range values {
+		// Skewed and offset, so the higher moments are non-trivial
+		values[i] = 1e6 + rng.ExpFloat64()*3
+		m.Add(values[i])
+	}
+
+	mean, std := stat.PopMeanStdDev(values, nil)
+	n := float64(len(values))
+	var sumZ3, sumZ4 float64
+	for _, v := range values {
+		z := (v - mean) / std
+		sumZ3 += z * z * z
+		sumZ4 += z * z * z * z
+	}
+	skewness := n / ((n - 1) * (n - 2)) * sumZ3
+	kurtosis := n*(n+1)/((n-1)*(n-2)*(n-3))*sumZ4 - 3*(n-1)*(n-1)/((n-2)*(n-3))
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+		tol       float64
+	}{
+		{"count", float64(m.Count()), n, 0},
+		{"mean", m.Mean(), mean, 1e-9},
+		{"variance", m.Variance(), std * std, 1e-6},
+		{"sample variance", m.SampleVariance(), stat.Variance(values, nil), 1e-6},
+		{"min", m.Min(), sorted[0], 0},
+		{"max", m.Max(), sorted[len(sorted)-1], 0},
+		{"skewness", m.Skewness(), skewness, 1e-6},
+		{"kurtosis", m.Kurtosis(), kurtosis, 1e-5},
+	} {
+		if math.Abs(tt.got-tt.want) > tt.tol*math.Max(1, math.Abs(tt.want)) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestP2QuantileApproximation(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	values := make([]float64, 200000)
+	summary := NewSummary()
+	for i := range values {
+		values[i] = rng.NormFloat64()*10 + 50
+		summary.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	q1, median, q3 := summary.Quartiles()
+	for _, tt := range []struct {
+		p   float64
+		got float64
+	}{
+		{0.25, q1},
+		{0.5, median},
+		{0.75, q3},
+	} {
+		want := stat.Quantile(tt.p, stat.LinInterp, values, nil)
+		// Within 0.5% of the standard deviation
+		if math.Abs(tt.got-want) > 0.05 {
+			t.Errorf("quantile %g: got %.4f, want %.4f", tt.p, tt.got, want)
+		}
+	}
+}
+
+func TestP2QuantileSmallSamples(t *testing.T) {
+	q := NewP2Quantile(0.5)
+	if !math.IsNaN(q.Value()) {
+		t.Errorf("expected NaN for an empty stream, got %v", q.Value())
+	}
+	for _, v := range []float64{4, 1, 3, 2} {
+		q.Add(v)
+	}
+	if got := q.Value(); got != 2.5 {
+		t.Errorf("expected exact median 2.5 for four values, got %v", got)
+	}
+}
+
+func TestDistinctCounter(t *testing.T) {
+	c := NewDistinctCounter(100)
+	for i := 0; i < 1000; i++ {
+		c.Add(float64(i % 40))
+	}
+	if c.Count() != 40 || c.Capped() {
+		t.Errorf("expected 40 distinct values below the limit, got %d (capped %v)", c.Count(), c.Capped())
+	}
+
+	for i := 0; i < 1000; i++ {
+		c.Add(float64(i))
+	}
+	if c.Count() != 100 || !c.Capped() {
+		t.Errorf("expected the count to stop at the limit of 100, got %d (capped %v)", c.Count(), c.Capped())
+	}
+}
+
+func TestReservoir(t *testing.T) {
+	small := NewReservoir(10)
+	for i := 0; i < 5; i++ {
+		small.Add(float64(i))
+	}
+	if len(small.Sample()) != 5 {
+		t.Errorf("expected all 5 values to be kept, got %v", small.Sample())
+	}
+
+	// The sample of a uniform stream has about the mean of the stream
+	r := NewReservoir(2000)
+	for i := 0; i < 200000; i++ {
+		r.Add(float64(i))
+	}
+	sample := r.Sample()
+	if len(sample) != 2000 {
+		t.Fatalf("expected a sample of 2000 values, got %d", len(sample))
+	}
+	if mean := stat.Mean(sample, nil); math.Abs(mean-99999.5) > 5000 {
+		t.Errorf("expected a sample mean near 99999.5, got %v", mean)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+// Package testdata loads the benchmark datasets and golden PCA results used to
+// guard the algorithms against numerical regressions. It only parses the embedded
+// sample files itself, so that tests of internal/core can import it without a cycle
+// through pkg/csv.
+package testdata
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bitjungle/gopca/internal/datasets"
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// Dataset is a numeric benchmark dataset
+type Dataset struct {
+	Name     string
+	RowNames []string
+	Headers  []string     // Name of each column of Matrix
+	Matrix   types.Matrix // NaN for missing values
+}
+
+// source describes how a dataset is taken from an embedded sample file
+type source struct {
+	file       string
+	rows       [2]int // Data rows [start, end) to keep; all rows when end is 0
+	columnStep int    // Keep every columnStep-th numeric column; all when 0
+}
+
+// sources holds the benchmark datasets by name
+var sources = map[string]source{
+	// Fisher's iris measurements: 150 complete rows, 4 columns on similar scales
+	"iris": {file: "iris.csv"},
+	// Corn NIR spectra: 80 rows, every 10th of 700 wavelengths
+	"corn": {file: "corn.csv", columnStep: 10},
+	// Hourly weather at two stations in November 2016: 720 rows, 6 columns with 15
+	// missing dew points
+	"met": {file: "met.csv", rows: [2]int{24840, 25560}},
+}
+
+// Names returns the names of the benchmark datasets in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns a benchmark dataset. The first column of the sample file holds the
+// row names; target columns (named with a #target suffix) and columns with
+// non-numeric values are left out. Empty and NA values are missing.
+func Load(name string) (*Dataset, error) {
+	src, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown benchmark dataset %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	content, ok := datasets.GetDataset(src.file)
+	if !ok {
+		return nil, fmt.Errorf("embedded dataset %s not found", src.file)
+	}
+	records, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", src.file, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%s has no data rows", src.file)
+	}
+	header, rows := records[0], records[1:]
+	if src.rows[1] > 0 {
+		if src.rows[1] > len(rows) || src.rows[0] >= src.rows[1] {
+			return nil, fmt.Errorf("rows %d to %d are out of range for %s with %d rows",
+				src.rows[0], src.rows[1], src.file, len(rows))
+		}
+		rows = rows[src.rows[0]:src.rows[1]]
+	}
+
+	var columns []int
+	for j := 1; j < len(header); j++ {
+		if !strings.HasSuffix(header[j], "#target") && numericColumn(rows, j) {
+			columns = append(columns, j)
+		}
+	}
+	if src.columnStep > 1 {
+		var kept []int
+		for k := 0; k < len(columns); k += src.columnStep {
+			kept = append(kept, columns[k])
+		}
+		columns = kept
+	}
+
+	data := &Dataset{Name: name, RowNames: make([]string, len(rows)), Matrix: make(types.Matrix, len(rows))}
+	for _, j := range columns {
+		data.Headers = append(data.Headers, header[j])
+	}
+	for i, record := range rows {
+		data.RowNames[i] = record[0]
+		data.Matrix[i] = make([]float64, len(columns))
+		for k, j := range columns {
+			data.Matrix[i][k] = parseValue(record[j])
+		}
+	}
+	return data, nil
+}
+
+// numericColumn reports whether every non-missing value of a column is a number,
+// with at least one present
+func numericColumn(rows [][]string, col int) bool {
+	present := false
+	for _, record := range rows {
+		value := strings.TrimSpace(record[col])
+		if missingValue(value) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return false
+		}
+		present = true
+	}
+	return present
+}
+
+// parseValue returns the number in a field of a numeric column, or NaN if missing
+func parseValue(field string) float64 {
+	value := strings.TrimSpace(field)
+	if missingValue(value) {
+		return math.NaN()
+	}
+	v, _ := strconv.ParseFloat(value, 64)
+	return v
+}
+
+// missingValue reports whether a trimmed field is a missing value
+func missingValue(value string) bool {
+	return value == "" || value == "NA"
+}
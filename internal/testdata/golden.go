@@ -0,0 +1,113 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package testdata
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// UpdateGolden regenerates golden files from the current results instead of checking
+// against them. Test packages with golden files bind it to their -update-golden flag.
+var UpdateGolden bool
+
+// GoldenResult is the committed expected result of a PCA fit
+type GoldenResult struct {
+	Method      string       `json:"method"`
+	Eigenvalues []float64    `json:"eigenvalues"`
+	Loadings    types.Matrix `json:"loadings,omitempty"`
+	// Scores are kept only for fits without loadings, such as kernel PCA
+	Scores types.Matrix `json:"scores,omitempty"`
+}
+
+// GoldenPath returns the path of a golden result file, from any test directory
+func GoldenPath(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "golden", name)
+}
+
+// AssertPCAResultMatches compares the eigenvalues and loadings of got, or its scores
+// when it has no loadings, with the golden result at goldenPath. Eigenvalues are
+// compared relative to their size, and each component of the loadings and scores up
+// to its sign, which is arbitrary. With -update-golden the file is written from got.
+func AssertPCAResultMatches(t *testing.T, got *types.PCAResult, goldenPath string, tol float64) {
+	t.Helper()
+	if UpdateGolden {
+		golden := GoldenResult{Method: got.Method, Eigenvalues: got.ExplainedVar}
+		if len(got.Loadings) > 0 {
+			golden.Loadings = got.Loadings
+		} else {
+			golden.Scores = got.Scores
+		}
+		content, err := json.MarshalIndent(golden, "", "  ")
+		if err != nil {
+			t.Fatalf("failed to encode golden result: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, append(content, '\n'), 0644); err != nil {
+			t.Fatalf("failed to update golden result: %v", err)
+		}
+		t.Logf("updated %s", goldenPath)
+	}
+
+	content, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden result (regenerate with -update-golden): %v", err)
+	}
+	var want GoldenResult
+	if err := json.Unmarshal(content, &want); err != nil {
+		t.Fatalf("failed to parse golden result %s: %v", goldenPath, err)
+	}
+
+	if len(got.ExplainedVar) != len(want.Eigenvalues) {
+		t.Fatalf("%s: expected %d eigenvalues, got %d", goldenPath, len(want.Eigenvalues), len(got.ExplainedVar))
+	}
+	for i, w := range want.Eigenvalues {
+		if math.Abs(got.ExplainedVar[i]-w) > tol*math.Max(1, math.Abs(w)) {
+			t.Errorf("%s: eigenvalue %d: expected %.12g, got %.12g", goldenPath, i+1, w, got.ExplainedVar[i])
+		}
+	}
+	if len(want.Loadings) > 0 {
+		assertColumnsMatch(t, "loadings", got.Loadings, want.Loadings, goldenPath, tol)
+	}
+	if len(want.Scores) > 0 {
+		assertColumnsMatch(t, "scores", got.Scores, want.Scores, goldenPath, tol)
+	}
+}
+
+// assertColumnsMatch compares each column of got with that of want, flipping its sign
+// when the two point in opposite directions, and reports the first mismatch of each
+func assertColumnsMatch(t *testing.T, what string, got, want types.Matrix, goldenPath string, tol float64) {
+	t.Helper()
+	if len(got) != len(want) || len(got[0]) != len(want[0]) {
+		t.Errorf("%s: expected %d×%d %s, got %d rows of %d", goldenPath, len(want), len(want[0]), what,
+			len(got), len(got[0]))
+		return
+	}
+	for c := range want[0] {
+		dot := 0.0
+		for i := range want {
+			dot += got[i][c] * want[i][c]
+		}
+		sign := 1.0
+		if dot < 0 {
+			sign = -1
+		}
+		for i := range want {
+			if math.Abs(sign*got[i][c]-want[i][c]) > tol {
+				t.Errorf("%s: %s [%d,%d]: expected %.12g, got %.12g (up to sign)",
+					goldenPath, what, i, c, want[i][c], sign*got[i][c])
+				break
+			}
+		}
+	}
+}
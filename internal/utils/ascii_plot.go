@@ -0,0 +1,177 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// scatterMarkers are the point markers of successive groups in ASCIIScatter
+var scatterMarkers = []rune{'o', 'x', '+', '@', '%', '&', '$', '~'}
+
+const (
+	// scatterPoint marks a cell holding points without a group
+	scatterPoint = '*'
+	// scatterMixed marks a cell holding points of more than one group
+	scatterMixed = '#'
+	// scatterTickWidth is the width of the y axis tick labels
+	scatterTickWidth = 9
+	// ansiReset ends an ANSI color sequence
+	ansiReset = "\033[0m"
+)
+
+// ScatterOptions configures ASCIIScatter
+type ScatterOptions struct {
+	Width  int    // Width of the plot area in characters
+	Height int    // Height of the plot area in lines
+	XLabel string // Label below the x axis
+	YLabel string // Label above the y axis
+	// Groups holds the group of each point, or nil. Each group gets its own marker
+	// and a legend entry; points with an empty group are drawn as groupless.
+	Groups []string
+	// Colors are ANSI color sequences cycled over the groups, or nil for plain text
+	Colors []string
+}
+
+// ASCIIScatter renders the points (x[i], y[i]) as a text scatter plot with tick
+// labels at the ends of both axes, the axis labels and a legend with the number of
+// points plotted. Points that are not finite are left out. A cell holding points of
+// more than one group is drawn as '#'.
+func ASCIIScatter(x, y []float64, opts ScatterOptions) (string, error) {
+	if len(x) != len(y) {
+		return "", fmt.Errorf("x and y have different lengths (%d and %d)", len(x), len(y))
+	}
+	if opts.Groups != nil && len(opts.Groups) != len(x) {
+		return "", fmt.Errorf("groups (%d) do not match points (%d)", len(opts.Groups), len(x))
+	}
+	if opts.Width < 2 || opts.Height < 2 {
+		return "", fmt.Errorf("plot must be at least 2×2 characters, got %d×%d", opts.Width, opts.Height)
+	}
+
+	// Groups are numbered in order of appearance; -1 is no group
+	groupIndex := make(map[string]int)
+	var groupNames []string
+	var groupCounts []int
+	pointGroup := make([]int, len(x))
+	for i := range x {
+		pointGroup[i] = -1
+		if opts.Groups == nil || opts.Groups[i] == "" {
+			continue
+		}
+		k, ok := groupIndex[opts.Groups[i]]
+		if !ok {
+			k = len(groupNames)
+			groupIndex[opts.Groups[i]] = k
+			groupNames = append(groupNames, opts.Groups[i])
+			groupCounts = append(groupCounts, 0)
+		}
+		pointGroup[i] = k
+	}
+
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	plotted := 0
+	for i := range x {
+		if math.IsNaN(x[i]) || math.IsInf(x[i], 0) || math.IsNaN(y[i]) || math.IsInf(y[i], 0) {
+			continue
+		}
+		xMin, xMax = min(xMin, x[i]), max(xMax, x[i])
+		yMin, yMax = min(yMin, y[i]), max(yMax, y[i])
+		plotted++
+	}
+	if plotted == 0 {
+		return "", fmt.Errorf("no finite points to plot")
+	}
+	// A constant coordinate is drawn in the middle of its axis
+	if xMin == xMax {
+		xMin, xMax = xMin-1, xMax+1
+	}
+	if yMin == yMax {
+		yMin, yMax = yMin-1, yMax+1
+	}
+
+	// cells holds the group of the points in each cell: -2 for empty, -3 for mixed
+	cells := make([][]int, opts.Height)
+	for r := range cells {
+		cells[r] = make([]int, opts.Width)
+		for c := range cells[r] {
+			cells[r][c] = -2
+		}
+	}
+	mixed := false
+	for i := range x {
+		if math.IsNaN(x[i]) || math.IsInf(x[i], 0) || math.IsNaN(y[i]) || math.IsInf(y[i], 0) {
+			continue
+		}
+		c := int(math.Round((x[i] - xMin) / (xMax - xMin) * float64(opts.Width-1)))
+		r := opts.Height - 1 - int(math.Round((y[i]-yMin)/(yMax-yMin)*float64(opts.Height-1)))
+		if k := pointGroup[i]; k >= 0 {
+			groupCounts[k]++
+		}
+		switch cells[r][c] {
+		case -2:
+			cells[r][c] = pointGroup[i]
+		case pointGroup[i]:
+		default:
+			cells[r][c] = -3
+			mixed = true
+		}
+	}
+
+	marker := func(k int) string {
+		switch {
+		case k == -3:
+			return string(scatterMixed)
+		case k < 0:
+			return string(scatterPoint)
+		}
+		m := string(scatterMarkers[k%len(scatterMarkers)])
+		if len(opts.Colors) > 0 {
+			m = opts.Colors[k%len(opts.Colors)] + m + ansiReset
+		}
+		return m
+	}
+
+	var b strings.Builder
+	pad := strings.Repeat(" ", scatterTickWidth+1)
+	fmt.Fprintf(&b, "%s%s\n", pad, opts.YLabel)
+	for r, row := range cells {
+		tick := ""
+		switch r {
+		case 0:
+			tick = fmt.Sprintf("%.3g", yMax)
+		case opts.Height - 1:
+			tick = fmt.Sprintf("%.3g", yMin)
+		}
+		fmt.Fprintf(&b, "%*s │", scatterTickWidth, tick)
+		for _, k := range row {
+			if k == -2 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteString(marker(k))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "%*s └%s\n", scatterTickWidth, "", strings.Repeat("─", opts.Width))
+	minTick, maxTick := fmt.Sprintf("%.3g", xMin), fmt.Sprintf("%.3g", xMax)
+	gap := max(1, opts.Width-len(minTick)-len(maxTick))
+	fmt.Fprintf(&b, "%s %s%s%s\n", pad, minTick, strings.Repeat(" ", gap), maxTick)
+	fmt.Fprintf(&b, "%s %*s\n", pad, (opts.Width+len(opts.XLabel))/2, opts.XLabel)
+
+	legend := fmt.Sprintf("%d points", plotted)
+	for k, name := range groupNames {
+		legend += fmt.Sprintf(", %s %s (%d)", marker(k), name, groupCounts[k])
+	}
+	if mixed {
+		legend += fmt.Sprintf(", %c mixed", scatterMixed)
+	}
+	b.WriteString(legend + "\n")
+	return b.String(), nil
+}
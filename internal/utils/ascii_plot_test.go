@@ -0,0 +1,62 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package utils
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestASCIIScatter(t *testing.T) {
+	x := []float64{-2, -1, 0, 1, 2, math.NaN()}
+	y := []float64{1, -1, 0.5, 2, -2, 0}
+	groups := []string{"a", "a", "b", "b", "c", "c"}
+
+	plot, err := ASCIIScatter(x, y, ScatterOptions{
+		Width: 40, Height: 10, XLabel: "PC1 (60.0%)", YLabel: "PC2 (30.0%)", Groups: groups,
+	})
+	if err != nil {
+		t.Fatalf("ASCIIScatter failed: %v", err)
+	}
+	t.Logf("\n%s", plot)
+
+	for _, want := range []string{"PC1 (60.0%)", "PC2 (30.0%)", "5 points", "o a (2)", "x b (2)", "+ c (1)"} {
+		if !strings.Contains(plot, want) {
+			t.Errorf("expected %q in plot", want)
+		}
+	}
+	lines := strings.Split(strings.TrimRight(plot, "\n"), "\n")
+	markers := ""
+	for _, line := range lines[1:11] {
+		_, row, _ := strings.Cut(line, "│")
+		markers += strings.TrimSpace(row)
+	}
+	if got := strings.Join(strings.Fields(markers), ""); len(got) != 5 || strings.Count(got, "o") != 2 {
+		t.Errorf("expected 5 markers, 2 of group a, got %q", got)
+	}
+	// Y label, plot rows, x axis, x ticks, x label and legend
+	if len(lines) != 10+5 {
+		t.Errorf("expected %d lines, got %d", 15, len(lines))
+	}
+	if !strings.Contains(lines[1], "2") || !strings.Contains(lines[10], "-2") {
+		t.Errorf("expected y ticks 2 and -2 at the ends of the axis, got %q and %q", lines[1], lines[10])
+	}
+
+	// Without groups every point is drawn the same way
+	plot, err = ASCIIScatter(x[:5], y[:5], ScatterOptions{Width: 20, Height: 5, XLabel: "PC1", YLabel: "PC2"})
+	if err != nil {
+		t.Fatalf("ASCIIScatter failed: %v", err)
+	}
+	if strings.Count(plot, "*") != 5 || !strings.Contains(plot, "5 points") {
+		t.Errorf("expected 5 points drawn as '*', got\n%s", plot)
+	}
+
+	if _, err := ASCIIScatter(x, y[:2], ScatterOptions{Width: 20, Height: 5}); err == nil {
+		t.Error("expected an error for x and y of different lengths")
+	}
+}
@@ -13,72 +13,82 @@ import (
 	"strings"
 )
 
-// ParseRanges parses a comma-separated string of indices and ranges into a slice of integers.
+// Index bases of ParseRangesWithBase. Indices typed by users on the command line are
+// 1-based, so the first row or column is 1; indices inside the program, in slices and
+// in the PCA configuration, are 0-based. Conversion happens once, when parsing.
+const (
+	ZeroBased = 0 // Input indices start at 0
+	OneBased  = 1 // Input indices start at 1, as on the command line
+)
+
+// ParseRanges parses a comma-separated string of 1-based indices and ranges, as
+// typed on the command line, into sorted, unique 0-based indices.
 // Examples:
-//   - "1,3,5" returns [1, 3, 5]
-//   - "1-3,5" returns [1, 2, 3, 5]
-//   - "1,3-5,7" returns [1, 3, 4, 5, 7]
-//
-// Note: Input indices are 1-based (human-friendly), output indices are 0-based
+//   - "1,3,5" returns [0, 2, 4]
+//   - "1-3,5" returns [0, 1, 2, 4]
+//   - "1,3-5,7" returns [0, 2, 3, 4, 6]
 func ParseRanges(input string) ([]int, error) {
-	if input == "" {
+	return ParseRangesWithBase(input, OneBased)
+}
+
+// ParseRangesWithBase parses a comma-separated string of indices and inclusive
+// ranges such as "2-4", numbered from base (ZeroBased or OneBased), into sorted,
+// unique 0-based indices. Whitespace around entries and range bounds is ignored, as
+// are empty entries. Open ranges such as "5-" or "-3", reversed ranges such as "3-1"
+// and indices below base are errors.
+func ParseRangesWithBase(input string, base int) ([]int, error) {
+	if base != ZeroBased && base != OneBased {
+		return nil, fmt.Errorf("invalid index base %d: must be 0 or 1", base)
+	}
+	if strings.TrimSpace(input) == "" {
 		return []int{}, nil
 	}
 
 	// Use a map to avoid duplicates
 	indexMap := make(map[int]bool)
 
-	// Split by comma
-	parts := strings.Split(input, ",")
-
-	for _, part := range parts {
+	for _, part := range strings.Split(input, ",") {
 		part = strings.TrimSpace(part)
 		if part == "" {
 			continue
 		}
 
-		// Check if it's a range (contains hyphen)
-		if strings.Contains(part, "-") {
-			rangeParts := strings.Split(part, "-")
-			if len(rangeParts) != 2 {
-				return nil, fmt.Errorf("invalid range format: %s", part)
-			}
-
-			start, err := strconv.Atoi(strings.TrimSpace(rangeParts[0]))
+		// A range has one hyphen between its bounds
+		startText, endText, isRange := strings.Cut(part, "-")
+		if !isRange {
+			index, err := parseRangeIndex(part, base)
 			if err != nil {
-				return nil, fmt.Errorf("invalid start index in range %s: %v", part, err)
-			}
-
-			end, err := strconv.Atoi(strings.TrimSpace(rangeParts[1]))
-			if err != nil {
-				return nil, fmt.Errorf("invalid end index in range %s: %v", part, err)
-			}
-
-			if start < 1 || end < 1 {
-				return nil, fmt.Errorf("indices must be positive (1-based), got range %d-%d", start, end)
-			}
-
-			if start > end {
-				return nil, fmt.Errorf("invalid range: start %d is greater than end %d", start, end)
-			}
-
-			// Add all indices in the range (convert to 0-based)
-			for i := start; i <= end; i++ {
-				indexMap[i-1] = true
-			}
-		} else {
-			// Single index
-			index, err := strconv.Atoi(part)
-			if err != nil {
-				return nil, fmt.Errorf("invalid index %s: %v", part, err)
-			}
-
-			if index < 1 {
-				return nil, fmt.Errorf("indices must be positive (1-based), got %d", index)
+				return nil, err
 			}
+			indexMap[index] = true
+			continue
+		}
 
-			// Convert to 0-based
-			indexMap[index-1] = true
+		startText, endText = strings.TrimSpace(startText), strings.TrimSpace(endText)
+		switch {
+		case startText == "" && endText == "":
+			return nil, fmt.Errorf("invalid range %q: missing start and end", part)
+		case startText == "":
+			return nil, fmt.Errorf("invalid range %q: missing start (indices start at %d)", part, base)
+		case endText == "":
+			return nil, fmt.Errorf("invalid range %q: missing end", part)
+		case strings.Contains(endText, "-"):
+			return nil, fmt.Errorf("invalid range format: %s", part)
+		}
+		start, err := parseRangeIndex(startText, base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of range %q: %w", part, err)
+		}
+		end, err := parseRangeIndex(endText, base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of range %q: %w", part, err)
+		}
+		if start > end {
+			return nil, fmt.Errorf("invalid range %q: start %d is greater than end %d, write %s-%s",
+				part, start+base, end+base, endText, startText)
+		}
+		for i := start; i <= end; i++ {
+			indexMap[i] = true
 		}
 	}
 
@@ -92,6 +102,18 @@ func ParseRanges(input string) ([]int, error) {
 	return result, nil
 }
 
+// parseRangeIndex parses a single index numbered from base and returns it 0-based
+func parseRangeIndex(text string, base int) (int, error) {
+	index, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q: not an integer", text)
+	}
+	if index < base {
+		return 0, fmt.Errorf("invalid index %d: indices start at %d", index, base)
+	}
+	return index - base, nil
+}
+
 // FilterMatrix removes specified rows and columns from a matrix
 func FilterMatrix(data [][]float64, excludedRows, excludedColumns []int) ([][]float64, error) {
 	if len(data) == 0 {
@@ -8,6 +8,7 @@ package utils
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -106,6 +107,55 @@ func TestParseRanges(t *testing.T) {
 	}
 }
 
+func TestParseRangesWithBase(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		base    int
+		want    []int
+		wantErr string
+	}{
+		// The CLI convention: 1-based input, 0-based output
+		{name: "one-based index", input: "1", base: OneBased, want: []int{0}},
+		{name: "one-based range", input: "2-4", base: OneBased, want: []int{1, 2, 3}},
+		{name: "one-based zero", input: "0", base: OneBased, wantErr: "indices start at 1"},
+		// Internal use: 0-based input is returned unchanged
+		{name: "zero-based index", input: "0", base: ZeroBased, want: []int{0}},
+		{name: "zero-based range", input: "0-2", base: ZeroBased, want: []int{0, 1, 2}},
+		{name: "whitespace", input: " 3 ,\t1 - 2 ,, ", base: OneBased, want: []int{0, 1, 2}},
+		{name: "duplicates and out of order", input: "5,2-3,3,1-2,5", base: OneBased, want: []int{0, 1, 2, 4}},
+		{name: "single element range", input: "3-3", base: OneBased, want: []int{2}},
+		{name: "only separators", input: " , ", base: OneBased, want: []int{}},
+		{name: "open end", input: "5-", base: OneBased, wantErr: "missing end"},
+		{name: "open start", input: "-3", base: OneBased, wantErr: "missing start"},
+		{name: "lone hyphen", input: "-", base: OneBased, wantErr: "missing start and end"},
+		{name: "reversed range", input: "3-1", base: OneBased, wantErr: "start 3 is greater than end 1, write 1-3"},
+		{name: "reversed zero-based range", input: "2-0", base: ZeroBased, wantErr: "start 2 is greater than end 0"},
+		{name: "three bounds", input: "1-2-3", base: OneBased, wantErr: "invalid range format"},
+		{name: "non-numeric bound", input: "1-x", base: OneBased, wantErr: "invalid end of range"},
+		{name: "decimal index", input: "1.5", base: OneBased, wantErr: "not an integer"},
+		{name: "invalid base", input: "1", base: 2, wantErr: "invalid index base"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRangesWithBase(tt.input, tt.base)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseRangesWithBase(%q, %d) error = %v, want %q", tt.input, tt.base, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRangesWithBase(%q, %d) unexpected error: %v", tt.input, tt.base, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRangesWithBase(%q, %d) = %v, want %v", tt.input, tt.base, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFilterMatrix(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -0,0 +1,93 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// zipMagic is the signature at the start of a zip archive
+var zipMagic = []byte("PK\x03\x04")
+
+// isZipFile reports whether a file is a zip archive, by its .zip extension or its
+// signature
+func isZipFile(filename string, file io.ReaderAt) bool {
+	if strings.EqualFold(filepath.Ext(filename), ".zip") {
+		return true
+	}
+	magic := make([]byte, len(zipMagic))
+	n, _ := file.ReadAt(magic, 0)
+	return n == len(magic) && bytes.Equal(magic, zipMagic)
+}
+
+// isTableEntry reports whether a zip entry is a CSV or TSV file. Directories and the
+// resource forks macOS adds under __MACOSX/ are not.
+func isTableEntry(f *zip.File) bool {
+	if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") {
+		return false
+	}
+	switch strings.ToLower(path.Ext(f.Name)) {
+	case ".csv", ".tsv", ".tab", ".txt":
+		return true
+	}
+	return false
+}
+
+// openZipEntry opens the entry of a zip archive to parse: the entry named entryName,
+// or else the only CSV or TSV entry. An archive with several CSV or TSV entries
+// needs entryName. Reading the entry fails once more than limit bytes have been
+// decompressed, whatever size the archive claims, so that a zip bomb cannot
+// exhaust memory.
+func openZipEntry(file io.ReaderAt, size int64, entryName string, limit int64) (io.ReadCloser, error) {
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	var entries []*zip.File
+	for _, f := range archive.File {
+		if entryName != "" && f.Name == entryName || entryName == "" && isTableEntry(f) {
+			entries = append(entries, f)
+		}
+	}
+	var names []string
+	for _, f := range archive.File {
+		if isTableEntry(f) {
+			names = append(names, f.Name)
+		}
+	}
+	switch {
+	case entryName != "" && len(entries) == 0:
+		return nil, fmt.Errorf("zip archive has no entry %q (CSV entries: %s)", entryName, strings.Join(names, ", "))
+	case len(entries) == 0:
+		return nil, fmt.Errorf("zip archive has no CSV or TSV entry")
+	case len(entries) > 1:
+		return nil, fmt.Errorf("zip archive has %d CSV entries, select one: %s", len(entries), strings.Join(names, ", "))
+	}
+
+	entry := entries[0]
+	if entry.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("zip entry %q too large: %d bytes (max %d)", entry.Name, entry.UncompressedSize64, limit)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip entry %q: %w", entry.Name, err)
+	}
+	return &limitedReadCloser{Reader: &countingReader{reader: rc, limit: limit}, Closer: rc}, nil
+}
+
+// limitedReadCloser reads through a size limit and closes the underlying reader
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
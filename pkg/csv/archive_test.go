@@ -0,0 +1,115 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeZip writes a zip archive with the given entries and returns its path
+func writeZip(t *testing.T, name string, entries map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for entryName, content := range entries {
+		f, err := w.Create(entryName)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write zip entry: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+	return path
+}
+
+func TestParseFileZip(t *testing.T) {
+	opts := DefaultOptions()
+
+	single := writeZip(t, "data.csv.zip", map[string]string{
+		"data.csv":          ",a,b\nr1,1,2\nr2,3,4\n",
+		"__MACOSX/data.csv": "ignored",
+	})
+	data, err := ParseFile(single, opts)
+	if err != nil {
+		t.Fatalf("ParseFile failed on a single-entry zip: %v", err)
+	}
+	if data.Rows != 2 || data.Columns != 2 || data.Matrix[1][1] != 4 {
+		t.Errorf("unexpected data from zip: %d×%d %v", data.Rows, data.Columns, data.Matrix)
+	}
+
+	// The signature identifies an archive without the .zip extension
+	renamed := filepath.Join(filepath.Dir(single), "data.bin")
+	if err := os.Rename(single, renamed); err != nil {
+		t.Fatalf("Failed to rename zip: %v", err)
+	}
+	if _, err := ParseFile(renamed, opts); err != nil {
+		t.Errorf("ParseFile failed on a zip without extension: %v", err)
+	}
+
+	multi := writeZip(t, "export.zip", map[string]string{
+		"train.csv":  ",a,b\nr1,1,2\nr2,3,4\n",
+		"test.csv":   ",a,b\nr3,5,6\n",
+		"readme.pdf": "not a table",
+	})
+	_, err = ParseFile(multi, opts)
+	if err == nil {
+		t.Fatal("expected an error for a zip with several CSV entries")
+	}
+	if !strings.Contains(err.Error(), "test.csv") || !strings.Contains(err.Error(), "train.csv") {
+		t.Errorf("expected the error to list the entries, got %v", err)
+	}
+
+	opts.ArchiveEntry = "test.csv"
+	data, err = ParseFile(multi, opts)
+	if err != nil {
+		t.Fatalf("ParseFile failed with ArchiveEntry: %v", err)
+	}
+	if data.Rows != 1 || data.RowNames[0] != "r3" {
+		t.Errorf("expected the test.csv entry, got rows %v", data.RowNames)
+	}
+
+	opts.ArchiveEntry = "missing.csv"
+	if _, err := ParseFile(multi, opts); err == nil {
+		t.Error("expected an error for a missing ArchiveEntry")
+	}
+}
+
+func TestOpenZipEntryLimit(t *testing.T) {
+	path := writeZip(t, "bomb.zip", map[string]string{"big.csv": strings.Repeat("0,", 5000)})
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+	file := bytes.NewReader(content)
+
+	if _, err := openZipEntry(file, file.Size(), "", 1000); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Error("expected the declared size to exceed the limit")
+	}
+
+	entry, err := openZipEntry(file, file.Size(), "", 100000)
+	if err != nil {
+		t.Fatalf("openZipEntry failed under the limit: %v", err)
+	}
+	defer func() { _ = entry.Close() }()
+	if content, err := io.ReadAll(entry); err != nil || len(content) != 10000 {
+		t.Errorf("expected 10000 bytes, got %d (%v)", len(content), err)
+	}
+}
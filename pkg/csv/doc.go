@@ -14,13 +14,14 @@
 //   - Automatic column type detection
 //   - Missing value handling
 //   - Large file streaming
+//   - Zip archives holding a CSV file
 //   - Security validation against malicious inputs
 //
 // # Security
 //
 // All file operations include security validations:
 //   - Path traversal prevention
-//   - File size limits (500MB default)
+//   - File size limits (500MB default), also on the decompressed size of zip entries
 //   - Field length limits (10,000 characters)
 //   - Row and column count limits
 //
@@ -44,6 +45,25 @@
 //	opts := csv.EuropeanOptions()
 //	data, err := csv.ParseFile("data.csv", opts)
 //
+// Named presets (us, european, tsv, pipe) give the same options by name:
+//
+//	opts, err := csv.PresetOptions("pipe")
+//
+// A zip archive with one CSV or TSV entry is read like the CSV file itself. When it
+// holds several, name the one to read:
+//
+//	opts.ArchiveEntry = "2024/measurements.csv"
+//	data, err := csv.ParseFile("export.zip", opts)
+//
+// Writing uses the same options, so data parsed with a set of options is written
+// back in the same format:
+//
+//	err := csv.WriteFile("out.csv", data, opts)
+//	err = csv.WriteExcelFile("out.xlsx", data, opts)
+//
+// Set EscapeFormulas when the output is meant to be opened in a spreadsheet, so
+// text cells such as "=SUM(A1:A9)" are not evaluated as formulas.
+//
 // # Performance
 //
 // The package is optimized for both small and large datasets.
@@ -0,0 +1,371 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bitjungle/gopca/pkg/types"
+	"github.com/xuri/excelize/v2"
+)
+
+// FileFormat identifies a tabular file format
+type FileFormat string
+
+const (
+	// FormatCSV is delimiter-separated text, comma by default
+	FormatCSV FileFormat = "csv"
+	// FormatTSV is tab-separated text
+	FormatTSV FileFormat = "tsv"
+	// FormatExcel is an Excel workbook; the first sheet is used
+	FormatExcel FileFormat = "xlsx"
+	// FormatJSON is a JSON object with headers, rowNames and data arrays
+	FormatJSON FileFormat = "json"
+)
+
+// Text encodings supported for delimited input
+const (
+	EncodingUTF8   = "utf-8"
+	EncodingLatin1 = "latin1"
+)
+
+// tableJSON is the JSON representation of a table, matching GoCSV's file data
+type tableJSON struct {
+	Headers     []string   `json:"headers"`
+	IndexHeader string     `json:"indexHeader,omitempty"` // Header of the row name column
+	RowNames    []string   `json:"rowNames,omitempty"`
+	Data        [][]string `json:"data"`
+}
+
+// FormatFromPath infers the file format from the file extension
+func FormatFromPath(filename string) (FileFormat, error) {
+	if types.IsTSVFile(filename) {
+		return FormatTSV, nil
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatExcel, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported file format %q (supported: .csv, .tsv, .xlsx, .json)", filepath.Ext(filename))
+	}
+}
+
+// ReadTableFile reads a table in the given format as string data. Numeric values
+// written with a comma decimal separator are normalized to use a dot.
+func ReadTableFile(filename string, format FileFormat, opts Options) (*Data, error) {
+	opts.ParseMode = ParseString
+
+	var data *Data
+	var err error
+	switch format {
+	case FormatCSV, FormatTSV:
+		if format == FormatTSV {
+			opts.Delimiter = '\t'
+		}
+		data, err = NewReader(opts).ReadFile(filename)
+	case FormatExcel:
+		data, err = readExcelFile(filename, opts)
+	case FormatJSON:
+		data, err = readJSONFile(filename)
+	default:
+		return nil, fmt.Errorf("unsupported file format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DecimalSeparator == ',' {
+		convertDecimalSeparator(data.StringData, ',', '.')
+	}
+	return data, nil
+}
+
+// WriteTableFile writes string data in the given format. Numeric values are
+// written with the decimal separator from opts.
+func WriteTableFile(filename string, format FileFormat, data *Data, opts Options) error {
+	if data == nil || len(data.StringData) == 0 {
+		return fmt.Errorf("no data to write")
+	}
+
+	opts.HasRowNames = len(data.RowNames) > 0
+	switch format {
+	case FormatCSV, FormatTSV:
+		if format == FormatTSV {
+			opts.Delimiter = '\t'
+		}
+		out := *data
+		if opts.DecimalSeparator == ',' {
+			out.StringData = copyStringData(data.StringData)
+			convertDecimalSeparator(out.StringData, '.', ',')
+		}
+		return WriteFile(filename, &out, opts)
+	case FormatExcel:
+		opts.HasHeaders = len(data.Headers) > 0
+		return WriteExcelFile(filename, data, opts)
+	case FormatJSON:
+		return writeJSONFile(filename, data)
+	default:
+		return fmt.Errorf("unsupported file format: %s", format)
+	}
+}
+
+// decodeInput wraps input so that it yields UTF-8 text, dropping a UTF-8 byte order mark
+func decodeInput(input io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToLower(encoding) {
+	case "", EncodingUTF8, "utf8":
+		buffered := bufio.NewReader(input)
+		if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+			_, _ = buffered.Discard(3)
+		}
+		return buffered, nil
+	case EncodingLatin1, "iso-8859-1":
+		raw, err := io.ReadAll(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		// Latin-1 bytes map directly to the first 256 Unicode code points
+		decoded := make([]byte, 0, len(raw))
+		for _, b := range raw {
+			decoded = utf8.AppendRune(decoded, rune(b))
+		}
+		return bytes.NewReader(decoded), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q (supported: %s, %s)", encoding, EncodingUTF8, EncodingLatin1)
+	}
+}
+
+// readExcelFile reads the first sheet of an Excel workbook as string data
+func readExcelFile(filename string, opts Options) (*Data, error) {
+	f, err := excelize.OpenFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("no sheets found in Excel file")
+	}
+
+	// Raw values avoid number formatting changing the stored precision
+	records, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet %s: %w", sheets[0], err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no data found in sheet %s", sheets[0])
+	}
+
+	// Excel omits trailing empty cells, so pad rows to a common width
+	width := 0
+	for _, record := range records {
+		if len(record) > width {
+			width = len(record)
+		}
+	}
+	for i, record := range records {
+		for len(record) < width {
+			record = append(record, "")
+		}
+		records[i] = record
+	}
+
+	data, err := NewReader(opts).parseAsString(records, nil)
+	if err != nil {
+		return nil, err
+	}
+	if opts.HasHeaders && opts.HasRowNames {
+		data.IndexHeader = records[0][0]
+	}
+	return data, nil
+}
+
+// WriteExcelFile writes data to the first sheet of a new Excel workbook. It is the
+// Excel counterpart of WriteFile: headers and row names are written as for CSV,
+// numbers are stored as numeric cells rounded to opts.Precision significant digits
+// (unless negative), and missing values are left empty. String data is parsed as
+// numbers with a period as decimal separator; other text is stored as text, which
+// Excel never evaluates as a formula.
+func WriteExcelFile(filename string, data *Data, opts Options) error {
+	if data == nil {
+		return fmt.Errorf("no data to write")
+	}
+
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+
+	sheet := f.GetSheetName(0)
+	colOffset := 0
+	if opts.HasRowNames && len(data.RowNames) > 0 {
+		colOffset = 1
+	}
+
+	setString := func(col, row int, value string) error {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+		return f.SetCellStr(sheet, cell, value)
+	}
+	setFloat := func(col, row int, value float64) error {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+		return f.SetCellFloat(sheet, cell, RoundFloat(value, opts.Precision), -1, 64)
+	}
+
+	row := 1
+	if opts.HasHeaders && len(data.Headers) > 0 {
+		if colOffset > 0 && data.IndexHeader != "" {
+			if err := setString(1, row, data.IndexHeader); err != nil {
+				return fmt.Errorf("failed to write header: %w", err)
+			}
+		}
+		for j, header := range data.Headers {
+			if err := setString(j+1+colOffset, row, header); err != nil {
+				return fmt.Errorf("failed to write header: %w", err)
+			}
+		}
+		row++
+	}
+
+	nRows := len(data.StringData)
+	if nRows == 0 {
+		nRows = len(data.Matrix)
+	}
+	for i := 0; i < nRows; i++ {
+		if colOffset > 0 && i < len(data.RowNames) {
+			if err := setString(1, row, data.RowNames[i]); err != nil {
+				return fmt.Errorf("failed to write row name %d: %w", i+1, err)
+			}
+		}
+
+		if len(data.StringData) > 0 {
+			for j, value := range data.StringData[i] {
+				if value == "" {
+					continue
+				}
+				// Store numbers as numbers so they stay numeric in Excel
+				var err error
+				if v, perr := strconv.ParseFloat(value, 64); perr == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+					err = setFloat(j+1+colOffset, row, v)
+				} else {
+					err = setString(j+1+colOffset, row, value)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to write row %d: %w", i+1, err)
+				}
+			}
+		} else {
+			for j, v := range data.Matrix[i] {
+				missing := data.MissingMask != nil && i < len(data.MissingMask) &&
+					j < len(data.MissingMask[i]) && data.MissingMask[i][j]
+				if missing || math.IsNaN(v) || math.IsInf(v, 0) {
+					continue
+				}
+				if err := setFloat(j+1+colOffset, row, v); err != nil {
+					return fmt.Errorf("failed to write row %d: %w", i+1, err)
+				}
+			}
+		}
+		row++
+	}
+
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save Excel file: %w", err)
+	}
+	return nil
+}
+
+// readJSONFile reads a table stored as a JSON object with headers, rowNames and data
+func readJSONFile(filename string) (*Data, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var table tableJSON
+	if err := json.Unmarshal(content, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if len(table.Data) == 0 {
+		return nil, fmt.Errorf("no data rows found")
+	}
+	if len(table.RowNames) > 0 && len(table.RowNames) != len(table.Data) {
+		return nil, fmt.Errorf("JSON has %d row names but %d data rows", len(table.RowNames), len(table.Data))
+	}
+
+	return &Data{
+		Headers:     table.Headers,
+		IndexHeader: table.IndexHeader,
+		RowNames:    table.RowNames,
+		StringData:  table.Data,
+		Rows:        len(table.Data),
+		Columns:     len(table.Data[0]),
+	}, nil
+}
+
+// writeJSONFile writes string data as a JSON object with headers, rowNames and data
+func writeJSONFile(filename string, data *Data) error {
+	content, err := json.MarshalIndent(tableJSON{
+		Headers:     data.Headers,
+		IndexHeader: data.IndexHeader,
+		RowNames:    data.RowNames,
+		Data:        data.StringData,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// convertDecimalSeparator replaces the decimal separator of numeric values in place.
+// Values that are not numbers are left unchanged.
+func convertDecimalSeparator(values [][]string, from, to rune) {
+	for _, row := range values {
+		for j, value := range row {
+			if !strings.ContainsRune(value, from) {
+				continue
+			}
+			converted := replaceDecimalSeparator(value, from, to)
+			dotForm := value
+			if from == ',' {
+				dotForm = converted
+			}
+			if _, err := strconv.ParseFloat(strings.TrimSpace(dotForm), 64); err == nil {
+				row[j] = converted
+			}
+		}
+	}
+}
+
+// copyStringData returns a deep copy of string data
+func copyStringData(values [][]string) [][]string {
+	result := make([][]string, len(values))
+	for i, row := range values {
+		result[i] = append([]string(nil), row...)
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"strconv"
+	"strings"
+)
+
+// headerSampleRows is the number of records DetectHeader is given by the reader
+const headerSampleRows = 20
+
+// DetectHeader guesses whether the first record of sample is a header row. It is a
+// header when none of its fields is a number while the following records are mostly
+// numbers in at least one column, so that categorical columns do not hide the header
+// of numeric ones. Empty fields, such as the corner above a row name column, and the
+// missing value markers of DefaultOptions are ignored. A sample of a single record is
+// a header when none of its fields is a number.
+func DetectHeader(sample [][]string) bool {
+	if len(sample) == 0 {
+		return false
+	}
+
+	isNull := make(map[string]bool)
+	for _, v := range DefaultOptions().NullValues {
+		isNull[v] = true
+	}
+
+	names := 0
+	for _, field := range sample[0] {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if isNumericField(field) {
+			return false
+		}
+		names++
+	}
+	if names == 0 {
+		return false
+	}
+	if len(sample) == 1 {
+		return true
+	}
+
+	// Count the numbers below the first row per column
+	numeric := make([]int, len(sample[0]))
+	total := make([]int, len(sample[0]))
+	for _, record := range sample[1:] {
+		for j, field := range record[:min(len(record), len(numeric))] {
+			field = strings.TrimSpace(field)
+			if isNull[field] {
+				continue
+			}
+			total[j]++
+			if isNumericField(field) {
+				numeric[j]++
+			}
+		}
+	}
+	for j := range numeric {
+		if 2*numeric[j] > total[j] {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumericField reports whether a field is a number, with a period or a comma as
+// the decimal separator
+func isNumericField(field string) bool {
+	if _, err := strconv.ParseFloat(field, 64); err == nil {
+		return true
+	}
+	if strings.Count(field, ",") == 1 && !strings.Contains(field, ".") {
+		_, err := strconv.ParseFloat(strings.Replace(field, ",", ".", 1), 64)
+		return err == nil
+	}
+	return false
+}
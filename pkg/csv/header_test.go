@@ -0,0 +1,65 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDetectHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample [][]string
+		want   bool
+	}{
+		{"header over numbers", [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}}, true},
+		{"numbers only", [][]string{{"1", "2"}, {"3", "4"}}, false},
+		{"row name corner", [][]string{{"", "x", "y"}, {"s1", "1.5", "2"}, {"s2", "NA", "3"}}, true},
+		{"mixed columns", [][]string{{"length", "species"}, {"5.1", "setosa"}, {"4.9", "setosa"}}, true},
+		{"comma decimals", [][]string{{"1,5", "2,5"}, {"3,5", "4,5"}}, false},
+		{"numeric column name", [][]string{{"id", "2020"}, {"1", "5"}}, false},
+		{"text data", [][]string{{"a", "b"}, {"x", "y"}}, false},
+		{"single row of names", [][]string{{"a", "b"}}, true},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectHeader(tt.sample); got != tt.want {
+				t.Errorf("DetectHeader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadAutoHeaders(t *testing.T) {
+	opts := DefaultOptions()
+	opts.HasRowNames = false
+	opts.AutoHeaders = true
+
+	// A clear header row becomes the column names
+	data, err := NewReader(opts).Read(strings.NewReader("height,weight\n1.8,75\n1.6,60\n1.7,68\n"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !data.HeaderDetected || data.Rows != 3 || !slices.Equal(data.Headers, []string{"height", "weight"}) {
+		t.Errorf("expected a detected header and 3 rows, got %v, %d rows, headers %v",
+			data.HeaderDetected, data.Rows, data.Headers)
+	}
+
+	// In a headerless numeric file the first row is data
+	opts.HasHeaders = true
+	data, err = NewReader(opts).Read(strings.NewReader("1.8,75\n1.6,60\n1.7,68\n"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if data.HeaderDetected || data.Rows != 3 || data.Matrix[0][0] != 1.8 {
+		t.Errorf("expected 3 data rows starting with 1.8, got header %v, %d rows, %v",
+			data.HeaderDetected, data.Rows, data.Matrix)
+	}
+}
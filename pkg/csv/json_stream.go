@@ -0,0 +1,185 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+// WriteOutputJSON writes output to w as JSON, the same bytes that json.Marshal gives
+// when compact and json.MarshalIndent with two-space indentation otherwise. The
+// scores are encoded one observation at a time as they are written, so that the
+// encoded score matrix, the largest part of the output for many observations, is
+// never held in memory as a whole. Every other member is marshaled from its field as
+// encoding/json would, so fields added to the output types are written too.
+func WriteOutputJSON(w io.Writer, output *types.PCAOutputData, compact bool) error {
+	jw := &jsonWriter{w: bufio.NewWriter(w), compact: compact}
+
+	samples := &output.Results.Samples
+	sampleMembers := structMembers(reflect.ValueOf(samples).Elem(), map[string]func(string){
+		"scores": func(indent string) { jw.writeRows(samples.Scores, indent) },
+	})
+	resultMembers := structMembers(reflect.ValueOf(&output.Results).Elem(), map[string]func(string){
+		"samples": func(indent string) { jw.writeObject(sampleMembers, indent) },
+	})
+	members := structMembers(reflect.ValueOf(output).Elem(), map[string]func(string){
+		"results": func(indent string) { jw.writeObject(resultMembers, indent) },
+	})
+
+	jw.writeObject(members, "")
+	if jw.err != nil {
+		return jw.err
+	}
+	return jw.w.Flush()
+}
+
+// structMembers returns the members that encoding/json writes for the addressable
+// struct v, with the names and omitempty and omitzero options of the field tags. The
+// members named in write are written by their function instead of being marshaled.
+// Embedded structs are not flattened, which the output types do not need.
+func structMembers(v reflect.Value, write map[string]func(indent string)) []jsonMember {
+	t := v.Type()
+	members := make([]jsonMember, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		value := v.Field(i)
+		optionList := strings.Split(options, ",")
+		if (slices.Contains(optionList, "omitempty") && isEmptyJSONValue(value)) ||
+			(slices.Contains(optionList, "omitzero") && value.IsZero()) {
+			continue
+		}
+		if w, ok := write[name]; ok {
+			members = append(members, jsonMember{name: name, write: w})
+		} else {
+			// The address keeps methods with pointer receivers, as json.Marshal of a pointer does
+			members = append(members, jsonMember{name: name, value: value.Addr().Interface()})
+		}
+	}
+	return members
+}
+
+// isEmptyJSONValue reports whether omitempty leaves out v, as in encoding/json; a
+// struct is never empty
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// jsonMember is a member of a JSON object, with its value either marshaled as a whole
+// or written by write at the given indentation
+type jsonMember struct {
+	name  string
+	value any
+	write func(indent string)
+}
+
+// jsonWriter writes JSON piece by piece, formatted as json.Marshal does when compact
+// and as json.MarshalIndent with two-space indentation otherwise. It keeps the first
+// marshaling error, after which it writes nothing; a bufio.Writer keeps its first
+// write error and returns it from Flush.
+type jsonWriter struct {
+	w       *bufio.Writer
+	compact bool
+	err     error
+}
+
+// writeValue writes v marshaled as a whole, with indent as the prefix of its lines
+// after the first
+func (jw *jsonWriter) writeValue(v any, indent string) {
+	if jw.err != nil {
+		return
+	}
+	var b []byte
+	if jw.compact {
+		b, jw.err = json.Marshal(v)
+	} else {
+		b, jw.err = json.MarshalIndent(v, indent, "  ")
+	}
+	_, _ = jw.w.Write(b)
+}
+
+// newline starts a new line at the given indentation, unless compact
+func (jw *jsonWriter) newline(indent string) {
+	if !jw.compact {
+		_ = jw.w.WriteByte('\n')
+		_, _ = jw.w.WriteString(indent)
+	}
+}
+
+// writeObject writes an object of members whose opening brace is at indent
+func (jw *jsonWriter) writeObject(members []jsonMember, indent string) {
+	if len(members) == 0 {
+		_, _ = jw.w.WriteString("{}")
+		return
+	}
+	_ = jw.w.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			_ = jw.w.WriteByte(',')
+		}
+		jw.newline(indent + "  ")
+		jw.writeValue(m.name, "")
+		_ = jw.w.WriteByte(':')
+		if !jw.compact {
+			_ = jw.w.WriteByte(' ')
+		}
+		if m.write != nil {
+			m.write(indent + "  ")
+		} else {
+			jw.writeValue(m.value, indent+"  ")
+		}
+	}
+	jw.newline(indent)
+	_ = jw.w.WriteByte('}')
+}
+
+// writeRows writes a matrix one row at a time, as null when it is nil
+func (jw *jsonWriter) writeRows(rows types.Matrix, indent string) {
+	switch {
+	case rows == nil:
+		_, _ = jw.w.WriteString("null")
+		return
+	case len(rows) == 0:
+		_, _ = jw.w.WriteString("[]")
+		return
+	}
+	_ = jw.w.WriteByte('[')
+	for i, row := range rows {
+		if i > 0 {
+			_ = jw.w.WriteByte(',')
+		}
+		jw.newline(indent + "  ")
+		jw.writeValue(row, indent+"  ")
+	}
+	jw.newline(indent)
+	_ = jw.w.WriteByte(']')
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bitjungle/gopca/internal/core"
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestWriteOutputJSON(t *testing.T) {
+	// 5000 observations of 6 variables, fitted and converted as analyze does
+	rng := rand.New(rand.NewSource(3))
+	data := &Data{Headers: []string{"a", "b", "c", "d", "e", "f"}, Rows: 5000, Columns: 6}
+	for i := 0; i < data.Rows; i++ {
+		row := make([]float64, data.Columns)
+		for j := range row {
+			row[j] = rng.NormFloat64() * float64(j+1)
+		}
+		data.Matrix = append(data.Matrix, row)
+		data.RowNames = append(data.RowNames, fmt.Sprintf("obs%d", i+1))
+	}
+	config := types.PCAConfig{Components: 3, Method: "svd", MeanCenter: true}
+	result, err := core.NewPCAEngine().Fit(data.Matrix, config)
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	output := ConvertToPCAOutputData(result, data, true, config, nil,
+		map[string][]string{"group": make([]string, data.Rows)}, nil)
+
+	emptyScores := *output
+	emptyScores.Results.Samples.Scores = types.Matrix{}
+	noScores := *output
+	noScores.Results.Samples.Scores = nil
+
+	// Every optional member present, with strings that are escaped
+	allMembers := *output
+	allMembers.Schema = "https://example.com/schema.json?a=1&b=<2>"
+	allMembers.Results.Samples.ScoreScale = []float64{1.5, 2, 0.25}
+	allMembers.Results.Samples.CarriedColumns = &types.PreservedColumns{NumericTarget: map[string][]float64{"y": {1, 2}}}
+	allMembers.Results.Samples.Influence = []types.InfluencePoint{{T2: 1.2, Q: 0.3, Status: "normal"}}
+	allMembers.Eigencorrelations = &types.EigencorrelationResult{
+		Correlations: map[string][]float64{"a": {0.9}},
+		Variables:    []string{"a"},
+		Components:   []string{"PC1"},
+		Method:       "pearson",
+	}
+	allMembers.SupplementaryGroups = &types.SupplementaryGroups{
+		Column: "group", Categories: []string{"x"}, Counts: []int{2}, Scores: types.Matrix{{0.1, -0.2, 0.3}},
+	}
+
+	for _, tt := range []struct {
+		name   string
+		output *types.PCAOutputData
+	}{
+		{"scores", output}, {"empty scores", &emptyScores}, {"no scores", &noScores}, {"all members", &allMembers},
+	} {
+		for _, compact := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s compact=%v", tt.name, compact), func(t *testing.T) {
+				var want []byte
+				if compact {
+					want, err = json.Marshal(tt.output)
+				} else {
+					want, err = json.MarshalIndent(tt.output, "", "  ")
+				}
+				if err != nil {
+					t.Fatalf("Marshal failed: %v", err)
+				}
+				var got bytes.Buffer
+				if err := WriteOutputJSON(&got, tt.output, compact); err != nil {
+					t.Fatalf("WriteOutputJSON failed: %v", err)
+				}
+				if !bytes.Equal(got.Bytes(), want) {
+					i := 0
+					for i < min(got.Len(), len(want)) && got.Bytes()[i] == want[i] {
+						i++
+					}
+					t.Fatalf("streamed output differs from the buffered output at byte %d of %d: got %q, want %q",
+						i, len(want), got.Bytes()[i:min(i+40, got.Len())], want[i:min(i+40, len(want))])
+				}
+			})
+		}
+	}
+	if len(output.Results.Samples.Scores) != data.Rows {
+		t.Errorf("expected the scores of the output to be kept, got %d rows", len(output.Results.Samples.Scores))
+	}
+}
+
+// jsonTagged has fields with each kind of tag that structMembers handles
+type jsonTagged struct {
+	Plain     string
+	Named     int                `json:"named"`
+	Empty     []float64          `json:"empty,omitempty"`
+	Full      []float64          `json:"full,omitempty"`
+	Zero      time.Time          `json:"zero,omitzero"`
+	Skipped   string             `json:"-"`
+	Dash      string             `json:"-,"`
+	Nested    struct{ A int }    `json:"nested,omitempty"`
+	Nil       *types.JSONFloat64 `json:"nil,omitempty"`
+	Marshaler types.JSONFloat64  `json:"marshaler"`
+	hidden    bool
+}
+
+func TestStructMembers(t *testing.T) {
+	v := jsonTagged{Plain: "p", Named: 3, Full: []float64{1.5}, Skipped: "s", Dash: "d",
+		Marshaler: types.JSONFloat64(math.NaN()), hidden: true}
+	for _, compact := range []bool{false, true} {
+		var want []byte
+		var err error
+		if compact {
+			want, err = json.Marshal(&v)
+		} else {
+			want, err = json.MarshalIndent(&v, "", "  ")
+		}
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var got bytes.Buffer
+		jw := &jsonWriter{w: bufio.NewWriter(&got), compact: compact}
+		jw.writeObject(structMembers(reflect.ValueOf(&v).Elem(), nil), "")
+		if jw.err != nil {
+			t.Fatalf("writeObject failed: %v", jw.err)
+		}
+		if err := jw.w.Flush(); err != nil {
+			t.Fatalf("Flush failed: %v", err)
+		}
+		if got.String() != string(want) {
+			t.Errorf("compact=%v: got %s, want %s", compact, got.String(), want)
+		}
+	}
+}
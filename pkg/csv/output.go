@@ -18,6 +18,7 @@ import (
 // ExportMetadata contains optional metadata for PCA export
 type ExportMetadata struct {
 	InputFilename string   // Original input file name
+	InputHash     string   // SHA-256 checksum of the input file, hex encoded (optional)
 	Description   string   // User-provided description
 	Tags          []string // User-defined tags
 }
@@ -48,6 +49,7 @@ func ConvertToPCAOutputDataWithMetadata(result *types.PCAResult, data *Data, inc
 	if exportMeta != nil && exportMeta.InputFilename != "" {
 		dataSource = &types.DataSource{
 			Filename:      exportMeta.InputFilename,
+			Hash:          exportMeta.InputHash,
 			NRowsOriginal: data.Rows,
 			NColsOriginal: data.Columns,
 		}
@@ -77,6 +79,8 @@ func ConvertToPCAOutputDataWithMetadata(result *types.PCAResult, data *Data, inc
 			MissingStrategy: config.MissingStrategy,
 			ExcludedRows:    config.ExcludedRows,
 			ExcludedColumns: config.ExcludedColumns,
+			DroppedColumns:  config.DroppedColumns,
+			ScoresOnly:      config.ScoresOnly,
 		},
 	}
 
@@ -105,6 +109,13 @@ func ConvertToPCAOutputDataWithMetadata(result *types.PCAResult, data *Data, inc
 		VectorNorm:    config.VectorNorm,
 		Parameters:    types.PreprocessingParams{},
 	}
+	if config.VectorNorm {
+		preprocessingInfo.VectorNormType = config.VectorNormType
+	}
+	if config.RobustScale {
+		preprocessingInfo.RobustCenter = config.RobustCenter
+		preprocessingInfo.RobustScaleStat = config.RobustScaleStat
+	}
 
 	// Add preprocessing parameters if preprocessor was used
 	if preprocessor != nil {
@@ -124,18 +135,37 @@ func ConvertToPCAOutputDataWithMetadata(result *types.PCAResult, data *Data, inc
 		CumulativeVariance:     result.CumulativeVar,
 		ComponentLabels:        result.ComponentLabels,
 		FeatureLabels:          data.Headers,
+		ComponentStatus:        result.ComponentStatus,
+		VarianceCurve:          result.VarianceCurve,
+	}
+	if result.Method != "kernel" && len(result.Loadings) > 0 {
+		modelComponents.VariableImportance = core.VariableImportance(result.Loadings, result.ExplainedVarRatio)
+		modelComponents.ComponentInterpretation = core.TopLoadings(result.Loadings, data.Headers,
+			core.InterpretationTopN)
+	}
+	eigenvalues := result.AllEigenvalues
+	if len(eigenvalues) == 0 {
+		eigenvalues = result.ExplainedVar
+	}
+	modelComponents.EffectiveDimensionality = core.EffectiveDimensionality(eigenvalues)
+	if n := len(result.CumulativeVar); n > 0 {
+		modelComponents.SignalCaptured = result.CumulativeVar[n-1]
 	}
 
 	// Create results data
 	resultsData := types.ResultsData{
 		Samples: types.SamplesResults{
-			Names:  data.RowNames,
-			Scores: result.Scores,
+			Names:      data.RowNames,
+			Scores:     result.Scores,
+			ScoreScale: result.ScoreScale,
+			Influence:  result.Influence,
 		},
 	}
 
-	// Add metrics if requested (skip for kernel PCA as it doesn't have loadings)
-	if includeMetrics && result.Method != "kernel" && data.Matrix != nil {
+	// Add metrics if requested (skip for kernel PCA as it doesn't have loadings).
+	// Metrics already in the result are used as they are, since they may have been
+	// calculated from scores that were normalized afterwards.
+	if includeMetrics && result.Method != "kernel" && len(result.Metrics) == 0 && data.Matrix != nil {
 		metrics, err := core.CalculateMetricsFromPCAResult(result, data.Matrix)
 		if err == nil && metrics != nil {
 			metricsData := &types.MetricsData{
@@ -152,7 +182,7 @@ func ConvertToPCAOutputDataWithMetadata(result *types.PCAResult, data *Data, inc
 			}
 			resultsData.Samples.Metrics = metricsData
 		}
-	} else if includeMetrics && result.Method == "kernel" {
+	} else if includeMetrics {
 		// For kernel PCA, we can't calculate RSS but we can still calculate some metrics if we have them in the result
 		if len(result.Metrics) > 0 {
 			metricsData := &types.MetricsData{
@@ -189,13 +219,74 @@ func ConvertToPCAOutputDataWithMetadata(result *types.PCAResult, data *Data, inc
 	}
 
 	return &types.PCAOutputData{
-		Schema:            "https://github.com/bitjungle/gopca/schemas/v1/pca-output.schema.json",
-		Metadata:          metadata,
-		Preprocessing:     preprocessingInfo,
-		Model:             modelComponents,
-		Results:           resultsData,
-		Diagnostics:       diagnostics,
-		Eigencorrelations: result.Eigencorrelations,
-		PreservedColumns:  preservedColumns,
+		Schema:              "https://github.com/bitjungle/gopca/schemas/v1/pca-output.schema.json",
+		Metadata:            metadata,
+		Preprocessing:       preprocessingInfo,
+		Model:               modelComponents,
+		Results:             resultsData,
+		Diagnostics:         diagnostics,
+		Eigencorrelations:   result.Eigencorrelations,
+		PreservedColumns:    preservedColumns,
+		SupplementaryGroups: result.SupplementaryGroups,
+	}
+}
+
+// RoundOutputData rounds the scores, loadings, explained variance, variable importance,
+// sample metrics and supplementary scores of output to precision significant digits,
+// replacing them with rounded copies. Preprocessing parameters keep full precision so
+// the model transforms new data exactly. Negative precision leaves output unchanged.
+func RoundOutputData(output *types.PCAOutputData, precision int) {
+	if precision < 0 {
+		return
+	}
+
+	model := &output.Model
+	model.Loadings = roundMatrix(model.Loadings, precision)
+	model.ExplainedVariance = roundValues(model.ExplainedVariance, precision)
+	model.ExplainedVarianceRatio = roundValues(model.ExplainedVarianceRatio, precision)
+	model.CumulativeVariance = roundValues(model.CumulativeVariance, precision)
+	model.VariableImportance = roundValues(model.VariableImportance, precision)
+	model.EffectiveDimensionality = RoundFloat(model.EffectiveDimensionality, precision)
+	model.SignalCaptured = RoundFloat(model.SignalCaptured, precision)
+
+	samples := &output.Results.Samples
+	samples.Scores = roundMatrix(samples.Scores, precision)
+	if metrics := samples.Metrics; metrics != nil {
+		samples.Metrics = &types.MetricsData{
+			HotellingT2: roundValues(metrics.HotellingT2, precision),
+			Mahalanobis: roundValues(metrics.Mahalanobis, precision),
+			RSS:         roundValues(metrics.RSS, precision),
+			IsOutlier:   metrics.IsOutlier,
+		}
+	}
+
+	if groups := output.SupplementaryGroups; groups != nil {
+		rounded := *groups
+		rounded.Scores = roundMatrix(groups.Scores, precision)
+		output.SupplementaryGroups = &rounded
+	}
+}
+
+// roundValues returns a copy of values rounded to precision significant digits
+func roundValues(values []float64, precision int) []float64 {
+	if values == nil {
+		return nil
+	}
+	rounded := make([]float64, len(values))
+	for i, v := range values {
+		rounded[i] = RoundFloat(v, precision)
+	}
+	return rounded
+}
+
+// roundMatrix returns a copy of m rounded to precision significant digits
+func roundMatrix(m types.Matrix, precision int) types.Matrix {
+	if m == nil {
+		return nil
+	}
+	rounded := make(types.Matrix, len(m))
+	for i, row := range m {
+		rounded[i] = roundValues(row, precision)
 	}
+	return rounded
 }
@@ -0,0 +1,49 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// formatPresets maps the name of each format preset to the options it returns
+var formatPresets = map[string]func() Options{
+	"us":       DefaultOptions,
+	"european": EuropeanOptions,
+	"tsv":      TabDelimitedOptions,
+	"pipe":     PipeDelimitedOptions,
+}
+
+// PipeDelimitedOptions returns options for pipe-delimited files with dot decimals
+func PipeDelimitedOptions() Options {
+	opts := DefaultOptions()
+	opts.Delimiter = '|'
+	return opts
+}
+
+// PresetNames returns the names of the format presets in alphabetical order
+func PresetNames() []string {
+	return slices.Sorted(maps.Keys(formatPresets))
+}
+
+// PresetOptions returns the options of a named format preset:
+//   - us: comma delimiter, dot decimal (DefaultOptions)
+//   - european: semicolon delimiter, comma decimal (EuropeanOptions)
+//   - tsv: tab delimiter, dot decimal (TabDelimitedOptions)
+//   - pipe: pipe delimiter, dot decimal (PipeDelimitedOptions)
+//
+// Names are case-insensitive.
+func PresetOptions(name string) (Options, error) {
+	preset, ok := formatPresets[strings.ToLower(name)]
+	if !ok {
+		return Options{}, fmt.Errorf("unknown format preset %q: must be one of %s", name, strings.Join(PresetNames(), ", "))
+	}
+	return preset(), nil
+}
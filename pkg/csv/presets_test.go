@@ -0,0 +1,48 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPresetOptions(t *testing.T) {
+	samples := map[string]string{
+		"us":       "id,A,B\nr1,1.5,2\nr2,3,4.25\n",
+		"european": "id;A;B\nr1;1,5;2\nr2;3;4,25\n",
+		"tsv":      "id\tA\tB\nr1\t1.5\t2\nr2\t3\t4.25\n",
+		"pipe":     "id|A|B\nr1|1.5|2\nr2|3|4.25\n",
+	}
+	if names := PresetNames(); !slices.Equal(names, []string{"european", "pipe", "tsv", "us"}) {
+		t.Fatalf("unexpected preset names %v", names)
+	}
+
+	for _, name := range PresetNames() {
+		t.Run(name, func(t *testing.T) {
+			opts, err := PresetOptions(strings.ToUpper(name))
+			if err != nil {
+				t.Fatalf("PresetOptions failed: %v", err)
+			}
+			data, err := NewReader(opts).Read(strings.NewReader(samples[name]))
+			if err != nil {
+				t.Fatalf("parsing a %s sample failed: %v", name, err)
+			}
+			want := [][]float64{{1.5, 2}, {3, 4.25}}
+			for i := range want {
+				if !slices.Equal(data.Matrix[i], want[i]) {
+					t.Errorf("row %d: expected %v, got %v", i, want[i], data.Matrix[i])
+				}
+			}
+		})
+	}
+
+	if _, err := PresetOptions("klingon"); err == nil || !strings.Contains(err.Error(), "european, pipe, tsv, us") {
+		t.Errorf("expected an error listing the presets, got %v", err)
+	}
+}
@@ -9,14 +9,18 @@ package csv
 import (
 	"encoding/csv"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"math"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/bitjungle/gopca/pkg/security"
 	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/stat"
 )
 
 // Security limits for CSV parsing
@@ -30,7 +34,9 @@ func NewReader(opts Options) *Reader {
 	return &Reader{opts: opts}
 }
 
-// ReadFile reads and parses a CSV file with security validations
+// ReadFile reads and parses a CSV file with security validations. A zip archive
+// (by .zip extension or signature) is read through its single CSV or TSV entry, or
+// the entry named by Options.ArchiveEntry.
 func (r *Reader) ReadFile(filename string) (*Data, error) {
 	// Validate file path for security
 	if err := r.validateFilePath(filename); err != nil {
@@ -53,14 +59,61 @@ func (r *Reader) ReadFile(filename string) (*Data, error) {
 		return nil, fmt.Errorf("file too large: %d bytes (max %d)", info.Size(), MaxFileSize)
 	}
 
+	// A zip archive is read through its CSV entry
+	if isZipFile(filename, file) {
+		entry, err := openZipEntry(file, info.Size(), r.opts.ArchiveEntry, MaxFileSize)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = entry.Close() }()
+		return r.Read(entry)
+	}
+
 	return r.Read(file)
 }
 
+// ReadAllLimited reads all of input into memory, failing as soon as it exceeds
+// MaxFileSize bytes. It is meant for input whose size cannot be checked in
+// advance, such as standard input, which also cannot be read twice.
+func ReadAllLimited(input io.Reader) ([]byte, error) {
+	return readAllLimited(input, MaxFileSize)
+}
+
+// readAllLimited reads all of input, failing once it exceeds limit bytes
+func readAllLimited(input io.Reader, limit int64) ([]byte, error) {
+	return io.ReadAll(&countingReader{reader: input, limit: limit})
+}
+
+// countingReader counts the bytes read through it and fails once more than limit
+// bytes have been read
+type countingReader struct {
+	reader io.Reader
+	count  int64
+	limit  int64
+}
+
+// Read implements io.Reader
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	if c.count > c.limit {
+		return n, fmt.Errorf("input too large: more than %d bytes", c.limit)
+	}
+	return n, err
+}
+
 // Read parses CSV data from an io.Reader
 func (r *Reader) Read(input io.Reader) (*Data, error) {
-	reader := csv.NewReader(input)
-	reader.Comma = r.opts.Delimiter
-	reader.TrimLeadingSpace = true
+	input, err := decodeInput(input, r.opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	input, err = types.SkipLines(input, r.opts.CommentPrefix, r.opts.SkipBlankLines)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := types.NewCSVReader(input, r.opts.Delimiter)
 	reader.FieldsPerRecord = -1 // Allow variable fields initially
 	reader.ReuseRecord = r.opts.StreamingMode
 
@@ -72,7 +125,6 @@ func (r *Reader) Read(input io.Reader) (*Data, error) {
 
 	// Read all records or stream based on options
 	var records [][]string
-	var err error
 
 	if r.opts.StreamingMode {
 		// Stream processing for large files
@@ -102,12 +154,9 @@ func (r *Reader) Read(input io.Reader) (*Data, error) {
 	}
 
 	// Validate field lengths
-	for i, record := range records {
-		for j, field := range record {
-			if err := r.validateField(field); err != nil {
-				return nil, fmt.Errorf("row %d, column %d: %w", i+1, j+1, err)
-			}
-		}
+	records, fieldWarnings, err := r.limitFieldLengths(records)
+	if err != nil {
+		return nil, err
 	}
 
 	// Skip rows if needed
@@ -119,17 +168,239 @@ func (r *Reader) Read(input io.Reader) (*Data, error) {
 		records = records[r.opts.SkipRows:]
 	}
 
+	// Decide whether the first row holds column names
+	if r.opts.AutoHeaders {
+		r = &Reader{opts: r.opts}
+		r.opts.HasHeaders = DetectHeader(records[:min(len(records), headerSampleRows)])
+	}
+
+	// Trim whitespace before type detection, so that " 3.14 " is a number and a
+	// " species " header matches "species"
+	trimRecords(records, r.opts.HasHeaders, r.opts.TrimHeaders, r.opts.TrimFields)
+
+	// Pad or truncate rows whose width differs from the header
+	records, warnings, err := types.RepairRaggedRows(records, r.opts.RaggedRows, r.opts.NullValues)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(fieldWarnings, warnings...)
+
+	// Join composite keys into a leading row name column
+	parser := r
+	if len(r.opts.IndexColumns) > 0 || len(r.opts.IndexColumnNames) > 0 {
+		records, err = r.mergeIndexColumns(records)
+		if err != nil {
+			return nil, err
+		}
+		parser = &Reader{opts: r.opts}
+		parser.opts.HasRowNames = true
+	}
+
+	// Drop exact duplicate data rows
+	var duplicates []int
+	var duplicateNames []string
+	if r.opts.Duplicates != DuplicatesKeep {
+		records, duplicates, duplicateNames = dropDuplicateRecords(records, r.opts.Duplicates,
+			r.opts.HasHeaders, parser.opts.HasRowNames)
+		if len(duplicates) > 0 {
+			dropped := duplicateNames
+			if len(dropped) == 0 {
+				dropped = make([]string, len(duplicates))
+				for i, row := range duplicates {
+					dropped[i] = fmt.Sprintf("row %d", row+1)
+				}
+			}
+			warnings = append(warnings, fmt.Sprintf("dropped %d duplicate row(s) (%s): %s",
+				len(duplicates), r.opts.Duplicates, strings.Join(dropped, ", ")))
+		}
+	}
+
 	// Process based on parse mode
-	switch r.opts.ParseMode {
+	var data *Data
+	switch parser.opts.ParseMode {
 	case ParseString:
-		return r.parseAsString(records, nullMap)
+		data, err = parser.parseAsString(records, nullMap)
 	case ParseMixed:
-		return r.parseAsMixed(records, nullMap)
+		data, err = parser.parseAsMixed(records, nullMap)
 	case ParseMixedWithTargets:
-		return r.parseAsMixedWithTargets(records, nullMap)
+		data, err = parser.parseAsMixedWithTargets(records, nullMap)
 	default: // ParseNumeric
-		return r.parseAsNumeric(records, nullMap)
+		data, err = parser.parseAsNumeric(records, nullMap)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data.Warnings = append(warnings, data.Warnings...)
+	if parser.opts.HasHeaders && parser.opts.HasRowNames && len(records[0]) > 0 {
+		data.IndexHeader = records[0][0]
+	}
+	data.DuplicateRows = duplicates
+	data.DuplicateRowNames = duplicateNames
+	data.HeaderDetected = r.opts.AutoHeaders && r.opts.HasHeaders
+	return data, nil
+}
+
+// trimRecords trims leading and trailing whitespace from the fields of the header
+// record, if there is one, when trimHeaders is set, and from the fields of the data
+// records when trimFields is set
+func trimRecords(records [][]string, hasHeaders, trimHeaders, trimFields bool) {
+	for i, record := range records {
+		isHeader := i == 0 && hasHeaders
+		if (isHeader && !trimHeaders) || (!isHeader && !trimFields) {
+			continue
+		}
+		for j, field := range record {
+			record[j] = strings.TrimSpace(field)
+		}
+	}
+}
+
+// dropDuplicateRecords removes data records whose fields, apart from the row name,
+// equal those of another record. DuplicatesDropFirst keeps the first of each set of
+// equal records and DuplicatesDropLast the last. It returns the remaining records,
+// the 0-based data row numbers of the removed ones and, with row names, their names.
+// Records are found by hashing and confirmed by comparing the fields.
+func dropDuplicateRecords(records [][]string, policy DuplicatePolicy, hasHeaders, hasRowNames bool) ([][]string, []int, []string) {
+	start := 0
+	if hasHeaders {
+		start = 1
+	}
+	first := 0
+	if hasRowNames {
+		first = 1
+	}
+	fields := func(record []string) []string {
+		if len(record) < first {
+			return nil
+		}
+		return record[first:]
+	}
+
+	seen := make(map[uint64][]int) // Row hash to the first records with that hash
+	hash := fnv.New64a()
+	group := make([]int, len(records)) // First record equal to each record
+	last := make(map[int]int)          // Last record of each group
+	for i := start; i < len(records); i++ {
+		hash.Reset()
+		for _, field := range fields(records[i]) {
+			// A zero byte separates fields so that ["ab", "c"] and ["a", "bc"] differ
+			_, _ = hash.Write([]byte(field))
+			_, _ = hash.Write([]byte{0})
+		}
+		key := hash.Sum64()
+
+		group[i] = i
+		for _, earlier := range seen[key] {
+			if slices.Equal(fields(records[earlier]), fields(records[i])) {
+				group[i] = earlier
+				break
+			}
+		}
+		if group[i] == i {
+			seen[key] = append(seen[key], i)
+		}
+		last[group[i]] = i
+	}
+
+	kept := records[:start:start]
+	var removed []int
+	var names []string
+	for i := start; i < len(records); i++ {
+		duplicate := group[i] != i
+		if policy == DuplicatesDropLast {
+			duplicate = last[group[i]] != i
+		}
+		if !duplicate {
+			kept = append(kept, records[i])
+			continue
+		}
+		removed = append(removed, i-start)
+		if hasRowNames && len(records[i]) > 0 {
+			names = append(names, strings.TrimSpace(records[i][0]))
+		}
 	}
+	return kept, removed, names
+}
+
+// mergeIndexColumns resolves the index columns and replaces them in every record with
+// a single leading column holding their joined values
+func (r *Reader) mergeIndexColumns(records [][]string) ([][]string, error) {
+	indexCols := append([]int(nil), r.opts.IndexColumns...)
+	if len(r.opts.IndexColumnNames) > 0 {
+		if !r.opts.HasHeaders {
+			return nil, fmt.Errorf("index columns can only be given by name when the file has headers")
+		}
+		for _, name := range r.opts.IndexColumnNames {
+			col := -1
+			for i, header := range records[0] {
+				if strings.TrimSpace(header) == name {
+					col = i
+					break
+				}
+			}
+			if col < 0 {
+				return nil, fmt.Errorf("index column %q not found", name)
+			}
+			indexCols = append(indexCols, col)
+		}
+	}
+
+	separator := r.opts.IndexSeparator
+	if separator == "" {
+		separator = DefaultIndexSeparator
+	}
+	names, rest, err := JoinIndexColumns(records, indexCols, separator)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rest {
+		rest[i] = append([]string{names[i]}, rest[i]...)
+	}
+	return rest, nil
+}
+
+// JoinIndexColumns splits the index columns off every record. It returns their values
+// joined by separator, one per record, and the records without the index columns.
+// Indices are 0-based; they must be unique, exist in every record, and leave at least
+// one other column.
+func JoinIndexColumns(records [][]string, indexColumns []int, separator string) (names []string, rest [][]string, err error) {
+	if len(indexColumns) == 0 {
+		return nil, nil, fmt.Errorf("no index columns given")
+	}
+	isIndex := make(map[int]bool, len(indexColumns))
+	for _, col := range indexColumns {
+		if col < 0 {
+			return nil, nil, fmt.Errorf("invalid index column %d", col+1)
+		}
+		if isIndex[col] {
+			return nil, nil, fmt.Errorf("index column %d given more than once", col+1)
+		}
+		isIndex[col] = true
+	}
+
+	names = make([]string, len(records))
+	rest = make([][]string, len(records))
+	parts := make([]string, len(indexColumns))
+	for i, record := range records {
+		for k, col := range indexColumns {
+			if col >= len(record) {
+				return nil, nil, fmt.Errorf("index column %d not found in row %d", col+1, i+1)
+			}
+			parts[k] = strings.TrimSpace(record[col])
+		}
+		if len(record) <= len(indexColumns) {
+			return nil, nil, fmt.Errorf("row %d has no columns besides the index columns", i+1)
+		}
+		names[i] = strings.Join(parts, separator)
+
+		rest[i] = make([]string, 0, len(record)-len(indexColumns))
+		for j, field := range record {
+			if !isIndex[j] {
+				rest[i] = append(rest[i], field)
+			}
+		}
+	}
+	return names, rest, nil
 }
 
 // parseAsNumeric parses all data as numeric values
@@ -224,13 +495,8 @@ func (r *Reader) parseAsNumeric(records [][]string, nullMap map[string]bool) (*D
 				continue
 			}
 
-			// Handle decimal separator if needed
-			if r.opts.DecimalSeparator == ',' {
-				value = strings.ReplaceAll(value, ",", ".")
-			}
-
 			// Try to parse as float
-			val, err := strconv.ParseFloat(value, 64)
+			val, err := types.ParseNumber(value, r.opts.DecimalSeparator, r.opts.ThousandsSeparator)
 			if err != nil {
 				// Try special cases
 				switch strings.ToLower(value) {
@@ -355,16 +621,18 @@ func (r *Reader) parseAsString(records [][]string, nullMap map[string]bool) (*Da
 func (r *Reader) parseAsMixed(records [][]string, nullMap map[string]bool) (*Data, error) {
 	// Convert to types.CSVFormat for compatibility with existing mixed parser
 	format := types.CSVFormat{
-		FieldDelimiter:   r.opts.Delimiter,
-		DecimalSeparator: r.opts.DecimalSeparator,
-		HasHeaders:       r.opts.HasHeaders,
-		HasRowNames:      r.opts.HasRowNames,
-		NullValues:       r.opts.NullValues,
+		FieldDelimiter:     r.opts.Delimiter,
+		DecimalSeparator:   r.opts.DecimalSeparator,
+		ThousandsSeparator: r.opts.ThousandsSeparator,
+		HasHeaders:         r.opts.HasHeaders,
+		HasRowNames:        r.opts.HasRowNames,
+		NullValues:         r.opts.NullValues,
+		NumericThreshold:   r.opts.NumericThreshold,
 	}
 
 	// Use existing mixed parser
 	csvData, categoricalData, err := types.ParseCSVMixed(
-		strings.NewReader(recordsToString(records)),
+		strings.NewReader(recordsToString(records, r.opts.Delimiter)),
 		format,
 	)
 	if err != nil {
@@ -380,6 +648,7 @@ func (r *Reader) parseAsMixed(records [][]string, nullMap map[string]bool) (*Dat
 		Rows:               csvData.Rows,
 		Columns:            csvData.Columns,
 		CategoricalColumns: categoricalData,
+		Warnings:           csvData.Warnings,
 	}
 
 	return data, nil
@@ -389,16 +658,18 @@ func (r *Reader) parseAsMixed(records [][]string, nullMap map[string]bool) (*Dat
 func (r *Reader) parseAsMixedWithTargets(records [][]string, nullMap map[string]bool) (*Data, error) {
 	// Convert to types.CSVFormat
 	format := types.CSVFormat{
-		FieldDelimiter:   r.opts.Delimiter,
-		DecimalSeparator: r.opts.DecimalSeparator,
-		HasHeaders:       r.opts.HasHeaders,
-		HasRowNames:      r.opts.HasRowNames,
-		NullValues:       r.opts.NullValues,
+		FieldDelimiter:     r.opts.Delimiter,
+		DecimalSeparator:   r.opts.DecimalSeparator,
+		ThousandsSeparator: r.opts.ThousandsSeparator,
+		HasHeaders:         r.opts.HasHeaders,
+		HasRowNames:        r.opts.HasRowNames,
+		NullValues:         r.opts.NullValues,
+		NumericThreshold:   r.opts.NumericThreshold,
 	}
 
 	// Use existing parser with target detection
 	csvData, categoricalData, targetData, err := types.ParseCSVMixedWithTargets(
-		strings.NewReader(recordsToString(records)),
+		strings.NewReader(recordsToString(records, r.opts.Delimiter)),
 		format,
 		nil, // Auto-detect targets based on suffix
 	)
@@ -416,11 +687,72 @@ func (r *Reader) parseAsMixedWithTargets(records [][]string, nullMap map[string]
 		Columns:              csvData.Columns,
 		CategoricalColumns:   categoricalData,
 		NumericTargetColumns: targetData,
+		Warnings:             csvData.Warnings,
+	}
+	if r.opts.AutoDetectTargets {
+		data.SuggestedTargets = suggestTargets(data)
 	}
 
 	return data, nil
 }
 
+// Thresholds used by suggestTargets
+const (
+	// targetMaxCorrelation is the largest absolute correlation with any other numeric
+	// column for which a trailing column is considered unrelated to the features
+	targetMaxCorrelation = 0.3
+	// targetMinColumns is the minimum number of numeric columns for the correlation check
+	targetMinColumns = 3
+)
+
+// targetNames are column names commonly used for response variables
+var targetNames = map[string]bool{
+	"y":             true,
+	"response":      true,
+	"target":        true,
+	"conc":          true,
+	"concentration": true,
+	"outcome":       true,
+}
+
+// suggestTargets returns the trailing numeric column if it looks like a response
+// variable rather than a feature: its name (up to the first non-letter, ignoring
+// case) is one commonly used for responses, or its absolute correlation with every
+// other numeric column is below targetMaxCorrelation. The column is not removed.
+func suggestTargets(data *Data) []string {
+	if len(data.Headers) == 0 || len(data.Matrix) < 3 {
+		return nil
+	}
+	last := len(data.Headers) - 1
+	name := data.Headers[last]
+
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(words) > 0 && targetNames[words[0]] {
+		return []string{name}
+	}
+
+	if len(data.Headers) < targetMinColumns {
+		return nil
+	}
+	for j := 0; j < last; j++ {
+		var x, y []float64
+		for _, row := range data.Matrix {
+			if !math.IsNaN(row[j]) && !math.IsNaN(row[last]) {
+				x = append(x, row[j])
+				y = append(y, row[last])
+			}
+		}
+		if len(x) < 3 {
+			continue
+		}
+		r := stat.Correlation(x, y, nil)
+		if math.IsNaN(r) || math.Abs(r) >= targetMaxCorrelation {
+			return nil
+		}
+	}
+	return []string{name}
+}
+
 // readStreaming handles streaming read for large files
 func (r *Reader) readStreaming(reader *csv.Reader, nullMap map[string]bool) (*Data, error) {
 	// Implementation for streaming large files
@@ -449,25 +781,14 @@ func (r *Reader) getSelectedColumns(totalCols int) []int {
 	return selected
 }
 
-// recordsToString converts records back to CSV string for compatibility
-func recordsToString(records [][]string) string {
+// recordsToString converts records back to CSV text for compatibility, using the
+// delimiter the text will be parsed with. Fields are quoted as needed, so values
+// containing the delimiter survive the round trip.
+func recordsToString(records [][]string, delimiter rune) string {
 	var sb strings.Builder
-	for _, record := range records {
-		for i, field := range record {
-			if i > 0 {
-				sb.WriteRune(',')
-			}
-			// Simple CSV escaping
-			if strings.ContainsAny(field, ",\"\n") {
-				sb.WriteRune('"')
-				sb.WriteString(strings.ReplaceAll(field, "\"", "\"\""))
-				sb.WriteRune('"')
-			} else {
-				sb.WriteString(field)
-			}
-		}
-		sb.WriteRune('\n')
-	}
+	writer := csv.NewWriter(&sb)
+	writer.Comma = delimiter
+	_ = writer.WriteAll(records) // Writing to a strings.Builder cannot fail
 	return sb.String()
 }
 
@@ -488,14 +809,87 @@ func (r *Reader) validateFilePath(filename string) error {
 	return security.ValidateInputPath(filename)
 }
 
+// maxFieldLength returns the field length limit of the options, or the security
+// limit if none is set
+func (r *Reader) maxFieldLength() int {
+	if r.opts.MaxFieldLength > 0 {
+		return r.opts.MaxFieldLength
+	}
+	return MaxFieldLength
+}
+
 // validateField validates a single field for security constraints
 func (r *Reader) validateField(field string) error {
-	if len(field) > MaxFieldLength {
-		return fmt.Errorf("field too long: %d characters (max %d)", len(field), MaxFieldLength)
+	if limit := r.maxFieldLength(); len(field) > limit {
+		return fmt.Errorf("field too long: %d characters (max %d)", len(field), limit)
 	}
 	return nil
 }
 
+// limitFieldLengths applies the oversize field policy to the fields longer than the
+// field length limit. It returns the records, with fields truncated or columns
+// removed, and a warning describing the change, or an error under OversizeError.
+func (r *Reader) limitFieldLengths(records [][]string) ([][]string, []string, error) {
+	limit := r.maxFieldLength()
+	truncated := 0
+	var skip []int // 0-based columns with an oversize field, in ascending order
+	for i, record := range records {
+		for j, field := range record {
+			if len(field) <= limit {
+				continue
+			}
+			switch r.opts.OnOversizeField {
+			case OversizeTruncate:
+				// Back up to the start of a character so no UTF-8 sequence is split
+				end := limit
+				for end > 0 && !utf8.RuneStart(field[end]) {
+					end--
+				}
+				record[j] = field[:end]
+				truncated++
+			case OversizeSkipColumn:
+				if !slices.Contains(skip, j) {
+					skip = append(skip, j)
+				}
+			default:
+				return nil, nil, fmt.Errorf("row %d, column %d: %w", i+1, j+1, r.validateField(field))
+			}
+		}
+	}
+
+	var warnings []string
+	if truncated > 0 {
+		warnings = append(warnings, fmt.Sprintf("truncated %d field(s) longer than %d characters", truncated, limit))
+	}
+	if len(skip) > 0 {
+		slices.Sort(skip)
+		// Name the columns by the header row, unless it is still to be detected
+		var header []string
+		if r.opts.HasHeaders && !r.opts.AutoHeaders && r.opts.SkipRows < len(records) {
+			header = records[r.opts.SkipRows]
+		}
+		names := make([]string, len(skip))
+		for k, j := range skip {
+			names[k] = fmt.Sprintf("column %d", j+1)
+			if j < len(header) && len(header[j]) <= limit {
+				names[k] = fmt.Sprintf("%q", header[j])
+			}
+		}
+		for i, record := range records {
+			kept := make([]string, 0, len(record))
+			for j, field := range record {
+				if !slices.Contains(skip, j) {
+					kept = append(kept, field)
+				}
+			}
+			records[i] = kept
+		}
+		warnings = append(warnings, fmt.Sprintf("skipped %d column(s) with fields longer than %d characters: %s",
+			len(skip), limit, strings.Join(names, ", ")))
+	}
+	return records, warnings, nil
+}
+
 // validateRecordCount validates the number of records
 func (r *Reader) validateRecordCount(count int) error {
 	if count > security.MaxCSVRows {
@@ -7,9 +7,13 @@
 package csv
 
 import (
+	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
 )
 
 func TestParseNumeric(t *testing.T) {
@@ -195,6 +199,22 @@ row2,4,5,6`
 	if len(data.Headers) != 3 {
 		t.Errorf("expected 3 headers, got %d", len(data.Headers))
 	}
+
+	// The header of the row name column is kept so writing can restore it
+	data, err = reader.Read(strings.NewReader("id,A\nrow1,1\nrow2,2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.IndexHeader != "id" {
+		t.Errorf("expected index header id, got %q", data.IndexHeader)
+	}
+	var sb strings.Builder
+	if err := Write(&sb, data, DefaultOptions()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := "id,A\nrow1,1\nrow2,2\n"; sb.String() != want {
+		t.Errorf("expected %q after a round trip, got %q", want, sb.String())
+	}
 }
 
 func TestParseMissingValues(t *testing.T) {
@@ -263,6 +283,40 @@ func TestParseEuropeanFormat(t *testing.T) {
 	}
 }
 
+func TestParseThousandsSeparator(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		delimiter rune
+		decimal   rune
+		thousands rune
+	}{
+		{"European", "A;B\n1.234,56;2\n", ';', ',', '.'},
+		{"US grouped", "A,B\n\"1,234.56\",2\n", ',', '.', ','},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []ParseMode{ParseNumeric, ParseMixed} {
+			t.Run(fmt.Sprintf("%s/mode %d", tt.name, mode), func(t *testing.T) {
+				opts := DefaultOptions()
+				opts.HasRowNames = false
+				opts.Delimiter = tt.delimiter
+				opts.DecimalSeparator = tt.decimal
+				opts.ThousandsSeparator = tt.thousands
+				opts.ParseMode = mode
+
+				data, err := NewReader(opts).Read(strings.NewReader(tt.input))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if data.Columns != 2 || data.Matrix[0][0] != 1234.56 {
+					t.Errorf("expected 1234.56 in a numeric column, got %v", data.Matrix)
+				}
+			})
+		}
+	}
+}
+
 func TestParseString(t *testing.T) {
 	input := `Name,Age,City
 Alice,30,NYC
@@ -344,3 +398,518 @@ func TestParseInvalidNumeric(t *testing.T) {
 		t.Error("expected error for invalid numeric value")
 	}
 }
+
+func TestParseCommentAndBlankLines(t *testing.T) {
+	input := `# Instrument: "Spectro 3000"
+# Operator: lab, 2025-01-01
+# Units: absorbance
+,A,B,C
+
+row1,1,2,3
+   
+row2,4,5,6
+`
+
+	opts := DefaultOptions()
+	opts.CommentPrefix = "#"
+	opts.SkipBlankLines = true
+	for _, mode := range []ParseMode{ParseNumeric, ParseMixedWithTargets} {
+		opts.ParseMode = mode
+		data, err := NewReader(opts).Read(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("mode %d: unexpected error: %v", mode, err)
+		}
+
+		if strings.Join(data.Headers, ",") != "A,B,C" {
+			t.Errorf("mode %d: unexpected headers: %v", mode, data.Headers)
+		}
+		if strings.Join(data.RowNames, ",") != "row1,row2" {
+			t.Errorf("mode %d: unexpected row names: %v", mode, data.RowNames)
+		}
+		if data.Rows != 2 || data.Matrix[1][2] != 6 {
+			t.Errorf("mode %d: unexpected data: %v", mode, data.Matrix)
+		}
+	}
+
+	// Without a comment prefix the metadata lines break parsing
+	opts.CommentPrefix = ""
+	if _, err := NewReader(opts).Read(strings.NewReader(input)); err == nil {
+		t.Error("expected error when comment lines are not skipped")
+	}
+}
+
+func TestTSVQuotedTabRoundTrip(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Delimiter = '\t'
+	opts.HasRowNames = false
+	opts.ParseMode = ParseString
+
+	original := &Data{
+		Headers:    []string{"name", "note"},
+		StringData: [][]string{{"a", "x\ty"}, {"b", ""}},
+		Rows:       2,
+		Columns:    2,
+	}
+
+	var buf strings.Builder
+	if err := NewWriter(opts).Write(&buf, original); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\"x\ty\"") {
+		t.Errorf("expected field containing a tab to be quoted, got %q", buf.String())
+	}
+
+	data, err := NewReader(opts).Read(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if data.StringData[0][1] != "x\ty" {
+		t.Errorf("expected embedded tab to survive, got %q", data.StringData[0][1])
+	}
+	if data.StringData[1][1] != "" {
+		t.Errorf("expected empty field, got %q", data.StringData[1][1])
+	}
+}
+
+func TestParseMixedTSVEmptyAndQuotedFields(t *testing.T) {
+	input := "\tA\tB\tgroup\n" +
+		"r1\t1\t2\t\"x\ty\"\n" +
+		"r2\t\t4\tz\n" +
+		"r3\t5\t6\tz\n"
+
+	opts := DefaultOptions()
+	opts.Delimiter = '\t'
+	opts.ParseMode = ParseMixedWithTargets
+
+	data, err := NewReader(opts).Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(data.Headers, ",") != "A,B" {
+		t.Errorf("unexpected headers: %v", data.Headers)
+	}
+	// An empty field between tabs is missing, not skipped
+	if !math.IsNaN(data.Matrix[1][0]) || data.Matrix[1][1] != 4 {
+		t.Errorf("unexpected row r2: %v", data.Matrix[1])
+	}
+	if got := data.CategoricalColumns["group"]; len(got) != 3 || got[0] != "x\ty" {
+		t.Errorf("unexpected categorical values: %q", got)
+	}
+}
+
+func TestParseCompositeIndexColumns(t *testing.T) {
+	input := "subject,x,visit,y\n" +
+		"subjectA,1.0,visit1,2.0\n" +
+		"subjectA,1.5,visit2,2.5\n" +
+		"subjectB,3.0,visit1,4.0\n"
+
+	tests := []struct {
+		name string
+		opts func(*Options)
+		want string
+	}{
+		{"names numeric", func(o *Options) { o.IndexColumnNames = []string{"subject", "visit"} }, "subjectA|visit1"},
+		{"names mixed", func(o *Options) {
+			o.ParseMode = ParseMixedWithTargets
+			o.IndexColumnNames = []string{"subject", "visit"}
+		}, "subjectA|visit1"},
+		{"indices with separator", func(o *Options) {
+			o.IndexColumns = []int{2, 0}
+			o.IndexSeparator = "_"
+		}, "visit1_subjectA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.opts(&opts)
+
+			data, err := NewReader(opts).Read(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if data.Rows != 3 || data.Columns != 2 || len(data.Matrix[0]) != 2 {
+				t.Errorf("expected a 3x2 matrix, got %dx%d", data.Rows, data.Columns)
+			}
+			if strings.Join(data.Headers, ",") != "x,y" {
+				t.Errorf("unexpected headers: %v", data.Headers)
+			}
+			if data.RowNames[0] != tt.want {
+				t.Errorf("expected row name %q, got %q", tt.want, data.RowNames[0])
+			}
+			if data.Matrix[2][0] != 3.0 || data.Matrix[2][1] != 4.0 {
+				t.Errorf("unexpected last row: %v", data.Matrix[2])
+			}
+		})
+	}
+
+	// Index columns must exist, be unique and leave data columns
+	for _, opts := range []Options{
+		{HasHeaders: true, IndexColumnNames: []string{"missing"}},
+		{HasHeaders: true, IndexColumns: []int{0, 0}},
+		{HasHeaders: true, IndexColumns: []int{7}},
+		{HasHeaders: true, IndexColumns: []int{0, 1, 2, 3}},
+		{HasHeaders: false, IndexColumnNames: []string{"subject"}},
+	} {
+		opts.Delimiter = ','
+		if _, err := NewReader(opts).Read(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for index columns %v %v", opts.IndexColumns, opts.IndexColumnNames)
+		}
+	}
+}
+
+func TestParseAutoDetectTargets(t *testing.T) {
+	features := [][]string{
+		{"1", "2.1", "0.5"},
+		{"2", "3.9", "1.1"},
+		{"3", "6.2", "1.4"},
+		{"4", "7.8", "2.1"},
+		{"5", "10.1", "2.4"},
+		{"6", "12", "3.1"},
+	}
+	build := func(header string, extra []string) string {
+		var sb strings.Builder
+		sb.WriteString(",a,b,c")
+		if header != "" {
+			sb.WriteString("," + header)
+		}
+		sb.WriteString(",yield#target\n")
+		for i, row := range features {
+			sb.WriteString(fmt.Sprintf("r%d,%s", i+1, strings.Join(row, ",")))
+			if header != "" {
+				sb.WriteString("," + extra[i])
+			}
+			sb.WriteString(fmt.Sprintf(",%d\n", i))
+		}
+		return sb.String()
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		extra  []string
+		detect bool
+		want   []string
+	}{
+		// Correlated with the features, but named like a response
+		{"response by name", "response", []string{"3.2", "5.8", "9.1", "11.9", "15.2", "18.1"}, true, []string{"response"}},
+		{"name with unit", "Conc (mg/L)", []string{"3.2", "5.8", "9.1", "11.9", "15.2", "18.1"}, true, []string{"Conc (mg/L)"}},
+		// Unrelated to every feature
+		{"uncorrelated", "extra", []string{"1", "-1", "-1", "1", "1", "-1"}, true, []string{"extra"}},
+		// An ordinary feature column is not suggested
+		{"ordinary feature", "", nil, true, nil},
+		{"correlated feature", "d", []string{"3.2", "5.8", "9.1", "11.9", "15.2", "18.1"}, true, nil},
+		{"off by default", "response", []string{"3.2", "5.8", "9.1", "11.9", "15.2", "18.1"}, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ParseMode = ParseMixedWithTargets
+			opts.AutoDetectTargets = tt.detect
+
+			data, err := NewReader(opts).Read(strings.NewReader(build(tt.header, tt.extra)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(data.SuggestedTargets, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected suggested targets %v, got %v", tt.want, data.SuggestedTargets)
+			}
+			// Suggestions are not excluded; suffixed targets still are
+			if _, ok := data.NumericTargetColumns["yield#target"]; !ok {
+				t.Errorf("expected yield#target as target column, got %v", data.NumericTargetColumns)
+			}
+			if tt.header != "" && data.Headers[len(data.Headers)-1] != tt.header {
+				t.Errorf("expected %q to stay in the data, got headers %v", tt.header, data.Headers)
+			}
+		})
+	}
+}
+
+func TestParseRaggedRows(t *testing.T) {
+	input := `,A,B,C
+row1,1,2,3
+row2,4,5
+row3,7,8,9,10
+row4,1,1,2
+`
+
+	opts := DefaultOptions()
+	for _, mode := range []ParseMode{ParseNumeric, ParseMixedWithTargets} {
+		opts.ParseMode = mode
+
+		opts.RaggedRows = types.RaggedRowsError
+		if _, err := NewReader(opts).Read(strings.NewReader(input)); err == nil {
+			t.Errorf("mode %d: expected error for ragged rows", mode)
+		}
+
+		// Padding repairs the short row but not the long one
+		opts.RaggedRows = types.RaggedRowsPad
+		if _, err := NewReader(opts).Read(strings.NewReader(input)); err == nil {
+			t.Errorf("mode %d: expected error for a long row when padding", mode)
+		}
+
+		opts.RaggedRows = types.RaggedRowsTruncate
+		data, err := NewReader(opts).Read(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("mode %d: unexpected error: %v", mode, err)
+		}
+		if data.Rows != 4 || data.Columns != 3 {
+			t.Fatalf("mode %d: expected 4x3 data, got %dx%d", mode, data.Rows, data.Columns)
+		}
+		if !math.IsNaN(data.Matrix[1][2]) || !data.MissingMask[1][2] {
+			t.Errorf("mode %d: expected the padded field to be missing, got %v", mode, data.Matrix[1])
+		}
+		if data.Matrix[2][2] != 9 {
+			t.Errorf("mode %d: expected the long row to be truncated, got %v", mode, data.Matrix[2])
+		}
+		if len(data.Warnings) != 2 ||
+			!strings.Contains(data.Warnings[0], "row 3") || !strings.Contains(data.Warnings[0], "padded") ||
+			!strings.Contains(data.Warnings[1], "row 4") || !strings.Contains(data.Warnings[1], "dropped 1") {
+			t.Errorf("mode %d: unexpected warnings: %v", mode, data.Warnings)
+		}
+	}
+}
+
+func TestParseDuplicateRows(t *testing.T) {
+	input := `,A,B
+row1,1,2
+row2,3,4
+row3,1,2
+row4,5,6
+row5,1,2
+`
+
+	opts := DefaultOptions()
+	for _, mode := range []ParseMode{ParseNumeric, ParseMixedWithTargets} {
+		opts.ParseMode = mode
+
+		opts.Duplicates = DuplicatesKeep
+		data, err := NewReader(opts).Read(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("mode %d: unexpected error: %v", mode, err)
+		}
+		if data.Rows != 5 || len(data.DuplicateRows) != 0 {
+			t.Errorf("mode %d: expected all 5 rows to be kept, got %d", mode, data.Rows)
+		}
+
+		// Only the first of the three identical rows survives, with its own name
+		opts.Duplicates = DuplicatesDropFirst
+		data, err = NewReader(opts).Read(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("mode %d: unexpected error: %v", mode, err)
+		}
+		if want := []string{"row1", "row2", "row4"}; !slices.Equal(data.RowNames, want) {
+			t.Errorf("mode %d: expected rows %v, got %v", mode, want, data.RowNames)
+		}
+		if data.Rows != 3 || data.Matrix[0][0] != 1 || data.Matrix[1][0] != 3 || data.Matrix[2][0] != 5 {
+			t.Errorf("mode %d: row names and data are not aligned: %v", mode, data.Matrix)
+		}
+		if !slices.Equal(data.DuplicateRows, []int{2, 4}) ||
+			!slices.Equal(data.DuplicateRowNames, []string{"row3", "row5"}) {
+			t.Errorf("mode %d: expected rows 2 and 4 (row3, row5) dropped, got %v %v",
+				mode, data.DuplicateRows, data.DuplicateRowNames)
+		}
+		if len(data.Warnings) != 1 || !strings.Contains(data.Warnings[0], "dropped 2 duplicate row(s)") {
+			t.Errorf("mode %d: unexpected warnings: %v", mode, data.Warnings)
+		}
+
+		opts.Duplicates = DuplicatesDropLast
+		data, err = NewReader(opts).Read(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("mode %d: unexpected error: %v", mode, err)
+		}
+		if want := []string{"row2", "row4", "row5"}; !slices.Equal(data.RowNames, want) {
+			t.Errorf("mode %d: expected rows %v, got %v", mode, want, data.RowNames)
+		}
+	}
+
+	if _, err := ParseDuplicatePolicy("first"); err == nil {
+		t.Error("expected an error for an unknown duplicate policy")
+	}
+}
+
+func TestParseOversizeFields(t *testing.T) {
+	// One 15,000-character cell in a text column, read with a 10,000-character limit
+	long := strings.Repeat("ab", 7500)
+	input := ",A,notes,B\nrow1,1,short,2\nrow2,3," + long + ",4\n"
+
+	opts := DefaultOptions()
+	opts.ParseMode = ParseMixedWithTargets
+
+	// The security limit is well above the cell
+	data, err := NewReader(opts).Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error under the default limit: %v", err)
+	}
+	if got := data.CategoricalColumns["notes"][1]; got != long {
+		t.Errorf("expected the long cell unchanged under the default limit, got %d characters", len(got))
+	}
+
+	opts.MaxFieldLength = 10000
+	opts.OnOversizeField = OversizeError
+	if _, err := NewReader(opts).Read(strings.NewReader(input)); err == nil ||
+		!strings.Contains(err.Error(), "row 3, column 3") || !strings.Contains(err.Error(), "max 10000") {
+		t.Errorf("expected a field length error at row 3, column 3, got %v", err)
+	}
+
+	opts.OnOversizeField = OversizeTruncate
+	data, err = NewReader(opts).Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("truncate: unexpected error: %v", err)
+	}
+	if got := data.CategoricalColumns["notes"]; len(got[1]) != 10000 || got[1] != long[:10000] || got[0] != "short" {
+		t.Errorf("truncate: expected the cell cut to 10000 characters, got %d", len(got[1]))
+	}
+	if len(data.Warnings) != 1 || !strings.Contains(data.Warnings[0], "truncated 1 field(s)") {
+		t.Errorf("truncate: unexpected warnings: %v", data.Warnings)
+	}
+
+	opts.OnOversizeField = OversizeSkipColumn
+	data, err = NewReader(opts).Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("skip-column: unexpected error: %v", err)
+	}
+	if _, ok := data.CategoricalColumns["notes"]; ok || !slices.Equal(data.Headers, []string{"A", "B"}) {
+		t.Errorf("skip-column: expected the notes column removed, got headers %v", data.Headers)
+	}
+	if data.Matrix[1][0] != 3 || data.Matrix[1][1] != 4 {
+		t.Errorf("skip-column: the remaining columns are not aligned: %v", data.Matrix)
+	}
+	if len(data.Warnings) != 1 || !strings.Contains(data.Warnings[0], `"notes"`) {
+		t.Errorf("skip-column: unexpected warnings: %v", data.Warnings)
+	}
+
+	// Truncation does not split a multi-byte character
+	opts.MaxFieldLength = 4
+	opts.OnOversizeField = OversizeTruncate
+	data, err = NewReader(opts).Read(strings.NewReader(",A,n\nrow1,1,abcé\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := data.CategoricalColumns["n"][0]; got != "abc" {
+		t.Errorf("expected truncation at a character boundary, got %q", got)
+	}
+
+	if _, err := ParseOversizePolicy("drop"); err == nil {
+		t.Error("expected an error for an unknown oversize field policy")
+	}
+}
+
+func TestParseNumericColumnWithTypos(t *testing.T) {
+	// Column A has two typos in 20 values, 90% numeric; B is a category
+	var b strings.Builder
+	b.WriteString("id,A,B\n")
+	for i := 1; i <= 20; i++ {
+		value := fmt.Sprintf("%d.5", i)
+		switch i {
+		case 3:
+			value = "1.2.3"
+		case 17:
+			value = "45x"
+		}
+		fmt.Fprintf(&b, "r%d,%s,%c\n", i, value, 'a'+rune(i%3))
+	}
+
+	opts := DefaultOptions()
+	opts.ParseMode = ParseMixedWithTargets
+	data, err := NewReader(opts).Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Columns != 1 || data.Headers[0] != "A" {
+		t.Fatalf("expected A to be read as numeric, got headers %v", data.Headers)
+	}
+	if _, ok := data.CategoricalColumns["B"]; !ok {
+		t.Errorf("expected B to be categorical, got %v", data.CategoricalColumns)
+	}
+	for i, row := range data.Matrix {
+		typo := i == 2 || i == 16
+		if data.MissingMask[i][0] != typo || math.IsNaN(row[0]) != typo {
+			t.Errorf("row %d: expected missing %v, got %v (%v)", i+1, typo, data.MissingMask[i][0], row[0])
+		}
+	}
+	if len(data.Warnings) != 1 || !strings.Contains(data.Warnings[0],
+		`column 'A' has 2 non-numeric value(s) read as missing: row 3 "1.2.3", row 17 "45x"`) {
+		t.Errorf("expected a warning listing both typos, got %v", data.Warnings)
+	}
+	if report := data.Validate(DefaultValidationOptions()); report.MissingValues != 2 {
+		t.Errorf("expected the typos to be reported as 2 missing values, got %d", report.MissingValues)
+	}
+
+	// A threshold of 1 reads any column with a non-number as categorical
+	opts.NumericThreshold = 1
+	data, err = NewReader(opts).Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Columns != 0 || len(data.CategoricalColumns) != 2 {
+		t.Errorf("expected A to be categorical with threshold 1, got %d numeric columns", data.Columns)
+	}
+}
+
+func TestParseTrimFields(t *testing.T) {
+	input := "id,\" x \", species ,\" note \"\n" +
+		"r1,\" 3.14 \", setosa ,\"  padded  \"\n" +
+		"r2, 2.5 ,versicolor , plain\n"
+
+	// Padded numbers parse and padded headers match by name
+	opts := DefaultOptions()
+	opts.ParseMode = ParseMixedWithTargets
+	data, err := NewReader(opts).Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"x"}; !slices.Equal(data.Headers, want) {
+		t.Errorf("expected numeric headers %v, got %v", want, data.Headers)
+	}
+	if data.Matrix[0][0] != 3.14 || data.Matrix[1][0] != 2.5 {
+		t.Errorf("expected values 3.14 and 2.5, got %v", data.Matrix)
+	}
+	if want := []string{"setosa", "versicolor"}; !slices.Equal(data.CategoricalColumns["species"], want) {
+		t.Errorf("expected categorical column species %v, got %v", want, data.CategoricalColumns)
+	}
+
+	// Without trimming, quoted fields keep their spaces
+	opts = DefaultOptions()
+	opts.ParseMode = ParseString
+	opts.TrimFields = false
+	opts.TrimHeaders = false
+	data, err = NewReader(opts).Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{" x ", "species ", " note "}; !slices.Equal(data.Headers, want) {
+		t.Errorf("expected untrimmed headers %q, got %q", want, data.Headers)
+	}
+	if got := data.StringData[0][2]; got != "  padded  " {
+		t.Errorf("expected quoted field %q to be preserved, got %q", "  padded  ", got)
+	}
+
+	// Headers and fields are trimmed independently
+	opts.TrimHeaders = true
+	data, err = NewReader(opts).Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"x", "species", "note"}; !slices.Equal(data.Headers, want) {
+		t.Errorf("expected trimmed headers %q, got %q", want, data.Headers)
+	}
+	if got := data.StringData[1][1]; got != "versicolor " {
+		t.Errorf("expected untrimmed field %q, got %q", "versicolor ", got)
+	}
+}
+
+func TestReadAllLimited(t *testing.T) {
+	input := "a,b\n1,2\n"
+	got, err := readAllLimited(strings.NewReader(input), int64(len(input)))
+	if err != nil || string(got) != input {
+		t.Errorf("expected %q, got %q (%v)", input, got, err)
+	}
+	if _, err := readAllLimited(strings.NewReader(input), int64(len(input)-1)); err == nil ||
+		!strings.Contains(err.Error(), "input too large") {
+		t.Errorf("expected an error for input over the limit, got %v", err)
+	}
+}
@@ -10,6 +10,7 @@
 package csv
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/bitjungle/gopca/pkg/types"
@@ -29,26 +30,177 @@ const (
 	ParseMixedWithTargets
 )
 
+// QuotingMode defines which fields the CSV writer encloses in double quotes
+type QuotingMode int
+
+const (
+	// QuoteMinimal quotes only fields that contain the delimiter, a quote or a line
+	// break, or start with a space
+	QuoteMinimal QuotingMode = iota
+	// QuoteAll quotes every field
+	QuoteAll
+	// QuoteNonNumeric quotes every field that is not a number written with the
+	// configured decimal separator
+	QuoteNonNumeric
+	// QuoteNone never quotes; writing a field that would need quotes is an error
+	QuoteNone
+)
+
+// quotingModeNames are the names accepted by ParseQuotingMode
+var quotingModeNames = map[QuotingMode]string{
+	QuoteMinimal:    "minimal",
+	QuoteAll:        "all",
+	QuoteNonNumeric: "nonnumeric",
+	QuoteNone:       "none",
+}
+
+// String returns the name of the quoting mode
+func (m QuotingMode) String() string {
+	if name, ok := quotingModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("QuotingMode(%d)", int(m))
+}
+
+// ParseQuotingMode returns the quoting mode with the given name: minimal, all,
+// nonnumeric or none
+func ParseQuotingMode(name string) (QuotingMode, error) {
+	for mode, modeName := range quotingModeNames {
+		if name == modeName {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid quoting mode %q: must be minimal, all, nonnumeric or none", name)
+}
+
+// DuplicatePolicy defines how the reader handles data rows that repeat an earlier
+// or later row exactly. Row names are not compared.
+type DuplicatePolicy int
+
+const (
+	// DuplicatesKeep keeps all rows
+	DuplicatesKeep DuplicatePolicy = iota
+	// DuplicatesDropFirst drops duplicate rows, keeping the first occurrence
+	DuplicatesDropFirst
+	// DuplicatesDropLast drops duplicate rows, keeping the last occurrence
+	DuplicatesDropLast
+)
+
+// duplicatePolicyNames are the names accepted by ParseDuplicatePolicy
+var duplicatePolicyNames = map[DuplicatePolicy]string{
+	DuplicatesKeep:      "keep",
+	DuplicatesDropFirst: "drop-first",
+	DuplicatesDropLast:  "drop-last",
+}
+
+// String returns the name of the duplicate policy
+func (p DuplicatePolicy) String() string {
+	if name, ok := duplicatePolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("DuplicatePolicy(%d)", int(p))
+}
+
+// ParseDuplicatePolicy returns the duplicate policy with the given name: keep,
+// drop-first or drop-last
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	for policy, policyName := range duplicatePolicyNames {
+		if name == policyName {
+			return policy, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid duplicate policy %q: must be keep, drop-first or drop-last", name)
+}
+
+// OversizePolicy defines how the reader handles fields longer than the field length
+// limit, Options.MaxFieldLength
+type OversizePolicy int
+
+const (
+	// OversizeError fails the parse
+	OversizeError OversizePolicy = iota
+	// OversizeTruncate cuts oversize fields to the limit, at a character boundary
+	OversizeTruncate
+	// OversizeSkipColumn removes every column holding an oversize field
+	OversizeSkipColumn
+)
+
+// oversizePolicyNames are the names accepted by ParseOversizePolicy
+var oversizePolicyNames = map[OversizePolicy]string{
+	OversizeError:      "error",
+	OversizeTruncate:   "truncate",
+	OversizeSkipColumn: "skip-column",
+}
+
+// String returns the name of the oversize field policy
+func (p OversizePolicy) String() string {
+	if name, ok := oversizePolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("OversizePolicy(%d)", int(p))
+}
+
+// ParseOversizePolicy returns the oversize field policy with the given name: error,
+// truncate or skip-column
+func ParseOversizePolicy(name string) (OversizePolicy, error) {
+	for policy, policyName := range oversizePolicyNames {
+		if name == policyName {
+			return policy, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid oversize field policy %q: must be error, truncate or skip-column", name)
+}
+
+// DefaultIndexSeparator joins the values of multiple index columns into one row name
+const DefaultIndexSeparator = "|"
+
 // Options provides unified configuration for CSV operations
 type Options struct {
 	// Parsing options
-	Delimiter        rune      // Field delimiter: ',', ';', '\t'
-	DecimalSeparator rune      // Decimal separator: '.', ','
-	HasHeaders       bool      // First row contains column names
-	HasRowNames      bool      // First column contains row names
-	NullValues       []string  // Strings to treat as missing values
-	ParseMode        ParseMode // How to parse the data
-	TargetSuffix     string    // Suffix to identify target columns (e.g., "#target")
+	Delimiter          rune      // Field delimiter: ',', ';', '\t'
+	DecimalSeparator   rune      // Decimal separator: '.', ','
+	ThousandsSeparator rune      // Digit grouping separator stripped from numbers, e.g. '.' in 1.234,56 (0 for none)
+	HasHeaders         bool      // First row contains column names
+	AutoHeaders        bool      // Decide HasHeaders from the first rows with DetectHeader
+	HasRowNames        bool      // First column contains row names
+	NullValues         []string  // Strings to treat as missing values
+	ParseMode          ParseMode // How to parse the data
+	TargetSuffix       string    // Suffix to identify target columns (e.g., "#target")
+	AutoDetectTargets  bool      // ParseMixedWithTargets: suggest likely target columns in Data.SuggestedTargets
+	Encoding           string    // Text encoding of the input: "utf-8" (default) or "latin1"
+	CommentPrefix      string    // Lines starting with this prefix are skipped, e.g. "#" (empty to disable)
+	SkipBlankLines     bool      // Skip lines containing only whitespace
+	TrimFields         bool      // Trim leading and trailing whitespace from data fields and row names, quoted or not
+	TrimHeaders        bool      // Trim leading and trailing whitespace from column names
+	// Fraction of a column's non-empty values that must be numbers for ParseMixed and
+	// ParseMixedWithTargets to read it as numeric; the other values become missing values
+	NumericThreshold float64
+	// How rows with more or fewer fields than the header are handled (default types.RaggedRowsError)
+	RaggedRows types.RaggedRowPolicy
+	Duplicates DuplicatePolicy // How exact duplicate data rows are handled (default DuplicatesKeep)
+	// Longest field in bytes (0 for the security limit, security.MaxFieldLength), and how
+	// longer fields are handled (default OversizeError)
+	MaxFieldLength  int
+	OnOversizeField OversizePolicy
+
+	// Composite row names. When index columns are given they replace the single
+	// row name column of HasRowNames, and Columns refers to the remaining columns.
+	IndexColumns     []int    // Columns (0-based) joined into row names
+	IndexColumnNames []string // Columns joined into row names, matched against the header row
+	IndexSeparator   string   // Separator between joined index values (default DefaultIndexSeparator)
 
 	// Reading options (for large files)
-	SkipRows      int   // Number of rows to skip at start
-	MaxRows       int   // Maximum rows to read (0 for all)
-	Columns       []int // Specific columns to read (empty for all)
-	StreamingMode bool  // Enable streaming for large files
+	SkipRows      int    // Number of rows to skip at start, after comment and blank lines are removed
+	MaxRows       int    // Maximum rows to read (0 for all)
+	Columns       []int  // Specific columns to read (empty for all)
+	StreamingMode bool   // Enable streaming for large files
+	ArchiveEntry  string // Entry to read from a zip archive with several CSV entries
 
 	// Writing options
-	FloatFormat byte // Format for float output: 'g', 'f', 'e'
-	Precision   int  // Decimal precision for float output (-1 for auto)
+	FloatFormat    byte        // Format for float output: 'g', 'f', 'e'
+	Precision      int         // Significant digits for float output; negative for full precision
+	EscapeFormulas bool        // Prefix text cells starting with =, +, -, @ with a quote so spreadsheets do not evaluate them
+	Quoting        QuotingMode // Which fields to enclose in double quotes (default QuoteMinimal)
 }
 
 // DefaultOptions returns sensible default options for CSV operations
@@ -61,6 +213,9 @@ func DefaultOptions() Options {
 		NullValues:       []string{"", "NA", "N/A", "nan", "NaN", "null", "NULL", "m"},
 		ParseMode:        ParseNumeric,
 		TargetSuffix:     "#target",
+		TrimFields:       true,
+		TrimHeaders:      true,
+		NumericThreshold: types.DefaultNumericThreshold,
 		SkipRows:         0,
 		MaxRows:          0,
 		Columns:          nil,
@@ -92,6 +247,7 @@ type Data struct {
 	Matrix      types.Matrix // Numeric data matrix
 	Headers     []string     // Column names
 	RowNames    []string     // Row names
+	IndexHeader string       // Header of the row name column, when there are headers
 	MissingMask [][]bool     // Track missing values (true = missing)
 	Rows        int          // Number of data rows
 	Columns     int          // Number of data columns
@@ -100,6 +256,11 @@ type Data struct {
 	StringData           [][]string           // Raw string data (for GoCSV)
 	CategoricalColumns   map[string][]string  // Categorical columns by name
 	NumericTargetColumns map[string][]float64 // Numeric target columns
+	SuggestedTargets     []string             // Numeric columns that look like targets (AutoDetectTargets); still in Matrix
+	Warnings             []string             // Problems repaired while parsing, such as ragged rows
+	DuplicateRows        []int                // Data rows (0-based, in file order) dropped by Options.Duplicates
+	DuplicateRowNames    []string             // Row names of DuplicateRows, if the data has row names
+	HeaderDetected       bool                 // With Options.AutoHeaders, whether the first row was read as column names
 }
 
 // DataProvider is an interface that different data representations can implement
@@ -134,6 +295,24 @@ type Validator struct {
 	opts Options
 }
 
+// ValidationOptions holds the thresholds used by Data.Validate
+type ValidationOptions struct {
+	MinRows            int     // Fewer rows is an error
+	MinNumericColumns  int     // Fewer numeric columns is an error
+	HighMissingPercent float64 // Columns with a higher percentage of missing values get a warning
+	LargeRows          int     // More rows get a note about processing time (0 to disable)
+}
+
+// DefaultValidationOptions returns the thresholds for data to be used in PCA
+func DefaultValidationOptions() ValidationOptions {
+	return ValidationOptions{
+		MinRows:            2,
+		MinNumericColumns:  2,
+		HighMissingPercent: 50,
+		LargeRows:          10000,
+	}
+}
+
 // ValidationResult contains the results of CSV validation
 type ValidationResult struct {
 	Valid       bool
@@ -11,6 +11,7 @@ import (
 	"math"
 
 	"github.com/bitjungle/gopca/pkg/types"
+	"gonum.org/v1/gonum/stat"
 )
 
 // NewValidator creates a new CSV validator with the given options
@@ -72,6 +73,101 @@ func (v *Validator) Validate(data *Data) *ValidationResult {
 	return result
 }
 
+// Validate checks whether the data is suitable for PCA and returns a report with
+// severity-tagged issues and a summary of the columns and missing values. Structural
+// problems, too few rows or numeric columns and columns with only missing values are
+// errors; high missing percentages and near-zero variance are warnings.
+func (d *Data) Validate(opts ValidationOptions) *types.ValidationReport {
+	report := &types.ValidationReport{Valid: true}
+	if d == nil {
+		report.Add(types.SeverityError, "", "nil CSV data")
+		return report
+	}
+	report.Rows = d.Rows
+	report.NumericColumns = d.Columns
+	report.CategoricalColumns = len(d.CategoricalColumns)
+	report.TargetColumns = len(d.NumericTargetColumns)
+
+	// Structure must be consistent before the values can be checked
+	if len(d.Matrix) == 0 {
+		report.Add(types.SeverityError, "", "empty data matrix")
+		return report
+	}
+	if d.Rows != len(d.Matrix) {
+		report.Add(types.SeverityError, "", "row count mismatch: reported %d, actual %d", d.Rows, len(d.Matrix))
+		return report
+	}
+	for i, row := range d.Matrix {
+		if len(row) != d.Columns {
+			report.Add(types.SeverityError, "", "row %d has %d columns, expected %d", i+1, len(row), d.Columns)
+			return report
+		}
+	}
+
+	if d.Rows < opts.MinRows {
+		report.Add(types.SeverityError, "", "data must have at least %d rows (found %d)", opts.MinRows, d.Rows)
+	}
+	if d.Columns < opts.MinNumericColumns {
+		report.Add(types.SeverityError, "", "need at least %d numeric columns for PCA (found %d)",
+			opts.MinNumericColumns, d.Columns)
+	} else {
+		report.Add(types.SeverityInfo, "", "%d numeric columns will be used for PCA", d.Columns)
+	}
+
+	for j := 0; j < d.Columns; j++ {
+		name := fmt.Sprintf("column %d", j+1)
+		if j < len(d.Headers) {
+			name = d.Headers[j]
+		}
+
+		values := make([]float64, 0, d.Rows)
+		for i, row := range d.Matrix {
+			missing := d.MissingMask != nil && i < len(d.MissingMask) &&
+				j < len(d.MissingMask[i]) && d.MissingMask[i][j]
+			if !missing && !math.IsNaN(row[j]) {
+				values = append(values, row[j])
+			}
+		}
+		missing := d.Rows - len(values)
+		report.MissingValues += missing
+
+		if len(values) == 0 {
+			report.Add(types.SeverityError, name, "column '%s' contains only missing values", name)
+			continue
+		}
+		if percent := float64(missing) / float64(d.Rows) * 100; percent > opts.HighMissingPercent {
+			report.Add(types.SeverityWarning, name, "column '%s' has %.1f%% missing values", name, percent)
+		}
+		if len(values) > 1 && stat.Variance(values, nil) < 1e-10 {
+			report.Add(types.SeverityWarning, name, "column '%s' has near-zero variance", name)
+		}
+	}
+
+	if cells := d.Rows * d.Columns; cells > 0 {
+		report.MissingPercent = float64(report.MissingValues) / float64(cells) * 100
+	}
+	if report.MissingValues > 0 {
+		report.Add(types.SeverityInfo, "", "dataset contains %.1f%% missing values (%d cells)",
+			report.MissingPercent, report.MissingValues)
+	}
+	if report.CategoricalColumns > 0 {
+		report.Add(types.SeverityInfo, "", "%d categorical column(s) detected - these will be excluded from PCA "+
+			"but available for visualization", report.CategoricalColumns)
+	}
+	if report.TargetColumns > 0 {
+		report.Add(types.SeverityInfo, "", "%d target column(s) detected - these will be excluded from PCA "+
+			"but available for visualization", report.TargetColumns)
+	}
+	if opts.LargeRows > 0 && d.Rows > opts.LargeRows {
+		report.Add(types.SeverityInfo, "", "large dataset detected (%d rows) - processing may take time", d.Rows)
+	}
+	if len(d.RowNames) > 0 {
+		report.Add(types.SeverityInfo, "", "row names detected in first column")
+	}
+
+	return report
+}
+
 // validateNumericData validates numeric matrix data
 func (v *Validator) validateNumericData(data *Data, result *ValidationResult) {
 	if len(data.Matrix) == 0 {
@@ -0,0 +1,101 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestDataValidate(t *testing.T) {
+	nan := math.NaN()
+
+	tests := []struct {
+		name         string
+		data         *Data
+		wantValid    bool
+		wantErrors   []string
+		wantWarnings []string
+		wantMissing  int
+	}{
+		{
+			name: "clean dataset",
+			data: &Data{
+				Headers:            []string{"a", "b", "c"},
+				RowNames:           []string{"r1", "r2", "r3"},
+				Matrix:             types.Matrix{{1, 2, 3}, {4, 5, 7}, {7, 9, 8}},
+				Rows:               3,
+				Columns:            3,
+				CategoricalColumns: map[string][]string{"group": {"x", "y", "x"}},
+			},
+			wantValid: true,
+		},
+		{
+			name: "too few numeric columns",
+			data: &Data{
+				Headers: []string{"a"},
+				Matrix:  types.Matrix{{1}, {2}, {3}},
+				Rows:    3,
+				Columns: 1,
+			},
+			wantErrors: []string{"need at least 2 numeric columns for PCA (found 1)"},
+		},
+		{
+			name: "high missing",
+			data: &Data{
+				Headers: []string{"a", "b"},
+				Matrix:  types.Matrix{{1, nan}, {2, nan}, {3, 5}, {4, 6}, {5, nan}},
+				Rows:    5,
+				Columns: 2,
+			},
+			wantValid:    true,
+			wantWarnings: []string{"column 'b' has 60.0% missing values"},
+			wantMissing:  3,
+		},
+		{
+			name: "only missing and constant",
+			data: &Data{
+				Headers: []string{"a", "b", "c"},
+				Matrix:  types.Matrix{{1, nan, 2}, {2, nan, 2}, {3, nan, 2}},
+				Rows:    3,
+				Columns: 3,
+			},
+			wantErrors:   []string{"column 'b' contains only missing values"},
+			wantWarnings: []string{"column 'c' has near-zero variance"},
+			wantMissing:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := tt.data.Validate(DefaultValidationOptions())
+
+			if report.Valid != tt.wantValid {
+				t.Errorf("expected valid=%v, got %v (issues: %+v)", tt.wantValid, report.Valid, report.Issues)
+			}
+			if got := report.Messages(types.SeverityError); strings.Join(got, "|") != strings.Join(tt.wantErrors, "|") {
+				t.Errorf("expected errors %q, got %q", tt.wantErrors, got)
+			}
+			if got := report.Messages(types.SeverityWarning); strings.Join(got, "|") != strings.Join(tt.wantWarnings, "|") {
+				t.Errorf("expected warnings %q, got %q", tt.wantWarnings, got)
+			}
+			if (report.Err() == nil) != tt.wantValid {
+				t.Errorf("expected Err() to match validity, got %v", report.Err())
+			}
+			if report.MissingValues != tt.wantMissing {
+				t.Errorf("expected %d missing values, got %d", tt.wantMissing, report.MissingValues)
+			}
+			if report.Rows != tt.data.Rows || report.NumericColumns != tt.data.Columns ||
+				report.CategoricalColumns != len(tt.data.CategoricalColumns) {
+				t.Errorf("unexpected counts: %+v", report)
+			}
+		})
+	}
+}
@@ -7,16 +7,21 @@
 package csv
 
 import (
+	"bufio"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/bitjungle/gopca/pkg/types"
 )
 
+// formulaPrefixes are the leading characters that make spreadsheets evaluate a cell
+const formulaPrefixes = "=+-@\t\r"
+
 // NewWriter creates a new CSV writer with the given options
 func NewWriter(opts Options) *Writer {
 	return &Writer{opts: opts}
@@ -35,9 +40,22 @@ func (w *Writer) WriteFile(filename string, data *Data) error {
 
 // Write writes CSV data to an io.Writer
 func (w *Writer) Write(output io.Writer, data *Data) error {
-	writer := csv.NewWriter(output)
-	writer.Comma = w.opts.Delimiter
-	defer writer.Flush()
+	var writer recordWriter
+	if w.opts.Quoting == QuoteMinimal {
+		csvWriter := csv.NewWriter(output)
+		csvWriter.Comma = w.opts.Delimiter
+		defer csvWriter.Flush()
+		writer = csvWriter
+	} else {
+		quoting := &quotingWriter{
+			w:       bufio.NewWriter(output),
+			comma:   w.opts.Delimiter,
+			mode:    w.opts.Quoting,
+			decimal: w.opts.DecimalSeparator,
+		}
+		defer func() { _ = quoting.w.Flush() }()
+		writer = quoting
+	}
 
 	// Determine what type of data to write
 	if len(data.StringData) > 0 {
@@ -46,28 +64,70 @@ func (w *Writer) Write(output io.Writer, data *Data) error {
 	return w.writeNumericData(writer, data)
 }
 
-// writeNumericData writes numeric matrix data
-func (w *Writer) writeNumericData(writer *csv.Writer, data *Data) error {
-	// Write headers
-	if w.opts.HasHeaders && len(data.Headers) > 0 {
-		headers := data.Headers
-		if w.opts.HasRowNames && len(data.RowNames) > 0 {
-			// Add empty header for row names column
-			headers = append([]string{""}, headers...)
+// recordWriter writes one CSV record at a time. *csv.Writer provides QuoteMinimal;
+// quotingWriter the other quoting modes.
+type recordWriter interface {
+	Write(record []string) error
+}
+
+// quotingWriter writes CSV records with a QuotingMode other than QuoteMinimal
+type quotingWriter struct {
+	w       *bufio.Writer
+	comma   rune
+	mode    QuotingMode
+	decimal rune
+}
+
+// Write writes a single record followed by a newline
+func (q *quotingWriter) Write(record []string) error {
+	for i, field := range record {
+		if i > 0 {
+			if _, err := q.w.WriteRune(q.comma); err != nil {
+				return err
+			}
+		}
+
+		quote := false
+		switch q.mode {
+		case QuoteAll:
+			quote = true
+		case QuoteNonNumeric:
+			quote = !isNumber(field, q.decimal)
+		case QuoteNone:
+			if strings.ContainsRune(field, q.comma) || strings.ContainsAny(field, "\"\r\n") {
+				return fmt.Errorf("field %q contains the delimiter, a quote or a line break and cannot be written without quoting", field)
+			}
 		}
-		if err := writer.Write(headers); err != nil {
-			return fmt.Errorf("failed to write headers: %w", err)
+
+		if quote {
+			field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+		}
+		if _, err := q.w.WriteString(field); err != nil {
+			return err
 		}
 	}
+	_, err := q.w.WriteString("\n")
+	return err
+}
+
+// isNumber reports whether value is a number written with the given decimal separator
+func isNumber(value string, decimal rune) bool {
+	if decimal == ',' {
+		value = replaceDecimalSeparator(value, ',', '.')
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// writeNumericData writes numeric matrix data
+func (w *Writer) writeNumericData(writer recordWriter, data *Data) error {
+	if err := w.writeHeaders(writer, data); err != nil {
+		return err
+	}
 
 	// Write data rows
 	for i, row := range data.Matrix {
-		record := make([]string, 0, len(row)+1)
-
-		// Add row name if present
-		if w.opts.HasRowNames && i < len(data.RowNames) {
-			record = append(record, data.RowNames[i])
-		}
+		record := w.newRecord(data, i, len(row))
 
 		// Convert numeric values to strings
 		for j, val := range row {
@@ -90,11 +150,7 @@ func (w *Writer) writeNumericData(writer *csv.Writer, data *Data) error {
 				str = "-Inf"
 			} else {
 				// Format the float value
-				if w.opts.Precision >= 0 {
-					str = strconv.FormatFloat(val, w.opts.FloatFormat, w.opts.Precision, 64)
-				} else {
-					str = strconv.FormatFloat(val, w.opts.FloatFormat, -1, 64)
-				}
+				str = w.formatFloat(val)
 
 				// Handle decimal separator for European format
 				if w.opts.DecimalSeparator == ',' {
@@ -114,31 +170,23 @@ func (w *Writer) writeNumericData(writer *csv.Writer, data *Data) error {
 }
 
 // writeStringData writes string matrix data (for GoCSV)
-func (w *Writer) writeStringData(writer *csv.Writer, data *Data) error {
-	// Write headers
-	if w.opts.HasHeaders && len(data.Headers) > 0 {
-		headers := data.Headers
-		if w.opts.HasRowNames && len(data.RowNames) > 0 {
-			// Add empty header for row names column
-			headers = append([]string{""}, headers...)
-		}
-		if err := writer.Write(headers); err != nil {
-			return fmt.Errorf("failed to write headers: %w", err)
-		}
+func (w *Writer) writeStringData(writer recordWriter, data *Data) error {
+	if err := w.writeHeaders(writer, data); err != nil {
+		return err
 	}
 
 	// Write string data rows
 	for i, row := range data.StringData {
-		record := make([]string, 0, len(row)+1)
+		record := w.newRecord(data, i, len(row))
 
-		// Add row name if present
-		if w.opts.HasRowNames && i < len(data.RowNames) {
-			record = append(record, data.RowNames[i])
+		// Append string values, reformatting numbers when a precision is set
+		for _, value := range row {
+			if w.opts.Precision >= 0 {
+				value = w.roundNumericString(value)
+			}
+			record = append(record, w.escape(value))
 		}
 
-		// Append string values
-		record = append(record, row...)
-
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("failed to write row %d: %w", i+1, err)
 		}
@@ -147,6 +195,94 @@ func (w *Writer) writeStringData(writer *csv.Writer, data *Data) error {
 	return nil
 }
 
+// writeHeaders writes the header row, with IndexHeader above the row names
+func (w *Writer) writeHeaders(writer recordWriter, data *Data) error {
+	if !w.opts.HasHeaders || len(data.Headers) == 0 {
+		return nil
+	}
+	headers := make([]string, 0, len(data.Headers)+1)
+	if w.opts.HasRowNames && len(data.RowNames) > 0 {
+		headers = append(headers, w.escape(data.IndexHeader))
+	}
+	for _, header := range data.Headers {
+		headers = append(headers, w.escape(header))
+	}
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("failed to write headers: %w", err)
+	}
+	return nil
+}
+
+// newRecord starts the record for row i with its row name, if row names are written.
+// Rows beyond the end of RowNames get an empty name so the columns stay aligned.
+func (w *Writer) newRecord(data *Data, i, nValues int) []string {
+	record := make([]string, 0, nValues+1)
+	if w.opts.HasRowNames && len(data.RowNames) > 0 {
+		name := ""
+		if i < len(data.RowNames) {
+			name = data.RowNames[i]
+		}
+		record = append(record, w.escape(name))
+	}
+	return record
+}
+
+// escape prefixes text that a spreadsheet would evaluate as a formula with a single
+// quote when EscapeFormulas is set. Numbers such as -1.5 are left unchanged.
+func (w *Writer) escape(value string) string {
+	if !w.opts.EscapeFormulas || value == "" || !strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return value
+	}
+	if isNumber(value, w.opts.DecimalSeparator) {
+		return value
+	}
+	return "'" + value
+}
+
+// formatFloat formats a finite value with the configured format and precision
+func (w *Writer) formatFloat(v float64) string {
+	format := w.opts.FloatFormat
+	if format == 0 {
+		format = 'g'
+	}
+	precision := w.opts.Precision
+	if precision < 0 {
+		precision = -1
+	}
+	return strconv.FormatFloat(v, format, precision, 64)
+}
+
+// roundNumericString reformats a number written with the configured decimal separator
+// to the configured precision. Other values are returned unchanged.
+func (w *Writer) roundNumericString(value string) string {
+	text := value
+	if w.opts.DecimalSeparator == ',' {
+		text = replaceDecimalSeparator(value, ',', '.')
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return value
+	}
+	str := w.formatFloat(v)
+	if w.opts.DecimalSeparator == ',' {
+		str = replaceDecimalSeparator(str, '.', ',')
+	}
+	return str
+}
+
+// RoundFloat rounds v to precision significant digits. Negative precision, NaN and
+// infinities leave v unchanged.
+func RoundFloat(v float64, precision int) float64 {
+	if precision < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', precision, 64), 64)
+	if err != nil {
+		return v
+	}
+	return rounded
+}
+
 // WriteMatrix writes a numeric matrix to CSV
 func (w *Writer) WriteMatrix(output io.Writer, matrix types.Matrix, headers []string, rowNames []string) error {
 	data := &Data{
@@ -184,16 +320,32 @@ func replaceDecimalSeparator(s string, old, new rune) string {
 	return string(runes)
 }
 
+// WriteFile writes data to a CSV file. It is the counterpart of ParseFile: string
+// data is written as text and numeric data is formatted with the float format,
+// precision and decimal separator from opts, with masked values written as the
+// first null value. Row names are written as the first column when HasRowNames is
+// set, and EscapeFormulas protects text cells from spreadsheet formula evaluation.
+func WriteFile(filename string, data *Data, opts Options) error {
+	return NewWriter(opts).WriteFile(filename, data)
+}
+
+// Write writes data as CSV to an io.Writer. See WriteFile.
+func Write(w io.Writer, data *Data, opts Options) error {
+	return NewWriter(opts).Write(w, data)
+}
+
 // SaveFile is a convenience function for simple CSV writing
+//
+// Deprecated: Use WriteFile.
 func SaveFile(filename string, data *Data, opts Options) error {
-	writer := NewWriter(opts)
-	return writer.WriteFile(filename, data)
+	return WriteFile(filename, data, opts)
 }
 
 // Save is a convenience function for writing CSV to an io.Writer
+//
+// Deprecated: Use Write.
 func Save(w io.Writer, data *Data, opts Options) error {
-	writer := NewWriter(opts)
-	return writer.Write(w, data)
+	return Write(w, data, opts)
 }
 
 // SaveMatrix is a convenience function for writing a matrix to CSV
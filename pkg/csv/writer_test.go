@@ -0,0 +1,290 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package csv
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitjungle/gopca/pkg/types"
+)
+
+func TestWriterPrecision(t *testing.T) {
+	data := &Data{
+		Headers:  []string{"a", "b"},
+		RowNames: []string{"r1"},
+		Matrix:   types.Matrix{{3.14159265358979, 1234567.891}},
+		Rows:     1,
+		Columns:  2,
+	}
+
+	tests := []struct {
+		name       string
+		opts       func(*Options)
+		want       string
+		wantString string // String data keeps its text at full precision
+	}{
+		{"full precision", func(o *Options) {},
+			"r1,3.14159265358979,1.234567891e+06", "r1,3.14159265358979,1234567.891,x1.5"},
+		{"six digits", func(o *Options) { o.Precision = 6 },
+			"r1,3.14159,1.23457e+06", "r1,3.14159,1.23457e+06,x1.5"},
+		{"decimal comma", func(o *Options) {
+			o.Precision = 3
+			o.Delimiter = ';'
+			o.DecimalSeparator = ','
+		}, "r1;3,14;1,23e+06", "r1;3,14;1,23e+06;x1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.opts(&opts)
+
+			var sb strings.Builder
+			if err := NewWriter(opts).Write(&sb, data); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+			if lines[1] != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, lines[1])
+			}
+
+			// String data is rounded the same way, leaving text alone
+			stringData := &Data{
+				Headers:    []string{"a", "b", "label"},
+				RowNames:   []string{"r1"},
+				StringData: [][]string{{"3.14159265358979", "1234567.891", "x1.5"}},
+			}
+			if opts.DecimalSeparator == ',' {
+				stringData.StringData[0] = []string{"3,14159265358979", "1234567,891", "x1.5"}
+			}
+			sb.Reset()
+			if err := NewWriter(opts).Write(&sb, stringData); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			lines = strings.Split(strings.TrimSpace(sb.String()), "\n")
+			if lines[1] != tt.wantString {
+				t.Errorf("expected %q for string data, got %q", tt.wantString, lines[1])
+			}
+		})
+	}
+
+	if got := RoundFloat(0.000123456789, 4); got != 0.0001235 {
+		t.Errorf("RoundFloat: expected 0.0001235, got %g", got)
+	}
+}
+
+func TestWriteQuoting(t *testing.T) {
+	data := &Data{
+		Headers:    []string{"name", "note"},
+		StringData: [][]string{{"a,b", `say "hi"`}, {"line\nbreak", "plain"}},
+	}
+	opts := DefaultOptions()
+	opts.HasRowNames = false
+
+	var sb strings.Builder
+	if err := Write(&sb, data, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "name,note\n\"a,b\",\"say \"\"hi\"\"\"\n\"line\nbreak\",plain\n"
+	if sb.String() != want {
+		t.Errorf("expected %q, got %q", want, sb.String())
+	}
+
+	// Written data parses back to the same values
+	opts.ParseMode = ParseString
+	parsed, err := Parse(strings.NewReader(sb.String()), opts)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	for i, row := range data.StringData {
+		for j, value := range row {
+			if parsed.StringData[i][j] != value {
+				t.Errorf("cell (%d,%d): expected %q, got %q", i, j, value, parsed.StringData[i][j])
+			}
+		}
+	}
+}
+
+func TestWriteQuotingModes(t *testing.T) {
+	data := &Data{
+		Headers:    []string{"name", "value"},
+		StringData: [][]string{{"a,b", "1.5"}},
+	}
+	tests := []struct {
+		mode    QuotingMode
+		want    string
+		wantErr bool
+	}{
+		{QuoteMinimal, "name,value\n\"a,b\",1.5\n", false},
+		{QuoteAll, "\"name\",\"value\"\n\"a,b\",\"1.5\"\n", false},
+		{QuoteNonNumeric, "\"name\",\"value\"\n\"a,b\",1.5\n", false},
+		{QuoteNone, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.HasRowNames = false
+			opts.Quoting = tt.mode
+
+			var sb strings.Builder
+			err := Write(&sb, data, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && sb.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, sb.String())
+			}
+		})
+	}
+
+	// Without special characters, none writes the fields as they are
+	opts := DefaultOptions()
+	opts.HasRowNames = false
+	opts.Quoting = QuoteNone
+	var sb strings.Builder
+	if err := Write(&sb, &Data{Headers: []string{"name"}, StringData: [][]string{{"1.5"}}}, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if sb.String() != "name\n1.5\n" {
+		t.Errorf("expected %q, got %q", "name\n1.5\n", sb.String())
+	}
+
+	if mode, err := ParseQuotingMode("nonnumeric"); err != nil || mode != QuoteNonNumeric {
+		t.Errorf("ParseQuotingMode(nonnumeric) = %v, %v", mode, err)
+	}
+	if _, err := ParseQuotingMode("some"); err == nil {
+		t.Error("expected an error for an unknown quoting mode")
+	}
+}
+
+func TestWriteEuropeanFormat(t *testing.T) {
+	data := &Data{
+		Headers:     []string{"x", "y"},
+		RowNames:    []string{"r1", "r2"},
+		Matrix:      types.Matrix{{1.5, -2.25}, {1000.125, 0}},
+		MissingMask: [][]bool{{false, false}, {false, true}},
+		Rows:        2,
+		Columns:     2,
+	}
+	opts := EuropeanOptions()
+	opts.NullValues = []string{"NA"}
+
+	var sb strings.Builder
+	if err := Write(&sb, data, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := ";x;y\nr1;1,5;-2,25\nr2;1000,125;NA\n"
+	if sb.String() != want {
+		t.Errorf("expected %q, got %q", want, sb.String())
+	}
+
+	parsed, err := Parse(strings.NewReader(sb.String()), opts)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Matrix[1][0] != 1000.125 || !math.IsNaN(parsed.Matrix[1][1]) {
+		t.Errorf("expected [1000.125 NaN], got %v", parsed.Matrix[1])
+	}
+}
+
+func TestWriteRowNames(t *testing.T) {
+	data := &Data{
+		Headers:    []string{"a", "b"},
+		RowNames:   []string{"first", "second"},
+		StringData: [][]string{{"1", "2"}, {"3", "4"}, {"5", "6"}},
+	}
+
+	tests := []struct {
+		name        string
+		hasRowNames bool
+		want        string
+	}{
+		// The third row has no name and gets an empty one to keep the columns aligned
+		{"prefixed", true, ",a,b\nfirst,1,2\nsecond,3,4\n,5,6\n"},
+		{"omitted", false, "a,b\n1,2\n3,4\n5,6\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.HasRowNames = tt.hasRowNames
+
+			var sb strings.Builder
+			if err := Write(&sb, data, opts); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, sb.String())
+			}
+		})
+	}
+}
+
+func TestWriteEscapeFormulas(t *testing.T) {
+	data := &Data{
+		Headers:    []string{"=cmd", "value"},
+		RowNames:   []string{"@row"},
+		StringData: [][]string{{"+SUM(A1:A2)", "-1.5"}},
+	}
+	opts := DefaultOptions()
+
+	var sb strings.Builder
+	if err := Write(&sb, data, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := ",=cmd,value\n@row,+SUM(A1:A2),-1.5\n"; sb.String() != want {
+		t.Errorf("expected %q without escaping, got %q", want, sb.String())
+	}
+
+	opts.EscapeFormulas = true
+	sb.Reset()
+	if err := Write(&sb, data, opts); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// Negative numbers are not formulas and keep their sign
+	if want := ",'=cmd,value\n'@row,'+SUM(A1:A2),-1.5\n"; sb.String() != want {
+		t.Errorf("expected %q with escaping, got %q", want, sb.String())
+	}
+}
+
+func TestWriteExcelFile(t *testing.T) {
+	data := &Data{
+		Headers:     []string{"x", "y"},
+		RowNames:    []string{"r1", "r2"},
+		Matrix:      types.Matrix{{1.23456, 2}, {3, 4}},
+		MissingMask: [][]bool{{false, false}, {true, false}},
+		Rows:        2,
+		Columns:     2,
+	}
+	opts := DefaultOptions()
+	opts.Precision = 3
+
+	filename := filepath.Join(t.TempDir(), "data.xlsx")
+	if err := WriteExcelFile(filename, data, opts); err != nil {
+		t.Fatalf("WriteExcelFile failed: %v", err)
+	}
+
+	parsed, err := ReadTableFile(filename, FormatExcel, opts)
+	if err != nil {
+		t.Fatalf("ReadTableFile failed: %v", err)
+	}
+	if len(parsed.RowNames) != 2 || parsed.RowNames[1] != "r2" {
+		t.Errorf("expected row names [r1 r2], got %v", parsed.RowNames)
+	}
+	want := [][]string{{"1.23", "2"}, {"", "4"}}
+	for i, row := range want {
+		for j, value := range row {
+			if parsed.StringData[i][j] != value {
+				t.Errorf("cell (%d,%d): expected %q, got %q", i, j, value, parsed.StringData[i][j])
+			}
+		}
+	}
+}
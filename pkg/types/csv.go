@@ -11,17 +11,181 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // CSVFormat defines the format and parsing options for CSV files
 type CSVFormat struct {
-	FieldDelimiter   rune     // Field separator: ',', ';', '\t'
-	DecimalSeparator rune     // Decimal separator: '.', ','
-	HasHeaders       bool     // First row contains column names
-	HasRowNames      bool     // First column contains row names
-	NullValues       []string // Strings to treat as missing values
+	FieldDelimiter     rune     // Field separator: ',', ';', '\t'
+	DecimalSeparator   rune     // Decimal separator: '.', ','
+	ThousandsSeparator rune     // Digit grouping separator stripped from numbers, e.g. '.' in 1.234,56 (0 for none)
+	HasHeaders         bool     // First row contains column names
+	HasRowNames        bool     // First column contains row names
+	NullValues         []string // Strings to treat as missing values
+	CommentPrefix      string   // Lines starting with this prefix are skipped (empty to disable)
+	SkipBlankLines     bool     // Skip lines containing only whitespace
+	// Fraction of a column's non-empty values that must be numbers for ParseCSVMixed and
+	// ParseCSVMixedWithTargets to read it as numeric (0 for DefaultNumericThreshold)
+	NumericThreshold float64
+	// How rows with more or fewer fields than the first row are handled (default RaggedRowsError)
+	RaggedRows RaggedRowPolicy
+}
+
+// RaggedRowPolicy defines how parsers handle rows whose number of fields differs
+// from the first (header) row
+type RaggedRowPolicy int
+
+const (
+	// RaggedRowsError rejects rows with too few or too many fields
+	RaggedRowsError RaggedRowPolicy = iota
+	// RaggedRowsPad fills short rows with missing values; long rows are still rejected
+	RaggedRowsPad
+	// RaggedRowsTruncate pads short rows and drops the extra fields of long rows
+	RaggedRowsTruncate
+)
+
+// raggedRowPolicyNames are the names accepted by ParseRaggedRowPolicy
+var raggedRowPolicyNames = map[RaggedRowPolicy]string{
+	RaggedRowsError:    "error",
+	RaggedRowsPad:      "pad",
+	RaggedRowsTruncate: "truncate",
+}
+
+// String returns the name of the ragged row policy
+func (p RaggedRowPolicy) String() string {
+	if name, ok := raggedRowPolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("RaggedRowPolicy(%d)", int(p))
+}
+
+// ParseRaggedRowPolicy returns the ragged row policy with the given name: error, pad
+// or truncate
+func ParseRaggedRowPolicy(name string) (RaggedRowPolicy, error) {
+	for policy, policyName := range raggedRowPolicyNames {
+		if name == policyName {
+			return policy, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid ragged row policy %q: must be error, pad or truncate", name)
+}
+
+// RepairRaggedRows makes every record as wide as the first one according to policy
+// and returns one warning per repaired record. Short records are padded with the
+// first of nullValues, preferring the empty string, so the added fields parse as
+// missing. Records are numbered from 1, including the header row. With
+// RaggedRowsError the records are returned unchanged.
+func RepairRaggedRows(records [][]string, policy RaggedRowPolicy, nullValues []string) ([][]string, []string, error) {
+	if policy == RaggedRowsError || len(records) == 0 {
+		return records, nil, nil
+	}
+
+	missing := ""
+	if len(nullValues) > 0 && !slices.Contains(nullValues, "") {
+		missing = nullValues[0]
+	}
+
+	width := len(records[0])
+	var warnings []string
+	for i, record := range records {
+		switch {
+		case len(record) < width:
+			padded := make([]string, width)
+			copy(padded, record)
+			for j := len(record); j < width; j++ {
+				padded[j] = missing
+			}
+			records[i] = padded
+			warnings = append(warnings, fmt.Sprintf("row %d has %d fields, expected %d: padded with missing values",
+				i+1, len(record), width))
+		case len(record) > width:
+			if policy != RaggedRowsTruncate {
+				return nil, nil, fmt.Errorf("row %d has %d fields, expected %d (truncate drops extra fields)",
+					i+1, len(record), width)
+			}
+			records[i] = record[:width]
+			warnings = append(warnings, fmt.Sprintf("row %d has %d fields, expected %d: dropped %d extra field(s)",
+				i+1, len(record), width, len(record)-width))
+		}
+	}
+	return records, warnings, nil
+}
+
+// ValidateSeparators rejects a field delimiter that is also the decimal separator.
+// With a comma for both, 1,5 could be one number or two fields, so numbers would be
+// split silently. Data with decimal commas is usually semicolon-delimited.
+func ValidateSeparators(delimiter, decimal rune) error {
+	if delimiter != 0 && delimiter == decimal {
+		return fmt.Errorf("the field delimiter and decimal separator are both %q, which makes numbers ambiguous; "+
+			"use ';' as the delimiter for data with decimal commas", decimal)
+	}
+	return nil
+}
+
+// readRecords reads all records from reader and repairs ragged rows according to format
+func readRecords(reader *csv.Reader, format CSVFormat) ([][]string, []string, error) {
+	if err := ValidateSeparators(format.FieldDelimiter, format.DecimalSeparator); err != nil {
+		return nil, nil, err
+	}
+	if format.RaggedRows != RaggedRowsError {
+		reader.FieldsPerRecord = -1
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return RepairRaggedRows(records, format.RaggedRows, format.NullValues)
+}
+
+// SkipLines returns a reader over input without the lines that start with commentPrefix
+// and, if skipBlank is set, without lines that contain only whitespace. Lines are
+// removed before CSV parsing, so they do not affect header or row name detection.
+// Note that a quoted field spanning several lines is filtered line by line.
+func SkipLines(input io.Reader, commentPrefix string, skipBlank bool) (io.Reader, error) {
+	if commentPrefix == "" && !skipBlank {
+		return input, nil
+	}
+
+	content, err := io.ReadAll(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	var kept strings.Builder
+	kept.Grow(len(content))
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if commentPrefix != "" && strings.HasPrefix(line, commentPrefix) {
+			continue
+		}
+		if skipBlank && strings.TrimSpace(line) == "" {
+			continue
+		}
+		kept.WriteString(line)
+	}
+	return strings.NewReader(kept.String()), nil
+}
+
+// IsTSVFile reports whether filename has a tab-separated extension (.tsv or .tab)
+func IsTSVFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".tsv", ".tab":
+		return true
+	}
+	return false
+}
+
+// NewCSVReader returns a csv.Reader using delimiter as the field separator. Leading
+// space is trimmed from fields unless the delimiter is itself whitespace, where
+// trimming would swallow empty fields. Quoted fields may contain the delimiter.
+func NewCSVReader(r io.Reader, delimiter rune) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.TrimLeadingSpace = delimiter != '\t' && delimiter != ' '
+	return reader
 }
 
 // DefaultCSVFormat returns the default CSV format options
@@ -43,6 +207,7 @@ type CSVData struct {
 	MissingMask [][]bool // Track NaN locations (true = missing)
 	Rows        int      // Number of data rows
 	Columns     int      // Number of data columns
+	Warnings    []string // Problems repaired while parsing, such as ragged rows
 }
 
 // CSVParser provides methods for parsing CSV files
@@ -57,22 +222,25 @@ func NewCSVParser(format CSVFormat) *CSVParser {
 
 // Parse reads and parses CSV data from an io.Reader
 func (p *CSVParser) Parse(r io.Reader) (*CSVData, error) {
-	reader := csv.NewReader(r)
-	reader.Comma = p.format.FieldDelimiter
-	reader.TrimLeadingSpace = true
+	r, err := SkipLines(r, p.format.CommentPrefix, p.format.SkipBlankLines)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := NewCSVReader(r, p.format.FieldDelimiter)
 	reader.FieldsPerRecord = -1 // Allow variable number of fields initially
 
 	// Read all records
-	records, err := reader.ReadAll()
+	records, warnings, err := readRecords(reader, p.format)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, err
 	}
 
 	if len(records) == 0 {
 		return nil, fmt.Errorf("empty CSV file")
 	}
 
-	data := &CSVData{}
+	data := &CSVData{Warnings: warnings}
 	currentRow := 0
 
 	// Handle headers
@@ -334,6 +502,22 @@ func (m *MissingValueInfo) HasMissing() bool {
 	return m.TotalMissing > 0
 }
 
+// ColumnsAboveFraction returns, in ascending order, the columns in which the share of
+// missing values out of rows exceeds threshold
+func (m *MissingValueInfo) ColumnsAboveFraction(rows int, threshold float64) []int {
+	var columns []int
+	if rows <= 0 {
+		return columns
+	}
+	for col, count := range m.MissingByColumn {
+		if float64(count)/float64(rows) > threshold {
+			columns = append(columns, col)
+		}
+	}
+	sort.Ints(columns)
+	return columns
+}
+
 // GetSummary returns a human-readable summary of missing values
 func (m *MissingValueInfo) GetSummary() string {
 	if !m.HasMissing() {
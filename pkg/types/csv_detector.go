@@ -7,14 +7,21 @@
 package types
 
 import (
-	"encoding/csv"
 	"io"
 	"math"
 	"strconv"
 	"strings"
 )
 
+// DefaultNumericThreshold is the default fraction of a column's non-empty values that
+// must be numbers for the column to be numeric. The other values are taken to be data
+// entry errors and read as missing values.
+const DefaultNumericThreshold = 0.9
+
 // DefaultColumnTypeDetectionSampleSize defines the default number of rows to check when detecting column types
+//
+// Deprecated: Column types are detected from every row, with DefaultNumericThreshold
+// deciding when a column is numeric. The constant is no longer used.
 const DefaultColumnTypeDetectionSampleSize = 10
 
 // isNumericValue checks if a string value represents a numeric value
@@ -26,14 +33,8 @@ func isNumericValue(value string, format CSVFormat) (bool, float64) {
 		}
 	}
 
-	// Handle decimal separator if needed
-	testValue := value
-	if format.DecimalSeparator == ',' {
-		testValue = strings.ReplaceAll(value, ",", ".")
-	}
-
 	// Try to parse as float
-	val, err := strconv.ParseFloat(testValue, 64)
+	val, err := ParseNumber(value, format.DecimalSeparator, format.ThousandsSeparator)
 	if err == nil {
 		return true, val
 	}
@@ -49,16 +50,97 @@ func isNumericValue(value string, format CSVFormat) (bool, float64) {
 	return false, 0
 }
 
+// ParseNumber parses a number written with the given decimal separator and, if
+// thousands is not 0, digit grouping. Grouping separators are only removed when the
+// integer part is grouped in threes, as in 1.234.567,8 or 1,234.5; otherwise the value
+// is parsed as it is, so 1,5 with thousands ',' is an error rather than 15.
+func ParseNumber(value string, decimal, thousands rune) (float64, error) {
+	if thousands != 0 && thousands != decimal {
+		value = stripThousands(value, decimal, thousands)
+	}
+	if decimal != 0 && decimal != '.' {
+		value = strings.ReplaceAll(value, string(decimal), ".")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// stripThousands removes thousands separators from the integer part of value if they
+// form valid groups of three digits, and returns value unchanged otherwise
+func stripThousands(value string, decimal, thousands rune) string {
+	if !strings.ContainsRune(value, thousands) {
+		return value
+	}
+
+	sign, digits := "", value
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	integer, rest := digits, ""
+	if end := strings.IndexFunc(digits, func(r rune) bool {
+		return r == decimal || r == 'e' || r == 'E'
+	}); end >= 0 {
+		integer, rest = digits[:end], digits[end:]
+	}
+	if strings.ContainsRune(rest, thousands) {
+		return value
+	}
+
+	groups := strings.Split(integer, string(thousands))
+	for i, group := range groups {
+		if (i == 0 && (len(group) < 1 || len(group) > 3)) || (i > 0 && len(group) != 3) {
+			return value
+		}
+		for _, r := range group {
+			if r < '0' || r > '9' {
+				return value
+			}
+		}
+	}
+	return sign + strings.Join(groups, "") + rest
+}
+
+// numericThreshold returns the NumericThreshold of the format, or
+// DefaultNumericThreshold if it is not set
+func (f CSVFormat) numericThreshold() float64 {
+	if f.NumericThreshold <= 0 {
+		return DefaultNumericThreshold
+	}
+	return f.NumericThreshold
+}
+
+// isNumericColumn reports whether the column col of the data rows from startRow on is
+// numeric, that is, at least the format's numeric threshold of its non-empty values are
+// numbers, and whether it has any non-empty value. A column without values is numeric.
+func isNumericColumn(records [][]string, startRow, col int, format CSVFormat) (numeric, hasAnyValue bool) {
+	total, numbers := 0, 0
+	for i := startRow; i < len(records); i++ {
+		if col >= len(records[i]) {
+			continue
+		}
+		value := strings.TrimSpace(records[i][col])
+		if value == "" {
+			continue
+		}
+		total++
+		if isNum, _ := isNumericValue(value, format); isNum {
+			numbers++
+		}
+	}
+	if total == 0 {
+		return true, false
+	}
+	// Allow for rounding when the fraction equals the threshold
+	return float64(numbers)/float64(total) >= format.numericThreshold()-1e-12, true
+}
+
 // DetectColumnTypes reads a CSV file and determines which columns are numeric vs categorical
 func DetectColumnTypes(r io.Reader, format CSVFormat) (numericCols []int, categoricalCols []int, headers []string, err error) {
 	// Initialize return slices
 	numericCols = make([]int, 0)
 	categoricalCols = make([]int, 0)
 
-	csvReader := csv.NewReader(r)
-	csvReader.Comma = format.FieldDelimiter
+	csvReader := NewCSVReader(r, format.FieldDelimiter)
 	csvReader.LazyQuotes = true
-	csvReader.TrimLeadingSpace = true
 
 	// Read all records
 	records, err := csvReader.ReadAll()
@@ -91,37 +173,9 @@ func DetectColumnTypes(r io.Reader, format CSVFormat) (numericCols []int, catego
 		return nil, nil, headers, nil
 	}
 
-	// For each column, check if it's numeric or categorical
+	// For each column, check if it's numeric or categorical; empty columns are numeric
 	for j := 0; j < numCols; j++ {
-		isNumeric := true
-		hasAnyValue := false
-
-		// Check first N rows to determine type
-		for i := startRow; i < len(records) && i < startRow+DefaultColumnTypeDetectionSampleSize; i++ {
-			if j+startCol >= len(records[i]) {
-				continue
-			}
-
-			value := strings.TrimSpace(records[i][j+startCol])
-			if value == "" {
-				continue
-			}
-
-			hasAnyValue = true
-
-			// Check if the value is numeric
-			isNum, _ := isNumericValue(value, format)
-			if !isNum {
-				// Not a number - this is categorical
-				isNumeric = false
-				break
-			}
-		}
-
-		if !hasAnyValue {
-			// Empty column - treat as numeric
-			numericCols = append(numericCols, j)
-		} else if isNumeric {
+		if isNumeric, _ := isNumericColumn(records, startRow, j+startCol, format); isNumeric {
 			numericCols = append(numericCols, j)
 		} else {
 			categoricalCols = append(categoricalCols, j)
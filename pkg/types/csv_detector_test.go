@@ -190,3 +190,37 @@ NA,4,cherry`,
 		})
 	}
 }
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		decimal   rune
+		thousands rune
+		want      float64
+		wantErr   bool
+	}{
+		{"European grouped", "1.234,56", ',', '.', 1234.56, false},
+		{"US grouped", "1,234.56", '.', ',', 1234.56, false},
+		{"several groups", "-1.234.567,5", ',', '.', -1234567.5, false},
+		{"space grouped", "12 345,5", ',', ' ', 12345.5, false},
+		{"ungrouped with separator set", "1234.56", '.', ',', 1234.56, false},
+		{"exponent", "1,234e3", '.', ',', 1234000, false},
+		{"decimal comma without grouping", "3,7", ',', 0, 3.7, false},
+		{"ambiguous short group", "1,5", '.', ',', 0, true},
+		{"ambiguous long group", "1.2345,6", ',', '.', 0, true},
+		{"separator in fraction", "1,234.5,6", '.', ',', 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNumber(tt.value, tt.decimal, tt.thousands)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNumber(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseNumber(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -7,7 +7,6 @@
 package types
 
 import (
-	"encoding/csv"
 	"fmt"
 	"io"
 	"math"
@@ -16,15 +15,18 @@ import (
 
 // ParseCSVMixed parses a CSV file that may contain both numeric and categorical columns
 func ParseCSVMixed(r io.Reader, format CSVFormat) (*CSVData, map[string][]string, error) {
+	r, err := SkipLines(r, format.CommentPrefix, format.SkipBlankLines)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// First, read all records as strings
-	csvReader := csv.NewReader(r)
-	csvReader.Comma = format.FieldDelimiter
+	csvReader := NewCSVReader(r, format.FieldDelimiter)
 	csvReader.LazyQuotes = true
-	csvReader.TrimLeadingSpace = true
 
-	records, err := csvReader.ReadAll()
+	records, warnings, err := readRecords(csvReader, format)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, nil, err
 	}
 
 	if len(records) == 0 {
@@ -71,32 +73,7 @@ func ParseCSVMixed(r io.Reader, format CSVFormat) (*CSVData, map[string][]string
 	categoricalCols := []int{}
 
 	for j := 0; j < numCols; j++ {
-		isNumeric := true
-		hasAnyValue := false
-
-		// Check first N rows to determine type
-		for i := startRow; i < len(records) && i < startRow+DefaultColumnTypeDetectionSampleSize; i++ {
-			if j+startCol >= len(records[i]) {
-				continue
-			}
-
-			value := strings.TrimSpace(records[i][j+startCol])
-			if value == "" {
-				continue
-			}
-
-			hasAnyValue = true
-
-			// Check if the value is numeric
-			isNum, _ := isNumericValue(value, format)
-			if !isNum {
-				// Not a number - this is categorical
-				isNumeric = false
-				break
-			}
-		}
-
-		if !hasAnyValue || isNumeric {
+		if isNumeric, _ := isNumericColumn(records, startRow, j+startCol, format); isNumeric {
 			numericCols = append(numericCols, j)
 		} else {
 			categoricalCols = append(categoricalCols, j)
@@ -118,40 +95,11 @@ func ParseCSVMixed(r io.Reader, format CSVFormat) (*CSVData, map[string][]string
 		MissingMask: make([][]bool, numRows),
 		Rows:        numRows,
 		Columns:     len(numericCols),
+		Warnings:    warnings,
 	}
 
 	// Parse numeric columns
-	for i := 0; i < numRows; i++ {
-		data.Matrix[i] = make([]float64, len(numericCols))
-		data.MissingMask[i] = make([]bool, len(numericCols))
-
-		rowIdx := i + startRow
-		if rowIdx >= len(records) {
-			continue
-		}
-
-		for j, colIdx := range numericCols {
-			if colIdx+startCol >= len(records[rowIdx]) {
-				data.Matrix[i][j] = math.NaN()
-				data.MissingMask[i][j] = true
-				continue
-			}
-
-			value := strings.TrimSpace(records[rowIdx][colIdx+startCol])
-
-			// Try to parse the value as numeric
-			isNum, val := isNumericValue(value, format)
-			if !isNum {
-				// This shouldn't happen if column detection worked correctly
-				data.Matrix[i][j] = math.NaN()
-				data.MissingMask[i][j] = true
-				continue
-			}
-
-			data.Matrix[i][j] = val
-			data.MissingMask[i][j] = math.IsNaN(val)
-		}
-	}
+	parseNumericColumns(data, records, startRow, startCol, numericCols, headers, format)
 
 	// Extract categorical data
 	categoricalData := make(map[string][]string)
@@ -177,6 +125,61 @@ func ParseCSVMixed(r io.Reader, format CSVFormat) (*CSVData, map[string][]string
 	return data, categoricalData, nil
 }
 
+// maxNonNumericExamples is the number of non-numeric values listed in the warning
+// for a numeric column
+const maxNonNumericExamples = 5
+
+// parseNumericColumns parses the columns cols of the data rows into data.Matrix and
+// data.MissingMask. Values that are not numbers in a column detected as numeric are
+// read as missing, with a warning listing them, so data entry errors are reported
+// rather than silently dropped.
+func parseNumericColumns(data *CSVData, records [][]string, startRow, startCol int, cols []int,
+	headers []string, format CSVFormat) {
+	nonNumeric := make([][]string, len(cols))
+	for i := 0; i < data.Rows; i++ {
+		data.Matrix[i] = make([]float64, len(cols))
+		data.MissingMask[i] = make([]bool, len(cols))
+
+		rowIdx := i + startRow
+		if rowIdx >= len(records) {
+			continue
+		}
+
+		for j, colIdx := range cols {
+			value := ""
+			if colIdx+startCol < len(records[rowIdx]) {
+				value = strings.TrimSpace(records[rowIdx][colIdx+startCol])
+			}
+
+			isNum, val := isNumericValue(value, format)
+			if !isNum {
+				if value != "" {
+					nonNumeric[j] = append(nonNumeric[j], fmt.Sprintf("row %d %q", i+1, value))
+				}
+				val = math.NaN()
+			}
+			data.Matrix[i][j] = val
+			data.MissingMask[i][j] = math.IsNaN(val)
+		}
+	}
+
+	for j, values := range nonNumeric {
+		if len(values) == 0 {
+			continue
+		}
+		name := fmt.Sprintf("Column%d", cols[j]+1)
+		if cols[j] < len(headers) {
+			name = headers[cols[j]]
+		}
+		examples := strings.Join(values[:min(len(values), maxNonNumericExamples)], ", ")
+		if len(values) > maxNonNumericExamples {
+			examples += ", ..."
+		}
+		data.Warnings = append(data.Warnings, fmt.Sprintf("column '%s' has %d non-numeric value(s) read as missing: %s",
+			name, len(values), examples))
+	}
+}
+
 // isTargetColumn checks if a column name indicates it should be a target column
 // Target columns are marked with "#target" suffix (with or without space) or are in the provided target list
 func isTargetColumn(columnName string, targetColumns []string) bool {
@@ -201,15 +204,18 @@ func isTargetColumn(columnName string, targetColumns []string) bool {
 // Target columns are numeric columns that should be available for visualization but not included in PCA
 // Columns with "#target" suffix (with or without space) are automatically detected as target columns
 func ParseCSVMixedWithTargets(r io.Reader, format CSVFormat, targetColumns []string) (*CSVData, map[string][]string, map[string][]float64, error) {
+	r, err := SkipLines(r, format.CommentPrefix, format.SkipBlankLines)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	// First, read all records as strings
-	csvReader := csv.NewReader(r)
-	csvReader.Comma = format.FieldDelimiter
+	csvReader := NewCSVReader(r, format.FieldDelimiter)
 	csvReader.LazyQuotes = true
-	csvReader.TrimLeadingSpace = true
 
-	records, err := csvReader.ReadAll()
+	records, warnings, err := readRecords(csvReader, format)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, nil, nil, err
 	}
 
 	if len(records) == 0 {
@@ -254,30 +260,7 @@ func ParseCSVMixedWithTargets(r io.Reader, format CSVFormat, targetColumns []str
 
 	// Check each column
 	for j := 0; j < numCols; j++ {
-		isNumeric := true
-		hasAnyValue := false
-
-		// Check first N rows to determine type
-		for i := startRow; i < len(records) && i < startRow+DefaultColumnTypeDetectionSampleSize; i++ {
-			if j+startCol >= len(records[i]) {
-				continue
-			}
-
-			value := strings.TrimSpace(records[i][j+startCol])
-			if value == "" {
-				continue
-			}
-
-			hasAnyValue = true
-
-			// Check if the value is numeric
-			isNum, _ := isNumericValue(value, format)
-			if !isNum {
-				// Not a number - this is categorical
-				isNumeric = false
-				break
-			}
-		}
+		isNumeric, hasAnyValue := isNumericColumn(records, startRow, j+startCol, format)
 
 		if !hasAnyValue {
 			// Empty column - check if it's a target column by name
@@ -323,40 +306,11 @@ func ParseCSVMixedWithTargets(r io.Reader, format CSVFormat, targetColumns []str
 		MissingMask: make([][]bool, numRows),
 		Rows:        numRows,
 		Columns:     len(numericDataCols),
+		Warnings:    warnings,
 	}
 
 	// Parse numeric data columns
-	for i := 0; i < numRows; i++ {
-		data.Matrix[i] = make([]float64, len(numericDataCols))
-		data.MissingMask[i] = make([]bool, len(numericDataCols))
-
-		rowIdx := i + startRow
-		if rowIdx >= len(records) {
-			continue
-		}
-
-		for j, colIdx := range numericDataCols {
-			if colIdx+startCol >= len(records[rowIdx]) {
-				data.Matrix[i][j] = math.NaN()
-				data.MissingMask[i][j] = true
-				continue
-			}
-
-			value := strings.TrimSpace(records[rowIdx][colIdx+startCol])
-
-			// Try to parse the value as numeric
-			isNum, val := isNumericValue(value, format)
-			if !isNum {
-				// This shouldn't happen if column detection worked correctly
-				data.Matrix[i][j] = math.NaN()
-				data.MissingMask[i][j] = true
-				continue
-			}
-
-			data.Matrix[i][j] = val
-			data.MissingMask[i][j] = math.IsNaN(val)
-		}
-	}
+	parseNumericColumns(data, records, startRow, startCol, numericDataCols, headers, format)
 
 	// Extract categorical data
 	categoricalData := make(map[string][]string)
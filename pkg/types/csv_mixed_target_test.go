@@ -130,3 +130,36 @@ func TestParseCSVMixedWithTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCSVMixedWithTargetsCommentLines(t *testing.T) {
+	csvContent := `# exported by instrument
+# serial: 1234
+# date: 2025-01-01
+id,feature1,category,value#target
+s1,1.0,A,10.5
+
+s2,3.0,B,20.3
+`
+
+	format := DefaultCSVFormat()
+	format.CommentPrefix = "#"
+	format.SkipBlankLines = true
+
+	data, catData, targetData, err := ParseCSVMixedWithTargets(strings.NewReader(csvContent), format, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(data.Headers) != 1 || data.Headers[0] != "feature1" {
+		t.Errorf("unexpected headers: %v", data.Headers)
+	}
+	if strings.Join(data.RowNames, ",") != "s1,s2" {
+		t.Errorf("unexpected row names: %v", data.RowNames)
+	}
+	if got := strings.Join(catData["category"], ","); got != "A,B" {
+		t.Errorf("unexpected categories: %s", got)
+	}
+	if values := targetData["value#target"]; len(values) != 2 || values[1] != 20.3 {
+		t.Errorf("unexpected target values: %v", values)
+	}
+}
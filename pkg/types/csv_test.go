@@ -357,6 +357,25 @@ func TestGetMissingValueInfo(t *testing.T) {
 	}
 }
 
+func TestMissingValueInfo_ColumnsAboveFraction(t *testing.T) {
+	// 10 rows: column 1 is 60% missing, column 2 is 10% missing and column 4 exactly 50%
+	info := MissingValueInfo{
+		MissingByColumn: map[int]int{4: 5, 1: 6, 2: 1},
+	}
+
+	got := info.ColumnsAboveFraction(10, 0.5)
+	if len(got) != 1 || got[0] != 1 {
+		t.Errorf("ColumnsAboveFraction(10, 0.5) = %v, want [1]", got)
+	}
+	got = info.ColumnsAboveFraction(10, 0.05)
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 4 {
+		t.Errorf("ColumnsAboveFraction(10, 0.05) = %v, want [1 2 4]", got)
+	}
+	if got := info.ColumnsAboveFraction(0, 0.5); len(got) != 0 {
+		t.Errorf("ColumnsAboveFraction(0, 0.5) = %v, want none", got)
+	}
+}
+
 func TestMissingValueInfo_GetSummary(t *testing.T) {
 	tests := []struct {
 		name string
@@ -390,3 +409,76 @@ func TestMissingValueInfo_GetSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCSVMixedWithTargetsRaggedRows(t *testing.T) {
+	input := `id,x,label,y#target
+s1,1.5,a,10
+s2,2.5
+s3,3.5,b,30,extra
+`
+
+	format := DefaultCSVFormat()
+	if _, _, _, err := ParseCSVMixedWithTargets(strings.NewReader(input), format, nil); err == nil {
+		t.Error("Expected an error for ragged rows by default")
+	}
+
+	format.RaggedRows = RaggedRowsTruncate
+	data, categorical, targets, err := ParseCSVMixedWithTargets(strings.NewReader(input), format, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data.Rows != 3 {
+		t.Fatalf("Expected 3 rows, got %d", data.Rows)
+	}
+	if got := categorical["label"]; len(got) != 3 || got[1] != "" || got[2] != "b" {
+		t.Errorf("Unexpected categorical values: %v", got)
+	}
+	if got := targets["y#target"]; len(got) != 3 || !math.IsNaN(got[1]) || got[2] != 30 {
+		t.Errorf("Expected the padded target to be missing and the long row truncated, got %v", got)
+	}
+	if len(data.Warnings) != 2 || !strings.Contains(data.Warnings[0], "row 3 has 2 fields, expected 4") ||
+		!strings.Contains(data.Warnings[1], "row 4 has 5 fields, expected 4") {
+		t.Errorf("Unexpected warnings: %v", data.Warnings)
+	}
+}
+
+func TestParseRaggedRowPolicy(t *testing.T) {
+	for _, policy := range []RaggedRowPolicy{RaggedRowsError, RaggedRowsPad, RaggedRowsTruncate} {
+		parsed, err := ParseRaggedRowPolicy(policy.String())
+		if err != nil || parsed != policy {
+			t.Errorf("ParseRaggedRowPolicy(%q) = %v, %v", policy.String(), parsed, err)
+		}
+	}
+	if _, err := ParseRaggedRowPolicy("fill"); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
+
+func TestValidateSeparators(t *testing.T) {
+	if err := ValidateSeparators(',', ','); err == nil {
+		t.Error("Expected an error for a comma delimiter with decimal commas")
+	}
+	if err := ValidateSeparators(';', ','); err != nil {
+		t.Errorf("Unexpected error for a semicolon delimiter with decimal commas: %v", err)
+	}
+	if err := ValidateSeparators(',', '.'); err != nil {
+		t.Errorf("Unexpected error for a comma delimiter with decimal points: %v", err)
+	}
+
+	// The parsers reject the conflicting combination instead of splitting numbers
+	format := DefaultCSVFormat()
+	format.DecimalSeparator = ','
+	input := "id,x,y\ns1,1,5,2\n"
+	if _, _, _, err := ParseCSVMixedWithTargets(strings.NewReader(input), format, nil); err == nil {
+		t.Error("Expected ParseCSVMixedWithTargets to reject a comma delimiter with decimal commas")
+	}
+
+	format.FieldDelimiter = ';'
+	data, _, _, err := ParseCSVMixedWithTargets(strings.NewReader("id;x;y\ns1;1,5;2\ns2;3;4,25\n"), format, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data.Matrix[0][0] != 1.5 || data.Matrix[1][1] != 4.25 {
+		t.Errorf("Expected decimal commas to be parsed, got %v", data.Matrix)
+	}
+}
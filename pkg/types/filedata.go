@@ -0,0 +1,200 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package types
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Column types of a FileData grid
+const (
+	ColumnTypeNumeric     = "numeric"
+	ColumnTypeCategorical = "categorical"
+	ColumnTypeTarget      = "target"
+)
+
+// maxExactInteger is the largest magnitude below which every whole float64 is exact
+const maxExactInteger = 1 << 53
+
+// FileData is a data grid with every cell held as text, as displayed and edited in
+// GoCSV. ColumnTypes maps each header to ColumnTypeNumeric, ColumnTypeCategorical
+// or ColumnTypeTarget.
+type FileData struct {
+	Headers     []string          `json:"headers"`
+	RowNames    []string          `json:"rowNames,omitempty"`
+	Data        [][]string        `json:"data"`
+	ColumnTypes map[string]string `json:"columnTypes,omitempty"`
+}
+
+// CSVDataToFileData combines parsed numeric data with its categorical and target
+// columns into one text grid. Columns follow columnOrder, usually the headers as they
+// appear in the file; columns it does not list are appended, numeric columns first,
+// then categorical and target columns by name. Missing numeric and target values
+// become empty cells, and whole numbers are written without a decimal point or
+// exponent.
+func CSVDataToFileData(data *CSVData, categorical map[string][]string, targets map[string][]float64, columnOrder []string) *FileData {
+	numericIndex := make(map[string]int, len(data.Headers))
+	for j, header := range data.Headers {
+		numericIndex[header] = j
+	}
+
+	order := make([]string, 0, len(data.Headers)+len(categorical)+len(targets))
+	listed := make(map[string]bool)
+	addColumn := func(header string) {
+		if listed[header] {
+			return
+		}
+		_, isNumeric := numericIndex[header]
+		_, isCategorical := categorical[header]
+		_, isTarget := targets[header]
+		if isNumeric || isCategorical || isTarget {
+			order = append(order, header)
+			listed[header] = true
+		}
+	}
+	for _, header := range columnOrder {
+		addColumn(header)
+	}
+	for _, header := range data.Headers {
+		addColumn(header)
+	}
+	for _, header := range sortedKeys(categorical) {
+		addColumn(header)
+	}
+	for _, header := range sortedKeys(targets) {
+		addColumn(header)
+	}
+
+	fd := &FileData{
+		Headers:     order,
+		RowNames:    data.RowNames,
+		Data:        make([][]string, data.Rows),
+		ColumnTypes: make(map[string]string, len(order)),
+	}
+	for i := range fd.Data {
+		fd.Data[i] = make([]string, len(order))
+	}
+
+	for col, header := range order {
+		if j, ok := numericIndex[header]; ok {
+			fd.ColumnTypes[header] = ColumnTypeNumeric
+			for i := range fd.Data {
+				if (data.MissingMask == nil || !data.MissingMask[i][j]) && !math.IsNaN(data.Matrix[i][j]) {
+					fd.Data[i][col] = FormatNumber(data.Matrix[i][j])
+				}
+			}
+		} else if values, ok := categorical[header]; ok {
+			fd.ColumnTypes[header] = ColumnTypeCategorical
+			for i := 0; i < len(fd.Data) && i < len(values); i++ {
+				fd.Data[i][col] = values[i]
+			}
+		} else {
+			values := targets[header]
+			fd.ColumnTypes[header] = ColumnTypeTarget
+			for i := 0; i < len(fd.Data) && i < len(values); i++ {
+				if !math.IsNaN(values[i]) {
+					fd.Data[i][col] = FormatNumber(values[i])
+				}
+			}
+		}
+	}
+
+	return fd
+}
+
+// FileDataToCSVData splits a text grid into numeric data and its categorical and
+// target columns according to ColumnTypes; columns without a type are left out.
+// Numeric and target cells that are empty, one of nullValues or not a number become
+// NaN, and are marked in the missing mask for numeric columns. Categorical cells are
+// kept as they are.
+func FileDataToCSVData(fd *FileData, nullValues []string) (*CSVData, map[string][]string, map[string][]float64) {
+	isNull := make(map[string]bool, len(nullValues)+1)
+	isNull[""] = true
+	for _, v := range nullValues {
+		isNull[v] = true
+	}
+	parseCell := func(row []string, col int) (float64, bool) {
+		if col >= len(row) {
+			return math.NaN(), false
+		}
+		cell := strings.TrimSpace(row[col])
+		if isNull[cell] {
+			return math.NaN(), false
+		}
+		v, err := strconv.ParseFloat(cell, 64)
+		if err != nil || math.IsNaN(v) {
+			return math.NaN(), false
+		}
+		return v, true
+	}
+
+	rows := len(fd.Data)
+	data := &CSVData{
+		Matrix:      make(Matrix, rows),
+		RowNames:    fd.RowNames,
+		MissingMask: make([][]bool, rows),
+		Rows:        rows,
+	}
+	categorical := make(map[string][]string)
+	targets := make(map[string][]float64)
+
+	for col, header := range fd.Headers {
+		switch fd.ColumnTypes[header] {
+		case ColumnTypeNumeric:
+			data.Headers = append(data.Headers, header)
+			for i, row := range fd.Data {
+				v, ok := parseCell(row, col)
+				data.Matrix[i] = append(data.Matrix[i], v)
+				data.MissingMask[i] = append(data.MissingMask[i], !ok)
+			}
+		case ColumnTypeCategorical:
+			values := make([]string, rows)
+			for i, row := range fd.Data {
+				if col < len(row) {
+					values[i] = row[col]
+				}
+			}
+			categorical[header] = values
+		case ColumnTypeTarget:
+			values := make([]float64, rows)
+			for i, row := range fd.Data {
+				values[i], _ = parseCell(row, col)
+			}
+			targets[header] = values
+		}
+	}
+	data.Columns = len(data.Headers)
+
+	return data, categorical, targets
+}
+
+// IsWholeNumber reports whether v is an integer that float64 represents exactly
+func IsWholeNumber(v float64) bool {
+	return v == math.Trunc(v) && math.Abs(v) < maxExactInteger
+}
+
+// FormatNumber formats a value for a FileData cell, writing whole numbers without
+// a decimal point or exponent
+func FormatNumber(v float64) string {
+	if IsWholeNumber(v) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
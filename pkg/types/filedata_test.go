@@ -0,0 +1,117 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package types
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileDataRoundTrip(t *testing.T) {
+	content := `id,length,species,width,yield#target
+s1,5.1,setosa,3.5,12
+s2,NA,versicolor,2.9,
+s3,6.7,,3.1,15.5
+s4,1000000,virginica,,9`
+	format := DefaultCSVFormat()
+	format.HasRowNames = true
+
+	data, categorical, targets, err := ParseCSVMixedWithTargets(strings.NewReader(content), format, nil)
+	if err != nil {
+		t.Fatalf("ParseCSVMixedWithTargets failed: %v", err)
+	}
+
+	columnOrder := []string{"length", "species", "width", "yield#target"}
+	fd := CSVDataToFileData(data, categorical, targets, columnOrder)
+
+	if !reflect.DeepEqual(fd.Headers, columnOrder) {
+		t.Errorf("headers = %v, want %v", fd.Headers, columnOrder)
+	}
+	wantTypes := map[string]string{
+		"length":       ColumnTypeNumeric,
+		"species":      ColumnTypeCategorical,
+		"width":        ColumnTypeNumeric,
+		"yield#target": ColumnTypeTarget,
+	}
+	if !reflect.DeepEqual(fd.ColumnTypes, wantTypes) {
+		t.Errorf("column types = %v, want %v", fd.ColumnTypes, wantTypes)
+	}
+	wantGrid := [][]string{
+		{"5.1", "setosa", "3.5", "12"},
+		{"", "versicolor", "2.9", ""},
+		{"6.7", "", "3.1", "15.5"},
+		{"1000000", "virginica", "", "9"},
+	}
+	if !reflect.DeepEqual(fd.Data, wantGrid) {
+		t.Errorf("grid = %v, want %v", fd.Data, wantGrid)
+	}
+
+	back, backCategorical, backTargets := FileDataToCSVData(fd, format.NullValues)
+
+	if !reflect.DeepEqual(back.Headers, data.Headers) || !reflect.DeepEqual(back.RowNames, data.RowNames) {
+		t.Errorf("headers/row names = %v/%v, want %v/%v", back.Headers, back.RowNames, data.Headers, data.RowNames)
+	}
+	if back.Rows != data.Rows || back.Columns != data.Columns {
+		t.Errorf("dimensions = %dx%d, want %dx%d", back.Rows, back.Columns, data.Rows, data.Columns)
+	}
+	if !reflect.DeepEqual(back.MissingMask, data.MissingMask) {
+		t.Errorf("missing mask = %v, want %v", back.MissingMask, data.MissingMask)
+	}
+	for i := range data.Matrix {
+		for j := range data.Matrix[i] {
+			got, want := back.Matrix[i][j], data.Matrix[i][j]
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Errorf("matrix[%d][%d] = %g, want %g", i, j, got, want)
+			}
+		}
+	}
+	if !reflect.DeepEqual(backCategorical, categorical) {
+		t.Errorf("categorical = %v, want %v", backCategorical, categorical)
+	}
+	for name, values := range targets {
+		for i, want := range values {
+			got := backTargets[name][i]
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Errorf("target %s[%d] = %g, want %g", name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestCSVDataToFileDataUnlistedColumns(t *testing.T) {
+	data := &CSVData{
+		Matrix:  Matrix{{1, 2}, {3, 4}},
+		Headers: []string{"a", "b"},
+		Rows:    2,
+		Columns: 2,
+	}
+	categorical := map[string][]string{"group": {"x", "y"}}
+	targets := map[string][]float64{"y#target": {0.5, math.NaN()}}
+
+	// Columns missing from the order are appended rather than dropped
+	fd := CSVDataToFileData(data, categorical, targets, []string{"b"})
+	if want := []string{"b", "a", "group", "y#target"}; !reflect.DeepEqual(fd.Headers, want) {
+		t.Errorf("headers = %v, want %v", fd.Headers, want)
+	}
+	if want := [][]string{{"2", "1", "x", "0.5"}, {"4", "3", "y", ""}}; !reflect.DeepEqual(fd.Data, want) {
+		t.Errorf("grid = %v, want %v", fd.Data, want)
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	if got := FormatNumber(1234567); got != "1234567" {
+		t.Errorf("expected whole numbers without an exponent, got %q", got)
+	}
+	if got := FormatNumber(0.1); got != "0.1" {
+		t.Errorf("expected 0.1, got %q", got)
+	}
+	if got := FormatNumber(1 << 60); got != "1.152921504606847e+18" {
+		t.Errorf("expected an exponent beyond exact integers, got %q", got)
+	}
+}
@@ -6,6 +6,8 @@
 
 package types
 
+import "context"
+
 // Matrix represents a 2D data matrix
 type Matrix [][]float64
 
@@ -23,6 +25,16 @@ const (
 	MissingMedian MissingValueStrategy = "median"
 	// MissingNative allows NIPALS to handle missing values natively (NIPALS only)
 	MissingNative MissingValueStrategy = "native"
+	// MissingKNN replaces missing values with the mean of the nearest rows
+	MissingKNN MissingValueStrategy = "knn"
+	// MissingIterative predicts missing values by regression on the other columns, repeated until stable
+	MissingIterative MissingValueStrategy = "iterative"
+	// MissingInterpolate interpolates missing values linearly between neighbouring rows
+	MissingInterpolate MissingValueStrategy = "interpolate"
+	// MissingMode replaces missing values with the most frequent value of the column
+	MissingMode MissingValueStrategy = "mode"
+	// MissingForward replaces missing values with the last observed value above them
+	MissingForward MissingValueStrategy = "forward"
 )
 
 // PCAConfig holds configuration for PCA analysis
@@ -30,20 +42,42 @@ type PCAConfig struct {
 	Components      int    `json:"components"`
 	MeanCenter      bool   `json:"mean_center"`
 	StandardScale   bool   `json:"standard_scale"`
-	RobustScale     bool   `json:"robust_scale"`               // Robust scaling (median/MAD)
-	ScaleOnly       bool   `json:"scale_only"`                 // Variance scaling: divide by std dev without mean centering
-	SNV             bool   `json:"snv"`                        // Standard Normal Variate (row-wise normalization)
-	VectorNorm      bool   `json:"vector_norm"`                // L2 normalization (row-wise)
-	Method          string `json:"method"`                     // "svd", "eigen", "nipals", or "kernel"
-	ExcludedRows    []int  `json:"excluded_rows,omitempty"`    // 0-based indices of rows to exclude
-	ExcludedColumns []int  `json:"excluded_columns,omitempty"` // 0-based indices of columns to exclude
+	RobustScale     bool   `json:"robust_scale"`                // Robust scaling (median/MAD)
+	ScaleOnly       bool   `json:"scale_only"`                  // Variance scaling: divide by std dev without mean centering
+	SNV             bool   `json:"snv"`                         // Standard Normal Variate (row-wise normalization)
+	VectorNorm      bool   `json:"vector_norm"`                 // Vector normalization (row-wise)
+	VectorNormType  string `json:"vector_norm_type,omitempty"`  // "l1", "l2" (default) or "max"
+	RobustCenter    string `json:"robust_center,omitempty"`     // "median" (default) or "trimmed-mean"
+	RobustScaleStat string `json:"robust_scale_stat,omitempty"` // "mad" (default) or "iqr"
+	Method          string `json:"method"`                      // "svd", "eigen", "nipals", or "kernel"
+	ExcludedRows    []int  `json:"excluded_rows,omitempty"`     // 0-based indices of rows to exclude
+	ExcludedColumns []int  `json:"excluded_columns,omitempty"`  // 0-based indices of columns to exclude
+	// Columns removed before fitting because too many of their values were missing
+	DroppedColumns []string `json:"dropped_columns,omitempty"`
 	// Missing value handling
 	MissingStrategy MissingValueStrategy `json:"missing_strategy,omitempty"` // How to handle missing values
+	// Rows whose values are all identical are dropped before fitting, instead of being
+	// reported in the warnings of the result. Fit checks the rows of the data it is given,
+	// so a caller that preprocesses the data itself checks them before that and sets
+	// ZeroVarianceRowsChecked.
+	DropZeroVarianceRows    bool `json:"drop_zero_variance_rows,omitempty"`
+	ZeroVarianceRowsChecked bool `json:"-"`
+	// ScoresOnly leaves the loadings out of the result, for callers that only use the
+	// scores and explained variance; the engine keeps them for Transform
+	ScoresOnly bool `json:"scores_only,omitempty"`
 	// Kernel PCA specific parameters
 	KernelType   string  `json:"kernel_type,omitempty"`   // "rbf", "linear", "poly"
 	KernelGamma  float64 `json:"kernel_gamma,omitempty"`  // RBF/Poly parameter
 	KernelDegree int     `json:"kernel_degree,omitempty"` // Poly parameter
 	KernelCoef0  float64 `json:"kernel_coef0,omitempty"`  // Poly parameter
+	// NIPALS specific parameters
+	OrthogonalizeScores bool `json:"orthogonalize_scores,omitempty"` // Re-orthogonalize each score vector against previous scores
+	NIPALSMaxIter       int  `json:"nipals_max_iter,omitempty"`      // Iterations per component before giving up (0 for the default of 1000)
+	// Refit complete data with SVD when a NIPALS component does not converge, instead of failing
+	NIPALSFallbackToSVD bool `json:"nipals_fallback_to_svd,omitempty"`
+	// Keep the preprocessed data of a NIPALS fit on complete data in the engine, so that
+	// a ComponentExtender can add components to the fit later
+	KeepFitData bool `json:"-"`
 }
 
 // PCAResult contains the results of PCA analysis
@@ -72,6 +106,41 @@ type PCAResult struct {
 	Eigencorrelations *EigencorrelationResult `json:"eigencorrelations,omitempty"`
 	// All eigenvalues (including non-retained) for diagnostic calculations
 	AllEigenvalues []float64 `json:"all_eigenvalues,omitempty"`
+	// Category centroids projected as supplementary (passive) points
+	SupplementaryGroups *SupplementaryGroups `json:"supplementary_groups,omitempty"`
+	// Rows removed before fitting; Scores has one row per remaining input row
+	DroppedRows []int `json:"dropped_rows,omitempty"` // 0-based indices into the input data
+	// Divisor of each score column when Scores are normalized to unit variance
+	ScoreScale []float64 `json:"score_scale,omitempty"`
+	// "signal" or "noise" for each component, by a component selection criterion
+	ComponentStatus []string `json:"component_status,omitempty"`
+	// T² against Q of each sample with their 95% limits, for an influence plot
+	Influence []InfluencePoint `json:"influence,omitempty"`
+	// Numerical safeguards applied to the centered kernel matrix of kernel PCA
+	KernelDiagnostics *KernelDiagnostics `json:"kernel_diagnostics,omitempty"`
+	// Explained variance of all components with the elbow of the curve
+	VarianceCurve *VarianceCurve `json:"variance_curve,omitempty"`
+	// Problems worked around during the fit, such as discarded rank-deficient components
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// VarianceCurve is the explained variance of all components of the data, not only
+// the retained ones, for scree plots
+type VarianceCurve struct {
+	ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"` // Percentage of variance of each component
+	CumulativeVariance     []float64 `json:"cumulative_variance"`      // Cumulative percentage
+	Elbow                  int       `json:"elbow"`                    // Components at the elbow of the cumulative curve
+}
+
+// KernelDiagnostics reports the numerical safeguards of a kernel PCA fit. Rounding
+// in the double-centering of the kernel matrix leaves small negative eigenvalues
+// where there should be zeros; these are clamped to zero.
+type KernelDiagnostics struct {
+	ClampedEigenvalues int     `json:"clamped_eigenvalues"` // Negative eigenvalues set to zero
+	MaxClamped         float64 `json:"max_clamped"`         // Magnitude of the most negative eigenvalue clamped
+	NullComponents     int     `json:"null_components"`     // Retained components with a numerically zero eigenvalue; their scores are 0
+	ConditionNumber    float64 `json:"condition_number"`    // Largest over the smallest nonzero retained eigenvalue
+	NearSingular       bool    `json:"near_singular"`       // Null components, or a condition number above the warning level
 }
 
 // EigencorrelationResult contains correlations between PC scores and metadata variables
@@ -90,6 +159,34 @@ type PCAEngine interface {
 	FitTransform(data Matrix, config PCAConfig) (*PCAResult, error)
 }
 
+// ContextFitter is implemented by PCA engines whose fit can be cancelled. It is
+// separate from PCAEngine so that engines without it still implement PCAEngine.
+type ContextFitter interface {
+	// FitContext is like Fit but returns ctx.Err() promptly when ctx is cancelled
+	FitContext(ctx context.Context, data Matrix, config PCAConfig) (*PCAResult, error)
+}
+
+// FitContext fits engine with its FitContext if it is a ContextFitter. Other engines
+// are fitted with Fit, which runs to completion, unless ctx is already cancelled.
+func FitContext(ctx context.Context, engine PCAEngine, data Matrix, config PCAConfig) (*PCAResult, error) {
+	if fitter, ok := engine.(ContextFitter); ok {
+		return fitter.FitContext(ctx, data, config)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return engine.Fit(data, config)
+}
+
+// ComponentExtender is implemented by PCA engines that can add components to their
+// last fit without recomputing the components already fitted. Engines can only do
+// so when the fit was configured with KeepFitData.
+type ComponentExtender interface {
+	// FitAdditionalComponents returns prev, the result of the last fit of the engine,
+	// extended with extra components
+	FitAdditionalComponents(prev *PCAResult, extra int) (*PCAResult, error)
+}
+
 // PCAOutputData represents complete PCA results for output
 type PCAOutputData struct {
 	Schema            string                  `json:"$schema,omitempty"`
@@ -100,6 +197,17 @@ type PCAOutputData struct {
 	Diagnostics       DiagnosticLimits        `json:"diagnostics,omitempty"`
 	Eigencorrelations *EigencorrelationResult `json:"eigencorrelations,omitempty"`
 	PreservedColumns  *PreservedColumns       `json:"preservedColumns,omitempty"`
+	// Supplementary points do not influence the fit
+	SupplementaryGroups *SupplementaryGroups `json:"supplementaryGroups,omitempty"`
+}
+
+// SupplementaryGroups contains the centroids of the categories of a column,
+// projected onto a fitted model as supplementary (passive) points
+type SupplementaryGroups struct {
+	Column     string   `json:"column"`     // Categorical column defining the groups
+	Categories []string `json:"categories"` // Category names in order of first appearance
+	Counts     []int    `json:"counts"`     // Number of member rows per category
+	Scores     Matrix   `json:"scores"`     // Centroid scores (categories × components)
 }
 
 // SampleData contains sample-space results
@@ -163,42 +271,78 @@ type ModelConfig struct {
 	MissingStrategy MissingValueStrategy `json:"missing_strategy"`
 	ExcludedRows    []int                `json:"excluded_rows,omitempty"`
 	ExcludedColumns []int                `json:"excluded_columns,omitempty"`
+	DroppedColumns  []string             `json:"dropped_columns,omitempty"` // Columns dropped for too many missing values
 	// Kernel PCA parameters
 	KernelType   string  `json:"kernel_type,omitempty"`
 	KernelGamma  float64 `json:"kernel_gamma,omitempty"`
 	KernelDegree int     `json:"kernel_degree,omitempty"`
 	KernelCoef0  float64 `json:"kernel_coef0,omitempty"`
+	// ScoresOnly marks output without loadings, which cannot be used as a model
+	ScoresOnly bool `json:"scores_only,omitempty"`
 }
 
 // PreprocessingInfo contains all preprocessing configuration and parameters
 type PreprocessingInfo struct {
-	MeanCenter    bool                `json:"mean_center"`
-	StandardScale bool                `json:"standard_scale"`
-	RobustScale   bool                `json:"robust_scale"`
-	ScaleOnly     bool                `json:"scale_only"`
-	SNV           bool                `json:"snv"`
-	VectorNorm    bool                `json:"vector_norm"`
-	Parameters    PreprocessingParams `json:"parameters"`
+	MeanCenter    bool `json:"mean_center"`
+	StandardScale bool `json:"standard_scale"`
+	RobustScale   bool `json:"robust_scale"`
+	ScaleOnly     bool `json:"scale_only"`
+	SNV           bool `json:"snv"`
+	VectorNorm    bool `json:"vector_norm"`
+	// VectorNormType is the norm used by vector normalization; empty means L2
+	VectorNormType string `json:"vector_norm_type,omitempty"`
+	// RobustCenter and RobustScaleStat are the statistics of robust scaling; empty
+	// means median and MAD
+	RobustCenter    string              `json:"robust_center,omitempty"`
+	RobustScaleStat string              `json:"robust_scale_stat,omitempty"`
+	Parameters      PreprocessingParams `json:"parameters"`
 }
 
 // PreprocessingParams contains the fitted preprocessing parameters
 type PreprocessingParams struct {
 	FeatureMeans   []float64 `json:"feature_means,omitempty"`
 	FeatureStdDevs []float64 `json:"feature_stddevs,omitempty"`
-	FeatureMedians []float64 `json:"feature_medians,omitempty"`
-	FeatureMADs    []float64 `json:"feature_mads,omitempty"`
+	FeatureMedians []float64 `json:"feature_medians,omitempty"` // Robust scaling center per feature
+	FeatureMADs    []float64 `json:"feature_mads,omitempty"`    // Robust scaling divisor per feature
 	RowMeans       []float64 `json:"row_means,omitempty"`
 	RowStdDevs     []float64 `json:"row_stddevs,omitempty"`
 }
 
 // ModelComponents contains the core PCA model components
 type ModelComponents struct {
-	Loadings               Matrix    `json:"loadings"`
+	Loadings               Matrix    `json:"loadings,omitzero"` // nil in scores-only output
 	ExplainedVariance      []float64 `json:"explained_variance"`
 	ExplainedVarianceRatio []float64 `json:"explained_variance_ratio"`
 	CumulativeVariance     []float64 `json:"cumulative_variance"`
 	ComponentLabels        []string  `json:"component_labels"`
 	FeatureLabels          []string  `json:"feature_labels"`
+	VariableImportance     []float64 `json:"variable_importance,omitempty"` // Normalized importance per feature
+	// Participation ratio (Σλ)²/Σλ² over all eigenvalues
+	EffectiveDimensionality float64 `json:"effective_dimensionality,omitempty"`
+	// Cumulative explained variance of the retained components (%)
+	SignalCaptured float64 `json:"signal_captured,omitempty"`
+	// Variables with the largest positive and negative loadings on each component
+	ComponentInterpretation []ComponentInterpretation `json:"component_interpretation,omitempty"`
+	// "signal" or "noise" for each component, by a component selection criterion
+	ComponentStatus []string `json:"component_status,omitempty"`
+	// Explained variance of all components with the elbow of the curve
+	VarianceCurve *VarianceCurve `json:"variance_curve,omitempty"`
+}
+
+// ComponentInterpretation lists the variables that dominate a component, as a
+// reading aid for the loadings. Signs are relative: flipping a component flips
+// Positive and Negative.
+type ComponentInterpretation struct {
+	Component string            `json:"component"`
+	Positive  []VariableLoading `json:"positive"` // Largest positive loadings, strongest first
+	Negative  []VariableLoading `json:"negative"` // Largest negative loadings, strongest first
+	Summary   string            `json:"summary"`  // e.g. "contrasts petal length (+) against sepal width (−)"
+}
+
+// VariableLoading is the loading of a named variable on a component
+type VariableLoading struct {
+	Variable string  `json:"variable"`
+	Loading  float64 `json:"loading"`
 }
 
 // ResultsData contains the results of the PCA analysis
@@ -208,9 +352,15 @@ type ResultsData struct {
 
 // SamplesResults contains sample-specific results
 type SamplesResults struct {
-	Names   []string     `json:"names"`
-	Scores  Matrix       `json:"scores"`
-	Metrics *MetricsData `json:"metrics,omitempty"`
+	Names  []string `json:"names"`
+	Scores Matrix   `json:"scores"`
+	// Divisor of each score column when the scores are normalized to unit variance
+	ScoreScale []float64 `json:"score_scale,omitempty"`
+	// Categorical and target columns carried next to the scores, one value per sample
+	CarriedColumns *PreservedColumns `json:"carried_columns,omitempty"`
+	Metrics        *MetricsData      `json:"metrics,omitempty"`
+	// T² against Q of each sample with their 95% limits, for an influence plot
+	Influence []InfluencePoint `json:"influence,omitempty"`
 }
 
 // MetricsData contains diagnostic metrics for samples
@@ -221,6 +371,17 @@ type MetricsData struct {
 	IsOutlier   []bool    `json:"is_outlier"`
 }
 
+// InfluencePoint is one observation of an influence plot: its Hotelling's T² against
+// its Q residual (SPE), the 95% limits of both and the quadrant it falls in. A limit
+// of 0 could not be calculated and is never exceeded.
+type InfluencePoint struct {
+	T2        float64 `json:"t2"`
+	Q         float64 `json:"q"`
+	T2Limit95 float64 `json:"t2_limit95"`
+	QLimit95  float64 `json:"q_limit95"`
+	Status    string  `json:"status"` // normal, high-t2, high-q or both
+}
+
 // DiagnosticLimits contains statistical limits for diagnostics
 type DiagnosticLimits struct {
 	T2Limit95 float64 `json:"t2_limit_95,omitempty"`
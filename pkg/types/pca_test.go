@@ -0,0 +1,73 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fitOnlyEngine is a PCAEngine without FitContext, as written before it existed
+type fitOnlyEngine struct {
+	fits int
+}
+
+func (e *fitOnlyEngine) Fit(data Matrix, config PCAConfig) (*PCAResult, error) {
+	e.fits++
+	return &PCAResult{}, nil
+}
+
+func (e *fitOnlyEngine) Transform(data Matrix) (Matrix, error) {
+	return data, nil
+}
+
+func (e *fitOnlyEngine) FitTransform(data Matrix, config PCAConfig) (*PCAResult, error) {
+	return e.Fit(data, config)
+}
+
+// contextEngine records the context it was fitted with
+type contextEngine struct {
+	fitOnlyEngine
+	ctx context.Context
+}
+
+func (e *contextEngine) FitContext(ctx context.Context, data Matrix, config PCAConfig) (*PCAResult, error) {
+	e.ctx = ctx
+	return &PCAResult{}, nil
+}
+
+func TestFitContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, true)
+
+	withContext := &contextEngine{}
+	if _, err := FitContext(ctx, withContext, nil, PCAConfig{}); err != nil {
+		t.Fatalf("FitContext failed: %v", err)
+	}
+	if withContext.ctx != ctx || withContext.fits != 0 {
+		t.Error("expected a ContextFitter to be fitted with its FitContext")
+	}
+
+	fitOnly := &fitOnlyEngine{}
+	if _, err := FitContext(ctx, fitOnly, nil, PCAConfig{}); err != nil {
+		t.Fatalf("FitContext failed: %v", err)
+	}
+	if fitOnly.fits != 1 {
+		t.Errorf("expected an engine without FitContext to be fitted with Fit, got %d fits", fitOnly.fits)
+	}
+
+	// A cancelled context stops an engine without FitContext before it starts
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := FitContext(cancelled, fitOnly, nil, PCAConfig{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if fitOnly.fits != 1 {
+		t.Error("expected no fit with a cancelled context")
+	}
+}
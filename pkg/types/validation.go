@@ -0,0 +1,85 @@
+// Copyright 2025 bitjungle - Rune Mathisen. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+// The author respectfully requests that it not be used for
+// military, warfare, or surveillance applications.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Severity classifies a validation issue
+type Severity string
+
+const (
+	// SeverityError marks an issue that prevents PCA
+	SeverityError Severity = "error"
+	// SeverityWarning marks an issue that may affect the results
+	SeverityWarning Severity = "warning"
+	// SeverityInfo marks a note about the data
+	SeverityInfo Severity = "info"
+)
+
+// ValidationIssue is a single finding of data validation
+type ValidationIssue struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Column   string   `json:"column,omitempty"` // Column the issue refers to, if any
+}
+
+// ValidationReport summarizes whether a dataset is suitable for PCA
+type ValidationReport struct {
+	Valid              bool              `json:"valid"` // No issues with SeverityError
+	Rows               int               `json:"rows"`
+	NumericColumns     int               `json:"numeric_columns"`
+	CategoricalColumns int               `json:"categorical_columns"`
+	TargetColumns      int               `json:"target_columns"`
+	MissingValues      int               `json:"missing_values"`  // Missing cells in numeric columns
+	MissingPercent     float64           `json:"missing_percent"` // Percentage of numeric cells missing
+	Issues             []ValidationIssue `json:"issues"`
+}
+
+// Add appends an issue with a formatted message and updates Valid
+func (r *ValidationReport) Add(severity Severity, column, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, ValidationIssue{
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Column:   column,
+	})
+	r.Valid = r.Count(SeverityError) == 0
+}
+
+// Count returns the number of issues with the given severity
+func (r *ValidationReport) Count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Messages returns the messages of the issues with the given severity
+func (r *ValidationReport) Messages(severity Severity) []string {
+	var messages []string
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			messages = append(messages, issue.Message)
+		}
+	}
+	return messages
+}
+
+// Err returns the error messages joined into one error, or nil if there are none
+func (r *ValidationReport) Err() error {
+	messages := r.Messages(SeverityError)
+	if len(messages) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(messages, "; "))
+}